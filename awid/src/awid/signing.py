@@ -13,6 +13,7 @@ from awid.did import public_key_from_did
 
 SIGNED_FIELDS = frozenset(
     {
+        "attachments_sha256",
         "body",
         "from",
         "from_did",
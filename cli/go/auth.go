@@ -0,0 +1,43 @@
+package aweb
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/awebai/aw/awid"
+)
+
+// ErrAPIKeyRotationUnsupported is returned by RotateAPIKey when the server
+// does not issue API keys, as with a self-hosted aweb that no hosting
+// operator fronts.
+var ErrAPIKeyRotationUnsupported = errors.New("aweb: this server does not support API key rotation")
+
+// RotateAPIKeyResponse carries the replacement workspace API key. The
+// previous key keeps working until PreviousKeyExpiresAt so that other
+// processes holding it can pick up the new one.
+type RotateAPIKeyResponse struct {
+	APIKey               string `json:"api_key"`
+	PreviousKeyExpiresAt string `json:"previous_key_expires_at,omitempty"`
+	GraceSeconds         int    `json:"grace_seconds,omitempty"`
+}
+
+// RotateAPIKey issues a new API key for the calling agent and starts the
+// grace window for the old one. The client authenticates with the key being
+// rotated, so it must use bearer auth; on success it switches to the new key
+// for every later request.
+func (c *Client) RotateAPIKey(ctx context.Context) (*RotateAPIKeyResponse, error) {
+	var out RotateAPIKeyResponse
+	err := c.Post(ctx, c.APIPath("/auth/rotate"), nil, &out)
+	if code, ok := awid.HTTPStatusCode(err); ok && (code == http.StatusNotFound || code == http.StatusMethodNotAllowed || code == http.StatusNotImplemented) {
+		return nil, ErrAPIKeyRotationUnsupported
+	}
+	if err != nil {
+		return nil, err
+	}
+	if key := strings.TrimSpace(out.APIKey); key != "" {
+		c.SetTokenSource(awid.StaticToken(key))
+	}
+	return &out, nil
+}
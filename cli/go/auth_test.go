@@ -0,0 +1,96 @@
+package aweb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/awebai/aw/awid"
+)
+
+func TestRotateAPIKey(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/v1/auth/rotate" {
+			t.Fatalf("unexpected %s %s", r.Method, r.URL.Path)
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"api_key":                 "aw_sk_new",
+			"previous_key_expires_at": "2026-10-16T12:05:00Z",
+			"grace_seconds":           300,
+		})
+	}))
+	t.Cleanup(server.Close)
+
+	c, err := New(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := c.RotateAPIKey(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.APIKey != "aw_sk_new" {
+		t.Fatalf("api_key=%q", resp.APIKey)
+	}
+	if resp.PreviousKeyExpiresAt != "2026-10-16T12:05:00Z" {
+		t.Fatalf("previous_key_expires_at=%q", resp.PreviousKeyExpiresAt)
+	}
+	if resp.GraceSeconds != 300 {
+		t.Fatalf("grace_seconds=%d", resp.GraceSeconds)
+	}
+}
+
+func TestRotateAPIKeySwitchesToNewKey(t *testing.T) {
+	t.Parallel()
+
+	var authHeaders []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authHeaders = append(authHeaders, r.Header.Get("Authorization"))
+		if r.URL.Path == "/v1/auth/rotate" {
+			_ = json.NewEncoder(w).Encode(map[string]any{"api_key": "aw_sk_new"})
+			return
+		}
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	t.Cleanup(server.Close)
+
+	c, err := New(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.SetTokenSource(awid.StaticToken("aw_sk_old"))
+	if _, err := c.RotateAPIKey(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.ClaimsList(context.Background(), "", 0); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"Bearer aw_sk_old", "Bearer aw_sk_new"}
+	if len(authHeaders) != 2 || authHeaders[0] != want[0] || authHeaders[1] != want[1] {
+		t.Fatalf("Authorization headers=%q, want %q", authHeaders, want)
+	}
+}
+
+func TestRotateAPIKeyUnsupportedServer(t *testing.T) {
+	t.Parallel()
+
+	for _, status := range []int{http.StatusNotFound, http.StatusMethodNotAllowed, http.StatusNotImplemented} {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, `{"detail":"no"}`, status)
+		}))
+		c, err := New(server.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = c.RotateAPIKey(context.Background())
+		server.Close()
+		if !errors.Is(err, ErrAPIKeyRotationUnsupported) {
+			t.Fatalf("status %d: err=%v, want ErrAPIKeyRotationUnsupported", status, err)
+		}
+	}
+}
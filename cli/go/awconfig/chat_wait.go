@@ -0,0 +1,36 @@
+package awconfig
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// ChatWaitEnvVar names the environment variable that overrides the default
+// chat reply wait, in seconds.
+const ChatWaitEnvVar = "AWEB_CHAT_WAIT"
+
+// ResolveChatWait returns how many seconds a chat command should wait for a
+// reply. The first of these that is set wins:
+//
+//  1. the --wait flag (flagSet reports whether it was given)
+//  2. the AWEB_CHAT_WAIT environment variable
+//  3. default_chat_wait_seconds in .aw/workspace.yaml (via sel)
+//  4. flagWait, the flag's built-in default
+func ResolveChatWait(sel *Selection, flagWait int, flagSet bool) (int, error) {
+	if flagSet {
+		return flagWait, nil
+	}
+	if v := strings.TrimSpace(os.Getenv(ChatWaitEnvVar)); v != "" {
+		wait, err := strconv.Atoi(v)
+		if err != nil || wait < 0 {
+			return 0, fmt.Errorf("invalid %s %q: want a non-negative number of seconds", ChatWaitEnvVar, v)
+		}
+		return wait, nil
+	}
+	if sel != nil && sel.DefaultChatWaitSeconds > 0 {
+		return sel.DefaultChatWaitSeconds, nil
+	}
+	return flagWait, nil
+}
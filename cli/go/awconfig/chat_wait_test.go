@@ -0,0 +1,47 @@
+package awconfig
+
+import (
+	"testing"
+)
+
+func TestResolveChatWaitPrecedence(t *testing.T) {
+	configured := &Selection{DefaultChatWaitSeconds: 300}
+
+	tests := []struct {
+		name    string
+		env     string
+		sel     *Selection
+		flag    int
+		flagSet bool
+		want    int
+	}{
+		{name: "built-in default", sel: &Selection{}, flag: 120, want: 120},
+		{name: "config", sel: configured, flag: 120, want: 300},
+		{name: "env beats config", env: "45", sel: configured, flag: 120, want: 45},
+		{name: "flag beats env and config", env: "45", sel: configured, flag: 10, flagSet: true, want: 10},
+		{name: "explicit zero flag", env: "45", sel: configured, flag: 0, flagSet: true, want: 0},
+		{name: "env zero", env: "0", sel: configured, flag: 120, want: 0},
+		{name: "nil selection", flag: 120, want: 120},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv(ChatWaitEnvVar, tc.env)
+			got, err := ResolveChatWait(tc.sel, tc.flag, tc.flagSet)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tc.want {
+				t.Fatalf("wait=%d, want %d", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestResolveChatWaitRejectsInvalidEnv(t *testing.T) {
+	for _, value := range []string{"soon", "-5", "1.5"} {
+		t.Setenv(ChatWaitEnvVar, value)
+		if _, err := ResolveChatWait(nil, 120, false); err == nil {
+			t.Fatalf("expected error for %s=%q", ChatWaitEnvVar, value)
+		}
+	}
+}
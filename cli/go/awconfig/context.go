@@ -2,6 +2,7 @@ package awconfig
 
 import (
 	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
@@ -9,6 +10,12 @@ import (
 	"gopkg.in/yaml.v3"
 )
 
+// WorktreeContext holds per-worktree preferences stored in .aw/context.
+//
+// HumanAccount names the team membership (by team_id) that a human
+// operator acts through in this worktree. It is only consulted when
+// ResolveOptions.UseHumanAccount is set, so agents sharing the worktree
+// keep resolving to the active team.
 type WorktreeContext struct {
 	HumanAccount string `yaml:"human_account,omitempty"`
 }
@@ -32,7 +39,7 @@ func LoadWorktreeContextFrom(path string) (*WorktreeContext, error) {
 	}
 	var ctx WorktreeContext
 	if err := yaml.Unmarshal(data, &ctx); err != nil {
-		return nil, err
+		return nil, fmt.Errorf("parse %s: %w", path, err)
 	}
 	return &ctx, nil
 }
@@ -0,0 +1,81 @@
+package awconfig
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Credentials holds API keys obtained by `aw login`, keyed by server URL.
+// `aw init` uses the key for its server when AWEB_API_KEY is not set.
+type Credentials struct {
+	Servers map[string]ServerCredential `yaml:"servers,omitempty"`
+}
+
+// ServerCredential is the API key stored for one server.
+type ServerCredential struct {
+	APIKey     string `yaml:"api_key"`
+	ObtainedAt string `yaml:"obtained_at,omitempty"`
+}
+
+func DefaultCredentialsPath() (string, error) {
+	return PathInUserState("credentials.yaml")
+}
+
+// LoadCredentialsFrom reads path. A missing file yields empty credentials.
+func LoadCredentialsFrom(path string) (*Credentials, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return &Credentials{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var creds Credentials
+	if err := yaml.Unmarshal(data, &creds); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return &creds, nil
+}
+
+// UpdateCredentialsAt applies fn to the credentials at path under an
+// exclusive lock and writes the result with owner-only permissions.
+func UpdateCredentialsAt(path string, fn func(*Credentials) error) error {
+	lock, err := LockExclusive(path + ".lock")
+	if err != nil {
+		return err
+	}
+	defer lock.Close()
+
+	creds, err := LoadCredentialsFrom(path)
+	if err != nil {
+		return err
+	}
+	if err := fn(creds); err != nil {
+		return err
+	}
+	data, err := yaml.Marshal(creds)
+	if err != nil {
+		return err
+	}
+	return atomicWriteFile(path, data)
+}
+
+// APIKeyFor returns the stored API key for serverURL, or "".
+func (c *Credentials) APIKeyFor(serverURL string) string {
+	if c == nil {
+		return ""
+	}
+	return strings.TrimSpace(c.Servers[strings.TrimSpace(serverURL)].APIKey)
+}
+
+// SetAPIKey stores apiKey for serverURL.
+func (c *Credentials) SetAPIKey(serverURL, apiKey, obtainedAt string) {
+	if c.Servers == nil {
+		c.Servers = map[string]ServerCredential{}
+	}
+	c.Servers[strings.TrimSpace(serverURL)] = ServerCredential{APIKey: strings.TrimSpace(apiKey), ObtainedAt: obtainedAt}
+}
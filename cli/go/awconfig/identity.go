@@ -2,6 +2,7 @@ package awconfig
 
 import (
 	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
@@ -63,7 +64,7 @@ func LoadWorktreeIdentityFrom(path string) (*WorktreeIdentity, error) {
 	}
 	var state WorktreeIdentity
 	if err := yaml.Unmarshal(data, &state); err != nil {
-		return nil, err
+		return nil, fmt.Errorf("parse %s: %w", path, err)
 	}
 	return &state, nil
 }
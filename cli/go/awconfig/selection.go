@@ -42,6 +42,17 @@ type Selection struct {
 	Custody     string
 	Lifetime    string
 	RegistryURL string
+
+	// DefaultChatWaitSeconds is default_chat_wait_seconds from
+	// workspace.yaml, or 0 when unset.
+	DefaultChatWaitSeconds int
+
+	// ReservationPrefix is reservation_prefix from workspace.yaml.
+	ReservationPrefix string
+
+	// CACert is the absolute path of ca_cert from workspace.yaml, or ""
+	// when unset.
+	CACert string
 }
 
 type ResolveOptions struct {
@@ -53,7 +64,18 @@ type ResolveOptions struct {
 
 	TeamIDOverride string
 
+	// UseHumanAccount selects the membership named by human_account in
+	// .aw/context instead of the active team. An explicit TeamIDOverride
+	// still wins.
+	UseHumanAccount bool
+
+	// AllowEnvOverrides lets AWEB_URL replace the workspace aweb_url and
+	// BaseURLOverride.
 	AllowEnvOverrides bool
+
+	// Logf, when set, is told which values came from the environment
+	// because an override won.
+	Logf func(format string, args ...any)
 }
 
 func ResolveWorkspace(opts ResolveOptions) (*Selection, error) {
@@ -67,9 +89,11 @@ func ResolveWorkspace(opts ResolveOptions) (*Selection, error) {
 	}
 
 	overrideBaseURL := strings.TrimSpace(opts.BaseURLOverride)
+	envBaseURL := ""
 	if opts.AllowEnvOverrides {
 		if v := strings.TrimSpace(os.Getenv("AWEB_URL")); v != "" {
 			overrideBaseURL = v
+			envBaseURL = v
 		}
 	}
 
@@ -93,10 +117,20 @@ func ResolveWorkspace(opts ResolveOptions) (*Selection, error) {
 	}
 
 	baseURL := strings.TrimSpace(workspace.AwebURL)
+	if envBaseURL != "" && opts.Logf != nil {
+		opts.Logf("AWEB_URL=%s overrides aweb_url %s from %s", envBaseURL, baseURL, DefaultWorktreeWorkspaceRelativePath())
+	}
 	if overrideBaseURL != "" {
 		baseURL = overrideBaseURL
 	}
 	selectedTeamID := strings.TrimSpace(opts.TeamIDOverride)
+	if selectedTeamID == "" && opts.UseHumanAccount {
+		humanTeamID, err := resolveHumanAccountTeamID(rootDir)
+		if err != nil {
+			return nil, err
+		}
+		selectedTeamID = humanTeamID
+	}
 	activeMembership := ActiveMembershipFor(workspace, teamState)
 	selectedMembership := activeMembership
 	if selectedTeamID != "" {
@@ -134,6 +168,21 @@ func ResolveWorkspace(opts ResolveOptions) (*Selection, error) {
 	return finalizeWorkspaceSelection(rootDir, workspacePath, serverName, baseURL, workspace, teamState, identity, teamID)
 }
 
+func resolveHumanAccountTeamID(rootDir string) (string, error) {
+	ctx, path, err := LoadWorktreeContextFromDir(rootDir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "", errors.New("no human account configured: .aw/context is missing; set human_account to a team_id from this workspace")
+		}
+		return "", fmt.Errorf("invalid worktree context: %w", err)
+	}
+	teamID := strings.TrimSpace(ctx.HumanAccount)
+	if teamID == "" {
+		return "", fmt.Errorf("no human account configured: set human_account in %s to a team_id from this workspace", path)
+	}
+	return teamID, nil
+}
+
 func finalizeWorkspaceSelection(workingDir, workspacePath, serverName, baseURL string, ws *WorktreeWorkspace, ts *TeamState, identity *WorktreeIdentity, selectedTeamID string) (*Selection, error) {
 	domain := ""
 	alias := ""
@@ -147,6 +196,9 @@ func finalizeWorkspaceSelection(workingDir, workspacePath, serverName, baseURL s
 	lifetime := ""
 	registryURL := ""
 	awebURL := ""
+	defaultChatWait := 0
+	reservationPrefix := ""
+	caCert := ""
 	if ws != nil {
 		selectedMembership := ws.Membership(selectedTeamID)
 		if selectedMembership == nil {
@@ -168,6 +220,11 @@ func finalizeWorkspaceSelection(workingDir, workspacePath, serverName, baseURL s
 			}
 		}
 		awebURL = strings.TrimSpace(ws.AwebURL)
+		defaultChatWait = ws.DefaultChatWaitSeconds
+		reservationPrefix = strings.TrimSpace(ws.ReservationPrefix)
+		if caCert = strings.TrimSpace(ws.CACert); caCert != "" && !filepath.IsAbs(caCert) {
+			caCert = filepath.Join(workingDir, filepath.FromSlash(caCert))
+		}
 	}
 	if identity != nil {
 		if v := strings.TrimSpace(identity.Address); v != "" && address == "" {
@@ -219,6 +276,10 @@ func finalizeWorkspaceSelection(workingDir, workspacePath, serverName, baseURL s
 		Custody:       custody,
 		Lifetime:      lifetime,
 		RegistryURL:   registryURL,
+
+		DefaultChatWaitSeconds: defaultChatWait,
+		ReservationPrefix:      reservationPrefix,
+		CACert:                 caCert,
 	}, nil
 }
 
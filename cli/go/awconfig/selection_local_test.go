@@ -1,6 +1,7 @@
 package awconfig
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
@@ -266,6 +267,75 @@ func TestResolveDerivesWorkspaceIdentityFromCanonicalBinding(t *testing.T) {
 	}
 }
 
+func TestResolveWorkspaceCarriesDefaultChatWaitReservationPrefixAndCACert(t *testing.T) {
+	t.Parallel()
+
+	tmp := t.TempDir()
+	saveWorkspaceAndTeamStateForSelectionTest(t, tmp, "backend:acme.com", &WorktreeWorkspace{
+		AwebURL: "https://app.aweb.ai",
+		Memberships: []WorktreeMembership{{
+			TeamID:   "backend:acme.com",
+			Alias:    "alice",
+			CertPath: TeamCertificateRelativePath("backend:acme.com"),
+		}},
+		DefaultChatWaitSeconds: 300,
+		ReservationPrefix:      "frontend/",
+		CACert:                 "certs/ca.pem",
+	})
+
+	sel, err := ResolveWorkspace(ResolveOptions{WorkingDir: tmp})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sel.DefaultChatWaitSeconds != 300 {
+		t.Fatalf("default_chat_wait_seconds=%d", sel.DefaultChatWaitSeconds)
+	}
+	if sel.ReservationPrefix != "frontend/" {
+		t.Fatalf("reservation_prefix=%q", sel.ReservationPrefix)
+	}
+	if want := filepath.Join(sel.WorkingDir, "certs", "ca.pem"); sel.CACert != want {
+		t.Fatalf("ca_cert=%q, want %q relative to the worktree root", sel.CACert, want)
+	}
+}
+
+func TestResolveWorkspaceEnvOverrideIsOptionalAndLogged(t *testing.T) {
+	// Cannot use t.Parallel() — sets AWEB_URL.
+	t.Setenv("AWEB_URL", "https://other.example.com")
+
+	tmp := t.TempDir()
+	saveWorkspaceAndTeamStateForSelectionTest(t, tmp, "backend:acme.com", &WorktreeWorkspace{
+		AwebURL: "https://app.aweb.ai",
+		Memberships: []WorktreeMembership{{
+			TeamID:   "backend:acme.com",
+			Alias:    "alice",
+			CertPath: TeamCertificateRelativePath("backend:acme.com"),
+		}},
+	})
+
+	var logged []string
+	logf := func(format string, args ...any) { logged = append(logged, fmt.Sprintf(format, args...)) }
+
+	sel, err := ResolveWorkspace(ResolveOptions{WorkingDir: tmp, AllowEnvOverrides: true, Logf: logf})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sel.BaseURL != "https://other.example.com" {
+		t.Fatalf("base_url=%q, want AWEB_URL", sel.BaseURL)
+	}
+	if len(logged) != 1 || !strings.Contains(logged[0], "AWEB_URL=https://other.example.com") || !strings.Contains(logged[0], "https://app.aweb.ai") {
+		t.Fatalf("logged=%q", logged)
+	}
+
+	logged = nil
+	sel, err = ResolveWorkspace(ResolveOptions{WorkingDir: tmp, Logf: logf})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sel.BaseURL != "https://app.aweb.ai" || len(logged) != 0 {
+		t.Fatalf("without env overrides base_url=%q logged=%q", sel.BaseURL, logged)
+	}
+}
+
 func TestResolveWorkspaceRejectsUnknownTeamOverrideWithAvailableMemberships(t *testing.T) {
 	t.Parallel()
 
@@ -298,3 +368,100 @@ func TestResolveWorkspaceRejectsUnknownTeamOverrideWithAvailableMemberships(t *t
 		t.Fatalf("error=%q", got)
 	}
 }
+
+func saveTwoTeamWorkspaceForHumanAccountTest(t *testing.T, root string) {
+	t.Helper()
+	saveWorkspaceAndTeamStateForSelectionTest(t, root, "backend:acme.com", &WorktreeWorkspace{
+		AwebURL: "https://app.aweb.ai",
+		Memberships: []WorktreeMembership{
+			{
+				TeamID:      "backend:acme.com",
+				Alias:       "builder",
+				WorkspaceID: "workspace-agent",
+				CertPath:    TeamCertificateRelativePath("backend:acme.com"),
+			},
+			{
+				TeamID:      "review:acme.com",
+				Alias:       "juan",
+				WorkspaceID: "workspace-human",
+				CertPath:    TeamCertificateRelativePath("review:acme.com"),
+			},
+		},
+	})
+}
+
+func TestResolveWorkspaceUseHumanAccountSelectsContextMembership(t *testing.T) {
+	t.Parallel()
+
+	tmp := t.TempDir()
+	saveTwoTeamWorkspaceForHumanAccountTest(t, tmp)
+	if err := SaveWorktreeContextTo(filepath.Join(tmp, ".aw", "context"), &WorktreeContext{HumanAccount: "review:acme.com"}); err != nil {
+		t.Fatal(err)
+	}
+
+	sel, err := ResolveWorkspace(ResolveOptions{WorkingDir: tmp})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sel.TeamID != "backend:acme.com" || sel.Alias != "builder" {
+		t.Fatalf("default selection team=%q alias=%q", sel.TeamID, sel.Alias)
+	}
+
+	sel, err = ResolveWorkspace(ResolveOptions{WorkingDir: tmp, UseHumanAccount: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sel.TeamID != "review:acme.com" || sel.Alias != "juan" || sel.WorkspaceID != "workspace-human" {
+		t.Fatalf("human selection team=%q alias=%q workspace=%q", sel.TeamID, sel.Alias, sel.WorkspaceID)
+	}
+}
+
+func TestResolveWorkspaceExplicitTeamOverridesHumanAccount(t *testing.T) {
+	t.Parallel()
+
+	tmp := t.TempDir()
+	saveTwoTeamWorkspaceForHumanAccountTest(t, tmp)
+	if err := SaveWorktreeContextTo(filepath.Join(tmp, ".aw", "context"), &WorktreeContext{HumanAccount: "review:acme.com"}); err != nil {
+		t.Fatal(err)
+	}
+
+	sel, err := ResolveWorkspace(ResolveOptions{
+		WorkingDir:      tmp,
+		TeamIDOverride:  "backend:acme.com",
+		UseHumanAccount: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sel.TeamID != "backend:acme.com" || sel.Alias != "builder" {
+		t.Fatalf("selection team=%q alias=%q", sel.TeamID, sel.Alias)
+	}
+}
+
+func TestResolveWorkspaceUseHumanAccountRequiresContextValue(t *testing.T) {
+	t.Parallel()
+
+	tmp := t.TempDir()
+	saveTwoTeamWorkspaceForHumanAccountTest(t, tmp)
+
+	_, err := ResolveWorkspace(ResolveOptions{WorkingDir: tmp, UseHumanAccount: true})
+	if err == nil || !strings.Contains(err.Error(), ".aw/context is missing") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := SaveWorktreeContextTo(filepath.Join(tmp, ".aw", "context"), &WorktreeContext{}); err != nil {
+		t.Fatal(err)
+	}
+	_, err = ResolveWorkspace(ResolveOptions{WorkingDir: tmp, UseHumanAccount: true})
+	if err == nil || !strings.Contains(err.Error(), "set human_account") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := SaveWorktreeContextTo(filepath.Join(tmp, ".aw", "context"), &WorktreeContext{HumanAccount: "ops:acme.com"}); err != nil {
+		t.Fatal(err)
+	}
+	_, err = ResolveWorkspace(ResolveOptions{WorkingDir: tmp, UseHumanAccount: true})
+	if err == nil || !strings.Contains(err.Error(), `team "ops:acme.com" is not present`) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
@@ -38,6 +38,12 @@ func DefaultControllersDir() (string, error) {
 	return PathInUserState("controllers")
 }
 
+// DefaultMailQueueDir is where mail that could not reach the server is
+// spooled until `aw mail flush` retries it.
+func DefaultMailQueueDir() (string, error) {
+	return PathInUserState("mail-queue")
+}
+
 // atomicWriteFile writes data to path using temp-file-and-rename
 // with 0600 permissions (suitable for secrets).
 func atomicWriteFile(path string, data []byte) error {
@@ -245,7 +245,8 @@ func TeamStatePath(root string) string {
 }
 
 func LoadTeamState(workingDir string) (*TeamState, error) {
-	data, err := os.ReadFile(TeamStatePath(workingDir))
+	path := TeamStatePath(workingDir)
+	data, err := os.ReadFile(path)
 	if err != nil {
 		if !os.IsNotExist(err) {
 			return nil, err
@@ -254,7 +255,7 @@ func LoadTeamState(workingDir string) (*TeamState, error) {
 	}
 	var state TeamState
 	if err := yaml.Unmarshal(data, &state); err != nil {
-		return nil, err
+		return nil, fmt.Errorf("parse %s: %w", path, err)
 	}
 	return &state, nil
 }
@@ -322,7 +323,7 @@ func loadLegacyWorkspaceActiveTeam(path string) (string, error) {
 	}
 	var raw worktreeWorkspaceYAML
 	if err := yaml.Unmarshal(data, &raw); err != nil {
-		return "", err
+		return "", fmt.Errorf("parse %s: %w", path, err)
 	}
 	return strings.TrimSpace(raw.ActiveTeam), nil
 }
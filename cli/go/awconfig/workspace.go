@@ -31,6 +31,19 @@ type WorktreeWorkspace struct {
 	Hostname        string               `yaml:"hostname,omitempty"`
 	WorkspacePath   string               `yaml:"workspace_path,omitempty"`
 	UpdatedAt       string               `yaml:"updated_at,omitempty"`
+
+	// DefaultChatWaitSeconds overrides the built-in chat reply wait for this
+	// worktree; 0 keeps the built-in default. See ResolveChatWait.
+	DefaultChatWaitSeconds int `yaml:"default_chat_wait_seconds,omitempty"`
+
+	// ReservationPrefix is prepended to lock resource keys, so a sub-team
+	// sharing a project gets its own lock namespace (e.g. "frontend/").
+	ReservationPrefix string `yaml:"reservation_prefix,omitempty"`
+
+	// CACert is a PEM file of extra certificate authorities to trust for
+	// aweb_url, for self-hosted servers with a private CA. A relative path
+	// is relative to the worktree root.
+	CACert string `yaml:"ca_cert,omitempty"`
 }
 
 type worktreeMembershipYAML struct {
@@ -54,6 +67,10 @@ type worktreeWorkspaceYAML struct {
 	Hostname        string                   `yaml:"hostname,omitempty"`
 	WorkspacePath   string                   `yaml:"workspace_path,omitempty"`
 	UpdatedAt       string                   `yaml:"updated_at,omitempty"`
+
+	DefaultChatWaitSeconds int    `yaml:"default_chat_wait_seconds,omitempty"`
+	ReservationPrefix      string `yaml:"reservation_prefix,omitempty"`
+	CACert                 string `yaml:"ca_cert,omitempty"`
 }
 
 type LegacySingleTeamWorkspace struct {
@@ -89,6 +106,10 @@ var canonicalWorkspaceYAMLKeys = map[string]struct{}{
 	"hostname":         {},
 	"workspace_path":   {},
 	"updated_at":       {},
+
+	"default_chat_wait_seconds": {},
+	"reservation_prefix":        {},
+	"ca_cert":                   {},
 }
 
 var canonicalMembershipYAMLKeys = map[string]struct{}{
@@ -148,6 +169,8 @@ func (w *WorktreeWorkspace) normalize() {
 	w.Hostname = strings.TrimSpace(w.Hostname)
 	w.WorkspacePath = strings.TrimSpace(w.WorkspacePath)
 	w.UpdatedAt = strings.TrimSpace(w.UpdatedAt)
+	w.ReservationPrefix = strings.TrimSpace(w.ReservationPrefix)
+	w.CACert = strings.TrimSpace(w.CACert)
 	normalized := make([]WorktreeMembership, 0, len(w.Memberships))
 	for _, membership := range w.Memberships {
 		membership.normalize()
@@ -218,6 +241,12 @@ func (w *WorktreeWorkspace) validate() error {
 	if len(w.Memberships) == 0 {
 		return errors.New("workspace.yaml must contain at least one membership")
 	}
+	if w.DefaultChatWaitSeconds < 0 {
+		return errors.New("workspace.yaml default_chat_wait_seconds must not be negative")
+	}
+	if strings.HasPrefix(w.ReservationPrefix, "/") {
+		return errors.New("workspace.yaml reservation_prefix must not start with /")
+	}
 	seen := make(map[string]struct{}, len(w.Memberships))
 	for _, membership := range w.Memberships {
 		if membership.TeamID == "" {
@@ -352,6 +381,10 @@ func (w *WorktreeWorkspace) UnmarshalYAML(value *yaml.Node) error {
 		Hostname:        raw.Hostname,
 		WorkspacePath:   raw.WorkspacePath,
 		UpdatedAt:       raw.UpdatedAt,
+
+		DefaultChatWaitSeconds: raw.DefaultChatWaitSeconds,
+		ReservationPrefix:      raw.ReservationPrefix,
+		CACert:                 raw.CACert,
 	}
 	w.normalize()
 	return w.validate()
@@ -384,6 +417,10 @@ func (w WorktreeWorkspace) MarshalYAML() (any, error) {
 		Hostname:        w.Hostname,
 		WorkspacePath:   w.WorkspacePath,
 		UpdatedAt:       w.UpdatedAt,
+
+		DefaultChatWaitSeconds: w.DefaultChatWaitSeconds,
+		ReservationPrefix:      w.ReservationPrefix,
+		CACert:                 w.CACert,
 	}, nil
 }
 
@@ -406,7 +443,7 @@ func LoadWorktreeWorkspaceFrom(path string) (*WorktreeWorkspace, error) {
 	}
 	var state WorktreeWorkspace
 	if err := yaml.Unmarshal(data, &state); err != nil {
-		return nil, err
+		return nil, fmt.Errorf("parse %s: %w", path, err)
 	}
 	return &state, nil
 }
@@ -418,7 +455,7 @@ func LoadLegacySingleTeamWorkspaceFrom(path string) (*LegacySingleTeamWorkspace,
 	}
 	var state LegacySingleTeamWorkspace
 	if err := yaml.Unmarshal(data, &state); err != nil {
-		return nil, err
+		return nil, fmt.Errorf("parse %s: %w", path, err)
 	}
 	state.AwebURL = strings.TrimSpace(state.AwebURL)
 	state.TeamID = strings.TrimSpace(state.TeamID)
@@ -459,7 +496,7 @@ func SaveWorktreeWorkspaceTo(path string, state *WorktreeWorkspace) error {
 		return err
 	}
 
-	data, err := yaml.Marshal(state)
+	data, err := marshalKeepingComments(path, state)
 	if err != nil {
 		return err
 	}
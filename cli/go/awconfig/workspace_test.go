@@ -48,6 +48,24 @@ memberships:
 	}
 }
 
+func TestLoadWorktreeWorkspaceFromReportsPathAndLineOnSyntaxError(t *testing.T) {
+	t.Parallel()
+
+	tmp := t.TempDir()
+	path := filepath.Join(tmp, "workspace.yaml")
+	if err := os.WriteFile(path, []byte("workspace_id: ws-1\nalias: alice\naweb_url: [unclosed\n"), 0o600); err != nil {
+		t.Fatalf("write workspace: %v", err)
+	}
+
+	_, err := LoadWorktreeWorkspaceFrom(path)
+	if err == nil {
+		t.Fatal("expected malformed yaml to fail")
+	}
+	if !strings.Contains(err.Error(), path) || !strings.Contains(err.Error(), "line ") {
+		t.Fatalf("error should name the file and line: %v", err)
+	}
+}
+
 func TestLoadWorktreeWorkspaceFromRejectsLegacyRoleKey(t *testing.T) {
 	t.Parallel()
 
@@ -95,6 +113,62 @@ func TestSaveWorktreeWorkspaceToWritesCanonicalRoleNameKey(t *testing.T) {
 	}
 }
 
+func TestSaveWorktreeWorkspaceToKeepsCommentsAndKeyOrder(t *testing.T) {
+	t.Parallel()
+
+	tmp := t.TempDir()
+	path := filepath.Join(tmp, "workspace.yaml")
+	if err := os.WriteFile(path, []byte(strings.TrimSpace(`
+# Shared dev server; see the team wiki.
+api_key: aw_sk_workspace # rotate quarterly
+aweb_url: https://app.aweb.ai
+memberships:
+  - team_id: backend:acme.com
+    alias: alice
+    role_name: developer
+    workspace_id: ws-1
+    cert_path: team-certs/backend__acme.com.pem
+    joined_at: "2026-04-09T00:00:00Z"
+`)+"\n"), 0o600); err != nil {
+		t.Fatalf("write workspace: %v", err)
+	}
+
+	state, err := LoadWorktreeWorkspaceFrom(path)
+	if err != nil {
+		t.Fatalf("load workspace: %v", err)
+	}
+	state.AwebURL = "https://staging.aweb.ai"
+	state.ReservationPrefix = "frontend/"
+	if err := SaveWorktreeWorkspaceTo(path, state); err != nil {
+		t.Fatalf("save workspace: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read workspace: %v", err)
+	}
+	text := string(data)
+	for _, want := range []string{"# Shared dev server; see the team wiki.\napi_key:", "# rotate quarterly", "aweb_url: https://staging.aweb.ai", "reservation_prefix: frontend/"} {
+		if !strings.Contains(text, want) {
+			t.Fatalf("workspace yaml missing %q:\n%s", want, text)
+		}
+	}
+	if strings.Index(text, "api_key:") > strings.Index(text, "aweb_url:") {
+		t.Fatalf("existing key order was not kept:\n%s", text)
+	}
+	if strings.Index(text, "reservation_prefix:") < strings.Index(text, "memberships:") {
+		t.Fatalf("new key should be appended after existing ones:\n%s", text)
+	}
+
+	reloaded, err := LoadWorktreeWorkspaceFrom(path)
+	if err != nil {
+		t.Fatalf("reload workspace: %v", err)
+	}
+	if reloaded.AwebURL != "https://staging.aweb.ai" || reloaded.ReservationPrefix != "frontend/" || len(reloaded.Memberships) != 1 {
+		t.Fatalf("reloaded=%+v", reloaded)
+	}
+}
+
 func TestSaveWorktreeWorkspaceToWrites0600(t *testing.T) {
 	t.Parallel()
 
@@ -0,0 +1,72 @@
+package awconfig
+
+import (
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// marshalKeepingComments marshals v like yaml.Marshal, but when path already
+// holds a YAML mapping the result keeps that file's comments and key order:
+// existing keys are updated in place, keys v no longer has are dropped, and
+// new keys are appended in the order v marshals them. Users can annotate the
+// file and keep it under version control without every save reshuffling or
+// stripping it. An unreadable or unparsable file is simply replaced.
+func marshalKeepingComments(path string, v any) ([]byte, error) {
+	var next yaml.Node
+	if err := next.Encode(v); err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return yaml.Marshal(&next)
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil || doc.Kind != yaml.DocumentNode || len(doc.Content) != 1 {
+		return yaml.Marshal(&next)
+	}
+	if doc.Content[0].Kind != yaml.MappingNode || next.Kind != yaml.MappingNode {
+		return yaml.Marshal(&next)
+	}
+	doc.Content[0] = mergeYAMLMapping(doc.Content[0], &next)
+	return yaml.Marshal(&doc)
+}
+
+// mergeYAMLMapping returns prev with its entries replaced by next's. Both
+// must be mapping nodes.
+func mergeYAMLMapping(prev, next *yaml.Node) *yaml.Node {
+	nextValues := make(map[string]*yaml.Node, len(next.Content)/2)
+	for i := 0; i+1 < len(next.Content); i += 2 {
+		nextValues[next.Content[i].Value] = next.Content[i+1]
+	}
+	seen := make(map[string]bool, len(nextValues))
+	content := make([]*yaml.Node, 0, len(next.Content))
+	for i := 0; i+1 < len(prev.Content); i += 2 {
+		key := prev.Content[i]
+		value, ok := nextValues[key.Value]
+		if !ok || seen[key.Value] {
+			continue
+		}
+		seen[key.Value] = true
+		content = append(content, key, mergeYAMLValue(prev.Content[i+1], value))
+	}
+	for i := 0; i+1 < len(next.Content); i += 2 {
+		if !seen[next.Content[i].Value] {
+			content = append(content, next.Content[i], next.Content[i+1])
+		}
+	}
+	prev.Content = content
+	return prev
+}
+
+func mergeYAMLValue(prev, next *yaml.Node) *yaml.Node {
+	if prev.Kind == yaml.MappingNode && next.Kind == yaml.MappingNode {
+		return mergeYAMLMapping(prev, next)
+	}
+	if next.HeadComment == "" && next.LineComment == "" && next.FootComment == "" {
+		next.HeadComment = prev.HeadComment
+		next.LineComment = prev.LineComment
+		next.FootComment = prev.FootComment
+	}
+	return next
+}
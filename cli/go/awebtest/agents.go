@@ -0,0 +1,156 @@
+package awebtest
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/awebai/aw/awid"
+)
+
+// connectRequest mirrors the body aw init sends to POST /v1/connect.
+type connectRequest struct {
+	Hostname      string         `json:"hostname"`
+	WorkspacePath string         `json:"workspace_path"`
+	RepoOrigin    string         `json:"repo_origin"`
+	Role          string         `json:"role"`
+	HumanName     string         `json:"human_name"`
+	AgentType     string         `json:"agent_type"`
+	Metadata      map[string]any `json:"metadata"`
+}
+
+type connectResponse struct {
+	TeamID      string `json:"team_id"`
+	Alias       string `json:"alias"`
+	AgentID     string `json:"agent_id"`
+	WorkspaceID string `json:"workspace_id"`
+	RepoID      string `json:"repo_id"`
+	TeamDIDKey  string `json:"team_did_key"`
+	Role        string `json:"role"`
+}
+
+// handleConnect provisions the certificate's agent on first use, like the
+// real server, so certificates from other team keys are accepted too.
+func (s *FakeServer) handleConnect(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cert, err := requestCertificate(r)
+	if err != nil {
+		writeDetail(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+	var req connectRequest
+	if !decodeBody(w, r, &req) {
+		return
+	}
+	me := s.agentByDIDKey(cert.MemberDIDKey)
+	if me == nil {
+		if other := s.agentByAlias(cert.Alias); other != nil {
+			writeDetail(w, http.StatusConflict, "alias "+cert.Alias+" is already in use by another active agent")
+			return
+		}
+		me = &agent{id: s.newID(), alias: cert.Alias, didKey: cert.MemberDIDKey, cert: cert}
+		s.agents = append(s.agents, me)
+	} else if me.alias != cert.Alias {
+		writeDetail(w, http.StatusConflict, "did_key is already bound to alias "+me.alias)
+		return
+	}
+	me.humanName = strings.TrimSpace(req.HumanName)
+	me.agentType = firstNonEmpty(strings.TrimSpace(req.AgentType), "agent")
+	me.role = strings.TrimSpace(req.Role)
+	if len(req.Metadata) > 0 {
+		me.metadata = req.Metadata
+	}
+	me.lastSeen = s.now()
+	writeJSON(w, http.StatusOK, connectResponse{
+		TeamID:      TeamID,
+		Alias:       me.alias,
+		AgentID:     me.id,
+		WorkspaceID: me.id,
+		TeamDIDKey:  s.teamDID,
+		Role:        me.role,
+	})
+}
+
+func (s *FakeServer) agentView(a *agent) awid.AgentView {
+	view := awid.AgentView{
+		AgentID:   a.id,
+		Alias:     a.alias,
+		DIDKey:    a.didKey,
+		HumanName: a.humanName,
+		AgentType: a.agentType,
+		Role:      a.role,
+		Status:    "offline",
+		Lifetime:  awid.LifetimeEphemeral,
+		Metadata:  a.metadata,
+	}
+	if a.cert != nil {
+		view.DIDAW = a.cert.MemberDIDAW
+		view.Address = a.cert.MemberAddress
+		view.Lifetime = firstNonEmpty(a.cert.Lifetime, awid.LifetimeEphemeral)
+	}
+	if !a.lastSeen.IsZero() {
+		view.LastSeen = timestamp(a.lastSeen)
+		if s.now().Sub(a.lastSeen) < onlineWindow {
+			view.Online = true
+			view.Status = "active"
+		}
+	}
+	return view
+}
+
+func (s *FakeServer) handleListAgents(w http.ResponseWriter, r *http.Request, me *agent) {
+	views := make([]awid.AgentView, 0, len(s.agents))
+	for _, a := range s.agents {
+		views = append(views, s.agentView(a))
+	}
+	writeJSON(w, http.StatusOK, awid.ListAgentsResponse{TeamID: TeamID, Agents: views})
+}
+
+// handleCreateAgent provisions an agent with its own key; NewClient with
+// the same alias then acts as that agent.
+func (s *FakeServer) handleCreateAgent(w http.ResponseWriter, r *http.Request, me *agent) {
+	var req awid.CreateAgentRequest
+	if !decodeBody(w, r, &req) {
+		return
+	}
+	alias := strings.TrimSpace(req.Alias)
+	if alias == "" {
+		writeDetail(w, http.StatusUnprocessableEntity, "alias is required")
+		return
+	}
+	if s.agentByAlias(alias) != nil {
+		writeDetail(w, http.StatusConflict, "alias "+alias+" is already in use")
+		return
+	}
+	a, err := s.addAgent(alias)
+	if err != nil {
+		writeDetail(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	a.humanName = strings.TrimSpace(req.HumanName)
+	a.agentType = firstNonEmpty(strings.TrimSpace(req.AgentType), "agent")
+	a.metadata = req.Metadata
+	writeJSON(w, http.StatusOK, awid.CreateAgentResponse{
+		AgentID: a.id,
+		Alias:   a.alias,
+		TeamID:  TeamID,
+		APIKey:  "aw_sk_awebtest_" + strings.ReplaceAll(a.id, "-", ""),
+	})
+}
+
+func (s *FakeServer) handleHeartbeat(w http.ResponseWriter, r *http.Request, me *agent) {
+	writeJSON(w, http.StatusOK, awid.HeartbeatResponse{
+		AgentID:    me.id,
+		Alias:      me.alias,
+		LastSeenAt: timestamp(me.lastSeen),
+	})
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,374 @@
+package awebtest
+
+import (
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/awebai/aw/awid"
+)
+
+// defaultChatHistoryLimit is the history page size when the caller sets none.
+const defaultChatHistoryLimit = 100
+
+type chatSession struct {
+	id           string
+	participants []*agent // sorted by alias
+	createdAt    time.Time
+	messages     []*chatMessage
+	// read counts, per agent ID, how many leading messages the agent has
+	// read; an agent has always read its own messages.
+	read     map[string]int
+	subject  string
+	metadata map[string]any
+}
+
+type chatMessage struct {
+	awid.ChatMessage
+	fromID string
+	at     time.Time
+}
+
+func (c *chatSession) has(a *agent) bool {
+	return slices.Contains(c.participants, a)
+}
+
+// unread returns the messages after a's read mark that others sent.
+func (c *chatSession) unread(a *agent) []*chatMessage {
+	var out []*chatMessage
+	for _, m := range c.messages[c.read[a.id]:] {
+		if m.fromID != a.id {
+			out = append(out, m)
+		}
+	}
+	return out
+}
+
+func (c *chatSession) aliases() []string {
+	out := make([]string, len(c.participants))
+	for i, p := range c.participants {
+		out[i] = p.alias
+	}
+	return out
+}
+
+func (c *chatSession) dids() []string {
+	out := make([]string, len(c.participants))
+	for i, p := range c.participants {
+		out[i] = p.didKey
+	}
+	return out
+}
+
+func (c *chatSession) addresses() []string {
+	var out []string
+	for _, p := range c.participants {
+		if p.cert != nil && p.cert.MemberAddress != "" {
+			out = append(out, p.cert.MemberAddress)
+		}
+	}
+	return out
+}
+
+func (c *chatSession) lastActivity() time.Time {
+	if len(c.messages) == 0 {
+		return c.createdAt
+	}
+	return c.messages[len(c.messages)-1].at
+}
+
+// chatTargets resolves the recipients of a new chat; missing names the
+// first one that is not a team agent.
+func (s *FakeServer) chatTargets(req *awid.ChatCreateSessionRequest) (targets []*agent, missing string) {
+	for _, alias := range req.ToAliases {
+		if _, bare, ok := strings.Cut(alias, "/"); ok {
+			alias = bare
+		}
+		a := s.agentByAlias(strings.TrimSpace(alias))
+		if a == nil {
+			return nil, alias
+		}
+		targets = append(targets, a)
+	}
+	for _, address := range req.ToAddresses {
+		a := s.resolveRecipient(&awid.SendMessageRequest{ToAddress: address})
+		if a == nil {
+			return nil, address
+		}
+		targets = append(targets, a)
+	}
+	for _, did := range req.ToDIDs {
+		a := s.resolveRecipient(&awid.SendMessageRequest{ToDID: did, ToStableID: did})
+		if a == nil {
+			return nil, did
+		}
+		targets = append(targets, a)
+	}
+	return targets, ""
+}
+
+// sessionFor returns the session between exactly these agents, creating it
+// if needed, as the server reuses one session per participant set.
+func (s *FakeServer) sessionFor(members []*agent) *chatSession {
+	sort.Slice(members, func(i, j int) bool { return members[i].alias < members[j].alias })
+	members = slices.Compact(members)
+	for _, c := range s.sessions {
+		if slices.Equal(c.participants, members) {
+			return c
+		}
+	}
+	c := &chatSession{
+		id:           s.newID(),
+		participants: members,
+		createdAt:    s.now(),
+		read:         map[string]int{},
+	}
+	s.sessions = append(s.sessions, c)
+	return c
+}
+
+func (s *FakeServer) postChat(c *chatSession, me *agent, m awid.ChatMessage) *chatMessage {
+	now := s.now()
+	m.MessageID = firstNonEmpty(strings.TrimSpace(m.MessageID), s.newID())
+	m.FromAgent = me.alias
+	m.Timestamp = timestamp(now)
+	if m.Priority == "" {
+		m.Priority = awid.PriorityNormal
+	}
+	cm := &chatMessage{ChatMessage: m, fromID: me.id, at: now}
+	c.messages = append(c.messages, cm)
+	c.read[me.id] = len(c.messages)
+	return cm
+}
+
+func (s *FakeServer) handleChatCreate(w http.ResponseWriter, r *http.Request, me *agent) {
+	var req awid.ChatCreateSessionRequest
+	if !decodeBody(w, r, &req) {
+		return
+	}
+	targets, missing := s.chatTargets(&req)
+	if missing != "" {
+		writeDetail(w, http.StatusNotFound, "agent not found: "+missing)
+		return
+	}
+	if len(targets) == 0 {
+		writeDetail(w, http.StatusUnprocessableEntity, "at least one recipient is required")
+		return
+	}
+	c := s.sessionFor(append(targets, me))
+	if req.Subject != "" {
+		c.subject = req.Subject
+	}
+	if len(req.Metadata) > 0 {
+		c.metadata = req.Metadata
+	}
+	m := s.postChat(c, me, awid.ChatMessage{
+		MessageID:        req.MessageID,
+		Body:             req.Message,
+		SenderLeaving:    req.Leaving,
+		ReplyToMessageID: req.ReplyTo,
+		FromDID:          req.FromDID,
+		Signature:        req.Signature,
+		SignedPayload:    req.SignedPayload,
+		Priority:         req.Priority,
+	})
+	participants := make([]awid.ChatParticipant, len(c.participants))
+	for i, p := range c.participants {
+		participants[i] = awid.ChatParticipant{AgentID: p.id, Alias: p.alias, DID: p.didKey}
+		if p.cert != nil {
+			participants[i].Address = p.cert.MemberAddress
+		}
+	}
+	writeJSON(w, http.StatusOK, awid.ChatCreateSessionResponse{
+		SessionID:        c.id,
+		MessageID:        m.MessageID,
+		Participants:     participants,
+		SSEURL:           "/v1/chat/sessions/" + c.id + "/stream",
+		TargetsConnected: []string{},
+		TargetsLeft:      []string{},
+	})
+}
+
+// sessionForCaller finds the path's session, answering 404 when it does not
+// exist and 403 when the caller is not in it.
+func (s *FakeServer) sessionForCaller(w http.ResponseWriter, r *http.Request, me *agent) *chatSession {
+	id := r.PathValue("id")
+	for _, c := range s.sessions {
+		if c.id != id {
+			continue
+		}
+		if !c.has(me) {
+			writeDetail(w, http.StatusForbidden, "not a participant in this session")
+			return nil
+		}
+		return c
+	}
+	writeDetail(w, http.StatusNotFound, "Session not found")
+	return nil
+}
+
+func (s *FakeServer) handleChatSend(w http.ResponseWriter, r *http.Request, me *agent) {
+	c := s.sessionForCaller(w, r, me)
+	if c == nil {
+		return
+	}
+	var req awid.ChatSendMessageRequest
+	if !decodeBody(w, r, &req) {
+		return
+	}
+	m := s.postChat(c, me, awid.ChatMessage{
+		MessageID:        req.MessageID,
+		Body:             req.Body,
+		ReplyToMessageID: req.ReplyTo,
+		FromDID:          req.FromDID,
+		Signature:        req.Signature,
+		SignedPayload:    req.SignedPayload,
+		Priority:         req.Priority,
+	})
+	writeJSON(w, http.StatusOK, awid.ChatSendMessageResponse{MessageID: m.MessageID, Delivered: true})
+}
+
+// handleChatHistory returns the session's most recent messages, oldest
+// first, or with oldest_first, since or after_message_id its earliest ones.
+func (s *FakeServer) handleChatHistory(w http.ResponseWriter, r *http.Request, me *agent) {
+	c := s.sessionForCaller(w, r, me)
+	if c == nil {
+		return
+	}
+	q := r.URL.Query()
+	messages := c.messages
+	if q.Get("unread_only") == "true" {
+		messages = c.unread(me)
+	}
+	if after := q.Get("after_message_id"); after != "" {
+		for i, m := range messages {
+			if m.MessageID == after {
+				messages = messages[i+1:]
+				break
+			}
+		}
+	}
+	var since time.Time
+	if raw := q.Get("since"); raw != "" {
+		since, _ = awid.ParseTimestamp(raw)
+	}
+	out := awid.ChatHistoryResponse{Messages: []awid.ChatMessage{}}
+	for _, m := range messages {
+		if !since.IsZero() && !m.at.After(since) {
+			continue
+		}
+		out.Messages = append(out.Messages, m.ChatMessage)
+	}
+	limit := queryInt(r, "limit")
+	if limit <= 0 {
+		limit = defaultChatHistoryLimit
+	}
+	out.OldestFirst = q.Get("oldest_first") == "true" || q.Get("since") != "" || q.Get("after_message_id") != ""
+	if len(out.Messages) > limit {
+		if out.OldestFirst {
+			out.Messages = out.Messages[:limit]
+		} else {
+			out.Messages = out.Messages[len(out.Messages)-limit:]
+		}
+	}
+	if out.OldestFirst && len(out.Messages) > 0 {
+		out.NextCursor = out.Messages[len(out.Messages)-1].MessageID
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+func (s *FakeServer) handleChatRead(w http.ResponseWriter, r *http.Request, me *agent) {
+	c := s.sessionForCaller(w, r, me)
+	if c == nil {
+		return
+	}
+	var req awid.ChatMarkReadRequest
+	if !decodeBody(w, r, &req) {
+		return
+	}
+	upTo := len(c.messages)
+	if req.UpToMessageID != "" {
+		upTo = -1
+		for i, m := range c.messages {
+			if m.MessageID == req.UpToMessageID {
+				upTo = i + 1
+				break
+			}
+		}
+		if upTo < 0 {
+			writeDetail(w, http.StatusNotFound, "message not found")
+			return
+		}
+	}
+	marked := 0
+	for _, m := range c.messages[min(c.read[me.id], upTo):upTo] {
+		if m.fromID != me.id {
+			marked++
+		}
+	}
+	c.read[me.id] = max(c.read[me.id], upTo)
+	writeJSON(w, http.StatusOK, awid.ChatMarkReadResponse{Success: true, MessagesMarked: marked})
+}
+
+// callerSessions returns the caller's sessions, most recently active first.
+func (s *FakeServer) callerSessions(me *agent) []*chatSession {
+	var out []*chatSession
+	for _, c := range s.sessions {
+		if c.has(me) {
+			out = append(out, c)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].lastActivity().After(out[j].lastActivity()) })
+	return out
+}
+
+func (s *FakeServer) handleChatPending(w http.ResponseWriter, r *http.Request, me *agent) {
+	out := awid.ChatPendingResponse{Pending: []awid.ChatPendingItem{}}
+	for _, c := range s.callerSessions(me) {
+		unread := c.unread(me)
+		if len(unread) == 0 {
+			continue
+		}
+		last := unread[len(unread)-1]
+		out.Pending = append(out.Pending, awid.ChatPendingItem{
+			SessionID:            c.id,
+			Participants:         c.aliases(),
+			ParticipantDIDs:      c.dids(),
+			ParticipantAddresses: c.addresses(),
+			LastMessage:          last.Body,
+			LastFrom:             last.FromAgent,
+			LastFromDID:          last.FromDID,
+			UnreadCount:          len(unread),
+			LastActivity:         timestamp(c.lastActivity()),
+			Subject:              c.subject,
+			Metadata:             c.metadata,
+		})
+		out.MessagesWaiting += len(unread)
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+func (s *FakeServer) handleChatList(w http.ResponseWriter, r *http.Request, me *agent) {
+	out := awid.ChatListSessionsResponse{Sessions: []awid.ChatSessionItem{}}
+	for _, c := range s.callerSessions(me) {
+		item := awid.ChatSessionItem{
+			SessionID:            c.id,
+			Participants:         c.aliases(),
+			ParticipantDIDs:      c.dids(),
+			ParticipantAddresses: c.addresses(),
+			CreatedAt:            timestamp(c.createdAt),
+			LastActivity:         timestamp(c.lastActivity()),
+			UnreadCount:          len(c.unread(me)),
+			Subject:              c.subject,
+			Metadata:             c.metadata,
+		}
+		if n := len(c.messages); n > 0 {
+			item.LastMessage = c.messages[n-1].Body
+			item.LastFrom = c.messages[n-1].FromAgent
+		}
+		out.Sessions = append(out.Sessions, item)
+	}
+	writeJSON(w, http.StatusOK, out)
+}
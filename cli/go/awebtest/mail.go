@@ -0,0 +1,192 @@
+package awebtest
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/awebai/aw/awid"
+)
+
+// defaultInboxLimit is the inbox page size when the caller sets none.
+const defaultInboxLimit = 50
+
+type mailMessage struct {
+	awid.InboxMessage
+	fromID string
+	toID   string
+}
+
+// resolveRecipient finds the agent a send is addressed to, using the same
+// precedence as the client: address, stable ID, DID, alias, then agent ID.
+func (s *FakeServer) resolveRecipient(req *awid.SendMessageRequest) *agent {
+	if address := strings.TrimSpace(req.ToAddress); address != "" {
+		for _, a := range s.agents {
+			if a.cert != nil && a.cert.MemberAddress == address {
+				return a
+			}
+		}
+		_, alias, _ := strings.Cut(address, "/")
+		return s.agentByAlias(alias)
+	}
+	if stableID := strings.TrimSpace(req.ToStableID); stableID != "" {
+		for _, a := range s.agents {
+			if a.cert != nil && a.cert.MemberDIDAW == stableID {
+				return a
+			}
+		}
+	}
+	if did := strings.TrimSpace(req.ToDID); did != "" {
+		if a := s.agentByDIDKey(did); a != nil {
+			return a
+		}
+	}
+	if alias := strings.TrimSpace(req.ToAlias); alias != "" {
+		if _, bare, ok := strings.Cut(alias, "/"); ok {
+			alias = bare
+		}
+		return s.agentByAlias(alias)
+	}
+	if id := strings.TrimSpace(req.ToAgentID); id != "" {
+		return s.agentByID(id)
+	}
+	return nil
+}
+
+func (s *FakeServer) handleSendMessage(w http.ResponseWriter, r *http.Request, me *agent) {
+	var req awid.SendMessageRequest
+	if !decodeBody(w, r, &req) {
+		return
+	}
+	to := s.resolveRecipient(&req)
+	if to == nil {
+		writeDetail(w, http.StatusNotFound, "recipient not found")
+		return
+	}
+	// A sender's message ID is an idempotency key.
+	if id := strings.TrimSpace(req.MessageID); id != "" {
+		for _, m := range s.messages {
+			if m.MessageID == id && m.fromID == me.id {
+				writeJSON(w, http.StatusOK, sendResponse(m))
+				return
+			}
+		}
+	}
+	priority, err := awid.ParsePriority(string(req.Priority))
+	if err != nil {
+		writeDetail(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	m := &mailMessage{
+		InboxMessage: awid.InboxMessage{
+			MessageID:     firstNonEmpty(strings.TrimSpace(req.MessageID), s.newID()),
+			FromAgentID:   me.id,
+			FromAlias:     me.alias,
+			ToAlias:       to.alias,
+			Subject:       req.Subject,
+			Body:          req.Body,
+			Priority:      priority,
+			CreatedAt:     timestamp(s.now()),
+			FromDID:       req.FromDID,
+			ToDID:         req.ToDID,
+			ToStableID:    req.ToStableID,
+			Signature:     req.Signature,
+			SignedPayload: req.SignedPayload,
+			Attachments:   req.Attachments,
+		},
+		fromID: me.id,
+		toID:   to.id,
+	}
+	s.messages = append(s.messages, m)
+	writeJSON(w, http.StatusOK, sendResponse(m))
+}
+
+func sendResponse(m *mailMessage) awid.SendMessageResponse {
+	return awid.SendMessageResponse{
+		MessageID:   m.MessageID,
+		Status:      "delivered",
+		DeliveredAt: m.CreatedAt,
+		ToAgentID:   m.toID,
+		ToAlias:     m.ToAlias,
+	}
+}
+
+// handleInbox lists the caller's mail newest first.
+func (s *FakeServer) handleInbox(w http.ResponseWriter, r *http.Request, me *agent) {
+	unreadOnly := r.URL.Query().Get("unread_only") == "true"
+	var mine []awid.InboxMessage
+	for i := len(s.messages) - 1; i >= 0; i-- {
+		m := s.messages[i]
+		if m.toID != me.id || (unreadOnly && m.ReadAt != nil) {
+			continue
+		}
+		mine = append(mine, m.InboxMessage)
+	}
+	start, end, next := page(len(mine), r.URL.Query().Get("cursor"), queryInt(r, "limit"), defaultInboxLimit)
+	out := awid.InboxResponse{Messages: append([]awid.InboxMessage{}, mine[start:end]...)}
+	if next != "" {
+		out.HasMore = true
+		out.NextCursor = &next
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+func (s *FakeServer) messageFor(id string, me *agent) *mailMessage {
+	for _, m := range s.messages {
+		if m.MessageID == id && (m.toID == me.id || m.fromID == me.id) {
+			return m
+		}
+	}
+	return nil
+}
+
+func (s *FakeServer) handleGetMessage(w http.ResponseWriter, r *http.Request, me *agent) {
+	m := s.messageFor(r.PathValue("id"), me)
+	if m == nil {
+		writeDetail(w, http.StatusNotFound, "message not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, m.InboxMessage)
+}
+
+// ack marks a message addressed to me as read, keeping the first read time.
+func (s *FakeServer) ack(id string, me *agent) (string, bool) {
+	m := s.messageFor(id, me)
+	if m == nil || m.toID != me.id {
+		return "", false
+	}
+	if m.ReadAt == nil {
+		at := timestamp(s.now())
+		m.ReadAt = &at
+	}
+	return *m.ReadAt, true
+}
+
+func (s *FakeServer) handleAck(w http.ResponseWriter, r *http.Request, me *agent) {
+	id := r.PathValue("id")
+	at, ok := s.ack(id, me)
+	if !ok {
+		writeDetail(w, http.StatusNotFound, "message not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, awid.AckResponse{MessageID: id, AcknowledgedAt: at})
+}
+
+func (s *FakeServer) handleBulkAck(w http.ResponseWriter, r *http.Request, me *agent) {
+	var req struct {
+		MessageIDs []string `json:"message_ids"`
+	}
+	if !decodeBody(w, r, &req) {
+		return
+	}
+	out := awid.BulkAckResponse{Results: make([]awid.BulkAckResult, 0, len(req.MessageIDs))}
+	for _, id := range req.MessageIDs {
+		result := awid.BulkAckResult{MessageID: id}
+		if at, ok := s.ack(id, me); ok {
+			result.AcknowledgedAt = at
+		} else {
+			result.Error = "message not found"
+		}
+		out.Results = append(out.Results, result)
+	}
+	writeJSON(w, http.StatusOK, out)
+}
@@ -0,0 +1,305 @@
+package awebtest
+
+import (
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	aweb "github.com/awebai/aw"
+)
+
+const (
+	// defaultReservationTTL and maxReservationTTL bound ttl_seconds as the
+	// server does.
+	defaultReservationTTL = 3600
+	maxReservationTTL     = 86400
+
+	// defaultReservationListLimit is the list page size when the caller sets
+	// none.
+	defaultReservationListLimit = 100
+)
+
+type reservation struct {
+	key        string
+	holder     *agent
+	acquiredAt time.Time
+	expiresAt  time.Time
+	lease      time.Duration // the TTL last acquired or renewed with
+	metadata   map[string]any
+	token      int64
+}
+
+func (v *reservation) live(now time.Time) bool {
+	return v.expiresAt.After(now)
+}
+
+func (v *reservation) view() aweb.ReservationView {
+	metadata := v.metadata
+	if metadata == nil {
+		metadata = map[string]any{}
+	}
+	return aweb.ReservationView{
+		ResourceKey:   v.key,
+		HolderAgentID: v.holder.id,
+		HolderAlias:   v.holder.alias,
+		AcquiredAt:    timestamp(v.acquiredAt),
+		ExpiresAt:     timestamp(v.expiresAt),
+		Metadata:      metadata,
+		FencingToken:  v.token,
+	}
+}
+
+// nextFencingToken advances key's counter, which outlives released locks so
+// the next holder always gets a larger token.
+func (s *FakeServer) nextFencingToken(key string) int64 {
+	s.fencing[key]++
+	return s.fencing[key]
+}
+
+func writeHeld(w http.ResponseWriter, v *reservation) {
+	writeJSON(w, http.StatusConflict, aweb.ReservationHeldError{
+		Detail:        "reservation is already held",
+		HolderAgentID: v.holder.id,
+		HolderAlias:   v.holder.alias,
+		ExpiresAt:     timestamp(v.expiresAt),
+	})
+}
+
+// ttl validates ttl_seconds, applying the server default for 0.
+func ttl(w http.ResponseWriter, seconds int) (time.Duration, bool) {
+	if seconds == 0 {
+		seconds = defaultReservationTTL
+	}
+	if seconds < 1 || seconds > maxReservationTTL {
+		writeDetail(w, http.StatusUnprocessableEntity, "ttl_seconds must be between 1 and 86400")
+		return 0, false
+	}
+	return time.Duration(seconds) * time.Second, true
+}
+
+func (s *FakeServer) handleReservationAcquire(w http.ResponseWriter, r *http.Request, me *agent) {
+	var req aweb.ReservationAcquireRequest
+	if !decodeBody(w, r, &req) {
+		return
+	}
+	if err := aweb.ValidateResourceKey(req.ResourceKey); err != nil {
+		writeDetail(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	lease, ok := ttl(w, req.TTLSeconds)
+	if !ok {
+		return
+	}
+	now := s.now()
+	existing := s.reservations[req.ResourceKey]
+	if existing != nil && existing.live(now) && existing.holder != me {
+		writeHeld(w, existing)
+		return
+	}
+
+	var reclaimed *reservation
+	if existing != nil && existing.holder != me {
+		reclaimed = existing
+	}
+	// Empty metadata keeps what the lock already carries.
+	metadata := req.Metadata
+	if len(metadata) == 0 && existing != nil {
+		metadata = existing.metadata
+	}
+	// Re-acquiring a lock the caller still holds keeps its token.
+	token := int64(0)
+	if existing != nil && existing.live(now) && reclaimed == nil {
+		token = existing.token
+	} else {
+		token = s.nextFencingToken(req.ResourceKey)
+	}
+	v := &reservation{
+		key:        req.ResourceKey,
+		holder:     me,
+		acquiredAt: now,
+		expiresAt:  now.Add(lease),
+		lease:      lease,
+		metadata:   metadata,
+		token:      token,
+	}
+	s.reservations[req.ResourceKey] = v
+
+	out := aweb.ReservationAcquireResponse{
+		Status:        "acquired",
+		ResourceKey:   v.key,
+		HolderAgentID: me.id,
+		HolderAlias:   me.alias,
+		AcquiredAt:    timestamp(v.acquiredAt),
+		ExpiresAt:     timestamp(v.expiresAt),
+		FencingToken:  v.token,
+	}
+	if reclaimed != nil && req.StealExpired {
+		out.Status = "stolen"
+		out.StolenFromAgentID = reclaimed.holder.id
+		out.StolenFromAlias = reclaimed.holder.alias
+		out.StolenFromExpiresAt = timestamp(reclaimed.expiresAt)
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+// heldByCaller returns the live reservation on key held by me, answering
+// 404 when there is none and 409 when someone else holds it.
+func (s *FakeServer) heldByCaller(w http.ResponseWriter, key string, me *agent) *reservation {
+	v := s.reservations[key]
+	if v == nil || !v.live(s.now()) {
+		writeDetail(w, http.StatusNotFound, "reservation not found")
+		return nil
+	}
+	if v.holder != me {
+		writeHeld(w, v)
+		return nil
+	}
+	return v
+}
+
+func (s *FakeServer) handleReservationRenew(w http.ResponseWriter, r *http.Request, me *agent) {
+	var req aweb.ReservationRenewRequest
+	if !decodeBody(w, r, &req) {
+		return
+	}
+	lease, ok := ttl(w, req.TTLSeconds)
+	if !ok {
+		return
+	}
+	v := s.heldByCaller(w, req.ResourceKey, me)
+	if v == nil {
+		return
+	}
+	v.expiresAt = s.now().Add(lease)
+	v.lease = lease
+	writeJSON(w, http.StatusOK, aweb.ReservationRenewResponse{
+		Status:        "renewed",
+		ResourceKey:   v.key,
+		ExpiresAt:     timestamp(v.expiresAt),
+		HolderAgentID: me.id,
+		HolderAlias:   me.alias,
+		FencingToken:  v.token,
+	})
+}
+
+// renewActiveReservations extends the caller's live locks listed in the
+// aweb.RenewReservationsHeader by their own TTL, as the server does before
+// every authenticated route. Like the server it ignores a header with too
+// many keys and skips locks the caller does not hold.
+func (s *FakeServer) renewActiveReservations(r *http.Request, me *agent) {
+	header := r.Header.Get(aweb.RenewReservationsHeader)
+	if header == "" {
+		return
+	}
+	parts := strings.Split(header, ",")
+	if len(parts) > aweb.MaxActiveReservations {
+		return
+	}
+	now := s.now()
+	for _, part := range parts {
+		key, err := url.PathUnescape(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		v := s.reservations[key]
+		if v == nil || v.holder != me || !v.live(now) {
+			continue
+		}
+		if renewed := now.Add(v.lease); renewed.After(v.expiresAt) {
+			v.expiresAt = renewed
+		}
+	}
+}
+
+// handleReservationRelease succeeds for locks that are free or expired, so
+// a release retried after a timeout is not an error.
+func (s *FakeServer) handleReservationRelease(w http.ResponseWriter, r *http.Request, me *agent) {
+	var req aweb.ReservationReleaseRequest
+	if !decodeBody(w, r, &req) {
+		return
+	}
+	if v := s.reservations[req.ResourceKey]; v != nil {
+		if v.live(s.now()) && v.holder != me {
+			writeHeld(w, v)
+			return
+		}
+		delete(s.reservations, req.ResourceKey)
+	}
+	writeJSON(w, http.StatusOK, aweb.ReservationReleaseResponse{Status: "released", ResourceKey: req.ResourceKey})
+}
+
+func (s *FakeServer) handleReservationTransfer(w http.ResponseWriter, r *http.Request, me *agent) {
+	var req aweb.ReservationTransferRequest
+	if !decodeBody(w, r, &req) {
+		return
+	}
+	v := s.heldByCaller(w, req.ResourceKey, me)
+	if v == nil {
+		return
+	}
+	to := s.agentByAlias(strings.TrimSpace(req.ToAlias))
+	if to == nil {
+		writeDetail(w, http.StatusUnprocessableEntity, "no agent with alias "+req.ToAlias+" in this team")
+		return
+	}
+	v.holder = to
+	v.acquiredAt = s.now()
+	v.token = s.nextFencingToken(v.key)
+	writeJSON(w, http.StatusOK, aweb.ReservationTransferResponse{
+		Status:                "transferred",
+		ResourceKey:           v.key,
+		HolderAgentID:         to.id,
+		HolderAlias:           to.alias,
+		PreviousHolderAgentID: me.id,
+		PreviousHolderAlias:   me.alias,
+		AcquiredAt:            timestamp(v.acquiredAt),
+		ExpiresAt:             timestamp(v.expiresAt),
+		FencingToken:          v.token,
+	})
+}
+
+// liveReservations returns the unexpired reservations under prefix, by key.
+func (s *FakeServer) liveReservations(prefix string) []*reservation {
+	now := s.now()
+	var out []*reservation
+	for _, v := range s.reservations {
+		if v.live(now) && strings.HasPrefix(v.key, prefix) {
+			out = append(out, v)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].key < out[j].key })
+	return out
+}
+
+func (s *FakeServer) handleReservationRevoke(w http.ResponseWriter, r *http.Request, me *agent) {
+	var req aweb.ReservationRevokeRequest
+	if !decodeBody(w, r, &req) {
+		return
+	}
+	out := aweb.ReservationRevokeResponse{RevokedKeys: []string{}}
+	for _, v := range s.liveReservations(req.Prefix) {
+		delete(s.reservations, v.key)
+		out.RevokedKeys = append(out.RevokedKeys, v.key)
+	}
+	out.RevokedCount = len(out.RevokedKeys)
+	writeJSON(w, http.StatusOK, out)
+}
+
+func (s *FakeServer) handleReservationList(w http.ResponseWriter, r *http.Request, me *agent) {
+	live := s.liveReservations(r.URL.Query().Get("prefix"))
+	start, end, next := page(len(live), r.URL.Query().Get("cursor"), queryInt(r, "limit"), defaultReservationListLimit)
+	out := aweb.ReservationListResponse{
+		Reservations: make([]aweb.ReservationView, 0, end-start),
+		Total:        len(live),
+	}
+	for _, v := range live[start:end] {
+		out.Reservations = append(out.Reservations, v.view())
+	}
+	if next != "" {
+		out.HasMore = true
+		out.NextCursor = &next
+	}
+	writeJSON(w, http.StatusOK, out)
+}
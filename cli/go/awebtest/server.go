@@ -0,0 +1,361 @@
+// Package awebtest provides an in-memory aweb server for testing code built
+// on the aweb client, so integrators do not have to hand-roll httptest
+// handlers for every endpoint they touch.
+//
+// A FakeServer implements the common endpoints: connect (what aw init
+// calls), the agent roster, mail, chat and reservations. It answers with
+// the real server's status codes and JSON shapes. NewClient returns an
+// aweb.Client whose requests are served in memory without opening a
+// socket:
+//
+//	srv := awebtest.NewFakeServer()
+//	alice, _ := srv.NewClient("alice")
+//	bob, _ := srv.NewClient("bob")
+//	_, err := alice.SendMessage(ctx, &awid.SendMessageRequest{ToAlias: "bob", Body: "hi"})
+//	inbox, err := bob.Inbox(ctx, awid.InboxParams{})
+//
+// FakeServer is also an http.Handler, so httptest.NewServer(srv) serves the
+// same state over a real listener, for example to a built aw binary.
+//
+// The fake identifies callers by their team certificate; it does not check
+// request signatures. Mail and chat keep the signatures clients send, so
+// received messages verify as they would against a real server. SSE streams
+// (chat streams, agent and reservation watches) are not implemented and
+// answer 404, which the client reports as an unsupported server.
+package awebtest
+
+import (
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	aweb "github.com/awebai/aw"
+	"github.com/awebai/aw/awid"
+)
+
+// TeamID is the team every FakeServer agent belongs to.
+const TeamID = "default:awebtest.local"
+
+// BaseURL is the base URL of clients returned by NewClient. Nothing listens
+// on it; requests go through Transport.
+const BaseURL = "http://awebtest.invalid"
+
+// onlineWindow is how recently an agent must have called the server to be
+// listed as online.
+const onlineWindow = 5 * time.Minute
+
+// FakeServer is an in-memory aweb server. Its zero value is not usable;
+// create one with NewFakeServer. It is safe for concurrent use.
+type FakeServer struct {
+	mu      sync.Mutex
+	teamKey ed25519.PrivateKey
+	teamDID string
+	now     func() time.Time
+	mux     *http.ServeMux
+	seq     int
+
+	agents       []*agent
+	messages     []*mailMessage
+	sessions     []*chatSession
+	reservations map[string]*reservation
+	fencing      map[string]int64
+}
+
+type agent struct {
+	id         string
+	alias      string
+	didKey     string
+	signingKey ed25519.PrivateKey
+	cert       *awid.TeamCertificate
+	humanName  string
+	agentType  string
+	role       string
+	metadata   map[string]any
+	lastSeen   time.Time
+}
+
+// NewFakeServer returns an empty server for team TeamID.
+func NewFakeServer() *FakeServer {
+	pub, priv, err := awid.GenerateKeypair()
+	if err != nil {
+		panic(fmt.Sprintf("awebtest: generate team key: %v", err))
+	}
+	s := &FakeServer{
+		teamKey:      priv,
+		teamDID:      awid.ComputeDIDKey(pub),
+		now:          time.Now,
+		mux:          http.NewServeMux(),
+		reservations: map[string]*reservation{},
+		fencing:      map[string]int64{},
+	}
+	s.routes()
+	return s
+}
+
+// SetNow replaces the server's clock, for example to let reservations
+// expire without sleeping. A nil now restores time.Now.
+func (s *FakeServer) SetNow(now func() time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if now == nil {
+		now = time.Now
+	}
+	s.now = now
+}
+
+// ServeHTTP serves the aweb API from the server's in-memory state.
+func (s *FakeServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// Transport returns a RoundTripper that serves every request from s in
+// memory, whatever its host.
+func (s *FakeServer) Transport() http.RoundTripper {
+	return roundTripper{s}
+}
+
+type roundTripper struct{ h http.Handler }
+
+func (t roundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		defer req.Body.Close()
+	}
+	if err := req.Context().Err(); err != nil {
+		return nil, err
+	}
+	rec := httptest.NewRecorder()
+	t.h.ServeHTTP(rec, req)
+	resp := rec.Result()
+	resp.Request = req
+	return resp, nil
+}
+
+// Enroll registers alias as an agent of the team, or returns the existing
+// agent's credentials, and returns its signing key and team certificate.
+// It is what NewClient uses; call it directly to write workspace files for
+// an aw binary pointed at httptest.NewServer(s).
+func (s *FakeServer) Enroll(alias string) (ed25519.PrivateKey, *awid.TeamCertificate, error) {
+	alias = strings.TrimSpace(alias)
+	if alias == "" {
+		return nil, nil, fmt.Errorf("awebtest: alias is required")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a := s.agentByAlias(alias)
+	if a == nil {
+		var err error
+		if a, err = s.addAgent(alias); err != nil {
+			return nil, nil, err
+		}
+	}
+	return a.signingKey, a.cert, nil
+}
+
+// NewClient returns a client authenticated as alias, enrolling it first if
+// needed. Its requests, including SSE requests, are served in memory.
+func (s *FakeServer) NewClient(alias string) (*aweb.Client, error) {
+	key, cert, err := s.Enroll(alias)
+	if err != nil {
+		return nil, err
+	}
+	c, err := aweb.NewWithCertificate(BaseURL, key, cert)
+	if err != nil {
+		return nil, err
+	}
+	c.SetHTTPClient(&http.Client{Transport: s.Transport()})
+	c.SetSSEClient(&http.Client{Transport: s.Transport()})
+	return c, nil
+}
+
+// addAgent creates an agent with a fresh key and certificate. s.mu must be
+// held.
+func (s *FakeServer) addAgent(alias string) (*agent, error) {
+	if s.agentByAlias(alias) != nil {
+		return nil, fmt.Errorf("awebtest: alias %q is already in use", alias)
+	}
+	pub, priv, err := awid.GenerateKeypair()
+	if err != nil {
+		return nil, err
+	}
+	didKey := awid.ComputeDIDKey(pub)
+	cert, err := awid.SignTeamCertificate(s.teamKey, awid.TeamCertificateFields{
+		Team:         TeamID,
+		MemberDIDKey: didKey,
+		Alias:        alias,
+		Lifetime:     awid.LifetimeEphemeral,
+	})
+	if err != nil {
+		return nil, err
+	}
+	a := &agent{
+		id:         s.newID(),
+		alias:      alias,
+		didKey:     didKey,
+		signingKey: priv,
+		cert:       cert,
+		agentType:  "agent",
+	}
+	s.agents = append(s.agents, a)
+	return a, nil
+}
+
+// newID returns a UUID, or a sequence-based ID should crypto/rand fail.
+// s.mu must be held.
+func (s *FakeServer) newID() string {
+	s.seq++
+	if id, err := awid.GenerateUUID4(); err == nil {
+		return id
+	}
+	return fmt.Sprintf("00000000-0000-4000-8000-%012d", s.seq)
+}
+
+func (s *FakeServer) agentByAlias(alias string) *agent {
+	for _, a := range s.agents {
+		if a.alias == alias {
+			return a
+		}
+	}
+	return nil
+}
+
+func (s *FakeServer) agentByID(id string) *agent {
+	for _, a := range s.agents {
+		if a.id == id {
+			return a
+		}
+	}
+	return nil
+}
+
+func (s *FakeServer) agentByDIDKey(didKey string) *agent {
+	for _, a := range s.agents {
+		if a.didKey == didKey {
+			return a
+		}
+	}
+	return nil
+}
+
+// requestCertificate decodes the caller's team certificate and checks it
+// names this server's team.
+func requestCertificate(r *http.Request) (*awid.TeamCertificate, error) {
+	header := strings.TrimSpace(r.Header.Get("X-AWID-Team-Certificate"))
+	if header == "" {
+		return nil, fmt.Errorf("missing team certificate")
+	}
+	cert, err := awid.DecodeTeamCertificateHeader(header)
+	if err != nil {
+		return nil, err
+	}
+	if cert.Team != TeamID {
+		return nil, fmt.Errorf("certificate is for team %q, not %q", cert.Team, TeamID)
+	}
+	return cert, nil
+}
+
+// route registers an authenticated handler. Handlers run with s.mu held and
+// the calling agent resolved from its certificate.
+func (s *FakeServer) route(pattern string, h func(w http.ResponseWriter, r *http.Request, me *agent)) {
+	s.mux.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		cert, err := requestCertificate(r)
+		if err != nil {
+			writeDetail(w, http.StatusUnauthorized, err.Error())
+			return
+		}
+		me := s.agentByDIDKey(cert.MemberDIDKey)
+		if me == nil {
+			writeDetail(w, http.StatusUnauthorized, "agent not found; connect first")
+			return
+		}
+		me.lastSeen = s.now()
+		s.renewActiveReservations(r, me)
+		h(w, r, me)
+	})
+}
+
+func (s *FakeServer) routes() {
+	s.mux.HandleFunc("POST /v1/connect", s.handleConnect)
+
+	s.route("GET /v1/agents", s.handleListAgents)
+	s.route("POST /v1/agents", s.handleCreateAgent)
+	s.route("POST /v1/agents/heartbeat", s.handleHeartbeat)
+
+	s.route("POST /v1/messages", s.handleSendMessage)
+	s.route("GET /v1/messages/inbox", s.handleInbox)
+	s.route("POST /v1/messages/ack", s.handleBulkAck)
+	s.route("GET /v1/messages/{id}", s.handleGetMessage)
+	s.route("POST /v1/messages/{id}/ack", s.handleAck)
+
+	s.route("POST /v1/chat/sessions", s.handleChatCreate)
+	s.route("GET /v1/chat/sessions", s.handleChatList)
+	s.route("GET /v1/chat/pending", s.handleChatPending)
+	s.route("POST /v1/chat/sessions/{id}/messages", s.handleChatSend)
+	s.route("GET /v1/chat/sessions/{id}/messages", s.handleChatHistory)
+	s.route("POST /v1/chat/sessions/{id}/read", s.handleChatRead)
+
+	s.route("POST /v1/reservations", s.handleReservationAcquire)
+	s.route("GET /v1/reservations", s.handleReservationList)
+	s.route("POST /v1/reservations/renew", s.handleReservationRenew)
+	s.route("POST /v1/reservations/release", s.handleReservationRelease)
+	s.route("POST /v1/reservations/transfer", s.handleReservationTransfer)
+	s.route("POST /v1/reservations/revoke", s.handleReservationRevoke)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// writeDetail writes a FastAPI-style {"detail": ...} error body.
+func writeDetail(w http.ResponseWriter, status int, detail string) {
+	writeJSON(w, status, map[string]string{"detail": detail})
+}
+
+// decodeBody decodes the JSON request body into v, answering 422 on
+// malformed input. An empty body leaves v unchanged.
+func decodeBody(w http.ResponseWriter, r *http.Request, v any) bool {
+	if r.Body == nil || r.Body == http.NoBody {
+		return true
+	}
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil && !errors.Is(err, io.EOF) {
+		writeDetail(w, http.StatusUnprocessableEntity, "invalid JSON body: "+err.Error())
+		return false
+	}
+	return true
+}
+
+// timestamp formats t the way the server does.
+func timestamp(t time.Time) string {
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+// page slices n items by an offset cursor and limit, returning the bounds
+// and the next cursor, or "" when this is the last page.
+func page(n int, cursor string, limit, defaultLimit int) (start, end int, next string) {
+	if limit <= 0 {
+		limit = defaultLimit
+	}
+	start, _ = strconv.Atoi(cursor)
+	start = min(max(start, 0), n)
+	end = min(start+limit, n)
+	if end < n {
+		next = strconv.Itoa(end)
+	}
+	return start, end, next
+}
+
+func queryInt(r *http.Request, name string) int {
+	n, _ := strconv.Atoi(r.URL.Query().Get(name))
+	return n
+}
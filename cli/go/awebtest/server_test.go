@@ -0,0 +1,290 @@
+package awebtest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	aweb "github.com/awebai/aw"
+	"github.com/awebai/aw/awid"
+)
+
+func newClients(t *testing.T, srv *FakeServer, aliases ...string) []*aweb.Client {
+	t.Helper()
+	out := make([]*aweb.Client, len(aliases))
+	for i, alias := range aliases {
+		c, err := srv.NewClient(alias)
+		if err != nil {
+			t.Fatal(err)
+		}
+		out[i] = c
+	}
+	return out
+}
+
+func TestFakeServerMailRoundTrip(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	srv := NewFakeServer()
+	clients := newClients(t, srv, "alice", "bob")
+	alice, bob := clients[0], clients[1]
+
+	sent, err := alice.SendMessage(ctx, &awid.SendMessageRequest{ToAlias: "bob", Subject: "plan", Body: "ship it"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sent.Status != "delivered" || sent.ToAlias != "bob" {
+		t.Fatalf("sent=%+v", sent)
+	}
+
+	inbox, err := bob.Inbox(ctx, awid.InboxParams{UnreadOnly: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(inbox.Messages) != 1 {
+		t.Fatalf("messages=%+v", inbox.Messages)
+	}
+	got := inbox.Messages[0]
+	if got.MessageID != sent.MessageID || got.FromAlias != "alice" || got.Body != "ship it" || got.Subject != "plan" {
+		t.Fatalf("message=%+v", got)
+	}
+	if got.VerificationStatus == awid.Failed {
+		t.Fatalf("verification_status=%s", got.VerificationStatus)
+	}
+
+	if _, err := bob.AckMessage(ctx, sent.MessageID); err != nil {
+		t.Fatal(err)
+	}
+	inbox, err = bob.Inbox(ctx, awid.InboxParams{UnreadOnly: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(inbox.Messages) != 0 {
+		t.Fatalf("unread after ack=%+v", inbox.Messages)
+	}
+	if _, err := alice.AckMessage(ctx, sent.MessageID); err == nil {
+		t.Fatal("sender acked a message it did not receive")
+	}
+}
+
+func TestFakeServerSendToUnknownAliasIsNotFound(t *testing.T) {
+	t.Parallel()
+	srv := NewFakeServer()
+	alice := newClients(t, srv, "alice")[0]
+
+	_, err := alice.SendMessage(context.Background(), &awid.SendMessageRequest{ToAlias: "nobody", Body: "hi"})
+	if code, ok := awid.HTTPStatusCode(err); !ok || code != http.StatusNotFound {
+		t.Fatalf("err=%v", err)
+	}
+}
+
+func TestFakeServerChatSession(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	srv := NewFakeServer()
+	clients := newClients(t, srv, "alice", "bob")
+	alice, bob := clients[0], clients[1]
+
+	created, err := alice.ChatCreateSession(ctx, &awid.ChatCreateSessionRequest{ToAliases: []string{"bob"}, Message: "ready?"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(created.Participants) != 2 {
+		t.Fatalf("participants=%+v", created.Participants)
+	}
+
+	pending, err := bob.ChatPending(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending.Pending) != 1 || pending.Pending[0].SessionID != created.SessionID || pending.Pending[0].LastFrom != "alice" {
+		t.Fatalf("pending=%+v", pending)
+	}
+
+	if _, err := bob.ChatSendMessage(ctx, created.SessionID, &awid.ChatSendMessageRequest{Body: "yes"}); err != nil {
+		t.Fatal(err)
+	}
+	history, err := alice.ChatHistory(ctx, awid.ChatHistoryParams{SessionID: created.SessionID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history.Messages) != 2 || history.Messages[0].Body != "ready?" || history.Messages[1].Body != "yes" {
+		t.Fatalf("history=%+v", history.Messages)
+	}
+
+	again, err := bob.ChatCreateSession(ctx, &awid.ChatCreateSessionRequest{ToAliases: []string{"alice"}, Message: "same room"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.SessionID != created.SessionID {
+		t.Fatalf("session=%s, want reuse of %s", again.SessionID, created.SessionID)
+	}
+}
+
+func TestFakeServerReservationConflictExpiryAndFencing(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	srv := NewFakeServer()
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	srv.SetNow(func() time.Time { return now })
+	clients := newClients(t, srv, "alice", "bob")
+	alice, bob := clients[0], clients[1]
+
+	first, err := alice.ReservationAcquire(ctx, &aweb.ReservationAcquireRequest{ResourceKey: "src/a.go", TTLSeconds: 60})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = bob.ReservationAcquire(ctx, &aweb.ReservationAcquireRequest{ResourceKey: "src/a.go", TTLSeconds: 60})
+	var held *aweb.ReservationHeldError
+	if !errors.As(err, &held) || held.HolderAlias != "alice" {
+		t.Fatalf("err=%v", err)
+	}
+
+	now = now.Add(2 * time.Minute)
+	stolen, err := bob.ReservationAcquire(ctx, &aweb.ReservationAcquireRequest{ResourceKey: "src/a.go", TTLSeconds: 60, StealExpired: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stolen.Status != "stolen" || stolen.StolenFromAlias != "alice" {
+		t.Fatalf("stolen=%+v", stolen)
+	}
+	if stolen.FencingToken <= first.FencingToken {
+		t.Fatalf("fencing token %d did not advance past %d", stolen.FencingToken, first.FencingToken)
+	}
+
+	transferred, err := bob.ReservationTransfer(ctx, "src/a.go", "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if transferred.HolderAlias != "alice" || transferred.FencingToken <= stolen.FencingToken {
+		t.Fatalf("transferred=%+v", transferred)
+	}
+
+	list, err := bob.ReservationList(ctx, aweb.ReservationListParams{Prefix: "src/"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Reservations) != 1 || list.Reservations[0].HolderAlias != "alice" {
+		t.Fatalf("list=%+v", list)
+	}
+	_, err = bob.ReservationRelease(ctx, &aweb.ReservationReleaseRequest{ResourceKey: "src/a.go"})
+	if code, ok := awid.HTTPStatusCode(err); !ok || code != http.StatusConflict {
+		t.Fatalf("release by non-holder err=%v", err)
+	}
+}
+
+func TestFakeServerCreateAgentMetadataInRoster(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	srv := NewFakeServer()
+	alice := newClients(t, srv, "alice")[0]
+
+	if _, err := alice.CreateAgent(ctx, &awid.CreateAgentRequest{Alias: "helper", Metadata: map[string]any{"model": "m-1"}}); err != nil {
+		t.Fatal(err)
+	}
+	roster, err := alice.ListAgents(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var found bool
+	for _, a := range roster.Agents {
+		if a.Alias == "helper" {
+			found = a.Metadata["model"] == "m-1" && !a.Online
+		}
+		if a.Alias == "alice" && !a.Online {
+			t.Fatalf("caller not online: %+v", a)
+		}
+	}
+	if !found {
+		t.Fatalf("roster=%+v", roster.Agents)
+	}
+
+	helper, err := srv.NewClient("helper")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := helper.Heartbeat(ctx); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFakeServerServesOverHTTP(t *testing.T) {
+	t.Parallel()
+	srv := NewFakeServer()
+	hs := httptest.NewServer(srv)
+	t.Cleanup(hs.Close)
+
+	key, cert, err := srv.Enroll("alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := aweb.NewWithCertificate(hs.URL, key, cert)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.ListAgents(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := http.Get(hs.URL + "/v1/agents")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("unauthenticated status=%d", resp.StatusCode)
+	}
+}
+
+func TestFakeServerRenewsActiveReservations(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	srv := NewFakeServer()
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	srv.SetNow(func() time.Time { return now })
+	clients := newClients(t, srv, "alice", "bob")
+	alice, bob := clients[0], clients[1]
+
+	for _, key := range []string{"src/a.go", "src/b.go"} {
+		if _, err := alice.ReservationAcquire(ctx, &aweb.ReservationAcquireRequest{ResourceKey: key, TTLSeconds: 60}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := alice.SetActiveReservations([]string{"src/a.go"}); err != nil {
+		t.Fatal(err)
+	}
+	// Bob listing alice's lock renews nothing.
+	if err := bob.SetActiveReservations([]string{"src/b.go"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Any request from alice keeps src/a.go alive; src/b.go lapses.
+	now = now.Add(50 * time.Second)
+	if _, err := alice.Inbox(ctx, awid.InboxParams{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := bob.Inbox(ctx, awid.InboxParams{}); err != nil {
+		t.Fatal(err)
+	}
+	// The renewal extends src/a.go by its own 60s TTL, to 110s.
+	now = now.Add(30 * time.Second)
+	list, err := bob.ReservationList(ctx, aweb.ReservationListParams{Prefix: "src/"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Reservations) != 1 || list.Reservations[0].ResourceKey != "src/a.go" || list.Reservations[0].HolderAlias != "alice" {
+		t.Fatalf("list=%+v", list.Reservations)
+	}
+	// Without further requests from alice, src/a.go lapses one TTL later.
+	now = now.Add(30 * time.Second)
+	list, err = bob.ReservationList(ctx, aweb.ReservationListParams{Prefix: "src/"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Reservations) != 0 {
+		t.Fatalf("list=%+v, want src/a.go expired", list.Reservations)
+	}
+}
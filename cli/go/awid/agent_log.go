@@ -27,13 +27,13 @@ type AgentLogResponse struct {
 func (c *Client) AgentLog(ctx context.Context, address string) (*AgentLogResponse, error) {
 	var path string
 	if address == "" {
-		path = "/v1/agents/me/log"
+		path = c.APIPath("/agents/me/log")
 	} else {
 		parts := strings.SplitN(address, "/", 2)
 		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
 			return nil, fmt.Errorf("address must be namespace/alias, got %q", address)
 		}
-		path = "/v1/agents/" + urlPathEscape(parts[0]) + "/" + urlPathEscape(parts[1]) + "/log"
+		path = c.APIPath("/agents/" + urlPathEscape(parts[0]) + "/" + urlPathEscape(parts[1]) + "/log")
 	}
 	var out AgentLogResponse
 	if err := c.Get(ctx, path, &out); err != nil {
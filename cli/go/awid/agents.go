@@ -1,6 +1,14 @@
 package awid
 
-import "context"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
 
 // HeartbeatResponse is returned by POST /v1/agents/heartbeat.
 type HeartbeatResponse struct {
@@ -26,27 +34,237 @@ type AgentView struct {
 	LastSeen      string `json:"last_seen,omitempty"`
 	Online        bool   `json:"online,omitempty"`
 	Lifetime      string `json:"lifetime,omitempty"`
+	// Metadata is the agent's self-description (model, capabilities,
+	// version) as reported when it connected.
+	Metadata map[string]any `json:"metadata,omitempty"`
 }
 
 type ListAgentsResponse struct {
 	TeamID string      `json:"team_id"`
 	Agents []AgentView `json:"agents"`
+	// NotModified is set when the server answered 304 and the roster came
+	// from the client's conditional GET cache.
+	NotModified bool `json:"-"`
 }
 
 // Heartbeat reports agent liveness to the aweb server.
 func (c *Client) Heartbeat(ctx context.Context) (*HeartbeatResponse, error) {
 	var out HeartbeatResponse
-	if err := c.Post(ctx, "/v1/agents/heartbeat", nil, &out); err != nil {
+	if err := c.Post(ctx, c.APIPath("/agents/heartbeat"), nil, &out); err != nil {
 		return nil, err
 	}
 	return &out, nil
 }
 
-// ListAgents lists agents visible in the authenticated team.
+// ListAgents lists agents visible in the authenticated team. It revalidates
+// with the server's ETag, so polling an unchanged roster costs an empty 304;
+// see SetConditionalGET.
 func (c *Client) ListAgents(ctx context.Context) (*ListAgentsResponse, error) {
 	var out ListAgentsResponse
-	if err := c.Get(ctx, "/v1/agents", &out); err != nil {
+	notModified, err := c.GetConditional(ctx, c.APIPath("/agents"), &out)
+	if err != nil {
 		return nil, err
 	}
+	out.NotModified = notModified
 	return &out, nil
 }
+
+// StaleAgents returns the agents last seen more than threshold ago, in
+// roster order. Agents whose LastSeen is empty or unparseable are skipped
+// rather than guessed at, so the result is safe to feed to DeleteAgent.
+func (r *ListAgentsResponse) StaleAgents(threshold time.Duration) []AgentView {
+	return r.staleAgentsAt(time.Now(), threshold)
+}
+
+func (r *ListAgentsResponse) staleAgentsAt(now time.Time, threshold time.Duration) []AgentView {
+	if r == nil {
+		return nil
+	}
+	var stale []AgentView
+	for _, a := range r.Agents {
+		seen, err := a.LastSeenTime()
+		if err != nil {
+			continue
+		}
+		if now.Sub(seen) > threshold {
+			stale = append(stale, a)
+		}
+	}
+	return stale
+}
+
+// CreateAgentRequest describes an additional agent to provision in the
+// caller's team.
+type CreateAgentRequest struct {
+	Alias     string `json:"alias"`
+	HumanName string `json:"human_name,omitempty"`
+	AgentType string `json:"agent_type,omitempty"`
+	// Metadata lets the new agent self-describe (model, capabilities,
+	// version); it is surfaced back in AgentView.
+	Metadata map[string]any `json:"metadata,omitempty"`
+}
+
+// CreateAgentResponse carries the new agent's identity and its API key. The
+// key is only returned once.
+type CreateAgentResponse struct {
+	AgentID string `json:"agent_id"`
+	Alias   string `json:"alias"`
+	TeamID  string `json:"team_id,omitempty"`
+	APIKey  string `json:"api_key"`
+}
+
+// AliasTakenError is returned by CreateAgent when the server reports the
+// requested alias is already in use. Callers can retry with the alias from
+// SuggestAliasPrefix.
+type AliasTakenError struct {
+	Alias string
+	Err   error
+}
+
+func (e *AliasTakenError) Error() string {
+	return fmt.Sprintf("aweb: alias %q is already in use", e.Alias)
+}
+
+func (e *AliasTakenError) Unwrap() error { return e.Err }
+
+// ErrAgentCreateUnsupported is wrapped by CreateAgent when the server has no
+// agent provisioning endpoint. The original *APIError stays in the chain.
+var ErrAgentCreateUnsupported = errors.New("aweb: server does not support creating agents")
+
+// CreateAgent provisions another agent in the authenticated team, for
+// example a supervisor spawning workers.
+//
+// It needs a server that issues API keys for the agents it creates, such as
+// a hosted deployment. The self-hosted aweb server has no such endpoint:
+// agents there join with a team certificate through POST /v1/connect (aw
+// init), and CreateAgent fails with ErrAgentCreateUnsupported.
+//
+// POST /v1/agents
+func (c *Client) CreateAgent(ctx context.Context, req *CreateAgentRequest) (*CreateAgentResponse, error) {
+	if req == nil || strings.TrimSpace(req.Alias) == "" {
+		return nil, errors.New("aweb: alias is required")
+	}
+	var out CreateAgentResponse
+	if err := c.Post(ctx, c.APIPath("/agents"), req, &out); err != nil {
+		if code, ok := HTTPStatusCode(err); ok {
+			switch code {
+			case http.StatusConflict:
+				return nil, &AliasTakenError{Alias: req.Alias, Err: err}
+			case http.StatusNotFound, http.StatusMethodNotAllowed:
+				return nil, fmt.Errorf("%w: %w", ErrAgentCreateUnsupported, err)
+			}
+		}
+		return nil, err
+	}
+	c.invalidateAgentsCache()
+	return &out, nil
+}
+
+// DeleteAgentResponse is returned by DELETE /v1/agents/{agent_id}.
+type DeleteAgentResponse struct {
+	AgentID   string `json:"agent_id"`
+	Alias     string `json:"alias"`
+	DeletedAt string `json:"deleted_at,omitempty"`
+}
+
+// ErrAgentNotFound and ErrAgentDeleteForbidden are wrapped by DeleteAgent
+// for 404 and 403 responses respectively. The original *APIError stays in
+// the chain.
+var (
+	ErrAgentNotFound        = errors.New("aweb: agent not found")
+	ErrAgentDeleteForbidden = errors.New("aweb: not allowed to delete agent")
+)
+
+// DeleteAgent removes an agent from the authenticated team. Deleting the
+// calling agent invalidates the client's own credentials.
+//
+// DELETE /v1/agents/{agent_id}
+func (c *Client) DeleteAgent(ctx context.Context, agentID string) (*DeleteAgentResponse, error) {
+	agentID = strings.TrimSpace(agentID)
+	if agentID == "" {
+		return nil, errors.New("aweb: agent_id is required")
+	}
+	var out DeleteAgentResponse
+	if err := c.Do(ctx, http.MethodDelete, c.APIPath("/agents/"+urlPathEscape(agentID)), nil, &out); err != nil {
+		if code, ok := HTTPStatusCode(err); ok {
+			switch code {
+			case http.StatusNotFound:
+				return nil, fmt.Errorf("%w %q: %w", ErrAgentNotFound, agentID, err)
+			case http.StatusForbidden:
+				return nil, fmt.Errorf("%w %q: %w", ErrAgentDeleteForbidden, agentID, err)
+			}
+		}
+		return nil, err
+	}
+	c.invalidateAgentsCache()
+	return &out, nil
+}
+
+// ErrUnknownAlias is returned by ResolveAlias when no agent in the team has
+// the alias.
+var ErrUnknownAlias = errors.New("aweb: unknown alias")
+
+// agentListCacheTTL bounds how stale the roster behind ResolveAlias and
+// ListAgentsCached may be, so a batch of sends costs one ListAgents round trip
+// rather than one each.
+const agentListCacheTTL = 30 * time.Second
+
+type agentListCache struct {
+	mu        sync.Mutex
+	fetchedAt time.Time
+	resp      *ListAgentsResponse
+}
+
+// ResolveAlias returns the team agent with the given alias, or an error
+// wrapping ErrUnknownAlias. The roster is cached on the client for
+// agentListCacheTTL.
+func (c *Client) ResolveAlias(ctx context.Context, alias string) (*AgentView, error) {
+	alias = strings.TrimSpace(alias)
+	resp, err := c.cachedAgents(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+	for i := range resp.Agents {
+		if resp.Agents[i].Alias == alias {
+			agent := resp.Agents[i]
+			return &agent, nil
+		}
+	}
+	return nil, fmt.Errorf("%w %q", ErrUnknownAlias, alias)
+}
+
+// ListAgentsCached is ListAgents served from the client's roster cache when
+// it is younger than agentListCacheTTL. CreateAgent and DeleteAgent drop the
+// cache, so the caller's own changes show up on the next call.
+func (c *Client) ListAgentsCached(ctx context.Context) (*ListAgentsResponse, error) {
+	return c.cachedAgents(ctx, false)
+}
+
+// RefreshAgents fetches the roster from the server and replaces the cache
+// behind ListAgentsCached and ResolveAlias.
+func (c *Client) RefreshAgents(ctx context.Context) (*ListAgentsResponse, error) {
+	return c.cachedAgents(ctx, true)
+}
+
+// cachedAgents returns a copy of the cached roster so callers cannot modify
+// the shared one.
+func (c *Client) cachedAgents(ctx context.Context, refresh bool) (*ListAgentsResponse, error) {
+	c.agentsCache.mu.Lock()
+	defer c.agentsCache.mu.Unlock()
+	if refresh || c.agentsCache.resp == nil || time.Since(c.agentsCache.fetchedAt) >= agentListCacheTTL {
+		resp, err := c.ListAgents(ctx)
+		if err != nil {
+			return nil, err
+		}
+		c.agentsCache.resp = resp
+		c.agentsCache.fetchedAt = time.Now()
+	}
+	cached := c.agentsCache.resp
+	return &ListAgentsResponse{TeamID: cached.TeamID, Agents: append([]AgentView{}, cached.Agents...)}, nil
+}
+
+func (c *Client) invalidateAgentsCache() {
+	c.agentsCache.mu.Lock()
+	defer c.agentsCache.mu.Unlock()
+	c.agentsCache.resp = nil
+}
@@ -13,7 +13,7 @@ type SuggestAliasPrefixResponse struct {
 // POST /v1/agents/suggest-alias-prefix
 func (c *Client) SuggestAliasPrefix(ctx context.Context) (*SuggestAliasPrefixResponse, error) {
 	var out SuggestAliasPrefixResponse
-	if err := c.Post(ctx, "/v1/agents/suggest-alias-prefix", struct{}{}, &out); err != nil {
+	if err := c.Post(ctx, c.APIPath("/agents/suggest-alias-prefix"), struct{}{}, &out); err != nil {
 		return nil, err
 	}
 	return &out, nil
@@ -2,15 +2,12 @@ package awid
 
 import (
 	"context"
-	"crypto/ed25519"
-	"encoding/base64"
 	"errors"
-	"fmt"
-	"io"
 	"net/http"
 	"sort"
 	"strings"
 	"time"
+	"unicode/utf8"
 )
 
 func (c *Client) namespaceSlug() string {
@@ -132,6 +129,17 @@ type ChatCreateSessionRequest struct {
 	Timestamp     string   `json:"timestamp,omitempty"`
 	MessageID     string   `json:"message_id,omitempty"`
 	SignedPayload string   `json:"signed_payload,omitempty"`
+
+	// Subject and Metadata label the conversation in pending and session
+	// listings. They are not part of the signed message; the latest sender
+	// to set them wins.
+	Subject  string         `json:"subject,omitempty"`
+	Metadata map[string]any `json:"metadata,omitempty"`
+
+	// Priority is an unsigned urgency hint; the server wakes recipients of
+	// high and urgent messages at once. Empty means normal, and is omitted
+	// so servers without priority support accept the request.
+	Priority MessagePriority `json:"priority,omitempty"`
 }
 
 type ChatCreateSessionResponse struct {
@@ -204,7 +212,7 @@ func (c *Client) ChatCreateSession(ctx context.Context, req *ChatCreateSessionRe
 	}
 
 	var out ChatCreateSessionResponse
-	if err := c.Post(ctx, "/v1/chat/sessions", &payload, &out); err != nil {
+	if err := c.Post(ctx, c.APIPath("/chat/sessions"), &payload, &out); err != nil {
 		return nil, err
 	}
 	return &out, nil
@@ -229,11 +237,14 @@ type ChatPendingItem struct {
 	LastActivity         string   `json:"last_activity"`
 	SenderWaiting        bool     `json:"sender_waiting"`
 	TimeRemainingSeconds *int     `json:"time_remaining_seconds"`
+
+	Subject  string         `json:"subject,omitempty"`
+	Metadata map[string]any `json:"metadata,omitempty"`
 }
 
 func (c *Client) ChatPending(ctx context.Context) (*ChatPendingResponse, error) {
 	var out ChatPendingResponse
-	if err := c.Get(ctx, "/v1/chat/pending", &out); err != nil {
+	if err := c.Get(ctx, c.APIPath("/chat/pending"), &out); err != nil {
 		return nil, err
 	}
 	return &out, nil
@@ -241,6 +252,14 @@ func (c *Client) ChatPending(ctx context.Context) (*ChatPendingResponse, error)
 
 type ChatHistoryResponse struct {
 	Messages []ChatMessage `json:"messages"`
+	// OldestFirst reports that the server honored
+	// ChatHistoryParams.OldestFirst. Older servers leave it false and
+	// return the latest messages. Servers that page forward also set it
+	// whenever Since or AfterMessageID is given.
+	OldestFirst bool `json:"oldest_first,omitempty"`
+	// NextCursor, with OldestFirst, is the AfterMessageID that reads on
+	// from the end of this page.
+	NextCursor string `json:"next_cursor,omitempty"`
 }
 
 type ChatMessage struct {
@@ -263,16 +282,27 @@ type ChatMessage struct {
 	ReplacementAnnouncement *ReplacementAnnouncement `json:"replacement_announcement,omitempty"`
 	VerificationStatus      VerificationStatus       `json:"verification_status,omitempty"`
 	IsContact               *bool                    `json:"is_contact,omitempty"`
+	Priority                MessagePriority          `json:"priority,omitempty"`
+	// Matches is set by ChatSearch.
+	Matches []TextMatch `json:"matches,omitempty"`
 }
 
 type ChatHistoryParams struct {
 	SessionID  string
 	UnreadOnly bool
 	Limit      int
+	// Since, when non-zero, asks for messages created strictly after it.
+	Since time.Time
+	// AfterMessageID asks for messages created after the given message.
+	AfterMessageID string
+	// OldestFirst makes Limit keep the earliest matching messages rather
+	// than the latest, so a caller can page forward by passing the last
+	// message of each page as the next AfterMessageID.
+	OldestFirst bool
 }
 
 func (c *Client) ChatHistory(ctx context.Context, p ChatHistoryParams) (*ChatHistoryResponse, error) {
-	path := "/v1/chat/sessions/" + urlPathEscape(p.SessionID) + "/messages"
+	path := c.APIPath("/chat/sessions/" + urlPathEscape(p.SessionID) + "/messages")
 	sep := "?"
 	if p.UnreadOnly {
 		path += sep + "unread_only=true"
@@ -282,12 +312,100 @@ func (c *Client) ChatHistory(ctx context.Context, p ChatHistoryParams) (*ChatHis
 		path += sep + "limit=" + itoa(p.Limit)
 		sep = "&"
 	}
+	if !p.Since.IsZero() {
+		path += sep + "since=" + urlQueryEscape(p.Since.UTC().Format(time.RFC3339Nano))
+		sep = "&"
+	}
+	if p.AfterMessageID != "" {
+		path += sep + "after_message_id=" + urlQueryEscape(p.AfterMessageID)
+		sep = "&"
+	}
+	if p.OldestFirst {
+		path += sep + "oldest_first=true"
+		sep = "&"
+	}
 	var out ChatHistoryResponse
 	if err := c.Get(ctx, path, &out); err != nil {
 		return nil, err
 	}
+	c.verifyChatMessages(ctx, out.Messages)
+	return &out, nil
+}
+
+// chatSearchFallbackLimit is how many of the latest messages ChatSearch
+// filters itself when the server has no search endpoint.
+const chatSearchFallbackLimit = 2000
+
+// TextMatch is the byte range [Start, End) of a search match in a message
+// body.
+type TextMatch struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+// ChatSearch returns the messages of a session whose body contains query,
+// ignoring case, oldest first. Each message carries the offsets of its
+// matches in Matches. When the server has no search endpoint, the latest
+// messages of the history are filtered on the client instead.
+func (c *Client) ChatSearch(ctx context.Context, sessionID, query string) (*ChatHistoryResponse, error) {
+	if strings.TrimSpace(query) == "" {
+		return nil, errors.New("aweb: search query is required")
+	}
+	var out ChatHistoryResponse
+	path := c.APIPath("/chat/sessions/" + urlPathEscape(sessionID) + "/messages/search?q=" + urlQueryEscape(query) + "&limit=" + itoa(chatSearchFallbackLimit))
+	err := c.Get(ctx, path, &out)
+	if code, ok := HTTPStatusCode(err); ok && (code == http.StatusNotFound || code == http.StatusMethodNotAllowed) {
+		history, err := c.ChatHistory(ctx, ChatHistoryParams{SessionID: sessionID, Limit: chatSearchFallbackLimit})
+		if err != nil {
+			return nil, err
+		}
+		out.Messages = history.Messages[:0]
+		for _, m := range history.Messages {
+			if len(matchOffsets(m.Body, query)) > 0 {
+				out.Messages = append(out.Messages, m)
+			}
+		}
+	} else if err != nil {
+		return nil, err
+	} else {
+		c.verifyChatMessages(ctx, out.Messages)
+	}
 	for i := range out.Messages {
-		m := &out.Messages[i]
+		out.Messages[i].Matches = matchOffsets(out.Messages[i].Body, query)
+	}
+	return &out, nil
+}
+
+// matchOffsets returns the non-overlapping ranges of body that equal query
+// under Unicode case folding.
+func matchOffsets(body, query string) []TextMatch {
+	n := utf8.RuneCountInString(query)
+	if n == 0 {
+		return nil
+	}
+	var matches []TextMatch
+	for i := 0; i < len(body); {
+		end := i
+		for k := 0; k < n && end < len(body); k++ {
+			_, size := utf8.DecodeRuneInString(body[end:])
+			end += size
+		}
+		if strings.EqualFold(body[i:end], query) {
+			matches = append(matches, TextMatch{Start: i, End: end})
+			i = end
+			continue
+		}
+		_, size := utf8.DecodeRuneInString(body[i:])
+		i += size
+	}
+	return matches
+}
+
+// verifyChatMessages fills in the identity fields carried by each message's
+// signed envelope and sets its verification and contact status.
+func (c *Client) verifyChatMessages(ctx context.Context, messages []ChatMessage) {
+	for i := range messages {
+		m := &messages[i]
 		if meta, ok := parseSignedEnvelopeMetadata(m.SignedPayload); ok {
 			if meta.FromDID != "" {
 				m.FromDID = meta.FromDID
@@ -337,7 +455,6 @@ func (c *Client) ChatHistory(ctx context.Context, p ChatHistoryParams) (*ChatHis
 		}
 		m.VerificationStatus, m.IsContact = c.NormalizeSenderTrust(ctx, m.VerificationStatus, from, m.FromDID, m.FromStableID, m.RotationAnnouncement, m.ReplacementAnnouncement, m.IsContact)
 	}
-	return &out, nil
 }
 
 type ChatMarkReadRequest struct {
@@ -351,7 +468,67 @@ type ChatMarkReadResponse struct {
 
 func (c *Client) ChatMarkRead(ctx context.Context, sessionID string, req *ChatMarkReadRequest) (*ChatMarkReadResponse, error) {
 	var out ChatMarkReadResponse
-	if err := c.Post(ctx, "/v1/chat/sessions/"+urlPathEscape(sessionID)+"/read", req, &out); err != nil {
+	if err := c.Post(ctx, c.APIPath("/chat/sessions/"+urlPathEscape(sessionID)+"/read"), req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+type ChatMarkUnreadRequest struct {
+	FromMessageID string `json:"from_message_id"`
+}
+
+// ChatMarkUnreadResponse carries the session's unread count after the read
+// receipt moved back.
+type ChatMarkUnreadResponse struct {
+	SessionID   string `json:"session_id"`
+	UnreadCount int    `json:"unread_count"`
+}
+
+// ChatMarkUnread moves the caller's read receipt back so fromMessageID and
+// every later message count as unread again. It never moves the receipt
+// forward.
+func (c *Client) ChatMarkUnread(ctx context.Context, sessionID, fromMessageID string) (*ChatMarkUnreadResponse, error) {
+	var out ChatMarkUnreadResponse
+	req := &ChatMarkUnreadRequest{FromMessageID: fromMessageID}
+	if err := c.Post(ctx, c.APIPath("/chat/sessions/"+urlPathEscape(sessionID)+"/unread"), req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ChatLeaveSessionResponse reports a leave. SessionClosed is true once every
+// participant has left.
+type ChatLeaveSessionResponse struct {
+	SessionID     string `json:"session_id"`
+	MessageID     string `json:"message_id"`
+	SessionClosed bool   `json:"session_closed"`
+}
+
+// ChatLeaveSession leaves a session without sending a final message. The
+// other participants see a sender_leaving event, as after a send with
+// Leaving set.
+func (c *Client) ChatLeaveSession(ctx context.Context, sessionID string) (*ChatLeaveSessionResponse, error) {
+	var out ChatLeaveSessionResponse
+	if err := c.Post(ctx, c.APIPath("/chat/sessions/"+urlPathEscape(sessionID)+"/leave"), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ChatNudgeResponse reports a nudge. Nudged lists the aliases that were
+// notified again; it is empty when every other participant has already read
+// the latest message.
+type ChatNudgeResponse struct {
+	SessionID string   `json:"session_id"`
+	Nudged    []string `json:"nudged"`
+}
+
+// ChatNudge asks the server to notify again the participants of a session
+// who have not read its latest message.
+func (c *Client) ChatNudge(ctx context.Context, sessionID string) (*ChatNudgeResponse, error) {
+	var out ChatNudgeResponse
+	if err := c.Post(ctx, c.APIPath("/chat/sessions/"+urlPathEscape(sessionID)+"/nudge"), nil, &out); err != nil {
 		return nil, err
 	}
 	return &out, nil
@@ -359,12 +536,16 @@ func (c *Client) ChatMarkRead(ctx context.Context, sessionID string, req *ChatMa
 
 // ChatStream opens an SSE stream for a session.
 //
-// deadline is required by the aweb API and must be a future time.
+// deadline is required by the aweb API and must be a future time. It is
+// shifted onto the server's clock when an earlier response showed the two
+// clocks disagree by more than a couple of seconds (see ClockSkew).
 // after controls replay: if non-nil, the server replays only messages created after
 // that timestamp; if nil, no replay (server polls from now).
-// Uses a dedicated HTTP client without response timeout since SSE connections are long-lived.
+// Uses a dedicated HTTP client without response timeout since SSE connections are long-lived;
+// a silently dropped connection is detected by the idle timeout instead (see SetSSEIdleTimeout).
 func (c *Client) ChatStream(ctx context.Context, sessionID string, deadline time.Time, after *time.Time) (*SSEStream, error) {
-	path := "/v1/chat/sessions/" + urlPathEscape(sessionID) + "/stream?deadline=" + urlQueryEscape(deadline.UTC().Format(time.RFC3339Nano))
+	deadline = c.skewAdjustedDeadline(deadline)
+	path := c.APIPath("/chat/sessions/" + urlPathEscape(sessionID) + "/stream?deadline=" + urlQueryEscape(deadline.UTC().Format(time.RFC3339Nano)))
 	if after != nil && !after.IsZero() {
 		// Truncate to second precision so the server replay query
 		// (WHERE created_at > $after) always includes our sent message.
@@ -376,44 +557,15 @@ func (c *Client) ChatStream(ctx context.Context, sessionID string, deadline time
 		path += "&after=" + urlQueryEscape(after.Truncate(time.Second).Add(-time.Second).UTC().Format(time.RFC3339))
 	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+path, nil)
+	body, err := c.openSSE(ctx, path, "")
 	if err != nil {
 		return nil, err
 	}
-	req.Header.Set("Accept", "text/event-stream")
-	req.Header.Set("Cache-Control", "no-cache")
-	if c.teamCertHeader != "" && c.signingKey != nil {
-		// Certificate auth: same DIDKey + cert headers as regular requests.
-		timestamp := time.Now().UTC().Format(time.RFC3339)
-		signPayload := certAuthSignPayload(c.teamID, timestamp, nil)
-		sig := ed25519.Sign(c.signingKey, signPayload)
-		req.Header.Set("Authorization", fmt.Sprintf("DIDKey %s %s", c.did, base64.RawStdEncoding.EncodeToString(sig)))
-		req.Header.Set("X-AWEB-Timestamp", timestamp)
-		req.Header.Set("X-AWID-Team-Certificate", c.teamCertHeader)
-	} else if c.signingKey != nil {
-		timestamp := time.Now().UTC().Format(time.RFC3339)
-		signPayload := identityAuthSignPayload(c.stableID, timestamp, nil)
-		sig := ed25519.Sign(c.signingKey, signPayload)
-		req.Header.Set("Authorization", fmt.Sprintf("DIDKey %s %s", c.did, base64.RawStdEncoding.EncodeToString(sig)))
-		req.Header.Set("X-AWEB-Timestamp", timestamp)
-		if c.stableID != "" {
-			req.Header.Set("X-AWEB-DID-AW", c.stableID)
-		}
-	}
-
-	resp, err := c.sseClient.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	if v := resp.Header.Get("X-Latest-Client-Version"); v != "" {
-		c.latestClientVersion.Store(v)
-	}
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
-		_ = resp.Body.Close()
-		return nil, &APIError{StatusCode: resp.StatusCode, Body: string(body)}
-	}
-	return NewSSEStream(resp.Body), nil
+	stream := c.newSSEStream(body)
+	stream.SetIdleTimeout(c.sseIdleTimeout)
+	observer := c.currentObserver()
+	stream.onEvent = func(ev *SSEEvent) { observer.StreamEvent(sessionID, ev.Event) }
+	return stream, nil
 }
 
 // ChatSendMessage sends a message in an existing chat session.
@@ -426,6 +578,9 @@ type ChatSendMessageRequest struct {
 	Timestamp     string `json:"timestamp,omitempty"`
 	MessageID     string `json:"message_id,omitempty"`
 	SignedPayload string `json:"signed_payload,omitempty"`
+
+	// Priority is as in ChatCreateSessionRequest.
+	Priority MessagePriority `json:"priority,omitempty"`
 }
 
 type ChatSendMessageResponse struct {
@@ -470,7 +625,7 @@ func (c *Client) ChatSendMessage(ctx context.Context, sessionID string, req *Cha
 	}
 
 	var out ChatSendMessageResponse
-	if err := c.Post(ctx, "/v1/chat/sessions/"+urlPathEscape(sessionID)+"/messages", &payload, &out); err != nil {
+	if err := c.Post(ctx, c.APIPath("/chat/sessions/"+urlPathEscape(sessionID)+"/messages"), &payload, &out); err != nil {
 		return nil, err
 	}
 	return &out, nil
@@ -484,15 +639,45 @@ type ChatSessionItem struct {
 	ParticipantAddresses []string `json:"participant_addresses,omitempty"`
 	CreatedAt            string   `json:"created_at"`
 	SenderWaiting        bool     `json:"sender_waiting,omitempty"`
+
+	// Activity summary; empty or zero when the server predates them.
+	LastActivity string `json:"last_activity,omitempty"`
+	LastMessage  string `json:"last_message,omitempty"`
+	LastFrom     string `json:"last_from,omitempty"`
+	UnreadCount  int    `json:"unread_count,omitempty"`
+
+	Subject  string         `json:"subject,omitempty"`
+	Metadata map[string]any `json:"metadata,omitempty"`
 }
 
 type ChatListSessionsResponse struct {
-	Sessions []ChatSessionItem `json:"sessions"`
+	Sessions   []ChatSessionItem `json:"sessions"`
+	HasMore    bool              `json:"has_more,omitempty"`
+	NextCursor *string           `json:"next_cursor,omitempty"`
 }
 
 func (c *Client) ChatListSessions(ctx context.Context) (*ChatListSessionsResponse, error) {
+	return c.chatListSessionsPage(ctx, "")
+}
+
+// ChatListSessionsIter iterates over chat sessions page by page.
+func (c *Client) ChatListSessionsIter() *Iterator[ChatSessionItem] {
+	return NewIterator(func(ctx context.Context, cursor string) ([]ChatSessionItem, string, error) {
+		resp, err := c.chatListSessionsPage(ctx, cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		return resp.Sessions, nextCursor(resp.HasMore, resp.NextCursor), nil
+	})
+}
+
+func (c *Client) chatListSessionsPage(ctx context.Context, cursor string) (*ChatListSessionsResponse, error) {
+	path := c.APIPath("/chat/sessions")
+	if cursor != "" {
+		path += "?cursor=" + urlQueryEscape(cursor)
+	}
 	var out ChatListSessionsResponse
-	if err := c.Get(ctx, "/v1/chat/sessions", &out); err != nil {
+	if err := c.Get(ctx, path, &out); err != nil {
 		return nil, err
 	}
 	return &out, nil
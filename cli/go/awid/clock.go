@@ -0,0 +1,95 @@
+package awid
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ErrNoServerDate is returned by ServerTime and ClockSkew when the server's
+// response carries no usable Date header.
+var ErrNoServerDate = errors.New("aweb: server response has no Date header")
+
+// clockSkewCorrectionThreshold is the smallest measured skew ChatStream
+// corrects its deadline for. The Date header has one-second resolution, so
+// smaller estimates are indistinguishable from round-trip noise.
+const clockSkewCorrectionThreshold = 2 * time.Second
+
+// EstimateClockSkew estimates how far the server clock is ahead of the local
+// one (negative when it is behind) from an HTTP Date header and the local
+// times the request was sent and its response received. The server time is
+// compared with the midpoint of the round trip; ok is false when the header
+// is missing or unparsable.
+func EstimateClockSkew(dateHeader string, sent, received time.Time) (time.Duration, bool) {
+	server, err := http.ParseTime(strings.TrimSpace(dateHeader))
+	if err != nil {
+		return 0, false
+	}
+	midpoint := sent.Add(received.Sub(sent) / 2)
+	// Date is truncated to the second, so on average the server was half a
+	// second further along than the header says.
+	return server.Add(500 * time.Millisecond).Sub(midpoint), true
+}
+
+// ClockSkew returns the skew measured from the most recent response that
+// carried a Date header, and whether one has been seen. It makes no request;
+// see MeasureClockSkew for an explicit probe.
+func (c *Client) ClockSkew() (time.Duration, bool) {
+	if !c.clockSkewKnown.Load() {
+		return 0, false
+	}
+	return time.Duration(c.clockSkew.Load()), true
+}
+
+// ServerTime returns the server's current time as reported by the Date
+// header of a lightweight read-only probe.
+func (c *Client) ServerTime(ctx context.Context) (time.Time, error) {
+	skew, err := c.MeasureClockSkew(ctx)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Now().Add(skew), nil
+}
+
+// MeasureClockSkew probes the server and returns how far its clock is ahead
+// of the local one (negative when it is behind). The probe's status code is
+// ignored: any response with a Date header is enough.
+func (c *Client) MeasureClockSkew(ctx context.Context) (time.Duration, error) {
+	sent := time.Now()
+	resp, err := c.DoRaw(ctx, http.MethodGet, c.APIPath("/agents/heartbeat"), "application/json", nil)
+	if err != nil {
+		return 0, err
+	}
+	received := time.Now()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1024))
+	_ = resp.Body.Close()
+	skew, ok := EstimateClockSkew(resp.Header.Get("Date"), sent, received)
+	if !ok {
+		return 0, ErrNoServerDate
+	}
+	return skew, nil
+}
+
+// recordClockSkew updates the client's skew estimate from a response's Date
+// header; responses without one leave the previous estimate in place.
+func (c *Client) recordClockSkew(dateHeader string, sent, received time.Time) {
+	skew, ok := EstimateClockSkew(dateHeader, sent, received)
+	if !ok {
+		return
+	}
+	c.clockSkew.Store(int64(skew))
+	c.clockSkewKnown.Store(true)
+}
+
+// skewAdjustedDeadline shifts a locally computed deadline onto the server's
+// clock when the measured skew is large enough to matter.
+func (c *Client) skewAdjustedDeadline(deadline time.Time) time.Time {
+	skew, ok := c.ClockSkew()
+	if !ok || (skew < clockSkewCorrectionThreshold && skew > -clockSkewCorrectionThreshold) {
+		return deadline
+	}
+	return deadline.Add(skew)
+}
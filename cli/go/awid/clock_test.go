@@ -0,0 +1,102 @@
+package awid
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestEstimateClockSkewComparesDateWithRoundTripMidpoint(t *testing.T) {
+	t.Parallel()
+
+	sent := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	received := sent.Add(time.Second)
+	date := sent.Add(time.Minute).Format(http.TimeFormat)
+
+	skew, ok := EstimateClockSkew(date, sent, received)
+	if !ok {
+		t.Fatal("expected a skew estimate")
+	}
+	// Server said 12:01:00, truncated: assume 12:01:00.5 against a local
+	// midpoint of 12:00:00.5.
+	if skew != time.Minute {
+		t.Fatalf("skew=%s, want 1m", skew)
+	}
+	if _, ok := EstimateClockSkew("", sent, received); ok {
+		t.Fatal("empty Date header should not produce an estimate")
+	}
+	if _, ok := EstimateClockSkew("yesterday", sent, received); ok {
+		t.Fatal("unparsable Date header should not produce an estimate")
+	}
+}
+
+func TestChatStreamShiftsDeadlineByMeasuredSkew(t *testing.T) {
+	t.Parallel()
+
+	const ahead = 10 * time.Minute
+	var gotDeadline string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Date", time.Now().Add(ahead).UTC().Format(http.TimeFormat))
+		if r.URL.Path == "/v1/agents/heartbeat" {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		gotDeadline = r.URL.Query().Get("deadline")
+		w.Header().Set("Content-Type", "text/event-stream")
+	}))
+	t.Cleanup(server.Close)
+
+	c, err := New(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := c.ClockSkew(); ok {
+		t.Fatal("no skew should be known before the first response")
+	}
+	skew, err := c.MeasureClockSkew(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if skew < ahead-2*time.Second || skew > ahead+2*time.Second {
+		t.Fatalf("skew=%s, want about %s", skew, ahead)
+	}
+	if recorded, ok := c.ClockSkew(); !ok || recorded < ahead-2*time.Second {
+		t.Fatalf("recorded skew=%s ok=%v", recorded, ok)
+	}
+
+	local := time.Now().Add(30 * time.Second)
+	stream, err := c.ChatStream(context.Background(), "sess", local, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = stream.Close()
+
+	deadline, err := time.Parse(time.RFC3339Nano, gotDeadline)
+	if err != nil {
+		t.Fatalf("deadline=%q: %v", gotDeadline, err)
+	}
+	if shift := deadline.Sub(local); shift < ahead-2*time.Second || shift > ahead+2*time.Second {
+		t.Fatalf("deadline shifted by %s, want about %s", shift, ahead)
+	}
+}
+
+func TestMeasureClockSkewWithoutDateHeader(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header()["Date"] = nil
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}))
+	t.Cleanup(server.Close)
+
+	c, err := New(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.ServerTime(context.Background()); !errors.Is(err, ErrNoServerDate) {
+		t.Fatalf("err=%v, want ErrNoServerDate", err)
+	}
+}
@@ -31,7 +31,7 @@ type ContactDeleteResponse struct {
 
 func (c *Client) CreateContact(ctx context.Context, req *ContactCreateRequest) (*ContactCreateResponse, error) {
 	var out ContactCreateResponse
-	if err := c.Post(ctx, "/v1/contacts", req, &out); err != nil {
+	if err := c.Post(ctx, c.APIPath("/contacts"), req, &out); err != nil {
 		return nil, err
 	}
 	return &out, nil
@@ -39,7 +39,7 @@ func (c *Client) CreateContact(ctx context.Context, req *ContactCreateRequest) (
 
 func (c *Client) ListContacts(ctx context.Context) (*ContactListResponse, error) {
 	var out ContactListResponse
-	if err := c.Get(ctx, "/v1/contacts", &out); err != nil {
+	if err := c.Get(ctx, c.APIPath("/contacts"), &out); err != nil {
 		return nil, err
 	}
 	return &out, nil
@@ -49,7 +49,7 @@ func (c *Client) ListContacts(ctx context.Context) (*ContactListResponse, error)
 // existing delete() helper discards the response body.
 func (c *Client) DeleteContact(ctx context.Context, contactID string) (*ContactDeleteResponse, error) {
 	var out ContactDeleteResponse
-	if err := c.Do(ctx, "DELETE", "/v1/contacts/"+urlPathEscape(contactID), nil, &out); err != nil {
+	if err := c.Do(ctx, "DELETE", c.APIPath("/contacts/"+urlPathEscape(contactID)), nil, &out); err != nil {
 		return nil, err
 	}
 	return &out, nil
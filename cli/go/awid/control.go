@@ -43,7 +43,7 @@ func (c *Client) SendControlSignal(ctx context.Context, alias string, signal Con
 
 	req := &SendControlSignalRequest{Signal: signal}
 	var out SendControlSignalResponse
-	if err := c.Post(ctx, "/v1/agents/"+urlPathEscape(alias)+"/control", req, &out); err != nil {
+	if err := c.Post(ctx, c.APIPath("/agents/"+urlPathEscape(alias)+"/control"), req, &out); err != nil {
 		return nil, err
 	}
 	return &out, nil
@@ -0,0 +1,157 @@
+package awid
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// OAuth device-authorization login (RFC 8628) for deployments that
+// authenticate humans through an identity provider instead of exposing the
+// unauthenticated init endpoint. The CLI shows the user a code to confirm in
+// a browser and polls until the server issues an API key.
+
+// DeviceLoginClientID identifies the aw CLI to the authorization server.
+const DeviceLoginClientID = "aw-cli"
+
+// DeviceCodeGrantType is the OAuth grant type for device-code token polls.
+const DeviceCodeGrantType = "urn:ietf:params:oauth:grant-type:device_code"
+
+const (
+	defaultDevicePollInterval = 5
+	devicePollSlowDownStep    = 5
+)
+
+var (
+	// ErrDeviceLoginDenied is returned when the user rejects the request in
+	// the browser.
+	ErrDeviceLoginDenied = errors.New("aweb: device login was denied")
+	// ErrDeviceLoginExpired is returned when the device code expires before
+	// the user approves it.
+	ErrDeviceLoginExpired = errors.New("aweb: device login expired before it was approved")
+)
+
+// devicePollUnit scales the server's poll interval; tests shrink it.
+var devicePollUnit = time.Second
+
+// DeviceAuthorization is the reply from POST /v1/auth/device/code. The user
+// visits VerificationURI and enters UserCode; VerificationURIComplete, when
+// present, already carries the code.
+type DeviceAuthorization struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationURI         string `json:"verification_uri"`
+	VerificationURIComplete string `json:"verification_uri_complete,omitempty"`
+	ExpiresIn               int    `json:"expires_in"`
+	Interval                int    `json:"interval,omitempty"`
+}
+
+// DeviceToken is the credential issued once the user approves the login.
+// AccessToken is an aweb API key.
+type DeviceToken struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type,omitempty"`
+	ExpiresIn   int    `json:"expires_in,omitempty"`
+}
+
+type deviceCodeRequest struct {
+	ClientID string `json:"client_id"`
+}
+
+type deviceTokenRequest struct {
+	GrantType  string `json:"grant_type"`
+	DeviceCode string `json:"device_code"`
+	ClientID   string `json:"client_id"`
+}
+
+type deviceTokenError struct {
+	Error       string `json:"error"`
+	Description string `json:"error_description,omitempty"`
+}
+
+// StartDeviceLogin requests a device and user code.
+func (c *Client) StartDeviceLogin(ctx context.Context) (*DeviceAuthorization, error) {
+	var out DeviceAuthorization
+	if err := c.Post(ctx, c.APIPath("/auth/device/code"), &deviceCodeRequest{ClientID: DeviceLoginClientID}, &out); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(out.DeviceCode) == "" || strings.TrimSpace(out.VerificationURI) == "" {
+		return nil, fmt.Errorf("aweb: device authorization response is missing device_code or verification_uri")
+	}
+	return &out, nil
+}
+
+// PollDeviceToken polls until the user approves or rejects auth, the code
+// expires, or ctx ends. authorization_pending keeps polling at the server's
+// interval and slow_down lengthens it, as RFC 8628 requires.
+func (c *Client) PollDeviceToken(ctx context.Context, auth *DeviceAuthorization) (*DeviceToken, error) {
+	if auth == nil {
+		return nil, errors.New("aweb: device authorization is required")
+	}
+	interval := auth.Interval
+	if interval <= 0 {
+		interval = defaultDevicePollInterval
+	}
+	var deadline <-chan time.Time
+	if auth.ExpiresIn > 0 {
+		timer := time.NewTimer(time.Duration(auth.ExpiresIn) * devicePollUnit)
+		defer timer.Stop()
+		deadline = timer.C
+	}
+
+	req := &deviceTokenRequest{GrantType: DeviceCodeGrantType, DeviceCode: auth.DeviceCode, ClientID: DeviceLoginClientID}
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-deadline:
+			return nil, ErrDeviceLoginExpired
+		case <-time.After(time.Duration(interval) * devicePollUnit):
+		}
+
+		var out DeviceToken
+		err := c.Post(ctx, c.APIPath("/auth/device/token"), req, &out)
+		if err == nil {
+			if strings.TrimSpace(out.AccessToken) == "" {
+				return nil, fmt.Errorf("aweb: device token response is missing access_token")
+			}
+			return &out, nil
+		}
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) {
+			return nil, err
+		}
+		var tokenErr deviceTokenError
+		if json.Unmarshal([]byte(apiErr.Body), &tokenErr) != nil {
+			return nil, err
+		}
+		switch tokenErr.Error {
+		case "authorization_pending":
+		case "slow_down":
+			interval += devicePollSlowDownStep
+		case "access_denied":
+			return nil, ErrDeviceLoginDenied
+		case "expired_token":
+			return nil, ErrDeviceLoginExpired
+		default:
+			return nil, err
+		}
+	}
+}
+
+// DeviceLogin runs the whole device flow: it requests a code, hands it to
+// prompt so the caller can show the verification URL and code, then polls
+// for the token.
+func (c *Client) DeviceLogin(ctx context.Context, prompt func(*DeviceAuthorization)) (*DeviceToken, error) {
+	auth, err := c.StartDeviceLogin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if prompt != nil {
+		prompt(auth)
+	}
+	return c.PollDeviceToken(ctx, auth)
+}
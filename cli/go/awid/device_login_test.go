@@ -0,0 +1,98 @@
+package awid
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// Device login tests shrink devicePollUnit, so they cannot run in parallel.
+
+func TestDeviceLoginPollsThroughPendingAndSlowDown(t *testing.T) {
+	defer func(old time.Duration) { devicePollUnit = old }(devicePollUnit)
+	devicePollUnit = time.Millisecond
+
+	var replies = []string{`{"error":"authorization_pending"}`, `{"error":"slow_down"}`}
+	var polls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/auth/device/code":
+			var req deviceCodeRequest
+			_ = json.NewDecoder(r.Body).Decode(&req)
+			if req.ClientID != DeviceLoginClientID {
+				t.Errorf("client_id=%q", req.ClientID)
+			}
+			_ = json.NewEncoder(w).Encode(DeviceAuthorization{
+				DeviceCode:      "dev-1",
+				UserCode:        "ABCD-EFGH",
+				VerificationURI: "https://example.com/device",
+				ExpiresIn:       600,
+				Interval:        1,
+			})
+		case "/v1/auth/device/token":
+			var req deviceTokenRequest
+			_ = json.NewDecoder(r.Body).Decode(&req)
+			if req.GrantType != DeviceCodeGrantType || req.DeviceCode != "dev-1" {
+				t.Errorf("token request=%+v", req)
+			}
+			if polls < len(replies) {
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = w.Write([]byte(replies[polls]))
+				polls++
+				return
+			}
+			polls++
+			_ = json.NewEncoder(w).Encode(DeviceToken{AccessToken: "aw_sk_device", TokenType: "bearer"})
+		default:
+			t.Errorf("unexpected path=%s", r.URL.Path)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	c, err := New(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var prompted *DeviceAuthorization
+	token, err := c.DeviceLogin(context.Background(), func(auth *DeviceAuthorization) { prompted = auth })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if prompted == nil || prompted.UserCode != "ABCD-EFGH" {
+		t.Fatalf("prompt got %+v", prompted)
+	}
+	if token.AccessToken != "aw_sk_device" || polls != 3 {
+		t.Fatalf("token=%+v polls=%d", token, polls)
+	}
+}
+
+func TestPollDeviceTokenReportsDeniedAndExpired(t *testing.T) {
+	defer func(old time.Duration) { devicePollUnit = old }(devicePollUnit)
+	devicePollUnit = time.Millisecond
+
+	for _, tc := range []struct {
+		reply string
+		want  error
+	}{
+		{`{"error":"access_denied"}`, ErrDeviceLoginDenied},
+		{`{"error":"expired_token"}`, ErrDeviceLoginExpired},
+	} {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(tc.reply))
+		}))
+		c, err := New(server.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = c.PollDeviceToken(context.Background(), &DeviceAuthorization{DeviceCode: "dev-1", Interval: 1})
+		server.Close()
+		if !errors.Is(err, tc.want) {
+			t.Fatalf("reply %s: err=%v, want %v", tc.reply, err, tc.want)
+		}
+	}
+}
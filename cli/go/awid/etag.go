@@ -0,0 +1,94 @@
+package awid
+
+import (
+	"context"
+	"net/http"
+	"sync"
+)
+
+// maxETagEntries bounds the conditional GET cache. Paging through a long
+// reservation list adds one entry per cursor; past the bound the cache
+// starts over rather than growing without limit.
+const maxETagEntries = 64
+
+// etagCache holds the last ETag and body per GET path (query included) for
+// GetConditional.
+type etagCache struct {
+	mu       sync.Mutex
+	disabled bool
+	entries  map[string]etagEntry
+}
+
+type etagEntry struct {
+	etag string
+	body []byte
+}
+
+// SetConditionalGET turns the ETag cache behind GetConditional on or off.
+// It is on by default; turning it off drops every cached body, and
+// GetConditional then behaves like Get.
+func (c *Client) SetConditionalGET(on bool) {
+	c.etags.mu.Lock()
+	defer c.etags.mu.Unlock()
+	c.etags.disabled = !on
+	if !on {
+		c.etags.entries = nil
+	}
+}
+
+// GetConditional is Get for endpoints that poll well: when the server sent
+// an ETag for path, the request carries If-None-Match, and a 304 decodes
+// the cached body into out instead of transferring it again. notModified
+// reports that out came from the cache.
+func (c *Client) GetConditional(ctx context.Context, path string, out any) (notModified bool, err error) {
+	c.etags.mu.Lock()
+	disabled := c.etags.disabled
+	cached, haveCached := c.etags.entries[path]
+	c.etags.mu.Unlock()
+	if disabled {
+		return false, c.Get(ctx, path, out)
+	}
+
+	if haveCached {
+		ctx = WithHeaders(ctx, http.Header{"If-None-Match": {cached.etag}})
+	}
+	resp, err := c.DoRaw(ctx, http.MethodGet, path, "application/json", nil)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	data, err := c.ReadResponseBody(resp)
+	if err != nil {
+		return false, err
+	}
+	if resp.StatusCode == http.StatusNotModified && haveCached {
+		return true, c.decodeResponse(path, http.StatusOK, cached.body, out)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return false, &APIError{StatusCode: resp.StatusCode, Body: string(data)}
+	}
+	c.storeETag(path, resp.Header.Get("ETag"), data)
+	return false, c.decodeResponse(path, resp.StatusCode, data, out)
+}
+
+// storeETag remembers body under etag for path, or forgets path when the
+// response carried no ETag.
+func (c *Client) storeETag(path, etag string, body []byte) {
+	c.etags.mu.Lock()
+	defer c.etags.mu.Unlock()
+	if c.etags.disabled {
+		return
+	}
+	if etag == "" {
+		delete(c.etags.entries, path)
+		return
+	}
+	if _, ok := c.etags.entries[path]; !ok && len(c.etags.entries) >= maxETagEntries {
+		c.etags.entries = nil
+	}
+	if c.etags.entries == nil {
+		c.etags.entries = make(map[string]etagEntry)
+	}
+	c.etags.entries[path] = etagEntry{etag: etag, body: append([]byte(nil), body...)}
+}
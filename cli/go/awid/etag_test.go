@@ -0,0 +1,91 @@
+package awid
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func TestListAgentsRevalidatesWithETag(t *testing.T) {
+	t.Parallel()
+
+	var requests, notModified atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Header().Set("ETag", `"roster-1"`)
+		if r.Header.Get("If-None-Match") == `"roster-1"` {
+			notModified.Add(1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(ListAgentsResponse{TeamID: "backend:acme.com", Agents: []AgentView{{AgentID: "agent-bob", Alias: "bob"}}})
+	}))
+	t.Cleanup(server.Close)
+
+	c, err := New(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, err := c.ListAgents(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.NotModified {
+		t.Fatal("first response marked not modified")
+	}
+	second, err := c.ListAgents(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !second.NotModified || second.TeamID != "backend:acme.com" || len(second.Agents) != 1 || second.Agents[0].Alias != "bob" {
+		t.Fatalf("revalidated roster=%+v", second)
+	}
+	if got := notModified.Load(); got != 1 {
+		t.Fatalf("304 responses=%d, want 1", got)
+	}
+
+	c.SetConditionalGET(false)
+	third, err := c.ListAgents(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if third.NotModified || notModified.Load() != 1 || requests.Load() != 3 {
+		t.Fatalf("with the cache off: roster=%+v, 304s=%d, requests=%d", third, notModified.Load(), requests.Load())
+	}
+}
+
+func TestGetConditionalForgetsPathWithoutETag(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	var sentTags []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sentTags = append(sentTags, r.Header.Get("If-None-Match"))
+		if calls.Add(1) == 1 {
+			w.Header().Set("ETag", `"v1"`)
+		}
+		_, _ = w.Write([]byte(`{"team_id":"t","agents":[]}`))
+	}))
+	t.Cleanup(server.Close)
+
+	c, err := New(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		var out ListAgentsResponse
+		notModified, err := c.GetConditional(context.Background(), "/v1/agents", &out)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if notModified || out.TeamID != "t" {
+			t.Fatalf("call %d: notModified=%v out=%+v", i, notModified, out)
+		}
+	}
+	if len(sentTags) != 3 || sentTags[0] != "" || sentTags[1] != `"v1"` || sentTags[2] != "" {
+		t.Fatalf("If-None-Match sent=%q, want only on the call after the ETag", sentTags)
+	}
+}
@@ -72,8 +72,8 @@ type AgentEventStream struct {
 	sse *SSEStream
 }
 
-func newAgentEventStream(body io.ReadCloser) *AgentEventStream {
-	return &AgentEventStream{sse: NewSSEStream(body)}
+func newAgentEventStream(sse *SSEStream) *AgentEventStream {
+	return &AgentEventStream{sse: sse}
 }
 
 func (s *AgentEventStream) Close() error {
@@ -109,15 +109,53 @@ func (s *AgentEventStream) Next(_ context.Context) (*AgentEvent, error) {
 // EventStream opens GET /v1/events/stream using the active client auth.
 // deadline is sent as an ISO8601/RFC3339 timestamp because the server expects an absolute time.
 func (c *Client) EventStream(ctx context.Context, deadline time.Time) (*AgentEventStream, error) {
-	path := "/v1/events/stream?deadline=" + urlQueryEscape(deadline.UTC().Format(time.RFC3339))
+	body, err := c.openSSE(ctx, c.APIPath("/events/stream?deadline="+urlQueryEscape(deadline.UTC().Format(time.RFC3339))), "")
+	if err != nil {
+		return nil, err
+	}
+	return newAgentEventStream(c.newSSEStream(body)), nil
+}
 
+// OpenSSEStream opens an authenticated text/event-stream GET on path for
+// streams built outside this package, such as reservation watches. It uses
+// the client's SSE idle timeout and buffer size; a non-2xx status is
+// returned as an *APIError.
+func (c *Client) OpenSSEStream(ctx context.Context, path string) (*SSEStream, error) {
+	return c.openSSEStream(ctx, path, "")
+}
+
+func (c *Client) openSSEStream(ctx context.Context, path, lastEventID string) (*SSEStream, error) {
+	body, err := c.openSSE(ctx, path, lastEventID)
+	if err != nil {
+		return nil, err
+	}
+	stream := c.newSSEStream(body)
+	stream.SetIdleTimeout(c.sseIdleTimeout)
+	return stream, nil
+}
+
+// openSSE issues an authenticated GET for a text/event-stream endpoint on the
+// long-lived SSE client and returns the response body on a 2xx status. A
+// non-empty lastEventID is sent as Last-Event-ID so the server can resume
+// after the last event the caller saw.
+func (c *Client) openSSE(ctx context.Context, path, lastEventID string) (io.ReadCloser, error) {
+	if c.closed.Load() {
+		return nil, ErrClientClosed
+	}
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+path, nil)
 	if err != nil {
 		return nil, err
 	}
+	c.applyExtraHeaders(ctx, req)
 	req.Header.Set("Accept", "text/event-stream")
 	req.Header.Set("Cache-Control", "no-cache")
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+	// Event streams must not be compressed: a gzip writer buffers events.
+	req.Header.Set("Accept-Encoding", "identity")
 	if c.teamCertHeader != "" && c.signingKey != nil {
+		// Certificate auth: same DIDKey + cert headers as regular requests.
 		timestamp := time.Now().UTC().Format(time.RFC3339)
 		sigPayload := certAuthSignPayload(c.teamID, timestamp, nil)
 		sig := ed25519.Sign(c.signingKey, sigPayload)
@@ -133,12 +171,21 @@ func (c *Client) EventStream(ctx context.Context, deadline time.Time) (*AgentEve
 		if c.stableID != "" {
 			req.Header.Set("X-AWEB-DID-AW", c.stableID)
 		}
+	} else if c.tokenSource != nil {
+		token, err := c.bearerToken(ctx)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
 	}
 
+	finished := c.observeRequest(http.MethodGet, path)
 	resp, err := c.sseClient.Do(req)
 	if err != nil {
-		return nil, err
+		finished(0)
+		return nil, unreachableError(ctx, c.baseURL, err)
 	}
+	finished(resp.StatusCode)
 	if v := resp.Header.Get("X-Latest-Client-Version"); v != "" {
 		c.latestClientVersion.Store(v)
 	}
@@ -147,7 +194,7 @@ func (c *Client) EventStream(ctx context.Context, deadline time.Time) (*AgentEve
 		_ = resp.Body.Close()
 		return nil, &APIError{StatusCode: resp.StatusCode, Body: string(body)}
 	}
-	return newAgentEventStream(resp.Body), nil
+	return resp.Body, nil
 }
 
 func parseAgentEvent(eventName, data string) (AgentEvent, bool, error) {
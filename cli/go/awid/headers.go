@@ -0,0 +1,65 @@
+package awid
+
+import (
+	"context"
+	"net/http"
+)
+
+// authHeaders carry request authentication. Default and context headers
+// never set them, so a caller cannot replace the client's credentials.
+var authHeaders = map[string]bool{
+	"Authorization":           true,
+	"X-Aweb-Timestamp":        true,
+	"X-Awid-Team-Certificate": true,
+	"X-Aweb-Did-Aw":           true,
+}
+
+type headersKey struct{}
+
+// WithHeaders returns a copy of ctx whose requests carry h, for example an
+// X-Request-ID to correlate one call with server logs. Keys in h replace the
+// same keys from an outer WithHeaders and from SetDefaultHeader. Auth headers
+// are ignored.
+func WithHeaders(ctx context.Context, h http.Header) context.Context {
+	merged := http.Header{}
+	for k, vs := range headersFromContext(ctx) {
+		merged[k] = vs
+	}
+	for k, vs := range h {
+		merged[http.CanonicalHeaderKey(k)] = append([]string(nil), vs...)
+	}
+	return context.WithValue(ctx, headersKey{}, merged)
+}
+
+func headersFromContext(ctx context.Context) http.Header {
+	h, _ := ctx.Value(headersKey{}).(http.Header)
+	return h
+}
+
+// SetDefaultHeader sends key: value on every request and event stream the
+// client opens. An empty value removes the header. Auth headers are ignored.
+func (c *Client) SetDefaultHeader(key, value string) {
+	key = http.CanonicalHeaderKey(key)
+	if value == "" {
+		delete(c.defaultHeaders, key)
+		return
+	}
+	if c.defaultHeaders == nil {
+		c.defaultHeaders = http.Header{}
+	}
+	c.defaultHeaders[key] = []string{value}
+}
+
+// applyExtraHeaders copies default and context headers onto req. It runs
+// before the client sets its own headers, so Accept, Content-Type and auth
+// always come from the client.
+func (c *Client) applyExtraHeaders(ctx context.Context, req *http.Request) {
+	for _, h := range []http.Header{c.defaultHeaders, headersFromContext(ctx)} {
+		for k, vs := range h {
+			if authHeaders[k] {
+				continue
+			}
+			req.Header[k] = append([]string(nil), vs...)
+		}
+	}
+}
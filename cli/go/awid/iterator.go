@@ -0,0 +1,75 @@
+package awid
+
+import "context"
+
+// PageFetcher fetches the page that starts at cursor ("" for the first page)
+// and returns its items plus the cursor of the next page ("" when there is
+// none).
+type PageFetcher[T any] func(ctx context.Context, cursor string) (items []T, nextCursor string, err error)
+
+// Iterator walks a cursor-paginated listing one item at a time, holding at
+// most one page in memory.
+type Iterator[T any] struct {
+	fetch   PageFetcher[T]
+	buf     []T
+	cursor  string
+	started bool
+	done    bool
+	err     error
+}
+
+// NewIterator returns an iterator backed by fetch.
+func NewIterator[T any](fetch PageFetcher[T]) *Iterator[T] {
+	return &Iterator[T]{fetch: fetch}
+}
+
+// Next returns the next item. ok is false once the listing is exhausted or a
+// page fetch failed; in the latter case err is set and every later call
+// returns the same error.
+func (it *Iterator[T]) Next(ctx context.Context) (item T, ok bool, err error) {
+	for len(it.buf) == 0 {
+		if it.err != nil || it.done {
+			return item, false, it.err
+		}
+		items, next, err := it.fetch(ctx, it.cursor)
+		if err != nil {
+			it.err = err
+			return item, false, err
+		}
+		// Stop on a repeated cursor too, so a misbehaving server cannot
+		// trap the caller in a loop.
+		if next == "" || (it.started && next == it.cursor) {
+			it.done = true
+		}
+		it.buf = items
+		it.cursor = next
+		it.started = true
+	}
+	item = it.buf[0]
+	it.buf = it.buf[1:]
+	return item, true, nil
+}
+
+// All drains the iterator into a slice.
+func (it *Iterator[T]) All(ctx context.Context) ([]T, error) {
+	var out []T
+	for {
+		item, ok, err := it.Next(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return out, nil
+		}
+		out = append(out, item)
+	}
+}
+
+// nextCursor returns the cursor of the following page, or "" when the
+// response says there is none.
+func nextCursor(hasMore bool, cursor *string) string {
+	if !hasMore || cursor == nil {
+		return ""
+	}
+	return *cursor
+}
@@ -0,0 +1,123 @@
+package awid
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestInboxIterFollowsCursors(t *testing.T) {
+	t.Parallel()
+
+	var cursors []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/messages/inbox" {
+			t.Fatalf("unexpected %s %s", r.Method, r.URL.Path)
+		}
+		if r.URL.Query().Get("limit") != "2" || r.URL.Query().Get("unread_only") != "true" {
+			t.Fatalf("query=%s", r.URL.RawQuery)
+		}
+		cursor := r.URL.Query().Get("cursor")
+		cursors = append(cursors, cursor)
+		switch cursor {
+		case "":
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"messages":    []map[string]any{{"message_id": "m1"}, {"message_id": "m2"}},
+				"has_more":    true,
+				"next_cursor": "p2",
+			})
+		case "p2":
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"messages": []map[string]any{{"message_id": "m3"}},
+			})
+		default:
+			t.Fatalf("unexpected cursor %q", cursor)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	c, err := New(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	it := c.InboxIter(InboxParams{UnreadOnly: true, Limit: 2})
+	var got []string
+	for {
+		msg, ok, err := it.Next(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !ok {
+			break
+		}
+		got = append(got, msg.MessageID)
+	}
+	if len(got) != 3 || got[0] != "m1" || got[2] != "m3" {
+		t.Fatalf("messages=%v", got)
+	}
+	if len(cursors) != 2 || cursors[1] != "p2" {
+		t.Fatalf("cursors=%v", cursors)
+	}
+}
+
+func TestChatListSessionsIterStopsOnRepeatedCursor(t *testing.T) {
+	t.Parallel()
+
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat/sessions" {
+			t.Fatalf("unexpected %s %s", r.Method, r.URL.Path)
+		}
+		requests++
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"sessions":    []map[string]any{{"session_id": "s" + r.URL.Query().Get("cursor")}},
+			"has_more":    true,
+			"next_cursor": "same",
+		})
+	}))
+	t.Cleanup(server.Close)
+
+	c, err := New(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sessions, err := c.ChatListSessionsIter().All(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sessions) != 2 || sessions[0].SessionID != "s" || sessions[1].SessionID != "ssame" {
+		t.Fatalf("sessions=%+v", sessions)
+	}
+	if requests != 2 {
+		t.Fatalf("requests=%d", requests)
+	}
+}
+
+func TestIteratorKeepsReturningFetchError(t *testing.T) {
+	t.Parallel()
+
+	boom := errors.New("boom")
+	calls := 0
+	it := NewIterator(func(context.Context, string) ([]int, string, error) {
+		calls++
+		if calls == 1 {
+			return []int{1}, "next", nil
+		}
+		return nil, "", boom
+	})
+	ctx := context.Background()
+	if v, ok, err := it.Next(ctx); err != nil || !ok || v != 1 {
+		t.Fatalf("first Next=%d,%v,%v", v, ok, err)
+	}
+	for i := 0; i < 2; i++ {
+		if _, ok, err := it.Next(ctx); ok || !errors.Is(err, boom) {
+			t.Fatalf("Next=%v,%v, want boom", ok, err)
+		}
+	}
+	if calls != 2 {
+		t.Fatalf("calls=%d", calls)
+	}
+}
@@ -2,8 +2,17 @@ package awid
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"net/http"
+	"sort"
 	"strings"
+	"sync"
+	"time"
 )
 
 type MessagePriority string
@@ -15,6 +24,101 @@ const (
 	PriorityUrgent MessagePriority = "urgent"
 )
 
+// validPriorities lists the accepted priorities in ascending order.
+var validPriorities = []MessagePriority{PriorityLow, PriorityNormal, PriorityHigh, PriorityUrgent}
+
+// ParsePriority validates a priority string. Matching is case-insensitive and
+// the empty string normalizes to PriorityNormal.
+func ParsePriority(s string) (MessagePriority, error) {
+	value := MessagePriority(strings.ToLower(strings.TrimSpace(s)))
+	if value == "" {
+		return PriorityNormal, nil
+	}
+	for _, p := range validPriorities {
+		if value == p {
+			return p, nil
+		}
+	}
+	names := make([]string, len(validPriorities))
+	for i, p := range validPriorities {
+		names[i] = string(p)
+	}
+	return "", fmt.Errorf("invalid priority %q (valid: %s)", s, strings.Join(names, ", "))
+}
+
+// Attachment is a small file or structured payload carried alongside a mail
+// body. Exactly one of Data (sent base64-encoded) or URL must be set.
+//
+// Signed mail commits to its attachments through AttachmentsDigest, so a
+// verified message's attachments are the ones the sender signed.
+type Attachment struct {
+	Name        string `json:"name"`
+	ContentType string `json:"content_type,omitempty"`
+	Data        []byte `json:"data,omitempty"`
+	URL         string `json:"url,omitempty"`
+}
+
+// MaxAttachmentsSize caps the encoded size of a message's body and
+// attachments. Inbox pages carry attachment data inline, so the cap sits
+// well below MaxResponseSize: a page holding several attachment-laden
+// messages must still fit the limit recipients read it with.
+const MaxAttachmentsSize = 1024 * 1024
+
+// ValidateAttachments checks each attachment and that the message, once
+// encoded, fits MaxAttachmentsSize.
+func ValidateAttachments(body string, attachments []Attachment) error {
+	total := len(body)
+	for i, a := range attachments {
+		if strings.TrimSpace(a.Name) == "" {
+			return fmt.Errorf("attachment %d: name is required", i+1)
+		}
+		if (len(a.Data) == 0) == (strings.TrimSpace(a.URL) == "") {
+			return fmt.Errorf("attachment %q: exactly one of data or url is required", a.Name)
+		}
+		total += len(a.Name) + len(a.ContentType) + len(a.URL) + base64.StdEncoding.EncodedLen(len(a.Data))
+	}
+	if total > MaxAttachmentsSize {
+		return fmt.Errorf("message with attachments is %d bytes encoded, exceeding the %d byte limit", total, MaxAttachmentsSize)
+	}
+	return nil
+}
+
+// AttachmentsDigest is the hex SHA-256 of the canonical JSON of attachments
+// as sent on the wire (empty fields omitted, data base64-encoded). It is ""
+// when there are no attachments, matching a signed payload without the field.
+func AttachmentsDigest(attachments []Attachment) string {
+	if len(attachments) == 0 {
+		return ""
+	}
+	wire := make([]map[string]string, len(attachments))
+	for i, a := range attachments {
+		m := map[string]string{"name": a.Name}
+		if a.ContentType != "" {
+			m["content_type"] = a.ContentType
+		}
+		if len(a.Data) > 0 {
+			m["data"] = base64.StdEncoding.EncodeToString(a.Data)
+		}
+		if a.URL != "" {
+			m["url"] = a.URL
+		}
+		wire[i] = m
+	}
+	canonical, err := CanonicalJSONValue(wire)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(canonical))
+	return hex.EncodeToString(sum[:])
+}
+
+// SendMessageRequest is the body of POST /v1/messages. A caller-set
+// MessageID (a UUID) acts as an idempotency key: the server stores a
+// sender's message once per ID, so retrying with the same ID cannot
+// deliver it twice.
+//
+// ToAliases and ToAgentIDs are for SendMessageMulti, which sends one message
+// per recipient; SendMessage rejects them. They are never sent to the server.
 type SendMessageRequest struct {
 	ToAgentID     string          `json:"to_agent_id,omitempty"`
 	ToAlias       string          `json:"to_alias,omitempty"`
@@ -29,12 +133,80 @@ type SendMessageRequest struct {
 	FromDID       string          `json:"from_did,omitempty"`
 	Signature     string          `json:"signature,omitempty"`
 	SignedPayload string          `json:"signed_payload,omitempty"`
+	Attachments   []Attachment    `json:"attachments,omitempty"`
+
+	ToAliases  []string `json:"-"`
+	ToAgentIDs []string `json:"-"`
 }
 
+// SendMessageResponse reports the delivered message. ToAgentID and ToAlias
+// name the agent the server actually resolved the recipient to; they are
+// empty when the server predates recipient reporting.
 type SendMessageResponse struct {
 	MessageID   string `json:"message_id"`
 	Status      string `json:"status"`
 	DeliveredAt string `json:"delivered_at"`
+	ToAgentID   string `json:"to_agent_id,omitempty"`
+	ToAlias     string `json:"to_alias,omitempty"`
+}
+
+// RecipientCandidate is one of the agents an ambiguous recipient matched.
+type RecipientCandidate struct {
+	AgentID string `json:"agent_id"`
+	Alias   string `json:"alias"`
+	Address string `json:"address,omitempty"`
+}
+
+// AmbiguousRecipientError means the server refused a send because the
+// recipient matched more than one agent. Candidates is empty when the server
+// did not list them.
+type AmbiguousRecipientError struct {
+	Recipient  string
+	Candidates []RecipientCandidate
+	Err        error
+}
+
+func (e *AmbiguousRecipientError) Error() string {
+	if len(e.Candidates) == 0 {
+		return fmt.Sprintf("recipient %q is ambiguous", e.Recipient)
+	}
+	labels := make([]string, len(e.Candidates))
+	for i, cand := range e.Candidates {
+		label := cand.Address
+		if label == "" {
+			label = cand.Alias
+		}
+		labels[i] = fmt.Sprintf("%s (agent_id=%s)", label, cand.AgentID)
+	}
+	return fmt.Sprintf("recipient %q is ambiguous; candidates: %s", e.Recipient, strings.Join(labels, ", "))
+}
+
+func (e *AmbiguousRecipientError) Unwrap() error {
+	return e.Err
+}
+
+// asAmbiguousRecipientError recognizes the server's 409 for recipients that
+// match several agents, in both the structured and the older plain-string
+// detail form.
+func asAmbiguousRecipientError(err error, recipient string) error {
+	code, ok := HTTPStatusCode(err)
+	if !ok || code != http.StatusConflict {
+		return err
+	}
+	body, _ := HTTPErrorBody(err)
+	var structured struct {
+		Detail struct {
+			Code       string               `json:"code"`
+			Candidates []RecipientCandidate `json:"candidates"`
+		} `json:"detail"`
+	}
+	if json.Unmarshal([]byte(body), &structured) == nil && structured.Detail.Code == "ambiguous_recipient" {
+		return &AmbiguousRecipientError{Recipient: recipient, Candidates: structured.Detail.Candidates, Err: err}
+	}
+	if strings.Contains(body, "matches multiple") {
+		return &AmbiguousRecipientError{Recipient: recipient, Err: err}
+	}
+	return err
 }
 
 func (c *Client) SendMessage(ctx context.Context, req *SendMessageRequest) (*SendMessageResponse, error) {
@@ -49,7 +221,18 @@ func (c *Client) sendMessage(ctx context.Context, req *SendMessageRequest, ident
 	if req == nil {
 		return nil, errors.New("aweb: request is required")
 	}
+	if len(req.ToAliases) > 0 || len(req.ToAgentIDs) > 0 {
+		return nil, errors.New("aweb: ToAliases and ToAgentIDs require SendMessageMulti")
+	}
 	payload := *req
+	priority, err := ParsePriority(string(payload.Priority))
+	if err != nil {
+		return nil, err
+	}
+	payload.Priority = priority
+	if err := ValidateAttachments(payload.Body, payload.Attachments); err != nil {
+		return nil, err
+	}
 
 	to := payload.ToAlias
 	if to == "" {
@@ -78,6 +261,8 @@ func (c *Client) sendMessage(ctx context.Context, req *SendMessageRequest, ident
 		Priority:                signedMailPriority(payload.Priority),
 		Subject:                 payload.Subject,
 		Body:                    payload.Body,
+		MessageID:               strings.TrimSpace(payload.MessageID),
+		AttachmentsSHA256:       AttachmentsDigest(payload.Attachments),
 		RequireRecipientBinding: strings.TrimSpace(payload.ToAddress) != "" && c.requireRecipientBinding,
 	})
 	if err != nil {
@@ -94,8 +279,8 @@ func (c *Client) sendMessage(ctx context.Context, req *SendMessageRequest, ident
 	}
 
 	var out SendMessageResponse
-	if err := c.Post(ctx, "/v1/messages", &payload, &out); err != nil {
-		return nil, err
+	if err := c.Post(ctx, c.APIPath("/messages"), &payload, &out); err != nil {
+		return nil, asAmbiguousRecipientError(err, to)
 	}
 	return &out, nil
 }
@@ -124,19 +309,24 @@ type InboxMessage struct {
 	ReplacementAnnouncement *ReplacementAnnouncement `json:"replacement_announcement,omitempty"`
 	VerificationStatus      VerificationStatus       `json:"verification_status,omitempty"`
 	IsContact               *bool                    `json:"is_contact,omitempty"`
+	Attachments             []Attachment             `json:"attachments,omitempty"`
 }
 
 type InboxResponse struct {
-	Messages []InboxMessage `json:"messages"`
+	Messages   []InboxMessage `json:"messages"`
+	HasMore    bool           `json:"has_more,omitempty"`
+	NextCursor *string        `json:"next_cursor,omitempty"`
 }
 
 type InboxParams struct {
 	UnreadOnly bool
 	Limit      int
+	// Cursor resumes from a previous page's NextCursor.
+	Cursor string
 }
 
 func (c *Client) Inbox(ctx context.Context, p InboxParams) (*InboxResponse, error) {
-	path := "/v1/messages/inbox"
+	path := c.APIPath("/messages/inbox")
 	sep := "?"
 	if p.UnreadOnly {
 		path += sep + "unread_only=true"
@@ -146,65 +336,152 @@ func (c *Client) Inbox(ctx context.Context, p InboxParams) (*InboxResponse, erro
 		path += sep + "limit=" + itoa(p.Limit)
 		sep = "&"
 	}
+	if p.Cursor != "" {
+		path += sep + "cursor=" + urlQueryEscape(p.Cursor)
+	}
 	var out InboxResponse
 	if err := c.Get(ctx, path, &out); err != nil {
 		return nil, err
 	}
 	for i := range out.Messages {
-		m := &out.Messages[i]
-		if meta, ok := parseSignedEnvelopeMetadata(m.SignedPayload); ok {
-			if meta.FromDID != "" {
-				m.FromDID = meta.FromDID
-			}
-			if meta.ToDID != "" {
-				m.ToDID = meta.ToDID
-			}
-			if m.FromStableID == "" {
-				m.FromStableID = meta.FromStableID
-			}
-			if m.ToStableID == "" {
-				m.ToStableID = meta.ToStableID
-			}
-			if m.FromAddress == "" && meta.From != "" {
-				m.FromAddress = meta.From
-			}
-			if m.ToAddress == "" && meta.To != "" {
-				m.ToAddress = meta.To
-			}
+		c.prepareInboxMessage(ctx, &out.Messages[i])
+	}
+	return &out, nil
+}
+
+// ErrMessageNotFound is wrapped by GetMessage when the server has no message
+// with the requested ID for this identity. The original *APIError stays in
+// the chain.
+var ErrMessageNotFound = errors.New("aweb: message not found")
+
+// GetMessage fetches one message addressed to (or sent by) the caller, with
+// the same signature verification and trust normalization as Inbox.
+func (c *Client) GetMessage(ctx context.Context, messageID string) (*InboxMessage, error) {
+	messageID = strings.TrimSpace(messageID)
+	if messageID == "" {
+		return nil, errors.New("aweb: message id is required")
+	}
+	var out InboxMessage
+	if err := c.Get(ctx, c.APIPath("/messages/"+urlPathEscape(messageID)), &out); err != nil {
+		if code, ok := HTTPStatusCode(err); ok && code == http.StatusNotFound {
+			return nil, fmt.Errorf("%w %q: %w", ErrMessageNotFound, messageID, err)
 		}
-		from := m.FromAlias
-		if m.FromAddress != "" {
-			from = m.FromAddress
+		return nil, err
+	}
+	c.prepareInboxMessage(ctx, &out)
+	return &out, nil
+}
+
+// prepareInboxMessage fills identity fields from the signed envelope and
+// sets VerificationStatus and IsContact.
+func (c *Client) prepareInboxMessage(ctx context.Context, m *InboxMessage) {
+	if meta, ok := parseSignedEnvelopeMetadata(m.SignedPayload); ok {
+		if meta.FromDID != "" {
+			m.FromDID = meta.FromDID
 		}
-		if m.SignedPayload != "" {
-			m.VerificationStatus, _ = VerifySignedPayload(m.SignedPayload, m.Signature, m.FromDID, m.SigningKeyID)
-		} else {
-			to := m.ToAlias
-			if m.ToAddress != "" {
-				to = m.ToAddress
-			}
-			env := &MessageEnvelope{
-				From:         from,
-				FromDID:      m.FromDID,
-				To:           to,
-				ToDID:        m.ToDID,
-				Type:         "mail",
-				Priority:     signedMailPriority(m.Priority),
-				Subject:      m.Subject,
-				Body:         m.Body,
-				Timestamp:    m.CreatedAt,
-				FromStableID: m.FromStableID,
-				ToStableID:   m.ToStableID,
-				MessageID:    m.MessageID,
-				Signature:    m.Signature,
-				SigningKeyID: m.SigningKeyID,
-			}
-			m.VerificationStatus, _ = VerifyMessage(env)
+		if meta.ToDID != "" {
+			m.ToDID = meta.ToDID
+		}
+		if m.FromStableID == "" {
+			m.FromStableID = meta.FromStableID
+		}
+		if m.ToStableID == "" {
+			m.ToStableID = meta.ToStableID
+		}
+		if m.FromAddress == "" && meta.From != "" {
+			m.FromAddress = meta.From
+		}
+		if m.ToAddress == "" && meta.To != "" {
+			m.ToAddress = meta.To
 		}
-		m.VerificationStatus = c.checkRecipientBinding(m.VerificationStatus, m.ToDID, m.ToStableID)
-		m.VerificationStatus, m.IsContact = c.NormalizeSenderTrust(ctx, m.VerificationStatus, from, m.FromDID, m.FromStableID, m.RotationAnnouncement, m.ReplacementAnnouncement, m.IsContact)
 	}
-	return &out, nil
+	from := m.FromAlias
+	if m.FromAddress != "" {
+		from = m.FromAddress
+	}
+	if m.SignedPayload != "" {
+		m.VerificationStatus, _ = VerifySignedPayload(m.SignedPayload, m.Signature, m.FromDID, m.SigningKeyID)
+		if meta, ok := parseSignedEnvelopeMetadata(m.SignedPayload); ok && m.VerificationStatus == Verified &&
+			meta.AttachmentsSHA256 != AttachmentsDigest(m.Attachments) {
+			m.VerificationStatus = Failed
+		}
+	} else {
+		to := m.ToAlias
+		if m.ToAddress != "" {
+			to = m.ToAddress
+		}
+		env := &MessageEnvelope{
+			From:         from,
+			FromDID:      m.FromDID,
+			To:           to,
+			ToDID:        m.ToDID,
+			Type:         "mail",
+			Priority:     signedMailPriority(m.Priority),
+			Subject:      m.Subject,
+			Body:         m.Body,
+			Timestamp:    m.CreatedAt,
+			FromStableID: m.FromStableID,
+			ToStableID:   m.ToStableID,
+			MessageID:    m.MessageID,
+			Signature:    m.Signature,
+			SigningKeyID: m.SigningKeyID,
+		}
+		m.VerificationStatus, _ = VerifyMessage(env)
+	}
+	m.VerificationStatus = c.checkRecipientBinding(m.VerificationStatus, m.ToDID, m.ToStableID)
+	m.VerificationStatus, m.IsContact = c.NormalizeSenderTrust(ctx, m.VerificationStatus, from, m.FromDID, m.FromStableID, m.RotationAnnouncement, m.ReplacementAnnouncement, m.IsContact)
+}
+
+// InboxIter iterates over the inbox page by page, using p.Limit as the page
+// size. p.Cursor is ignored; iteration always starts at the first page.
+func (c *Client) InboxIter(p InboxParams) *Iterator[InboxMessage] {
+	return NewIterator(func(ctx context.Context, cursor string) ([]InboxMessage, string, error) {
+		page := p
+		page.Cursor = cursor
+		resp, err := c.Inbox(ctx, page)
+		if err != nil {
+			return nil, "", err
+		}
+		return resp.Messages, nextCursor(resp.HasMore, resp.NextCursor), nil
+	})
+}
+
+// threadPageSize is the inbox page size GetThread reads with.
+const threadPageSize = 100
+
+// GetThread returns the messages in threadID, oldest first. There is no
+// thread endpoint, so it pages through the whole inbox, read and unread, and
+// keeps the messages whose ThreadID matches. That means it sees only
+// messages addressed to the caller, not the caller's own replies, and an
+// empty result when the server does not report thread IDs.
+func (c *Client) GetThread(ctx context.Context, threadID string) ([]InboxMessage, error) {
+	threadID = strings.TrimSpace(threadID)
+	if threadID == "" {
+		return nil, errors.New("aweb: thread id is required")
+	}
+	it := c.InboxIter(InboxParams{Limit: threadPageSize})
+	thread := []InboxMessage{}
+	for {
+		m, ok, err := it.Next(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			break
+		}
+		if m.ThreadID != nil && *m.ThreadID == threadID {
+			thread = append(thread, m)
+		}
+	}
+	sort.SliceStable(thread, func(i, j int) bool {
+		ti, erri := ParseTimestamp(thread[i].CreatedAt)
+		tj, errj := ParseTimestamp(thread[j].CreatedAt)
+		if erri != nil || errj != nil {
+			return thread[i].CreatedAt < thread[j].CreatedAt
+		}
+		return ti.Before(tj)
+	})
+	return thread, nil
 }
 
 // signedMailPriority normalizes "" and "normal" to the same empty signed value.
@@ -226,8 +503,260 @@ type AckResponse struct {
 
 func (c *Client) AckMessage(ctx context.Context, messageID string) (*AckResponse, error) {
 	var out AckResponse
-	if err := c.Post(ctx, "/v1/messages/"+urlPathEscape(messageID)+"/ack", nil, &out); err != nil {
+	if err := c.Post(ctx, c.APIPath("/messages/"+urlPathEscape(messageID)+"/ack"), nil, &out); err != nil {
 		return nil, err
 	}
 	return &out, nil
 }
+
+// maxConcurrentSends bounds how many recipients SendMessageMulti sends to at
+// once.
+const maxConcurrentSends = 4
+
+// MultiSendResult is the outcome of sending to one recipient of
+// SendMessageMulti. Exactly one of ToAlias and ToAgentID is set; Error is
+// empty on success.
+type MultiSendResult struct {
+	ToAlias   string               `json:"to_alias,omitempty"`
+	ToAgentID string               `json:"to_agent_id,omitempty"`
+	Response  *SendMessageResponse `json:"response,omitempty"`
+	Error     string               `json:"error,omitempty"`
+	Err       error                `json:"-"`
+}
+
+type MultiSendResponse struct {
+	Results []MultiSendResult `json:"results"`
+}
+
+// Failed returns the results whose send did not succeed.
+func (r *MultiSendResponse) Failed() []MultiSendResult {
+	if r == nil {
+		return nil
+	}
+	var failed []MultiSendResult
+	for _, res := range r.Results {
+		if res.Error != "" {
+			failed = append(failed, res)
+		}
+	}
+	return failed
+}
+
+// SendMessageMulti sends req to every alias in req.ToAliases and then every
+// agent in req.ToAgentIDs, concurrently, as separate messages. The server
+// takes one recipient per message, so each recipient gets its own message
+// ID; a MessageID set on req is ignored. Results follow the recipient order.
+// A failed recipient gets its Error set instead of failing the batch; the
+// returned error is reserved for requests with nothing to send.
+func (c *Client) SendMessageMulti(ctx context.Context, req *SendMessageRequest) (*MultiSendResponse, error) {
+	if req == nil {
+		return nil, errors.New("aweb: request is required")
+	}
+	var recipients []MultiSendResult
+	for _, alias := range req.ToAliases {
+		recipients = append(recipients, MultiSendResult{ToAlias: alias})
+	}
+	for _, id := range req.ToAgentIDs {
+		recipients = append(recipients, MultiSendResult{ToAgentID: id})
+	}
+	if len(recipients) == 0 {
+		return nil, errors.New("aweb: ToAliases or ToAgentIDs is required")
+	}
+
+	reqs := make([]*SendMessageRequest, len(recipients))
+	for i, res := range recipients {
+		single := *req
+		single.ToAliases, single.ToAgentIDs = nil, nil
+		single.ToAlias, single.ToAgentID = res.ToAlias, res.ToAgentID
+		single.MessageID = ""
+		reqs[i] = &single
+	}
+	// Cancellation shows up as per-recipient errors, like any failed send.
+	results, _ := c.SendMessageBatch(ctx, reqs, maxConcurrentSends)
+	for i, out := range results {
+		recipients[i].Response, recipients[i].Err = out.Response, out.Err
+		recipients[i].Error = out.Error
+	}
+	return &MultiSendResponse{Results: recipients}, nil
+}
+
+// Rate-limited (429) sends in SendMessageBatch are retried up to
+// batchRateLimitRetries times, waiting batchRateLimitBackoff and doubling
+// between attempts.
+const batchRateLimitRetries = 3
+
+var batchRateLimitBackoff = time.Second
+
+// SendMessageResult is the outcome of one request of SendMessageBatch.
+// Error is empty on success.
+type SendMessageResult struct {
+	Response *SendMessageResponse `json:"response,omitempty"`
+	Error    string               `json:"error,omitempty"`
+	Err      error                `json:"-"`
+}
+
+// SendMessageBatch sends each request with at most concurrency sends in
+// flight (maxConcurrentSends when concurrency <= 0). Results follow the
+// input order, and a failed send sets its result's Error rather than
+// failing the batch.
+//
+// Requests without a MessageID are given one, so the retries of a
+// rate-limited send cannot deliver it twice; reqs themselves are not
+// modified. When ctx is cancelled, no further requests are dispatched,
+// in-flight sends are waited for, undispatched results carry ctx.Err(),
+// and ctx.Err() is returned.
+func (c *Client) SendMessageBatch(ctx context.Context, reqs []*SendMessageRequest, concurrency int) ([]SendMessageResult, error) {
+	if concurrency <= 0 {
+		concurrency = maxConcurrentSends
+	}
+	results := make([]SendMessageResult, len(reqs))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	dispatched := 0
+dispatch:
+	for i, req := range reqs {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			break dispatch
+		}
+		if ctx.Err() != nil {
+			<-sem
+			break
+		}
+		dispatched = i + 1
+		wg.Add(1)
+		go func(res *SendMessageResult, req *SendMessageRequest) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			res.Response, res.Err = c.sendWithRateLimitRetry(ctx, req)
+			if res.Err != nil {
+				res.Error = res.Err.Error()
+			}
+		}(&results[i], req)
+	}
+	wg.Wait()
+	if dispatched < len(reqs) {
+		for i := dispatched; i < len(reqs); i++ {
+			results[i].Err = ctx.Err()
+			results[i].Error = ctx.Err().Error()
+		}
+		return results, ctx.Err()
+	}
+	return results, nil
+}
+
+// sendWithRateLimitRetry sends a copy of req, retrying 429 responses with
+// backoff under one message ID.
+func (c *Client) sendWithRateLimitRetry(ctx context.Context, req *SendMessageRequest) (*SendMessageResponse, error) {
+	if req == nil {
+		return nil, errors.New("aweb: request is required")
+	}
+	single := *req
+	if strings.TrimSpace(single.MessageID) == "" {
+		id, err := GenerateUUID4()
+		if err != nil {
+			return nil, err
+		}
+		single.MessageID = id
+	}
+	backoff := batchRateLimitBackoff
+	for attempt := 0; ; attempt++ {
+		resp, err := c.SendMessage(ctx, &single)
+		code, ok := HTTPStatusCode(err)
+		if !ok || code != http.StatusTooManyRequests || attempt >= batchRateLimitRetries {
+			return resp, err
+		}
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, err
+		case <-timer.C:
+		}
+		backoff *= 2
+	}
+}
+
+// maxConcurrentAcks bounds the single-ack fallback used by AckMessages when
+// the server has no batch endpoint.
+const maxConcurrentAcks = 8
+
+// maxBulkAckMessages is the most message IDs the server takes in one batch
+// ack; AckMessages splits longer lists.
+const maxBulkAckMessages = 200
+
+// BulkAckResult is the outcome of acknowledging one message in a batch.
+// Error is empty on success.
+type BulkAckResult struct {
+	MessageID      string `json:"message_id"`
+	AcknowledgedAt string `json:"acknowledged_at,omitempty"`
+	Error          string `json:"error,omitempty"`
+}
+
+type BulkAckResponse struct {
+	Results []BulkAckResult `json:"results"`
+}
+
+// Failed returns the results whose ack did not succeed.
+func (r *BulkAckResponse) Failed() []BulkAckResult {
+	if r == nil {
+		return nil
+	}
+	var failed []BulkAckResult
+	for _, res := range r.Results {
+		if res.Error != "" {
+			failed = append(failed, res)
+		}
+	}
+	return failed
+}
+
+type bulkAckRequest struct {
+	MessageIDs []string `json:"message_ids"`
+}
+
+// AckMessages acknowledges a batch of messages in one round trip per
+// maxBulkAckMessages IDs. When the server does not expose the batch endpoint
+// it falls back to concurrent single acks. Per-message failures are reported
+// in the response rather than as an error; the returned error is reserved
+// for failures of the batch call itself.
+func (c *Client) AckMessages(ctx context.Context, messageIDs []string) (*BulkAckResponse, error) {
+	all := &BulkAckResponse{Results: []BulkAckResult{}}
+	for len(messageIDs) > 0 {
+		batch := messageIDs[:min(len(messageIDs), maxBulkAckMessages)]
+		messageIDs = messageIDs[len(batch):]
+		var out BulkAckResponse
+		err := c.Post(ctx, c.APIPath("/messages/ack"), &bulkAckRequest{MessageIDs: batch}, &out)
+		if code, ok := HTTPStatusCode(err); ok && (code == http.StatusNotFound || code == http.StatusMethodNotAllowed) {
+			out = *c.ackMessagesIndividually(ctx, batch)
+		} else if err != nil {
+			return nil, err
+		}
+		all.Results = append(all.Results, out.Results...)
+	}
+	return all, nil
+}
+
+func (c *Client) ackMessagesIndividually(ctx context.Context, messageIDs []string) *BulkAckResponse {
+	results := make([]BulkAckResult, len(messageIDs))
+	sem := make(chan struct{}, maxConcurrentAcks)
+	var wg sync.WaitGroup
+	for i, id := range messageIDs {
+		wg.Add(1)
+		go func(i int, id string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			results[i].MessageID = id
+			resp, err := c.AckMessage(ctx, id)
+			if err != nil {
+				results[i].Error = err.Error()
+				return
+			}
+			results[i].AcknowledgedAt = resp.AcknowledgedAt
+		}(i, id)
+	}
+	wg.Wait()
+	return &BulkAckResponse{Results: results}
+}
@@ -0,0 +1,615 @@
+package awid
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestAckMessagesUsesBatchEndpoint(t *testing.T) {
+	t.Parallel()
+
+	var gotBody bulkAckRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/v1/messages/ack" {
+			t.Fatalf("unexpected %s %s", r.Method, r.URL.Path)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&gotBody); err != nil {
+			t.Fatal(err)
+		}
+		_ = json.NewEncoder(w).Encode(BulkAckResponse{Results: []BulkAckResult{
+			{MessageID: "m1", AcknowledgedAt: "2026-02-08T10:00:00Z"},
+			{MessageID: "m2", Error: "not found"},
+		}})
+	}))
+	t.Cleanup(server.Close)
+
+	c, err := New(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := c.AckMessages(context.Background(), []string{"m1", "m2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(gotBody.MessageIDs) != 2 || gotBody.MessageIDs[0] != "m1" || gotBody.MessageIDs[1] != "m2" {
+		t.Fatalf("message_ids=%v", gotBody.MessageIDs)
+	}
+	failed := resp.Failed()
+	if len(failed) != 1 || failed[0].MessageID != "m2" {
+		t.Fatalf("failed=%+v", failed)
+	}
+}
+
+func TestAckMessagesFallsBackToSingleAcks(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/messages/ack":
+			w.WriteHeader(http.StatusNotFound)
+		case "/v1/messages/m1/ack", "/v1/messages/m3/ack":
+			_ = json.NewEncoder(w).Encode(AckResponse{MessageID: "m", AcknowledgedAt: "2026-02-08T10:00:00Z"})
+		case "/v1/messages/m2/ack":
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"detail":"not yours"}`))
+		default:
+			t.Fatalf("unexpected %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	c, err := New(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := c.AckMessages(context.Background(), []string{"m1", "m2", "m3"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Results) != 3 {
+		t.Fatalf("results=%d", len(resp.Results))
+	}
+	for i, want := range []string{"m1", "m2", "m3"} {
+		if resp.Results[i].MessageID != want {
+			t.Fatalf("results[%d].message_id=%q, want %q", i, resp.Results[i].MessageID, want)
+		}
+	}
+	failed := resp.Failed()
+	if len(failed) != 1 || failed[0].MessageID != "m2" || failed[0].Error == "" {
+		t.Fatalf("failed=%+v", failed)
+	}
+}
+
+func TestSendMessageMultiReportsEachRecipient(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Error(err)
+		}
+		if _, ok := req["to_aliases"]; ok {
+			t.Errorf("fan-out fields sent to the server: %v", req)
+		}
+		to, _ := req["to_alias"].(string)
+		if to == "" {
+			to, _ = req["to_agent_id"].(string)
+		}
+		if to == "ghost" {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"detail":"Agent not found"}`))
+			return
+		}
+		_ = json.NewEncoder(w).Encode(SendMessageResponse{MessageID: "msg-" + to, Status: "delivered"})
+	}))
+	t.Cleanup(server.Close)
+
+	c, err := New(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := c.SendMessageMulti(context.Background(), &SendMessageRequest{
+		ToAliases:  []string{"bob", "ghost"},
+		ToAgentIDs: []string{"agent-3"},
+		Body:       "standup in 5",
+		MessageID:  "shared-id",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Results) != 3 || resp.Results[0].ToAlias != "bob" || resp.Results[1].ToAlias != "ghost" || resp.Results[2].ToAgentID != "agent-3" {
+		t.Fatalf("results=%+v", resp.Results)
+	}
+	if resp.Results[0].Response.MessageID != "msg-bob" || resp.Results[2].Response.MessageID != "msg-agent-3" {
+		t.Fatalf("results=%+v", resp.Results)
+	}
+	failed := resp.Failed()
+	if len(failed) != 1 || failed[0].ToAlias != "ghost" {
+		t.Fatalf("failed=%+v", failed)
+	}
+	if code, ok := HTTPStatusCode(failed[0].Err); !ok || code != http.StatusNotFound {
+		t.Fatalf("err=%v, want the 404", failed[0].Err)
+	}
+
+	if _, err := c.SendMessage(context.Background(), &SendMessageRequest{ToAliases: []string{"bob"}, Body: "x"}); err == nil {
+		t.Fatal("SendMessage accepted ToAliases")
+	}
+}
+
+func TestSendMessageBatchBoundsConcurrencyAndKeepsOrder(t *testing.T) {
+	t.Parallel()
+
+	var mu sync.Mutex
+	inFlight, peak := 0, 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		inFlight++
+		peak = max(peak, inFlight)
+		mu.Unlock()
+		time.Sleep(20 * time.Millisecond)
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+
+		var req SendMessageRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		if req.ToAlias == "ghost" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(SendMessageResponse{MessageID: "msg-" + req.ToAlias, Status: "delivered"})
+	}))
+	t.Cleanup(server.Close)
+
+	c, err := New(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	aliases := []string{"a", "b", "ghost", "d", "e", "f"}
+	reqs := make([]*SendMessageRequest, len(aliases))
+	for i, alias := range aliases {
+		reqs[i] = &SendMessageRequest{ToAlias: alias, Body: "release at 5"}
+	}
+	results, err := c.SendMessageBatch(context.Background(), reqs, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, alias := range aliases {
+		if alias == "ghost" {
+			if code, _ := HTTPStatusCode(results[i].Err); code != http.StatusNotFound {
+				t.Fatalf("ghost err=%v", results[i].Err)
+			}
+			continue
+		}
+		if results[i].Err != nil || results[i].Response.MessageID != "msg-"+alias {
+			t.Fatalf("result %d=%+v", i, results[i])
+		}
+	}
+	if peak > 2 {
+		t.Fatalf("peak in-flight sends=%d, want <= 2", peak)
+	}
+	if reqs[0].MessageID != "" {
+		t.Fatal("SendMessageBatch modified the caller's request")
+	}
+}
+
+func TestSendMessageBatchRetriesRateLimitWithSameMessageID(t *testing.T) {
+	old := batchRateLimitBackoff
+	batchRateLimitBackoff = time.Millisecond
+	t.Cleanup(func() { batchRateLimitBackoff = old })
+
+	var ids []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req SendMessageRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		ids = append(ids, req.MessageID)
+		if len(ids) < 3 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(SendMessageResponse{MessageID: req.MessageID, Status: "delivered"})
+	}))
+	t.Cleanup(server.Close)
+
+	c, err := New(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	results, err := c.SendMessageBatch(context.Background(), []*SendMessageRequest{{ToAlias: "bob", Body: "hi"}}, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if results[0].Err != nil {
+		t.Fatalf("err=%v", results[0].Err)
+	}
+	if len(ids) != 3 || ids[0] == "" || ids[1] != ids[0] || ids[2] != ids[0] {
+		t.Fatalf("message ids across attempts=%q, want one stable id", ids)
+	}
+}
+
+func TestSendMessageBatchStopsDispatchingWhenCancelled(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var mu sync.Mutex
+	sent := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		sent++
+		mu.Unlock()
+		cancel()
+		_ = json.NewEncoder(w).Encode(SendMessageResponse{MessageID: "m", Status: "delivered"})
+	}))
+	t.Cleanup(server.Close)
+
+	c, err := New(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reqs := []*SendMessageRequest{{ToAlias: "a", Body: "x"}, {ToAlias: "b", Body: "x"}, {ToAlias: "c", Body: "x"}}
+	results, err := c.SendMessageBatch(ctx, reqs, 1)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("err=%v, want context.Canceled", err)
+	}
+	if sent != 1 {
+		t.Fatalf("sent=%d, want dispatch to stop after the first", sent)
+	}
+	if !errors.Is(results[2].Err, context.Canceled) || results[2].Error == "" {
+		t.Fatalf("undispatched result=%+v", results[2])
+	}
+}
+
+func TestParsePriority(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		in   string
+		want MessagePriority
+	}{
+		{in: "", want: PriorityNormal},
+		{in: "low", want: PriorityLow},
+		{in: " High ", want: PriorityHigh},
+		{in: "URGENT", want: PriorityUrgent},
+	}
+	for _, tc := range tests {
+		got, err := ParsePriority(tc.in)
+		if err != nil {
+			t.Fatalf("ParsePriority(%q): %v", tc.in, err)
+		}
+		if got != tc.want {
+			t.Fatalf("ParsePriority(%q)=%q, want %q", tc.in, got, tc.want)
+		}
+	}
+
+	_, err := ParsePriority("hgih")
+	if err == nil {
+		t.Fatal("expected error for unknown priority")
+	}
+	if !strings.Contains(err.Error(), "low, normal, high, urgent") {
+		t.Fatalf("error should list valid priorities: %v", err)
+	}
+}
+
+func TestSendMessageRejectsInvalidPriorityBeforeRequest(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Fatalf("unexpected %s %s", r.Method, r.URL.Path)
+	}))
+	t.Cleanup(server.Close)
+
+	c, err := New(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = c.SendMessage(context.Background(), &SendMessageRequest{
+		ToAlias:  "monitor",
+		Body:     "hi",
+		Priority: "hgih",
+	})
+	if err == nil || !strings.Contains(err.Error(), `invalid priority "hgih"`) {
+		t.Fatalf("err=%v", err)
+	}
+}
+
+func TestSendMessageEncodesAttachments(t *testing.T) {
+	t.Parallel()
+
+	var gotBody map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&gotBody)
+		_ = json.NewEncoder(w).Encode(SendMessageResponse{MessageID: "msg-1", Status: "delivered"})
+	}))
+	t.Cleanup(server.Close)
+
+	c, err := New(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = c.SendMessage(context.Background(), &SendMessageRequest{
+		ToAlias: "monitor",
+		Body:    "report attached",
+		Attachments: []Attachment{
+			{Name: "report.json", ContentType: "application/json", Data: []byte(`{"ok":true}`)},
+			{Name: "log", URL: "https://example.com/log.txt"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	atts, ok := gotBody["attachments"].([]any)
+	if !ok || len(atts) != 2 {
+		t.Fatalf("attachments=%v", gotBody["attachments"])
+	}
+	first := atts[0].(map[string]any)
+	if first["data"] != "eyJvayI6dHJ1ZX0=" || first["content_type"] != "application/json" {
+		t.Fatalf("first attachment=%v", first)
+	}
+	if second := atts[1].(map[string]any); second["url"] != "https://example.com/log.txt" {
+		t.Fatalf("second attachment=%v", second)
+	}
+}
+
+func TestSignedMailCommitsToAttachments(t *testing.T) {
+	t.Parallel()
+
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	did := ComputeDIDKey(pub)
+	attachments := []Attachment{{Name: "report.json", ContentType: "application/json", Data: []byte(`{"ok":true}`)}}
+
+	var sent SendMessageRequest
+	var served InboxMessage
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			_ = json.NewDecoder(r.Body).Decode(&sent)
+			_ = json.NewEncoder(w).Encode(SendMessageResponse{MessageID: sent.MessageID, Status: "delivered"})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(served)
+	}))
+	t.Cleanup(server.Close)
+
+	c, err := NewWithIdentity(server.URL, priv, did)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.SendMessage(context.Background(), &SendMessageRequest{
+		ToAlias:     "monitor",
+		Body:        "report attached",
+		Attachments: attachments,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	var signed map[string]any
+	if err := json.Unmarshal([]byte(sent.SignedPayload), &signed); err != nil {
+		t.Fatal(err)
+	}
+	if signed["attachments_sha256"] != AttachmentsDigest(attachments) {
+		t.Fatalf("attachments_sha256=%v, want %s", signed["attachments_sha256"], AttachmentsDigest(attachments))
+	}
+
+	served = InboxMessage{
+		MessageID:     sent.MessageID,
+		FromAlias:     "alice",
+		ToAlias:       "monitor",
+		Body:          sent.Body,
+		FromDID:       sent.FromDID,
+		Signature:     sent.Signature,
+		SignedPayload: sent.SignedPayload,
+		Attachments:   sent.Attachments,
+	}
+	got, err := c.GetMessage(context.Background(), sent.MessageID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.VerificationStatus != Verified {
+		t.Fatalf("untampered status=%s, want verified", got.VerificationStatus)
+	}
+
+	served.Attachments = []Attachment{{Name: "report.json", ContentType: "application/json", Data: []byte(`{"ok":false}`)}}
+	got, err = c.GetMessage(context.Background(), sent.MessageID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.VerificationStatus != Failed {
+		t.Fatalf("tampered status=%s, want failed", got.VerificationStatus)
+	}
+}
+
+func TestValidateAttachmentsRejectsInvalidAndOversized(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		attachments []Attachment
+		want        string
+	}{
+		{name: "missing name", attachments: []Attachment{{Data: []byte("x")}}, want: "name is required"},
+		{name: "neither data nor url", attachments: []Attachment{{Name: "a"}}, want: "exactly one of data or url"},
+		{name: "both data and url", attachments: []Attachment{{Name: "a", Data: []byte("x"), URL: "https://x"}}, want: "exactly one of data or url"},
+		{name: "oversized", attachments: []Attachment{{Name: "a", Data: make([]byte, MaxAttachmentsSize)}}, want: "byte limit"},
+	}
+	for _, tc := range tests {
+		err := ValidateAttachments("body", tc.attachments)
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Fatalf("%s: err=%v, want %q", tc.name, err, tc.want)
+		}
+	}
+}
+
+func TestSendMessageReportsAmbiguousRecipient(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		detail     any
+		candidates int
+	}{
+		{
+			name: "structured",
+			detail: map[string]any{
+				"code":    "ambiguous_recipient",
+				"message": "Address acme.com/bob matches multiple local agents",
+				"candidates": []map[string]any{
+					{"agent_id": "agent-1", "alias": "bob", "address": "acme.com/bob"},
+					{"agent_id": "agent-2", "alias": "bob"},
+				},
+			},
+			candidates: 2,
+		},
+		{
+			name:   "plain",
+			detail: "Address acme.com/bob matches multiple local agents",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusConflict)
+				_ = json.NewEncoder(w).Encode(map[string]any{"detail": tc.detail})
+			}))
+			t.Cleanup(server.Close)
+
+			c, err := New(server.URL)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = c.SendMessage(context.Background(), &SendMessageRequest{ToAlias: "bob", Body: "hi"})
+			var ambErr *AmbiguousRecipientError
+			if !errors.As(err, &ambErr) {
+				t.Fatalf("err=%v, want AmbiguousRecipientError", err)
+			}
+			if ambErr.Recipient != "bob" || len(ambErr.Candidates) != tc.candidates {
+				t.Fatalf("recipient=%q candidates=%v", ambErr.Recipient, ambErr.Candidates)
+			}
+			if code, _ := HTTPStatusCode(err); code != http.StatusConflict {
+				t.Fatalf("status=%d", code)
+			}
+			if tc.candidates > 0 && !strings.Contains(err.Error(), "acme.com/bob (agent_id=agent-1)") {
+				t.Fatalf("err=%q", err.Error())
+			}
+		})
+	}
+}
+
+func TestSendMessageReturnsResolvedRecipient(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"message_id":   "msg-1",
+			"status":       "delivered",
+			"delivered_at": "2026-03-17T12:00:00Z",
+			"to_agent_id":  "agent-1",
+			"to_alias":     "bob",
+		})
+	}))
+	t.Cleanup(server.Close)
+
+	c, err := New(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := c.SendMessage(context.Background(), &SendMessageRequest{ToAlias: "bob", Body: "hi"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.ToAgentID != "agent-1" || resp.ToAlias != "bob" {
+		t.Fatalf("to_agent_id=%q to_alias=%q", resp.ToAgentID, resp.ToAlias)
+	}
+}
+
+func TestGetMessageFetchesOneAndReportsNotFound(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			t.Errorf("method=%s", r.Method)
+		}
+		switch r.URL.Path {
+		case "/v1/messages/msg-1":
+			_ = json.NewEncoder(w).Encode(InboxMessage{MessageID: "msg-1", FromAlias: "bob", Subject: "hi", Body: "full body"})
+		case "/v1/messages/msg-gone":
+			w.WriteHeader(http.StatusNotFound)
+		default:
+			t.Errorf("unexpected path=%s", r.URL.Path)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	c, err := New(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg, err := c.GetMessage(context.Background(), "msg-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg.Body != "full body" || msg.VerificationStatus != Unverified {
+		t.Fatalf("msg=%+v", msg)
+	}
+	_, err = c.GetMessage(context.Background(), "msg-gone")
+	if !errors.Is(err, ErrMessageNotFound) {
+		t.Fatalf("err=%v, want ErrMessageNotFound", err)
+	}
+	if code, ok := HTTPStatusCode(err); !ok || code != http.StatusNotFound {
+		t.Fatalf("status=%d ok=%v", code, ok)
+	}
+}
+
+func TestGetThreadCollectsMatchingMessagesAcrossPagesOldestFirst(t *testing.T) {
+	t.Parallel()
+
+	thread := func(id string) *string { return &id }
+	pages := map[string]InboxResponse{
+		"": {
+			Messages: []InboxMessage{
+				{MessageID: "m3", ThreadID: thread("t1"), CreatedAt: "2026-03-10T10:03:00Z"},
+				{MessageID: "other", ThreadID: thread("t2"), CreatedAt: "2026-03-10T10:02:00Z"},
+				{MessageID: "loose", CreatedAt: "2026-03-10T10:02:30Z"},
+			},
+			HasMore:    true,
+			NextCursor: thread("c1"),
+		},
+		"c1": {
+			Messages: []InboxMessage{
+				{MessageID: "m1", ThreadID: thread("t1"), CreatedAt: "2026-03-10T10:01:00Z"},
+			},
+		},
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("unread_only") != "" {
+			t.Errorf("thread should include read messages: %s", r.URL.RawQuery)
+		}
+		_ = json.NewEncoder(w).Encode(pages[r.URL.Query().Get("cursor")])
+	}))
+	t.Cleanup(server.Close)
+
+	c, err := New(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := c.GetThread(context.Background(), "t1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0].MessageID != "m1" || got[1].MessageID != "m3" {
+		t.Fatalf("thread=%+v", got)
+	}
+	if _, err := c.GetThread(context.Background(), " "); err == nil {
+		t.Fatal("expected an error for an empty thread id")
+	}
+}
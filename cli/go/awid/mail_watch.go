@@ -0,0 +1,70 @@
+package awid
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// inboxWatchStreamLife is the deadline WatchInbox asks for on each
+// connection. The server caps streams at five minutes anyway; the watch
+// reconnects when one ends.
+const inboxWatchStreamLife = 5 * time.Minute
+
+// WatchInbox streams mail addressed to the caller, built on the
+// actionable_mail events of GET /v1/events/stream.
+//
+// The channel first receives the unread mail present at connect time, then
+// new mail as it arrives. Each message is fetched with GetMessage, so it
+// carries the same verification as Inbox. Dropped connections, and the
+// server's regular end of stream, are reopened with backoff. The server
+// restates unread mail on every connect; messages already emitted are
+// skipped, and a message whose fetch failed is retried when it is restated.
+// The channel is closed when ctx is done or the server rejects a reconnect
+// with a 4xx status. The initial connection error is returned directly.
+func (c *Client) WatchInbox(ctx context.Context) (<-chan InboxMessage, error) {
+	stream, err := c.openInboxWatchStream(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	out := make(chan InboxMessage, 16)
+	go c.watchInbox(ctx, stream, out)
+	return out, nil
+}
+
+func (c *Client) openInboxWatchStream(ctx context.Context, lastEventID string) (*SSEStream, error) {
+	deadline := time.Now().Add(inboxWatchStreamLife).UTC().Format(time.RFC3339)
+	return c.openSSEStream(ctx, c.APIPath("/events/stream?deadline="+urlQueryEscape(deadline)), lastEventID)
+}
+
+func (c *Client) watchInbox(ctx context.Context, stream *SSEStream, out chan<- InboxMessage) {
+	defer close(out)
+	handle := func(ctx context.Context, ev *SSEEvent) bool {
+		messageID := inboxWatchKey(ev)
+		if messageID == "" {
+			return true
+		}
+		msg, err := c.GetMessage(ctx, messageID)
+		if err != nil {
+			// A message deleted in the meantime will not come back.
+			return errors.Is(err, ErrMessageNotFound)
+		}
+		select {
+		case out <- *msg:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+	_ = newResilientStream(c.openInboxWatchStream, inboxWatchKey).run(ctx, stream, handle)
+}
+
+// inboxWatchKey returns the message ID of an actionable_mail event, or ""
+// for any other event.
+func inboxWatchKey(ev *SSEEvent) string {
+	parsed, ok, err := parseAgentEvent(ev.Event, ev.Data)
+	if err != nil || !ok || parsed.Type != AgentEventActionableMail {
+		return ""
+	}
+	return parsed.MessageID
+}
@@ -0,0 +1,83 @@
+package awid
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWatchInboxSkipsMailRestatedAfterReconnect(t *testing.T) {
+	t.Parallel()
+
+	var connections atomic.Int32
+	var fetches atomic.Int32
+	mail := func(id string) string {
+		return fmt.Sprintf("event: actionable_mail\ndata: {\"type\":\"actionable_mail\",\"message_id\":%q,\"from_alias\":\"alice\"}\n\n", id)
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/v1/events/stream":
+			if r.URL.Query().Get("deadline") == "" {
+				t.Errorf("missing deadline")
+			}
+			w.Header().Set("Content-Type", "text/event-stream")
+			flusher := w.(http.Flusher)
+			fmt.Fprint(w, "event: connected\ndata: {}\n\n")
+			switch connections.Add(1) {
+			case 1:
+				fmt.Fprint(w, mail("m1")+mail("m2"))
+				flusher.Flush()
+			default:
+				// A fresh connection restates all unread mail.
+				fmt.Fprint(w, mail("m1")+mail("m2")+mail("m3"))
+				flusher.Flush()
+				<-r.Context().Done()
+			}
+		case strings.HasPrefix(r.URL.Path, "/v1/messages/"):
+			fetches.Add(1)
+			id := strings.TrimPrefix(r.URL.Path, "/v1/messages/")
+			_ = json.NewEncoder(w).Encode(map[string]any{"message_id": id, "from_alias": "alice", "body": "body " + id})
+		default:
+			t.Errorf("unexpected %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	c, err := New(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	messages, err := c.WatchInbox(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, want := range []string{"m1", "m2", "m3"} {
+		select {
+		case m, ok := <-messages:
+			if !ok {
+				t.Fatalf("channel closed after %d messages", i)
+			}
+			if m.MessageID != want || m.Body != "body "+want {
+				t.Fatalf("message %d=%+v, want %s", i, m, want)
+			}
+		case <-ctx.Done():
+			t.Fatalf("timed out waiting for message %d", i)
+		}
+	}
+	cancel()
+	for m := range messages {
+		t.Fatalf("duplicate message %+v", m)
+	}
+	if n := fetches.Load(); n != 3 {
+		t.Fatalf("fetched %d messages, want 3", n)
+	}
+}
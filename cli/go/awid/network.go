@@ -26,7 +26,7 @@ type NetworkDirectoryParams struct {
 }
 
 func (c *Client) NetworkDirectorySearch(ctx context.Context, p NetworkDirectoryParams) (*NetworkDirectoryResponse, error) {
-	path := "/v1/network/directory"
+	path := c.APIPath("/network/directory")
 	sep := "?"
 	if p.Capability != "" {
 		path += sep + "capability=" + urlQueryEscape(p.Capability)
@@ -52,7 +52,7 @@ func (c *Client) NetworkDirectorySearch(ctx context.Context, p NetworkDirectoryP
 
 func (c *Client) NetworkDirectoryGet(ctx context.Context, domain, handle string) (*NetworkDirectoryAgent, error) {
 	var out NetworkDirectoryAgent
-	if err := c.Get(ctx, "/v1/network/directory/"+urlPathEscape(domain)+"/"+urlPathEscape(handle), &out); err != nil {
+	if err := c.Get(ctx, c.APIPath("/network/directory/"+urlPathEscape(domain)+"/"+urlPathEscape(handle)), &out); err != nil {
 		return nil, err
 	}
 	return &out, nil
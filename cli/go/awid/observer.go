@@ -0,0 +1,59 @@
+package awid
+
+import (
+	"strings"
+	"time"
+)
+
+// Observer receives client activity for metrics and tracing. Implementations
+// must be safe for concurrent use and should return quickly; they run inline
+// with the request.
+//
+// Paths exclude the query string but still carry session, message and agent
+// IDs, so they take unbounded values. Map them to route templates before
+// using them as metric labels.
+type Observer interface {
+	// RequestStarted is called before an HTTP request is sent.
+	RequestStarted(method, path string)
+	// RequestFinished is called once response headers arrive, or the request
+	// fails. status is 0 when no response was received.
+	RequestFinished(method, path string, status int, latency time.Duration)
+	// StreamEvent is called for each event read from a chat session stream,
+	// with the SSE event name as kind.
+	StreamEvent(sessionID, kind string)
+}
+
+// NopObserver is an Observer that does nothing. It is the client default.
+type NopObserver struct{}
+
+func (NopObserver) RequestStarted(string, string)                      {}
+func (NopObserver) RequestFinished(string, string, int, time.Duration) {}
+func (NopObserver) StreamEvent(string, string)                         {}
+
+// SetObserver installs o to receive client activity. A nil o restores
+// NopObserver.
+func (c *Client) SetObserver(o Observer) {
+	if o == nil {
+		o = NopObserver{}
+	}
+	c.observer = o
+}
+
+// observeRequest reports the start of a request and returns the function
+// that reports its end.
+func (c *Client) observeRequest(method, path string) func(status int) {
+	o := c.currentObserver()
+	path, _, _ = strings.Cut(path, "?")
+	o.RequestStarted(method, path)
+	start := time.Now()
+	return func(status int) {
+		o.RequestFinished(method, path, status, time.Since(start))
+	}
+}
+
+func (c *Client) currentObserver() Observer {
+	if c.observer == nil {
+		return NopObserver{}
+	}
+	return c.observer
+}
@@ -0,0 +1,108 @@
+package awid
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type recordingObserver struct {
+	mu    sync.Mutex
+	calls []string
+}
+
+func (o *recordingObserver) record(call string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.calls = append(o.calls, call)
+}
+
+func (o *recordingObserver) RequestStarted(method, path string) {
+	o.record("start " + method + " " + path)
+}
+
+func (o *recordingObserver) RequestFinished(method, path string, status int, latency time.Duration) {
+	if latency < 0 {
+		o.record("negative latency")
+	}
+	o.record("finish " + method + " " + path + " " + itoa(status))
+}
+
+func (o *recordingObserver) StreamEvent(sessionID, kind string) {
+	o.record("event " + sessionID + " " + kind)
+}
+
+func TestObserverSeesRequestsAndStreamEvents(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/agents":
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte(`{"detail":"down"}`))
+		case "/v1/chat/sessions/s1/stream":
+			w.Header().Set("Content-Type", "text/event-stream")
+			_, _ = w.Write([]byte("event: message\ndata: {}\n\nevent: read_receipt\ndata: {}\n\n"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	c, err := New(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	obs := &recordingObserver{}
+	c.SetObserver(obs)
+
+	if _, err := c.ListAgents(context.Background()); err == nil {
+		t.Fatal("expected error from 503")
+	}
+	stream, err := c.ChatStream(context.Background(), "s1", time.Now().Add(2*time.Second), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stream.Close()
+	for i := 0; i < 2; i++ {
+		if _, err := stream.Next(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want := []string{
+		"start GET /v1/agents",
+		"finish GET /v1/agents 503",
+		"start GET /v1/chat/sessions/s1/stream",
+		"finish GET /v1/chat/sessions/s1/stream 200",
+		"event s1 message",
+		"event s1 read_receipt",
+	}
+	obs.mu.Lock()
+	defer obs.mu.Unlock()
+	if strings.Join(obs.calls, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("calls:\n%s\nwant:\n%s", strings.Join(obs.calls, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestSetObserverNilRestoresNop(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"agents":[]}`))
+	}))
+	t.Cleanup(server.Close)
+
+	c, err := New(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.SetObserver(nil)
+	if _, err := c.ListAgents(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -7,7 +7,6 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
 	"strings"
 	"time"
@@ -76,9 +75,12 @@ func (c *Client) BootstrapRedeem(ctx context.Context, req *BootstrapRedeemReques
 	httpReq.Header.Set("Authorization", fmt.Sprintf("DIDKey %s %s", c.did, base64.RawStdEncoding.EncodeToString(signature)))
 	httpReq.Header.Set("X-AWEB-Timestamp", timestamp)
 
+	if c.closed.Load() {
+		return nil, ErrClientClosed
+	}
 	resp, err := c.httpClient.Do(httpReq)
 	if err != nil {
-		return nil, err
+		return nil, unreachableError(ctx, c.baseURL, err)
 	}
 	defer resp.Body.Close()
 
@@ -86,8 +88,7 @@ func (c *Client) BootstrapRedeem(ctx context.Context, req *BootstrapRedeemReques
 		c.latestClientVersion.Store(v)
 	}
 
-	limited := io.LimitReader(resp.Body, MaxResponseSize)
-	data, err := io.ReadAll(limited)
+	data, err := c.ReadResponseBody(resp)
 	if err != nil {
 		return nil, err
 	}
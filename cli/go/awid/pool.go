@@ -0,0 +1,54 @@
+package awid
+
+import "net/http"
+
+// SetConnectionPool sizes the pool of connections used for API requests.
+// net/http keeps at most two idle connections per host, so a server that
+// embeds the client and makes many concurrent calls, such as SendMessage
+// from a worker pool, spends its time opening and closing connections.
+// For batch workloads set maxIdlePerHost to roughly the number of
+// concurrent callers, for example SetConnectionPool(100, 32, 64). A limit
+// of zero or less keeps the http.DefaultTransport value (100 idle in
+// total, 2 idle per host, no cap on connections per host).
+//
+// The pool only applies while the client uses its own transport. It is
+// ignored when an *http.Client with a Transport was passed to
+// SetHTTPClient; size that transport directly. Event streams are not
+// affected.
+func (c *Client) SetConnectionPool(maxIdle, maxIdlePerHost, maxConnsPerHost int) {
+	t := ownTransport(&c.httpClient, &c.ownTransport)
+	if t == nil {
+		return
+	}
+	defaults := http.DefaultTransport.(*http.Transport)
+	t.MaxIdleConns = positiveOr(maxIdle, defaults.MaxIdleConns)
+	t.MaxIdleConnsPerHost = positiveOr(maxIdlePerHost, defaults.MaxIdleConnsPerHost)
+	t.MaxConnsPerHost = positiveOr(maxConnsPerHost, defaults.MaxConnsPerHost)
+}
+
+func positiveOr(n, fallback int) int {
+	if n > 0 {
+		return n
+	}
+	return fallback
+}
+
+// ownTransport returns the transport of *hc that this client created,
+// giving *hc one cloned from http.DefaultTransport on first use. The
+// http.Client is copied rather than changed in place, since the caller of
+// SetHTTPClient or SetSSEClient may still hold it. It returns nil when *hc
+// has a Transport the caller supplied.
+func ownTransport(hc **http.Client, own **http.Transport) *http.Transport {
+	if (*hc).Transport != nil {
+		if t, ok := (*hc).Transport.(*http.Transport); ok && t == *own {
+			return t
+		}
+		return nil
+	}
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	next := **hc
+	next.Transport = t
+	*hc = &next
+	*own = t
+	return t
+}
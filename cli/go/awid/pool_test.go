@@ -0,0 +1,103 @@
+package awid
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSetConnectionPoolSizesOwnTransportOnly(t *testing.T) {
+	t.Parallel()
+
+	c, err := New("http://example.test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.SetConnectionPool(10, 8, 0)
+	tr, ok := c.HTTPClient().Transport.(*http.Transport)
+	if !ok {
+		t.Fatalf("transport=%T, want *http.Transport", c.HTTPClient().Transport)
+	}
+	if tr.MaxIdleConns != 10 || tr.MaxIdleConnsPerHost != 8 || tr.MaxConnsPerHost != 0 {
+		t.Fatalf("pool=%d/%d/%d", tr.MaxIdleConns, tr.MaxIdleConnsPerHost, tr.MaxConnsPerHost)
+	}
+	if c.HTTPClient().Timeout != DefaultTimeout || c.HTTPClient().CheckRedirect == nil {
+		t.Fatal("SetConnectionPool dropped the client's timeout or redirect policy")
+	}
+
+	// Resizing replaces the pool it created.
+	c.SetConnectionPool(0, 0, 4)
+	if tr := c.HTTPClient().Transport.(*http.Transport); tr.MaxConnsPerHost != 4 || tr.MaxIdleConnsPerHost != 0 {
+		t.Fatalf("resized pool=%d/%d", tr.MaxIdleConnsPerHost, tr.MaxConnsPerHost)
+	}
+
+	// A caller's client is copied, not modified.
+	mine := &http.Client{Timeout: time.Second}
+	c.SetHTTPClient(mine)
+	c.SetConnectionPool(10, 8, 4)
+	if mine.Transport != nil {
+		t.Fatal("SetConnectionPool modified the caller's http.Client")
+	}
+
+	// A caller's transport is left alone.
+	custom := &http.Transport{}
+	c.SetHTTPClient(&http.Client{Transport: custom})
+	c.SetConnectionPool(10, 8, 4)
+	if c.HTTPClient().Transport != custom {
+		t.Fatal("SetConnectionPool replaced a caller-supplied transport")
+	}
+}
+
+// BenchmarkConcurrentRequests compares the net/http pool with a batch-sized
+// one under 32 concurrent callers:
+//
+//	go test ./awid -run '^$' -bench ConcurrentRequests
+func BenchmarkConcurrentRequests(b *testing.B) {
+	const callers = 32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(time.Millisecond)
+		_, _ = w.Write([]byte(`{"status":"ok"}`))
+	}))
+	b.Cleanup(server.Close)
+
+	for _, bc := range []struct {
+		name string
+		pool func(*Client)
+	}{
+		{"default", func(*Client) {}},
+		{"pooled", func(c *Client) { c.SetConnectionPool(100, callers, 0) }},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			c, err := New(server.URL)
+			if err != nil {
+				b.Fatal(err)
+			}
+			bc.pool(c)
+			b.Cleanup(func() { _ = c.Close() })
+
+			jobs := make(chan struct{})
+			var wg sync.WaitGroup
+			for i := 0; i < callers; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					var out map[string]string
+					for range jobs {
+						if err := c.Get(context.Background(), "/v1/things", &out); err != nil {
+							b.Error(err)
+						}
+					}
+				}()
+			}
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				jobs <- struct{}{}
+			}
+			close(jobs)
+			wg.Wait()
+		})
+	}
+}
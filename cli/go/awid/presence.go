@@ -0,0 +1,144 @@
+package awid
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+)
+
+// AgentPresenceEventType identifies a presence transition.
+type AgentPresenceEventType string
+
+const (
+	PresenceOnline   AgentPresenceEventType = "online"
+	PresenceOffline  AgentPresenceEventType = "offline"
+	PresenceLastSeen AgentPresenceEventType = "last_seen"
+
+	// PresenceStreamError is the last event before the channel closes
+	// because the server rejected a reconnect. It carries no agent; Error
+	// says why.
+	PresenceStreamError AgentPresenceEventType = "error"
+)
+
+// AgentPresenceEvent is one presence transition emitted by WatchPresence.
+// Snapshot is true for the events that describe agents already online when
+// the watch started.
+type AgentPresenceEvent struct {
+	Type     AgentPresenceEventType `json:"type"`
+	Agent    AgentView              `json:"agent"`
+	Snapshot bool                   `json:"snapshot,omitempty"`
+	Error    string                 `json:"error,omitempty"`
+}
+
+// WatchPresence streams presence transitions for the authenticated team from
+// GET /v1/agents/presence/stream.
+//
+// The channel first receives one PresenceOnline event (Snapshot set) per
+// agent online at connect time, then deltas. Dropped connections are
+// reopened with backoff, resuming from the last event ID when the server
+// assigns them and skipping replayed events; the server's fresh snapshot is
+// diffed against the known state so only real transitions are emitted,
+// never a second snapshot. The channel is closed when ctx is done, or after
+// a PresenceStreamError event when the server rejects a reconnect with a
+// 4xx status. The initial connection error is returned directly.
+func (c *Client) WatchPresence(ctx context.Context) (<-chan AgentPresenceEvent, error) {
+	stream, err := c.openPresenceStream(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	out := make(chan AgentPresenceEvent, 16)
+	go c.watchPresence(ctx, stream, out)
+	return out, nil
+}
+
+func (c *Client) openPresenceStream(ctx context.Context, lastEventID string) (*SSEStream, error) {
+	return c.openSSEStream(ctx, c.APIPath("/agents/presence/stream"), lastEventID)
+}
+
+func (c *Client) watchPresence(ctx context.Context, stream *SSEStream, out chan<- AgentPresenceEvent) {
+	defer close(out)
+	w := &presenceWatcher{out: out, online: make(map[string]AgentView)}
+	if err := newResilientStream(c.openPresenceStream, sseEventID).run(ctx, stream, w.handle); err != nil {
+		w.emit(ctx, AgentPresenceEvent{Type: PresenceStreamError, Error: err.Error()})
+	}
+}
+
+// presenceWatcher tracks which agents are online so reconnect snapshots can
+// be turned into deltas.
+type presenceWatcher struct {
+	out         chan<- AgentPresenceEvent
+	online      map[string]AgentView
+	seenInitial bool
+}
+
+// handle applies one stream event.
+func (w *presenceWatcher) handle(ctx context.Context, ev *SSEEvent) bool {
+	switch strings.TrimSpace(ev.Event) {
+	case "snapshot":
+		var payload struct {
+			Agents []AgentView `json:"agents"`
+		}
+		if json.Unmarshal([]byte(ev.Data), &payload) == nil {
+			w.applySnapshot(ctx, payload.Agents)
+		}
+	case string(PresenceOnline), string(PresenceOffline), string(PresenceLastSeen):
+		var agent AgentView
+		if json.Unmarshal([]byte(ev.Data), &agent) == nil {
+			w.applyDelta(ctx, AgentPresenceEventType(strings.TrimSpace(ev.Event)), agent)
+		}
+	}
+	return true
+}
+
+func (w *presenceWatcher) applySnapshot(ctx context.Context, agents []AgentView) {
+	initial := !w.seenInitial
+	w.seenInitial = true
+	current := make(map[string]AgentView, len(agents))
+	for _, agent := range agents {
+		agent.Online = true
+		key := presenceKey(agent)
+		current[key] = agent
+		if _, known := w.online[key]; !known {
+			w.emit(ctx, AgentPresenceEvent{Type: PresenceOnline, Agent: agent, Snapshot: initial})
+		}
+	}
+	for key, agent := range w.online {
+		if _, still := current[key]; !still {
+			agent.Online = false
+			w.emit(ctx, AgentPresenceEvent{Type: PresenceOffline, Agent: agent})
+		}
+	}
+	w.online = current
+}
+
+func (w *presenceWatcher) applyDelta(ctx context.Context, kind AgentPresenceEventType, agent AgentView) {
+	key := presenceKey(agent)
+	switch kind {
+	case PresenceOnline:
+		agent.Online = true
+		w.online[key] = agent
+	case PresenceOffline:
+		agent.Online = false
+		delete(w.online, key)
+	case PresenceLastSeen:
+		if known, ok := w.online[key]; ok {
+			known.LastSeen = agent.LastSeen
+			w.online[key] = known
+		}
+	}
+	w.emit(ctx, AgentPresenceEvent{Type: kind, Agent: agent})
+}
+
+func (w *presenceWatcher) emit(ctx context.Context, ev AgentPresenceEvent) {
+	select {
+	case w.out <- ev:
+	case <-ctx.Done():
+	}
+}
+
+func presenceKey(agent AgentView) string {
+	if agent.AgentID != "" {
+		return agent.AgentID
+	}
+	return agent.Alias
+}
@@ -0,0 +1,134 @@
+package awid
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWatchPresenceEmitsSnapshotThenDiffsReconnects(t *testing.T) {
+	t.Parallel()
+
+	var connections atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/agents/presence/stream" {
+			t.Errorf("unexpected %s %s", r.Method, r.URL.Path)
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		flusher := w.(http.Flusher)
+		switch connections.Add(1) {
+		case 1:
+			fmt.Fprint(w, "event: snapshot\ndata: {\"agents\":[{\"agent_id\":\"a\",\"alias\":\"alice\"},{\"agent_id\":\"b\",\"alias\":\"bob\"}]}\n\n")
+			fmt.Fprint(w, "event: online\ndata: {\"agent_id\":\"c\",\"alias\":\"carol\"}\n\n")
+			flusher.Flush()
+		default:
+			fmt.Fprint(w, "event: snapshot\ndata: {\"agents\":[{\"agent_id\":\"a\",\"alias\":\"alice\"},{\"agent_id\":\"c\",\"alias\":\"carol\"},{\"agent_id\":\"d\",\"alias\":\"dave\"}]}\n\n")
+			flusher.Flush()
+			<-r.Context().Done()
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	c, err := New(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	events, err := c.WatchPresence(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []struct {
+		kind     AgentPresenceEventType
+		alias    string
+		snapshot bool
+	}{
+		{PresenceOnline, "alice", true},
+		{PresenceOnline, "bob", true},
+		{PresenceOnline, "carol", false},
+		{PresenceOnline, "dave", false},
+		{PresenceOffline, "bob", false},
+	}
+	for i, w := range want {
+		select {
+		case ev, ok := <-events:
+			if !ok {
+				t.Fatalf("channel closed after %d events", i)
+			}
+			if ev.Type != w.kind || ev.Agent.Alias != w.alias || ev.Snapshot != w.snapshot {
+				t.Fatalf("event %d=%+v, want %s %s snapshot=%v", i, ev, w.kind, w.alias, w.snapshot)
+			}
+		case <-ctx.Done():
+			t.Fatalf("timed out waiting for event %d", i)
+		}
+	}
+
+	cancel()
+	for range events {
+	}
+}
+
+func TestWatchPresenceReturnsInitialConnectError(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	t.Cleanup(server.Close)
+
+	c, err := New(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.WatchPresence(context.Background()); err == nil {
+		t.Fatal("expected error")
+	} else if code, ok := HTTPStatusCode(err); !ok || code != http.StatusNotFound {
+		t.Fatalf("err=%v", err)
+	}
+}
+
+func TestWatchPresenceReportsRejectedReconnect(t *testing.T) {
+	t.Parallel()
+
+	var connections atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if connections.Add(1) > 1 {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "event: snapshot\ndata: {\"agents\":[]}\n\n")
+	}))
+	t.Cleanup(server.Close)
+
+	c, err := New(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	events, err := c.WatchPresence(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var last AgentPresenceEvent
+	for ev := range events {
+		last = ev
+	}
+	if ctx.Err() != nil {
+		t.Fatal("timed out waiting for the channel to close")
+	}
+	if last.Type != PresenceStreamError || !strings.Contains(last.Error, "403") {
+		t.Fatalf("last event=%+v, want a stream error for the 403", last)
+	}
+}
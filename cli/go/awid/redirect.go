@@ -0,0 +1,32 @@
+package awid
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// MaxRedirects is how many redirects a request follows before failing.
+const MaxRedirects = 5
+
+// CheckRedirect is the redirect policy of the HTTP clients this package
+// creates. It stops after MaxRedirects, and drops the auth headers when a
+// redirect leaves the scheme and host:port of the original request.
+// net/http already drops Authorization across domains, but not for a
+// subdomain or a different port, and never the signed X-AWEB-* and team
+// certificate headers. Pass it as http.Client.CheckRedirect when supplying
+// clients through SetHTTPClient or SetSSEClient.
+func CheckRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= MaxRedirects {
+		return fmt.Errorf("aweb: stopped after %d redirects", MaxRedirects)
+	}
+	if len(via) > 0 && !sameOrigin(req, via[0]) {
+		for k := range authHeaders {
+			req.Header.Del(k)
+		}
+	}
+	return nil
+}
+
+func sameOrigin(a, b *http.Request) bool {
+	return a.URL.Scheme == b.URL.Scheme && a.URL.Host == b.URL.Host
+}
@@ -0,0 +1,99 @@
+package awid
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRedirectToAnotherHostDropsAuthHeaders(t *testing.T) {
+	t.Parallel()
+
+	var got http.Header
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+		_ = json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+	}))
+	t.Cleanup(other.Close)
+	origin := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/same" {
+			http.Redirect(w, r, "/v1/landed", http.StatusFound)
+			return
+		}
+		if r.URL.Path == "/v1/landed" {
+			got = r.Header.Clone()
+			_ = json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+			return
+		}
+		http.Redirect(w, r, other.URL+"/v1/elsewhere", http.StatusFound)
+	}))
+	t.Cleanup(origin.Close)
+
+	c, err := New(origin.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.SetTokenSource(StaticToken("aw_sk_secret"))
+
+	var out map[string]string
+	if err := c.Get(context.Background(), "/v1/things", &out); err != nil {
+		t.Fatal(err)
+	}
+	if v := got.Get("Authorization"); v != "" {
+		t.Fatalf("bearer token forwarded to another host: %q", v)
+	}
+
+	// Same host: credentials still go along.
+	if err := c.Get(context.Background(), "/v1/same", &out); err != nil {
+		t.Fatal(err)
+	}
+	if got.Get("Authorization") != "Bearer aw_sk_secret" {
+		t.Fatalf("same-host redirect lost Authorization: %v", got)
+	}
+
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signed, err := NewWithIdentity(origin.URL, key, ComputeDIDKey(key.Public().(ed25519.PublicKey)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	signed.SetStableID("did:aw:test")
+	if err := signed.Get(context.Background(), "/v1/things", &out); err != nil {
+		t.Fatal(err)
+	}
+	for k := range authHeaders {
+		if v := got.Get(k); v != "" {
+			t.Fatalf("%s forwarded to another host: %q", k, v)
+		}
+	}
+}
+
+func TestRedirectLoopStopsAtMaxRedirects(t *testing.T) {
+	t.Parallel()
+
+	hops := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hops++
+		http.Redirect(w, r, "/v1/again", http.StatusFound)
+	}))
+	t.Cleanup(server.Close)
+
+	c, err := New(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = c.Get(context.Background(), "/v1/things", nil)
+	if err == nil || !strings.Contains(err.Error(), "stopped after 5 redirects") {
+		t.Fatalf("err=%v", err)
+	}
+	if hops != MaxRedirects {
+		t.Fatalf("hops=%d, want %d", hops, MaxRedirects)
+	}
+}
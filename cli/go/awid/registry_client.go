@@ -414,7 +414,7 @@ func (c *RegistryClient) httpClient() *http.Client {
 	if c != nil && c.HTTPClient != nil {
 		return c.HTTPClient
 	}
-	return &http.Client{Timeout: DefaultTimeout}
+	return &http.Client{Timeout: DefaultTimeout, CheckRedirect: CheckRedirect}
 }
 
 func signedPathHeaders(method, path string, signingKey ed25519.PrivateKey) map[string]string {
@@ -0,0 +1,120 @@
+package awid
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+)
+
+const (
+	streamReconnectMin = time.Second
+	streamReconnectMax = 30 * time.Second
+
+	// streamSeenLimit bounds the dedup set of a long-running watch; the
+	// oldest keys are forgotten first.
+	streamSeenLimit = 4096
+)
+
+// resilientStream keeps an SSE subscription alive across dropped
+// connections. Reconnects wait with exponential backoff between min and
+// max, and send the last event ID the server assigned as Last-Event-ID so a
+// server with a replay buffer resumes where the old connection stopped.
+//
+// Servers without replay restate their current state on every connect.
+// When key is set it names each event, and an event whose name was already
+// handled is dropped instead of reaching the handler again; events with an
+// empty name are always delivered.
+type resilientStream struct {
+	open     func(ctx context.Context, lastEventID string) (*SSEStream, error)
+	key      func(ev *SSEEvent) string
+	min, max time.Duration
+
+	lastEventID string
+	seen        map[string]struct{}
+	seenOrder   []string
+}
+
+func newResilientStream(open func(ctx context.Context, lastEventID string) (*SSEStream, error), key func(ev *SSEEvent) string) *resilientStream {
+	return &resilientStream{
+		open: open,
+		key:  key,
+		min:  streamReconnectMin,
+		max:  streamReconnectMax,
+		seen: make(map[string]struct{}),
+	}
+}
+
+// sseEventID keys events by the ID the server assigned them.
+func sseEventID(ev *SSEEvent) string {
+	return ev.ID
+}
+
+// run reads stream, then each reopened stream, until ctx is done or the
+// server rejects a reconnect with a 4xx status other than 429, and returns
+// that rejection (nil when ctx ended the run). handle reports whether it
+// consumed the event; one it declines is not recorded as seen, so a later
+// replay or restatement delivers it again. The stream being read is closed
+// before run returns.
+func (r *resilientStream) run(ctx context.Context, stream *SSEStream, handle func(ctx context.Context, ev *SSEEvent) bool) error {
+	backoff := r.min
+	for {
+		if r.consume(ctx, stream, handle) {
+			backoff = r.min
+		}
+		_ = stream.Close()
+		for {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-time.After(backoff):
+			}
+			backoff = min(backoff*2, r.max)
+			next, err := r.open(ctx, r.lastEventID)
+			if err == nil {
+				stream = next
+				break
+			}
+			var apiErr *APIError
+			if errors.As(err, &apiErr) && apiErr.StatusCode >= 400 && apiErr.StatusCode < 500 && apiErr.StatusCode != http.StatusTooManyRequests {
+				return err
+			}
+		}
+	}
+}
+
+// consume reads stream until it fails, reporting whether any event arrived.
+func (r *resilientStream) consume(ctx context.Context, stream *SSEStream, handle func(ctx context.Context, ev *SSEEvent) bool) bool {
+	received := false
+	for {
+		ev, err := stream.Next()
+		if err != nil || ctx.Err() != nil {
+			return received
+		}
+		received = true
+		if ev.ID != "" {
+			r.lastEventID = ev.ID
+		}
+		key := ""
+		if r.key != nil {
+			key = r.key(ev)
+		}
+		if key != "" {
+			if _, dup := r.seen[key]; dup {
+				continue
+			}
+		}
+		if handle(ctx, ev) && key != "" {
+			r.remember(key)
+		}
+	}
+}
+
+func (r *resilientStream) remember(key string) {
+	r.seen[key] = struct{}{}
+	r.seenOrder = append(r.seenOrder, key)
+	if len(r.seenOrder) > streamSeenLimit {
+		delete(r.seen, r.seenOrder[0])
+		r.seenOrder = r.seenOrder[1:]
+	}
+}
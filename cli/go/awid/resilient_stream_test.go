@@ -0,0 +1,165 @@
+package awid
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newTestResilientStream(t *testing.T, c *Client, path string, key func(ev *SSEEvent) string) *resilientStream {
+	t.Helper()
+	r := newResilientStream(func(ctx context.Context, lastEventID string) (*SSEStream, error) {
+		return c.openSSEStream(ctx, path, lastEventID)
+	}, key)
+	r.min = 10 * time.Millisecond
+	r.max = 40 * time.Millisecond
+	return r
+}
+
+func TestResilientStreamResumesAfterDisconnectWithoutDuplicates(t *testing.T) {
+	t.Parallel()
+
+	var connections atomic.Int32
+	lastEventIDs := make(chan string, 4)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lastEventIDs <- r.Header.Get("Last-Event-ID")
+		w.Header().Set("Content-Type", "text/event-stream")
+		flusher := w.(http.Flusher)
+		switch connections.Add(1) {
+		case 1:
+			// Drop the connection mid-stream, before the third event.
+			fmt.Fprint(w, "id: 1\ndata: a\n\nid: 2\ndata: b\n\n")
+			flusher.Flush()
+		default:
+			// Replay from the requested point, overlapping what was seen.
+			fmt.Fprint(w, "id: 2\ndata: b\n\nid: 3\ndata: c\n\n")
+			flusher.Flush()
+			<-r.Context().Done()
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	c, err := New(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	r := newTestResilientStream(t, c, "/stream", sseEventID)
+	stream, err := r.open(ctx, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make(chan string, 8)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		r.run(ctx, stream, func(ctx context.Context, ev *SSEEvent) bool {
+			got <- ev.Data
+			return true
+		})
+	}()
+
+	for i, want := range []string{"a", "b", "c"} {
+		select {
+		case data := <-got:
+			if data != want {
+				t.Fatalf("event %d=%q, want %q", i, data, want)
+			}
+		case <-ctx.Done():
+			t.Fatalf("timed out waiting for event %d", i)
+		}
+	}
+	if first, second := <-lastEventIDs, <-lastEventIDs; first != "" || second != "2" {
+		t.Fatalf("Last-Event-ID=%q then %q, want none then 2", first, second)
+	}
+	cancel()
+	<-done
+	select {
+	case data := <-got:
+		t.Fatalf("duplicate event %q", data)
+	default:
+	}
+}
+
+func TestResilientStreamRedeliversDeclinedEvents(t *testing.T) {
+	t.Parallel()
+
+	var connections atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		connections.Add(1)
+		// Like the events stream, every connection restates the same state.
+		fmt.Fprint(w, "event: item\ndata: x\n\n")
+		w.(http.Flusher).Flush()
+	}))
+	t.Cleanup(server.Close)
+
+	c, err := New(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	r := newTestResilientStream(t, c, "/stream", func(ev *SSEEvent) string { return ev.Data })
+	stream, err := r.open(ctx, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var handled atomic.Int32
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		r.run(ctx, stream, func(ctx context.Context, ev *SSEEvent) bool {
+			// Decline the first delivery, as a failed fetch would.
+			return handled.Add(1) > 1
+		})
+	}()
+
+	for connections.Load() < 4 && ctx.Err() == nil {
+		time.Sleep(5 * time.Millisecond)
+	}
+	cancel()
+	<-done
+	if n := handled.Load(); n != 2 {
+		t.Fatalf("handled %d times, want 2", n)
+	}
+}
+
+func TestResilientStreamStopsWhenReconnectIsRejected(t *testing.T) {
+	t.Parallel()
+
+	var connections atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if connections.Add(1) > 1 {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "data: a\n\n")
+	}))
+	t.Cleanup(server.Close)
+
+	c, err := New(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	r := newTestResilientStream(t, c, "/stream", nil)
+	stream, err := r.open(ctx, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.run(ctx, stream, func(ctx context.Context, ev *SSEEvent) bool { return true })
+	if ctx.Err() != nil {
+		t.Fatal("run did not stop on 401")
+	}
+}
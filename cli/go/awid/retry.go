@@ -0,0 +1,130 @@
+package awid
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"time"
+)
+
+// RetryPolicy controls how requests sent through DoRaw, and so through Do,
+// Get and the other request helpers, are retried. The zero value sends each
+// request once, which is the client default.
+//
+// Two budgets apply to a request. AttemptTimeout bounds each attempt,
+// including reading its response body, so one slow attempt cannot use up
+// the whole budget. The caller's context carries the overall deadline for
+// all attempts and the backoff between them. A retry starts only when, after
+// the backoff, at least MinAttemptBudget of the overall deadline is left;
+// otherwise the last attempt's response or error is returned. The HTTP
+// client's own timeout (DefaultTimeout unless replaced with SetHTTPClient)
+// still applies to every attempt, so the shorter of the two wins.
+//
+// Only idempotent requests (GET, HEAD, OPTIONS, PUT and DELETE) are retried,
+// and only after a connection failure, an attempt timeout, or a 502, 503 or
+// 504 response. TLS handshake and certificate failures are not retried: the
+// same certificate fails the same way every time. Event streams are never
+// retried here.
+type RetryPolicy struct {
+	// MaxAttempts is the most attempts per request, counting the first.
+	// Values below 2 disable retries.
+	MaxAttempts int
+	// AttemptTimeout bounds each attempt. Zero leaves attempts bounded only
+	// by the context and the HTTP client timeout.
+	AttemptTimeout time.Duration
+	// Backoff is the wait before the first retry; it doubles for each
+	// retry after that. Zero means DefaultRetryBackoff.
+	Backoff time.Duration
+}
+
+const (
+	// DefaultRetryBackoff is the wait before the first retry when
+	// RetryPolicy.Backoff is zero.
+	DefaultRetryBackoff = 200 * time.Millisecond
+
+	// MinAttemptBudget is the least time an attempt is given. A retry is
+	// not started with less than this left before the context deadline.
+	MinAttemptBudget = 100 * time.Millisecond
+)
+
+// SetRetryPolicy sets how requests are retried; see RetryPolicy. The zero
+// policy turns retries off.
+func (c *Client) SetRetryPolicy(p RetryPolicy) {
+	c.retryPolicy = p
+}
+
+func (p RetryPolicy) attempts(method string) int {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return max(p.MaxAttempts, 1)
+	}
+	return 1
+}
+
+func (p RetryPolicy) backoff() time.Duration {
+	if p.Backoff <= 0 {
+		return DefaultRetryBackoff
+	}
+	return p.Backoff
+}
+
+// attemptContext derives the context for one attempt from the caller's.
+func (p RetryPolicy) attemptContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if p.AttemptTimeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, p.AttemptTimeout)
+}
+
+// retryable reports whether an attempt that produced resp or err is worth
+// repeating. ctx is the caller's context: once it is done, nothing is.
+func retryable(ctx context.Context, resp *http.Response, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	if err != nil {
+		if tlsFailure(err) != "" {
+			return false
+		}
+		var netErr net.Error
+		return errors.Is(err, ErrServerUnreachable) ||
+			errors.Is(err, context.DeadlineExceeded) ||
+			(errors.As(err, &netErr) && netErr.Timeout())
+	}
+	switch resp.StatusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// waitToRetry sleeps for backoff unless that would leave less than
+// MinAttemptBudget before ctx's deadline. It reports whether to retry.
+func waitToRetry(ctx context.Context, backoff time.Duration) bool {
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < backoff+MinAttemptBudget {
+		return false
+	}
+	timer := time.NewTimer(backoff)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// cancelOnClose ends an attempt's context when its response body is closed,
+// so the attempt timeout also covers reading the body.
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
@@ -0,0 +1,151 @@
+package awid
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRetryPolicyGivesEachAttemptItsOwnTimeout(t *testing.T) {
+	t.Parallel()
+
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if attempts.Add(1) == 1 {
+			// The first attempt hangs until the client gives up on it.
+			<-r.Context().Done()
+			return
+		}
+		_, _ = w.Write([]byte(`{"ok":true}`))
+	}))
+	t.Cleanup(server.Close)
+
+	c, err := New(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.SetRetryPolicy(RetryPolicy{MaxAttempts: 3, AttemptTimeout: 200 * time.Millisecond, Backoff: 10 * time.Millisecond})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	start := time.Now()
+	var out struct {
+		OK bool `json:"ok"`
+	}
+	if err := c.Get(ctx, "/v1/things", &out); err != nil {
+		t.Fatal(err)
+	}
+	if !out.OK || attempts.Load() != 2 {
+		t.Fatalf("out=%+v attempts=%d, want success on the second attempt", out, attempts.Load())
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Fatalf("took %s; the slow attempt used up the overall budget", elapsed)
+	}
+}
+
+func TestRetryPolicyStopsNearTheOverallDeadline(t *testing.T) {
+	t.Parallel()
+
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		<-r.Context().Done()
+	}))
+	t.Cleanup(server.Close)
+
+	c, err := New(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.SetRetryPolicy(RetryPolicy{MaxAttempts: 5, AttemptTimeout: 100 * time.Millisecond, Backoff: 100 * time.Millisecond})
+
+	// After the first attempt times out, the backoff would leave less than
+	// MinAttemptBudget, so no second attempt starts.
+	ctx, cancel := context.WithTimeout(context.Background(), 250*time.Millisecond)
+	defer cancel()
+	err = c.Get(ctx, "/v1/things", nil)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err=%v, want the attempt timeout", err)
+	}
+	if attempts.Load() != 1 {
+		t.Fatalf("attempts=%d, want 1", attempts.Load())
+	}
+}
+
+func TestRetryPolicyRetriesOnlyIdempotentRequests(t *testing.T) {
+	t.Parallel()
+
+	var gets, posts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		counter := &gets
+		if r.Method == http.MethodPost {
+			counter = &posts
+		}
+		if counter.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	t.Cleanup(server.Close)
+
+	c, err := New(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	// The zero policy sends once.
+	if code, _ := HTTPStatusCode(c.Get(ctx, "/v1/things", nil)); code != http.StatusServiceUnavailable {
+		t.Fatalf("without a policy: status=%d", code)
+	}
+	gets.Store(0)
+
+	c.SetRetryPolicy(RetryPolicy{MaxAttempts: 2, Backoff: time.Millisecond})
+	if err := c.Get(ctx, "/v1/things", nil); err != nil || gets.Load() != 2 {
+		t.Fatalf("GET err=%v attempts=%d, want a retried success", err, gets.Load())
+	}
+	if code, _ := HTTPStatusCode(c.Post(ctx, "/v1/things", map[string]string{}, nil)); code != http.StatusServiceUnavailable || posts.Load() != 1 {
+		t.Fatalf("POST status=%d attempts=%d, want one attempt", code, posts.Load())
+	}
+}
+
+func TestRetryPolicyDoesNotRetryTLSFailures(t *testing.T) {
+	t.Parallel()
+
+	var conns atomic.Int32
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		t.Error("handler reached despite an untrusted certificate")
+	}))
+	server.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			conns.Add(1)
+		}
+	}
+	server.Config.ErrorLog = log.New(io.Discard, "", 0)
+	server.StartTLS()
+	t.Cleanup(server.Close)
+
+	// The client does not trust the test server's certificate.
+	c, err := New(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.SetRetryPolicy(RetryPolicy{MaxAttempts: 3, Backoff: time.Millisecond})
+
+	err = c.Get(context.Background(), "/v1/things", nil)
+	var unreachable *ServerUnreachableError
+	if !errors.As(err, &unreachable) || unreachable.Reason != UnreachableTLS {
+		t.Fatalf("err=%v, want a TLS failure", err)
+	}
+	if conns.Load() != 1 {
+		t.Fatalf("connections=%d, want 1", conns.Load())
+	}
+}
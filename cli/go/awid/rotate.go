@@ -61,7 +61,7 @@ func (c *Client) RotateKey(ctx context.Context, req *RotateKeyRequest) (*RotateK
 	}
 
 	var resp RotateKeyResponse
-	if err := c.Put(ctx, "/v1/agents/me/rotate", wire, &resp); err != nil {
+	if err := c.Put(ctx, c.APIPath("/agents/me/rotate"), wire, &resp); err != nil {
 		return nil, err
 	}
 	return &resp, nil
@@ -120,7 +120,7 @@ func (c *Client) RotateKeyCustodial(ctx context.Context, req *RotateKeyCustodial
 		wire.NewPublicKey = base64.RawURLEncoding.EncodeToString(req.NewPublicKey)
 	}
 	var resp RotateKeyResponse
-	if err := c.Put(ctx, "/v1/agents/me/rotate", wire, &resp); err != nil {
+	if err := c.Put(ctx, c.APIPath("/agents/me/rotate"), wire, &resp); err != nil {
 		return nil, err
 	}
 	return &resp, nil
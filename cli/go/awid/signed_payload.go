@@ -9,6 +9,8 @@ type signedEnvelopeMetadata struct {
 	ToDID        string `json:"to_did"`
 	FromStableID string `json:"from_stable_id"`
 	ToStableID   string `json:"to_stable_id"`
+
+	AttachmentsSHA256 string `json:"attachments_sha256"`
 }
 
 func parseSignedEnvelopeMetadata(payload string) (signedEnvelopeMetadata, bool) {
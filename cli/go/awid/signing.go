@@ -80,6 +80,8 @@ type MessageEnvelope struct {
 	ReplyTo       string `json:"reply_to,omitempty"`
 	SenderLeaving bool   `json:"sender_leaving,omitempty"`
 	HangOn        bool   `json:"hang_on,omitempty"`
+	// AttachmentsSHA256 is AttachmentsDigest of the mail's attachments.
+	AttachmentsSHA256 string `json:"attachments_sha256,omitempty"`
 
 	RequireRecipientBinding bool `json:"-"`
 
@@ -295,6 +297,9 @@ func CanonicalJSON(env *MessageEnvelope) string {
 	}
 
 	// Optional fields included when present.
+	if env.AttachmentsSHA256 != "" {
+		fields = append(fields, field{"attachments_sha256", jsonStringValue(env.AttachmentsSHA256)})
+	}
 	if env.FromStableID != "" {
 		fields = append(fields, field{"from_stable_id", jsonStringValue(env.FromStableID)})
 	}
@@ -2,11 +2,26 @@ package awid
 
 import (
 	"bufio"
+	"bytes"
+	"errors"
 	"io"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
+// ErrStreamIdle is returned by SSEStream.Next when no bytes (including comment
+// heartbeats) arrived within the stream's idle timeout. Callers should treat it
+// as a signal to reconnect rather than as a fatal error.
+var ErrStreamIdle = errors.New("aweb: sse stream idle")
+
+// DefaultSSEIdleTimeout is the idle window applied to chat streams. The server
+// sends a keepalive comment every 30s, so this leaves room for one missed
+// heartbeat before the connection is considered dead.
+const DefaultSSEIdleTimeout = 75 * time.Second
+
 // SSEEvent is a single Server-Sent Event.
 type SSEEvent struct {
 	Event string
@@ -21,72 +36,238 @@ type SSEEvent struct {
 type SSEStream struct {
 	body io.ReadCloser
 	r    *bufio.Reader
+
+	mu          sync.Mutex
+	idleTimeout time.Duration
+	idleTimer   *time.Timer
+	idle        atomic.Bool
+	onComment   func(comment string)
+	onEvent     func(ev *SSEEvent)
+
+	// Line splitting state for readLine.
+	started bool
+	skipLF  bool
 }
 
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// DefaultSSEBufferSize is the read buffer size used by NewSSEStream.
+const DefaultSSEBufferSize = 4096
+
+// NewSSEStream wraps body. No idle timeout is applied until SetIdleTimeout is called.
 func NewSSEStream(body io.ReadCloser) *SSEStream {
-	return &SSEStream{body: body, r: bufio.NewReader(body)}
+	return NewSSEStreamSize(body, DefaultSSEBufferSize)
+}
+
+// NewSSEStreamSize is NewSSEStream with a read buffer of size bytes. Lines
+// longer than the buffer still parse; a larger buffer means fewer reads and
+// copies for streams that carry big events. A size below the default uses
+// the default.
+func NewSSEStreamSize(body io.ReadCloser, size int) *SSEStream {
+	if size < DefaultSSEBufferSize {
+		size = DefaultSSEBufferSize
+	}
+	s := &SSEStream{body: body}
+	s.r = bufio.NewReaderSize(sseBodyReader{s: s}, size)
+	return s
+}
+
+// SetIdleTimeout makes Next return ErrStreamIdle when no bytes arrive for d.
+// The body is closed when the timeout fires. A zero or negative d disables it.
+func (s *SSEStream) SetIdleTimeout(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.idleTimer != nil {
+		s.idleTimer.Stop()
+		s.idleTimer = nil
+	}
+	s.idleTimeout = d
+	if d <= 0 {
+		return
+	}
+	s.idleTimer = time.AfterFunc(d, func() {
+		s.idle.Store(true)
+		if s.body != nil {
+			_ = s.body.Close()
+		}
+	})
+}
+
+// SetCommentHandler registers fn to receive comment lines (such as keepalive
+// heartbeats) without the leading colon. Comments are otherwise discarded.
+func (s *SSEStream) SetCommentHandler(fn func(comment string)) {
+	s.onComment = fn
 }
 
 func (s *SSEStream) Close() error {
+	s.mu.Lock()
+	if s.idleTimer != nil {
+		s.idleTimer.Stop()
+	}
+	s.mu.Unlock()
 	if s.body == nil {
 		return nil
 	}
 	return s.body.Close()
 }
 
-// Next reads the next SSE event. It returns io.EOF when the stream ends.
+func (s *SSEStream) resetIdleTimer() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.idleTimer != nil && !s.idle.Load() {
+		s.idleTimer.Reset(s.idleTimeout)
+	}
+}
+
+// sseBodyReader reads from the stream body, resetting the idle timer on every
+// successful read and reporting ErrStreamIdle once the timer has fired.
+type sseBodyReader struct {
+	s *SSEStream
+}
+
+func (r sseBodyReader) Read(p []byte) (int, error) {
+	if r.s.body == nil {
+		return 0, io.EOF
+	}
+	n, err := r.s.body.Read(p)
+	if n > 0 {
+		r.s.resetIdleTimer()
+	}
+	if err != nil && r.s.idle.Load() {
+		return n, ErrStreamIdle
+	}
+	return n, err
+}
+
+// Next reads the next SSE event. It returns io.EOF when the stream ends and
+// ErrStreamIdle when the idle timeout fired.
 func (s *SSEStream) Next() (*SSEEvent, error) {
-	var eventName string
-	var dataLines []string
-	var eventID string
-	retry := -1
+	ev, err := s.next()
+	if err == nil && s.onEvent != nil {
+		s.onEvent(ev)
+	}
+	return ev, err
+}
+
+// sseEventBuilder collects the fields of one event until it is dispatched.
+type sseEventBuilder struct {
+	eventName string
+	dataLines []string
+	eventID   string
+	retry     int
+}
+
+func (b *sseEventBuilder) pending() bool {
+	return b.eventName != "" || len(b.dataLines) > 0
+}
+
+func (b *sseEventBuilder) event() *SSEEvent {
+	return &SSEEvent{
+		Event: b.eventName,
+		Data:  strings.Join(b.dataLines, "\n"),
+		ID:    b.eventID,
+		Retry: b.retry,
+	}
+}
+
+// next assembles one event. Lines may end in LF, CRLF or a lone CR, and
+// may mix them. An event is dispatched at a blank line, or when the body
+// ends after at least one field, so a server that closes the connection
+// instead of sending the final blank line still delivers its last event.
+// While the connection stays open an unterminated event is held back, as
+// the spec requires.
+func (s *SSEStream) next() (*SSEEvent, error) {
+	b := sseEventBuilder{retry: -1}
 
 	for {
-		line, err := s.r.ReadString('\n')
+		line, err := s.readLine()
 		if err != nil {
-			if err == io.EOF && (eventName != "" || len(dataLines) > 0) {
-				return &SSEEvent{
-					Event: eventName,
-					Data:  strings.Join(dataLines, "\n"),
-					ID:    eventID,
-					Retry: retry,
-				}, nil
+			if line != "" {
+				s.applyLine(&b, line)
+			}
+			if err == io.EOF && b.pending() {
+				return b.event(), nil
 			}
 			return nil, err
 		}
 
-		line = strings.TrimRight(line, "\r\n")
 		if line == "" {
-			if eventName == "" && len(dataLines) == 0 {
+			if !b.pending() {
 				continue
 			}
-			return &SSEEvent{
-				Event: eventName,
-				Data:  strings.Join(dataLines, "\n"),
-				ID:    eventID,
-				Retry: retry,
-			}, nil
+			return b.event(), nil
 		}
-		if strings.HasPrefix(line, ":") {
-			continue
+		s.applyLine(&b, line)
+	}
+}
+
+func (s *SSEStream) applyLine(b *sseEventBuilder, line string) {
+	if strings.HasPrefix(line, ":") {
+		if s.onComment != nil {
+			s.onComment(strings.TrimPrefix(strings.TrimPrefix(line, ":"), " "))
 		}
+		return
+	}
 
-		field, value, ok := parseSSEField(line)
-		if !ok {
-			continue
+	field, value, ok := parseSSEField(line)
+	if !ok {
+		return
+	}
+	switch field {
+	case "event":
+		b.eventName = value
+	case "data":
+		b.dataLines = append(b.dataLines, value)
+	case "id":
+		b.eventID = value
+	case "retry":
+		if ms, err := strconv.Atoi(value); err == nil && ms >= 0 {
+			b.retry = ms
+		}
+	}
+}
+
+// readLine returns the next line without its terminator. A CR ends the
+// line at once rather than waiting to see whether LF follows, so a server
+// using bare CR is not stalled; the LF of a CRLF pair is skipped at the
+// start of the following call instead. A UTF-8 byte order mark at the very
+// start of the stream is dropped. On error, line holds any bytes read
+// since the last terminator.
+func (s *SSEStream) readLine() (string, error) {
+	var line []byte
+	for {
+		if _, err := s.r.Peek(1); err != nil {
+			return string(line), err
 		}
-		switch field {
-		case "event":
-			eventName = value
-		case "data":
-			dataLines = append(dataLines, value)
-		case "id":
-			eventID = value
-		case "retry":
-			if ms, err := strconv.Atoi(value); err == nil && ms >= 0 {
-				retry = ms
+		buf, _ := s.r.Peek(s.r.Buffered())
+		if s.skipLF {
+			s.skipLF = false
+			if buf[0] == '\n' {
+				_, _ = s.r.Discard(1)
+				continue
+			}
+		}
+		if !s.started {
+			if len(buf) < len(utf8BOM) && bytes.HasPrefix(utf8BOM, buf) {
+				// Wait for the rest of a possible BOM before deciding.
+				if full, err := s.r.Peek(len(utf8BOM)); err == nil {
+					buf = full
+				}
 			}
+			s.started = true
+			if bytes.HasPrefix(buf, utf8BOM) {
+				_, _ = s.r.Discard(len(utf8BOM))
+				continue
+			}
+		}
+		if i := bytes.IndexAny(buf, "\r\n"); i >= 0 {
+			line = append(line, buf[:i]...)
+			s.skipLF = buf[i] == '\r'
+			_, _ = s.r.Discard(i + 1)
+			return string(line), nil
 		}
+		line = append(line, buf...)
+		_, _ = s.r.Discard(len(buf))
 	}
 }
 
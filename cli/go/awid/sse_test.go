@@ -1,9 +1,12 @@
 package awid
 
 import (
+	"errors"
 	"io"
 	"strings"
 	"testing"
+	"testing/iotest"
+	"time"
 )
 
 func TestSSEStreamParsesIDAndRetry(t *testing.T) {
@@ -71,3 +74,183 @@ func TestSSEStreamIgnoresInvalidRetry(t *testing.T) {
 		t.Fatalf("retry=%d", ev.Retry)
 	}
 }
+
+func TestSSEStreamReturnsErrStreamIdleWhenNoBytesArrive(t *testing.T) {
+	t.Parallel()
+
+	pr, pw := io.Pipe()
+	defer pw.Close()
+	stream := NewSSEStream(pr)
+	stream.SetIdleTimeout(50 * time.Millisecond)
+	defer stream.Close()
+
+	_, err := stream.Next()
+	if !errors.Is(err, ErrStreamIdle) {
+		t.Fatalf("err=%v, want ErrStreamIdle", err)
+	}
+}
+
+func TestSSEStreamHeartbeatsResetIdleTimerAndReachCommentHandler(t *testing.T) {
+	t.Parallel()
+
+	pr, pw := io.Pipe()
+	stream := NewSSEStream(pr)
+	stream.SetIdleTimeout(150 * time.Millisecond)
+	defer stream.Close()
+
+	comments := make(chan string, 10)
+	stream.SetCommentHandler(func(comment string) { comments <- comment })
+
+	go func() {
+		for i := 0; i < 4; i++ {
+			time.Sleep(75 * time.Millisecond)
+			_, _ = io.WriteString(pw, ": keepalive\n\n")
+		}
+		_, _ = io.WriteString(pw, "event: message\ndata: ok\n\n")
+	}()
+
+	ev, err := stream.Next()
+	if err != nil {
+		t.Fatalf("Next returned error: %v", err)
+	}
+	if ev.Data != "ok" {
+		t.Fatalf("data=%q", ev.Data)
+	}
+	if len(comments) != 4 {
+		t.Fatalf("comments=%d, want 4", len(comments))
+	}
+	if got := <-comments; got != "keepalive" {
+		t.Fatalf("comment=%q", got)
+	}
+}
+
+func TestSSEStreamParsesDataLargerThanBuffer(t *testing.T) {
+	t.Parallel()
+
+	payload := `{"body":"` + strings.Repeat("x", 96*1024) + `"}`
+	raw := "event: message\ndata: " + payload + "\n\nevent: message\ndata: {}\n\n"
+	for _, stream := range []*SSEStream{
+		NewSSEStream(io.NopCloser(strings.NewReader(raw))),
+		NewSSEStreamSize(io.NopCloser(strings.NewReader(raw)), 128*1024),
+	} {
+		ev, err := stream.Next()
+		if err != nil {
+			t.Fatalf("Next returned error: %v", err)
+		}
+		if ev.Event != "message" || ev.Data != payload {
+			t.Fatalf("event=%q data length=%d, want %d", ev.Event, len(ev.Data), len(payload))
+		}
+		ev, err = stream.Next()
+		if err != nil || ev.Data != "{}" {
+			t.Fatalf("event after the large one=%+v err=%v", ev, err)
+		}
+	}
+}
+
+func TestSSEStreamJoinsMultipleDataLinesAcrossPartialReads(t *testing.T) {
+	t.Parallel()
+
+	body := "event: chat\ndata: {\"a\":1,\ndata:  \"b\":2}\n\nevent: second\ndata: x\n\n"
+	stream := NewSSEStream(io.NopCloser(iotest.OneByteReader(strings.NewReader(body))))
+
+	ev, err := stream.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ev.Event != "chat" || ev.Data != "{\"a\":1,\n \"b\":2}" {
+		t.Fatalf("first=%+v", ev)
+	}
+	ev, err = stream.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ev.Event != "second" || ev.Data != "x" {
+		t.Fatalf("second=%+v", ev)
+	}
+	if _, err := stream.Next(); err != io.EOF {
+		t.Fatalf("err=%v, want io.EOF", err)
+	}
+}
+
+func TestSSEStreamDispatchesEventTerminatedByClose(t *testing.T) {
+	t.Parallel()
+
+	// The last line has no newline at all before the body ends.
+	stream := NewSSEStream(io.NopCloser(strings.NewReader("data: first\n\nevent: last\ndata: tail")))
+
+	if ev, err := stream.Next(); err != nil || ev.Data != "first" {
+		t.Fatalf("first=%+v err=%v", ev, err)
+	}
+	ev, err := stream.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ev.Event != "last" || ev.Data != "tail" {
+		t.Fatalf("last=%+v", ev)
+	}
+	if _, err := stream.Next(); err != io.EOF {
+		t.Fatalf("err=%v, want io.EOF", err)
+	}
+}
+
+func TestSSEStreamAcceptsMixedLineEndingsAndBOM(t *testing.T) {
+	t.Parallel()
+
+	body := "\xef\xbb\xbfevent: one\r\ndata: a\rdata: b\n\r\n" +
+		"event: two\rdata: c\r\r" +
+		"data: d\n\n"
+	stream := NewSSEStream(io.NopCloser(strings.NewReader(body)))
+
+	var got []string
+	for {
+		ev, err := stream.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, ev.Event+"="+ev.Data)
+	}
+	if want := []string{"one=a\nb", "two=c", "=d"}; strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Fatalf("events=%q, want %q", got, want)
+	}
+}
+
+func TestSSEStreamDispatchesOnBareCRWithoutWaitingForMoreBytes(t *testing.T) {
+	t.Parallel()
+
+	pr, pw := io.Pipe()
+	stream := NewSSEStream(pr)
+	t.Cleanup(func() { _ = stream.Close() })
+
+	events := make(chan *SSEEvent, 1)
+	go func() {
+		ev, err := stream.Next()
+		if err == nil {
+			events <- ev
+		}
+	}()
+
+	// An unterminated event on an open connection is held back.
+	if _, err := pw.Write([]byte("data: held\r")); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case ev := <-events:
+		t.Fatalf("dispatched before the blank line: %+v", ev)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	if _, err := pw.Write([]byte("\r")); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case ev := <-events:
+		if ev.Data != "held" {
+			t.Fatalf("data=%q", ev.Data)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("event ending in bare CR was not dispatched")
+	}
+}
@@ -0,0 +1,126 @@
+package awid
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// ErrMissingField is wrapped by DecodeError when strict decoding finds a
+// response without a field the client expects.
+var ErrMissingField = errors.New("missing field")
+
+// DecodeError reports a response body that did not match the type the client
+// decoded it into. It is only returned with strict decoding enabled.
+type DecodeError struct {
+	Path  string // request path, without the query string
+	Field string // offending field, e.g. "agents[0].alias"
+	Err   error
+}
+
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("aweb: decode %s: field %s: %v", e.Path, e.Field, e.Err)
+}
+
+func (e *DecodeError) Unwrap() error { return e.Err }
+
+// SetStrictDecoding turns strict response decoding on or off. It is meant
+// for diagnosing a client pointed at a newer or older server than it was
+// built against: instead of leaving zero values, responses with a missing
+// or mistyped field fail with a *DecodeError naming the field, and chat
+// streams report event types the client does not know. Unknown fields are
+// still accepted.
+func (c *Client) SetStrictDecoding(on bool) { c.strictDecoding = on }
+
+// StrictDecoding reports whether strict decoding is on.
+func (c *Client) StrictDecoding() bool { return c.strictDecoding }
+
+func decodeStrict(path string, data []byte, out any) error {
+	path, _, _ = strings.Cut(path, "?")
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(out); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			field := typeErr.Field
+			if field == "" {
+				field = "(root)"
+			}
+			return &DecodeError{Path: path, Field: field, Err: err}
+		}
+		return err
+	}
+
+	var raw any
+	dec = json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&raw); err != nil {
+		return err
+	}
+	if field := firstMissingField(reflect.TypeOf(out), raw, ""); field != "" {
+		return &DecodeError{Path: path, Field: field, Err: ErrMissingField}
+	}
+	return nil
+}
+
+// firstMissingField walks t alongside the decoded JSON value and returns the
+// path of the first field that has no omitempty tag but is absent.
+func firstMissingField(t reflect.Type, raw any, prefix string) string {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array:
+		items, ok := raw.([]any)
+		if !ok {
+			return ""
+		}
+		for i, item := range items {
+			if field := firstMissingField(t.Elem(), item, fmt.Sprintf("%s[%d]", prefix, i)); field != "" {
+				return field
+			}
+		}
+	case reflect.Struct:
+		obj, ok := raw.(map[string]any)
+		if !ok {
+			return ""
+		}
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if !f.IsExported() {
+				continue
+			}
+			name, opts, _ := strings.Cut(f.Tag.Get("json"), ",")
+			if name == "-" && opts == "" {
+				continue
+			}
+			if f.Anonymous && name == "" {
+				if field := firstMissingField(f.Type, raw, prefix); field != "" {
+					return field
+				}
+				continue
+			}
+			if name == "" {
+				name = f.Name
+			}
+			path := name
+			if prefix != "" {
+				path = prefix + "." + name
+			}
+			value, present := obj[name]
+			if !present {
+				if !strings.Contains(","+opts+",", ",omitempty,") {
+					return path
+				}
+				continue
+			}
+			if field := firstMissingField(f.Type, value, path); field != "" {
+				return field
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,50 @@
+package awid
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestStrictDecodingReportsMissingAndMistypedFields(t *testing.T) {
+	t.Parallel()
+
+	body := `{"team_id":"backend:acme.com","agents":[{"agent_id":"a-1","alias":"alice","did_key":"did:key:z1"},{"agent_id":"a-2","did_key":"did:key:z2"}]}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/agents":
+			_, _ = w.Write([]byte(body))
+		case "/v1/agents/heartbeat":
+			_, _ = w.Write([]byte(`{"agent_id":"a-1","alias":"alice","last_seen_at":42}`))
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	c, err := New(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.ListAgents(context.Background()); err != nil {
+		t.Fatalf("lenient decode failed: %v", err)
+	}
+
+	c.SetStrictDecoding(true)
+	_, err = c.ListAgents(context.Background())
+	var decodeErr *DecodeError
+	if !errors.As(err, &decodeErr) {
+		t.Fatalf("err=%v, want *DecodeError", err)
+	}
+	if decodeErr.Path != "/v1/agents" || decodeErr.Field != "agents[1].alias" || !errors.Is(err, ErrMissingField) {
+		t.Fatalf("err=%+v", decodeErr)
+	}
+
+	_, err = c.Heartbeat(context.Background())
+	if !errors.As(err, &decodeErr) {
+		t.Fatalf("err=%v, want *DecodeError", err)
+	}
+	if decodeErr.Field != "last_seen_at" {
+		t.Fatalf("field=%q", decodeErr.Field)
+	}
+}
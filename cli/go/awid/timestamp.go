@@ -0,0 +1,48 @@
+package awid
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// ErrNoTimestamp is returned by ParseTimestamp for an empty value, which the
+// API uses for "not set".
+var ErrNoTimestamp = errors.New("aweb: timestamp not set")
+
+// ParseTimestamp parses an API timestamp. The server emits RFC 3339 with or
+// without fractional seconds; both are accepted.
+//
+// Response types keep their timestamps as the raw strings the server sent so
+// they round-trip unchanged; the accessor methods below parse them on demand.
+func ParseTimestamp(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}, ErrNoTimestamp
+	}
+	return time.Parse(time.RFC3339Nano, s)
+}
+
+// LastSeenTime parses LastSeenAt.
+func (r HeartbeatResponse) LastSeenTime() (time.Time, error) { return ParseTimestamp(r.LastSeenAt) }
+
+// LastSeenTime parses LastSeen.
+func (a AgentView) LastSeenTime() (time.Time, error) { return ParseTimestamp(a.LastSeen) }
+
+// DeliveredTime parses DeliveredAt.
+func (r SendMessageResponse) DeliveredTime() (time.Time, error) { return ParseTimestamp(r.DeliveredAt) }
+
+// CreatedTime parses CreatedAt.
+func (m InboxMessage) CreatedTime() (time.Time, error) { return ParseTimestamp(m.CreatedAt) }
+
+// AcknowledgedTime parses AcknowledgedAt.
+func (r AckResponse) AcknowledgedTime() (time.Time, error) { return ParseTimestamp(r.AcknowledgedAt) }
+
+// LastActivityTime parses LastActivity.
+func (p ChatPendingItem) LastActivityTime() (time.Time, error) { return ParseTimestamp(p.LastActivity) }
+
+// CreatedTime parses CreatedAt.
+func (s ChatSessionItem) CreatedTime() (time.Time, error) { return ParseTimestamp(s.CreatedAt) }
+
+// Time parses Timestamp.
+func (m ChatMessage) Time() (time.Time, error) { return ParseTimestamp(m.Timestamp) }
@@ -0,0 +1,79 @@
+package awid
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseTimestampAcceptsServerFormats(t *testing.T) {
+	t.Parallel()
+
+	want := time.Date(2026, 3, 17, 12, 0, 0, 0, time.UTC)
+	for _, value := range []string{"2026-03-17T12:00:00Z", "2026-03-17T12:00:00.000000Z", "2026-03-17T13:00:00+01:00"} {
+		got, err := ParseTimestamp(value)
+		if err != nil {
+			t.Fatalf("ParseTimestamp(%q): %v", value, err)
+		}
+		if !got.Equal(want) {
+			t.Fatalf("ParseTimestamp(%q)=%v, want %v", value, got, want)
+		}
+	}
+	if _, err := ParseTimestamp(""); !errors.Is(err, ErrNoTimestamp) {
+		t.Fatalf("empty err=%v, want ErrNoTimestamp", err)
+	}
+	if _, err := ParseTimestamp("yesterday"); err == nil {
+		t.Fatal("expected error for malformed timestamp")
+	}
+}
+
+func TestInboxMessageCreatedTimeKeepsRawString(t *testing.T) {
+	t.Parallel()
+
+	raw := `{"message_id":"m1","created_at":"2026-03-17T12:00:00.123456Z"}`
+	var msg InboxMessage
+	if err := json.Unmarshal([]byte(raw), &msg); err != nil {
+		t.Fatal(err)
+	}
+	created, err := msg.CreatedTime()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.Nanosecond() != 123456000 {
+		t.Fatalf("created=%v", created)
+	}
+
+	out, err := json.Marshal(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var roundTrip map[string]any
+	if err := json.Unmarshal(out, &roundTrip); err != nil {
+		t.Fatal(err)
+	}
+	if roundTrip["created_at"] != "2026-03-17T12:00:00.123456Z" {
+		t.Fatalf("created_at=%v", roundTrip["created_at"])
+	}
+}
+
+func TestStaleAgentsSkipsUnparseableLastSeen(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 3, 17, 12, 0, 0, 0, time.UTC)
+	resp := &ListAgentsResponse{Agents: []AgentView{
+		{Alias: "fresh", LastSeen: "2026-03-17T11:00:00Z"},
+		{Alias: "old", LastSeen: "2026-03-15T12:00:00.000000Z"},
+		{Alias: "never"},
+		{Alias: "garbled", LastSeen: "last tuesday"},
+		{Alias: "edge", LastSeen: "2026-03-16T12:00:00Z"},
+	}}
+
+	stale := resp.staleAgentsAt(now, 24*time.Hour)
+	if len(stale) != 1 || stale[0].Alias != "old" {
+		t.Fatalf("stale=%+v, want only old", stale)
+	}
+	if got := (*ListAgentsResponse)(nil).StaleAgents(time.Hour); got != nil {
+		t.Fatalf("nil response stale=%+v", got)
+	}
+}
@@ -0,0 +1,67 @@
+package awid
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+)
+
+// SetRootCAs makes the client trust the certificate authorities in pool,
+// instead of the system roots, for API requests and event streams. Use it
+// for self-hosted servers behind a private CA. pool replaces the system
+// roots, so build it with LoadRootCAs when the client also talks to
+// publicly certified hosts such as the awid registry.
+//
+// Like SetConnectionPool it only applies to the client's own transports,
+// not to an *http.Client with a Transport passed to SetHTTPClient or
+// SetSSEClient.
+func (c *Client) SetRootCAs(pool *x509.CertPool) {
+	c.configureTLS(func(cfg *tls.Config) { cfg.RootCAs = pool })
+}
+
+// SetInsecureSkipVerify turns off TLS certificate verification for API
+// requests and event streams. Anyone on the network path can then
+// impersonate the server, read the signed requests and bearer tokens the
+// client sends, and alter the responses, including the identity data used
+// to verify other agents. Prefer SetRootCAs with the server's CA; this is
+// for throwaway test servers with self-signed certificates.
+func (c *Client) SetInsecureSkipVerify() {
+	c.configureTLS(func(cfg *tls.Config) { cfg.InsecureSkipVerify = true })
+}
+
+func (c *Client) configureTLS(apply func(*tls.Config)) {
+	for _, t := range []*http.Transport{
+		ownTransport(&c.httpClient, &c.ownTransport),
+		ownTransport(&c.sseClient, &c.ownSSETransport),
+	} {
+		if t == nil {
+			continue
+		}
+		if t.TLSClientConfig == nil {
+			t.TLSClientConfig = &tls.Config{}
+		}
+		apply(t.TLSClientConfig)
+	}
+}
+
+// LoadRootCAs returns the system roots plus the PEM certificates in path,
+// for SetRootCAs. It fails if path holds no certificates.
+func LoadRootCAs(path string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	pool, err := x509.SystemCertPool()
+	if err != nil || pool == nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("%s: %w", path, errNoPEMCertificates)
+	}
+	return pool, nil
+}
+
+var errNoPEMCertificates = errors.New("no PEM certificates found")
@@ -0,0 +1,70 @@
+package awid
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPrivateCAServerNeedsRootCAsOrInsecureSkipVerify(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+	}))
+	t.Cleanup(server.Close)
+
+	get := func(c *Client) error {
+		var out map[string]string
+		return c.Get(context.Background(), "/v1/things", &out)
+	}
+
+	c, err := New(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := get(c); err == nil {
+		t.Fatal("expected an unknown-authority error without the server's CA")
+	}
+
+	caPath := filepath.Join(t.TempDir(), "ca.pem")
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(caPath, certPEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	pool, err := LoadRootCAs(caPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.SetConnectionPool(10, 4, 0)
+	c.SetRootCAs(pool)
+	if err := get(c); err != nil {
+		t.Fatalf("with the server's CA: %v", err)
+	}
+	if tr := c.HTTPClient().Transport.(*http.Transport); tr.MaxIdleConnsPerHost != 4 {
+		t.Fatalf("SetRootCAs reset the pool: %d idle per host", tr.MaxIdleConnsPerHost)
+	}
+
+	insecure, err := New(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	insecure.SetInsecureSkipVerify()
+	if err := get(insecure); err != nil {
+		t.Fatalf("with verification off: %v", err)
+	}
+
+	notPEM := filepath.Join(t.TempDir(), "ca.txt")
+	if err := os.WriteFile(notPEM, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadRootCAs(notPEM); !errors.Is(err, errNoPEMCertificates) {
+		t.Fatalf("LoadRootCAs(non-PEM) err=%v", err)
+	}
+}
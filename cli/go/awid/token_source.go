@@ -0,0 +1,69 @@
+package awid
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+)
+
+// tokenRefreshMargin is how close to expiry a cached token is refreshed
+// before use, so a request does not start with a token about to lapse.
+const tokenRefreshMargin = 30 * time.Second
+
+// TokenSource supplies bearer tokens for clients that authenticate with
+// expiring tokens rather than a signing key. Token returns the token and its
+// expiry; a zero expiry means the token does not expire.
+type TokenSource interface {
+	Token(ctx context.Context) (token string, expiry time.Time, err error)
+}
+
+type staticTokenSource string
+
+func (s staticTokenSource) Token(context.Context) (string, time.Time, error) {
+	return string(s), time.Time{}, nil
+}
+
+// StaticToken returns a TokenSource for a long-lived API key.
+func StaticToken(apiKey string) TokenSource {
+	return staticTokenSource(strings.TrimSpace(apiKey))
+}
+
+// SetTokenSource authenticates requests with a bearer token from ts. The
+// client caches the token, asks ts again shortly before it expires, and on a
+// 401 drops it and retries the request once with a fresh one. It has no
+// effect when the client signs requests with an identity key. A nil ts
+// turns bearer auth off.
+func (c *Client) SetTokenSource(ts TokenSource) {
+	c.tokenMu.Lock()
+	defer c.tokenMu.Unlock()
+	c.tokenSource = ts
+	c.cachedToken = ""
+	c.cachedTokenExpiry = time.Time{}
+}
+
+func (c *Client) bearerToken(ctx context.Context) (string, error) {
+	c.tokenMu.Lock()
+	defer c.tokenMu.Unlock()
+	if c.cachedToken != "" && (c.cachedTokenExpiry.IsZero() || time.Until(c.cachedTokenExpiry) > tokenRefreshMargin) {
+		return c.cachedToken, nil
+	}
+	token, expiry, err := c.tokenSource.Token(ctx)
+	if err != nil {
+		return "", err
+	}
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return "", errors.New("aweb: token source returned an empty token")
+	}
+	c.cachedToken = token
+	c.cachedTokenExpiry = expiry
+	return token, nil
+}
+
+func (c *Client) invalidateToken() {
+	c.tokenMu.Lock()
+	defer c.tokenMu.Unlock()
+	c.cachedToken = ""
+	c.cachedTokenExpiry = time.Time{}
+}
@@ -0,0 +1,125 @@
+package awid
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+type countingTokenSource struct {
+	mu     sync.Mutex
+	calls  int
+	expiry func() time.Time
+}
+
+func (s *countingTokenSource) Token(context.Context) (string, time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls++
+	var expiry time.Time
+	if s.expiry != nil {
+		expiry = s.expiry()
+	}
+	return fmt.Sprintf("t%d", s.calls), expiry, nil
+}
+
+func TestTokenSourceCachesUntilNearExpiry(t *testing.T) {
+	t.Parallel()
+
+	var seen []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = append(seen, r.Header.Get("Authorization"))
+		_ = json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+	}))
+	t.Cleanup(server.Close)
+
+	c, err := New(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expiry := time.Now().Add(time.Hour)
+	ts := &countingTokenSource{expiry: func() time.Time { return expiry }}
+	c.SetTokenSource(ts)
+
+	var out map[string]string
+	for range 2 {
+		if err := c.Get(context.Background(), "/v1/status", &out); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Inside the refresh margin the cached token is replaced before use.
+	expiry = time.Now().Add(tokenRefreshMargin / 2)
+	c.invalidateToken()
+	for range 2 {
+		if err := c.Get(context.Background(), "/v1/status", &out); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want := []string{"Bearer t1", "Bearer t1", "Bearer t2", "Bearer t3"}
+	if fmt.Sprint(seen) != fmt.Sprint(want) {
+		t.Fatalf("authorization headers=%v, want %v", seen, want)
+	}
+}
+
+func TestTokenSourceRetriesOnceAfter401(t *testing.T) {
+	t.Parallel()
+
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var in map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&in)
+		bodies = append(bodies, in["key"])
+		if r.Header.Get("Authorization") != "Bearer t2" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+	}))
+	t.Cleanup(server.Close)
+
+	c, err := New(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := &countingTokenSource{}
+	c.SetTokenSource(ts)
+
+	var out map[string]string
+	if err := c.Post(context.Background(), "/v1/things", map[string]string{"key": "val"}, &out); err != nil {
+		t.Fatal(err)
+	}
+	if ts.calls != 2 || fmt.Sprint(bodies) != "[val val]" {
+		t.Fatalf("token calls=%d bodies=%v, want a refresh and a replayed body", ts.calls, bodies)
+	}
+
+}
+
+func TestTokenSourceRetriesOnlyOnce(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	t.Cleanup(server.Close)
+
+	c, err := New(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := &countingTokenSource{}
+	c.SetTokenSource(ts)
+
+	err = c.Get(context.Background(), "/v1/status", nil)
+	if code, ok := HTTPStatusCode(err); !ok || code != http.StatusUnauthorized {
+		t.Fatalf("err=%v, want 401", err)
+	}
+	if ts.calls != 2 {
+		t.Fatalf("token calls=%d, want the initial token and one refresh", ts.calls)
+	}
+}
@@ -0,0 +1,133 @@
+package awid
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"syscall"
+)
+
+// ErrServerUnreachable matches, through errors.Is, every
+// *ServerUnreachableError: the request never got an HTTP response because
+// the server could not be reached.
+var ErrServerUnreachable = errors.New("aweb: server unreachable")
+
+// UnreachableReason says why a connection to the server failed.
+type UnreachableReason string
+
+const (
+	// UnreachableDNS means the host name in the base URL did not resolve.
+	UnreachableDNS UnreachableReason = "dns"
+	// UnreachableRefused means nothing accepted the connection on that
+	// host and port.
+	UnreachableRefused UnreachableReason = "refused"
+	// UnreachableTLS means the TLS handshake failed, usually because the
+	// certificate is not trusted or the server does not speak TLS.
+	UnreachableTLS UnreachableReason = "tls"
+	// UnreachableNetwork covers other connection failures, such as a dial
+	// timeout or an unreachable network.
+	UnreachableNetwork UnreachableReason = "network"
+)
+
+// ServerUnreachableError is returned when a request fails before the server
+// answers. Err is the transport error, still reachable with errors.As and
+// errors.Unwrap.
+type ServerUnreachableError struct {
+	BaseURL string
+	Reason  UnreachableReason
+	Err     error
+}
+
+func (e *ServerUnreachableError) Error() string {
+	return fmt.Sprintf("aweb: cannot reach server at %s (%s): %v", e.BaseURL, e.Reason, e.Err)
+}
+
+func (e *ServerUnreachableError) Unwrap() error { return e.Err }
+
+func (e *ServerUnreachableError) Is(target error) bool { return target == ErrServerUnreachable }
+
+// Hint returns a one-line suggestion for the reason, without the raw
+// transport error.
+func (e *ServerUnreachableError) Hint() string {
+	switch e.Reason {
+	case UnreachableDNS:
+		var dnsErr *net.DNSError
+		if errors.As(e.Err, &dnsErr) && dnsErr.Name != "" {
+			return fmt.Sprintf("cannot resolve host %q; is the server URL correct?", dnsErr.Name)
+		}
+		return "cannot resolve the server's host name; is the server URL correct?"
+	case UnreachableRefused:
+		return "connection refused; is the aweb server running, and are the host and port in the URL correct?"
+	case UnreachableTLS:
+		return fmt.Sprintf("TLS handshake failed: %s; is the URL scheme correct, and is the server's certificate trusted?", tlsFailure(e.Err))
+	default:
+		return "the connection failed; is the server running and is the URL correct?"
+	}
+}
+
+// unreachableError wraps a transport error from an HTTP client in a
+// *ServerUnreachableError when it is a connection-level failure. Errors
+// caused by ctx ending, and failures after the connection was made, are
+// returned unchanged.
+func unreachableError(ctx context.Context, baseURL string, err error) error {
+	if err == nil || ctx.Err() != nil || errors.Is(err, context.Canceled) {
+		return err
+	}
+	reason, ok := unreachableReason(err)
+	if !ok {
+		return err
+	}
+	return &ServerUnreachableError{BaseURL: baseURL, Reason: reason, Err: err}
+}
+
+func unreachableReason(err error) (UnreachableReason, bool) {
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return UnreachableDNS, true
+	}
+	if errors.Is(err, syscall.ECONNREFUSED) {
+		return UnreachableRefused, true
+	}
+	if tlsFailure(err) != "" {
+		return UnreachableTLS, true
+	}
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		return UnreachableNetwork, true
+	}
+	return "", false
+}
+
+// tlsFailure describes a TLS handshake error, or returns "" when err is not
+// one.
+func tlsFailure(err error) string {
+	var (
+		verifyErr    *tls.CertificateVerificationError
+		authorityErr x509.UnknownAuthorityError
+		hostnameErr  x509.HostnameError
+		invalidErr   x509.CertificateInvalidError
+		recordErr    tls.RecordHeaderError
+		alertErr     tls.AlertError
+	)
+	switch {
+	case errors.As(err, &authorityErr):
+		return "certificate signed by unknown authority"
+	case errors.As(err, &hostnameErr):
+		return "certificate is not valid for " + hostnameErr.Host
+	case errors.As(err, &invalidErr):
+		return "certificate is invalid: " + invalidErr.Error()
+	case errors.As(err, &verifyErr):
+		return "certificate verification failed: " + verifyErr.Err.Error()
+	case errors.As(err, &recordErr),
+		// net/http reports this case with an untyped error.
+		strings.Contains(err.Error(), "server gave HTTP response to HTTPS client"):
+		return "the server did not answer with TLS"
+	case errors.As(err, &alertErr):
+		return "the server sent alert: " + alertErr.Error()
+	}
+	return ""
+}
@@ -0,0 +1,101 @@
+package awid
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+func TestRequestsToUnreachableServersReportTheReason(t *testing.T) {
+	t.Parallel()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	closedURL := "http://" + listener.Addr().String()
+	_ = listener.Close()
+
+	tlsServer := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	t.Cleanup(tlsServer.Close)
+	plainServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	t.Cleanup(plainServer.Close)
+
+	cases := []struct {
+		name    string
+		baseURL string
+		reason  UnreachableReason
+		hint    string
+	}{
+		{"refused", closedURL, UnreachableRefused, "is the aweb server running"},
+		{"untrusted certificate", tlsServer.URL, UnreachableTLS, "certificate signed by unknown authority"},
+		{"https to a plain server", "https://" + strings.TrimPrefix(plainServer.URL, "http://"), UnreachableTLS, "did not answer with TLS"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			c, err := New(tc.baseURL)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = c.Get(context.Background(), "/v1/things", nil)
+			var unreachable *ServerUnreachableError
+			if !errors.As(err, &unreachable) || !errors.Is(err, ErrServerUnreachable) {
+				t.Fatalf("err=%v, want a ServerUnreachableError", err)
+			}
+			if unreachable.BaseURL != tc.baseURL || unreachable.Reason != tc.reason {
+				t.Fatalf("unreachable=%+v, want %s for %s", unreachable, tc.reason, tc.baseURL)
+			}
+			if !strings.Contains(unreachable.Hint(), tc.hint) {
+				t.Fatalf("hint=%q, want mention of %q", unreachable.Hint(), tc.hint)
+			}
+			var urlErr *url.Error
+			if !errors.As(err, &urlErr) {
+				t.Fatalf("transport error not wrapped: %v", errors.Unwrap(err))
+			}
+		})
+	}
+}
+
+func TestUnreachableErrorClassifiesTransportErrors(t *testing.T) {
+	t.Parallel()
+
+	dial := func(err error) error {
+		return &url.Error{Op: "Get", URL: "https://aweb.example/v1/things", Err: &net.OpError{Op: "dial", Net: "tcp", Err: err}}
+	}
+	dnsErr := dial(&net.DNSError{Err: "no such host", Name: "aweb.example", IsNotFound: true})
+	err := unreachableError(context.Background(), "https://aweb.example", dnsErr)
+	var unreachable *ServerUnreachableError
+	if !errors.As(err, &unreachable) || unreachable.Reason != UnreachableDNS {
+		t.Fatalf("DNS failure: err=%v", err)
+	}
+	if hint := unreachable.Hint(); !strings.Contains(hint, `"aweb.example"`) {
+		t.Fatalf("DNS hint=%q", hint)
+	}
+	if errors.Unwrap(err) != dnsErr {
+		t.Fatal("Unwrap did not return the transport error")
+	}
+
+	err = unreachableError(context.Background(), "https://aweb.example", dial(syscall.ENETUNREACH))
+	if !errors.As(err, &unreachable) || unreachable.Reason != UnreachableNetwork {
+		t.Fatalf("network failure: err=%v", err)
+	}
+
+	// Failures after the connection was made, and cancellation by the
+	// caller, keep their own errors.
+	reset := &url.Error{Op: "Get", URL: "https://aweb.example/v1/things", Err: &net.OpError{Op: "read", Net: "tcp", Err: syscall.ECONNRESET}}
+	if err := unreachableError(context.Background(), "https://aweb.example", reset); err != reset {
+		t.Fatalf("read error wrapped: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := unreachableError(ctx, "https://aweb.example", dial(syscall.ECONNREFUSED)); errors.Is(err, ErrServerUnreachable) {
+		t.Fatalf("canceled request wrapped: %v", err)
+	}
+}
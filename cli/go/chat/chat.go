@@ -9,7 +9,10 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"net/http"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	awid "github.com/awebai/aw/awid"
@@ -47,6 +50,42 @@ const maxStreamDeadline = 15 * time.Minute
 // accounting for all possible wait extensions.
 const MaxSendTimeout = 16 * time.Minute
 
+// MaxWait is the default cap, in seconds, on SendOptions.Wait. It keeps the
+// initial wait inside both maxStreamDeadline and MaxSendTimeout.
+const MaxWait = 900
+
+// HighPriorityWait and UrgentPriorityWait are the reply waits, in seconds,
+// Send uses for high and urgent messages whose wait was not set explicitly.
+const (
+	HighPriorityWait   = 300
+	UrgentPriorityWait = 600
+)
+
+// ErrWaitExceedsDeadline is returned by Send when the caller's context
+// expires before the requested wait could elapse.
+var ErrWaitExceedsDeadline = errors.New("context deadline is shorter than the requested wait")
+
+// resolveSendWait validates a wait in seconds against the cap and the
+// context deadline, returning the wait to use and whether it was capped.
+func resolveSendWait(ctx context.Context, wait, maxWait int) (int, bool, error) {
+	if wait < 0 {
+		return 0, false, fmt.Errorf("wait must not be negative (got %d)", wait)
+	}
+	if maxWait <= 0 {
+		maxWait = MaxWait
+	}
+	capped := wait > maxWait
+	if capped {
+		wait = maxWait
+	}
+	if deadline, ok := ctx.Deadline(); ok && wait > 0 {
+		if remaining := time.Until(deadline); remaining < time.Duration(wait)*time.Second {
+			return 0, false, fmt.Errorf("%w: %ds wait, %s left", ErrWaitExceedsDeadline, wait, remaining.Truncate(time.Second))
+		}
+	}
+	return wait, capped, nil
+}
+
 func classifyChatTargets(targets []string) (aliases []string, dids []string, addresses []string) {
 	for _, target := range targets {
 		target = strings.TrimSpace(target)
@@ -75,13 +114,20 @@ type sseResult struct {
 // Returns the event channel and a cleanup function. The cleanup function closes the
 // stream, signals the goroutine to stop, and blocks until it has exited.
 // The caller must call cleanup to avoid goroutine leaks.
+//
+// The goroutine spends its time blocked in Next, which only returns once the
+// stream is closed or delivers something, so cancelling ctx closes the stream
+// too: the goroutine then exits promptly even before cleanup runs.
 func streamToChannel(ctx context.Context, stream *awid.SSEStream) (<-chan sseResult, func()) {
 	ch := make(chan sseResult, 10)
 	stopCtx, stopCancel := context.WithCancel(ctx)
+	stopClosing := context.AfterFunc(stopCtx, func() { _ = stream.Close() })
 	done := make(chan struct{})
 	go func() {
-		defer close(ch)
+		// done is closed last, so a cleanup that has seen it leaves no
+		// goroutine work behind.
 		defer close(done)
+		defer close(ch)
 		for {
 			ev, err := stream.Next()
 			if err != nil {
@@ -100,12 +146,41 @@ func streamToChannel(ctx context.Context, stream *awid.SSEStream) (<-chan sseRes
 	}()
 	cleanup := func() {
 		stopCancel()
-		stream.Close()
+		stopClosing()
+		_ = stream.Close()
 		<-done
 	}
 	return ch, cleanup
 }
 
+// knownEventTypes are the chat stream events this client understands.
+var knownEventTypes = map[string]bool{"message": true, "read_receipt": true, "error": true}
+
+// maxParseErrorData bounds how much of an undecodable event's raw data a
+// parse_error status carries.
+const maxParseErrorData = 512
+
+// reportUnexpectedEvent tells callback about stream events whose data is not
+// valid JSON, which parseSSEEvent would otherwise drop silently, and, when
+// the client has strict decoding on, about event types this client does not
+// understand.
+func reportUnexpectedEvent(client *awid.Client, callback StatusCallback, sseEvent *awid.SSEEvent) {
+	if callback == nil {
+		return
+	}
+	if !json.Valid([]byte(sseEvent.Data)) {
+		raw := sseEvent.Data
+		if len(raw) > maxParseErrorData {
+			raw = raw[:maxParseErrorData] + "..."
+		}
+		callback("parse_error", fmt.Sprintf("could not decode chat %s event data: %q", sseEvent.Event, raw))
+		return
+	}
+	if client.StrictDecoding() && !knownEventTypes[sseEvent.Event] {
+		callback("unexpected_event", fmt.Sprintf("unexpected chat event type %q", sseEvent.Event))
+	}
+}
+
 // parseSSEEvent converts an SSE event to a chat Event.
 func parseSSEEvent(sseEvent *awid.SSEEvent) Event {
 	ev := Event{
@@ -168,6 +243,9 @@ func parseSSEEvent(sseEvent *awid.SSEEvent) Event {
 	if v, ok := data["reply_to_message_id"].(string); ok {
 		ev.ReplyToMessageID = v
 	}
+	if v, ok := data["priority"].(string); ok {
+		ev.Priority = awid.MessagePriority(v)
+	}
 	if v, ok := data["from_did"].(string); ok {
 		ev.FromDID = v
 	}
@@ -637,6 +715,10 @@ func normalizeSessionTarget(ctx context.Context, client *awid.Client, target str
 	return target
 }
 
+// ErrNoConversation is wrapped when no existing conversation matches a
+// target.
+var ErrNoConversation = errors.New("no conversation found")
+
 // findSession finds the session ID for a conversation with targetAlias.
 // Checks pending first (captures sender_waiting), falls back to listing sessions.
 //
@@ -826,7 +908,49 @@ func findSession(ctx context.Context, client *awid.Client, targetAlias string) (
 		return bestSessionID, false, nil
 	}
 
-	return "", false, fmt.Errorf("no conversation found with %s", targetAlias)
+	return "", false, fmt.Errorf("%w with %s", ErrNoConversation, targetAlias)
+}
+
+// findExactSession returns the most recently created session whose
+// participants are exactly the caller and targets, or "" when there is none.
+// Targets match participants the same way they do in findSession; unlike
+// findSession, a larger group that includes the targets does not count.
+func findExactSession(ctx context.Context, client *awid.Client, targets []string) (string, error) {
+	var normalized []string
+	for _, target := range targets {
+		target = normalizeSessionTarget(ctx, client, target)
+		if target != "" && !chatTargetNameListContains(normalized, target) {
+			normalized = append(normalized, target)
+		}
+	}
+	if len(normalized) == 0 {
+		return "", nil
+	}
+	var bestID, bestCreated string
+	it := client.ChatListSessionsIter()
+	for {
+		s, ok, err := it.Next(ctx)
+		if err != nil {
+			return "", fmt.Errorf("listing chat sessions: %w", err)
+		}
+		if !ok {
+			return bestID, nil
+		}
+		if participantIdentityCount(s.Participants, s.ParticipantDIDs, s.ParticipantAddresses) != len(normalized)+1 {
+			continue
+		}
+		matched := true
+		for _, target := range normalized {
+			if !exactParticipantMatch(s.Participants, s.ParticipantDIDs, s.ParticipantAddresses, target) {
+				matched = false
+				break
+			}
+		}
+		if matched && (bestID == "" || s.CreatedAt > bestCreated) {
+			bestID = s.SessionID
+			bestCreated = s.CreatedAt
+		}
+	}
 }
 
 // buildMessages converts ChatMessage slice to Event slice.
@@ -853,6 +977,8 @@ func buildMessages(messages []awid.ChatMessage) []Event {
 			ReplacementAnnouncement: m.ReplacementAnnouncement,
 			VerificationStatus:      m.VerificationStatus,
 			IsContact:               m.IsContact,
+			Priority:                m.Priority,
+			Matches:                 m.Matches,
 		}
 	}
 	return events
@@ -903,23 +1029,32 @@ type streamOpener func(ctx context.Context, sessionID string, deadline time.Time
 //	both false:   unrelated message, continue waiting
 type messageAcceptor func(ev Event) (accept, skip bool)
 
+// statusNote is a status update produced off the wait loop's goroutine and
+// handed to it, so the StatusCallback is only ever called from the wait loop.
+type statusNote struct {
+	kind    string
+	message string
+}
+
 // waitForMessage opens an SSE stream and waits for a message matching the acceptor.
 // Handles read receipts, extend-wait messages, and wait extensions.
 // after controls SSE replay: non-nil replays messages after that timestamp; nil skips replay.
-func waitForMessage(ctx context.Context, client *awid.Client, openStream streamOpener, sessionID string, participants []awid.ChatParticipant, selfAlias string, waitSeconds int, after *time.Time, callback StatusCallback, accept messageAcceptor) (*SendResult, error) {
+// Notes received on notes are passed to callback; notes may be nil.
+func waitForMessage(ctx context.Context, clock Clock, client *awid.Client, openStream streamOpener, sessionID string, participants []awid.ChatParticipant, selfAlias string, waitSeconds int, after *time.Time, callback StatusCallback, notes <-chan statusNote, accept messageAcceptor) (*SendResult, error) {
 	result := &SendResult{
 		SessionID: sessionID,
-		Status:    "timeout",
+		Status:    StatusTimeout,
 		Events:    []Event{},
 	}
 
+	clock = clockOrReal(clock)
 	waitTimeout := time.Duration(waitSeconds) * time.Second
-	waitDeadline := time.Now().Add(waitTimeout)
-	waitStart := time.Now()
+	waitStart := clock.Now()
+	waitDeadline := waitStart.Add(waitTimeout)
 
 	// The server deadline is a safety net for orphaned connections —
 	// the local waitTimer manages actual wait semantics.
-	stream, err := openStream(ctx, sessionID, time.Now().Add(maxStreamDeadline), after)
+	stream, err := openStream(ctx, sessionID, clock.Now().Add(maxStreamDeadline), after)
 	if err != nil {
 		if ctx.Err() != nil {
 			return nil, ctx.Err()
@@ -931,19 +1066,80 @@ func waitForMessage(ctx context.Context, client *awid.Client, openStream streamO
 					Limit:     1,
 				})
 			}
-			result.WaitedSeconds = int(time.Since(waitStart).Seconds())
+			result.WaitedSeconds = int(clock.Now().Sub(waitStart).Seconds())
 			return result, nil
 		}
 		return nil, fmt.Errorf("connecting to SSE: %w", err)
 	}
+	streamOpenedAt := clock.Now()
 	events, streamCleanup := streamToChannel(ctx, stream)
-	defer streamCleanup()
+	defer func() { streamCleanup() }()
+
+	// Events already handled, so a reconnect that replays history does not
+	// deliver them twice.
+	seen := make(map[string]struct{})
+
+	// lastSeenAt is the newest event timestamp processed so far. The stream
+	// endpoint resumes by timestamp rather than message ID, so a reconnect
+	// replays from here and the seen set drops the overlap.
+	var lastSeenAt *time.Time
+	var reconnectDelay time.Duration
+
+	// reconnect replaces a dropped stream. Consecutive attempts back off
+	// exponentially and give up once the wait deadline passes.
+	reconnect := func(reason string) error {
+		streamCleanup()
+		streamCleanup = func() {}
+		for {
+			if reconnectDelay > 0 {
+				wait := min(reconnectDelay, waitDeadline.Sub(clock.Now()))
+				if wait <= 0 {
+					return errWaitExpired
+				}
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case <-clock.After(wait):
+				}
+			}
+			reconnectDelay = nextReconnectDelay(reconnectDelay)
+			if !clock.Now().Before(waitDeadline) {
+				return errWaitExpired
+			}
+
+			replayFrom := lastSeenAt
+			if replayFrom == nil {
+				replayFrom = after
+			}
+			if replayFrom == nil {
+				openedAt := streamOpenedAt
+				replayFrom = &openedAt
+			}
+			openedAt := clock.Now()
+			stream, err := openStream(ctx, sessionID, clock.Now().Add(maxStreamDeadline), replayFrom)
+			if err != nil {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				if !retryableStreamError(err) {
+					return fmt.Errorf("reconnecting to SSE: %w", err)
+				}
+				continue
+			}
+			streamOpenedAt = openedAt
+			events, streamCleanup = streamToChannel(ctx, stream)
+			if callback != nil {
+				callback("reconnect", fmt.Sprintf("chat stream %s; reconnected", reason))
+			}
+			return nil
+		}
+	}
 
-	waitTimer := time.NewTimer(waitTimeout)
+	waitTimer := clock.NewTimer(waitTimeout)
 	defer func() {
 		if !waitTimer.Stop() {
 			select {
-			case <-waitTimer.C:
+			case <-waitTimer.C():
 			default:
 			}
 		}
@@ -953,18 +1149,18 @@ func waitForMessage(ctx context.Context, client *awid.Client, openStream streamO
 		if extendsSeconds <= 0 {
 			return
 		}
-		if time.Now().After(waitDeadline) {
-			waitDeadline = time.Now()
+		if clock.Now().After(waitDeadline) {
+			waitDeadline = clock.Now()
 		}
 		waitDeadline = waitDeadline.Add(time.Duration(extendsSeconds) * time.Second)
 
 		if !waitTimer.Stop() {
 			select {
-			case <-waitTimer.C:
+			case <-waitTimer.C():
 			default:
 			}
 		}
-		waitTimer.Reset(time.Until(waitDeadline))
+		waitTimer.Reset(waitDeadline.Sub(clock.Now()))
 
 		if callback != nil {
 			minutes := extendsSeconds / 60
@@ -980,16 +1176,52 @@ func waitForMessage(ctx context.Context, client *awid.Client, openStream streamO
 		select {
 		case <-ctx.Done():
 			return nil, ctx.Err()
-		case <-waitTimer.C:
-			result.WaitedSeconds = int(time.Since(waitStart).Seconds())
+		case <-waitTimer.C():
+			result.WaitedSeconds = int(clock.Now().Sub(waitStart).Seconds())
 			return result, nil
+		case note := <-notes:
+			if callback != nil {
+				callback(note.kind, note.message)
+			}
 		case sr, ok := <-events:
 			if !ok || sr.err != nil {
-				result.WaitedSeconds = int(time.Since(waitStart).Seconds())
+				if ctx.Err() == nil && clock.Now().Before(waitDeadline) {
+					reason := "closed"
+					if errors.Is(sr.err, awid.ErrStreamIdle) {
+						reason = "went idle"
+					} else if sr.err != nil && !isCleanEOF(sr.err) {
+						reason = "failed"
+					}
+					err := reconnect(reason)
+					if err == nil {
+						continue
+					}
+					if ctx.Err() != nil {
+						return nil, ctx.Err()
+					}
+					if !errors.Is(err, errWaitExpired) {
+						return nil, err
+					}
+				}
+				result.WaitedSeconds = int(clock.Now().Sub(waitStart).Seconds())
 				return result, nil
 			}
 
+			reportUnexpectedEvent(client, callback, sr.event)
 			chatEvent := parseSSEEvent(sr.event)
+			if chatEvent.MessageID != "" || chatEvent.Timestamp != "" {
+				key := chatEvent.Type + "\x00" + chatEvent.MessageID + "\x00" + chatEvent.ReaderAlias + "\x00" + chatEvent.Timestamp
+				if _, dup := seen[key]; dup {
+					continue
+				}
+				seen[key] = struct{}{}
+			}
+			// Only a new event shows the stream is healthy; a stream that
+			// replays the overlap and drops again keeps backing off.
+			reconnectDelay = 0
+			if ts, err := time.Parse(time.RFC3339Nano, chatEvent.Timestamp); err == nil && (lastSeenAt == nil || ts.After(*lastSeenAt)) {
+				lastSeenAt = &ts
+			}
 			tofuFrom := chatEventTrustAddress(chatEvent, participants)
 			chatEvent.VerificationStatus, chatEvent.IsContact = client.NormalizeSenderTrust(ctx, chatEvent.VerificationStatus, tofuFrom, chatEvent.FromDID, chatEvent.FromStableID, chatEvent.RotationAnnouncement, chatEvent.ReplacementAnnouncement, chatEvent.IsContact)
 			chatEvent.VerificationStatus = client.NormalizeRecipientBinding(chatEvent.VerificationStatus, chatEvent.ToDID, chatEvent.ToStableID)
@@ -1035,12 +1267,12 @@ func waitForMessage(ctx context.Context, client *awid.Client, openStream streamO
 				result.SenderWaiting = chatEvent.SenderWaiting
 
 				if chatEvent.SenderLeaving {
-					result.Status = "sender_left"
+					result.Status = StatusSenderLeft
 					result.Reply = chatEvent.Body
 					return result, nil
 				}
 
-				result.Status = "replied"
+				result.Status = StatusReplied
 				result.Reply = chatEvent.Body
 				return result, nil
 			}
@@ -1048,6 +1280,41 @@ func waitForMessage(ctx context.Context, client *awid.Client, openStream streamO
 	}
 }
 
+const (
+	reconnectBackoffMin = 250 * time.Millisecond
+	reconnectBackoffMax = 5 * time.Second
+)
+
+// errWaitExpired stops a reconnect attempt that would outlive the wait.
+var errWaitExpired = errors.New("wait deadline reached while reconnecting")
+
+// nextReconnectDelay doubles the delay between consecutive reconnects. The
+// first reconnect after a healthy stream is immediate.
+func nextReconnectDelay(d time.Duration) time.Duration {
+	if d <= 0 {
+		return reconnectBackoffMin
+	}
+	return min(d*2, reconnectBackoffMax)
+}
+
+// retryableStreamError reports whether reopening a stream after err can
+// succeed. Network errors, 408, 429 and 5xx are retried; any other API error
+// is permanent, such as a revoked certificate (401/403) or a deleted
+// session (404).
+func retryableStreamError(err error) bool {
+	var apiErr *awid.APIError
+	if !errors.As(err, &apiErr) {
+		return true
+	}
+	switch {
+	case apiErr.StatusCode == http.StatusRequestTimeout,
+		apiErr.StatusCode == http.StatusTooManyRequests,
+		apiErr.StatusCode >= 500:
+		return true
+	}
+	return false
+}
+
 func isCleanEOF(err error) bool {
 	if err == nil {
 		return false
@@ -1079,14 +1346,62 @@ func Send(ctx context.Context, client *awid.Client, myAlias string, targets []st
 	if opts.StartConversation && !opts.WaitExplicit {
 		waitSeconds = 300
 	}
+	if waitSeconds > 0 && !opts.WaitExplicit {
+		waitSeconds = max(waitSeconds, priorityWait(opts.Priority))
+	}
+	// Normal is the server default; leaving it out keeps older servers,
+	// which reject unknown fields, working.
+	priority := opts.Priority
+	if priority == awid.PriorityNormal {
+		priority = ""
+	}
+	waitSeconds, capped, err := resolveSendWait(ctx, waitSeconds, opts.MaxWait)
+	if err != nil {
+		return nil, err
+	}
+	if capped {
+		if opts.Wait > waitSeconds {
+			opts.Wait = waitSeconds
+		}
+		if callback != nil {
+			callback("wait_capped", fmt.Sprintf("wait capped at %ds", waitSeconds))
+		}
+	}
 
 	aliases, dids, addresses := classifyChatTargets(targets)
+	if !opts.SkipAliasCheck {
+		if err := checkAliasesExist(ctx, client, aliases); err != nil {
+			return nil, err
+		}
+	}
+	if opts.ExistingSession == ReuseSession && !opts.Leaving {
+		sessionID, err := findExactSession(ctx, client, targets)
+		if err != nil {
+			return nil, err
+		}
+		if sessionID != "" {
+			msgResp, err := client.ChatSendMessage(ctx, sessionID, &awid.ChatSendMessageRequest{Body: message, Priority: priority})
+			if err != nil {
+				return nil, fmt.Errorf("sending message: %w", err)
+			}
+			// As with reply, the in-session send does not report who is
+			// connected or has left.
+			return sendCommon(ctx, client, client.ChatStream, sendResponse{
+				SessionID:        sessionID,
+				MessageID:        msgResp.MessageID,
+				TargetsConnected: targets,
+			}, myAlias, targets, message, waitSeconds, opts, &sentAt, callback)
+		}
+	}
 	req := &awid.ChatCreateSessionRequest{
 		ToAliases:   aliases,
 		ToDIDs:      dids,
 		ToAddresses: addresses,
 		Message:     message,
 		Leaving:     opts.Leaving,
+		Subject:     strings.TrimSpace(opts.Subject),
+		Metadata:    opts.Metadata,
+		Priority:    priority,
 	}
 	if waitSeconds > 0 {
 		req.WaitSeconds = &waitSeconds
@@ -1105,13 +1420,28 @@ func Send(ctx context.Context, client *awid.Client, myAlias string, targets []st
 	}, myAlias, targets, message, waitSeconds, opts, &sentAt, callback)
 }
 
+// checkAliasesExist rejects same-team aliases that no agent has, before a
+// session is created for them. Cross-team (team~alias) targets are left to
+// the server, and a roster that cannot be fetched does not block the send.
+func checkAliasesExist(ctx context.Context, client *awid.Client, aliases []string) error {
+	for _, alias := range aliases {
+		if strings.Contains(alias, "~") {
+			continue
+		}
+		if _, err := client.ResolveAlias(ctx, alias); errors.Is(err, awid.ErrUnknownAlias) {
+			return err
+		}
+	}
+	return nil
+}
+
 // sendCommon handles the post-send wait logic after a message has been created.
 // resolvedWait is the actual wait duration in seconds, already accounting for
 // StartConversation upgrades. This must match what was sent to the server.
 func sendCommon(ctx context.Context, client *awid.Client, openStream streamOpener, resp sendResponse, myAlias string, targets []string, message string, resolvedWait int, opts SendOptions, after *time.Time, callback StatusCallback) (*SendResult, error) {
 	result := &SendResult{
 		SessionID:   resp.SessionID,
-		Status:      "sent",
+		Status:      StatusSent,
 		TargetAgent: strings.Join(targets, ", "),
 		Events:      []Event{},
 	}
@@ -1144,7 +1474,7 @@ func sendCommon(ctx context.Context, client *awid.Client, openStream streamOpene
 	}
 
 	if targetHasLeft && !opts.StartConversation {
-		result.Status = "targets_left"
+		result.Status = StatusTargetsLeft
 		return result, nil
 	}
 
@@ -1168,10 +1498,98 @@ func sendCommon(ctx context.Context, client *awid.Client, openStream streamOpene
 		result.TargetNotConnected = true
 	}
 
-	// Build message acceptor: skip replays, accept only from targets.
-	// The gate opens when we see our sent message by ID. If the server
-	// didn't return a message ID (sentMessageID==""), the gate starts open.
-	sentMessageID := resp.MessageID
+	nudges, stopNudging := nudgeWhileWaiting(ctx, clockOrReal(opts.Clock), client, resp.SessionID, opts.Priority)
+	waitResult, err := waitForReply(ctx, opts.Clock, client, openStream, resp.SessionID, resp.Participants, myAlias, targetStatusNames, resp.MessageID, resolvedWait, after, callback, nudges)
+	stopNudging()
+	if err != nil {
+		return nil, err
+	}
+
+	result.Status = waitResult.Status
+	result.Reply = waitResult.Reply
+	result.Events = waitResult.Events
+	result.SenderWaiting = waitResult.SenderWaiting
+	result.WaitedSeconds = waitResult.WaitedSeconds
+	return result, nil
+}
+
+// priorityWait is the default reply wait for a priority, or zero when the
+// priority does not change it.
+func priorityWait(priority awid.MessagePriority) int {
+	switch priority {
+	case awid.PriorityUrgent:
+		return UrgentPriorityWait
+	case awid.PriorityHigh:
+		return HighPriorityWait
+	}
+	return 0
+}
+
+// priorityNudgeInterval is how often a send of the given priority nudges
+// unread recipients while it waits; zero means it does not nudge.
+func priorityNudgeInterval(priority awid.MessagePriority) time.Duration {
+	switch priority {
+	case awid.PriorityUrgent:
+		return MinNudgeInterval
+	case awid.PriorityHigh:
+		return 2 * MinNudgeInterval
+	}
+	return 0
+}
+
+// nudgeWhileWaiting nudges the session's unread recipients every
+// priorityNudgeInterval until the returned stop function is called. Failed
+// nudges are skipped; a server without the nudge endpoint ends the loop.
+// Each successful nudge is reported as a "nudged" note for the wait loop.
+func nudgeWhileWaiting(ctx context.Context, clock Clock, client *awid.Client, sessionID string, priority awid.MessagePriority) (notes <-chan statusNote, stop func()) {
+	interval := priorityNudgeInterval(priority)
+	if interval == 0 {
+		return nil, func() {}
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	out := make(chan statusNote)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-clock.After(interval):
+			}
+			resp, err := client.ChatNudge(ctx, sessionID)
+			if err != nil {
+				if code, ok := awid.HTTPStatusCode(err); ok && (code == http.StatusNotFound || code == http.StatusMethodNotAllowed) {
+					return
+				}
+				continue
+			}
+			if len(resp.Nudged) == 0 {
+				continue
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case out <- statusNote{kind: "nudged", message: "nudged " + strings.Join(resp.Nudged, ", ")}:
+			}
+		}
+	}()
+	return out, func() {
+		cancel()
+		<-done
+	}
+}
+
+// waitForReply waits up to waitSeconds for the next message in sessionID
+// from one of targetNames (each a target's normalized names), or from any
+// participant other than the caller when targetNames is empty. It is the
+// wait loop behind Send, Reply and Wait.
+//
+// With sentMessageID set, replayed messages are skipped until that message
+// is seen, so only replies to it count. Read messages are marked read.
+func waitForReply(ctx context.Context, clock Clock, client *awid.Client, openStream streamOpener, sessionID string, participants []awid.ChatParticipant, selfAlias string, targetNames [][]string, sentMessageID string, waitSeconds int, after *time.Time, callback StatusCallback, notes <-chan statusNote) (*SendResult, error) {
+	// The gate opens when we see our sent message by ID. If there is no
+	// sent message (sentMessageID==""), the gate starts open.
 	seenSentMessage := sentMessageID == ""
 	acceptor := func(ev Event) (accept, skip bool) {
 		if !seenSentMessage {
@@ -1180,41 +1598,122 @@ func sendCommon(ctx context.Context, client *awid.Client, openStream streamOpene
 			}
 			return false, true
 		}
-		eventNames := normalizedChatEventNames(ev, resp.Participants)
-		for _, targetNames := range targetStatusNames {
-			if chatTargetNameListsOverlap(targetNames, eventNames) {
+		if len(targetNames) == 0 {
+			return !chatEventFromSelf(ev, client, selfAlias), false
+		}
+		eventNames := normalizedChatEventNames(ev, participants)
+		for _, names := range targetNames {
+			if chatTargetNameListsOverlap(names, eventNames) {
 				return true, false
 			}
 		}
 		return false, false
 	}
 
-	waitResult, err := waitForMessage(ctx, client, openStream, resp.SessionID, resp.Participants, myAlias, resolvedWait, after, callback, acceptor)
+	result, err := waitForMessage(ctx, clock, client, openStream, sessionID, participants, selfAlias, waitSeconds, after, callback, notes, acceptor)
 	if err != nil {
 		return nil, err
 	}
+	markLastRead(ctx, client, sessionID, result.Events)
+	return result, nil
+}
 
-	markLastRead(ctx, client, resp.SessionID, waitResult.Events)
+// chatEventFromSelf reports whether ev was sent by the client's identity.
+func chatEventFromSelf(ev Event, client *awid.Client, selfAlias string) bool {
+	selfAddress, selfStableID, selfDID := "", "", ""
+	if client != nil {
+		selfAddress = client.Address()
+		selfStableID = client.StableID()
+		selfDID = client.DID()
+	}
+	return identityutil.MatchesSelfStrict(
+		ev.FromAgent,
+		ev.FromAddress,
+		ev.FromStableID,
+		ev.FromDID,
+		selfAlias,
+		selfAddress,
+		selfStableID,
+		selfDID,
+	)
+}
 
-	result.Status = waitResult.Status
-	result.Reply = waitResult.Reply
-	result.Events = waitResult.Events
-	result.SenderWaiting = waitResult.SenderWaiting
-	result.WaitedSeconds = waitResult.WaitedSeconds
+// Wait re-attaches to an existing session and waits up to waitSeconds for
+// the next message from another participant, without sending anything.
+// It resumes a send-and-wait whose local wait was interrupted; messages
+// that arrived before the call are left to Open and History.
+func Wait(ctx context.Context, client *awid.Client, myAlias, sessionID string, waitSeconds int, callback StatusCallback) (*SendResult, error) {
+	return wait(ctx, client, client.ChatStream, myAlias, sessionID, waitSeconds, callback)
+}
+
+func wait(ctx context.Context, client *awid.Client, openStream streamOpener, myAlias, sessionID string, waitSeconds int, callback StatusCallback) (*SendResult, error) {
+	sessionID = strings.TrimSpace(sessionID)
+	if sessionID == "" {
+		return nil, fmt.Errorf("session id is required")
+	}
+	waitSeconds, capped, err := resolveSendWait(ctx, waitSeconds, 0)
+	if err != nil {
+		return nil, err
+	}
+	if capped && callback != nil {
+		callback("wait_capped", fmt.Sprintf("wait capped at %ds", waitSeconds))
+	}
+
+	session, err := findSessionByID(ctx, client, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	participants := make([]awid.ChatParticipant, 0, len(session.Participants))
+	var others []string
+	for _, row := range chatParticipantRows(session.Participants, session.ParticipantDIDs, session.ParticipantAddresses) {
+		participant := awid.ChatParticipant{Alias: row.Alias, DID: row.DID, Address: row.Address}
+		participants = append(participants, participant)
+		if !chatParticipantMatchesSelf(participant, client, myAlias) {
+			others = append(others, row.label())
+		}
+	}
+
+	result, err := waitForReply(ctx, nil, client, openStream, sessionID, participants, myAlias, nil, "", waitSeconds, nil, callback, nil)
+	if err != nil {
+		return nil, err
+	}
+	result.TargetAgent = strings.Join(others, ", ")
 	return result, nil
 }
 
+// findSessionByID returns the caller's session with sessionID.
+func findSessionByID(ctx context.Context, client *awid.Client, sessionID string) (*awid.ChatSessionItem, error) {
+	it := client.ChatListSessionsIter()
+	for {
+		item, ok, err := it.Next(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("listing chat sessions: %w", err)
+		}
+		if !ok {
+			return nil, fmt.Errorf("no chat session %s for this identity", sessionID)
+		}
+		if item.SessionID == sessionID {
+			return &item, nil
+		}
+	}
+}
+
 // Listen waits for a message in an existing conversation without sending.
-// Returns on any message in the session (not filtered by sender).
+// Returns on any message in the session (not filtered by sender). When the
+// other side is waiting on the conversation, the wait follows the time the
+// server still holds theirs open; see PendingStreamWait.
 func Listen(ctx context.Context, client *awid.Client, targetAlias string, waitSeconds int, callback StatusCallback) (*SendResult, error) {
-	sessionID, _, err := findSession(ctx, client, targetAlias)
+	sessionID, senderWaiting, err := findSession(ctx, client, targetAlias)
 	if err != nil {
 		return nil, err
 	}
+	if senderWaiting {
+		waitSeconds = reconcilePendingWait(ctx, client, sessionID, waitSeconds, callback)
+	}
 
 	acceptAll := func(ev Event) (bool, bool) { return true, false }
 
-	result, err := waitForMessage(ctx, client, client.ChatStream, sessionID, nil, "", waitSeconds, nil, callback, acceptAll)
+	result, err := waitForMessage(ctx, nil, client, client.ChatStream, sessionID, nil, "", waitSeconds, nil, callback, nil, acceptAll)
 	if err != nil {
 		return nil, err
 	}
@@ -1225,20 +1724,116 @@ func Listen(ctx context.Context, client *awid.Client, targetAlias string, waitSe
 	return result, nil
 }
 
+// PendingStreamWait reconciles a client-side wait with the server's view of
+// a pending conversation. While a sender is waiting, the server reports in
+// TimeRemainingSeconds how long it holds that wait open; a stream on the
+// conversation should last exactly as long, since ending earlier drops out
+// while the sender still expects an answer and lasting longer listens after
+// the server has closed their wait. Without a positive server figure, wait
+// is returned unchanged. The result never exceeds the stream safety-net
+// deadline.
+func PendingStreamWait(wait time.Duration, pending *awid.ChatPendingItem) time.Duration {
+	if pending == nil || !pending.SenderWaiting || pending.TimeRemainingSeconds == nil || *pending.TimeRemainingSeconds <= 0 {
+		return wait
+	}
+	return min(time.Duration(*pending.TimeRemainingSeconds)*time.Second, maxStreamDeadline)
+}
+
+// reconcilePendingWait re-reads sessionID's pending entry and returns
+// waitSeconds adjusted by PendingStreamWait. Failing to read pending keeps
+// the client-side wait.
+func reconcilePendingWait(ctx context.Context, client *awid.Client, sessionID string, waitSeconds int, callback StatusCallback) int {
+	pendingResp, err := client.ChatPending(ctx)
+	if err != nil {
+		return waitSeconds
+	}
+	for i := range pendingResp.Pending {
+		if pendingResp.Pending[i].SessionID != sessionID {
+			continue
+		}
+		adjusted := int(PendingStreamWait(time.Duration(waitSeconds)*time.Second, &pendingResp.Pending[i]) / time.Second)
+		if adjusted != waitSeconds && callback != nil {
+			callback("wait_adjusted", fmt.Sprintf("waiting %ds to match the sender's remaining wait", adjusted))
+		}
+		return adjusted
+	}
+	return waitSeconds
+}
+
 // Open fetches unread messages for a conversation and marks them as read.
 func Open(ctx context.Context, client *awid.Client, targetAlias string) (*OpenResult, error) {
+	return OpenWithOptions(ctx, client, targetAlias, OpenOptions{})
+}
+
+// OpenWithOptions is Open with a per-message callback; see OpenOptions.
+func OpenWithOptions(ctx context.Context, client *awid.Client, targetAlias string, opts OpenOptions) (*OpenResult, error) {
 	sessionID, senderWaiting, err := findSession(ctx, client, targetAlias)
 	if err != nil {
 		return nil, err
 	}
 
-	messagesResp, err := client.ChatHistory(ctx, awid.ChatHistoryParams{
+	result, delivered, err := openSession(ctx, client, sessionID, targetAlias, senderWaiting, opts.OnMessage)
+	if err != nil {
+		return nil, err
+	}
+	if len(delivered) > 0 {
+		_ = SaveDeliveredIDs(delivered)
+	}
+	refreshSenderWaiting(ctx, client, []*OpenResult{result})
+	return result, nil
+}
+
+// refreshSenderWaiting re-reads the pending conversations once the results'
+// messages are marked read, and records whether each sender that was
+// waiting still is, with the time left on their wait. A sender that was not
+// waiting before the read is not looked up again.
+func refreshSenderWaiting(ctx context.Context, client *awid.Client, results []*OpenResult) {
+	check := false
+	for _, r := range results {
+		r.SenderStillWaiting = r.SenderWaiting && r.Error == ""
+		check = check || r.SenderStillWaiting
+	}
+	if !check {
+		return
+	}
+	pendingResp, err := client.ChatPending(ctx)
+	if err != nil {
+		return
+	}
+	bySession := make(map[string]awid.ChatPendingItem, len(pendingResp.Pending))
+	for _, p := range pendingResp.Pending {
+		bySession[p.SessionID] = p
+	}
+	for _, r := range results {
+		if !r.SenderStillWaiting {
+			continue
+		}
+		p, ok := bySession[r.SessionID]
+		remaining := 0
+		if ok && p.TimeRemainingSeconds != nil {
+			remaining = *p.TimeRemainingSeconds
+		}
+		r.SenderStillWaiting = ok && p.SenderWaiting && remaining > 0
+		if r.SenderStillWaiting {
+			r.TimeRemainingSeconds = remaining
+		}
+	}
+}
+
+// openSession reads and marks read the unread messages in sessionID,
+// passing each to onMessage, if set, as its page arrives. It returns the
+// IDs to record as delivered rather than saving them, so that OpenAll can
+// write the delivered-ID file once instead of racing on it.
+func openSession(ctx context.Context, client *awid.Client, sessionID, targetAlias string, senderWaiting bool, onMessage MessageCallback) (*OpenResult, []string, error) {
+	messages := []Event{}
+	unread, err := fetchHistory(ctx, client, awid.ChatHistoryParams{
 		SessionID:  sessionID,
 		UnreadOnly: true,
-		Limit:      1000,
+	}, func(page []awid.ChatMessage) {
+		messages = append(messages, emitMessages(buildMessages(FilterDeliveredMessages(page)), onMessage)...)
 	})
 	if err != nil {
-		return nil, fmt.Errorf("getting unread messages: %w", err)
+		return nil, nil, fmt.Errorf("getting unread messages: %w", err)
 	}
 
 	result := &OpenResult{
@@ -1247,39 +1842,109 @@ func Open(ctx context.Context, client *awid.Client, targetAlias string) (*OpenRe
 		SenderWaiting: senderWaiting,
 	}
 
-	if len(messagesResp.Messages) == 0 {
+	if len(unread) == 0 {
 		result.UnreadWasEmpty = true
-		return result, nil
+		return result, nil, nil
 	}
 
-	filteredMessages := FilterDeliveredMessages(messagesResp.Messages)
-	result.Messages = buildMessages(filteredMessages)
-
-	if ids := DeliveredMessageIDs(messagesResp.Messages); len(ids) > 0 {
-		_ = SaveDeliveredIDs(ids)
-	}
+	result.Messages = messages
 
-	lastMessageID := messagesResp.Messages[len(messagesResp.Messages)-1].MessageID
+	lastMessageID := unread[len(unread)-1].MessageID
 	if markReadBestEffort(ctx, client, sessionID, lastMessageID) {
-		result.MarkedRead = len(messagesResp.Messages)
+		result.MarkedRead = len(unread)
 	}
 	if len(result.Messages) == 0 {
 		result.UnreadWasEmpty = true
 	}
 
-	return result, nil
+	return result, DeliveredMessageIDs(unread), nil
+}
+
+// maxConcurrentOpens bounds how many sessions OpenAll reads at once.
+const maxConcurrentOpens = 4
+
+// OpenAll opens every pending conversation, reading and marking read their
+// unread messages concurrently. Results follow the pending order. A session
+// that fails to open gets its Error set instead of failing the batch; the
+// returned error is reserved for failing to list pending conversations.
+func OpenAll(ctx context.Context, client *awid.Client, myAlias string) ([]OpenResult, error) {
+	pendingResp, err := client.ChatPending(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("getting pending chats: %w", err)
+	}
+
+	results := make([]OpenResult, len(pendingResp.Pending))
+	delivered := make([][]string, len(pendingResp.Pending))
+	sem := make(chan struct{}, maxConcurrentOpens)
+	var wg sync.WaitGroup
+	for i, p := range pendingResp.Pending {
+		target := pendingTargetLabel(client, myAlias, p)
+		wg.Add(1)
+		go func(i int, p awid.ChatPendingItem, target string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			result, ids, err := openSession(ctx, client, p.SessionID, target, p.SenderWaiting, nil)
+			if err != nil {
+				results[i] = OpenResult{SessionID: p.SessionID, TargetAgent: target, SenderWaiting: p.SenderWaiting, Error: err.Error()}
+				return
+			}
+			results[i] = *result
+			delivered[i] = ids
+		}(i, p, target)
+	}
+	wg.Wait()
+
+	var allDelivered []string
+	for _, ids := range delivered {
+		allDelivered = append(allDelivered, ids...)
+	}
+	if len(allDelivered) > 0 {
+		_ = SaveDeliveredIDs(allDelivered)
+	}
+	refs := make([]*OpenResult, len(results))
+	for i := range results {
+		refs[i] = &results[i]
+	}
+	refreshSenderWaiting(ctx, client, refs)
+	return results, nil
 }
 
-// History fetches all messages in a conversation.
-func History(ctx context.Context, client *awid.Client, targetAlias string) (*HistoryResult, error) {
+// pendingTargetLabel names the other participants of a pending conversation
+// in the form the other chat commands accept as a target.
+func pendingTargetLabel(client *awid.Client, myAlias string, p awid.ChatPendingItem) string {
+	var labels []string
+	for _, row := range chatParticipantRows(p.Participants, p.ParticipantDIDs, p.ParticipantAddresses) {
+		participant := awid.ChatParticipant{Alias: row.Alias, Address: row.Address, DID: row.DID}
+		if chatParticipantMatchesSelf(participant, client, myAlias) {
+			continue
+		}
+		if label := row.label(); label != "" {
+			labels = append(labels, label)
+		}
+	}
+	if len(labels) == 0 {
+		return strings.TrimSpace(p.LastFrom)
+	}
+	return strings.Join(labels, ", ")
+}
+
+// History fetches messages in a conversation: all of them by default, or only
+// those newer than opts.Since / opts.AfterMessageID for incremental tailing.
+// opts.OnMessage, if set, sees each message as its page arrives.
+func History(ctx context.Context, client *awid.Client, targetAlias string, opts HistoryOptions) (*HistoryResult, error) {
 	sessionID, _, err := findSession(ctx, client, targetAlias)
 	if err != nil {
 		return nil, err
 	}
 
-	messagesResp, err := client.ChatHistory(ctx, awid.ChatHistoryParams{
-		SessionID: sessionID,
-		Limit:     1000,
+	messages := []Event{}
+	_, err = fetchHistory(ctx, client, awid.ChatHistoryParams{
+		SessionID:      sessionID,
+		Since:          opts.Since,
+		AfterMessageID: opts.AfterMessageID,
+	}, func(page []awid.ChatMessage) {
+		messages = append(messages, emitMessages(buildMessages(filterHistorySince(page, opts)), opts.OnMessage)...)
 	})
 	if err != nil {
 		return nil, fmt.Errorf("getting messages: %w", err)
@@ -1287,10 +1952,107 @@ func History(ctx context.Context, client *awid.Client, targetAlias string) (*His
 
 	return &HistoryResult{
 		SessionID: sessionID,
-		Messages:  buildMessages(messagesResp.Messages),
+		Messages:  messages,
 	}, nil
 }
 
+const (
+	// historyPageSize is how many messages each history request asks for
+	// from servers that can page forward.
+	historyPageSize = 200
+	// legacyHistoryLimit is how many of the latest messages one request
+	// asks for from servers that cannot.
+	legacyHistoryLimit = 1000
+)
+
+// fetchHistory reads the messages params selects, oldest first, a page at a
+// time, and hands each page to onPage as it arrives. It returns every
+// message read. A server that ignores oldest_first is read with a single
+// request for its latest legacyHistoryLimit messages instead.
+func fetchHistory(ctx context.Context, client *awid.Client, params awid.ChatHistoryParams, onPage func([]awid.ChatMessage)) ([]awid.ChatMessage, error) {
+	params.OldestFirst = true
+	params.Limit = historyPageSize
+	var all []awid.ChatMessage
+	for {
+		resp, err := client.ChatHistory(ctx, params)
+		if err != nil {
+			return nil, err
+		}
+		// A short answer from an older server already holds every
+		// message; a full one may be missing some.
+		if !resp.OldestFirst && len(resp.Messages) >= historyPageSize {
+			params.OldestFirst = false
+			params.Limit = legacyHistoryLimit
+			if resp, err = client.ChatHistory(ctx, params); err != nil {
+				return nil, err
+			}
+		}
+		all = append(all, resp.Messages...)
+		onPage(resp.Messages)
+		if !resp.OldestFirst || len(resp.Messages) < historyPageSize {
+			return all, nil
+		}
+		params.AfterMessageID = resp.NextCursor
+		if params.AfterMessageID == "" {
+			params.AfterMessageID = resp.Messages[len(resp.Messages)-1].MessageID
+		}
+	}
+}
+
+// emitMessages passes each of events to onMessage, if set, and returns
+// events.
+func emitMessages(events []Event, onMessage MessageCallback) []Event {
+	if onMessage != nil {
+		for _, e := range events {
+			onMessage(e)
+		}
+	}
+	return events
+}
+
+// Search finds the messages in a conversation whose body contains query,
+// ignoring case.
+func Search(ctx context.Context, client *awid.Client, targetAlias, query string) (*SearchResult, error) {
+	sessionID, _, err := findSession(ctx, client, targetAlias)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.ChatSearch(ctx, sessionID, query)
+	if err != nil {
+		return nil, fmt.Errorf("searching messages: %w", err)
+	}
+	return &SearchResult{
+		SessionID: sessionID,
+		Query:     query,
+		Messages:  buildMessages(resp.Messages),
+	}, nil
+}
+
+// filterHistorySince drops messages at or before the incremental cursor.
+// Servers that ignore the since/after_message_id parameters return the full
+// transcript, so the cursor is applied here too.
+func filterHistorySince(messages []awid.ChatMessage, opts HistoryOptions) []awid.ChatMessage {
+	if opts.AfterMessageID != "" {
+		for i, m := range messages {
+			if m.MessageID == opts.AfterMessageID {
+				messages = messages[i+1:]
+				break
+			}
+		}
+	}
+	if opts.Since.IsZero() {
+		return messages
+	}
+	filtered := make([]awid.ChatMessage, 0, len(messages))
+	for _, m := range messages {
+		if ts, err := m.Time(); err == nil && !ts.After(opts.Since) {
+			continue
+		}
+		filtered = append(filtered, m)
+	}
+	return filtered
+}
+
 // Pending lists conversations with unread messages.
 func Pending(ctx context.Context, client *awid.Client) (*PendingResult, error) {
 	resp, err := client.ChatPending(ctx)
@@ -1333,12 +2095,94 @@ func Pending(ctx context.Context, client *awid.Client) (*PendingResult, error) {
 			LastActivity:         p.LastActivity,
 			SenderWaiting:        p.SenderWaiting,
 			TimeRemainingSeconds: p.TimeRemainingSeconds,
+			Subject:              p.Subject,
+			Metadata:             p.Metadata,
 		})
 	}
 
 	return result, nil
 }
 
+// List summarizes every conversation the caller participates in, newest
+// activity first. Pending conversations are merged in so unread counts and
+// last messages are accurate even when the session list omits them.
+func List(ctx context.Context, client *awid.Client, myAlias string) (*ListResult, error) {
+	pending, err := client.ChatPending(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("getting pending chats: %w", err)
+	}
+	pendingBySession := make(map[string]awid.ChatPendingItem, len(pending.Pending))
+	for _, p := range pending.Pending {
+		pendingBySession[p.SessionID] = p
+	}
+
+	result := &ListResult{Conversations: []ConversationSummary{}}
+	it := client.ChatListSessionsIter()
+	for {
+		item, ok, err := it.Next(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("listing chat sessions: %w", err)
+		}
+		if !ok {
+			break
+		}
+		summary := ConversationSummary{
+			SessionID:     item.SessionID,
+			TargetAgent:   sessionTargetLabel(client, myAlias, item),
+			Participants:  item.Participants,
+			LastActivity:  item.LastActivity,
+			LastMessage:   item.LastMessage,
+			LastFrom:      item.LastFrom,
+			UnreadCount:   item.UnreadCount,
+			SenderWaiting: item.SenderWaiting,
+			CreatedAt:     item.CreatedAt,
+			Subject:       item.Subject,
+		}
+		if p, ok := pendingBySession[item.SessionID]; ok {
+			summary.UnreadCount = p.UnreadCount
+			summary.SenderWaiting = summary.SenderWaiting || p.SenderWaiting
+			if p.LastActivity != "" {
+				summary.LastActivity = p.LastActivity
+				summary.LastMessage = p.LastMessage
+				summary.LastFrom = p.LastFrom
+			}
+		}
+		result.Conversations = append(result.Conversations, summary)
+	}
+
+	sort.SliceStable(result.Conversations, func(i, j int) bool {
+		return conversationRecency(result.Conversations[i]).After(conversationRecency(result.Conversations[j]))
+	})
+	return result, nil
+}
+
+// sessionTargetLabel names the other participants of a listed session in
+// the form the other chat commands accept as a target.
+func sessionTargetLabel(client *awid.Client, myAlias string, item awid.ChatSessionItem) string {
+	var labels []string
+	for _, row := range chatParticipantRows(item.Participants, item.ParticipantDIDs, item.ParticipantAddresses) {
+		participant := awid.ChatParticipant{Alias: row.Alias, Address: row.Address, DID: row.DID}
+		if chatParticipantMatchesSelf(participant, client, myAlias) {
+			continue
+		}
+		if label := row.label(); label != "" {
+			labels = append(labels, label)
+		}
+	}
+	return strings.Join(labels, ", ")
+}
+
+// conversationRecency is when a conversation last saw activity, falling back
+// to its creation time; unparsable timestamps sort last.
+func conversationRecency(c ConversationSummary) time.Time {
+	for _, value := range []string{c.LastActivity, c.CreatedAt} {
+		if ts, err := awid.ParseTimestamp(value); err == nil {
+			return ts
+		}
+	}
+	return time.Time{}
+}
+
 // ExtendWait sends an extend-wait message requesting more time to reply.
 func ExtendWait(ctx context.Context, client *awid.Client, targetAlias string, message string) (*ExtendWaitResult, error) {
 	sessionID, _, err := findSession(ctx, client, targetAlias)
@@ -1362,6 +2206,101 @@ func ExtendWait(ctx context.Context, client *awid.Client, targetAlias string, me
 	}, nil
 }
 
+// Reply posts message into the existing conversation with targetAlias and,
+// when wait is positive, waits up to wait seconds for targetAlias to answer.
+// Unlike Send it never starts a new session; it returns an error wrapping
+// ErrNoConversation when there is none to continue.
+func Reply(ctx context.Context, client *awid.Client, myAlias, targetAlias, message string, wait int, callback StatusCallback) (*SendResult, error) {
+	return reply(ctx, client, client.ChatStream, myAlias, targetAlias, message, wait, callback)
+}
+
+func reply(ctx context.Context, client *awid.Client, openStream streamOpener, myAlias, targetAlias, message string, wait int, callback StatusCallback) (*SendResult, error) {
+	sentAt := time.Now()
+	wait, capped, err := resolveSendWait(ctx, wait, 0)
+	if err != nil {
+		return nil, err
+	}
+	if capped && callback != nil {
+		callback("wait_capped", fmt.Sprintf("wait capped at %ds", wait))
+	}
+
+	sessionID, _, err := findSession(ctx, client, targetAlias)
+	if err != nil {
+		return nil, err
+	}
+	msgResp, err := client.ChatSendMessage(ctx, sessionID, &awid.ChatSendMessageRequest{Body: message})
+	if err != nil {
+		return nil, fmt.Errorf("sending reply: %w", err)
+	}
+
+	// The in-session send endpoint does not report who is connected, so
+	// treat the target as connected rather than warn on every reply.
+	return sendCommon(ctx, client, openStream, sendResponse{
+		SessionID:        sessionID,
+		MessageID:        msgResp.MessageID,
+		TargetsConnected: []string{targetAlias},
+	}, myAlias, []string{targetAlias}, message, wait, SendOptions{Wait: wait}, &sentAt, callback)
+}
+
+// Leave leaves the conversation with targetAlias without sending a message.
+func Leave(ctx context.Context, client *awid.Client, targetAlias string) (*LeaveResult, error) {
+	sessionID, _, err := findSession(ctx, client, targetAlias)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.ChatLeaveSession(ctx, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("leaving conversation: %w", err)
+	}
+
+	return &LeaveResult{
+		SessionID:     sessionID,
+		TargetAgent:   targetAlias,
+		SessionClosed: resp.SessionClosed,
+	}, nil
+}
+
+// MarkUnread marks the conversation with targetAlias unread again from
+// fromMessageID onwards, so it shows up in Pending on the next poll. An empty
+// fromMessageID marks the latest message from targetAlias unread.
+func MarkUnread(ctx context.Context, client *awid.Client, targetAlias, fromMessageID string) (*MarkUnreadResult, error) {
+	sessionID, _, err := findSession(ctx, client, targetAlias)
+	if err != nil {
+		return nil, err
+	}
+
+	fromMessageID = strings.TrimSpace(fromMessageID)
+	if fromMessageID == "" {
+		messagesResp, err := client.ChatHistory(ctx, awid.ChatHistoryParams{SessionID: sessionID, Limit: 1000})
+		if err != nil {
+			return nil, fmt.Errorf("getting messages: %w", err)
+		}
+		events := buildMessages(messagesResp.Messages)
+		for i := len(events) - 1; i >= 0; i-- {
+			if chatEventMatchesTarget(events[i], targetAlias) {
+				fromMessageID = events[i].MessageID
+				break
+			}
+		}
+		if fromMessageID == "" {
+			return nil, fmt.Errorf("no message from %s to mark unread", targetAlias)
+		}
+	}
+
+	resp, err := client.ChatMarkUnread(ctx, sessionID, fromMessageID)
+	if err != nil {
+		return nil, fmt.Errorf("marking unread: %w", err)
+	}
+
+	return &MarkUnreadResult{
+		SessionID:     sessionID,
+		TargetAgent:   targetAlias,
+		FromMessageID: fromMessageID,
+		UnreadCount:   resp.UnreadCount,
+	}, nil
+}
+
 // ShowPending shows the pending conversation with a specific agent.
 func ShowPending(ctx context.Context, client *awid.Client, targetAlias string) (*SendResult, error) {
 	sessionID, _, err := findSession(ctx, client, targetAlias)
@@ -1424,7 +2363,7 @@ func ShowPending(ctx context.Context, client *awid.Client, targetAlias string) (
 		}
 		return &SendResult{
 			SessionID:     p.SessionID,
-			Status:        "pending",
+			Status:        StatusPending,
 			TargetAgent:   targetAlias,
 			Reply:         p.LastMessage,
 			SenderWaiting: p.SenderWaiting,
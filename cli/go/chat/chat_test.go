@@ -15,7 +15,10 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"runtime"
+	"strconv"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -180,6 +183,65 @@ func TestPendingReturnsConversations(t *testing.T) {
 	}
 }
 
+func TestListSortsByRecencyAndMergesPending(t *testing.T) {
+	t.Parallel()
+
+	server := newMockServer(map[string]http.HandlerFunc{
+		"GET /v1/chat/sessions": func(w http.ResponseWriter, _ *http.Request) {
+			jsonResponse(w, awid.ChatListSessionsResponse{Sessions: []awid.ChatSessionItem{
+				// Old server: no activity fields, so created_at orders it.
+				{SessionID: "quiet", Participants: []string{"carol"}, CreatedAt: "2026-03-01T09:00:00Z"},
+				{SessionID: "busy", Participants: []string{"bob"}, CreatedAt: "2026-02-01T09:00:00Z", LastActivity: "2026-03-01T10:00:00Z", LastMessage: "stale", LastFrom: "bob"},
+				{SessionID: "newest", Participants: []string{"dave"}, CreatedAt: "2026-02-01T09:00:00Z", LastActivity: "2026-03-01T11:00:00Z", LastMessage: "latest", LastFrom: "alice"},
+			}})
+		},
+		"GET /v1/chat/pending": func(w http.ResponseWriter, _ *http.Request) {
+			jsonResponse(w, awid.ChatPendingResponse{Pending: []awid.ChatPendingItem{
+				{SessionID: "busy", Participants: []string{"alice", "bob"}, LastMessage: "are you there?", LastFrom: "bob", UnreadCount: 3, LastActivity: "2026-03-01T12:00:00Z", SenderWaiting: true},
+			}})
+		},
+	})
+	t.Cleanup(server.Close)
+
+	result, err := List(context.Background(), mustClient(t, server.URL), "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var order []string
+	for _, c := range result.Conversations {
+		order = append(order, c.SessionID)
+	}
+	if strings.Join(order, ",") != "busy,newest,quiet" {
+		t.Fatalf("order=%v", order)
+	}
+	busy := result.Conversations[0]
+	if busy.UnreadCount != 3 || busy.LastMessage != "are you there?" || !busy.SenderWaiting || busy.TargetAgent != "bob" {
+		t.Fatalf("busy=%+v", busy)
+	}
+	if quiet := result.Conversations[2]; quiet.UnreadCount != 0 || quiet.LastActivity != "" {
+		t.Fatalf("quiet=%+v", quiet)
+	}
+}
+
+func TestPendingCarriesSubject(t *testing.T) {
+	t.Parallel()
+
+	server := newMockServer(map[string]http.HandlerFunc{
+		"GET /v1/chat/pending": func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte(`{"pending":[{"session_id":"s1","participants":["bob"],"unread_count":1,"subject":"Deploy plan","metadata":{"ticket":"OPS-7"}}]}`))
+		},
+	})
+	t.Cleanup(server.Close)
+
+	result, err := Pending(context.Background(), mustClient(t, server.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Pending) != 1 || result.Pending[0].Subject != "Deploy plan" || result.Pending[0].Metadata["ticket"] != "OPS-7" {
+		t.Fatalf("pending=%+v", result.Pending)
+	}
+}
+
 func TestPendingMapsLastFromAliasToParticipantAddress(t *testing.T) {
 	t.Parallel()
 
@@ -280,6 +342,229 @@ func TestExtendWait(t *testing.T) {
 	}
 }
 
+func TestLeave(t *testing.T) {
+	t.Parallel()
+
+	left := false
+	server := newMockServer(map[string]http.HandlerFunc{
+		"GET /v1/chat/pending": func(w http.ResponseWriter, _ *http.Request) {
+			jsonResponse(w, awid.ChatPendingResponse{
+				Pending: []awid.ChatPendingItem{
+					{SessionID: "s1", Participants: []string{"alice", "bob"}},
+				},
+			})
+		},
+		"POST /v1/chat/sessions/s1/leave": func(w http.ResponseWriter, _ *http.Request) {
+			left = true
+			jsonResponse(w, awid.ChatLeaveSessionResponse{SessionID: "s1", MessageID: "msg-1", SessionClosed: true})
+		},
+	})
+	t.Cleanup(server.Close)
+
+	result, err := Leave(context.Background(), mustClient(t, server.URL), "bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !left {
+		t.Fatal("leave endpoint not called")
+	}
+	if result.SessionID != "s1" || result.TargetAgent != "bob" || !result.SessionClosed {
+		t.Fatalf("result=%+v", result)
+	}
+}
+
+func TestNudgeRateLimitsRepeatNudges(t *testing.T) {
+	// Not parallel: the rate limit lives in the file deliveredIDsTestPath
+	// points the environment at, which parallel tests overwrite.
+	deliveredIDsTestPath(t)
+
+	nudges := 0
+	server := newMockServer(map[string]http.HandlerFunc{
+		"GET /v1/chat/pending": func(w http.ResponseWriter, _ *http.Request) {
+			jsonResponse(w, awid.ChatPendingResponse{
+				Pending: []awid.ChatPendingItem{
+					{SessionID: "s1", Participants: []string{"alice", "bob"}},
+				},
+			})
+		},
+		"POST /v1/chat/sessions/s1/nudge": func(w http.ResponseWriter, _ *http.Request) {
+			nudges++
+			jsonResponse(w, awid.ChatNudgeResponse{SessionID: "s1", Nudged: []string{"bob"}})
+		},
+	})
+	t.Cleanup(server.Close)
+	client := mustClient(t, server.URL)
+
+	result, err := Nudge(context.Background(), client, "bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.SessionID != "s1" || len(result.Nudged) != 1 || result.Nudged[0] != "bob" {
+		t.Fatalf("result=%+v", result)
+	}
+
+	_, err = Nudge(context.Background(), client, "bob")
+	if !errors.Is(err, ErrNudgeTooSoon) {
+		t.Fatalf("second nudge err=%v, want ErrNudgeTooSoon", err)
+	}
+	if nudges != 1 {
+		t.Fatalf("nudge endpoint called %d times, want 1", nudges)
+	}
+}
+
+func TestReplyPostsIntoExistingSessionAndWaits(t *testing.T) {
+	t.Parallel()
+
+	server := newMockServer(map[string]http.HandlerFunc{
+		"GET /v1/chat/pending": func(w http.ResponseWriter, _ *http.Request) {
+			jsonResponse(w, awid.ChatPendingResponse{
+				Pending: []awid.ChatPendingItem{
+					{SessionID: "s1", Participants: []string{"alice", "bob"}},
+				},
+			})
+		},
+		"POST /v1/chat/sessions": func(w http.ResponseWriter, _ *http.Request) {
+			t.Error("reply must not create a session")
+		},
+		"POST /v1/chat/sessions/s1/messages": func(w http.ResponseWriter, r *http.Request) {
+			var req awid.ChatSendMessageRequest
+			_ = json.NewDecoder(r.Body).Decode(&req)
+			if req.Body != "sounds good" {
+				t.Errorf("body=%q", req.Body)
+			}
+			jsonResponse(w, awid.ChatSendMessageResponse{MessageID: "msg-sent", Delivered: true})
+		},
+		"GET /v1/chat/sessions/s1/stream": func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("Content-Type", "text/event-stream")
+			for _, ev := range []map[string]any{
+				{"type": "message", "message_id": "msg-sent", "from_agent": "alice", "body": "sounds good"},
+				{"type": "message", "message_id": "msg-reply", "from_agent": "bob", "body": "shipping it"},
+			} {
+				data, _ := json.Marshal(ev)
+				fmt.Fprintf(w, "event: message\ndata: %s\n\n", data)
+			}
+		},
+	})
+	t.Cleanup(server.Close)
+
+	result, err := Reply(context.Background(), mustClient(t, server.URL), "alice", "bob", "sounds good", 5, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.SessionID != "s1" || result.Status != "replied" || result.Reply != "shipping it" {
+		t.Fatalf("result=%+v", result)
+	}
+	if result.TargetNotConnected {
+		t.Fatal("reply should not report the target as disconnected")
+	}
+}
+
+func TestReplyWithoutConversation(t *testing.T) {
+	t.Parallel()
+
+	server := newMockServer(map[string]http.HandlerFunc{
+		"GET /v1/chat/pending": func(w http.ResponseWriter, _ *http.Request) {
+			jsonResponse(w, awid.ChatPendingResponse{})
+		},
+		"GET /v1/chat/sessions": func(w http.ResponseWriter, _ *http.Request) {
+			jsonResponse(w, awid.ChatListSessionsResponse{})
+		},
+	})
+	t.Cleanup(server.Close)
+
+	_, err := Reply(context.Background(), mustClient(t, server.URL), "alice", "bob", "hello", 0, nil)
+	if !errors.Is(err, ErrNoConversation) {
+		t.Fatalf("err=%v, want ErrNoConversation", err)
+	}
+}
+
+func TestWaitReattachesToSessionAndSkipsOwnMessages(t *testing.T) {
+	t.Parallel()
+
+	server := newMockServer(map[string]http.HandlerFunc{
+		"GET /v1/chat/sessions": func(w http.ResponseWriter, _ *http.Request) {
+			jsonResponse(w, awid.ChatListSessionsResponse{
+				Sessions: []awid.ChatSessionItem{{SessionID: "s1", Participants: []string{"alice", "bob"}}},
+			})
+		},
+		"POST /v1/chat/sessions/s1/messages": func(w http.ResponseWriter, _ *http.Request) {
+			t.Error("wait must not send")
+		},
+		"GET /v1/chat/sessions/s1/stream": func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("Content-Type", "text/event-stream")
+			for _, ev := range []map[string]any{
+				{"type": "message", "message_id": "m1", "from_agent": "alice", "body": "from another terminal"},
+				{"type": "message", "message_id": "m2", "from_agent": "bob", "body": "done, see PR"},
+			} {
+				data, _ := json.Marshal(ev)
+				fmt.Fprintf(w, "event: message\ndata: %s\n\n", data)
+			}
+		},
+	})
+	t.Cleanup(server.Close)
+
+	client := mustClient(t, server.URL)
+	result, err := Wait(context.Background(), client, "alice", "s1", 5, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.SessionID != "s1" || result.Status != StatusReplied || result.Reply != "done, see PR" || result.TargetAgent != "bob" {
+		t.Fatalf("result=%+v", result)
+	}
+
+	if _, err := Wait(context.Background(), client, "alice", "s-unknown", 5, nil); err == nil || !strings.Contains(err.Error(), "no chat session s-unknown") {
+		t.Fatalf("err=%v", err)
+	}
+}
+
+func TestMarkUnreadDefaultsToLatestMessageFromTarget(t *testing.T) {
+	t.Parallel()
+
+	var gotFrom string
+	server := newMockServer(map[string]http.HandlerFunc{
+		"GET /v1/chat/pending": func(w http.ResponseWriter, _ *http.Request) {
+			jsonResponse(w, awid.ChatPendingResponse{})
+		},
+		"GET /v1/chat/sessions": func(w http.ResponseWriter, _ *http.Request) {
+			jsonResponse(w, awid.ChatListSessionsResponse{
+				Sessions: []awid.ChatSessionItem{{SessionID: "s1", Participants: []string{"alice", "bob"}}},
+			})
+		},
+		"GET /v1/chat/sessions/s1/messages": func(w http.ResponseWriter, _ *http.Request) {
+			jsonResponse(w, awid.ChatHistoryResponse{
+				Messages: []awid.ChatMessage{
+					{MessageID: "m1", FromAgent: "bob", Body: "hello", Timestamp: "2025-01-01T00:00:00Z"},
+					{MessageID: "m2", FromAgent: "bob", Body: "still there?", Timestamp: "2025-01-01T00:00:01Z"},
+					{MessageID: "m3", FromAgent: "alice", Body: "yes", Timestamp: "2025-01-01T00:00:02Z"},
+				},
+			})
+		},
+		"POST /v1/chat/sessions/s1/unread": func(w http.ResponseWriter, r *http.Request) {
+			var req awid.ChatMarkUnreadRequest
+			_ = json.NewDecoder(r.Body).Decode(&req)
+			gotFrom = req.FromMessageID
+			jsonResponse(w, awid.ChatMarkUnreadResponse{SessionID: "s1", UnreadCount: 1})
+		},
+	})
+	t.Cleanup(server.Close)
+
+	client := mustClient(t, server.URL)
+	result, err := MarkUnread(context.Background(), client, "bob", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotFrom != "m2" || result.FromMessageID != "m2" || result.UnreadCount != 1 || result.SessionID != "s1" {
+		t.Fatalf("from=%q result=%+v", gotFrom, result)
+	}
+
+	if _, err := MarkUnread(context.Background(), client, "bob", "m1"); err != nil {
+		t.Fatal(err)
+	}
+	if gotFrom != "m1" {
+		t.Fatalf("explicit from=%q, want m1", gotFrom)
+	}
+}
+
 func TestOpen(t *testing.T) {
 	t.Parallel()
 	deliveredIDsTestPath(t)
@@ -332,6 +617,112 @@ func TestOpen(t *testing.T) {
 	}
 }
 
+func TestOpenRechecksSenderWaitingAfterRead(t *testing.T) {
+	t.Parallel()
+	deliveredIDsTestPath(t)
+
+	seconds := func(n int) *int { return &n }
+	for _, tc := range []struct {
+		name          string
+		after         []awid.ChatPendingItem
+		wantWaiting   bool
+		wantRemaining int
+	}{
+		{
+			name:          "still waiting",
+			after:         []awid.ChatPendingItem{{SessionID: "s1", Participants: []string{"alice", "bob"}, SenderWaiting: true, TimeRemainingSeconds: seconds(42)}},
+			wantWaiting:   true,
+			wantRemaining: 42,
+		},
+		{
+			name:  "gave up",
+			after: []awid.ChatPendingItem{},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var pendingCalls atomic.Int32
+			server := newMockServer(map[string]http.HandlerFunc{
+				"GET /v1/chat/pending": func(w http.ResponseWriter, _ *http.Request) {
+					if pendingCalls.Add(1) == 1 {
+						jsonResponse(w, awid.ChatPendingResponse{
+							Pending: []awid.ChatPendingItem{{SessionID: "s1", Participants: []string{"alice", "bob"}, SenderWaiting: true, TimeRemainingSeconds: seconds(50)}},
+						})
+						return
+					}
+					jsonResponse(w, awid.ChatPendingResponse{Pending: tc.after})
+				},
+				"GET /v1/chat/sessions/s1/messages": func(w http.ResponseWriter, _ *http.Request) {
+					jsonResponse(w, awid.ChatHistoryResponse{
+						Messages: []awid.ChatMessage{{MessageID: "m1", FromAgent: "bob", Body: "still there?", Timestamp: "2025-01-01T00:00:00Z"}},
+					})
+				},
+				"POST /v1/chat/sessions/s1/read": func(w http.ResponseWriter, _ *http.Request) {
+					jsonResponse(w, awid.ChatMarkReadResponse{Success: true, MessagesMarked: 1})
+				},
+			})
+			t.Cleanup(server.Close)
+
+			result, err := Open(context.Background(), mustClient(t, server.URL), "bob")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if pendingCalls.Load() != 2 {
+				t.Fatalf("pending lookups=%d, want one before and one after the read", pendingCalls.Load())
+			}
+			if !result.SenderWaiting || result.SenderStillWaiting != tc.wantWaiting || result.TimeRemainingSeconds != tc.wantRemaining {
+				t.Fatalf("waiting=%v still=%v remaining=%d", result.SenderWaiting, result.SenderStillWaiting, result.TimeRemainingSeconds)
+			}
+		})
+	}
+}
+
+func TestOpenAllCollectsPerSessionErrors(t *testing.T) {
+	t.Parallel()
+	deliveredIDsTestPath(t)
+
+	var marked atomic.Bool
+	server := newMockServer(map[string]http.HandlerFunc{
+		"GET /v1/chat/pending": func(w http.ResponseWriter, _ *http.Request) {
+			jsonResponse(w, awid.ChatPendingResponse{
+				Pending: []awid.ChatPendingItem{
+					{SessionID: "s1", Participants: []string{"alice", "bob"}, SenderWaiting: true},
+					{SessionID: "s2", Participants: []string{"alice", "carol"}},
+				},
+			})
+		},
+		"GET /v1/chat/sessions/s1/messages": func(w http.ResponseWriter, _ *http.Request) {
+			jsonResponse(w, awid.ChatHistoryResponse{
+				Messages: []awid.ChatMessage{{MessageID: "m1", FromAgent: "bob", Body: "ping", Timestamp: "2025-01-01T00:00:00Z"}},
+			})
+		},
+		"GET /v1/chat/sessions/s2/messages": func(w http.ResponseWriter, _ *http.Request) {
+			http.Error(w, `{"detail":"boom"}`, http.StatusInternalServerError)
+		},
+		"POST /v1/chat/sessions/s1/read": func(w http.ResponseWriter, _ *http.Request) {
+			marked.Store(true)
+			jsonResponse(w, awid.ChatMarkReadResponse{Success: true, MessagesMarked: 1})
+		},
+	})
+	t.Cleanup(server.Close)
+
+	results, err := OpenAll(context.Background(), mustClient(t, server.URL), "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("results=%+v", results)
+	}
+	if got := results[0]; got.SessionID != "s1" || got.TargetAgent != "bob" || got.Error != "" || len(got.Messages) != 1 || got.MarkedRead != 1 || !got.SenderWaiting {
+		t.Fatalf("s1 result=%+v", got)
+	}
+	if got := results[1]; got.SessionID != "s2" || got.TargetAgent != "carol" || got.Error == "" {
+		t.Fatalf("s2 result=%+v, want an error", got)
+	}
+	if !marked.Load() {
+		t.Fatal("s1 was not marked read")
+	}
+}
+
 func TestOpenSupportsAddressTargetViaUniqueHandleMatch(t *testing.T) {
 	deliveredIDsTestPath(t)
 
@@ -607,7 +998,7 @@ func TestHistory(t *testing.T) {
 	})
 	t.Cleanup(server.Close)
 
-	result, err := History(context.Background(), mustClient(t, server.URL), "bob")
+	result, err := History(context.Background(), mustClient(t, server.URL), "bob", HistoryOptions{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -619,6 +1010,208 @@ func TestHistory(t *testing.T) {
 	}
 }
 
+func TestHistorySinceReturnsOnlyNewerMessages(t *testing.T) {
+	t.Parallel()
+
+	var gotQuery url.Values
+	server := newMockServer(map[string]http.HandlerFunc{
+		"GET /v1/chat/pending": func(w http.ResponseWriter, _ *http.Request) {
+			jsonResponse(w, awid.ChatPendingResponse{
+				Pending: []awid.ChatPendingItem{
+					{SessionID: "s1", Participants: []string{"alice", "bob"}},
+				},
+			})
+		},
+		"GET /v1/chat/sessions/s1/messages": func(w http.ResponseWriter, r *http.Request) {
+			gotQuery = r.URL.Query()
+			// Respond with the full transcript, as a server without
+			// incremental support would.
+			jsonResponse(w, awid.ChatHistoryResponse{
+				Messages: []awid.ChatMessage{
+					{MessageID: "m1", FromAgent: "alice", Body: "hello", Timestamp: "2025-01-01T00:00:00Z"},
+					{MessageID: "m2", FromAgent: "bob", Body: "hi!", Timestamp: "2025-01-01T00:00:01Z"},
+					{MessageID: "m3", FromAgent: "alice", Body: "how are you?", Timestamp: "2025-01-01T00:00:02Z"},
+				},
+			})
+		},
+	})
+	t.Cleanup(server.Close)
+
+	since := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	result, err := History(context.Background(), mustClient(t, server.URL), "bob", HistoryOptions{Since: since})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotQuery.Get("since") != "2025-01-01T00:00:00Z" {
+		t.Fatalf("since=%q", gotQuery.Get("since"))
+	}
+	if len(result.Messages) != 2 || result.Messages[0].MessageID != "m2" {
+		t.Fatalf("messages=%+v", result.Messages)
+	}
+
+	result, err = History(context.Background(), mustClient(t, server.URL), "bob", HistoryOptions{AfterMessageID: "m2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotQuery.Get("after_message_id") != "m2" {
+		t.Fatalf("after_message_id=%q", gotQuery.Get("after_message_id"))
+	}
+	if len(result.Messages) != 1 || result.Messages[0].MessageID != "m3" {
+		t.Fatalf("messages=%+v", result.Messages)
+	}
+}
+
+// pagedHistoryHandler serves n messages m0..m(n-1) the way a server that
+// supports oldest_first does, recording how many messages onMessage had seen
+// when each request arrived.
+func pagedHistoryHandler(t *testing.T, n int, seen *atomic.Int32, seenAtRequest *[]int32) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		*seenAtRequest = append(*seenAtRequest, seen.Load())
+		q := r.URL.Query()
+		if q.Get("oldest_first") != "true" {
+			t.Errorf("oldest_first=%q", q.Get("oldest_first"))
+		}
+		start := 0
+		if after := q.Get("after_message_id"); after != "" {
+			fmt.Sscanf(after, "m%d", &start)
+			start++
+		}
+		limit, _ := strconv.Atoi(q.Get("limit"))
+		end := min(start+limit, n)
+		resp := awid.ChatHistoryResponse{Messages: []awid.ChatMessage{}, OldestFirst: true}
+		for i := start; i < end; i++ {
+			resp.Messages = append(resp.Messages, awid.ChatMessage{
+				MessageID: fmt.Sprintf("m%d", i),
+				FromAgent: "bob",
+				Body:      fmt.Sprintf("message %d", i),
+				Timestamp: time.Date(2025, 1, 1, 0, 0, i, 0, time.UTC).Format(time.RFC3339),
+			})
+		}
+		jsonResponse(w, resp)
+	}
+}
+
+func TestHistoryCallsOnMessageAsPagesArrive(t *testing.T) {
+	t.Parallel()
+
+	var seen atomic.Int32
+	var seenAtRequest []int32
+	server := newMockServer(map[string]http.HandlerFunc{
+		"GET /v1/chat/pending": func(w http.ResponseWriter, _ *http.Request) {
+			jsonResponse(w, awid.ChatPendingResponse{
+				Pending: []awid.ChatPendingItem{
+					{SessionID: "s1", Participants: []string{"alice", "bob"}},
+				},
+			})
+		},
+		"GET /v1/chat/sessions/s1/messages": pagedHistoryHandler(t, 450, &seen, &seenAtRequest),
+	})
+	t.Cleanup(server.Close)
+
+	var ids []string
+	result, err := History(context.Background(), mustClient(t, server.URL), "bob", HistoryOptions{
+		OnMessage: func(e Event) {
+			seen.Add(1)
+			ids = append(ids, e.MessageID)
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []int32{0, 200, 400}; fmt.Sprint(seenAtRequest) != fmt.Sprint(want) {
+		t.Fatalf("messages seen at each request=%v, want %v", seenAtRequest, want)
+	}
+	if len(ids) != 450 || ids[0] != "m0" || ids[449] != "m449" {
+		t.Fatalf("callback saw %d messages, first=%v", len(ids), ids[:min(len(ids), 1)])
+	}
+	if len(result.Messages) != 450 || result.Messages[449].MessageID != "m449" {
+		t.Fatalf("messages=%d", len(result.Messages))
+	}
+}
+
+func TestHistoryFallsBackToOneRequestOnOlderServers(t *testing.T) {
+	t.Parallel()
+
+	var limits []string
+	server := newMockServer(map[string]http.HandlerFunc{
+		"GET /v1/chat/pending": func(w http.ResponseWriter, _ *http.Request) {
+			jsonResponse(w, awid.ChatPendingResponse{
+				Pending: []awid.ChatPendingItem{
+					{SessionID: "s1", Participants: []string{"alice", "bob"}},
+				},
+			})
+		},
+		"GET /v1/chat/sessions/s1/messages": func(w http.ResponseWriter, r *http.Request) {
+			// Ignore oldest_first and return the latest messages, as a
+			// server without forward paging would.
+			limits = append(limits, r.URL.Query().Get("limit"))
+			limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+			resp := awid.ChatHistoryResponse{}
+			for i := 300 - min(limit, 300); i < 300; i++ {
+				resp.Messages = append(resp.Messages, awid.ChatMessage{MessageID: fmt.Sprintf("m%d", i), FromAgent: "bob"})
+			}
+			jsonResponse(w, resp)
+		},
+	})
+	t.Cleanup(server.Close)
+
+	calls := 0
+	result, err := History(context.Background(), mustClient(t, server.URL), "bob", HistoryOptions{
+		OnMessage: func(Event) { calls++ },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(limits, ",") != "200,1000" {
+		t.Fatalf("limits=%v", limits)
+	}
+	if len(result.Messages) != 300 || calls != 300 || result.Messages[0].MessageID != "m0" {
+		t.Fatalf("messages=%d calls=%d", len(result.Messages), calls)
+	}
+}
+
+func TestOpenWithOptionsCallsOnMessageBeforeMarkingRead(t *testing.T) {
+	t.Parallel()
+	deliveredIDsTestPath(t)
+
+	var seen atomic.Int32
+	var seenAtRequest []int32
+	var seenAtRead int32
+	server := newMockServer(map[string]http.HandlerFunc{
+		"GET /v1/chat/pending": func(w http.ResponseWriter, _ *http.Request) {
+			jsonResponse(w, awid.ChatPendingResponse{
+				Pending: []awid.ChatPendingItem{
+					{SessionID: "s1", Participants: []string{"alice", "bob"}},
+				},
+			})
+		},
+		"GET /v1/chat/sessions/s1/messages": pagedHistoryHandler(t, 250, &seen, &seenAtRequest),
+		"POST /v1/chat/sessions/s1/read": func(w http.ResponseWriter, r *http.Request) {
+			seenAtRead = seen.Load()
+			var req awid.ChatMarkReadRequest
+			_ = json.NewDecoder(r.Body).Decode(&req)
+			if req.UpToMessageID != "m249" {
+				t.Errorf("up_to_message_id=%s", req.UpToMessageID)
+			}
+			jsonResponse(w, awid.ChatMarkReadResponse{Success: true, MessagesMarked: 250})
+		},
+	})
+	t.Cleanup(server.Close)
+
+	result, err := OpenWithOptions(context.Background(), mustClient(t, server.URL), "bob", OpenOptions{
+		OnMessage: func(Event) { seen.Add(1) },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(seenAtRequest) != "[0 200]" || seenAtRead != 250 {
+		t.Fatalf("seen at requests=%v, at read=%d", seenAtRequest, seenAtRead)
+	}
+	if len(result.Messages) != 250 || result.MarkedRead != 250 {
+		t.Fatalf("messages=%d marked_read=%d", len(result.Messages), result.MarkedRead)
+	}
+}
+
 func TestShowPending(t *testing.T) {
 	t.Parallel()
 
@@ -1053,7 +1646,7 @@ func TestHistorySupportsStableDIDTargetViaParticipantDIDs(t *testing.T) {
 	})
 	t.Cleanup(server.Close)
 
-	result, err := History(context.Background(), mustClient(t, server.URL), "did:aw:monitor")
+	result, err := History(context.Background(), mustClient(t, server.URL), "did:aw:monitor", HistoryOptions{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -1089,6 +1682,117 @@ func TestSendWithLeaving(t *testing.T) {
 	}
 }
 
+func TestSendRejectsUnknownAliasBeforeCreatingSession(t *testing.T) {
+	t.Parallel()
+
+	server := newMockServer(map[string]http.HandlerFunc{
+		"GET /v1/agents": func(w http.ResponseWriter, _ *http.Request) {
+			jsonResponse(w, awid.ListAgentsResponse{Agents: []awid.AgentView{{Alias: "alice"}, {Alias: "bob"}}})
+		},
+		"POST /v1/chat/sessions": func(w http.ResponseWriter, _ *http.Request) {
+			t.Error("session created for unknown alias")
+			jsonResponse(w, awid.ChatCreateSessionResponse{SessionID: "s1"})
+		},
+	})
+	t.Cleanup(server.Close)
+
+	_, err := Send(context.Background(), mustClient(t, server.URL), "alice", []string{"bobb"}, "hello", SendOptions{Wait: 0}, nil)
+	if !errors.Is(err, awid.ErrUnknownAlias) || !strings.Contains(err.Error(), `"bobb"`) {
+		t.Fatalf("err=%v, want unknown alias error", err)
+	}
+}
+
+func TestSendSkipAliasCheckCreatesSessionDirectly(t *testing.T) {
+	t.Parallel()
+
+	server := newMockServer(map[string]http.HandlerFunc{
+		"GET /v1/agents": func(w http.ResponseWriter, _ *http.Request) {
+			t.Error("roster fetched despite SkipAliasCheck")
+		},
+		"POST /v1/chat/sessions": func(w http.ResponseWriter, _ *http.Request) {
+			jsonResponse(w, awid.ChatCreateSessionResponse{SessionID: "s1", MessageID: "m1"})
+		},
+	})
+	t.Cleanup(server.Close)
+
+	result, err := Send(context.Background(), mustClient(t, server.URL), "alice", []string{"bobb"}, "hello", SendOptions{Wait: 0, SkipAliasCheck: true}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.SessionID != "s1" {
+		t.Fatalf("session_id=%s", result.SessionID)
+	}
+}
+
+func TestSendReuseSessionPicksExactParticipantSet(t *testing.T) {
+	t.Parallel()
+
+	var created atomic.Int32
+	server := newMockServer(map[string]http.HandlerFunc{
+		"GET /v1/chat/sessions": func(w http.ResponseWriter, _ *http.Request) {
+			jsonResponse(w, awid.ChatListSessionsResponse{Sessions: []awid.ChatSessionItem{
+				{SessionID: "bigger", Participants: []string{"alice", "bob", "carol", "dave"}, CreatedAt: "2026-03-03T00:00:00Z"},
+				{SessionID: "older", Participants: []string{"alice", "bob", "carol"}, CreatedAt: "2026-03-01T00:00:00Z"},
+				{SessionID: "newer", Participants: []string{"carol", "alice", "bob"}, CreatedAt: "2026-03-02T00:00:00Z"},
+			}})
+		},
+		"POST /v1/chat/sessions": func(w http.ResponseWriter, _ *http.Request) {
+			created.Add(1)
+			jsonResponse(w, awid.ChatCreateSessionResponse{SessionID: "fresh", MessageID: "m1"})
+		},
+		"POST /v1/chat/sessions/newer/messages": func(w http.ResponseWriter, r *http.Request) {
+			var req awid.ChatSendMessageRequest
+			_ = json.NewDecoder(r.Body).Decode(&req)
+			if req.Body != "again" {
+				t.Errorf("body=%q", req.Body)
+			}
+			jsonResponse(w, awid.ChatSendMessageResponse{MessageID: "m2", Delivered: true})
+		},
+	})
+	t.Cleanup(server.Close)
+	client := mustClient(t, server.URL)
+	opts := SendOptions{SkipAliasCheck: true, ExistingSession: ReuseSession}
+
+	result, err := Send(context.Background(), client, "alice", []string{"bob", "carol"}, "again", opts, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.SessionID != "newer" || created.Load() != 0 {
+		t.Fatalf("session_id=%s created=%d, want the newest exact match reused", result.SessionID, created.Load())
+	}
+
+	result, err = Send(context.Background(), client, "alice", []string{"bob"}, "just bob", opts, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.SessionID != "fresh" || created.Load() != 1 {
+		t.Fatalf("session_id=%s created=%d, want a new session when no exact match exists", result.SessionID, created.Load())
+	}
+}
+
+func TestSendCarriesSubjectAndMetadata(t *testing.T) {
+	t.Parallel()
+
+	var got awid.ChatCreateSessionRequest
+	server := newMockServer(map[string]http.HandlerFunc{
+		"POST /v1/chat/sessions": func(w http.ResponseWriter, r *http.Request) {
+			if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+				t.Error(err)
+			}
+			jsonResponse(w, awid.ChatCreateSessionResponse{SessionID: "s1", MessageID: "m1"})
+		},
+	})
+	t.Cleanup(server.Close)
+
+	opts := SendOptions{Wait: 0, SkipAliasCheck: true, Subject: " Deploy plan ", Metadata: map[string]any{"ticket": "OPS-7"}}
+	if _, err := Send(context.Background(), mustClient(t, server.URL), "alice", []string{"bob"}, "ready?", opts, nil); err != nil {
+		t.Fatal(err)
+	}
+	if got.Subject != "Deploy plan" || got.Metadata["ticket"] != "OPS-7" {
+		t.Fatalf("request=%+v", got)
+	}
+}
+
 func TestSendNoWait(t *testing.T) {
 	t.Parallel()
 
@@ -1215,6 +1919,59 @@ func TestSendWithReply(t *testing.T) {
 	}
 }
 
+func TestSendReconnectsAfterStreamCloseAndReceivesReply(t *testing.T) {
+	t.Parallel()
+
+	sentMsgID := "msg-sent-1"
+	var connections atomic.Int32
+
+	server := newMockServer(map[string]http.HandlerFunc{
+		"POST /v1/chat/sessions": func(w http.ResponseWriter, _ *http.Request) {
+			jsonResponse(w, awid.ChatCreateSessionResponse{
+				SessionID: "s1",
+				MessageID: sentMsgID,
+				SSEURL:    "/v1/chat/sessions/s1/stream",
+			})
+		},
+		"GET /v1/chat/sessions/s1/stream": func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("Content-Type", "text/event-stream")
+			sentData, _ := json.Marshal(map[string]any{
+				"type": "message", "message_id": sentMsgID, "from_agent": "alice", "body": "hello",
+				"timestamp": "2026-03-10T10:00:00Z",
+			})
+			fmt.Fprintf(w, "event: message\ndata: %s\n\n", sentData)
+
+			// The first connection drops right after replaying our message.
+			if connections.Add(1) == 1 {
+				return
+			}
+			replyData, _ := json.Marshal(map[string]any{
+				"type": "message", "message_id": "msg-reply-1", "from_agent": "bob", "body": "hi back!",
+				"timestamp": "2026-03-10T10:00:05Z",
+			})
+			fmt.Fprintf(w, "event: message\ndata: %s\n\n", replyData)
+		},
+	})
+	t.Cleanup(server.Close)
+
+	var kinds []string
+	result, err := Send(context.Background(), mustClient(t, server.URL), "alice", []string{"bob"}, "hello", SendOptions{Wait: 5}, func(kind, _ string) {
+		kinds = append(kinds, kind)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Status != "replied" || result.Reply != "hi back!" {
+		t.Fatalf("status=%s reply=%q", result.Status, result.Reply)
+	}
+	if got := connections.Load(); got != 2 {
+		t.Fatalf("connections=%d, want 2", got)
+	}
+	if len(kinds) != 1 || kinds[0] != "reconnect" {
+		t.Fatalf("callback kinds=%v", kinds)
+	}
+}
+
 func TestSendWithReplySuppressesEphemeralContactTag(t *testing.T) {
 	t.Parallel()
 
@@ -1288,64 +2045,171 @@ func TestSendWithReplySuppressesEphemeralContactTag(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	if result.Status != "replied" {
-		t.Fatalf("status=%s", result.Status)
-	}
-	if result.Reply != "hi back!" {
-		t.Fatalf("reply=%s", result.Reply)
-	}
-	if len(result.Events) != 1 {
-		t.Fatalf("events=%d, want 1", len(result.Events))
+	if result.Status != "replied" {
+		t.Fatalf("status=%s", result.Status)
+	}
+	if result.Reply != "hi back!" {
+		t.Fatalf("reply=%s", result.Reply)
+	}
+	if len(result.Events) != 1 {
+		t.Fatalf("events=%d, want 1", len(result.Events))
+	}
+	if result.Events[0].IsContact != nil {
+		t.Fatalf("ephemeral SSE sender should suppress contact tag, got %v", *result.Events[0].IsContact)
+	}
+}
+
+func TestSendWithTimeout(t *testing.T) {
+	t.Parallel()
+
+	sentMsgID := "msg-sent-1"
+
+	server := newMockServer(map[string]http.HandlerFunc{
+		"POST /v1/chat/sessions": func(w http.ResponseWriter, _ *http.Request) {
+			jsonResponse(w, awid.ChatCreateSessionResponse{
+				SessionID: "s1",
+				MessageID: sentMsgID,
+				SSEURL:    "/v1/chat/sessions/s1/stream",
+			})
+		},
+		"GET /v1/chat/sessions/s1/stream": func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/event-stream")
+			flusher, _ := w.(http.Flusher)
+
+			// Replay our sent message, then hang (no reply)
+			sentData, _ := json.Marshal(map[string]any{
+				"type": "message", "message_id": sentMsgID, "from_agent": "alice", "body": "hello",
+			})
+			fmt.Fprintf(w, "event: message\ndata: %s\n\n", sentData)
+			if flusher != nil {
+				flusher.Flush()
+			}
+
+			// Block until client disconnects
+			<-r.Context().Done()
+		},
+	})
+	t.Cleanup(server.Close)
+
+	// The context deadline is real time; it only has to outlast the fake wait.
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	clock := newFakeClock()
+	go func() {
+		<-clock.timerCreated
+		clock.Advance(60 * time.Second)
+	}()
+
+	result, err := Send(ctx, mustClient(t, server.URL), "alice", []string{"bob"}, "hello", SendOptions{Wait: 60, Clock: clock}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Status != "timeout" {
+		t.Fatalf("status=%s (expected timeout)", result.Status)
 	}
-	if result.Events[0].IsContact != nil {
-		t.Fatalf("ephemeral SSE sender should suppress contact tag, got %v", *result.Events[0].IsContact)
+	if result.WaitedSeconds != 60 {
+		t.Fatalf("waited_seconds=%d", result.WaitedSeconds)
 	}
 }
 
-func TestSendWithTimeout(t *testing.T) {
+func TestSendUrgentWaitsLongerAndNudgesWhileWaiting(t *testing.T) {
 	t.Parallel()
 
 	sentMsgID := "msg-sent-1"
-
+	var created awid.ChatCreateSessionRequest
+	nudged := make(chan struct{}, 4)
 	server := newMockServer(map[string]http.HandlerFunc{
-		"POST /v1/chat/sessions": func(w http.ResponseWriter, _ *http.Request) {
+		"POST /v1/chat/sessions": func(w http.ResponseWriter, r *http.Request) {
+			_ = json.NewDecoder(r.Body).Decode(&created)
 			jsonResponse(w, awid.ChatCreateSessionResponse{
 				SessionID: "s1",
 				MessageID: sentMsgID,
 				SSEURL:    "/v1/chat/sessions/s1/stream",
 			})
 		},
-		"GET /v1/chat/sessions/s1/stream": func(w http.ResponseWriter, _ *http.Request) {
+		"POST /v1/chat/sessions/s1/nudge": func(w http.ResponseWriter, _ *http.Request) {
+			jsonResponse(w, awid.ChatNudgeResponse{SessionID: "s1", Nudged: []string{"bob"}})
+			nudged <- struct{}{}
+		},
+		"GET /v1/chat/sessions/s1/stream": func(w http.ResponseWriter, r *http.Request) {
 			w.Header().Set("Content-Type", "text/event-stream")
-			flusher, _ := w.(http.Flusher)
-
-			// Replay our sent message, then hang (no reply)
 			sentData, _ := json.Marshal(map[string]any{
-				"type": "message", "message_id": sentMsgID, "from_agent": "alice", "body": "hello",
+				"type": "message", "message_id": sentMsgID, "from_agent": "alice", "body": "prod is down", "priority": "urgent",
 			})
 			fmt.Fprintf(w, "event: message\ndata: %s\n\n", sentData)
-			if flusher != nil {
+			if flusher, ok := w.(http.Flusher); ok {
 				flusher.Flush()
 			}
-
-			// Block until client disconnects
-			<-time.After(10 * time.Second)
+			<-r.Context().Done()
 		},
 	})
 	t.Cleanup(server.Close)
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	// The context deadline is real time; it only has to outlast the fake wait.
+	ctx, cancel := context.WithTimeout(context.Background(), MaxSendTimeout)
 	defer cancel()
 
-	result, err := Send(ctx, mustClient(t, server.URL), "alice", []string{"bob"}, "hello", SendOptions{Wait: 1}, nil)
+	// The callback runs on the wait loop's goroutine, so a plain counter is
+	// race-free; reported signals the clock driver once it has run.
+	callbacks := 0
+	reported := make(chan struct{}, 4)
+	clock := newFakeClock()
+	go func() {
+		// One timer for the reply wait, one for the first nudge.
+		<-clock.timerCreated
+		<-clock.timerCreated
+		clock.Advance(MinNudgeInterval)
+		<-nudged
+		<-reported
+		clock.Advance(UrgentPriorityWait*time.Second - MinNudgeInterval)
+	}()
+
+	result, err := Send(ctx, mustClient(t, server.URL), "alice", []string{"bob"}, "prod is down", SendOptions{
+		Wait:     60,
+		Priority: awid.PriorityUrgent,
+		Clock:    clock,
+	}, func(kind, _ string) {
+		if kind == "nudged" {
+			callbacks++
+			reported <- struct{}{}
+		}
+	})
 	if err != nil {
 		t.Fatal(err)
 	}
-	if result.Status != "timeout" {
-		t.Fatalf("status=%s (expected timeout)", result.Status)
+	if created.Priority != awid.PriorityUrgent || created.WaitSeconds == nil || *created.WaitSeconds != UrgentPriorityWait {
+		t.Fatalf("priority=%q wait_seconds=%v, want urgent with the urgent default wait", created.Priority, created.WaitSeconds)
 	}
-	if result.WaitedSeconds < 1 {
-		t.Fatalf("waited_seconds=%d", result.WaitedSeconds)
+	if result.Status != "timeout" || result.WaitedSeconds != UrgentPriorityWait {
+		t.Fatalf("status=%s waited_seconds=%d", result.Status, result.WaitedSeconds)
+	}
+	if callbacks < 1 {
+		t.Fatal("expected a nudged status callback")
+	}
+}
+
+func TestSendNormalPriorityIsOmittedAndKeepsWait(t *testing.T) {
+	t.Parallel()
+
+	var raw map[string]any
+	server := newMockServer(map[string]http.HandlerFunc{
+		"POST /v1/chat/sessions": func(w http.ResponseWriter, r *http.Request) {
+			_ = json.NewDecoder(r.Body).Decode(&raw)
+			jsonResponse(w, awid.ChatCreateSessionResponse{SessionID: "s1", MessageID: "m1"})
+		},
+	})
+	t.Cleanup(server.Close)
+
+	_, err := Send(context.Background(), mustClient(t, server.URL), "alice", []string{"bob"}, "hi", SendOptions{
+		Leaving:  true,
+		Priority: awid.PriorityNormal,
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := raw["priority"]; ok {
+		t.Fatalf("normal priority should not be sent: %v", raw)
 	}
 }
 
@@ -1466,7 +2330,7 @@ func TestSendWithExtendWaitPrefersStableIDOverParticipantDIDInCallbacks(t *testi
 
 			hangOnData, _ := json.Marshal(map[string]any{
 				"type": "message", "message_id": "msg-hangon", "from_did": senderDID, "from_stable_id": stableID,
-				"body": "thinking...", "hang_on": true, "extends_wait_seconds": 300,
+				"body": "thinking...", "hang_on": true, "extends_wait_seconds": 1,
 			})
 			fmt.Fprintf(w, "event: message\ndata: %s\n\n", hangOnData)
 			if flusher != nil {
@@ -2085,6 +2949,69 @@ func TestMaxSendTimeoutIs16Min(t *testing.T) {
 	}
 }
 
+func TestSendRejectsNegativeWait(t *testing.T) {
+	t.Parallel()
+
+	server := newMockServer(map[string]http.HandlerFunc{})
+	t.Cleanup(server.Close)
+
+	_, err := Send(context.Background(), mustClient(t, server.URL), "alice", []string{"bob"}, "hello", SendOptions{Wait: -1}, nil)
+	if err == nil || !strings.Contains(err.Error(), "must not be negative") {
+		t.Fatalf("err=%v", err)
+	}
+}
+
+func TestSendRejectsWaitBeyondContextDeadline(t *testing.T) {
+	t.Parallel()
+
+	server := newMockServer(map[string]http.HandlerFunc{})
+	t.Cleanup(server.Close)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, err := Send(ctx, mustClient(t, server.URL), "alice", []string{"bob"}, "hello", SendOptions{Wait: 60}, nil)
+	if !errors.Is(err, ErrWaitExceedsDeadline) {
+		t.Fatalf("err=%v, want ErrWaitExceedsDeadline", err)
+	}
+}
+
+func TestSendCapsWaitAtMaxWait(t *testing.T) {
+	t.Parallel()
+
+	var gotWait int
+	server := newMockServer(map[string]http.HandlerFunc{
+		"POST /v1/chat/sessions": func(w http.ResponseWriter, r *http.Request) {
+			var req awid.ChatCreateSessionRequest
+			_ = json.NewDecoder(r.Body).Decode(&req)
+			if req.WaitSeconds != nil {
+				gotWait = *req.WaitSeconds
+			}
+			jsonResponse(w, awid.ChatCreateSessionResponse{SessionID: "s1", MessageID: "msg-1"})
+		},
+		"GET /v1/chat/sessions/s1/stream": func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("Content-Type", "text/event-stream")
+		},
+	})
+	t.Cleanup(server.Close)
+
+	var kinds []string
+	result, err := Send(context.Background(), mustClient(t, server.URL), "alice", []string{"bob"}, "hello", SendOptions{Wait: 3600, MaxWait: 1}, func(kind, _ string) {
+		kinds = append(kinds, kind)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotWait != 1 {
+		t.Fatalf("wait_seconds=%d, want 1", gotWait)
+	}
+	if result.Status != "timeout" {
+		t.Fatalf("status=%s", result.Status)
+	}
+	if len(kinds) == 0 || kinds[0] != "wait_capped" {
+		t.Fatalf("callback kinds=%v", kinds)
+	}
+}
+
 func TestFindSessionFallback(t *testing.T) {
 	t.Parallel()
 
@@ -3202,6 +4129,95 @@ func TestStreamToChannelCleansUpWhenBufferFull(t *testing.T) {
 	pw.Close()
 }
 
+// chatGoroutines returns the stacks of live goroutines running code from this
+// module, keyed by goroutine ID. Transport goroutines parked in the idle
+// connection pool are not the package's and are left out.
+func chatGoroutines() map[string]string {
+	buf := make([]byte, 1<<20)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			buf = buf[:n]
+			break
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+	out := map[string]string{}
+	for _, g := range strings.Split(string(buf), "\n\n") {
+		header, _, _ := strings.Cut(g, "\n")
+		if !strings.Contains(g, "github.com/awebai/aw/chat.") && !strings.Contains(g, "github.com/awebai/aw/awid.") {
+			continue
+		}
+		if strings.Contains(g, "chatGoroutines") || strings.Contains(g, "testing.tRunner") {
+			continue
+		}
+		id := strings.Fields(header)[1]
+		out[id] = g
+	}
+	return out
+}
+
+// requireNoLeakedGoroutines fails if goroutines started since before are
+// still running after a grace period for ones that are already returning.
+func requireNoLeakedGoroutines(t *testing.T, before map[string]string) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for {
+		leaked := []string{}
+		for id, stack := range chatGoroutines() {
+			if _, ok := before[id]; !ok {
+				leaked = append(leaked, stack)
+			}
+		}
+		if len(leaked) == 0 {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("%d goroutine(s) outlived the call:\n%s", len(leaked), strings.Join(leaked, "\n\n"))
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// Not parallel: the leak check compares every goroutine in the process.
+func TestSendLeavesNoStreamGoroutineAfterTimeout(t *testing.T) {
+	streamClosed := make(chan struct{})
+	server := newMockServer(map[string]http.HandlerFunc{
+		"POST /v1/chat/sessions": func(w http.ResponseWriter, _ *http.Request) {
+			jsonResponse(w, awid.ChatCreateSessionResponse{SessionID: "s1", MessageID: "msg-sent-1"})
+		},
+		"GET /v1/chat/sessions/s1/stream": func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/event-stream")
+			fmt.Fprint(w, ": keepalive\n\n")
+			if flusher, ok := w.(http.Flusher); ok {
+				flusher.Flush()
+			}
+			<-r.Context().Done()
+			close(streamClosed)
+		},
+	})
+	t.Cleanup(server.Close)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	before := chatGoroutines()
+	result, err := Send(ctx, mustClient(t, server.URL), "alice", []string{"bob"}, "ping", SendOptions{Wait: 1, WaitExplicit: true}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Status != StatusTimeout {
+		t.Fatalf("status=%s", result.Status)
+	}
+	// ctx is still live, so only closing the stream can end the request.
+	select {
+	case <-streamClosed:
+	case <-time.After(2 * time.Second):
+		t.Fatal("server still holds the stream open after Send returned")
+	}
+	requireNoLeakedGoroutines(t, before)
+}
+
 // --- Fix 1: WaitExplicit prevents sentinel upgrade ---
 
 func TestSendStartConversationRespectsExplicitWait(t *testing.T) {
@@ -3242,8 +4258,9 @@ func TestSendStartConversationRespectsExplicitWait(t *testing.T) {
 	t.Cleanup(server.Close)
 
 	// StartConversation=true, Wait=DefaultWait, WaitExplicit=true
-	// Should wait DefaultWait seconds, NOT 300s.
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	// Should wait DefaultWait seconds, NOT 300s: a 300s wait would be
+	// rejected against this deadline.
+	ctx, cancel := context.WithTimeout(context.Background(), (DefaultWait+60)*time.Second)
 	defer cancel()
 
 	result, err := Send(ctx, mustClient(t, server.URL), "alice", []string{"bob"}, "hello", SendOptions{
@@ -3290,8 +4307,8 @@ func TestSendStartConversationUpgradesWhenNotExplicit(t *testing.T) {
 	t.Cleanup(server.Close)
 
 	// Wait=1 with WaitExplicit=false + StartConversation=true should upgrade to 300s.
-	// Without the upgrade the wait timer would fire at 1s and return "sent".
-	// With the upgrade the wait is 300s, so the 2s context expires first.
+	// Without the upgrade the 1s wait fits the 2s context and returns "sent".
+	// With the upgrade the 300s wait cannot fit, so Send rejects it up front.
 	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
 	defer cancel()
 
@@ -3300,8 +4317,8 @@ func TestSendStartConversationUpgradesWhenNotExplicit(t *testing.T) {
 		WaitExplicit:      false,
 		StartConversation: true,
 	}, nil)
-	if err != context.DeadlineExceeded {
-		t.Fatalf("expected context.DeadlineExceeded (proving wait was upgraded past 1s), got %v", err)
+	if !errors.Is(err, ErrWaitExceedsDeadline) || !strings.Contains(err.Error(), "300s wait") {
+		t.Fatalf("expected ErrWaitExceedsDeadline for the upgraded 300s wait, got %v", err)
 	}
 }
 
@@ -3392,6 +4409,72 @@ func TestListenTimeout(t *testing.T) {
 	}
 }
 
+func TestListenMatchesWaitToServerTimeRemaining(t *testing.T) {
+	t.Parallel()
+
+	remaining := 1
+	server := newMockServer(map[string]http.HandlerFunc{
+		"GET /v1/chat/pending": func(w http.ResponseWriter, _ *http.Request) {
+			jsonResponse(w, awid.ChatPendingResponse{
+				Pending: []awid.ChatPendingItem{
+					{SessionID: "s1", Participants: []string{"alice", "bob"}, SenderWaiting: true, TimeRemainingSeconds: &remaining},
+				},
+			})
+		},
+		"GET /v1/chat/sessions/s1/stream": func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/event-stream")
+			fmt.Fprintf(w, ": keepalive\n\n")
+			if flusher, ok := w.(http.Flusher); ok {
+				flusher.Flush()
+			}
+			<-r.Context().Done()
+		},
+	})
+	t.Cleanup(server.Close)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var adjusted []string
+	result, err := Listen(ctx, mustClient(t, server.URL), "bob", 60, func(kind, msg string) {
+		if kind == "wait_adjusted" {
+			adjusted = append(adjusted, msg)
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Status != "timeout" || result.WaitedSeconds > 5 {
+		t.Fatalf("status=%s waited=%ds, want a timeout after the server's 1s", result.Status, result.WaitedSeconds)
+	}
+	if len(adjusted) != 1 || !strings.Contains(adjusted[0], "1s") {
+		t.Fatalf("wait_adjusted callbacks=%q", adjusted)
+	}
+}
+
+func TestPendingStreamWait(t *testing.T) {
+	t.Parallel()
+
+	secs := func(n int) *int { return &n }
+	for _, tc := range []struct {
+		name    string
+		pending *awid.ChatPendingItem
+		want    time.Duration
+	}{
+		{"no pending entry", nil, time.Minute},
+		{"sender not waiting", &awid.ChatPendingItem{TimeRemainingSeconds: secs(30)}, time.Minute},
+		{"no server figure", &awid.ChatPendingItem{SenderWaiting: true}, time.Minute},
+		{"wait already over", &awid.ChatPendingItem{SenderWaiting: true, TimeRemainingSeconds: secs(0)}, time.Minute},
+		{"server shorter", &awid.ChatPendingItem{SenderWaiting: true, TimeRemainingSeconds: secs(30)}, 30 * time.Second},
+		{"server longer", &awid.ChatPendingItem{SenderWaiting: true, TimeRemainingSeconds: secs(300)}, 300 * time.Second},
+		{"capped", &awid.ChatPendingItem{SenderWaiting: true, TimeRemainingSeconds: secs(3600)}, maxStreamDeadline},
+	} {
+		if got := PendingStreamWait(time.Minute, tc.pending); got != tc.want {
+			t.Errorf("%s: got %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
 func TestSendSuppressesContactTagForEphemeralStableDIDSSESender(t *testing.T) {
 	t.Parallel()
 
@@ -3844,6 +4927,7 @@ func TestWaitForMessageTreatsInitialEOFAsTimeout(t *testing.T) {
 
 	result, err := waitForMessage(
 		context.Background(),
+		nil,
 		mustClient(t, server.URL),
 		func(context.Context, string, time.Time, *time.Time) (*awid.SSEStream, error) {
 			return nil, io.EOF
@@ -3854,6 +4938,7 @@ func TestWaitForMessageTreatsInitialEOFAsTimeout(t *testing.T) {
 		1,
 		nil,
 		nil,
+		nil,
 		func(Event) (bool, bool) { return false, false },
 	)
 	if err != nil {
@@ -3876,6 +4961,7 @@ func TestWaitForMessageTreatsWrappedEOFAsTimeout(t *testing.T) {
 
 	result, err := waitForMessage(
 		context.Background(),
+		nil,
 		mustClient(t, server.URL),
 		func(context.Context, string, time.Time, *time.Time) (*awid.SSEStream, error) {
 			return nil, &url.Error{Op: "Get", URL: server.URL + "/v1/chat/sessions/s1/stream", Err: io.EOF}
@@ -3886,6 +4972,7 @@ func TestWaitForMessageTreatsWrappedEOFAsTimeout(t *testing.T) {
 		1,
 		nil,
 		nil,
+		nil,
 		func(Event) (bool, bool) { return false, false },
 	)
 	if err != nil {
@@ -3907,6 +4994,7 @@ func TestWaitForMessagePropagatesContextCancellationOnOpen(t *testing.T) {
 
 	_, err := waitForMessage(
 		ctx,
+		nil,
 		mustClient(t, server.URL),
 		func(context.Context, string, time.Time, *time.Time) (*awid.SSEStream, error) {
 			return nil, context.Canceled
@@ -3917,6 +5005,7 @@ func TestWaitForMessagePropagatesContextCancellationOnOpen(t *testing.T) {
 		1,
 		nil,
 		nil,
+		nil,
 		func(Event) (bool, bool) { return false, false },
 	)
 	if !errors.Is(err, context.Canceled) {
@@ -3932,6 +5021,7 @@ func TestWaitForMessageDoesNotTreatUnexpectedEOFAsTimeout(t *testing.T) {
 
 	_, err := waitForMessage(
 		context.Background(),
+		nil,
 		mustClient(t, server.URL),
 		func(context.Context, string, time.Time, *time.Time) (*awid.SSEStream, error) {
 			return nil, &url.Error{Op: "Get", URL: server.URL + "/v1/chat/sessions/s1/stream", Err: io.ErrUnexpectedEOF}
@@ -3942,6 +5032,7 @@ func TestWaitForMessageDoesNotTreatUnexpectedEOFAsTimeout(t *testing.T) {
 		1,
 		nil,
 		nil,
+		nil,
 		func(Event) (bool, bool) { return false, false },
 	)
 	if err == nil {
@@ -3952,6 +5043,138 @@ func TestWaitForMessageDoesNotTreatUnexpectedEOFAsTimeout(t *testing.T) {
 	}
 }
 
+func TestWaitForMessageReconnectsWhenStreamGoesIdle(t *testing.T) {
+	t.Parallel()
+
+	server := newMockServer(map[string]http.HandlerFunc{})
+	t.Cleanup(server.Close)
+
+	var opens atomic.Int32
+	var replayFrom *time.Time
+	var kinds []string
+	result, err := waitForMessage(
+		context.Background(),
+		nil,
+		mustClient(t, server.URL),
+		func(_ context.Context, _ string, _ time.Time, after *time.Time) (*awid.SSEStream, error) {
+			if opens.Add(1) == 1 {
+				pr, pw := io.Pipe()
+				t.Cleanup(func() { _ = pw.Close() })
+				stream := awid.NewSSEStream(pr)
+				stream.SetIdleTimeout(50 * time.Millisecond)
+				return stream, nil
+			}
+			replayFrom = after
+			return awid.NewSSEStream(io.NopCloser(strings.NewReader(
+				"event: message\ndata: {\"message_id\":\"m1\",\"from_agent\":\"bob\",\"body\":\"hi\"}\n\n",
+			))), nil
+		},
+		"s1",
+		nil,
+		"alice",
+		5,
+		nil,
+		func(kind, _ string) { kinds = append(kinds, kind) },
+		nil,
+		func(Event) (bool, bool) { return true, false },
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Status != "replied" || result.Reply != "hi" {
+		t.Fatalf("status=%s reply=%q", result.Status, result.Reply)
+	}
+	if opens.Load() != 2 {
+		t.Fatalf("opens=%d, want 2", opens.Load())
+	}
+	if replayFrom == nil {
+		t.Fatal("reconnect did not request replay")
+	}
+	if len(kinds) != 1 || kinds[0] != "reconnect" {
+		t.Fatalf("callback kinds=%v", kinds)
+	}
+}
+
+func TestWaitForMessageStopsReconnectingOnPermanentError(t *testing.T) {
+	t.Parallel()
+
+	server := newMockServer(map[string]http.HandlerFunc{})
+	t.Cleanup(server.Close)
+
+	var opens atomic.Int32
+	start := time.Now()
+	_, err := waitForMessage(
+		context.Background(),
+		nil,
+		mustClient(t, server.URL),
+		func(context.Context, string, time.Time, *time.Time) (*awid.SSEStream, error) {
+			if opens.Add(1) == 1 {
+				return awid.NewSSEStream(io.NopCloser(strings.NewReader(""))), nil
+			}
+			return nil, &awid.APIError{StatusCode: http.StatusNotFound, Body: "session not found"}
+		},
+		"s1",
+		nil,
+		"alice",
+		30,
+		nil,
+		nil,
+		nil,
+		func(Event) (bool, bool) { return true, false },
+	)
+	var apiErr *awid.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound {
+		t.Fatalf("err=%v, want the 404", err)
+	}
+	if opens.Load() != 2 {
+		t.Fatalf("opens=%d, want 2", opens.Load())
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("waited %s for a permanent error", elapsed)
+	}
+}
+
+func TestWaitForMessageKeepsBackingOffWhenReconnectsOnlyReplay(t *testing.T) {
+	t.Parallel()
+
+	server := newMockServer(map[string]http.HandlerFunc{})
+	t.Cleanup(server.Close)
+
+	// Every stream replays the same message and drops, so no reconnect ever
+	// delivers anything new and the backoff must keep growing.
+	var opens atomic.Int32
+	result, err := waitForMessage(
+		context.Background(),
+		nil,
+		mustClient(t, server.URL),
+		func(context.Context, string, time.Time, *time.Time) (*awid.SSEStream, error) {
+			opens.Add(1)
+			return awid.NewSSEStream(io.NopCloser(strings.NewReader(
+				"event: message\ndata: {\"message_id\":\"m1\",\"from_agent\":\"bob\",\"body\":\"hi\",\"timestamp\":\"2026-01-01T00:00:00Z\"}\n\n",
+			))), nil
+		},
+		"s1",
+		nil,
+		"alice",
+		2,
+		nil,
+		nil,
+		nil,
+		func(Event) (bool, bool) { return false, false },
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Status != "timeout" || len(result.Events) != 1 {
+		t.Fatalf("status=%s events=%d, want a timeout with the message once", result.Status, len(result.Events))
+	}
+	// Backoff from 250ms fits at most a handful of reopens into 2s; resetting
+	// it on every replayed duplicate reopens in a tight loop.
+	if got := opens.Load(); got > 6 {
+		t.Fatalf("opens=%d, want the reconnect delay to keep growing", got)
+	}
+}
+
 func TestListenNoSession(t *testing.T) {
 	t.Parallel()
 
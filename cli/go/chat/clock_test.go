@@ -0,0 +1,178 @@
+package chat
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/awebai/aw/awid"
+)
+
+// fakeClock only moves when a test calls Set or Advance. Advance fires the
+// timers it passes; Set does not, which models a deadline that has arrived
+// before the wait loop sees its timer fire.
+type fakeClock struct {
+	mu           sync.Mutex
+	now          time.Time
+	timers       []*fakeTimer
+	timerCreated chan struct{}
+}
+
+type fakeTimer struct {
+	clock    *fakeClock
+	c        chan time.Time
+	deadline time.Time
+	active   bool
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{
+		now:          time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+		timerCreated: make(chan struct{}, 16),
+	}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) NewTimer(d time.Duration) Timer {
+	c.mu.Lock()
+	t := &fakeTimer{clock: c, c: make(chan time.Time, 1), deadline: c.now.Add(d), active: true}
+	c.timers = append(c.timers, t)
+	c.fireLocked()
+	c.mu.Unlock()
+	select {
+	case c.timerCreated <- struct{}{}:
+	default:
+	}
+	return t
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	return c.NewTimer(d).C()
+}
+
+func (c *fakeClock) Set(now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	c.fireLocked()
+}
+
+// activeTimers counts timers that have neither fired nor been stopped.
+func (c *fakeClock) activeTimers() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	n := 0
+	for _, t := range c.timers {
+		if t.active {
+			n++
+		}
+	}
+	return n
+}
+
+func (c *fakeClock) fireLocked() {
+	for _, t := range c.timers {
+		if t.active && !t.deadline.After(c.now) {
+			t.active = false
+			select {
+			case t.c <- c.now:
+			default:
+			}
+		}
+	}
+}
+
+func (t *fakeTimer) C() <-chan time.Time { return t.c }
+
+func (t *fakeTimer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	was := t.active
+	t.active = false
+	return was
+}
+
+func (t *fakeTimer) Reset(d time.Duration) bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	was := t.active
+	t.deadline = t.clock.now.Add(d)
+	t.active = true
+	t.clock.fireLocked()
+	return was
+}
+
+func TestWaitForMessageExtensionAtDeadlineExtendsWait(t *testing.T) {
+	t.Parallel()
+
+	server := newMockServer(map[string]http.HandlerFunc{})
+	t.Cleanup(server.Close)
+	client := mustClient(t, server.URL)
+
+	clock := newFakeClock()
+	start := clock.Now()
+	pr, pw := io.Pipe()
+	t.Cleanup(func() { _ = pw.Close() })
+
+	extended := make(chan struct{}, 1)
+	done := make(chan struct{})
+	var result *SendResult
+	var err error
+	go func() {
+		defer close(done)
+		result, err = waitForMessage(
+			context.Background(),
+			clock,
+			client,
+			func(context.Context, string, time.Time, *time.Time) (*awid.SSEStream, error) {
+				return awid.NewSSEStream(pr), nil
+			},
+			"s1",
+			nil,
+			"alice",
+			10,
+			nil,
+			func(kind, _ string) {
+				if kind == "wait_extended" {
+					extended <- struct{}{}
+				}
+			},
+			nil,
+			func(Event) (bool, bool) { return true, false },
+		)
+	}()
+
+	<-clock.timerCreated
+	clock.Set(start.Add(10 * time.Second))
+	_, _ = fmt.Fprint(pw, "event: message\ndata: {\"message_id\":\"m1\",\"from_agent\":\"bob\",\"body\":\"thinking\",\"hang_on\":true,\"extends_wait_seconds\":60}\n\n")
+	<-extended
+
+	clock.Advance(59 * time.Second)
+	if got := clock.activeTimers(); got != 1 {
+		t.Fatalf("wait ended before the extension ran out: active timers=%d", got)
+	}
+	clock.Advance(time.Second)
+	<-done
+
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Status != "timeout" || result.WaitedSeconds != 70 {
+		t.Fatalf("status=%s waited_seconds=%d, want timeout after 70s", result.Status, result.WaitedSeconds)
+	}
+}
@@ -0,0 +1,135 @@
+package chat
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/awebai/aw/awid"
+)
+
+// FollowOptions configures Follow.
+type FollowOptions struct {
+	NoMarkRead bool // Leave followed messages unread
+}
+
+// Follow streams new messages in the conversation with targetAlias to
+// onMessage until ctx is done, like tail -f. Streams are opened with a
+// rolling deadline and reopened (replaying from the last message seen)
+// whenever the server closes them or they go idle, so a long watch does not
+// drop. Each delivered message is marked read unless opts.NoMarkRead is set.
+//
+// Follow returns nil when ctx is cancelled. It returns early on session
+// lookup errors and when the server refuses a stream for good, such as for a
+// revoked certificate or a deleted session.
+func Follow(ctx context.Context, client *awid.Client, targetAlias string, opts FollowOptions, callback StatusCallback, onMessage func(Event)) error {
+	sessionID, _, err := findSession(ctx, client, targetAlias)
+	if err != nil {
+		return err
+	}
+	return follow(ctx, client, client.ChatStream, sessionID, opts, callback, onMessage)
+}
+
+// followSeenLimit bounds how many recent message IDs follow remembers to
+// drop replayed duplicates. Replays start at the newest message seen, so only
+// the last few IDs can ever repeat.
+const followSeenLimit = 1024
+
+func follow(ctx context.Context, client *awid.Client, openStream streamOpener, sessionID string, opts FollowOptions, callback StatusCallback, onMessage func(Event)) error {
+	seen := newRecentIDs(followSeenLimit)
+	// replayFrom is nil for the first stream, which only carries messages
+	// from now on. Later streams replay from the newest message seen (by
+	// server time), or from when following began if none arrived yet.
+	var replayFrom, lastMessageAt *time.Time
+	var delay time.Duration
+	connected := false
+
+	for ctx.Err() == nil {
+		if delay > 0 {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-time.After(delay):
+			}
+		}
+		delay = nextReconnectDelay(delay)
+
+		openedAt := time.Now()
+		stream, err := openStream(ctx, sessionID, openedAt.Add(maxStreamDeadline), replayFrom)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			if !retryableStreamError(err) {
+				return fmt.Errorf("opening chat stream: %w", err)
+			}
+			if callback != nil {
+				callback("reconnect", fmt.Sprintf("chat stream failed to open: %v", err))
+			}
+			continue
+		}
+		if connected && callback != nil {
+			callback("reconnect", "chat stream reconnected")
+		}
+		connected = true
+		if replayFrom == nil {
+			replayFrom = &openedAt
+		}
+
+		for {
+			ev, err := stream.Next()
+			if err != nil {
+				break
+			}
+			delay = 0
+			reportUnexpectedEvent(client, callback, ev)
+			chatEvent := parseSSEEvent(ev)
+			if chatEvent.Type != "message" {
+				continue
+			}
+			if chatEvent.MessageID != "" && !seen.add(chatEvent.MessageID) {
+				continue
+			}
+			if ts, err := chatEvent.Time(); err == nil && (lastMessageAt == nil || ts.After(*lastMessageAt)) {
+				lastMessageAt = &ts
+				replayFrom = lastMessageAt
+			}
+			tofuFrom := chatEventTrustAddress(chatEvent, nil)
+			chatEvent.VerificationStatus, chatEvent.IsContact = client.NormalizeSenderTrust(ctx, chatEvent.VerificationStatus, tofuFrom, chatEvent.FromDID, chatEvent.FromStableID, chatEvent.RotationAnnouncement, chatEvent.ReplacementAnnouncement, chatEvent.IsContact)
+			chatEvent.VerificationStatus = client.NormalizeRecipientBinding(chatEvent.VerificationStatus, chatEvent.ToDID, chatEvent.ToStableID)
+
+			onMessage(chatEvent)
+			if !opts.NoMarkRead {
+				_ = markReadBestEffort(ctx, client, sessionID, chatEvent.MessageID)
+			}
+		}
+		_ = stream.Close()
+	}
+	return nil
+}
+
+// recentIDs is a set of the most recently added IDs, evicting the oldest
+// once it holds max of them.
+type recentIDs struct {
+	max   int
+	order []string
+	set   map[string]struct{}
+}
+
+func newRecentIDs(max int) *recentIDs {
+	return &recentIDs{max: max, set: make(map[string]struct{}, max)}
+}
+
+// add records id and reports whether it was new.
+func (r *recentIDs) add(id string) bool {
+	if _, ok := r.set[id]; ok {
+		return false
+	}
+	if len(r.order) >= r.max {
+		delete(r.set, r.order[0])
+		r.order = r.order[1:]
+	}
+	r.order = append(r.order, id)
+	r.set[id] = struct{}{}
+	return true
+}
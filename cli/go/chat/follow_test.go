@@ -0,0 +1,206 @@
+package chat
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/awebai/aw/awid"
+)
+
+func TestFollowReconnectsDedupsAndMarksRead(t *testing.T) {
+	t.Parallel()
+
+	var markedRead atomic.Int32
+	server := newMockServer(map[string]http.HandlerFunc{
+		"POST /v1/chat/sessions/s1/read": func(w http.ResponseWriter, _ *http.Request) {
+			markedRead.Add(1)
+			jsonResponse(w, awid.ChatMarkReadResponse{})
+		},
+	})
+	t.Cleanup(server.Close)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	m1 := "event: message\ndata: {\"message_id\":\"m1\",\"from_agent\":\"bob\",\"body\":\"one\",\"timestamp\":\"2026-03-10T10:00:00Z\"}\n\n"
+	m2 := "event: message\ndata: {\"message_id\":\"m2\",\"from_agent\":\"bob\",\"body\":\"two\",\"timestamp\":\"2026-03-10T10:00:05Z\"}\n\n"
+	var opens atomic.Int32
+	var replayFrom *time.Time
+	openStream := func(_ context.Context, _ string, _ time.Time, after *time.Time) (*awid.SSEStream, error) {
+		if opens.Add(1) == 1 {
+			// The server closes the first stream right after one message.
+			return awid.NewSSEStream(io.NopCloser(strings.NewReader(m1))), nil
+		}
+		replayFrom = after
+		pr, pw := io.Pipe()
+		go func() {
+			_, _ = io.WriteString(pw, m1+m2)
+			<-ctx.Done()
+			_ = pw.Close()
+		}()
+		return awid.NewSSEStream(pr), nil
+	}
+
+	var got []string
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		follow(ctx, mustClient(t, server.URL), openStream, "s1", FollowOptions{}, nil, func(ev Event) {
+			got = append(got, ev.MessageID)
+			if len(got) == 2 {
+				cancel()
+			}
+		})
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("follow did not stop after cancel")
+	}
+	if strings.Join(got, ",") != "m1,m2" {
+		t.Fatalf("messages=%v, want m1,m2 once each", got)
+	}
+	if replayFrom == nil || !replayFrom.Equal(time.Date(2026, 3, 10, 10, 0, 0, 0, time.UTC)) {
+		t.Fatalf("reconnect replayed from %v, want the last message time", replayFrom)
+	}
+	if markedRead.Load() < 1 {
+		t.Fatal("expected followed messages to be marked read")
+	}
+}
+
+func TestFollowStopsOnPermanentOpenError(t *testing.T) {
+	t.Parallel()
+
+	server := newMockServer(map[string]http.HandlerFunc{})
+	t.Cleanup(server.Close)
+
+	var opens atomic.Int32
+	openStream := func(context.Context, string, time.Time, *time.Time) (*awid.SSEStream, error) {
+		opens.Add(1)
+		return nil, &awid.APIError{StatusCode: http.StatusForbidden, Body: "certificate revoked"}
+	}
+	var kinds []string
+	err := follow(context.Background(), mustClient(t, server.URL), openStream, "s1", FollowOptions{}, func(kind, _ string) {
+		kinds = append(kinds, kind)
+	}, func(Event) {})
+	var apiErr *awid.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusForbidden {
+		t.Fatalf("err=%v, want the 403", err)
+	}
+	if opens.Load() != 1 || len(kinds) != 0 {
+		t.Fatalf("opens=%d callbacks=%v, want one open and no reconnects", opens.Load(), kinds)
+	}
+}
+
+func TestRecentIDsEvictsOldest(t *testing.T) {
+	t.Parallel()
+
+	seen := newRecentIDs(2)
+	for _, id := range []string{"m1", "m2", "m3"} {
+		if !seen.add(id) {
+			t.Fatalf("%s reported as seen", id)
+		}
+	}
+	if seen.add("m3") || seen.add("m2") {
+		t.Fatal("recent IDs were not remembered")
+	}
+	if !seen.add("m1") {
+		t.Fatal("oldest ID was not evicted")
+	}
+	if len(seen.set) != 2 || len(seen.order) != 2 {
+		t.Fatalf("set=%d order=%d, want 2", len(seen.set), len(seen.order))
+	}
+}
+
+func TestFollowNoMarkReadLeavesMessagesUnread(t *testing.T) {
+	t.Parallel()
+
+	server := newMockServer(map[string]http.HandlerFunc{
+		"POST /v1/chat/sessions/s1/read": func(http.ResponseWriter, *http.Request) {
+			t.Error("unexpected mark-read with NoMarkRead")
+		},
+	})
+	t.Cleanup(server.Close)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	openStream := func(context.Context, string, time.Time, *time.Time) (*awid.SSEStream, error) {
+		return awid.NewSSEStream(io.NopCloser(strings.NewReader(
+			"event: message\ndata: {\"message_id\":\"m1\",\"from_agent\":\"bob\",\"body\":\"one\"}\n\n",
+		))), nil
+	}
+
+	follow(ctx, mustClient(t, server.URL), openStream, "s1", FollowOptions{NoMarkRead: true}, nil, func(Event) {
+		cancel()
+	})
+}
+
+func TestFollowReportsUnexpectedEventsWithStrictDecoding(t *testing.T) {
+	t.Parallel()
+
+	server := newMockServer(nil)
+	t.Cleanup(server.Close)
+	client := mustClient(t, server.URL)
+	client.SetStrictDecoding(true)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	openStream := func(context.Context, string, time.Time, *time.Time) (*awid.SSEStream, error) {
+		return awid.NewSSEStream(io.NopCloser(strings.NewReader(
+			"event: typing\ndata: {\"agent\":\"bob\"}\n\n" +
+				"event: message\ndata: {\"message_id\":\"m1\",\"from_agent\":\"bob\",\"body\":\"one\"}\n\n",
+		))), nil
+	}
+
+	var statuses []string
+	follow(ctx, client, openStream, "s1", FollowOptions{NoMarkRead: true}, func(kind, msg string) {
+		statuses = append(statuses, kind+": "+msg)
+	}, func(Event) {
+		cancel()
+	})
+	if len(statuses) != 1 || statuses[0] != `unexpected_event: unexpected chat event type "typing"` {
+		t.Fatalf("statuses=%v", statuses)
+	}
+}
+
+func TestFollowReportsUndecodableEventData(t *testing.T) {
+	t.Parallel()
+
+	server := newMockServer(nil)
+	t.Cleanup(server.Close)
+	client := mustClient(t, server.URL)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	openStream := func(context.Context, string, time.Time, *time.Time) (*awid.SSEStream, error) {
+		return awid.NewSSEStream(io.NopCloser(strings.NewReader(
+			"event: message\ndata: {\"message_id\":\"m0\",\n\n" +
+				"event: message\ndata: {\"message_id\":\"m1\",\"from_agent\":\"bob\",\"body\":\"one\"}\n\n",
+		))), nil
+	}
+
+	var statuses []string
+	var delivered bool
+	follow(ctx, client, openStream, "s1", FollowOptions{NoMarkRead: true}, func(kind, msg string) {
+		statuses = append(statuses, kind+": "+msg)
+	}, func(ev Event) {
+		if ev.MessageID == "m1" {
+			delivered = true
+			cancel()
+		}
+	})
+	want := `parse_error: could not decode chat message event data: "{\"message_id\":\"m0\","`
+	if len(statuses) != 1 || statuses[0] != want {
+		t.Fatalf("statuses=%v", statuses)
+	}
+	if !delivered {
+		t.Fatal("the valid message after the bad one was not delivered")
+	}
+}
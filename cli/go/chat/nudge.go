@@ -0,0 +1,76 @@
+package chat
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/awebai/aw/awid"
+)
+
+const (
+	NudgesFileName = "chat-nudges.json"
+	// MinNudgeInterval is how long Nudge refuses to nudge the same
+	// conversation again, so a waiting agent cannot spam the other side.
+	MinNudgeInterval = time.Minute
+)
+
+// ErrNudgeTooSoon is wrapped by Nudge when the conversation was nudged less
+// than MinNudgeInterval ago.
+var ErrNudgeTooSoon = errors.New("chat: nudged too recently")
+
+// nudgesPath keeps the last nudge per session next to the delivered-ID file,
+// in the same session ID -> timestamp format.
+func nudgesPath(startDir string) string {
+	return filepath.Join(filepath.Dir(deliveredIDsPath(startDir)), NudgesFileName)
+}
+
+// Nudge notifies again the participants of the conversation with targetAlias
+// who have not read its latest message. Nudges are rate limited per
+// conversation on this machine; a second nudge within MinNudgeInterval fails
+// with ErrNudgeTooSoon without contacting the server.
+func Nudge(ctx context.Context, client *awid.Client, targetAlias string) (*NudgeResult, error) {
+	sessionID, _, err := findSession(ctx, client, targetAlias)
+	if err != nil {
+		return nil, err
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	path := nudgesPath(wd)
+	now := time.Now()
+	last, err := loadDeliveredIDTimes(path, now)
+	if err != nil {
+		return nil, fmt.Errorf("reading nudge history: %w", err)
+	}
+	if at, ok := last[sessionID]; ok {
+		if wait := at.Add(MinNudgeInterval).Sub(now); wait > 0 {
+			return nil, fmt.Errorf("%w: conversation with %s was nudged %s ago; try again in %s",
+				ErrNudgeTooSoon, targetAlias, now.Sub(at).Round(time.Second), wait.Round(time.Second))
+		}
+	}
+
+	resp, err := client.ChatNudge(ctx, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("nudging conversation: %w", err)
+	}
+
+	// The nudge went out; failing to record it only loosens the rate limit.
+	last[sessionID] = now
+	_ = writeDeliveredIDs(path, last)
+
+	nudged := resp.Nudged
+	if nudged == nil {
+		nudged = []string{}
+	}
+	return &NudgeResult{
+		SessionID:   sessionID,
+		TargetAgent: targetAlias,
+		Nudged:      nudged,
+	}, nil
+}
@@ -0,0 +1,104 @@
+package chat
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/awebai/aw/awid"
+)
+
+// FormatOptions configures FormatTranscript.
+type FormatOptions struct {
+	RelativeTimes bool      // "5m ago" instead of absolute UTC timestamps
+	AlignAuthors  bool      // Pad authors to the widest one so bodies line up
+	Now           time.Time // Reference for relative timestamps; zero means time.Now
+}
+
+// FormatTranscript renders a conversation as plain text for pasting into a
+// ticket or a prompt: one "[timestamp] alias: body" entry per message, with
+// the continuation lines of multi-line bodies indented under the first.
+// Nothing is escaped; the output is for humans, not for parsing back.
+func FormatTranscript(result *HistoryResult, opts FormatOptions) string {
+	if result == nil || len(result.Messages) == 0 {
+		return ""
+	}
+	now := opts.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+
+	authors := make([]string, len(result.Messages))
+	width := 0
+	for i, m := range result.Messages {
+		authors[i] = transcriptAuthor(m)
+		if n := len([]rune(authors[i])); n > width {
+			width = n
+		}
+	}
+
+	var sb strings.Builder
+	for i, m := range result.Messages {
+		prefix := ""
+		if ts := transcriptTimestamp(m.Timestamp, opts.RelativeTimes, now); ts != "" {
+			prefix = "[" + ts + "] "
+		}
+		author := authors[i] + ":"
+		if opts.AlignAuthors {
+			author += strings.Repeat(" ", width-len([]rune(authors[i])))
+		}
+		prefix += author + " "
+
+		lines := strings.Split(strings.TrimRight(m.Body, "\n"), "\n")
+		sb.WriteString(prefix + lines[0] + "\n")
+		indent := strings.Repeat(" ", len([]rune(prefix)))
+		for _, line := range lines[1:] {
+			if line == "" {
+				sb.WriteString("\n")
+				continue
+			}
+			sb.WriteString(indent + line + "\n")
+		}
+	}
+	return sb.String()
+}
+
+func transcriptAuthor(m Event) string {
+	if alias := strings.TrimSpace(m.FromAgent); alias != "" {
+		return alias
+	}
+	if label := preferredChatIdentityLabel("", m.FromAddress, m.FromStableID, m.FromDID); label != "" {
+		return label
+	}
+	return "unknown"
+}
+
+func transcriptTimestamp(value string, relative bool, now time.Time) string {
+	ts, err := awid.ParseTimestamp(value)
+	if err != nil {
+		return strings.TrimSpace(value)
+	}
+	if !relative {
+		return ts.UTC().Format(time.RFC3339)
+	}
+	return formatAgo(now.Sub(ts))
+}
+
+func formatAgo(d time.Duration) string {
+	if d < 0 {
+		d = 0
+	}
+	secs := int(d.Seconds())
+	if secs < 60 {
+		return fmt.Sprintf("%ds ago", secs)
+	}
+	mins := secs / 60
+	if mins < 60 {
+		return fmt.Sprintf("%dm ago", mins)
+	}
+	hours := mins / 60
+	if hours < 48 {
+		return fmt.Sprintf("%dh ago", hours)
+	}
+	return fmt.Sprintf("%dd ago", hours/24)
+}
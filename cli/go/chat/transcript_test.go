@@ -0,0 +1,51 @@
+package chat
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFormatTranscriptAlignsAuthorsAndIndentsMultilineBodies(t *testing.T) {
+	t.Parallel()
+
+	result := &HistoryResult{
+		SessionID: "sess-1",
+		Messages: []Event{
+			{FromAgent: "bob", Body: "can you check the <deploy> & logs?", Timestamp: "2026-03-01T12:00:00Z"},
+			{FromAgent: "alice", Body: "found it:\n  stack trace here\n\ndone\n", Timestamp: "2026-03-01T12:05:30Z"},
+			{FromAddress: "acme.com/carol", Body: "thanks", Timestamp: "not-a-time"},
+		},
+	}
+
+	got := FormatTranscript(result, FormatOptions{AlignAuthors: true})
+	want := "" +
+		"[2026-03-01T12:00:00Z] bob:            can you check the <deploy> & logs?\n" +
+		"[2026-03-01T12:05:30Z] alice:          found it:\n" +
+		"                                         stack trace here\n" +
+		"\n" +
+		"                                       done\n" +
+		"[not-a-time] acme.com/carol: thanks\n"
+	if got != want {
+		t.Fatalf("transcript mismatch\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestFormatTranscriptRelativeTimes(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 3, 1, 15, 0, 0, 0, time.UTC)
+	result := &HistoryResult{Messages: []Event{
+		{FromAgent: "bob", Body: "hi", Timestamp: "2026-03-01T14:55:00Z"},
+		{FromAgent: "alice", Body: "hello", Timestamp: "2026-02-25T15:00:00Z"},
+		{FromAgent: "bob", Body: "no time"},
+	}}
+
+	got := FormatTranscript(result, FormatOptions{RelativeTimes: true, Now: now})
+	want := "[5m ago] bob: hi\n[4d ago] alice: hello\nbob: no time\n"
+	if got != want {
+		t.Fatalf("transcript mismatch\ngot:\n%s\nwant:\n%s", got, want)
+	}
+	if got := FormatTranscript(&HistoryResult{}, FormatOptions{}); got != "" {
+		t.Fatalf("empty history rendered %q", got)
+	}
+}
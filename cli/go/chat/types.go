@@ -3,7 +3,11 @@
 
 package chat
 
-import awid "github.com/awebai/aw/awid"
+import (
+	"time"
+
+	awid "github.com/awebai/aw/awid"
+)
 
 // Event represents an event received during chat (message or read receipt).
 type Event struct {
@@ -36,19 +40,46 @@ type Event struct {
 	ReplacementAnnouncement *awid.ReplacementAnnouncement `json:"replacement_announcement,omitempty"`
 	VerificationStatus     awid.VerificationStatus    `json:"verification_status,omitempty"`
 	IsContact              *bool                      `json:"is_contact,omitempty"`
+
+	// Priority is the sender's urgency hint, normal unless set; empty from
+	// servers without priority support.
+	Priority awid.MessagePriority `json:"priority,omitempty"`
+
+	// Matches locates the search query in Body; set by Search only.
+	Matches []awid.TextMatch `json:"matches,omitempty"`
 }
 
+// Time parses Timestamp.
+func (e Event) Time() (time.Time, error) { return awid.ParseTimestamp(e.Timestamp) }
+
+// SendStatus is the outcome reported in SendResult.Status. The values are
+// the JSON wire strings.
+type SendStatus string
+
+const (
+	StatusSent        SendStatus = "sent"         // delivered, no wait requested or leaving
+	StatusReplied     SendStatus = "replied"      // a target replied within the wait
+	StatusSenderLeft  SendStatus = "sender_left"  // a target replied and left the exchange
+	StatusPending     SendStatus = "pending"      // ShowPending found an unread message
+	StatusTargetsLeft SendStatus = "targets_left" // a target had already left; no wait
+	StatusTimeout     SendStatus = "timeout"      // the wait ended without a reply
+	// StatusTargetOffline is reserved for a send whose targets are all
+	// offline. Send currently reports that as StatusSent or StatusTimeout
+	// with TargetNotConnected set.
+	StatusTargetOffline SendStatus = "target_offline"
+)
+
 // SendResult is the result of sending a message and optionally waiting for a reply.
 type SendResult struct {
-	SessionID          string  `json:"session_id"`
-	Status             string  `json:"status"` // sent, replied, sender_left, pending, targets_left, timeout
-	TargetAgent        string  `json:"target_agent,omitempty"`
-	Reply              string  `json:"reply,omitempty"`
-	Events             []Event `json:"events"`
-	Error              string  `json:"error,omitempty"`
-	TargetNotConnected bool    `json:"target_not_connected,omitempty"`
-	SenderWaiting      bool    `json:"sender_waiting,omitempty"`
-	WaitedSeconds      int     `json:"waited_seconds,omitempty"`
+	SessionID          string     `json:"session_id"`
+	Status             SendStatus `json:"status"`
+	TargetAgent        string     `json:"target_agent,omitempty"`
+	Reply              string     `json:"reply,omitempty"`
+	Events             []Event    `json:"events"`
+	Error              string     `json:"error,omitempty"`
+	TargetNotConnected bool       `json:"target_not_connected,omitempty"`
+	SenderWaiting      bool       `json:"sender_waiting,omitempty"`
+	WaitedSeconds      int        `json:"waited_seconds,omitempty"`
 }
 
 // OpenResult is the result of opening unread messages for a conversation.
@@ -59,6 +90,14 @@ type OpenResult struct {
 	MarkedRead     int     `json:"marked_read"`
 	SenderWaiting  bool    `json:"sender_waiting"`
 	UnreadWasEmpty bool    `json:"unread_was_empty,omitempty"`
+	Error          string  `json:"error,omitempty"` // set by OpenAll when this session failed to open
+
+	// SenderStillWaiting and TimeRemainingSeconds are re-read after the
+	// messages were marked read: whether the sender is still blocked on a
+	// reply, and how long their wait has left. If that lookup fails,
+	// SenderStillWaiting repeats SenderWaiting and the time is unknown (0).
+	SenderStillWaiting   bool `json:"sender_still_waiting"`
+	TimeRemainingSeconds int  `json:"time_remaining_seconds,omitempty"`
 }
 
 // HistoryResult is the result of fetching chat history.
@@ -67,6 +106,35 @@ type HistoryResult struct {
 	Messages  []Event `json:"messages"`
 }
 
+// SearchResult is the result of searching a conversation. Messages are
+// oldest first.
+type SearchResult struct {
+	SessionID string  `json:"session_id"`
+	Query     string  `json:"query"`
+	Messages  []Event `json:"messages"`
+}
+
+// ListResult is the result of listing every conversation, newest activity first.
+type ListResult struct {
+	Conversations []ConversationSummary `json:"conversations"`
+}
+
+// ConversationSummary describes one conversation in a ListResult. Activity
+// fields are empty when the server does not report them and the
+// conversation has nothing pending.
+type ConversationSummary struct {
+	SessionID     string   `json:"session_id"`
+	TargetAgent   string   `json:"target_agent"`
+	Participants  []string `json:"participants"`
+	LastActivity  string   `json:"last_activity,omitempty"`
+	LastMessage   string   `json:"last_message,omitempty"`
+	LastFrom      string   `json:"last_from,omitempty"`
+	UnreadCount   int      `json:"unread_count"`
+	SenderWaiting bool     `json:"sender_waiting,omitempty"`
+	CreatedAt     string   `json:"created_at"`
+	Subject       string   `json:"subject,omitempty"`
+}
+
 // PendingResult is the result of checking pending conversations.
 type PendingResult struct {
 	Pending         []PendingConversation `json:"pending"`
@@ -88,6 +156,9 @@ type PendingConversation struct {
 	LastActivity         string   `json:"last_activity"`
 	SenderWaiting        bool     `json:"sender_waiting"`
 	TimeRemainingSeconds *int     `json:"time_remaining_seconds"`
+
+	Subject  string         `json:"subject,omitempty"`
+	Metadata map[string]any `json:"metadata,omitempty"`
 }
 
 // ExtendWaitResult is the result of an extend-wait acknowledgment.
@@ -98,14 +169,94 @@ type ExtendWaitResult struct {
 	ExtendsWaitSeconds int    `json:"extends_wait_seconds"`
 }
 
+// LeaveResult is the result of leaving a conversation.
+type LeaveResult struct {
+	SessionID     string `json:"session_id"`
+	TargetAgent   string `json:"target_agent"`
+	SessionClosed bool   `json:"session_closed"`
+}
+
+// NudgeResult is the result of nudging a conversation.
+type NudgeResult struct {
+	SessionID   string   `json:"session_id"`
+	TargetAgent string   `json:"target_agent"`
+	Nudged      []string `json:"nudged"`
+}
+
+// MarkUnreadResult is the result of marking a conversation unread.
+type MarkUnreadResult struct {
+	SessionID     string `json:"session_id"`
+	TargetAgent   string `json:"target_agent"`
+	FromMessageID string `json:"from_message_id"`
+	UnreadCount   int    `json:"unread_count"`
+}
+
+// HistoryOptions narrows History to messages newer than a point in the
+// conversation. Zero values fetch the whole transcript.
+type HistoryOptions struct {
+	Since          time.Time // only messages created after this instant
+	AfterMessageID string    // only messages created after this message
+
+	// OnMessage, when set, receives each message as its page arrives.
+	OnMessage MessageCallback
+}
+
+// OpenOptions configures OpenWithOptions.
+type OpenOptions struct {
+	// OnMessage, when set, receives each unread message as its page
+	// arrives, before the messages are marked read.
+	OnMessage MessageCallback
+}
+
 // SendOptions configures message sending behavior.
 type SendOptions struct {
 	Wait              int  // Seconds to wait for reply (0 = no wait)
 	WaitExplicit      bool // true if caller explicitly set Wait
 	Leaving           bool // Sender is leaving the conversation
 	StartConversation bool // Ignore targets_left, use 5min default wait
+	MaxWait           int  // Cap on Wait in seconds (0 = MaxWait)
+	SkipAliasCheck    bool // Don't confirm alias targets exist before sending
+
+	Subject  string         // Conversation label shown in pending listings
+	Metadata map[string]any // Free-form context attached to the conversation
+
+	// Priority marks the message high or urgent for the recipients. Those
+	// sends wait longer by default (HighPriorityWait, UrgentPriorityWait)
+	// and nudge unread recipients while waiting. Empty means normal.
+	Priority awid.MessagePriority
+
+	Clock Clock // Time source for the reply wait (nil = real clock)
+
+	ExistingSession ExistingSessionPolicy // What to do when the conversation already exists
 }
 
+// ExistingSessionPolicy decides what Send does when a session with exactly
+// the sender and the targets as participants already exists.
+type ExistingSessionPolicy int
+
+const (
+	// CreateSession always asks the server for a session. The server reuses
+	// a 1:1 session between the same two identities, but every group send
+	// starts a new session.
+	CreateSession ExistingSessionPolicy = iota
+	// ReuseSession sends into the most recent session with exactly those
+	// participants, and creates one only when there is none. Subject and
+	// Metadata are not applied to a reused session.
+	ReuseSession
+)
+
 // StatusCallback receives protocol status updates.
-// kind is one of: "read_receipt", "extend_wait", "wait_extended".
+// kind is one of: "read_receipt", "extend_wait", "wait_extended", "reconnect",
+// "wait_capped", "wait_adjusted" (Listen matched its wait to the server's
+// time remaining), "nudged" (a high or urgent send re-notified unread
+// recipients), "parse_error" (event data that is not valid JSON, with the
+// raw data), "unexpected_event" (strict decoding only).
 type StatusCallback func(kind string, message string)
+
+// MessageCallback receives the messages Open and History fetch one at a
+// time, oldest first, while later pages are still being read, so a caller
+// can show a long transcript as it loads. It runs on the calling goroutine
+// before Open or History returns, and the returned result still holds every
+// message. If a later page fails, the messages already passed to it are not
+// in any result.
+type MessageCallback func(Event)
@@ -17,7 +17,7 @@ type ClaimsResponse struct {
 }
 
 func (c *Client) ClaimsList(ctx context.Context, workspaceID string, limit int) (*ClaimsResponse, error) {
-	path := "/v1/claims"
+	path := c.APIPath("/claims")
 	sep := "?"
 	if workspaceID != "" {
 		path += sep + "workspace_id=" + urlQueryEscape(workspaceID)
@@ -1,7 +1,9 @@
 package aweb
 
 import (
+	"context"
 	"crypto/ed25519"
+	"net/http"
 
 	"github.com/awebai/aw/awid"
 )
@@ -9,9 +11,14 @@ import (
 // Client provides both protocol and coordination operations.
 // Protocol operations are available via the embedded awid.Client.
 // Coordination operations (workspaces, team roles, tasks, reservations,
-// claims) are defined as methods on this type.
+// claims) are defined as methods on this type. Like awid.Client it is safe
+// for concurrent use once configured.
 type Client struct {
 	*awid.Client
+
+	mailQueueDir      string
+	reservationPrefix string
+	fencingTokens     fencingTokenCache
 }
 
 // New creates a client.
@@ -32,3 +39,9 @@ func NewWithCertificate(baseURL string, signingKey ed25519.PrivateKey, cert *awi
 	}
 	return &Client{Client: c}, nil
 }
+
+// WithHeaders returns a copy of ctx whose requests carry h; see
+// awid.WithHeaders.
+func WithHeaders(ctx context.Context, h http.Header) context.Context {
+	return awid.WithHeaders(ctx, h)
+}
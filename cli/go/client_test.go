@@ -0,0 +1,48 @@
+package aweb
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSetAPIPrefixAppliesToCoordinationModules(t *testing.T) {
+	t.Parallel()
+
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	t.Cleanup(server.Close)
+
+	c, err := New(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.SetAPIPrefix("/api/aweb/v1")
+	ctx := context.Background()
+
+	calls := []struct {
+		want string
+		call func() error
+	}{
+		{"/api/aweb/v1/auth/rotate", func() error { _, err := c.RotateAPIKey(ctx); return err }},
+		{"/api/aweb/v1/claims", func() error { _, err := c.ClaimsList(ctx, "", 0); return err }},
+		{"/api/aweb/v1/status", func() error { _, err := c.CoordinationStatus(ctx, ""); return err }},
+		{"/api/aweb/v1/reservations", func() error { _, err := c.ReservationList(ctx, ReservationListParams{}); return err }},
+		{"/api/aweb/v1/tasks/aw-1", func() error { _, err := c.TaskGet(ctx, "aw-1"); return err }},
+		{"/api/aweb/v1/instructions/active", func() error { _, err := c.ActiveTeamInstructions(ctx); return err }},
+		{"/api/aweb/v1/roles/active", func() error { _, err := c.ActiveTeamRoles(ctx, ActiveTeamRolesParams{}); return err }},
+		{"/api/aweb/v1/workspaces", func() error { _, err := c.WorkspaceList(ctx, WorkspaceListParams{}); return err }},
+	}
+	for i, tc := range calls {
+		if err := tc.call(); err != nil {
+			t.Fatalf("%s: %v", tc.want, err)
+		}
+		if paths[i] != tc.want {
+			t.Fatalf("path=%q, want %q", paths[i], tc.want)
+		}
+	}
+}
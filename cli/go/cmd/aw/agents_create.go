@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/awebai/aw/awid"
+	"github.com/spf13/cobra"
+)
+
+var (
+	agentsCreateAlias     string
+	agentsCreateType      string
+	agentsCreateHumanName string
+	agentsCreateMeta      []string
+)
+
+var agentsCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Provision another agent in the active team",
+	Long: `Provision another agent in the active team, for example a worker spawned
+by a supervisor. Without --alias the server suggests the next free alias.
+The new agent's API key is printed once; store it before continuing.`,
+	RunE: runAgentsCreate,
+}
+
+type agentsCreateOutput struct {
+	AgentID string `json:"agent_id"`
+	Alias   string `json:"alias"`
+	TeamID  string `json:"team_id,omitempty"`
+	APIKey  string `json:"api_key"`
+}
+
+func init() {
+	agentsCreateCmd.Flags().StringVar(&agentsCreateAlias, "alias", "", "Alias for the new agent (default: server suggestion)")
+	agentsCreateCmd.Flags().StringVar(&agentsCreateType, "type", "agent", "Agent type")
+	agentsCreateCmd.Flags().StringVar(&agentsCreateHumanName, "human-name", "", "Human name for the new agent")
+	agentsCreateCmd.Flags().StringArrayVar(&agentsCreateMeta, "meta", nil, "Metadata for the new agent as key=value or key=@file.json (repeatable)")
+	agentsCmd.AddCommand(agentsCreateCmd)
+}
+
+func runAgentsCreate(cmd *cobra.Command, args []string) error {
+	alias := strings.TrimSpace(agentsCreateAlias)
+	if alias != "" && !isValidWorkspaceAlias(alias) {
+		return usageError("invalid alias %q: must start with an alphanumeric and contain only alphanumerics, dashes, or underscores (max 64 chars)", alias)
+	}
+	metadata, err := parseMetaFlags(agentsCreateMeta)
+	if err != nil {
+		return err
+	}
+
+	client, err := resolveClient()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	if alias == "" {
+		suggestion, err := client.SuggestAliasPrefix(ctx)
+		if err != nil {
+			return fmt.Errorf("suggest next alias from server: %w", err)
+		}
+		alias = strings.TrimSpace(suggestion.NamePrefix)
+		if !isValidSuggestedAliasPrefix(alias) {
+			return fmt.Errorf("server returned invalid alias suggestion %q", alias)
+		}
+	}
+
+	resp, err := client.CreateAgent(ctx, &awid.CreateAgentRequest{
+		Alias:     alias,
+		HumanName: strings.TrimSpace(agentsCreateHumanName),
+		AgentType: strings.TrimSpace(agentsCreateType),
+		Metadata:  metadata,
+	})
+	if err != nil {
+		var taken *awid.AliasTakenError
+		if errors.As(err, &taken) {
+			return usageError("alias %q is already in use by this team; pick another or omit --alias to use the server suggestion", taken.Alias)
+		}
+		if errors.Is(err, awid.ErrAgentCreateUnsupported) {
+			return fmt.Errorf("this server does not create agents over the API; start the new agent with `aw init` and a team certificate instead")
+		}
+		return err
+	}
+
+	printOutput(agentsCreateOutput{
+		AgentID: resp.AgentID,
+		Alias:   resp.Alias,
+		TeamID:  resp.TeamID,
+		APIKey:  resp.APIKey,
+	}, formatAgentsCreate)
+	return nil
+}
+
+func formatAgentsCreate(v any) string {
+	out := v.(agentsCreateOutput)
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Created agent %s (agent_id=%s)\n", out.Alias, out.AgentID))
+	if out.APIKey != "" {
+		sb.WriteString(fmt.Sprintf("API key: %s\n", out.APIKey))
+		sb.WriteString("This key is not shown again.\n")
+	}
+	return sb.String()
+}
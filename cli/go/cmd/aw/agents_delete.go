@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/awebai/aw/awid"
+	"github.com/spf13/cobra"
+)
+
+var (
+	agentsDeleteAgentID string
+	agentsDeleteForce   bool
+)
+
+var agentsDeleteCmd = &cobra.Command{
+	Use:   "delete",
+	Short: "Delete an agent from the active team",
+	Long: `Delete an agent from the active team, for example a worker whose task is
+done. Deleting the agent this workspace runs as invalidates its own
+credentials, so that requires --force, as does any delete when the current
+agent cannot be found in the team roster.`,
+	RunE: runAgentsDelete,
+}
+
+func init() {
+	agentsDeleteCmd.Flags().StringVar(&agentsDeleteAgentID, "agent-id", "", "ID of the agent to delete")
+	agentsDeleteCmd.Flags().BoolVar(&agentsDeleteForce, "force", false, "Allow deleting the current agent")
+	agentsCmd.AddCommand(agentsDeleteCmd)
+}
+
+func runAgentsDelete(cmd *cobra.Command, args []string) error {
+	agentID := strings.TrimSpace(agentsDeleteAgentID)
+	if agentID == "" {
+		return usageError("--agent-id is required")
+	}
+
+	client, sel, err := resolveClientSelection()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	if !agentsDeleteForce {
+		self, err := client.ResolveAlias(ctx, sel.Alias)
+		if errors.Is(err, awid.ErrUnknownAlias) {
+			return usageError("cannot tell whether %s is the current agent: %s is not in the team roster; pass --force to delete it anyway", agentID, sel.Alias)
+		}
+		if err != nil {
+			return fmt.Errorf("look up current agent: %w", err)
+		}
+		if self.AgentID == agentID {
+			return usageError("refusing to delete the current agent %s (%s): it would invalidate this workspace's credentials; pass --force to delete it anyway", sel.Alias, agentID)
+		}
+	}
+
+	resp, err := client.DeleteAgent(ctx, agentID)
+	switch {
+	case errors.Is(err, awid.ErrAgentNotFound):
+		return fmt.Errorf("agent %s not found in this team", agentID)
+	case errors.Is(err, awid.ErrAgentDeleteForbidden):
+		return fmt.Errorf("not allowed to delete agent %s", agentID)
+	case err != nil:
+		return err
+	}
+	printOutput(resp, formatAgentsDelete)
+	return nil
+}
+
+func formatAgentsDelete(v any) string {
+	resp := v.(*awid.DeleteAgentResponse)
+	return fmt.Sprintf("Deleted agent %s (agent_id=%s)\n", resp.Alias, resp.AgentID)
+}
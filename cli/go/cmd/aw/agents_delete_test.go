@@ -0,0 +1,153 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestAwAgentsDeleteRefusesSelfWithoutForce(t *testing.T) {
+	t.Parallel()
+
+	var deletes atomic.Int32
+	server := newLocalHTTPServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.Path {
+		case "GET /v1/agents":
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"team_id": "backend:demo",
+				"agents":  []map[string]any{{"agent_id": "agent-alice", "alias": "alice"}},
+			})
+		case "DELETE /v1/agents/agent-alice":
+			requireCertificateAuthForTest(t, r)
+			deletes.Add(1)
+			_ = json.NewEncoder(w).Encode(map[string]any{"agent_id": "agent-alice", "alias": "alice"})
+		case "POST /v1/agents/heartbeat":
+			w.WriteHeader(http.StatusOK)
+		default:
+			t.Errorf("unexpected %s %s", r.Method, r.URL.Path)
+		}
+	}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	tmp := t.TempDir()
+	bin := filepath.Join(tmp, "aw")
+	buildAwBinary(t, ctx, bin)
+	writeDefaultWorkspaceBindingForTest(t, tmp, server.URL)
+
+	run := exec.CommandContext(ctx, bin, "agents", "delete", "--agent-id", "agent-alice")
+	run.Env = testCommandEnv(tmp)
+	run.Dir = tmp
+	out, err := run.CombinedOutput()
+	if err == nil {
+		t.Fatalf("expected refusal, got:\n%s", string(out))
+	}
+	if !strings.Contains(string(out), "--force") {
+		t.Fatalf("output=%s", string(out))
+	}
+	if deletes.Load() != 0 {
+		t.Fatal("agent was deleted without --force")
+	}
+
+	run = exec.CommandContext(ctx, bin, "agents", "delete", "--agent-id", "agent-alice", "--force")
+	run.Env = testCommandEnv(tmp)
+	run.Dir = tmp
+	out, err = run.CombinedOutput()
+	if err != nil {
+		t.Fatalf("run failed: %v\n%s", err, string(out))
+	}
+	if !strings.Contains(string(out), "Deleted agent alice") {
+		t.Fatalf("output=%s", string(out))
+	}
+	if deletes.Load() != 1 {
+		t.Fatalf("deletes=%d", deletes.Load())
+	}
+}
+
+func TestAwAgentsDeleteReportsNotFound(t *testing.T) {
+	t.Parallel()
+
+	server := newLocalHTTPServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.Path {
+		case "GET /v1/agents":
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"agents": []map[string]any{{"agent_id": "agent-alice", "alias": "alice"}},
+			})
+		case "DELETE /v1/agents/agent-gone":
+			w.WriteHeader(http.StatusNotFound)
+		case "POST /v1/agents/heartbeat":
+			w.WriteHeader(http.StatusOK)
+		default:
+			t.Errorf("unexpected %s %s", r.Method, r.URL.Path)
+		}
+	}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	tmp := t.TempDir()
+	bin := filepath.Join(tmp, "aw")
+	buildAwBinary(t, ctx, bin)
+	writeDefaultWorkspaceBindingForTest(t, tmp, server.URL)
+
+	run := exec.CommandContext(ctx, bin, "agents", "delete", "--agent-id", "agent-gone")
+	run.Env = testCommandEnv(tmp)
+	run.Dir = tmp
+	out, err := run.CombinedOutput()
+	if err == nil {
+		t.Fatalf("expected failure, got:\n%s", string(out))
+	}
+	if !strings.Contains(string(out), "agent agent-gone not found") {
+		t.Fatalf("output=%s", string(out))
+	}
+}
+
+func TestAwAgentsDeleteRefusesWhenSelfIsUnknown(t *testing.T) {
+	t.Parallel()
+
+	var deletes atomic.Int32
+	server := newLocalHTTPServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.Path {
+		case "GET /v1/agents":
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"agents": []map[string]any{{"agent_id": "agent-bob", "alias": "bob"}},
+			})
+		case "DELETE /v1/agents/agent-bob":
+			deletes.Add(1)
+			_ = json.NewEncoder(w).Encode(map[string]any{"agent_id": "agent-bob", "alias": "bob"})
+		case "POST /v1/agents/heartbeat":
+			w.WriteHeader(http.StatusOK)
+		default:
+			t.Errorf("unexpected %s %s", r.Method, r.URL.Path)
+		}
+	}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	tmp := t.TempDir()
+	bin := filepath.Join(tmp, "aw")
+	buildAwBinary(t, ctx, bin)
+	writeDefaultWorkspaceBindingForTest(t, tmp, server.URL)
+
+	run := exec.CommandContext(ctx, bin, "agents", "delete", "--agent-id", "agent-bob")
+	run.Env = testCommandEnv(tmp)
+	run.Dir = tmp
+	out, err := run.CombinedOutput()
+	if err == nil {
+		t.Fatalf("expected refusal, got:\n%s", string(out))
+	}
+	if !strings.Contains(string(out), "not in the team roster") || !strings.Contains(string(out), "--force") {
+		t.Fatalf("output=%s", string(out))
+	}
+	if deletes.Load() != 0 {
+		t.Fatal("agent was deleted although the current agent could not be identified")
+	}
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/awebai/aw/awid"
+	"github.com/spf13/cobra"
+)
+
+var agentsListStale time.Duration
+
+var agentsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List agents in the active team",
+	Long: `List the agents in the active team with their presence.
+
+--stale 24h shows only agents last seen more than 24 hours ago, to find dead
+agents worth removing with "aw agents delete". Agents with no usable
+last-seen time are left out of the stale list.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if agentsListStale < 0 {
+			return usageError("--stale must not be negative")
+		}
+		client, err := resolveClient()
+		if err != nil {
+			return err
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		resp, err := client.ListAgents(ctx)
+		if err != nil {
+			return err
+		}
+		if agentsListStale > 0 {
+			resp = &awid.ListAgentsResponse{TeamID: resp.TeamID, Agents: resp.StaleAgents(agentsListStale)}
+		}
+		printOutput(resp, formatAgentsList)
+		return nil
+	},
+}
+
+func init() {
+	agentsListCmd.Flags().DurationVar(&agentsListStale, "stale", 0, "Show only agents last seen longer ago than this (e.g. 24h)")
+	agentsCmd.AddCommand(agentsListCmd)
+}
+
+func formatAgentsList(v any) string {
+	resp := v.(*awid.ListAgentsResponse)
+	if len(resp.Agents) == 0 {
+		return "No agents.\n"
+	}
+	var sb strings.Builder
+	for _, a := range resp.Agents {
+		line := "- " + a.Alias
+		if a.Role != "" {
+			line += " (" + a.Role + ")"
+		}
+		switch {
+		case a.Online:
+			line += " — online"
+		case a.LastSeen != "":
+			line += " — seen " + formatTimeAgo(a.LastSeen)
+		default:
+			line += " — never seen"
+		}
+		sb.WriteString(fmt.Sprintf("%s (agent_id=%s)\n", line, a.AgentID))
+	}
+	return sb.String()
+}
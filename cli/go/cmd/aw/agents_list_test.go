@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAwAgentsListStaleFiltersByLastSeen(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now().UTC()
+	server := newLocalHTTPServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.Path {
+		case "GET /v1/agents":
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"team_id": "backend:demo",
+				"agents": []map[string]any{
+					{"agent_id": "agent-alice", "alias": "alice", "online": true, "last_seen": now.Format(time.RFC3339)},
+					{"agent_id": "agent-bob", "alias": "bob", "last_seen": now.Add(-72 * time.Hour).Format(time.RFC3339)},
+					{"agent_id": "agent-carol", "alias": "carol", "last_seen": "not a time"},
+				},
+			})
+		case "POST /v1/agents/heartbeat":
+			w.WriteHeader(http.StatusOK)
+		default:
+			t.Errorf("unexpected %s %s", r.Method, r.URL.Path)
+		}
+	}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	tmp := t.TempDir()
+	bin := filepath.Join(tmp, "aw")
+	buildAwBinary(t, ctx, bin)
+	writeDefaultWorkspaceBindingForTest(t, tmp, server.URL)
+
+	run := exec.CommandContext(ctx, bin, "agents", "list")
+	run.Env = testCommandEnv(tmp)
+	run.Dir = tmp
+	out, err := run.CombinedOutput()
+	if err != nil {
+		t.Fatalf("run failed: %v\n%s", err, string(out))
+	}
+	for _, want := range []string{"- alice — online", "- bob — seen 3d ago", "- carol — seen not a time"} {
+		if !strings.Contains(string(out), want) {
+			t.Fatalf("output missing %q:\n%s", want, string(out))
+		}
+	}
+
+	run = exec.CommandContext(ctx, bin, "agents", "list", "--stale", "24h")
+	run.Env = testCommandEnv(tmp)
+	run.Dir = tmp
+	out, err = run.CombinedOutput()
+	if err != nil {
+		t.Fatalf("run failed: %v\n%s", err, string(out))
+	}
+	if got := string(out); !strings.Contains(got, "- bob") || strings.Contains(got, "alice") || strings.Contains(got, "carol") {
+		t.Fatalf("stale output=%s", got)
+	}
+}
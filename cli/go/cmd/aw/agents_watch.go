@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/awebai/aw/awid"
+	"github.com/spf13/cobra"
+)
+
+var agentsCmd = &cobra.Command{
+	Use:   "agents",
+	Short: "Team agent roster",
+}
+
+var agentsWatchTimeout int
+
+var agentsWatchCmd = &cobra.Command{
+	Use:   "watch",
+	Short: "Stream agent presence changes as NDJSON",
+	Long: `Stream agent presence changes for the active team as newline-delimited JSON.
+
+The stream starts with one "online" event (with "snapshot": true) for every
+agent online at connect time, followed by online, offline and last_seen
+transitions. Dropped connections are reopened automatically; if the server
+rejects a reconnect, the command fails with a non-zero exit.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := resolveClient()
+		if err != nil {
+			return err
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+		if agentsWatchTimeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, time.Duration(agentsWatchTimeout)*time.Second)
+			defer cancel()
+		}
+
+		events, err := client.WatchPresence(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		enc := json.NewEncoder(os.Stdout)
+		for ev := range events {
+			if ev.Type == awid.PresenceStreamError {
+				return fmt.Errorf("presence stream: %s", ev.Error)
+			}
+			if err := enc.Encode(ev); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
+
+func init() {
+	agentsWatchCmd.Flags().IntVar(&agentsWatchTimeout, "timeout", 0, "Stop after N seconds (0 = indefinite)")
+
+	agentsCmd.AddCommand(agentsWatchCmd)
+	rootCmd.AddCommand(agentsCmd)
+}
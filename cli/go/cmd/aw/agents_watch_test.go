@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/awebai/aw/awid"
+)
+
+func TestAwAgentsWatchPrintsNDJSON(t *testing.T) {
+	t.Parallel()
+
+	server := newLocalHTTPServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/agents/presence/stream":
+			requireCertificateAuthForTest(t, r)
+			w.Header().Set("Content-Type", "text/event-stream")
+			fmt.Fprint(w, "event: snapshot\ndata: {\"agents\":[{\"agent_id\":\"a-1\",\"alias\":\"alice\"}]}\n\n")
+			fmt.Fprint(w, "event: offline\ndata: {\"agent_id\":\"a-1\",\"alias\":\"alice\"}\n\n")
+			w.(http.Flusher).Flush()
+			<-r.Context().Done()
+		case "/v1/agents/heartbeat":
+			w.WriteHeader(http.StatusOK)
+		default:
+			t.Errorf("unexpected path=%s", r.URL.Path)
+		}
+	}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	tmp := t.TempDir()
+	bin := filepath.Join(tmp, "aw")
+	buildAwBinary(t, ctx, bin)
+	writeDefaultWorkspaceBindingForTest(t, tmp, server.URL)
+
+	run := exec.CommandContext(ctx, bin, "agents", "watch", "--timeout", "2")
+	run.Env = testCommandEnv(tmp)
+	run.Dir = tmp
+	out, err := run.Output()
+	if err != nil {
+		t.Fatalf("run failed: %v\n%s", err, string(out))
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(out)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("lines=%d, want 2:\n%s", len(lines), string(out))
+	}
+	var first, second awid.AgentPresenceEvent
+	if err := json.Unmarshal([]byte(lines[0]), &first); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal([]byte(lines[1]), &second); err != nil {
+		t.Fatal(err)
+	}
+	if first.Type != awid.PresenceOnline || !first.Snapshot || first.Agent.Alias != "alice" {
+		t.Fatalf("first=%+v", first)
+	}
+	if second.Type != awid.PresenceOffline || second.Snapshot {
+		t.Fatalf("second=%+v", second)
+	}
+}
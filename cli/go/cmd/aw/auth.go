@@ -0,0 +1,156 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	aweb "github.com/awebai/aw"
+	"github.com/awebai/aw/awconfig"
+	"github.com/awebai/aw/awid"
+	"github.com/spf13/cobra"
+)
+
+var authCmd = &cobra.Command{
+	Use:   "auth",
+	Short: "Manage workspace API credentials",
+}
+
+var authRotateCmd = &cobra.Command{
+	Use:   "rotate",
+	Short: "Rotate the workspace API key stored in .aw/workspace.yaml",
+	Long: `Ask the server for a new API key for the current agent and store it in
+.aw/workspace.yaml, and in ~/.config/aw/credentials.yaml where that file held
+the old key. The previous key keeps working for the grace window reported by
+the server, so other processes still holding it have time to pick up the new
+one.`,
+	RunE: runAuthRotate,
+}
+
+type authRotateOutput struct {
+	Status               string `json:"status"`
+	Workspace            string `json:"workspace"`
+	PreviousKeyExpiresAt string `json:"previous_key_expires_at,omitempty"`
+	GraceSeconds         int    `json:"grace_seconds,omitempty"`
+}
+
+func init() {
+	authCmd.AddCommand(authRotateCmd)
+	rootCmd.AddCommand(authCmd)
+	authCmd.GroupID = groupIdentity
+}
+
+func runAuthRotate(cmd *cobra.Command, args []string) error {
+	wd, _ := os.Getwd()
+	workspace, workspacePath, err := awconfig.LoadWorktreeWorkspaceFromDir(wd)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return usageError("current worktree is missing .aw/workspace.yaml; run `aw init` first")
+		}
+		return err
+	}
+	oldKey := strings.TrimSpace(workspace.APIKey)
+	if oldKey == "" {
+		return usageError("this workspace has no stored API key to rotate; it was not initialized with AWEB_API_KEY")
+	}
+
+	// The rotation authenticates with the key being rotated rather than the
+	// worktree's signing key, so the server knows which key to replace.
+	sel, err := resolveSelectionForDir(wd)
+	if err != nil {
+		return err
+	}
+	baseURL, err := resolveAuthenticatedBaseURL(sel.BaseURL)
+	if err != nil {
+		return err
+	}
+	client, err := aweb.New(baseURL)
+	if err != nil {
+		return err
+	}
+	client.SetTokenSource(awid.StaticToken(oldKey))
+	if err := configureClientTLS(client, sel); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	resp, err := client.RotateAPIKey(ctx)
+	if err != nil {
+		return fmt.Errorf("rotating API key: %w", err)
+	}
+	newKey := strings.TrimSpace(resp.APIKey)
+	if newKey == "" {
+		return fmt.Errorf("rotate response is missing api_key")
+	}
+	if len(newKey) > maxWorkspaceAPIKeyLength {
+		return fmt.Errorf("rotate response api_key exceeds %d bytes", maxWorkspaceAPIKeyLength)
+	}
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	workspace.APIKey = newKey
+	workspace.UpdatedAt = now
+	if err := awconfig.SaveWorktreeWorkspaceTo(workspacePath, workspace); err != nil {
+		return fmt.Errorf("write %s: %w", workspacePath, err)
+	}
+	credentialsPath, err := awconfig.DefaultCredentialsPath()
+	if err != nil {
+		return err
+	}
+	if err := replaceStoredAPIKey(credentialsPath, oldKey, newKey, now); err != nil {
+		return fmt.Errorf("update %s: %w", credentialsPath, err)
+	}
+
+	printOutput(authRotateOutput{
+		Status:               "rotated",
+		Workspace:            workspacePath,
+		PreviousKeyExpiresAt: strings.TrimSpace(resp.PreviousKeyExpiresAt),
+		GraceSeconds:         resp.GraceSeconds,
+	}, formatAuthRotate)
+	return nil
+}
+
+// replaceStoredAPIKey swaps oldKey for newKey wherever `aw login` or
+// `aw config set-key` stored it, so later `aw init` runs use the new key.
+// The file is left untouched when it does not hold oldKey.
+func replaceStoredAPIKey(path, oldKey, newKey, obtainedAt string) error {
+	creds, err := awconfig.LoadCredentialsFrom(path)
+	if err != nil {
+		return err
+	}
+	stored := false
+	for server := range creds.Servers {
+		if creds.APIKeyFor(server) == oldKey {
+			stored = true
+		}
+	}
+	if !stored {
+		return nil
+	}
+	return awconfig.UpdateCredentialsAt(path, func(creds *awconfig.Credentials) error {
+		for server := range creds.Servers {
+			if creds.APIKeyFor(server) == oldKey {
+				creds.SetAPIKey(server, newKey, obtainedAt)
+			}
+		}
+		return nil
+	})
+}
+
+func formatAuthRotate(v any) string {
+	out := v.(authRotateOutput)
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Rotated API key in %s\n", out.Workspace))
+	switch {
+	case out.PreviousKeyExpiresAt != "":
+		sb.WriteString(fmt.Sprintf("Previous key remains valid until %s\n", out.PreviousKeyExpiresAt))
+	case out.GraceSeconds > 0:
+		sb.WriteString(fmt.Sprintf("Previous key remains valid for %ds\n", out.GraceSeconds))
+	default:
+		sb.WriteString("Previous key is no longer valid\n")
+	}
+	return sb.String()
+}
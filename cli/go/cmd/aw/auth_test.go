@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/awebai/aw/awconfig"
+)
+
+func TestAwAuthRotatePersistsNewKey(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/v1/auth/rotate" {
+			t.Errorf("unexpected %s %s", r.Method, r.URL.Path)
+			http.NotFound(w, r)
+			return
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer aw_sk_old" {
+			t.Errorf("Authorization=%q, want the key being rotated", got)
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"api_key": "aw_sk_new", "grace_seconds": 300})
+	}))
+	t.Cleanup(server.Close)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	tmp := t.TempDir()
+	bin := filepath.Join(tmp, "aw")
+	buildAwBinary(t, ctx, bin)
+
+	binding := workspaceBinding(server.URL, "backend:demo", "alice", "workspace-1")
+	binding.APIKey = "aw_sk_old"
+	workspacePath := writeWorkspaceBindingForTest(t, tmp, binding)
+	credentialsPath := filepath.Join(tmp, ".config", "aw", "credentials.yaml")
+	if err := os.MkdirAll(filepath.Dir(credentialsPath), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	err := awconfig.UpdateCredentialsAt(credentialsPath, func(creds *awconfig.Credentials) error {
+		creds.SetAPIKey(server.URL, "aw_sk_old", "2026-01-01T00:00:00Z")
+		creds.SetAPIKey("https://other.example", "aw_sk_other", "2026-01-01T00:00:00Z")
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	run := exec.CommandContext(ctx, bin, "auth", "rotate")
+	run.Env = testCommandEnv(tmp)
+	run.Dir = tmp
+	out, err := run.CombinedOutput()
+	if err != nil {
+		t.Fatalf("aw auth rotate failed: %v\n%s", err, out)
+	}
+	if !strings.Contains(string(out), "Previous key remains valid for 300s") {
+		t.Fatalf("output missing grace window:\n%s", out)
+	}
+
+	workspace, err := awconfig.LoadWorktreeWorkspaceFrom(workspacePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if workspace.APIKey != "aw_sk_new" {
+		t.Fatalf("workspace api_key=%q, want aw_sk_new", workspace.APIKey)
+	}
+	creds, err := awconfig.LoadCredentialsFrom(credentialsPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := creds.APIKeyFor(server.URL); got != "aw_sk_new" {
+		t.Fatalf("credentials api_key=%q, want aw_sk_new", got)
+	}
+	if got := creds.APIKeyFor("https://other.example"); got != "aw_sk_other" {
+		t.Fatalf("unrelated credentials api_key=%q, want it untouched", got)
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"runtime"
 	"strings"
 	"sync/atomic"
 	"testing"
@@ -388,6 +389,10 @@ func TestAwChatSendAndLeavePositionalArgs(t *testing.T) {
 				"targets_connected": []string{},
 				"targets_left":      []string{},
 			})
+		case "/v1/agents":
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"agents": []map[string]any{{"agent_id": "agent-bob", "alias": "bob"}},
+			})
 		case "/v1/agents/heartbeat":
 			w.WriteHeader(http.StatusOK)
 		default:
@@ -529,6 +534,10 @@ func TestAwChatSendAndLeavePositionalArgsOrder(t *testing.T) {
 				"targets_connected": []string{},
 				"targets_left":      []string{},
 			})
+		case "/v1/agents":
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"agents": []map[string]any{{"agent_id": "agent-bob", "alias": "bob"}},
+			})
 		case "/v1/agents/heartbeat":
 			w.WriteHeader(http.StatusOK)
 		default:
@@ -613,6 +622,20 @@ func TestVersionCommand(t *testing.T) {
 	if !strings.HasPrefix(string(out), "aw ") {
 		t.Fatalf("unexpected version output: %s", string(out))
 	}
+
+	run = exec.CommandContext(ctx, bin, "version", "--json")
+	run.Env = append(os.Environ(), "AWEB_URL=")
+	out, err = run.Output()
+	if err != nil {
+		t.Fatalf("run --json failed: %v\n%s", err, string(out))
+	}
+	var info map[string]any
+	if err := json.Unmarshal(out, &info); err != nil {
+		t.Fatalf("version --json is not a JSON document: %v\n%s", err, string(out))
+	}
+	if info["version"] != "dev" || info["go_version"] != runtime.Version() || info["os"] != runtime.GOOS || info["arch"] != runtime.GOARCH {
+		t.Fatalf("unexpected version --json output: %s", string(out))
+	}
 }
 
 func TestAwContactsList(t *testing.T) {
@@ -1688,6 +1711,65 @@ func TestAwMailInboxLogsStableIDWhenAddressMissing(t *testing.T) {
 	}
 }
 
+func TestAwMailInboxAckAllReportsPartialFailures(t *testing.T) {
+	t.Parallel()
+
+	server := newLocalHTTPServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/messages/inbox":
+			readAt := "2026-04-10T00:05:00Z"
+			_ = json.NewEncoder(w).Encode(awid.InboxResponse{
+				Messages: []awid.InboxMessage{
+					{MessageID: "msg-1", FromAlias: "monitor", Body: "new", CreatedAt: "2026-04-10T00:00:00Z"},
+					{MessageID: "msg-2", FromAlias: "monitor", Body: "old", CreatedAt: "2026-04-09T00:00:00Z", ReadAt: &readAt},
+				},
+			})
+		case "/v1/messages/ack":
+			var req struct {
+				MessageIDs []string `json:"message_ids"`
+			}
+			_ = json.NewDecoder(r.Body).Decode(&req)
+			if len(req.MessageIDs) != 2 {
+				t.Errorf("message_ids=%v, want both displayed messages", req.MessageIDs)
+			}
+			_ = json.NewEncoder(w).Encode(awid.BulkAckResponse{Results: []awid.BulkAckResult{
+				{MessageID: "msg-1", AcknowledgedAt: "2026-04-10T00:10:00Z"},
+				{MessageID: "msg-2", Error: "message not found"},
+			}})
+		case "/v1/agents/heartbeat":
+			w.WriteHeader(http.StatusOK)
+		default:
+			t.Fatalf("unexpected path=%s", r.URL.Path)
+		}
+	}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	tmp := t.TempDir()
+	bin := filepath.Join(tmp, "aw")
+	buildAwBinary(t, ctx, bin)
+	writeDefaultWorkspaceBindingForTest(t, tmp, server.URL)
+
+	run := exec.CommandContext(ctx, bin, "mail", "inbox", "--show-all", "--ack-all")
+	run.Env = testCommandEnv(tmp)
+	run.Dir = tmp
+	out, err := run.CombinedOutput()
+	if err == nil {
+		t.Fatalf("expected failure, got success:\n%s", string(out))
+	}
+	text := string(out)
+	if !strings.Contains(text, "failed to ack msg-2: message not found") {
+		t.Fatalf("missing per-message failure:\n%s", text)
+	}
+	if !strings.Contains(text, "failed to ack 1 of 2 messages") {
+		t.Fatalf("missing failure summary:\n%s", text)
+	}
+	if !strings.Contains(text, "MAILS: 2") {
+		t.Fatalf("inbox should still be displayed:\n%s", text)
+	}
+}
+
 func TestAwResetLocal(t *testing.T) {
 	t.Parallel()
 
@@ -1891,3 +1973,20 @@ func TestMCPConfigRequiresChannelForCertificateAuth(t *testing.T) {
 		t.Fatalf("unexpected output:\n%s", string(out))
 	}
 }
+
+func TestParseHistorySinceAcceptsTimestampOrDuration(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 3, 17, 12, 0, 0, 0, time.UTC)
+	got, err := parseHistorySince("2026-03-17T11:00:00Z", now)
+	if err != nil || !got.Equal(now.Add(-time.Hour)) {
+		t.Fatalf("timestamp: got=%v err=%v", got, err)
+	}
+	got, err = parseHistorySince("15m", now)
+	if err != nil || !got.Equal(now.Add(-15*time.Minute)) {
+		t.Fatalf("duration: got=%v err=%v", got, err)
+	}
+	if _, err := parseHistorySince("yesterday", now); err == nil {
+		t.Fatal("expected error for invalid value")
+	}
+}
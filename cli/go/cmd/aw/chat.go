@@ -2,12 +2,16 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
+	"os/signal"
 	"strings"
 	"time"
 
+	aweb "github.com/awebai/aw"
 	"github.com/awebai/aw/awconfig"
+	"github.com/awebai/aw/awid"
 	"github.com/awebai/aw/chat"
 	"github.com/spf13/cobra"
 )
@@ -18,14 +22,26 @@ var chatCmd = &cobra.Command{
 }
 
 func chatStderrCallback(kind, message string) {
-	fmt.Fprintf(os.Stderr, "[chat:%s] %s\n", kind, message)
+	statusf("[chat:%s] %s", kind, message)
 }
 
 func chatSend(ctx context.Context, toAlias, message string, opts chat.SendOptions) (*chat.SendResult, *awconfig.Selection, error) {
+	if !strings.HasPrefix(toAlias, "did:") && !strings.Contains(toAlias, "/") {
+		if err := aweb.ValidateAlias(toAlias); err != nil {
+			return nil, nil, usageError("%v", err)
+		}
+	}
 	c, sel, err := resolveClientSelectionForAliasTarget(ctx, toAlias)
 	if err != nil {
 		return nil, nil, err
 	}
+	if !opts.Leaving {
+		wait, err := awconfig.ResolveChatWait(sel, opts.Wait, opts.WaitExplicit)
+		if err != nil {
+			return nil, nil, err
+		}
+		opts.Wait = wait
+	}
 	r, err := chat.Send(ctx, c.Client, sel.Alias, []string{toAlias}, message, opts, chatStderrCallback)
 	return r, sel, err
 }
@@ -102,22 +118,57 @@ func selectionIdentityDIDs(sel *awconfig.Selection) []string {
 var (
 	chatSendAndWaitWait              int
 	chatSendAndWaitStartConversation bool
+	chatSendAndWaitReuseSession      bool
 	chatListenWait                   int
+	chatReplyWait                    int
+	chatSendAndWaitFailOnNoReply     bool
+	chatSendSubject                  string
+	chatSendPriority                 string
+)
+
+// Exit codes for chat send-and-wait outcomes other than a reply. They are
+// returned after the result has been printed.
+const (
+	chatExitNoReply       = 10
+	chatExitTargetsLeft   = 11
+	chatExitTargetOffline = 12
 )
 
 var chatSendAndWaitCmd = &cobra.Command{
 	Use:   "send-and-wait <alias> <message>",
 	Short: "Send a message and wait for a reply",
-	Args:  cobra.ExactArgs(2),
+	Long: `Send a message and wait for a reply.
+
+The result is printed whatever the outcome, and the exit status tells
+scripts what happened:
+
+  0   a reply arrived, or no reply was required
+  10  no reply arrived (only with --fail-on-no-reply)
+  11  the target had already left the conversation, so there was no wait
+  12  no reply arrived and the target was offline (only with
+      --fail-on-no-reply, unless the server reports the target offline)
+
+Other failures exit 1, and invalid flags exit 2.`,
+	Args: cobra.ExactArgs(2),
 	RunE: func(cmd *cobra.Command, args []string) error {
+		priority, err := awid.ParsePriority(chatSendPriority)
+		if err != nil {
+			return usageError("--priority: %v", err)
+		}
 		ctx, cancel := context.WithTimeout(context.Background(), chat.MaxSendTimeout)
 		defer cancel()
 
-		result, sel, err := chatSend(ctx, args[0], args[1], chat.SendOptions{
+		opts := chat.SendOptions{
 			Wait:              chatSendAndWaitWait,
 			WaitExplicit:      cmd.Flags().Changed("wait"),
 			StartConversation: chatSendAndWaitStartConversation,
-		})
+			Subject:           chatSendSubject,
+			Priority:          priority,
+		}
+		if chatSendAndWaitReuseSession {
+			opts.ExistingSession = chat.ReuseSession
+		}
+		result, sel, err := chatSend(ctx, args[0], args[1], opts)
 		if err != nil {
 			return networkError(err, args[0])
 		}
@@ -144,10 +195,31 @@ var chatSendAndWaitCmd = &cobra.Command{
 		// Log any reply events.
 		logChatEvents(logsDir, logName, myAddr, result.Events, selectionIdentityDIDs(sel)...)
 		printOutput(result, formatChatSend)
-		return nil
+		return chatSendOutcomeError(result, args[0], chatSendAndWaitFailOnNoReply)
 	},
 }
 
+// chatSendOutcomeError maps the outcome of a send-and-wait to an error
+// carrying its exit code, or nil when the command should exit 0. Without
+// failOnNoReply, a send that simply got no reply still succeeds.
+func chatSendOutcomeError(result *chat.SendResult, target string, failOnNoReply bool) error {
+	switch result.Status {
+	case chat.StatusTargetsLeft:
+		return &cliError{code: chatExitTargetsLeft, msg: fmt.Sprintf("%s has left the conversation; not waiting for a reply", target)}
+	case chat.StatusTargetOffline:
+		return &cliError{code: chatExitTargetOffline, msg: fmt.Sprintf("%s is offline; no reply", target)}
+	case chat.StatusSent, chat.StatusTimeout:
+		if !failOnNoReply {
+			return nil
+		}
+		if result.TargetNotConnected {
+			return &cliError{code: chatExitTargetOffline, msg: fmt.Sprintf("%s is offline; no reply", target)}
+		}
+		return &cliError{code: chatExitNoReply, msg: fmt.Sprintf("no reply from %s", target)}
+	}
+	return nil
+}
+
 // chat send-and-leave
 
 var chatSendAndLeaveCmd = &cobra.Command{
@@ -155,12 +227,18 @@ var chatSendAndLeaveCmd = &cobra.Command{
 	Short: "Send a message and leave the conversation",
 	Args:  cobra.ExactArgs(2),
 	RunE: func(cmd *cobra.Command, args []string) error {
+		priority, err := awid.ParsePriority(chatSendPriority)
+		if err != nil {
+			return usageError("--priority: %v", err)
+		}
 		ctx, cancel := context.WithTimeout(context.Background(), chat.MaxSendTimeout)
 		defer cancel()
 
 		result, sel, err := chatSend(ctx, args[0], args[1], chat.SendOptions{
-			Wait:    0,
-			Leaving: true,
+			Wait:     0,
+			Leaving:  true,
+			Subject:  chatSendSubject,
+			Priority: priority,
 		})
 		if err != nil {
 			return networkError(err, args[0])
@@ -209,13 +287,45 @@ var chatPendingCmd = &cobra.Command{
 	},
 }
 
+// chat list
+
+var chatListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List all chat conversations, most recent first",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		c, sel, err := resolveClientSelection()
+		if err != nil {
+			return err
+		}
+		result, err := chat.List(ctx, c.Client, sel.Alias)
+		if err != nil {
+			return err
+		}
+		printOutput(result, formatChatList)
+		return nil
+	},
+}
+
 // chat open
 
+var chatOpenAll bool
+
 var chatOpenCmd = &cobra.Command{
 	Use:   "open <alias>",
 	Short: "Open a chat session",
-	Args:  cobra.ExactArgs(1),
+	Args: func(cmd *cobra.Command, args []string) error {
+		if chatOpenAll {
+			return cobra.NoArgs(cmd, args)
+		}
+		return cobra.ExactArgs(1)(cmd, args)
+	},
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if chatOpenAll {
+			return runChatOpenAll()
+		}
 		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 		defer cancel()
 
@@ -237,13 +347,54 @@ var chatOpenCmd = &cobra.Command{
 	},
 }
 
+func runChatOpenAll() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	c, sel, err := resolveClientSelection()
+	if err != nil {
+		return err
+	}
+	results, err := chat.OpenAll(ctx, c.Client, sel.Alias)
+	if err != nil {
+		return err
+	}
+	logsDir := defaultLogsDir()
+	myAddr := selectionAddress(sel)
+	for _, result := range results {
+		logChatEvents(logsDir, commLogNameForSelection(sel), myAddr, result.Messages, selectionIdentityDIDs(sel)...)
+	}
+	printOutput(results, formatChatOpenAll)
+	return nil
+}
+
 // chat history
 
+var (
+	chatHistorySince    string
+	chatHistoryAfter    string
+	chatHistoryFormat   string
+	chatHistoryRelative bool
+)
+
 var chatHistoryCmd = &cobra.Command{
 	Use:   "history <alias>",
 	Short: "Show chat history with alias",
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
+		format := strings.TrimSpace(chatHistoryFormat)
+		if format != "" && format != outputJSON && format != outputText {
+			return usageError("--format: want %s or %s", outputJSON, outputText)
+		}
+		opts := chat.HistoryOptions{AfterMessageID: strings.TrimSpace(chatHistoryAfter)}
+		if chatHistorySince != "" {
+			since, err := parseHistorySince(chatHistorySince, time.Now())
+			if err != nil {
+				return usageError("--since: %v", err)
+			}
+			opts.Since = since
+		}
+
 		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 		defer cancel()
 
@@ -251,16 +402,92 @@ var chatHistoryCmd = &cobra.Command{
 		if err != nil {
 			return err
 		}
-		result, err := chat.History(ctx, c.Client, args[0])
+		result, err := chat.History(ctx, c.Client, args[0], opts)
 		if err != nil {
 			return err
 		}
 		// History is a replay; skip logging to avoid duplicates.
+		if format == outputText {
+			fmt.Print(terminalText(chat.FormatTranscript(result, chat.FormatOptions{
+				RelativeTimes: chatHistoryRelative,
+				AlignAuthors:  true,
+			})))
+			return nil
+		}
+		if format == outputJSON {
+			printJSON(result)
+			return nil
+		}
+		if jsonStreamOutput() {
+			printJSONLines(result.Messages)
+			return nil
+		}
 		printOutput(result, formatChatHistory)
 		return nil
 	},
 }
 
+// chat search
+
+var (
+	chatSearchAlias string
+	chatSearchQuery string
+)
+
+var chatSearchCmd = &cobra.Command{
+	Use:   "search --alias <alias> --query <text>",
+	Short: "Find messages in the conversation with alias",
+	Long: `Find messages in the conversation with alias whose text contains the
+query, ignoring case. On a terminal the matches are highlighted; with
+--json each message carries the byte offsets of its matches.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		alias := strings.TrimSpace(chatSearchAlias)
+		if alias == "" {
+			return usageError("--alias is required")
+		}
+		if strings.TrimSpace(chatSearchQuery) == "" {
+			return usageError("--query is required")
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		c, _, err := resolveClientSelection()
+		if err != nil {
+			return err
+		}
+		result, err := chat.Search(ctx, c.Client, alias, chatSearchQuery)
+		if err != nil {
+			return err
+		}
+		if jsonStreamOutput() {
+			printJSONLines(result.Messages)
+			return nil
+		}
+		if jsonFlag || csvOutput() {
+			printOutput(result, formatChatSearch)
+			return nil
+		}
+		fmt.Print(formatChatSearchStyled(result, !rawOutputFlag && writerSupportsANSI(os.Stdout)))
+		return nil
+	},
+}
+
+// parseHistorySince accepts an RFC 3339 timestamp or a duration such as
+// "15m", read as that long before now.
+func parseHistorySince(value string, now time.Time) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if ts, err := awid.ParseTimestamp(value); err == nil {
+		return ts, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		return time.Time{}, fmt.Errorf("invalid value %q (want an RFC 3339 timestamp or a duration like 15m)", value)
+	}
+	return now.Add(-d), nil
+}
+
 // chat extend-wait
 
 var chatExtendWaitCmd = &cobra.Command{
@@ -301,6 +528,132 @@ var chatExtendWaitCmd = &cobra.Command{
 	},
 }
 
+// chat reply
+
+var chatReplyCmd = &cobra.Command{
+	Use:   "reply <alias> <message>",
+	Short: "Reply in the existing conversation with an agent",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := context.WithTimeout(context.Background(), chat.MaxSendTimeout)
+		defer cancel()
+
+		c, sel, err := resolveClientSelection()
+		if err != nil {
+			return err
+		}
+		result, err := chat.Reply(ctx, c.Client, sel.Alias, args[0], args[1], chatReplyWait, chatStderrCallback)
+		if errors.Is(err, chat.ErrNoConversation) {
+			return fmt.Errorf("%w; start one with `aw chat send-and-wait %s <message>`", err, args[0])
+		}
+		if err != nil {
+			return networkError(err, args[0])
+		}
+		logsDir := defaultLogsDir()
+		myAddr := selectionAddress(sel)
+		logName := commLogNameForSelection(sel)
+		appendCommLog(logsDir, logName, &CommLogEntry{
+			Timestamp: time.Now().UTC().Format(time.RFC3339),
+			Dir:       "send",
+			Channel:   "chat",
+			SessionID: result.SessionID,
+			From:      myAddr,
+			To:        args[0],
+			Body:      args[1],
+		})
+		appendInteractionLogForCWD(&InteractionEntry{
+			Timestamp: time.Now().UTC().Format(time.RFC3339),
+			Kind:      interactionKindChatOut,
+			SessionID: result.SessionID,
+			To:        args[0],
+			Text:      args[1],
+		})
+		logChatEvents(logsDir, logName, myAddr, result.Events, selectionIdentityDIDs(sel)...)
+		printOutput(result, formatChatSend)
+		return nil
+	},
+}
+
+// chat leave
+
+var chatLeaveCmd = &cobra.Command{
+	Use:   "leave <alias>",
+	Short: "Leave a conversation without sending a message",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		c, err := resolveClient()
+		if err != nil {
+			return err
+		}
+		result, err := chat.Leave(ctx, c.Client, args[0])
+		if err != nil {
+			return err
+		}
+		printOutput(result, formatChatLeave)
+		return nil
+	},
+}
+
+// chat nudge
+
+var chatNudgeCmd = &cobra.Command{
+	Use:   "nudge <alias>",
+	Short: "Notify a conversation's unread participants again",
+	Long: `Notify again the participants of the conversation with alias who have
+not read its latest message. The same conversation can be nudged at most
+once a minute from this workspace.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		c, err := resolveClient()
+		if err != nil {
+			return err
+		}
+		result, err := chat.Nudge(ctx, c.Client, args[0])
+		if err != nil {
+			if code, ok := awid.HTTPStatusCode(err); ok && (code == 404 || code == 405) {
+				return errors.New("chat nudge is not supported by the current backend")
+			}
+			return err
+		}
+		printOutput(result, formatChatNudge)
+		return nil
+	},
+}
+
+// chat mark-unread
+
+var chatMarkUnreadFrom string
+
+var chatMarkUnreadCmd = &cobra.Command{
+	Use:   "mark-unread <alias>",
+	Short: "Mark a conversation unread again",
+	Long: `Mark messages in the conversation with alias unread again so it shows up
+in aw chat pending. By default the latest message from alias is marked
+unread; --from marks that message and every later one.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		c, err := resolveClient()
+		if err != nil {
+			return err
+		}
+		result, err := chat.MarkUnread(ctx, c.Client, args[0], chatMarkUnreadFrom)
+		if err != nil {
+			return err
+		}
+		printOutput(result, formatChatMarkUnread)
+		return nil
+	},
+}
+
 // chat listen
 
 var chatListenCmd = &cobra.Command{
@@ -316,7 +669,53 @@ var chatListenCmd = &cobra.Command{
 		if err != nil {
 			return err
 		}
-		result, err := chat.Listen(ctx, c.Client, args[0], chatListenWait, chatStderrCallback)
+		wait, err := awconfig.ResolveChatWait(sel, chatListenWait, cmd.Flags().Changed("wait"))
+		if err != nil {
+			return err
+		}
+		result, err := chat.Listen(ctx, c.Client, args[0], wait, chatStderrCallback)
+		if err != nil {
+			return err
+		}
+		logsDir := defaultLogsDir()
+		myAddr := selectionAddress(sel)
+		logChatEvents(logsDir, commLogNameForSelection(sel), myAddr, result.Events, selectionIdentityDIDs(sel)...)
+		printOutput(result, formatChatSend)
+		return nil
+	},
+}
+
+// chat wait
+
+var (
+	chatWaitSessionID string
+	chatWaitWait      int
+)
+
+var chatWaitCmd = &cobra.Command{
+	Use:   "wait --session-id <id>",
+	Short: "Re-attach to a conversation and wait for the next reply",
+	Long: `Wait for the next message from another participant in an existing session,
+without sending anything. Use it to resume a send-and-wait whose local wait
+was interrupted; the session ID is in the --json output of send-and-wait and
+chat pending.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if strings.TrimSpace(chatWaitSessionID) == "" {
+			return usageError("--session-id is required")
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), chat.MaxSendTimeout)
+		defer cancel()
+
+		c, sel, err := resolveClientSelection()
+		if err != nil {
+			return err
+		}
+		wait, err := awconfig.ResolveChatWait(sel, chatWaitWait, cmd.Flags().Changed("wait"))
+		if err != nil {
+			return err
+		}
+		result, err := chat.Wait(ctx, c.Client, sel.Alias, chatWaitSessionID, wait, chatStderrCallback)
 		if err != nil {
 			return err
 		}
@@ -328,6 +727,43 @@ var chatListenCmd = &cobra.Command{
 	},
 }
 
+// chat follow
+
+var chatFollowNoMarkRead bool
+
+var chatFollowCmd = &cobra.Command{
+	Use:   "follow <alias>",
+	Short: "Print messages in a conversation as they arrive",
+	Long: `Print new messages in the conversation with alias as they arrive, until
+interrupted. The stream reconnects on its own, so a long watch does not drop.
+Messages are marked read as they are printed unless --no-mark-read is set.
+With --json or --output json-stream each message is printed as one JSON
+object per line. Following stops with an error if the server refuses the
+stream for good, for example after the session is deleted.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+
+		c, sel, err := resolveClientSelection()
+		if err != nil {
+			return err
+		}
+		logsDir := defaultLogsDir()
+		myAddr := selectionAddress(sel)
+		logName := commLogNameForSelection(sel)
+		selfDIDs := selectionIdentityDIDs(sel)
+		return chat.Follow(ctx, c.Client, args[0], chat.FollowOptions{NoMarkRead: chatFollowNoMarkRead}, chatStderrCallback, func(ev chat.Event) {
+			logChatEvent(logsDir, logName, myAddr, ev, selfDIDs...)
+			if jsonFlag || jsonStreamOutput() {
+				printJSONLine(ev)
+				return
+			}
+			fmt.Print(terminalText(formatChatEventLine(ev)))
+		})
+	},
+}
+
 // chat show-pending
 
 var chatShowPendingCmd = &cobra.Command{
@@ -355,11 +791,29 @@ var chatShowPendingCmd = &cobra.Command{
 }
 
 func init() {
-	chatSendAndWaitCmd.Flags().IntVar(&chatSendAndWaitWait, "wait", chat.DefaultWait, "Seconds to wait for reply")
+	chatSendAndWaitCmd.Flags().IntVar(&chatSendAndWaitWait, "wait", chat.DefaultWait, "Seconds to wait for reply (overrides "+awconfig.ChatWaitEnvVar+" and default_chat_wait_seconds)")
 	chatSendAndWaitCmd.Flags().BoolVar(&chatSendAndWaitStartConversation, "start-conversation", false, "Start conversation (5min default wait)")
-
-	chatListenCmd.Flags().IntVar(&chatListenWait, "wait", chat.DefaultWait, "Seconds to wait for a message (0 = no wait)")
-
-	chatCmd.AddCommand(chatSendAndWaitCmd, chatSendAndLeaveCmd, chatPendingCmd, chatOpenCmd, chatHistoryCmd, chatExtendWaitCmd, chatShowPendingCmd, chatListenCmd)
+	chatSendAndWaitCmd.Flags().BoolVar(&chatSendAndWaitReuseSession, "reuse-session", false, "Send into an existing conversation with exactly these participants instead of starting another")
+	chatSendAndWaitCmd.Flags().BoolVar(&chatSendAndWaitFailOnNoReply, "fail-on-no-reply", false, "Exit 10 (12 if the target was offline) when no reply arrives")
+	chatSendAndWaitCmd.Flags().StringVar(&chatSendSubject, "subject", "", "Label the conversation in aw chat pending")
+	chatSendAndLeaveCmd.Flags().StringVar(&chatSendSubject, "subject", "", "Label the conversation in aw chat pending")
+	chatSendAndWaitCmd.Flags().StringVar(&chatSendPriority, "priority", "normal", "Priority: low|normal|high|urgent; high and urgent wait longer and nudge unread recipients")
+	chatSendAndLeaveCmd.Flags().StringVar(&chatSendPriority, "priority", "normal", "Priority: low|normal|high|urgent")
+
+	chatOpenCmd.Flags().BoolVar(&chatOpenAll, "all", false, "Open every pending conversation")
+	chatReplyCmd.Flags().IntVar(&chatReplyWait, "wait", 0, "Seconds to wait for their next reply, 0 = no wait")
+	chatListenCmd.Flags().IntVar(&chatListenWait, "wait", chat.DefaultWait, "Seconds to wait for a message, 0 = no wait (overrides "+awconfig.ChatWaitEnvVar+" and default_chat_wait_seconds)")
+	chatMarkUnreadCmd.Flags().StringVar(&chatMarkUnreadFrom, "from", "", "Mark this message ID and every later one unread")
+	chatWaitCmd.Flags().StringVar(&chatWaitSessionID, "session-id", "", "Session to wait in")
+	chatWaitCmd.Flags().IntVar(&chatWaitWait, "wait", chat.DefaultWait, "Seconds to wait for a reply (overrides "+awconfig.ChatWaitEnvVar+" and default_chat_wait_seconds)")
+	chatFollowCmd.Flags().BoolVar(&chatFollowNoMarkRead, "no-mark-read", false, "Leave followed messages unread")
+	chatHistoryCmd.Flags().StringVar(&chatHistorySince, "since", "", "Only show messages after this RFC 3339 time or duration ago (e.g. 15m)")
+	chatHistoryCmd.Flags().StringVar(&chatHistoryAfter, "after", "", "Only show messages after this message ID")
+	chatHistoryCmd.Flags().StringVar(&chatHistoryFormat, "format", "", "Export format: json, or text for a plain transcript to paste into a ticket or prompt")
+	chatHistoryCmd.Flags().BoolVar(&chatHistoryRelative, "relative", false, "With --format text, show relative timestamps (e.g. 5m ago)")
+	chatSearchCmd.Flags().StringVar(&chatSearchAlias, "alias", "", "Agent whose conversation to search")
+	chatSearchCmd.Flags().StringVar(&chatSearchQuery, "query", "", "Text to find, ignoring case")
+
+	chatCmd.AddCommand(chatSendAndWaitCmd, chatSendAndLeaveCmd, chatPendingCmd, chatListCmd, chatOpenCmd, chatHistoryCmd, chatSearchCmd, chatFollowCmd, chatReplyCmd, chatExtendWaitCmd, chatLeaveCmd, chatNudgeCmd, chatMarkUnreadCmd, chatShowPendingCmd, chatListenCmd, chatWaitCmd)
 	rootCmd.AddCommand(chatCmd)
 }
@@ -0,0 +1,242 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+
+	"github.com/awebai/aw/chat"
+	"github.com/spf13/cobra"
+)
+
+// chat repl
+
+var chatREPLAlias string
+
+var chatREPLCmd = &cobra.Command{
+	Use:   "repl --alias <alias>",
+	Short: "Chat interactively with an agent",
+	Long: `Open the conversation with alias and chat interactively: incoming messages
+are printed as they arrive and each line typed is sent as a message. When
+there is no conversation yet, the first line starts one.
+
+Lines starting with / are commands:
+  /history  print the conversation so far
+  /nudge    notify participants who have not read the latest message
+  /leave    leave the conversation and exit
+  /help     list these commands
+Start a line with // to send a message that begins with /. End of input
+(Ctrl-D) or Ctrl-C exits without leaving the conversation.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		target := strings.TrimSpace(chatREPLAlias)
+		if target == "" {
+			return usageError("missing required flag: --alias")
+		}
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+
+		c, sel, err := resolveClientSelectionForAliasTarget(ctx, target)
+		if err != nil {
+			return err
+		}
+		logsDir := defaultLogsDir()
+		myAddr := selectionAddress(sel)
+		logName := commLogNameForSelection(sel)
+		selfDIDs := selectionIdentityDIDs(sel)
+
+		sent := false
+		repl := &chatREPL{
+			target: target,
+			in:     cmd.InOrStdin(),
+			out:    cmd.OutOrStdout(),
+			send: func(ctx context.Context, body string) error {
+				_, err := chat.Send(ctx, c.Client, sel.Alias, []string{target}, body, chat.SendOptions{
+					WaitExplicit:    true,
+					SkipAliasCheck:  sent,
+					ExistingSession: chat.ReuseSession,
+				}, chatStderrCallback)
+				if err == nil {
+					sent = true
+				}
+				return err
+			},
+			follow: func(ctx context.Context, onMessage func(chat.Event)) error {
+				return chat.Follow(ctx, c.Client, target, chat.FollowOptions{}, chatStderrCallback, func(ev chat.Event) {
+					logChatEvent(logsDir, logName, myAddr, ev, selfDIDs...)
+					if chatEventIsFromSelf(ev, myAddr, selfDIDs...) {
+						return
+					}
+					onMessage(ev)
+				})
+			},
+			history: func(ctx context.Context) (*chat.HistoryResult, error) {
+				return chat.History(ctx, c.Client, target, chat.HistoryOptions{})
+			},
+			leave: func(ctx context.Context) (*chat.LeaveResult, error) {
+				return chat.Leave(ctx, c.Client, target)
+			},
+			nudge: func(ctx context.Context) (*chat.NudgeResult, error) {
+				return chat.Nudge(ctx, c.Client, target)
+			},
+		}
+		return repl.run(ctx)
+	},
+}
+
+// chatREPL runs an interactive conversation: a follow stream printing
+// incoming messages and a stdin loop sending lines, both stopped by the
+// same context. The chat operations are fields so tests can stub them.
+type chatREPL struct {
+	target string
+	in     io.Reader
+	out    io.Writer
+
+	send    func(ctx context.Context, body string) error
+	follow  func(ctx context.Context, onMessage func(chat.Event)) error
+	history func(ctx context.Context) (*chat.HistoryResult, error)
+	leave   func(ctx context.Context) (*chat.LeaveResult, error)
+	nudge   func(ctx context.Context) (*chat.NudgeResult, error)
+
+	mu sync.Mutex // serializes writes to out from the follow goroutine
+}
+
+const chatREPLHelp = "Commands: /history, /nudge, /leave, /help. Start a line with // to send a message beginning with /.\n"
+
+func (r *chatREPL) printf(format string, args ...any) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	fmt.Fprintf(r.out, format, args...)
+}
+
+func (r *chatREPL) run(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Reading stdin cannot be interrupted, so the reader goroutine is left
+	// blocked when the REPL exits for another reason; the process ends soon
+	// after.
+	lines := make(chan string)
+	readErr := make(chan error, 1)
+	go func() {
+		scanner := bufio.NewScanner(r.in)
+		for scanner.Scan() {
+			select {
+			case lines <- scanner.Text():
+			case <-ctx.Done():
+				return
+			}
+		}
+		readErr <- scanner.Err()
+	}()
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	defer cancel()
+	followErr := make(chan error, 1)
+	following := false
+	startFollow := func() {
+		if following {
+			return
+		}
+		following = true
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			followErr <- r.follow(ctx, func(ev chat.Event) {
+				r.printf("%s", terminalText(formatChatEventLine(ev)))
+			})
+		}()
+	}
+
+	if _, err := r.history(ctx); err != nil {
+		if !errors.Is(err, chat.ErrNoConversation) {
+			return err
+		}
+		r.printf("No conversation with %s yet; the first line you type starts one.\n", r.target)
+	} else {
+		r.printf("Chatting with %s. /help lists commands.\n", r.target)
+		startFollow()
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-followErr:
+			return err
+		case err := <-readErr:
+			return err
+		case line := <-lines:
+			done, err := r.handleLine(ctx, line)
+			if err != nil {
+				r.printf("error: %v\n", err)
+				continue
+			}
+			if done {
+				return nil
+			}
+			if !following && strings.TrimSpace(line) != "" && !isChatREPLCommand(line) {
+				startFollow()
+			}
+		}
+	}
+}
+
+func isChatREPLCommand(line string) bool {
+	line = strings.TrimSpace(line)
+	return strings.HasPrefix(line, "/") && !strings.HasPrefix(line, "//")
+}
+
+// handleLine sends line or runs the command it names. done reports that
+// the REPL should exit.
+func (r *chatREPL) handleLine(ctx context.Context, line string) (done bool, err error) {
+	trimmed := strings.TrimSpace(line)
+	if trimmed == "" {
+		return false, nil
+	}
+	if !isChatREPLCommand(trimmed) {
+		if strings.HasPrefix(trimmed, "//") {
+			line = strings.Replace(line, "//", "/", 1)
+		}
+		return false, r.send(ctx, line)
+	}
+
+	switch strings.Fields(trimmed)[0] {
+	case "/history":
+		result, err := r.history(ctx)
+		if err != nil {
+			return false, err
+		}
+		r.printf("%s", terminalText(chat.FormatTranscript(result, chat.FormatOptions{})))
+	case "/nudge":
+		result, err := r.nudge(ctx)
+		if err != nil {
+			return false, err
+		}
+		r.printf("%s", formatChatNudge(result))
+	case "/leave":
+		result, err := r.leave(ctx)
+		if err != nil {
+			return false, err
+		}
+		r.printf("%s", formatChatLeave(result))
+		return true, nil
+	case "/help":
+		r.printf("%s", chatREPLHelp)
+	default:
+		r.printf("unknown command %s. %s", strings.Fields(trimmed)[0], chatREPLHelp)
+	}
+	return false, nil
+}
+
+func init() {
+	chatREPLCmd.Flags().StringVar(&chatREPLAlias, "alias", "", "Agent to chat with")
+	chatCmd.AddCommand(chatREPLCmd)
+}
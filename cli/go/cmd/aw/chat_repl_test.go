@@ -0,0 +1,136 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/awebai/aw/chat"
+)
+
+func TestChatREPLStartsConversationFollowsAndRunsCommands(t *testing.T) {
+	t.Parallel()
+
+	var sent []string
+	var followStopped atomic.Bool
+	var out bytes.Buffer
+	hasSession := false
+	repl := &chatREPL{
+		target: "bob",
+		in:     strings.NewReader("hello\n\n//etc/hosts is odd\n/history\n/bogus\n/leave\nnot sent\n"),
+		out:    &out,
+		send: func(_ context.Context, body string) error {
+			sent = append(sent, body)
+			hasSession = true
+			return nil
+		},
+		follow: func(ctx context.Context, onMessage func(chat.Event)) error {
+			onMessage(chat.Event{FromAgent: "bob", Body: "hi back"})
+			<-ctx.Done()
+			followStopped.Store(true)
+			return nil
+		},
+		history: func(context.Context) (*chat.HistoryResult, error) {
+			if !hasSession {
+				return nil, fmt.Errorf("%w with bob", chat.ErrNoConversation)
+			}
+			return &chat.HistoryResult{Messages: []chat.Event{{FromAgent: "alice", Body: "hello", Timestamp: "2026-03-01T12:00:00Z"}}}, nil
+		},
+		leave: func(context.Context) (*chat.LeaveResult, error) {
+			return &chat.LeaveResult{TargetAgent: "bob"}, nil
+		},
+		nudge: func(context.Context) (*chat.NudgeResult, error) {
+			t.Error("unexpected nudge")
+			return nil, nil
+		},
+	}
+
+	errc := make(chan error, 1)
+	go func() { errc <- repl.run(context.Background()) }()
+	select {
+	case err := <-errc:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("repl did not exit after /leave")
+	}
+
+	if got := strings.Join(sent, "|"); got != "hello|/etc/hosts is odd" {
+		t.Fatalf("sent=%q", got)
+	}
+	if !followStopped.Load() {
+		t.Fatal("follow was not cancelled on exit")
+	}
+	got := out.String()
+	for _, want := range []string{
+		"No conversation with bob yet",
+		"bob: hi back\n",
+		"[2026-03-01T12:00:00Z] alice: hello\n",
+		"unknown command /bogus",
+		"Left conversation with bob\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("output missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestChatREPLReportsCommandErrorsAndExitsOnEOF(t *testing.T) {
+	t.Parallel()
+
+	var out bytes.Buffer
+	repl := &chatREPL{
+		target: "bob",
+		in:     strings.NewReader("/nudge\n"),
+		out:    &out,
+		send:   func(context.Context, string) error { return nil },
+		follow: func(ctx context.Context, _ func(chat.Event)) error {
+			<-ctx.Done()
+			return nil
+		},
+		history: func(context.Context) (*chat.HistoryResult, error) { return &chat.HistoryResult{}, nil },
+		leave:   func(context.Context) (*chat.LeaveResult, error) { return &chat.LeaveResult{}, nil },
+		nudge: func(context.Context) (*chat.NudgeResult, error) {
+			return nil, chat.ErrNudgeTooSoon
+		},
+	}
+
+	if err := repl.run(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "error: "+chat.ErrNudgeTooSoon.Error()) {
+		t.Fatalf("output=%q", out.String())
+	}
+}
+
+func TestChatREPLEscapesControlCharactersInIncomingMessages(t *testing.T) {
+	t.Parallel()
+
+	var out bytes.Buffer
+	repl := &chatREPL{
+		target: "bob",
+		in:     strings.NewReader(""),
+		out:    &out,
+		send:   func(context.Context, string) error { return nil },
+		follow: func(ctx context.Context, onMessage func(chat.Event)) error {
+			onMessage(chat.Event{FromAgent: "bob", Body: "\x1b[2Jgotcha\x07"})
+			return nil
+		},
+		history: func(context.Context) (*chat.HistoryResult, error) { return &chat.HistoryResult{}, nil },
+		leave:   func(context.Context) (*chat.LeaveResult, error) { return &chat.LeaveResult{}, nil },
+		nudge:   func(context.Context) (*chat.NudgeResult, error) { return &chat.NudgeResult{}, nil },
+	}
+
+	if err := repl.run(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	got := out.String()
+	if strings.ContainsAny(got, "\x1b\x07") || !strings.Contains(got, `bob: \x1b[2Jgotcha\x07`) {
+		t.Fatalf("output=%q", got)
+	}
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/awebai/aw/chat"
+)
+
+func TestChatSendOutcomeErrorExitCodes(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		name          string
+		result        chat.SendResult
+		failOnNoReply bool
+		want          int
+	}{
+		{"replied", chat.SendResult{Status: chat.StatusReplied}, true, 0},
+		{"sender left", chat.SendResult{Status: chat.StatusSenderLeft}, true, 0},
+		{"timeout", chat.SendResult{Status: chat.StatusTimeout}, false, 0},
+		{"timeout failing", chat.SendResult{Status: chat.StatusTimeout}, true, chatExitNoReply},
+		{"sent without wait failing", chat.SendResult{Status: chat.StatusSent}, true, chatExitNoReply},
+		{"offline timeout", chat.SendResult{Status: chat.StatusTimeout, TargetNotConnected: true}, false, 0},
+		{"offline timeout failing", chat.SendResult{Status: chat.StatusTimeout, TargetNotConnected: true}, true, chatExitTargetOffline},
+		{"targets left", chat.SendResult{Status: chat.StatusTargetsLeft}, false, chatExitTargetsLeft},
+		{"target offline", chat.SendResult{Status: chat.StatusTargetOffline}, false, chatExitTargetOffline},
+	}
+	for _, tc := range cases {
+		err := chatSendOutcomeError(&tc.result, "bob", tc.failOnNoReply)
+		got := 0
+		if err != nil {
+			got = exitCode(err)
+		}
+		if got != tc.want {
+			t.Errorf("%s: exit code %d (err=%v), want %d", tc.name, got, err, tc.want)
+		}
+	}
+}
@@ -0,0 +1,495 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/awebai/aw/awconfig"
+	awrun "github.com/awebai/aw/run"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// configBundleVersion is bumped when the bundle layout changes
+// incompatibly.
+const configBundleVersion = 1
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Move aw configuration between machines",
+}
+
+var configExportIncludeSecrets bool
+
+var configExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Print a portable bundle of servers, run settings and worktree context",
+	Long: `Print the configuration a new machine needs as a YAML bundle (JSON with
+--json): the servers ` + "`aw login`" + ` stored API keys for, the run settings in
+~/.config/aw/run.json (or the --config file), and the human account chosen in
+this worktree's .aw/context.
+
+API keys are left out unless --include-secrets is given. A bundle with
+secrets grants access to every server in it; store it like a password and
+delete it once imported. Workspace identities (signing keys and team
+certificates) are never exported.`,
+	Args: cobra.NoArgs,
+	RunE: runConfigExport,
+}
+
+var configImportMerge bool
+
+var configImportCmd = &cobra.Command{
+	Use:   "import <file>",
+	Short: "Load a bundle written by aw config export",
+	Long: `Load a bundle written by ` + "`aw config export`" + `; "-" reads it from stdin.
+
+Each section present in the bundle replaces the stored one: the server API
+keys, the run settings, and this worktree's .aw/context. With --merge the
+bundle's servers are added to the stored ones, its run settings override
+only the fields it sets, and a server without an API key keeps the stored
+key. Sections missing from the bundle are left alone.
+
+The whole bundle is checked before anything is written; a context naming a
+team this worktree has not joined is rejected.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runConfigImport,
+}
+
+var (
+	configSetKeyServer string
+	configSetKeyFile   string
+)
+
+var configSetKeyCmd = &cobra.Command{
+	Use:   "set-key --key-file <path>",
+	Short: "Store a pre-minted API key for aw init",
+	Long: `Store an API key for a server in ~/.config/aw/credentials.yaml, as
+` + "`aw login`" + ` does, reading it from --key-file; "-" reads it from stdin.
+Surrounding whitespace, including a trailing newline, is trimmed. The key
+never appears in shell history or the process list, and the credentials
+file is written with owner-only permissions.
+
+--server defaults to AWEB_URL. A later ` + "`aw init --aweb-url <server>`" + ` uses the
+stored key.`,
+	Args: cobra.NoArgs,
+	RunE: runConfigSetKey,
+}
+
+type configSetKeyOutput struct {
+	Status      string `json:"status"`
+	Server      string `json:"server"`
+	Credentials string `json:"credentials"`
+}
+
+type configBundle struct {
+	Version    int                  `yaml:"version" json:"version"`
+	ExportedAt string               `yaml:"exported_at,omitempty" json:"exported_at,omitempty"`
+	Servers    []configBundleServer `yaml:"servers,omitempty" json:"servers,omitempty"`
+	Run        map[string]any       `yaml:"run,omitempty" json:"run,omitempty"`
+	Context    *configBundleContext `yaml:"context,omitempty" json:"context,omitempty"`
+}
+
+type configBundleServer struct {
+	URL        string `yaml:"url" json:"url"`
+	APIKey     string `yaml:"api_key,omitempty" json:"api_key,omitempty"`
+	ObtainedAt string `yaml:"obtained_at,omitempty" json:"obtained_at,omitempty"`
+}
+
+type configBundleContext struct {
+	HumanAccount string `yaml:"human_account,omitempty" json:"human_account,omitempty"`
+}
+
+type configImportOutput struct {
+	Status      string `json:"status"`
+	Mode        string `json:"mode"`
+	Servers     int    `json:"servers"`
+	Credentials string `json:"credentials,omitempty"`
+	RunConfig   string `json:"run_config,omitempty"`
+	Context     string `json:"context,omitempty"`
+}
+
+func init() {
+	configExportCmd.Flags().BoolVar(&configExportIncludeSecrets, "include-secrets", false, "Include API keys in the bundle (treat the output as a secret)")
+	configImportCmd.Flags().BoolVar(&configImportMerge, "merge", false, "Merge into the existing configuration instead of replacing it")
+	configSetKeyCmd.Flags().StringVar(&configSetKeyServer, "server", "", "aweb server URL the key is for (defaults to AWEB_URL)")
+	configSetKeyCmd.Flags().StringVar(&configSetKeyFile, "key-file", "", `File holding the API key; "-" reads stdin`)
+	configCmd.AddCommand(configExportCmd, configImportCmd, configSetKeyCmd)
+	configCmd.GroupID = groupUtility
+	rootCmd.AddCommand(configCmd)
+}
+
+func runConfigExport(cmd *cobra.Command, args []string) error {
+	credentialsPath, err := awconfig.DefaultCredentialsPath()
+	if err != nil {
+		return err
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	bundle, err := buildConfigBundle(credentialsPath, configFlag, wd, configExportIncludeSecrets)
+	if err != nil {
+		return err
+	}
+	if configExportIncludeSecrets && len(bundle.Servers) > 0 {
+		fmt.Fprintln(os.Stderr, "Warning: this bundle contains API keys. Anyone holding it can act on those servers; store it like a password and delete it once imported.")
+	}
+	printOutput(bundle, formatConfigBundle)
+	return nil
+}
+
+func runConfigImport(cmd *cobra.Command, args []string) error {
+	var (
+		data []byte
+		err  error
+	)
+	if args[0] == "-" {
+		data, err = io.ReadAll(cmd.InOrStdin())
+	} else {
+		data, err = os.ReadFile(args[0])
+	}
+	if err != nil {
+		return err
+	}
+	bundle, err := parseConfigBundle(data)
+	if err != nil {
+		return err
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	if err := validateConfigBundle(bundle, configImportMerge, wd); err != nil {
+		return err
+	}
+	credentialsPath, err := awconfig.DefaultCredentialsPath()
+	if err != nil {
+		return err
+	}
+	out, err := applyConfigBundle(bundle, configImportMerge, credentialsPath, configFlag, wd)
+	if err != nil {
+		return err
+	}
+	printOutput(out, formatConfigImport)
+	return nil
+}
+
+func runConfigSetKey(cmd *cobra.Command, args []string) error {
+	raw := strings.TrimSpace(configSetKeyServer)
+	if raw == "" {
+		raw = envAwebURL()
+	}
+	if raw == "" {
+		return usageError("--server or AWEB_URL is required")
+	}
+	server, err := normalizeAPIKeyBootstrapBaseURL(raw)
+	if err != nil {
+		return usageError("invalid --server %q: %v", raw, err)
+	}
+	if strings.TrimSpace(configSetKeyFile) == "" {
+		return usageError("--key-file is required; use - to read the key from stdin")
+	}
+	var apiKey string
+	if configSetKeyFile == "-" {
+		apiKey, err = readAPIKey(cmd.InOrStdin())
+	} else {
+		var f *os.File
+		if f, err = os.Open(configSetKeyFile); err != nil {
+			return err
+		}
+		defer f.Close()
+		apiKey, err = readAPIKey(f)
+	}
+	if err != nil {
+		return err
+	}
+
+	path, err := awconfig.DefaultCredentialsPath()
+	if err != nil {
+		return err
+	}
+	err = awconfig.UpdateCredentialsAt(path, func(creds *awconfig.Credentials) error {
+		creds.SetAPIKey(server, apiKey, time.Now().UTC().Format(time.RFC3339))
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("write %s: %w", path, err)
+	}
+	printOutput(configSetKeyOutput{Status: "stored", Server: server, Credentials: path}, formatConfigSetKey)
+	return nil
+}
+
+// readAPIKey reads an API key from r, trimming surrounding whitespace so a
+// key piped from echo or a file with a trailing newline is accepted.
+func readAPIKey(r io.Reader) (string, error) {
+	data, err := io.ReadAll(io.LimitReader(r, maxWorkspaceAPIKeyLength+1024))
+	if err != nil {
+		return "", fmt.Errorf("read API key: %w", err)
+	}
+	apiKey := strings.TrimSpace(string(data))
+	switch {
+	case apiKey == "":
+		return "", usageError("no API key was provided")
+	case len(apiKey) > maxWorkspaceAPIKeyLength:
+		return "", usageError("API key exceeds %d bytes", maxWorkspaceAPIKeyLength)
+	case strings.ContainsAny(apiKey, " \t\r\n"):
+		return "", usageError("API key must be a single token without whitespace")
+	}
+	return apiKey, nil
+}
+
+// buildConfigBundle collects the portable configuration: the credentials
+// at credentialsPath, the user's own run config layer for configPath, and
+// the context of the worktree at workingDir, if any.
+func buildConfigBundle(credentialsPath, configPath, workingDir string, includeSecrets bool) (*configBundle, error) {
+	bundle := &configBundle{
+		Version:    configBundleVersion,
+		ExportedAt: time.Now().UTC().Format(time.RFC3339),
+	}
+
+	creds, err := awconfig.LoadCredentialsFrom(credentialsPath)
+	if err != nil {
+		return nil, err
+	}
+	urls := make([]string, 0, len(creds.Servers))
+	for url := range creds.Servers {
+		urls = append(urls, url)
+	}
+	sort.Strings(urls)
+	for _, url := range urls {
+		server := configBundleServer{URL: url, ObtainedAt: creds.Servers[url].ObtainedAt}
+		if includeSecrets {
+			server.APIKey = creds.APIKeyFor(url)
+		}
+		bundle.Servers = append(bundle.Servers, server)
+	}
+
+	runCfg, _, err := awrun.ReadUserConfig(configPath)
+	if err != nil {
+		return nil, err
+	}
+	if bundle.Run, err = userConfigMap(runCfg); err != nil {
+		return nil, err
+	}
+
+	ctx, _, err := awconfig.LoadWorktreeContextFromDir(workingDir)
+	switch {
+	case err == nil && strings.TrimSpace(ctx.HumanAccount) != "":
+		bundle.Context = &configBundleContext{HumanAccount: strings.TrimSpace(ctx.HumanAccount)}
+	case err != nil && !errors.Is(err, os.ErrNotExist):
+		return nil, err
+	}
+	return bundle, nil
+}
+
+// parseConfigBundle decodes a bundle in either YAML or JSON, rejecting
+// unknown fields so a mistyped section is not silently dropped.
+func parseConfigBundle(data []byte) (*configBundle, error) {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	var bundle configBundle
+	if err := dec.Decode(&bundle); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, usageError("config bundle is empty")
+		}
+		return nil, usageError("invalid config bundle: %v", err)
+	}
+	return &bundle, nil
+}
+
+// validateConfigBundle checks everything applyConfigBundle would write, so
+// a bad bundle changes nothing. It normalizes server URLs in place.
+func validateConfigBundle(bundle *configBundle, merge bool, workingDir string) error {
+	if bundle.Version != configBundleVersion {
+		return usageError("unsupported config bundle version %d (this aw reads version %d)", bundle.Version, configBundleVersion)
+	}
+
+	seen := make(map[string]bool, len(bundle.Servers))
+	for i := range bundle.Servers {
+		server := &bundle.Servers[i]
+		url, err := normalizeAPIKeyBootstrapBaseURL(server.URL)
+		if err != nil {
+			return usageError("bundle server %q: %v", server.URL, err)
+		}
+		if seen[url] {
+			return usageError("bundle lists server %s twice", url)
+		}
+		seen[url] = true
+		server.URL = url
+		server.APIKey = strings.TrimSpace(server.APIKey)
+		if len(server.APIKey) > maxWorkspaceAPIKeyLength {
+			return usageError("bundle api key for %s exceeds %d bytes", url, maxWorkspaceAPIKeyLength)
+		}
+		if server.APIKey == "" && !merge {
+			return usageError("bundle has no API key for %s; export it with --include-secrets, or import with --merge to keep the stored key", url)
+		}
+	}
+
+	if bundle.Run != nil {
+		cfg, err := userConfigFromMap(bundle.Run)
+		if err != nil {
+			return usageError("bundle run settings: %v", err)
+		}
+		if _, err := awrun.ResolveSettings(cfg, awrun.SettingOverrides{}); err != nil {
+			return usageError("bundle run settings: %v", err)
+		}
+	}
+
+	if bundle.Context != nil {
+		account := strings.TrimSpace(bundle.Context.HumanAccount)
+		if account != "" {
+			state, err := awconfig.LoadTeamState(workingDir)
+			if err != nil || state.Membership(account) == nil {
+				return usageError("bundle context names team %s, which this worktree has not joined; join it first or remove context from the bundle", account)
+			}
+		}
+		bundle.Context.HumanAccount = account
+	}
+	return nil
+}
+
+// applyConfigBundle writes a validated bundle. Only the sections the
+// bundle contains are touched.
+func applyConfigBundle(bundle *configBundle, merge bool, credentialsPath, configPath, workingDir string) (configImportOutput, error) {
+	out := configImportOutput{Status: "imported", Mode: "replace", Servers: len(bundle.Servers)}
+	if merge {
+		out.Mode = "merge"
+	}
+
+	if len(bundle.Servers) > 0 {
+		err := awconfig.UpdateCredentialsAt(credentialsPath, func(creds *awconfig.Credentials) error {
+			if !merge {
+				creds.Servers = nil
+			}
+			for _, server := range bundle.Servers {
+				if server.APIKey != "" {
+					creds.SetAPIKey(server.URL, server.APIKey, server.ObtainedAt)
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return out, fmt.Errorf("write %s: %w", credentialsPath, err)
+		}
+		out.Credentials = credentialsPath
+	}
+
+	if bundle.Run != nil {
+		fields := bundle.Run
+		if merge {
+			current, _, err := awrun.ReadUserConfig(configPath)
+			if err != nil {
+				return out, err
+			}
+			if fields, err = userConfigMap(current); err != nil {
+				return out, err
+			}
+			if fields == nil {
+				fields = map[string]any{}
+			}
+			for key, value := range bundle.Run {
+				fields[key] = value
+			}
+		}
+		cfg, err := userConfigFromMap(fields)
+		if err != nil {
+			return out, err
+		}
+		path, err := awrun.WriteUserConfig(configPath, cfg)
+		if err != nil {
+			return out, fmt.Errorf("write %s: %w", path, err)
+		}
+		out.RunConfig = path
+	}
+
+	if bundle.Context != nil && (bundle.Context.HumanAccount != "" || !merge) {
+		path := filepath.Join(workingDir, awconfig.DefaultWorktreeContextRelativePath())
+		ctx, err := awconfig.LoadWorktreeContextFrom(path)
+		if errors.Is(err, os.ErrNotExist) {
+			ctx, err = &awconfig.WorktreeContext{}, nil
+		}
+		if err != nil {
+			return out, err
+		}
+		ctx.HumanAccount = bundle.Context.HumanAccount
+		if err := awconfig.SaveWorktreeContextTo(path, ctx); err != nil {
+			return out, fmt.Errorf("write %s: %w", path, err)
+		}
+		out.Context = path
+	}
+	return out, nil
+}
+
+// userConfigMap returns the fields cfg sets, keyed as in run.json, or nil
+// when it sets none.
+func userConfigMap(cfg awrun.UserConfig) (map[string]any, error) {
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]any
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	for key, value := range fields {
+		if value == nil {
+			delete(fields, key)
+		}
+	}
+	if len(fields) == 0 {
+		return nil, nil
+	}
+	return fields, nil
+}
+
+func userConfigFromMap(fields map[string]any) (awrun.UserConfig, error) {
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return awrun.UserConfig{}, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	var cfg awrun.UserConfig
+	if err := dec.Decode(&cfg); err != nil {
+		return awrun.UserConfig{}, err
+	}
+	return cfg, nil
+}
+
+func formatConfigBundle(v any) string {
+	data, err := yaml.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("error: %v\n", err)
+	}
+	return string(data)
+}
+
+func formatConfigImport(v any) string {
+	out := v.(configImportOutput)
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Imported config bundle (%s)\n", out.Mode)
+	if out.Credentials != "" {
+		fmt.Fprintf(&sb, "  servers:    %d -> %s\n", out.Servers, out.Credentials)
+	}
+	if out.RunConfig != "" {
+		fmt.Fprintf(&sb, "  run config: %s\n", out.RunConfig)
+	}
+	if out.Context != "" {
+		fmt.Fprintf(&sb, "  context:    %s\n", out.Context)
+	}
+	return sb.String()
+}
+
+func formatConfigSetKey(v any) string {
+	out := v.(configSetKeyOutput)
+	return fmt.Sprintf("API key for %s stored in %s\nRun `aw init --aweb-url %s` to create a workspace with it.\n", out.Server, out.Credentials, out.Server)
+}
@@ -0,0 +1,255 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/awebai/aw/awconfig"
+	awrun "github.com/awebai/aw/run"
+)
+
+// writeConfigFixture sets up credentials, a run config and a worktree that
+// has joined default:acme.com with that team chosen in .aw/context.
+func writeConfigFixture(t *testing.T, dir string) (credentialsPath, runConfigPath string) {
+	t.Helper()
+	credentialsPath = filepath.Join(dir, "credentials.yaml")
+	err := awconfig.UpdateCredentialsAt(credentialsPath, func(creds *awconfig.Credentials) error {
+		creds.SetAPIKey("https://app.example.com", "aw_sk_one", "2026-01-01T00:00:00Z")
+		creds.SetAPIKey("https://self.example.org", "aw_sk_two", "")
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	runConfigPath = filepath.Join(dir, "run.json")
+	prompt := "be brief"
+	if _, err := awrun.WriteUserConfig(runConfigPath, awrun.UserConfig{BasePrompt: &prompt}); err != nil {
+		t.Fatal(err)
+	}
+	joinConfigFixtureTeam(t, dir)
+	ctxPath := filepath.Join(dir, awconfig.DefaultWorktreeContextRelativePath())
+	if err := awconfig.SaveWorktreeContextTo(ctxPath, &awconfig.WorktreeContext{HumanAccount: "default:acme.com"}); err != nil {
+		t.Fatal(err)
+	}
+	return credentialsPath, runConfigPath
+}
+
+func joinConfigFixtureTeam(t *testing.T, dir string) {
+	t.Helper()
+	state := &awconfig.TeamState{
+		ActiveTeam:  "default:acme.com",
+		Memberships: []awconfig.TeamMembership{{TeamID: "default:acme.com", Alias: "alice", CertPath: "team-certs/default__acme.com.pem"}},
+	}
+	if err := awconfig.SaveTeamState(dir, state); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestConfigExportOmitsSecretsByDefault(t *testing.T) {
+	t.Parallel()
+	src := t.TempDir()
+	credentialsPath, runConfigPath := writeConfigFixture(t, src)
+
+	bundle, err := buildConfigBundle(credentialsPath, runConfigPath, src, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bundle.Servers) != 2 || bundle.Servers[0].URL != "https://app.example.com" || bundle.Servers[0].ObtainedAt == "" {
+		t.Fatalf("servers=%+v", bundle.Servers)
+	}
+	text := formatConfigBundle(bundle)
+	if strings.Contains(text, "aw_sk_") || strings.Contains(text, "api_key") {
+		t.Fatalf("bundle leaks secrets:\n%s", text)
+	}
+	if bundle.Run["base_prompt"] != "be brief" || len(bundle.Run) != 1 {
+		t.Fatalf("run=%v", bundle.Run)
+	}
+	if bundle.Context == nil || bundle.Context.HumanAccount != "default:acme.com" {
+		t.Fatalf("context=%+v", bundle.Context)
+	}
+}
+
+func TestConfigExportImportRoundTrip(t *testing.T) {
+	t.Parallel()
+	src := t.TempDir()
+	credentialsPath, runConfigPath := writeConfigFixture(t, src)
+	bundle, err := buildConfigBundle(credentialsPath, runConfigPath, src, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dst := t.TempDir()
+	joinConfigFixtureTeam(t, dst)
+	dstCredentials := filepath.Join(dst, "credentials.yaml")
+	if err := awconfig.UpdateCredentialsAt(dstCredentials, func(creds *awconfig.Credentials) error {
+		creds.SetAPIKey("https://stale.example.net", "aw_sk_stale", "")
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	dstRunConfig := filepath.Join(dst, "run.json")
+
+	parsed, err := parseConfigBundle([]byte(formatConfigBundle(bundle)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := validateConfigBundle(parsed, false, dst); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := applyConfigBundle(parsed, false, dstCredentials, dstRunConfig, dst); err != nil {
+		t.Fatal(err)
+	}
+
+	creds, err := awconfig.LoadCredentialsFrom(dstCredentials)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if creds.APIKeyFor("https://app.example.com") != "aw_sk_one" || creds.APIKeyFor("https://self.example.org") != "aw_sk_two" {
+		t.Fatalf("credentials=%+v", creds.Servers)
+	}
+	if creds.APIKeyFor("https://stale.example.net") != "" {
+		t.Fatal("replace kept a server missing from the bundle")
+	}
+	cfg, _, err := awrun.ReadUserConfig(dstRunConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.BasePrompt == nil || *cfg.BasePrompt != "be brief" {
+		t.Fatalf("run config=%+v", cfg)
+	}
+	ctx, _, err := awconfig.LoadWorktreeContextFromDir(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ctx.HumanAccount != "default:acme.com" {
+		t.Fatalf("context=%+v", ctx)
+	}
+}
+
+func TestConfigImportMergeKeepsStoredKeysAndSettings(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	credentialsPath, runConfigPath := writeConfigFixture(t, dir)
+
+	bundle, err := parseConfigBundle([]byte(`version: 1
+servers:
+  - url: https://app.example.com/api/
+  - url: https://new.example.com
+    api_key: aw_sk_new
+run:
+  wait_seconds: 5
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := validateConfigBundle(bundle, true, dir); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := applyConfigBundle(bundle, true, credentialsPath, runConfigPath, dir); err != nil {
+		t.Fatal(err)
+	}
+
+	creds, err := awconfig.LoadCredentialsFrom(credentialsPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if creds.APIKeyFor("https://app.example.com") != "aw_sk_one" || creds.APIKeyFor("https://new.example.com") != "aw_sk_new" || creds.APIKeyFor("https://self.example.org") != "aw_sk_two" {
+		t.Fatalf("credentials=%+v", creds.Servers)
+	}
+	cfg, _, err := awrun.ReadUserConfig(runConfigPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.BasePrompt == nil || *cfg.BasePrompt != "be brief" || cfg.WaitSeconds == nil || *cfg.WaitSeconds != 5 {
+		t.Fatalf("run config=%+v", cfg)
+	}
+}
+
+func TestConfigImportRejectsInvalidBundles(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		name   string
+		bundle string
+		merge  bool
+		want   string
+	}{
+		{"missing key on replace", "version: 1\nservers:\n  - url: https://app.example.com\n", false, "--include-secrets"},
+		{"unknown team", "version: 1\ncontext:\n  human_account: default:other.com\n", true, "has not joined"},
+		{"bad run settings", "version: 1\nrun:\n  wait_seconds: -1\n", true, "wait_seconds"},
+		{"unknown run field", "version: 1\nrun:\n  wait_secs: 5\n", true, "wait_secs"},
+		{"wrong version", "version: 9\n", true, "version 9"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			dir := t.TempDir()
+			joinConfigFixtureTeam(t, dir)
+			bundle, err := parseConfigBundle([]byte(tc.bundle))
+			if err == nil {
+				err = validateConfigBundle(bundle, tc.merge, dir)
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Fatalf("err=%v, want mention of %q", err, tc.want)
+			}
+		})
+	}
+
+	if _, err := parseConfigBundle([]byte("version: 1\nserverz: []\n")); err == nil {
+		t.Fatal("unknown section accepted")
+	}
+}
+
+func TestConfigSetKeyReadsKeyFromStdin(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	tmp := t.TempDir()
+	bin := filepath.Join(tmp, "aw")
+	buildAwBinary(t, ctx, bin)
+
+	run := exec.CommandContext(ctx, bin, "config", "set-key", "--server", "https://keys.example.com/api", "--key-file", "-")
+	run.Env = append(testCommandEnv(tmp), "AWEB_URL=")
+	run.Dir = tmp
+	run.Stdin = strings.NewReader("  aw_sk_piped\n\n")
+	out, err := run.CombinedOutput()
+	if err != nil {
+		t.Fatalf("run failed: %v\n%s", err, out)
+	}
+	if strings.Contains(string(out), "aw_sk_piped") {
+		t.Fatalf("output echoes the key:\n%s", out)
+	}
+
+	path := filepath.Join(tmp, ".config", "aw", "credentials.yaml")
+	creds, err := awconfig.LoadCredentialsFrom(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := creds.APIKeyFor("https://keys.example.com"); got != "aw_sk_piped" {
+		t.Fatalf("stored key=%q", got)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mode := info.Mode().Perm(); mode != 0o600 {
+		t.Fatalf("credentials mode=%o, want 600", mode)
+	}
+}
+
+func TestReadAPIKeyTrimsAndRejectsBadInput(t *testing.T) {
+	t.Parallel()
+	if key, err := readAPIKey(strings.NewReader("aw_sk_one\r\n")); err != nil || key != "aw_sk_one" {
+		t.Fatalf("key=%q err=%v", key, err)
+	}
+	for _, input := range []string{"", " \n", "aw_sk_one aw_sk_two", strings.Repeat("k", maxWorkspaceAPIKeyLength+1)} {
+		if _, err := readAPIKey(strings.NewReader(input)); err == nil || exitCode(err) != 2 {
+			t.Fatalf("input %.20q: err=%v, want a usage error", input, err)
+		}
+	}
+}
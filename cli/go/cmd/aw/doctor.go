@@ -47,7 +47,6 @@ const (
 )
 
 var (
-	doctorVerbose bool
 	doctorOffline bool
 	doctorOnline  bool
 	doctorFixFlag bool
@@ -71,7 +70,7 @@ var doctorCmd = &cobra.Command{
 		return runDoctorCommand(cmd, doctorRunOptions{
 			Categories: []string{"local", "identity", "workspace", "team", "registry", "messaging"},
 			Mode:       selectedDoctorMode(),
-			Verbose:    doctorVerbose,
+			Verbose:    verboseLevel > 0,
 			Fix:        doctorFixFlag,
 			DryRun:     doctorDryRun,
 			FixTarget:  fixTarget,
@@ -90,7 +89,7 @@ var doctorSupportBundleCmd = &cobra.Command{
 		return runDoctorSupportBundle(cmd, doctorRunOptions{
 			Categories: []string{"local", "identity", "workspace", "team", "registry", "messaging"},
 			Mode:       selectedDoctorMode(),
-			Verbose:    doctorVerbose,
+			Verbose:    verboseLevel > 0,
 		})
 	},
 }
@@ -184,7 +183,6 @@ type doctorRedaction struct {
 }
 
 func init() {
-	doctorCmd.PersistentFlags().BoolVar(&doctorVerbose, "verbose", false, "Include verbose diagnostic details")
 	doctorCmd.PersistentFlags().BoolVar(&doctorOffline, "offline", false, "Run without network checks")
 	doctorCmd.PersistentFlags().BoolVar(&doctorOnline, "online", false, "Allow online checks")
 	doctorCmd.Flags().BoolVar(&doctorFixFlag, "fix", false, "Apply safe doctor fixes")
@@ -200,7 +198,7 @@ func init() {
 				return runDoctorCommand(cmd, doctorRunOptions{
 					Categories: []string{category},
 					Mode:       selectedDoctorMode(),
-					Verbose:    doctorVerbose,
+					Verbose:    verboseLevel > 0,
 				})
 			},
 		})
@@ -237,9 +235,22 @@ func runDoctorCommand(cmd *cobra.Command, opts doctorRunOptions) error {
 	}
 	out := buildDoctorOutput(opts)
 	printOutput(out, formatDoctorOutput)
+	if out.Status == doctorStatusFail {
+		return &cliError{code: 1, msg: fmt.Sprintf("doctor: %d check(s) failed", countDoctorChecks(out.Checks, doctorStatusFail))}
+	}
 	return nil
 }
 
+func countDoctorChecks(checks []doctorCheck, status doctorStatus) int {
+	n := 0
+	for _, check := range checks {
+		if check.Status == status {
+			n++
+		}
+	}
+	return n
+}
+
 func runDoctorSupportBundle(cmd *cobra.Command, opts doctorRunOptions) error {
 	if err := validateDoctorModeFlags(); err != nil {
 		return err
@@ -383,7 +394,8 @@ func collectDoctorSubject(workingDir string) doctorSubject {
 		ServerName:        serverFlag,
 		TeamIDOverride:    strings.TrimSpace(teamFlag),
 		WorkingDir:        subject.WorkingDir,
-		AllowEnvOverrides: true,
+		UseHumanAccount:   asHumanFlag,
+		AllowEnvOverrides: !noEnvFlag,
 	}); err == nil && sel != nil {
 		if awebURL, urlErr := sanitizeLocalURLForOutput(sel.BaseURL); urlErr == nil {
 			subject.AwebURL = awebURL
@@ -459,7 +471,7 @@ func formatDoctorOutput(v any) string {
 		sb.WriteString(fmt.Sprintf("  [%s] %s — %s\n", check.Status, check.ID, check.Message))
 		if check.Handoff != nil {
 			sb.WriteString(fmt.Sprintf("        handoff: %s\n", conciseDoctorHandoffLine(check.Handoff)))
-			if doctorVerbose {
+			if verboseLevel > 0 {
 				sb.WriteString(fmt.Sprintf("        authority: %s (%s)\n", check.Handoff.RequiredAuthority, check.Handoff.CallerAuthorityStatus))
 				if len(check.Handoff.CallerAuthorityEvidence) > 0 {
 					sb.WriteString(fmt.Sprintf("        evidence: %s\n", strings.Join(check.Handoff.CallerAuthorityEvidence, ", ")))
@@ -478,7 +490,7 @@ func formatDoctorOutput(v any) string {
 				}
 			}
 		}
-		if doctorVerbose && check.NextStep != "" {
+		if verboseLevel > 0 && check.NextStep != "" {
 			sb.WriteString(fmt.Sprintf("        next: %s\n", check.NextStep))
 		}
 	}
@@ -5,6 +5,7 @@ import (
 	"crypto/ed25519"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"net/url"
@@ -23,6 +24,7 @@ const (
 	doctorCheckServerAwebURLRuntime    = "server.aweb_url.runtime_path"
 	doctorCheckServerReachable         = "server.aweb_url.reachable"
 	doctorCheckServerVersion           = "server.aweb_url.version_header"
+	doctorCheckServerClockSkew         = "server.aweb_url.clock_skew"
 	doctorCheckServerTeamAuth          = "server.auth.team_certificate_request"
 
 	doctorCheckWorkspaceTeamRead       = "workspace.server.team_read"
@@ -72,9 +74,16 @@ type doctorAwebProbeResult struct {
 	RecommendedHTML    bool
 	ConfiguredVersion  string
 	RecommendedVersion string
+	ConfiguredSkew     time.Duration
+	ConfiguredSkewOK   bool
 	Err                error
 }
 
+// doctorClockSkewWarnThreshold is how far the local clock may drift from the
+// server's before doctor warns. Signed requests carry RFC3339 timestamps the
+// server checks against its own clock, and stream deadlines are absolute.
+const doctorClockSkewWarnThreshold = 30 * time.Second
+
 func (r *doctorRunner) runWorkspaceDoctorChecks() {
 	state := collectDoctorAwebState(r.workingDir)
 	r.addServerConfiguredChecks(state, r.opts.Mode != doctorModeOnline)
@@ -82,6 +91,7 @@ func (r *doctorRunner) runWorkspaceDoctorChecks() {
 	networkIDs := []string{
 		doctorCheckServerReachable,
 		doctorCheckServerVersion,
+		doctorCheckServerClockSkew,
 		doctorCheckServerTeamAuth,
 		doctorCheckWorkspaceTeamRead,
 		doctorCheckWorkspaceCurrentRow,
@@ -103,12 +113,12 @@ func (r *doctorRunner) runWorkspaceDoctorChecks() {
 		return
 	}
 	if !r.addOnlineServerProbeChecks(state) {
-		r.addBlockedAwebChecks(networkIDs[2:], doctorCheckServerReachable)
+		r.addBlockedAwebChecks(networkIDs[3:], doctorCheckServerReachable)
 		return
 	}
 	client, prereq := r.doctorTeamClient(state)
 	if prereq != "" {
-		r.addBlockedAwebChecks(networkIDs[2:], prereq)
+		r.addBlockedAwebChecks(networkIDs[3:], prereq)
 		return
 	}
 	r.addWorkspaceOnlineChecks(state, client)
@@ -249,6 +259,7 @@ func (r *doctorRunner) addOnlineServerProbeChecks(state *doctorAwebState) bool {
 	if strings.TrimSpace(state.baseURL) == "" || state.urlErr != nil {
 		r.add(awebCheck(doctorCheckServerReachable, doctorStatusBlocked, nil, "Aweb reachability requires a valid configured aweb_url.", "Resolve server.aweb_url.configured first.", map[string]any{"prerequisite": doctorCheckServerAwebURLConfigured}))
 		r.add(awebCheck(doctorCheckServerVersion, doctorStatusBlocked, nil, "Version header check requires a reachable aweb server.", "Resolve server.aweb_url.reachable first.", map[string]any{"prerequisite": doctorCheckServerReachable}))
+		r.add(awebCheck(doctorCheckServerClockSkew, doctorStatusBlocked, nil, "Clock skew check requires a reachable aweb server.", "Resolve server.aweb_url.reachable first.", map[string]any{"prerequisite": doctorCheckServerReachable}))
 		return false
 	}
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -262,12 +273,14 @@ func (r *doctorRunner) addOnlineServerProbeChecks(state *doctorAwebState) bool {
 		} else {
 			r.add(awebCheck(doctorCheckServerVersion, doctorStatusInfo, nil, "No compatible version header was advertised by the server probe.", "", map[string]any{"present": false}))
 		}
+		r.addServerClockSkewCheck(probe)
 		return true
 	}
 	if probe.RecommendedOK {
 		r.add(awebCheck(doctorCheckServerAwebURLRuntime, doctorStatusWarn, nil, "Configured aweb_url does not appear to be the API runtime, but /api does.", "Update .aw/workspace.yaml to the recommended runtime URL; doctor will not rewrite it automatically.", map[string]any{"reason": "api_runtime_at_recommended_path", "configured_aweb_url": state.baseURL, "recommended_aweb_url": probe.RecommendedURL}))
 		r.add(awebCheck(doctorCheckServerReachable, doctorStatusUnknown, nil, "Configured aweb API runtime was not reachable at its current path.", "Use the recommended /api runtime URL and retry doctor --online.", map[string]any{"reason": "configured_runtime_unreachable", "configured_status_code": probe.ConfiguredStatus, "recommended_status_code": probe.RecommendedStatus}))
 		r.add(awebCheck(doctorCheckServerVersion, doctorStatusBlocked, nil, "Version header check requires the configured aweb runtime to be reachable.", "Resolve server.aweb_url.runtime_path first.", map[string]any{"prerequisite": doctorCheckServerAwebURLRuntime}))
+		r.add(awebCheck(doctorCheckServerClockSkew, doctorStatusBlocked, nil, "Clock skew check requires the configured aweb runtime to be reachable.", "Resolve server.aweb_url.runtime_path first.", map[string]any{"prerequisite": doctorCheckServerAwebURLRuntime}))
 		return false
 	}
 	detail := map[string]any{"reason": "aweb_unavailable", "aweb_url": state.baseURL}
@@ -279,16 +292,37 @@ func (r *doctorRunner) addOnlineServerProbeChecks(state *doctorAwebState) bool {
 	}
 	r.add(awebCheck(doctorCheckServerReachable, doctorStatusUnknown, nil, "Configured aweb API runtime is not reachable with a read-only probe.", "Check the configured aweb_url and network connectivity.", detail))
 	r.add(awebCheck(doctorCheckServerVersion, doctorStatusBlocked, nil, "Version header check requires a reachable aweb server.", "Resolve server.aweb_url.reachable first.", map[string]any{"prerequisite": doctorCheckServerReachable}))
+	r.add(awebCheck(doctorCheckServerClockSkew, doctorStatusBlocked, nil, "Clock skew check requires a reachable aweb server.", "Resolve server.aweb_url.reachable first.", map[string]any{"prerequisite": doctorCheckServerReachable}))
 	return false
 }
 
+func (r *doctorRunner) addServerClockSkewCheck(probe doctorAwebProbeResult) {
+	if !probe.ConfiguredSkewOK {
+		r.add(awebCheck(doctorCheckServerClockSkew, doctorStatusInfo, nil, "The server probe carried no Date header to compare clocks against.", "", map[string]any{"present": false}))
+		return
+	}
+	skew := probe.ConfiguredSkew.Round(time.Second)
+	detail := map[string]any{
+		"present":           true,
+		"skew_seconds":      skew.Seconds(),
+		"threshold_seconds": doctorClockSkewWarnThreshold.Seconds(),
+	}
+	if skew > doctorClockSkewWarnThreshold || skew < -doctorClockSkewWarnThreshold {
+		r.add(awebCheck(doctorCheckServerClockSkew, doctorStatusWarn, nil, fmt.Sprintf("Local clock differs from the aweb server by %s.", skew), "Sync the local clock (e.g. enable NTP); signed requests and stream deadlines depend on it.", detail))
+		return
+	}
+	r.add(awebCheck(doctorCheckServerClockSkew, doctorStatusOK, nil, "Local clock agrees with the aweb server.", "", detail))
+}
+
 func probeDoctorAwebRuntime(ctx context.Context, baseURL string) doctorAwebProbeResult {
 	baseURL = strings.TrimSuffix(strings.TrimSpace(baseURL), "/")
 	result := doctorAwebProbeResult{ConfiguredURL: baseURL}
-	status, html, version, err := probeDoctorAwebEndpoint(ctx, baseURL)
+	status, html, version, skew, skewOK, err := probeDoctorAwebEndpoint(ctx, baseURL)
 	result.ConfiguredStatus = status
 	result.ConfiguredHTML = html
 	result.ConfiguredVersion = version
+	result.ConfiguredSkew = skew
+	result.ConfiguredSkewOK = skewOK
 	if err != nil {
 		result.Err = err
 	}
@@ -298,7 +332,7 @@ func probeDoctorAwebRuntime(ctx context.Context, baseURL string) doctorAwebProbe
 	}
 	recommended := baseURL + "/api"
 	result.RecommendedURL = recommended
-	status, html, version, err = probeDoctorAwebEndpoint(ctx, recommended)
+	status, html, version, _, _, err = probeDoctorAwebEndpoint(ctx, recommended)
 	result.RecommendedStatus = status
 	result.RecommendedHTML = html
 	result.RecommendedVersion = version
@@ -306,19 +340,21 @@ func probeDoctorAwebRuntime(ctx context.Context, baseURL string) doctorAwebProbe
 	return result
 }
 
-func probeDoctorAwebEndpoint(ctx context.Context, baseURL string) (int, bool, string, error) {
+func probeDoctorAwebEndpoint(ctx context.Context, baseURL string) (int, bool, string, time.Duration, bool, error) {
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(baseURL, "/")+"/v1/agents/heartbeat", nil)
 	if err != nil {
-		return 0, false, "", err
+		return 0, false, "", 0, false, err
 	}
 	req.Header.Set("Accept", "application/json")
+	sent := time.Now()
 	resp, err := (&http.Client{Timeout: 2 * time.Second}).Do(req)
 	if err != nil {
-		return 0, false, "", err
+		return 0, false, "", 0, false, err
 	}
+	skew, skewOK := awid.EstimateClockSkew(resp.Header.Get("Date"), sent, time.Now())
 	defer resp.Body.Close()
 	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1024))
-	return resp.StatusCode, strings.Contains(resp.Header.Get("Content-Type"), "text/html"), sanitizeDoctorVersionHeader(resp.Header.Get("X-Latest-Client-Version")), nil
+	return resp.StatusCode, strings.Contains(resp.Header.Get("Content-Type"), "text/html"), sanitizeDoctorVersionHeader(resp.Header.Get("X-Latest-Client-Version")), skew, skewOK, nil
 }
 
 func sanitizeDoctorVersionHeader(raw string) string {
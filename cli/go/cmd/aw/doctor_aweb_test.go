@@ -9,6 +9,7 @@ import (
 	"strings"
 	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/awebai/aw/awconfig"
 )
@@ -30,6 +31,7 @@ type doctorAwebServer struct {
 	VersionHeader  string
 	RolesTeamID    string
 	WorkspaceError string
+	ClockOffset    time.Duration
 }
 
 func newDoctorAwebServer(t *testing.T, cfg *doctorAwebServer) *doctorAwebServer {
@@ -57,6 +59,9 @@ func newDoctorAwebServer(t *testing.T, cfg *doctorAwebServer) *doctorAwebServer
 			if strings.TrimSpace(cfg.VersionHeader) != "" {
 				w.Header().Set("X-Latest-Client-Version", cfg.VersionHeader)
 			}
+			if cfg.ClockOffset != 0 {
+				w.Header().Set("Date", time.Now().Add(cfg.ClockOffset).UTC().Format(http.TimeFormat))
+			}
 			w.WriteHeader(http.StatusMethodNotAllowed)
 		case "/v1/workspaces/team":
 			if !cfg.requireTeamAuth(w, r) {
@@ -246,6 +251,7 @@ func TestAwDoctorAwebOnlineHappyPath(t *testing.T) {
 	got := decodeDoctorOutput(t, out)
 	for _, id := range []string{
 		doctorCheckServerReachable,
+		doctorCheckServerClockSkew,
 		doctorCheckWorkspaceTeamRead,
 		doctorCheckWorkspaceCurrentRow,
 		doctorCheckWorkspaceIdentityMatch,
@@ -304,6 +310,25 @@ func TestAwDoctorAwebVersionHeaderReported(t *testing.T) {
 	}
 }
 
+func TestAwDoctorAwebClockSkewWarns(t *testing.T) {
+	t.Parallel()
+
+	server := newDoctorAwebServer(t, &doctorAwebServer{ClockOffset: -5 * time.Minute})
+	bin, tmp := buildDoctorBinary(t)
+	writeDoctorEphemeralFixture(t, tmp, server.Server.URL)
+
+	out, err := runDoctorCLI(t, bin, tmp, "doctor", "workspace", "--online", "--json")
+	if err != nil {
+		t.Fatalf("doctor workspace failed: %v\n%s", err, string(out))
+	}
+	got := decodeDoctorOutput(t, out)
+	check := requireDoctorCheckStatus(t, got, doctorCheckServerClockSkew, doctorStatusWarn)
+	skew, _ := check.Detail["skew_seconds"].(float64)
+	if skew > -295 || skew < -305 {
+		t.Fatalf("skew_seconds=%v, want about -300", check.Detail["skew_seconds"])
+	}
+}
+
 func TestAwDoctorAwebURLConfusionHostedBase(t *testing.T) {
 	t.Parallel()
 
@@ -359,7 +384,7 @@ func TestAwDoctorWorkspaceMissingServerRowFails(t *testing.T) {
 	writeDoctorEphemeralFixture(t, tmp, server.Server.URL)
 
 	out, err := runDoctorCLI(t, bin, tmp, "doctor", "workspace", "--online", "--json")
-	if err != nil {
+	if err := failingDoctorReport(err); err != nil {
 		t.Fatalf("doctor workspace failed: %v\n%s", err, string(out))
 	}
 	got := decodeDoctorOutput(t, out)
@@ -378,7 +403,7 @@ func TestAwDoctorWorkspaceDeletedFails(t *testing.T) {
 	writeDoctorEphemeralFixture(t, tmp, server.Server.URL)
 
 	out, err := runDoctorCLI(t, bin, tmp, "doctor", "workspace", "--online", "--json")
-	if err != nil {
+	if err := failingDoctorReport(err); err != nil {
 		t.Fatalf("doctor workspace failed: %v\n%s", err, string(out))
 	}
 	got := decodeDoctorOutput(t, out)
@@ -396,7 +421,7 @@ func TestAwDoctorWorkspaceIdentityMismatchFails(t *testing.T) {
 	writeDoctorEphemeralFixture(t, tmp, server.Server.URL)
 
 	out, err := runDoctorCLI(t, bin, tmp, "doctor", "workspace", "--online", "--json")
-	if err != nil {
+	if err := failingDoctorReport(err); err != nil {
 		t.Fatalf("doctor workspace failed: %v\n%s", err, string(out))
 	}
 	got := decodeDoctorOutput(t, out)
@@ -426,7 +451,7 @@ func TestAwDoctorTeamMembershipAuthorizedDetectsTeamMismatch(t *testing.T) {
 	writeDoctorEphemeralFixture(t, tmp, server.Server.URL)
 
 	out, err := runDoctorCLI(t, bin, tmp, "doctor", "team", "--online", "--json")
-	if err != nil {
+	if err := failingDoctorReport(err); err != nil {
 		t.Fatalf("doctor team failed: %v\n%s", err, string(out))
 	}
 	got := decodeDoctorOutput(t, out)
@@ -91,7 +91,7 @@ func TestAwDoctorLocalFixActiveTeamDryRunApplyAndNoop(t *testing.T) {
 	}
 
 	out, err := runDoctorCLI(t, bin, tmp, "doctor", "--fix", "--dry-run", doctorCheckTeamsActiveTeam, "--json")
-	if err != nil {
+	if err := failingDoctorReport(err); err != nil {
 		t.Fatalf("doctor active_team dry-run failed: %v\n%s", err, string(out))
 	}
 	got := decodeDoctorOutput(t, out)
@@ -112,7 +112,7 @@ func TestAwDoctorLocalFixActiveTeamDryRunApplyAndNoop(t *testing.T) {
 	}
 
 	out, err = runDoctorCLI(t, bin, tmp, "doctor", "--fix", doctorCheckTeamsActiveTeam, "--json")
-	if err != nil {
+	if err := failingDoctorReport(err); err != nil {
 		t.Fatalf("doctor active_team apply failed: %v\n%s", err, string(out))
 	}
 	got = decodeDoctorOutput(t, out)
@@ -179,7 +179,7 @@ memberships:
 		{TeamID: "frontend:example.com", Alias: "mia", WorkspaceID: "ws-2", CertPath: "team-certs/frontend__example.com.pem"},
 	})
 	out, err := runDoctorCLI(t, bin, ambiguousDir, "doctor", "--fix", doctorCheckTeamsActiveTeam, "--json")
-	if err != nil {
+	if err := failingDoctorReport(err); err != nil {
 		t.Fatalf("doctor ambiguous active_team failed: %v\n%s", err, string(out))
 	}
 	got := decodeDoctorOutput(t, out)
@@ -207,7 +207,7 @@ func TestAwDoctorLocalFixWorkspaceURLNormalizeRedactsAndApplies(t *testing.T) {
 	}
 
 	out, err := runDoctorCLI(t, bin, tmp, "doctor", "--json")
-	if err != nil {
+	if err := failingDoctorReport(err); err != nil {
 		t.Fatalf("plain doctor failed: %v\n%s", err, string(out))
 	}
 	got := decodeDoctorOutput(t, out)
@@ -224,7 +224,7 @@ func TestAwDoctorLocalFixWorkspaceURLNormalizeRedactsAndApplies(t *testing.T) {
 	requireNoDoctorOutputLeak(t, out, "user:pass", "token=secret-query", "secret-fragment")
 
 	out, err = runDoctorCLI(t, bin, tmp, "doctor", "--fix", "--dry-run", doctorCheckWorkspaceAwebURL, "--json")
-	if err != nil {
+	if err := failingDoctorReport(err); err != nil {
 		t.Fatalf("doctor aweb_url dry-run failed: %v\n%s", err, string(out))
 	}
 	got = decodeDoctorOutput(t, out)
@@ -239,7 +239,7 @@ func TestAwDoctorLocalFixWorkspaceURLNormalizeRedactsAndApplies(t *testing.T) {
 	}
 
 	out, err = runDoctorCLI(t, bin, tmp, "doctor", "--fix", doctorCheckWorkspaceAwebURL, "--json")
-	if err != nil {
+	if err := failingDoctorReport(err); err != nil {
 		t.Fatalf("doctor aweb_url apply failed: %v\n%s", err, string(out))
 	}
 	got = decodeDoctorOutput(t, out)
@@ -146,7 +146,7 @@ func TestAwDoctorHandoffMissingPersistentStateNeedsExternalReview(t *testing.T)
 	}
 
 	out, err := runDoctorCLI(t, bin, tmp, "doctor", "local", "--json")
-	if err != nil {
+	if err := failingDoctorReport(err); err != nil {
 		t.Fatalf("doctor local failed: %v\n%s", err, string(out))
 	}
 	got := decodeDoctorOutput(t, out)
@@ -182,7 +182,7 @@ func TestAwDoctorHandoffDIDNotFoundUsesCallerRepairAuthority(t *testing.T) {
 	writeIdentityForTest(t, fixture.Dir, *identity)
 
 	out, err := runDoctorCLI(t, fixture.Bin, fixture.Dir, "doctor", "registry", "--online", "--json")
-	if err != nil {
+	if err := failingDoctorReport(err); err != nil {
 		t.Fatalf("doctor registry failed: %v\n%s", err, string(out))
 	}
 	got := decodeDoctorOutput(t, out)
@@ -211,7 +211,7 @@ func TestAwDoctorHandoffHumanDefaultAndVerboseOutput(t *testing.T) {
 	}
 
 	out, err := runDoctorCLI(t, bin, tmp, "doctor", "local")
-	if err != nil {
+	if err := failingDoctorReport(err); err != nil {
 		t.Fatalf("doctor local human failed: %v\n%s", err, string(out))
 	}
 	text := string(out)
@@ -223,7 +223,7 @@ func TestAwDoctorHandoffHumanDefaultAndVerboseOutput(t *testing.T) {
 	}
 
 	out, err = runDoctorCLI(t, bin, tmp, "doctor", "--verbose", "local")
-	if err != nil {
+	if err := failingDoctorReport(err); err != nil {
 		t.Fatalf("doctor local verbose failed: %v\n%s", err, string(out))
 	}
 	text = string(out)
@@ -282,7 +282,7 @@ func TestAwDoctorIdentityPersistentMissingIdentityYAMLFails(t *testing.T) {
 	}
 
 	out, err := runDoctorCLI(t, fixture.Bin, fixture.Dir, "doctor", "identity", "--json")
-	if err != nil {
+	if err := failingDoctorReport(err); err != nil {
 		t.Fatalf("doctor identity failed: %v\n%s", err, string(out))
 	}
 	got := decodeDoctorOutput(t, out)
@@ -311,7 +311,7 @@ func TestAwDoctorIdentityDIDNotFoundFails(t *testing.T) {
 	updateDoctorIdentityRegistryURL(t, fixture.Dir, server.URL)
 
 	out, err := runDoctorCLI(t, fixture.Bin, fixture.Dir, "doctor", "registry", "--online", "--json")
-	if err != nil {
+	if err := failingDoctorReport(err); err != nil {
 		t.Fatalf("doctor registry online failed: %v\n%s", err, string(out))
 	}
 	got := decodeDoctorOutput(t, out)
@@ -352,7 +352,7 @@ func TestAwDoctorIdentityKeyMismatchFails(t *testing.T) {
 	updateDoctorIdentityRegistryURL(t, fixture.Dir, server.URL)
 
 	out, err := runDoctorCLI(t, fixture.Bin, fixture.Dir, "doctor", "registry", "--online", "--json")
-	if err != nil {
+	if err := failingDoctorReport(err); err != nil {
 		t.Fatalf("doctor registry online failed: %v\n%s", err, string(out))
 	}
 	got := decodeDoctorOutput(t, out)
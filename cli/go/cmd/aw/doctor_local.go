@@ -42,6 +42,8 @@ const (
 	doctorCheckIdentityCustody       = "local.identity_yaml.custody_present"
 	doctorCheckIdentityRegistryURL   = "local.identity_yaml.registry_url_syntax"
 	doctorCheckRegistryCoherence     = "local.registry_url.local_coherence"
+	doctorCheckEnvAwebURLOverride    = "local.env.aweb_url_override"
+	doctorCheckCredentialsParse      = "local.credentials.parse"
 )
 
 type doctorLocalState struct {
@@ -106,6 +108,7 @@ func (r *doctorRunner) runLocalChecks() {
 		signingKeyPath: awconfig.WorktreeSigningKeyPath(r.workingDir),
 		identityPath:   filepath.Join(r.workingDir, awconfig.DefaultWorktreeIdentityRelativePath()),
 	}
+	r.runCredentialsChecks()
 
 	workspace, workspacePath, err := loadDoctorWorkspaceFromDir(r.workingDir)
 	if err != nil {
@@ -200,6 +203,7 @@ func (r *doctorRunner) runWorkspaceChecks(state *doctorLocalState) {
 			check.Fix = safeDoctorFixInfo(doctorCheckWorkspaceAwebURL)
 		}
 		r.add(check)
+		r.runEnvOverrideChecks(state, awebURL.URL)
 	}
 
 	activeTeam := ""
@@ -872,6 +876,73 @@ func (r *doctorRunner) addIdentityDependentBlockedChecks(prerequisite string) {
 	}
 }
 
+// runCredentialsChecks reports whether the `aw login` credentials file can
+// be read. A missing file is normal and produces no check.
+func (r *doctorRunner) runCredentialsChecks() {
+	path, err := awconfig.DefaultCredentialsPath()
+	if err != nil {
+		return
+	}
+	if _, err := os.Stat(path); err != nil {
+		return
+	}
+	creds, err := awconfig.LoadCredentialsFrom(path)
+	if err != nil {
+		r.add(localPathCheck(
+			doctorCheckCredentialsParse,
+			doctorStatusFail,
+			path,
+			"Stored login credentials could not be parsed.",
+			"Repair the file or remove it and run `aw login` again.",
+			map[string]any{"error": err.Error()},
+		))
+		return
+	}
+	r.add(localPathCheck(
+		doctorCheckCredentialsParse,
+		doctorStatusOK,
+		path,
+		"Stored login credentials parsed successfully.",
+		"",
+		map[string]any{"servers": len(creds.Servers)},
+	))
+}
+
+// runEnvOverrideChecks warns when AWEB_URL points commands at a different
+// server than the workspace binding, since the environment wins silently.
+func (r *doctorRunner) runEnvOverrideChecks(state *doctorLocalState, workspaceURL string) {
+	envURL := envAwebURL()
+	if envURL == "" {
+		return
+	}
+	target := &doctorTarget{Type: "workspace", ID: strings.TrimSpace(state.workspacePath), Display: abbreviateUserHome(state.workspacePath)}
+	normalized, err := normalizeLocalURLForDoctor(envURL)
+	if err != nil {
+		r.add(localCheck(
+			doctorCheckEnvAwebURLOverride,
+			doctorStatusFail,
+			target,
+			"AWEB_URL is set but is not a valid URL.",
+			"Unset AWEB_URL or set it to a valid http(s) URL.",
+			map[string]any{"error": err.Error()},
+		))
+		return
+	}
+	detail := map[string]any{"env_aweb_url": normalized.URL, "workspace_aweb_url": workspaceURL}
+	if strings.TrimSuffix(normalized.URL, "/") == strings.TrimSuffix(workspaceURL, "/") {
+		r.add(localCheck(doctorCheckEnvAwebURLOverride, doctorStatusOK, target, "AWEB_URL matches the workspace aweb_url.", "", detail))
+		return
+	}
+	r.add(localCheck(
+		doctorCheckEnvAwebURLOverride,
+		doctorStatusWarn,
+		target,
+		"AWEB_URL overrides the workspace aweb_url with a different server.",
+		"Unset AWEB_URL unless you mean to point this workspace at another server.",
+		detail,
+	))
+}
+
 func localPathCheck(id string, status doctorStatus, path, message, nextStep string, detail map[string]any) doctorCheck {
 	return localCheck(id, status, localPathTarget(path), message, nextStep, detail)
 }
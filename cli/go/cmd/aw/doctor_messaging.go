@@ -197,7 +197,7 @@ func (r *doctorRunner) addMessagingOnlineChecks(client *aweb.Client) {
 		r.add(awebCheck(doctorCheckMessagingChatSessions, doctorStatusOK, nil, "Chat sessions can be read under current identity credentials.", "", map[string]any{"session_count": len(sessions.Sessions)}))
 	}
 	var contacts doctorContactsResponse
-	if err := client.Get(ctx, "/v1/contacts", &contacts); err != nil {
+	if err := client.Get(ctx, client.APIPath("/contacts"), &contacts); err != nil {
 		r.addAwebHTTPErrorCheck(doctorCheckMessagingContactsRead, err, "Contacts read failed under current identity credentials.", "Retry with the current identity credentials or repair local signing key state.")
 	} else {
 		r.add(awebCheck(doctorCheckMessagingContactsRead, doctorStatusOK, nil, "Messaging contacts can be read under current identity credentials.", "", map[string]any{"contact_count": len(contacts.Contacts)}))
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"bytes"
 	"context"
 	"crypto/ed25519"
 	"encoding/json"
@@ -42,10 +43,21 @@ func runDoctorCLI(t *testing.T, bin, dir string, args ...string) ([]byte, error)
 	return run.CombinedOutput()
 }
 
+// failingDoctorReport drops the exit status doctor uses when the report has
+// failing checks, so tests can go on to inspect the report itself.
+func failingDoctorReport(err error) error {
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+		return nil
+	}
+	return err
+}
+
 func decodeDoctorOutput(t *testing.T, out []byte) doctorOutput {
 	t.Helper()
 	var got doctorOutput
-	if err := json.Unmarshal(extractJSON(t, out), &got); err != nil {
+	// A failing report is followed by the exit error on stderr.
+	if err := json.NewDecoder(bytes.NewReader(extractJSON(t, out))).Decode(&got); err != nil {
 		t.Fatalf("invalid doctor json: %v\n%s", err, string(out))
 	}
 	return got
@@ -830,6 +842,37 @@ func TestAwDoctorLocalChecksValidPersistentWorkspace(t *testing.T) {
 	requireDoctorCheckStatus(t, got, doctorCheckRegistryCoherence, doctorStatusOK)
 }
 
+func TestAwDoctorLocalChecksEnvOverrideAndCredentials(t *testing.T) {
+	t.Parallel()
+
+	bin, tmp := buildDoctorBinary(t)
+	writeDoctorEphemeralFixture(t, tmp, "https://app.example.com/api")
+	credsPath := filepath.Join(tmp, ".config", "aw", "credentials.yaml")
+	if err := os.MkdirAll(filepath.Dir(credsPath), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(credsPath, []byte("servers: [\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	run := exec.CommandContext(ctx, bin, "doctor", "local", "--offline", "--json")
+	run.Dir = tmp
+	run.Env = append(testCommandEnv(tmp), "AWEB_URL=https://other.example.com/api")
+	out, err := run.CombinedOutput()
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) || exitErr.ExitCode() != 1 {
+		t.Fatalf("doctor should exit 1 for unreadable credentials: %v\n%s", err, string(out))
+	}
+	got := decodeDoctorOutput(t, out)
+	requireDoctorCheckStatus(t, got, doctorCheckCredentialsParse, doctorStatusFail)
+	check := requireDoctorCheckStatus(t, got, doctorCheckEnvAwebURLOverride, doctorStatusWarn)
+	if check.Detail["env_aweb_url"] != "https://other.example.com/api" || check.Detail["workspace_aweb_url"] != "https://app.example.com/api" {
+		t.Fatalf("override detail=%#v", check.Detail)
+	}
+}
+
 func TestAwDoctorLocalChecksCorruptWorkspaceYAML(t *testing.T) {
 	t.Parallel()
 
@@ -837,8 +880,9 @@ func TestAwDoctorLocalChecksCorruptWorkspaceYAML(t *testing.T) {
 	writeDoctorWorkspaceYAML(t, tmp, "aweb_url: [\n")
 
 	out, err := runDoctorCLI(t, bin, tmp, "doctor", "--json")
-	if err != nil {
-		t.Fatalf("doctor should report corrupt workspace as checks: %v\n%s", err, string(out))
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) || exitErr.ExitCode() != 1 {
+		t.Fatalf("doctor should report corrupt workspace as checks and exit 1: %v\n%s", err, string(out))
 	}
 	got := decodeDoctorOutput(t, out)
 	if got.Status != doctorStatusFail {
@@ -936,7 +980,7 @@ memberships:
 				}
 			}
 			out, err := runDoctorCLI(t, bin, tmp, "doctor", "local", "--json")
-			if err != nil {
+			if err := failingDoctorReport(err); err != nil {
 				t.Fatalf("doctor should report workspace semantic issue as checks: %v\n%s", err, string(out))
 			}
 			got := decodeDoctorOutput(t, out)
@@ -957,7 +1001,7 @@ func TestAwDoctorLocalChecksCorruptSigningKey(t *testing.T) {
 	}
 
 	out, err := runDoctorCLI(t, bin, tmp, "doctor", "local", "--json")
-	if err != nil {
+	if err := failingDoctorReport(err); err != nil {
 		t.Fatalf("doctor should report corrupt signing key as checks: %v\n%s", err, string(out))
 	}
 	got := decodeDoctorOutput(t, out)
@@ -976,7 +1020,7 @@ func TestAwDoctorLocalChecksMissingCertificate(t *testing.T) {
 	}
 
 	out, err := runDoctorCLI(t, bin, tmp, "doctor", "local", "--json")
-	if err != nil {
+	if err := failingDoctorReport(err); err != nil {
 		t.Fatalf("doctor should report missing cert as checks: %v\n%s", err, string(out))
 	}
 	got := decodeDoctorOutput(t, out)
@@ -999,7 +1043,7 @@ func TestAwDoctorLocalChecksSigningKeyCertificateDIDMismatch(t *testing.T) {
 	}
 
 	out, err := runDoctorCLI(t, bin, tmp, "doctor", "local", "--json")
-	if err != nil {
+	if err := failingDoctorReport(err); err != nil {
 		t.Fatalf("doctor should report did mismatch as checks: %v\n%s", err, string(out))
 	}
 	got := decodeDoctorOutput(t, out)
@@ -1019,7 +1063,7 @@ func TestAwDoctorLocalChecksPersistentMissingIdentity(t *testing.T) {
 	}
 
 	out, err := runDoctorCLI(t, bin, tmp, "doctor", "local", "--json")
-	if err != nil {
+	if err := failingDoctorReport(err); err != nil {
 		t.Fatalf("doctor should report missing persistent identity as checks: %v\n%s", err, string(out))
 	}
 	got := decodeDoctorOutput(t, out)
@@ -1037,7 +1081,7 @@ func TestAwDoctorLocalChecksCorruptEphemeralIdentityFails(t *testing.T) {
 	}
 
 	out, err := runDoctorCLI(t, bin, tmp, "doctor", "local", "--json")
-	if err != nil {
+	if err := failingDoctorReport(err); err != nil {
 		t.Fatalf("doctor should report corrupt ephemeral identity as checks: %v\n%s", err, string(out))
 	}
 	got := decodeDoctorOutput(t, out)
@@ -3,6 +3,7 @@ package main
 import (
 	"fmt"
 	"strings"
+	"text/tabwriter"
 	"time"
 
 	aweb "github.com/awebai/aw"
@@ -38,9 +39,20 @@ func formatContactTag(isContact *bool) string {
 	return ""
 }
 
+// formatPriorityTag marks high and urgent chat messages; normal and low
+// ones, and messages from servers without priorities, get no tag.
+func formatPriorityTag(priority awid.MessagePriority) string {
+	switch priority {
+	case awid.PriorityHigh, awid.PriorityUrgent:
+		return " [" + string(priority) + "]"
+	default:
+		return ""
+	}
+}
+
 // formatChatEventLine formats a single chat event as "[HH:MM:SS] agent: body" with tags.
 func formatChatEventLine(m chat.Event) string {
-	tags := formatVerificationTag(m.VerificationStatus) + formatContactTag(m.IsContact)
+	tags := formatVerificationTag(m.VerificationStatus) + formatContactTag(m.IsContact) + formatPriorityTag(m.Priority)
 	from := preferredIdentityDisplayLabel(m.FromAgent, m.FromAddress, m.FromStableID, m.FromDID, "")
 	ts := ""
 	if m.Timestamp != "" {
@@ -128,10 +140,109 @@ func formatMailInbox(v any) string {
 		}
 		tags := formatVerificationTag(msg.VerificationStatus) + formatContactTag(msg.IsContact)
 		sb.WriteString(fmt.Sprintf("- %s%s%s: %s\n", preferredIdentityDisplayLabel(msg.FromAlias, msg.FromAddress, msg.FromStableID, msg.FromDID, ""), subj, tags, msg.Body))
+		for _, a := range msg.Attachments {
+			if a.URL != "" {
+				sb.WriteString(fmt.Sprintf("  attachment: %s <%s>\n", a.Name, a.URL))
+			} else {
+				sb.WriteString(fmt.Sprintf("  attachment: %s (%s, %d bytes)\n", a.Name, a.ContentType, len(a.Data)))
+			}
+		}
 	}
 	return sb.String()
 }
 
+func formatMailShow(v any) string {
+	msg := v.(*awid.InboxMessage)
+	var sb strings.Builder
+	tags := formatVerificationTag(msg.VerificationStatus) + formatContactTag(msg.IsContact)
+	sb.WriteString(fmt.Sprintf("From:     %s%s\n", preferredIdentityDisplayLabel(msg.FromAlias, msg.FromAddress, msg.FromStableID, msg.FromDID, ""), tags))
+	if subj := strings.TrimSpace(msg.Subject); subj != "" {
+		sb.WriteString(fmt.Sprintf("Subject:  %s\n", subj))
+	}
+	if msg.Priority != "" && msg.Priority != awid.PriorityNormal {
+		sb.WriteString(fmt.Sprintf("Priority: %s\n", msg.Priority))
+	}
+	sb.WriteString(fmt.Sprintf("Sent:     %s\n", msg.CreatedAt))
+	if msg.ReadAt != nil && *msg.ReadAt != "" {
+		sb.WriteString(fmt.Sprintf("Read:     %s\n", *msg.ReadAt))
+	} else {
+		sb.WriteString("Read:     unread\n")
+	}
+	for _, a := range msg.Attachments {
+		if a.URL != "" {
+			sb.WriteString(fmt.Sprintf("Attachment: %s <%s>\n", a.Name, a.URL))
+		} else {
+			sb.WriteString(fmt.Sprintf("Attachment: %s (%s, %d bytes)\n", a.Name, a.ContentType, len(a.Data)))
+		}
+	}
+	sb.WriteString("\n" + msg.Body + "\n")
+	return sb.String()
+}
+
+func formatMailThread(v any) string {
+	messages := v.([]awid.InboxMessage)
+	if len(messages) == 0 {
+		return "No messages in thread.\n"
+	}
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Thread (%d messages):\n", len(messages)))
+	for _, msg := range messages {
+		ts := msg.CreatedAt
+		if t, err := awid.ParseTimestamp(msg.CreatedAt); err == nil {
+			ts = t.Local().Format("2006-01-02 15:04")
+		}
+		subj := strings.TrimSpace(msg.Subject)
+		if subj != "" {
+			subj = " — " + subj
+		}
+		tags := formatVerificationTag(msg.VerificationStatus) + formatContactTag(msg.IsContact)
+		sb.WriteString(fmt.Sprintf("\n[%s] %s%s%s\n", ts, preferredIdentityDisplayLabel(msg.FromAlias, msg.FromAddress, msg.FromStableID, msg.FromDID, ""), subj, tags))
+		for _, line := range strings.Split(strings.TrimRight(msg.Body, "\n"), "\n") {
+			sb.WriteString("  " + line + "\n")
+		}
+	}
+	return sb.String()
+}
+
+func formatMailFlush(v any) string {
+	result := v.(*aweb.FlushResult)
+	var sb strings.Builder
+	for _, r := range result.Sent {
+		sb.WriteString(fmt.Sprintf("Sent queued mail %s (message_id=%s)\n", queuedMailLabel(r.Message), r.Response.MessageID))
+	}
+	for _, r := range result.Failed {
+		sb.WriteString(fmt.Sprintf("Rejected queued mail %s: %s\n", queuedMailLabel(r.Message), r.Error))
+	}
+	if len(result.Sent) == 0 && len(result.Failed) == 0 && result.Remaining == 0 {
+		return "No queued mail.\n"
+	}
+	if result.Remaining > 0 {
+		sb.WriteString(fmt.Sprintf("%d still queued.\n", result.Remaining))
+	}
+	return sb.String()
+}
+
+func formatMailSendMulti(v any) string {
+	result := v.(*awid.MultiSendResponse)
+	var sb strings.Builder
+	for _, r := range result.Results {
+		if r.Error != "" {
+			sb.WriteString(fmt.Sprintf("Failed to send mail to %s: %s\n", r.ToAlias, r.Error))
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("Sent mail to %s (message_id=%s)\n", r.ToAlias, r.Response.MessageID))
+	}
+	return sb.String()
+}
+
+func queuedMailLabel(m aweb.QueuedMessage) string {
+	to := firstNonEmpty(m.Request.ToAlias, m.Request.ToAddress, m.Request.ToStableID, m.Request.ToDID, m.Request.ToAgentID)
+	if subj := strings.TrimSpace(m.Request.Subject); subj != "" {
+		return fmt.Sprintf("to %s — %s", to, subj)
+	}
+	return "to " + to
+}
+
 // --- chat ---
 
 func formatChatSend(v any) string {
@@ -182,7 +293,7 @@ func formatChatSend(v any) string {
 	tags := ""
 	if tagEvent != nil {
 		timestamp = tagEvent.Timestamp
-		tags = formatVerificationTag(tagEvent.VerificationStatus) + formatContactTag(tagEvent.IsContact)
+		tags = formatVerificationTag(tagEvent.VerificationStatus) + formatContactTag(tagEvent.IsContact) + formatPriorityTag(tagEvent.Priority)
 	}
 	replyFrom := preferredIdentityDisplayLabel(
 		func() string {
@@ -213,18 +324,18 @@ func formatChatSend(v any) string {
 	)
 
 	switch result.Status {
-	case "replied":
+	case chat.StatusReplied:
 		writeChatLine("Chat from", replyFrom+tags, timestamp)
 		sb.WriteString(fmt.Sprintf("Body: %s\n", result.Reply))
 		return sb.String()
 
-	case "sender_left":
+	case chat.StatusSenderLeft:
 		writeChatLine("Chat from", replyFrom+tags, timestamp)
 		sb.WriteString(fmt.Sprintf("Body: %s\n", result.Reply))
 		sb.WriteString(fmt.Sprintf("Note: %s has left the exchange\n", result.TargetAgent))
 		return sb.String()
 
-	case "pending":
+	case chat.StatusPending:
 		incomingReply := true
 		if tagEvent != nil {
 			incomingReply = false
@@ -250,14 +361,14 @@ func formatChatSend(v any) string {
 		}
 		return sb.String()
 
-	case "sent":
+	case chat.StatusSent:
 		sb.WriteString(fmt.Sprintf("Message sent to %s\n", result.TargetAgent))
 		if result.TargetNotConnected {
 			sb.WriteString(fmt.Sprintf("Note: %s was not connected.\n", result.TargetAgent))
 		}
 		return sb.String()
 
-	case "timeout":
+	case chat.StatusTimeout:
 		sb.WriteString(fmt.Sprintf("Message sent to %s\n", result.TargetAgent))
 		if result.TargetNotConnected {
 			sb.WriteString(fmt.Sprintf("Note: %s was not connected.\n", result.TargetAgent))
@@ -265,7 +376,7 @@ func formatChatSend(v any) string {
 		sb.WriteString(fmt.Sprintf("Waited %ds — no reply\n", result.WaitedSeconds))
 		return sb.String()
 
-	case "targets_left":
+	case chat.StatusTargetsLeft:
 		sb.WriteString(fmt.Sprintf("Message sent to %s\n", result.TargetAgent))
 		sb.WriteString(fmt.Sprintf("%s previously left the conversation.\n", result.TargetAgent))
 		sb.WriteString(fmt.Sprintf("To start a new exchange, run: \"aw chat send-and-wait %s \\\"message\\\" --start-conversation\"\n", result.TargetAgent))
@@ -281,7 +392,7 @@ func formatChatSend(v any) string {
 		if messageIndex > 0 {
 			sb.WriteString("\n---\n\n")
 		}
-		tags := formatVerificationTag(event.VerificationStatus) + formatContactTag(event.IsContact)
+		tags := formatVerificationTag(event.VerificationStatus) + formatContactTag(event.IsContact) + formatPriorityTag(event.Priority)
 		writeChatLine("Chat from", preferredIdentityDisplayLabel(event.FromAgent, event.FromAddress, event.FromStableID, event.FromDID, "")+tags, event.Timestamp)
 		sb.WriteString(fmt.Sprintf("Body: %s\n", event.Body))
 		messageIndex++
@@ -442,6 +553,9 @@ func formatChatPending(v any) string {
 	for _, p := range result.Pending {
 		openHint := ""
 		displayFrom := preferredPendingSenderLabel(p, "")
+		if subject := strings.TrimSpace(p.Subject); subject != "" {
+			displayFrom += fmt.Sprintf(" [%s]", subject)
+		}
 		openTarget := pendingOpenTarget(p)
 		if openTarget != "" {
 			openHint = fmt.Sprintf(" — Run \"aw chat open %s\"", openTarget)
@@ -461,6 +575,43 @@ func formatChatPending(v any) string {
 	return sb.String()
 }
 
+func formatChatList(v any) string {
+	result := v.(*chat.ListResult)
+	if len(result.Conversations) == 0 {
+		return "No conversations\n"
+	}
+
+	var sb strings.Builder
+	tw := tabwriter.NewWriter(&sb, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "WITH\tUNREAD\tLAST ACTIVITY\tLAST MESSAGE")
+	for _, c := range result.Conversations {
+		with := firstNonEmpty(c.TargetAgent, "-")
+		if subject := strings.TrimSpace(c.Subject); subject != "" {
+			with += fmt.Sprintf(" [%s]", subject)
+		}
+		if c.SenderWaiting {
+			with += " (waiting)"
+		}
+		activity := "-"
+		if c.LastActivity != "" {
+			activity = formatTimeAgo(c.LastActivity)
+		}
+		last := "-"
+		if body := summarizeInteractionText(c.LastMessage, chatListBodyLimit); body != "" {
+			last = body
+			if from := strings.TrimSpace(c.LastFrom); from != "" {
+				last = from + ": " + last
+			}
+		}
+		_, _ = fmt.Fprintf(tw, "%s\t%d\t%s\t%s\n", with, c.UnreadCount, activity, last)
+	}
+	_ = tw.Flush()
+	return sb.String()
+}
+
+// chatListBodyLimit caps the last-message column of aw chat list, in runes.
+const chatListBodyLimit = 60
+
 func formatChatOpen(v any) string {
 	result := v.(*chat.OpenResult)
 	if len(result.Messages) == 0 {
@@ -476,8 +627,12 @@ func formatChatOpen(v any) string {
 	} else {
 		sb.WriteString(fmt.Sprintf("Unread chat messages (%d):\n\n", len(result.Messages)))
 	}
-	if result.SenderWaiting {
-		sb.WriteString(fmt.Sprintf("Status: %s is WAITING for your reply\n\n", result.TargetAgent))
+	if result.SenderStillWaiting {
+		if result.TimeRemainingSeconds > 0 {
+			sb.WriteString(fmt.Sprintf("Status: %s is still WAITING for your reply (%ds left)\n\n", result.TargetAgent, result.TimeRemainingSeconds))
+		} else {
+			sb.WriteString(fmt.Sprintf("Status: %s is still WAITING for your reply\n\n", result.TargetAgent))
+		}
 	}
 
 	for i, m := range result.Messages {
@@ -488,7 +643,7 @@ func formatChatOpen(v any) string {
 	}
 
 	sb.WriteString(fmt.Sprintf("\nNext: Run \"aw chat send-and-wait %s \\\"your reply\\\"\"", result.TargetAgent))
-	if result.SenderWaiting {
+	if result.SenderStillWaiting {
 		sb.WriteString(fmt.Sprintf(" or \"aw chat extend-wait %s \\\"message\\\"\"", result.TargetAgent))
 	}
 	sb.WriteString("\n")
@@ -496,6 +651,27 @@ func formatChatOpen(v any) string {
 	return sb.String()
 }
 
+func formatChatOpenAll(v any) string {
+	results := v.([]chat.OpenResult)
+	if len(results) == 0 {
+		return "No pending conversations\n"
+	}
+	var sb strings.Builder
+	for i := range results {
+		if i > 0 {
+			sb.WriteString("\n===\n\n")
+		}
+		result := &results[i]
+		if result.Error != "" {
+			sb.WriteString(fmt.Sprintf("Could not open conversation with %s: %s\n", result.TargetAgent, result.Error))
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("Conversation with %s\n", result.TargetAgent))
+		sb.WriteString(formatChatOpen(result))
+	}
+	return sb.String()
+}
+
 func formatChatHistory(v any) string {
 	result := v.(*chat.HistoryResult)
 	if len(result.Messages) == 0 {
@@ -512,6 +688,69 @@ func formatChatHistory(v any) string {
 	return sb.String()
 }
 
+func formatChatSearch(v any) string {
+	return formatChatSearchStyled(v.(*chat.SearchResult), false)
+}
+
+// formatChatSearchStyled lists the matching messages, highlighting each
+// match in reverse video when ansi is set. Server text is escaped piece by
+// piece so the highlight codes survive.
+func formatChatSearchStyled(result *chat.SearchResult, ansi bool) string {
+	if len(result.Messages) == 0 {
+		return terminalText(fmt.Sprintf("No messages match %q\n", result.Query))
+	}
+	var sb strings.Builder
+	sb.WriteString(terminalText(fmt.Sprintf("Messages matching %q (%d):\n\n", result.Query, len(result.Messages))))
+	for _, m := range result.Messages {
+		m.FromAgent = terminalText(m.FromAgent)
+		m.FromAddress = terminalText(m.FromAddress)
+		m.FromStableID = terminalText(m.FromStableID)
+		m.FromDID = terminalText(m.FromDID)
+		m.Body = highlightMatches(m.Body, m.Matches, ansi)
+		sb.WriteString(formatChatEventLine(m))
+	}
+	return sb.String()
+}
+
+func highlightMatches(body string, matches []awid.TextMatch, ansi bool) string {
+	if !ansi {
+		return terminalText(body)
+	}
+	var sb strings.Builder
+	last := 0
+	for _, match := range matches {
+		if match.Start < last || match.End > len(body) || match.Start >= match.End {
+			continue
+		}
+		sb.WriteString(terminalText(body[last:match.Start]))
+		sb.WriteString("\x1b[7m" + terminalText(body[match.Start:match.End]) + "\x1b[0m")
+		last = match.End
+	}
+	sb.WriteString(terminalText(body[last:]))
+	return sb.String()
+}
+
+func formatChatLeave(v any) string {
+	result := v.(*chat.LeaveResult)
+	if result.SessionClosed {
+		return fmt.Sprintf("Left conversation with %s (conversation closed)\n", result.TargetAgent)
+	}
+	return fmt.Sprintf("Left conversation with %s\n", result.TargetAgent)
+}
+
+func formatChatNudge(v any) string {
+	result := v.(*chat.NudgeResult)
+	if len(result.Nudged) == 0 {
+		return fmt.Sprintf("Nobody to nudge: the conversation with %s is read\n", result.TargetAgent)
+	}
+	return fmt.Sprintf("Nudged %s\n", strings.Join(result.Nudged, ", "))
+}
+
+func formatChatMarkUnread(v any) string {
+	result := v.(*chat.MarkUnreadResult)
+	return fmt.Sprintf("Marked conversation with %s unread (%d unread)\n", result.TargetAgent, result.UnreadCount)
+}
+
 func formatChatExtendWait(v any) string {
 	result := v.(*chat.ExtendWaitResult)
 	var sb strings.Builder
@@ -528,15 +767,42 @@ func formatChatExtendWait(v any) string {
 
 func formatLockAcquire(v any) string {
 	resp := v.(*aweb.ReservationAcquireResponse)
-	return fmt.Sprintf("Locked %s\n", resp.ResourceKey)
+	var notes []string
+	if resp.Stolen() {
+		from := resp.StolenFromAlias
+		if from == "" {
+			from = resp.StolenFromAgentID
+		}
+		notes = append(notes, "took over expired lock from "+from)
+	}
+	if resp.FencingToken > 0 {
+		notes = append(notes, fmt.Sprintf("fencing token %d", resp.FencingToken))
+	}
+	if len(notes) == 0 {
+		return fmt.Sprintf("Locked %s\n", resp.ResourceKey)
+	}
+	return fmt.Sprintf("Locked %s (%s)\n", resp.ResourceKey, strings.Join(notes, ", "))
 }
 
 func formatLockRenew(v any) string {
 	resp := v.(*aweb.ReservationRenewResponse)
 	remaining := ttlRemainingSeconds(resp.ExpiresAt, time.Now())
+	if resp.FencingToken > 0 {
+		return fmt.Sprintf("Renewed %s (expires in %s, fencing token %d)\n", resp.ResourceKey, formatDuration(remaining), resp.FencingToken)
+	}
 	return fmt.Sprintf("Renewed %s (expires in %s)\n", resp.ResourceKey, formatDuration(remaining))
 }
 
+func formatLockTransfer(v any) string {
+	resp := v.(*aweb.ReservationTransferResponse)
+	to := resp.HolderAlias
+	if to == "" {
+		to = resp.HolderAgentID
+	}
+	remaining := ttlRemainingSeconds(resp.ExpiresAt, time.Now())
+	return fmt.Sprintf("Transferred %s to %s (expires in %s)\n", resp.ResourceKey, to, formatDuration(remaining))
+}
+
 func formatLockRelease(v any) string {
 	resp := v.(*aweb.ReservationReleaseResponse)
 	return fmt.Sprintf("Released %s\n", resp.ResourceKey)
@@ -557,6 +823,12 @@ func formatLockList(v any) string {
 	for _, r := range resp.Reservations {
 		sb.WriteString(fmt.Sprintf("- %s — %s (expires in %s)\n", r.ResourceKey, r.HolderAlias, formatDuration(ttlRemainingSeconds(r.ExpiresAt, now))))
 	}
+	if resp.Total > len(resp.Reservations) {
+		sb.WriteString(fmt.Sprintf("Showing %d of %d locks.\n", len(resp.Reservations), resp.Total))
+	}
+	if resp.HasMore && resp.NextCursor != nil && *resp.NextCursor != "" {
+		sb.WriteString(fmt.Sprintf("More locks available; rerun with --cursor %s\n", *resp.NextCursor))
+	}
 	return sb.String()
 }
 
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+
+	aweb "github.com/awebai/aw"
+	"github.com/awebai/aw/awid"
+	"github.com/awebai/aw/chat"
+)
+
+// CSV columns per list command. They are part of the CLI's output contract
+// (see "--output csv" in the README): append new columns, never reorder.
+var (
+	mailCSVHeader        = []string{"message_id", "created_at", "from_alias", "from_address", "subject", "body", "priority", "thread_id", "read_at", "verification_status"}
+	chatHistoryCSVHeader = []string{"message_id", "timestamp", "from_agent", "from_address", "body", "sender_leaving", "verification_status"}
+	chatListCSVHeader    = []string{"session_id", "with", "subject", "unread_count", "last_activity", "last_from", "last_message", "sender_waiting", "created_at"}
+	lockCSVHeader        = []string{"resource_key", "holder_alias", "holder_agent_id", "acquired_at", "expires_at"}
+)
+
+// csvTable flattens the result of a list command into a header and rows.
+// ok is false for results that are not list-shaped; printOutput prints
+// those as text.
+func csvTable(v any) (header []string, rows [][]string, ok bool) {
+	switch v := v.(type) {
+	case *awid.InboxResponse:
+		return mailCSVHeader, mailCSVRows(v.Messages), true
+	case []awid.InboxMessage:
+		return mailCSVHeader, mailCSVRows(v), true
+	case *chat.HistoryResult:
+		rows = make([][]string, 0, len(v.Messages))
+		for _, m := range v.Messages {
+			rows = append(rows, []string{
+				m.MessageID,
+				m.Timestamp,
+				m.FromAgent,
+				m.FromAddress,
+				m.Body,
+				strconv.FormatBool(m.SenderLeaving),
+				string(m.VerificationStatus),
+			})
+		}
+		return chatHistoryCSVHeader, rows, true
+	case *chat.ListResult:
+		rows = make([][]string, 0, len(v.Conversations))
+		for _, c := range v.Conversations {
+			rows = append(rows, []string{
+				c.SessionID,
+				c.TargetAgent,
+				c.Subject,
+				strconv.Itoa(c.UnreadCount),
+				c.LastActivity,
+				c.LastFrom,
+				c.LastMessage,
+				strconv.FormatBool(c.SenderWaiting),
+				c.CreatedAt,
+			})
+		}
+		return chatListCSVHeader, rows, true
+	case *aweb.ReservationListResponse:
+		rows = make([][]string, 0, len(v.Reservations))
+		for _, r := range v.Reservations {
+			rows = append(rows, []string{r.ResourceKey, r.HolderAlias, r.HolderAgentID, r.AcquiredAt, r.ExpiresAt})
+		}
+		return lockCSVHeader, rows, true
+	}
+	return nil, nil, false
+}
+
+func mailCSVRows(messages []awid.InboxMessage) [][]string {
+	rows := make([][]string, 0, len(messages))
+	for _, m := range messages {
+		threadID, readAt := "", ""
+		if m.ThreadID != nil {
+			threadID = *m.ThreadID
+		}
+		if m.ReadAt != nil {
+			readAt = *m.ReadAt
+		}
+		rows = append(rows, []string{
+			m.MessageID,
+			m.CreatedAt,
+			m.FromAlias,
+			m.FromAddress,
+			m.Subject,
+			m.Body,
+			string(m.Priority),
+			threadID,
+			readAt,
+			string(m.VerificationStatus),
+		})
+	}
+	return rows
+}
+
+// writeCSV writes a header row and one row per item. encoding/csv quotes
+// fields containing commas, quotes or newlines, so message bodies survive.
+func writeCSV(w io.Writer, header []string, rows [][]string) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	if err := cw.WriteAll(rows); err != nil {
+		return err
+	}
+	return cw.Error()
+}
@@ -1,9 +1,11 @@
 package main
 
 import (
+	"encoding/csv"
 	"strings"
 	"testing"
 
+	aweb "github.com/awebai/aw"
 	"github.com/awebai/aw/awid"
 	"github.com/awebai/aw/chat"
 )
@@ -41,6 +43,37 @@ func TestFormatChatSendUsesReplyMessageTagsNotReadReceipt(t *testing.T) {
 	}
 }
 
+func TestFormatChatEventLineTagsUrgentMessages(t *testing.T) {
+	urgent := formatChatEventLine(chat.Event{FromAgent: "bob", Body: "prod is down", Priority: awid.PriorityUrgent})
+	if urgent != "bob [urgent]: prod is down\n" {
+		t.Fatalf("urgent line=%q", urgent)
+	}
+	normal := formatChatEventLine(chat.Event{FromAgent: "bob", Body: "lunch?", Priority: awid.PriorityNormal})
+	if normal != "bob: lunch?\n" {
+		t.Fatalf("normal line=%q", normal)
+	}
+}
+
+func TestFormatChatSearchHighlightsMatches(t *testing.T) {
+	result := &chat.SearchResult{Query: "deploy", Messages: []chat.Event{{
+		FromAgent: "bob",
+		Body:      "Deploy \x1b[2Jnow",
+		Matches:   []awid.TextMatch{{Start: 0, End: 6}},
+	}}}
+
+	styled := formatChatSearchStyled(result, true)
+	if !strings.Contains(styled, "bob: \x1b[7mDeploy\x1b[0m \\x1b[2Jnow\n") {
+		t.Fatalf("styled=%q", styled)
+	}
+	plain := formatChatSearch(result)
+	if !strings.HasPrefix(plain, "Messages matching \"deploy\" (1):") || !strings.Contains(plain, "bob: Deploy \\x1b[2Jnow\n") {
+		t.Fatalf("plain=%q", plain)
+	}
+	if got := formatChatSearch(&chat.SearchResult{Query: "x"}); got != "No messages match \"x\"\n" {
+		t.Fatalf("empty=%q", got)
+	}
+}
+
 func TestFormatChatPendingOmitsOpenHintForGroupSession(t *testing.T) {
 	result := &chat.PendingResult{
 		Pending: []chat.PendingConversation{
@@ -59,6 +92,41 @@ func TestFormatChatPendingOmitsOpenHintForGroupSession(t *testing.T) {
 	}
 }
 
+func TestFormatChatListTabulatesConversations(t *testing.T) {
+	result := &chat.ListResult{Conversations: []chat.ConversationSummary{
+		{TargetAgent: "bob", UnreadCount: 2, LastFrom: "bob", LastMessage: "line one\nline two", Subject: "Deploy plan", SenderWaiting: true},
+		{TargetAgent: "carol"},
+	}}
+
+	out := formatChatList(result)
+	lines := strings.Split(strings.TrimRight(out, "\n"), "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[0], "WITH") {
+		t.Fatalf("unexpected table:\n%s", out)
+	}
+	if !strings.Contains(lines[1], "bob [Deploy plan] (waiting)") || !strings.Contains(lines[1], "bob: line one line two") {
+		t.Fatalf("row should show subject, waiting and a one-line last message:\n%s", out)
+	}
+	if !strings.Contains(lines[2], "carol") || !strings.HasSuffix(lines[2], "-") {
+		t.Fatalf("row without activity should use placeholders:\n%s", out)
+	}
+	if got := formatChatList(&chat.ListResult{}); got != "No conversations\n" {
+		t.Fatalf("empty list=%q", got)
+	}
+}
+
+func TestFormatChatPendingShowsSubject(t *testing.T) {
+	result := &chat.PendingResult{
+		Pending: []chat.PendingConversation{
+			{Participants: []string{"bob"}, LastFrom: "bob", UnreadCount: 2, Subject: "Deploy plan"},
+		},
+	}
+
+	out := formatChatPending(result)
+	if !strings.Contains(out, "CHAT: bob [Deploy plan] (unread: 2)") {
+		t.Fatalf("pending output should show the subject:\n%s", out)
+	}
+}
+
 func TestFormatChatPendingKeepsOpenHintForDirectSession(t *testing.T) {
 	result := &chat.PendingResult{
 		Pending: []chat.PendingConversation{
@@ -100,6 +168,91 @@ func TestFormatMailInboxPrefersFromAddress(t *testing.T) {
 	}
 }
 
+func TestFormatMailInboxListsAttachments(t *testing.T) {
+	resp := &awid.InboxResponse{
+		Messages: []awid.InboxMessage{
+			{
+				FromAlias: "carol",
+				Body:      "see attached",
+				Attachments: []awid.Attachment{
+					{Name: "report.json", ContentType: "application/json", Data: []byte(`{"ok":true}`)},
+					{Name: "log", URL: "https://example.com/log.txt"},
+				},
+			},
+		},
+	}
+
+	out := formatMailInbox(resp)
+	if !strings.Contains(out, "  attachment: report.json (application/json, 11 bytes)") {
+		t.Fatalf("missing inline attachment:\n%s", out)
+	}
+	if !strings.Contains(out, "  attachment: log <https://example.com/log.txt>") {
+		t.Fatalf("missing url attachment:\n%s", out)
+	}
+}
+
+func TestFormatMailShowIncludesReadStatusAndBody(t *testing.T) {
+	readAt := "2026-03-10T10:05:00Z"
+	msg := &awid.InboxMessage{
+		FromAlias: "carol",
+		Subject:   "handoff",
+		Body:      "line one\nline two",
+		Priority:  awid.PriorityHigh,
+		CreatedAt: "2026-03-10T10:00:00Z",
+		ReadAt:    &readAt,
+	}
+
+	out := formatMailShow(msg)
+	for _, want := range []string{"From:     carol", "Subject:  handoff", "Priority: high", "Read:     2026-03-10T10:05:00Z", "\nline one\nline two\n"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("missing %q in:\n%s", want, out)
+		}
+	}
+
+	msg.ReadAt = nil
+	if out := formatMailShow(msg); !strings.Contains(out, "Read:     unread") {
+		t.Fatalf("unread message should say so:\n%s", out)
+	}
+}
+
+func TestFormatMailThreadIndentsBodiesInOrder(t *testing.T) {
+	messages := []awid.InboxMessage{
+		{FromAlias: "carol", Subject: "handoff", Body: "first\nsecond", CreatedAt: "2026-03-10T10:00:00Z"},
+		{FromAlias: "dave", Body: "ack", CreatedAt: "2026-03-10T10:05:00Z"},
+	}
+
+	out := formatMailThread(messages)
+	for _, want := range []string{"Thread (2 messages):", "carol — handoff\n  first\n  second\n", "dave\n  ack\n"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("missing %q in:\n%s", want, out)
+		}
+	}
+	if strings.Index(out, "carol") > strings.Index(out, "dave") {
+		t.Fatalf("messages out of order:\n%s", out)
+	}
+	if out := formatMailThread([]awid.InboxMessage{}); out != "No messages in thread.\n" {
+		t.Fatalf("empty thread: %q", out)
+	}
+}
+
+func TestFormatLockAcquireReportsTakeover(t *testing.T) {
+	resp := &aweb.ReservationAcquireResponse{Status: "acquired", ResourceKey: "deploy/prod"}
+	if out := formatLockAcquire(resp); out != "Locked deploy/prod\n" {
+		t.Fatalf("out=%q", out)
+	}
+
+	resp.Status = "stolen"
+	resp.StolenFromAlias = "bob"
+	if out := formatLockAcquire(resp); !strings.Contains(out, "took over expired lock from bob") {
+		t.Fatalf("out=%q", out)
+	}
+
+	resp.FencingToken = 42
+	if out := formatLockAcquire(resp); out != "Locked deploy/prod (took over expired lock from bob, fencing token 42)\n" {
+		t.Fatalf("out=%q", out)
+	}
+}
+
 func TestFormatMailInboxFallsBackToStableID(t *testing.T) {
 	resp := &awid.InboxResponse{
 		Messages: []awid.InboxMessage{
@@ -438,3 +591,74 @@ func TestFormatChatSendPendingTreatsAliasTargetStableReplyAsIncoming(t *testing.
 		t.Fatalf("pending chat send output should not misclassify stable-id reply to alias target as outgoing:\n%s", out)
 	}
 }
+
+func TestFormatLockListShowsPageSummary(t *testing.T) {
+	t.Parallel()
+
+	next := "cursor-2"
+	out := formatLockList(&aweb.ReservationListResponse{
+		Reservations: []aweb.ReservationView{{ResourceKey: "src/a.go", HolderAlias: "alice"}},
+		HasMore:      true,
+		NextCursor:   &next,
+		Total:        3,
+	})
+	if !strings.Contains(out, "Showing 1 of 3 locks.") {
+		t.Fatalf("missing page summary:\n%s", out)
+	}
+	if !strings.Contains(out, "--cursor cursor-2") {
+		t.Fatalf("missing next cursor hint:\n%s", out)
+	}
+
+	out = formatLockList(&aweb.ReservationListResponse{
+		Reservations: []aweb.ReservationView{{ResourceKey: "src/a.go", HolderAlias: "alice"}},
+		Total:        1,
+	})
+	if strings.Contains(out, "Showing") {
+		t.Fatalf("unexpected page summary for complete list:\n%s", out)
+	}
+}
+
+func TestCSVTableQuotesMailBodies(t *testing.T) {
+	thread := "thread-1"
+	resp := &awid.InboxResponse{Messages: []awid.InboxMessage{{
+		MessageID: "m1",
+		CreatedAt: "2026-03-01T12:00:00Z",
+		FromAlias: "bob",
+		Subject:   "Q1, final",
+		Body:      "line one, with comma\nline \"two\"",
+		Priority:  "normal",
+		ThreadID:  &thread,
+	}}}
+
+	header, rows, ok := csvTable(resp)
+	if !ok {
+		t.Fatal("inbox should be list-shaped")
+	}
+	var sb strings.Builder
+	if err := writeCSV(&sb, header, rows); err != nil {
+		t.Fatal(err)
+	}
+	records, err := csv.NewReader(strings.NewReader(sb.String())).ReadAll()
+	if err != nil {
+		t.Fatalf("output is not valid CSV: %v\n%s", err, sb.String())
+	}
+	if len(records) != 2 || strings.Join(records[0], ",") != strings.Join(mailCSVHeader, ",") {
+		t.Fatalf("records=%q", records)
+	}
+	if records[1][4] != "Q1, final" || records[1][5] != "line one, with comma\nline \"two\"" || records[1][7] != "thread-1" {
+		t.Fatalf("row=%q", records[1])
+	}
+	if _, _, ok := csvTable(&chat.SendResult{}); ok {
+		t.Fatal("send results are not list-shaped")
+	}
+}
+
+func TestCSVTableLockList(t *testing.T) {
+	resp := &aweb.ReservationListResponse{Reservations: []aweb.ReservationView{
+		{ResourceKey: "src/a.go", HolderAlias: "alice", HolderAgentID: "agent-1", AcquiredAt: "2026-03-01T12:00:00Z", ExpiresAt: "2026-03-01T13:00:00Z"},
+	}}
+	header, rows, ok := csvTable(resp)
+	if !ok || len(rows) != 1 || len(rows[0]) != len(header) || rows[0][0] != "src/a.go" || rows[0][1] != "alice" {
+		t.Fatalf("header=%q rows=%q ok=%v", header, rows, ok)
+	}
+}
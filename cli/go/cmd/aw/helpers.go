@@ -78,7 +78,9 @@ func resolveSelectionForDirWithTeamOverride(workingDir, teamIDOverride string) (
 		ServerName:        serverFlag,
 		TeamIDOverride:    strings.TrimSpace(teamIDOverride),
 		WorkingDir:        workingDir,
-		AllowEnvOverrides: true,
+		UseHumanAccount:   asHumanFlag,
+		AllowEnvOverrides: !noEnvFlag,
+		Logf:              debugLog,
 	})
 	if err != nil {
 		return nil, err
@@ -373,7 +375,7 @@ func resolveClientSelectionForAliasTarget(ctx context.Context, targetAlias strin
 
 func shouldSearchOtherLocalTeamsForAlias(sel *awconfig.Selection, targetAlias string) bool {
 	targetAlias = strings.TrimSpace(targetAlias)
-	if sel == nil || strings.TrimSpace(teamFlag) != "" || targetAlias == "" {
+	if sel == nil || strings.TrimSpace(teamFlag) != "" || asHumanFlag || targetAlias == "" {
 		return false
 	}
 	if strings.Contains(targetAlias, "/") || strings.Contains(targetAlias, "~") || strings.HasPrefix(targetAlias, "did:") {
@@ -390,7 +392,7 @@ func clientHasAgentAlias(ctx context.Context, c *aweb.Client, targetAlias string
 	if c == nil || c.Client == nil {
 		return false, nil
 	}
-	resp, err := c.Client.ListAgents(ctx)
+	resp, err := c.Client.ListAgentsCached(ctx)
 	if err != nil {
 		return false, err
 	}
@@ -440,6 +442,13 @@ func configureResolvedClient(c *aweb.Client, sel *awconfig.Selection, baseURL st
 		c.SetStableID(sel.StableID)
 	}
 	c.SetRequireRecipientBindingForDirectAddresses(strings.TrimSpace(sel.Lifetime) == awid.LifetimePersistent || strings.TrimSpace(sel.StableID) != "")
+	c.SetReservationPrefix(sel.ReservationPrefix)
+	if err := configureClientTLS(c, sel); err != nil {
+		return err
+	}
+	if verboseLevel > 0 && !quietFlag {
+		c.SetObserver(newVerboseObserver(baseURL))
+	}
 
 	pinPath, err := awconfig.DefaultKnownAgentsPath()
 	if err != nil {
@@ -473,11 +482,33 @@ func configureResolvedClient(c *aweb.Client, sel *awconfig.Selection, baseURL st
 	return nil
 }
 
+// configureClientTLS applies ca_cert from workspace.yaml and the
+// AWEB_TLS_INSECURE=1 escape hatch, warning on stderr when verification is
+// off. It runs before the registry resolver takes the client's HTTP client,
+// so registry lookups get the same trust.
+func configureClientTLS(c *aweb.Client, sel *awconfig.Selection) error {
+	if err := c.ConfigureTLS(sel); err != nil {
+		return err
+	}
+	if aweb.TLSInsecureEnv() {
+		fmt.Fprintln(os.Stderr, "Warning: AWEB_TLS_INSECURE=1 disables TLS certificate verification; anyone on the network path can impersonate the server and read or alter your requests. Set ca_cert in .aw/workspace.yaml instead.")
+		c.SetInsecureSkipVerify()
+	}
+	return nil
+}
+
 func resolveClient() (*aweb.Client, error) {
 	c, _, err := resolveClientSelection()
 	return c, err
 }
 
+// warnedAPIPrefixURLs records base URLs already warned about, so a command
+// that cleans the same URL several times warns once.
+var warnedAPIPrefixURLs sync.Map
+
+// cleanBaseURL returns raw without a trailing slash, query or fragment. A
+// base URL that ends in the API prefix (/v1) is cut back to the server root
+// with a warning, since the client adds the prefix itself.
 func cleanBaseURL(raw string) (string, error) {
 	raw = strings.TrimSpace(raw)
 	if raw == "" {
@@ -494,6 +525,12 @@ func cleanBaseURL(raw string) (string, error) {
 	u.RawPath = ""
 	u.RawQuery = ""
 	u.Fragment = ""
+	if trimmed, ok := strings.CutSuffix(u.Path, awid.DefaultAPIPrefix); ok {
+		u.Path = trimmed
+		if _, warned := warnedAPIPrefixURLs.LoadOrStore(raw, struct{}{}); !warned {
+			fmt.Fprintf(os.Stderr, "Warning: base URL %s ends in %s; using %s. Drop the %s from the configured URL.\n", raw, awid.DefaultAPIPrefix, strings.TrimSuffix(u.String(), "/"), awid.DefaultAPIPrefix)
+		}
+	}
 	return strings.TrimSuffix(u.String(), "/"), nil
 }
 
@@ -547,12 +584,6 @@ func resolveWorkingBaseURLContext(ctx context.Context, raw string) (string, erro
 	}
 
 	add(base)
-	if strings.HasSuffix(base, "/v1") {
-		add(strings.TrimSuffix(base, "/v1"))
-	}
-	if strings.HasSuffix(base, "/api/v1") {
-		add(strings.TrimSuffix(base, "/v1"))
-	}
 	if strings.HasSuffix(base, "/api") {
 		add(strings.TrimSuffix(base, "/api"))
 	}
@@ -585,8 +616,16 @@ func resolveWorkingBaseURLContext(ctx context.Context, raw string) (string, erro
 	return "", fmt.Errorf("no aweb API detected at %q (tried %v)", raw, candidates)
 }
 
+// envAwebURL returns AWEB_URL from the environment, or "" when --no-env is set.
+func envAwebURL() string {
+	if noEnvFlag {
+		return ""
+	}
+	return strings.TrimSpace(os.Getenv("AWEB_URL"))
+}
+
 func resolveAuthenticatedBaseURL(raw string) (string, error) {
-	if envBaseURL := strings.TrimSpace(os.Getenv("AWEB_URL")); envBaseURL != "" {
+	if envBaseURL := envAwebURL(); envBaseURL != "" {
 		return resolveWorkingBaseURL(envBaseURL)
 	}
 	return cleanBaseURL(raw)
@@ -596,7 +635,7 @@ func configureBaseURLFallback(c *aweb.Client, sel *awconfig.Selection, baseURL s
 	if c == nil || sel == nil || strings.TrimSpace(sel.ServerName) == "" {
 		return
 	}
-	if strings.TrimSpace(os.Getenv("AWEB_URL")) != "" {
+	if envAwebURL() != "" {
 		return
 	}
 	state := &baseURLFallbackState{
@@ -608,18 +647,26 @@ func configureBaseURLFallback(c *aweb.Client, sel *awconfig.Selection, baseURL s
 			}
 		},
 	}
+	// Keep the TLS settings configureClientTLS gave the client's own
+	// transport.
+	apiBase, sseBase := http.DefaultTransport, http.DefaultTransport
+	if t, ok := c.HTTPClient().Transport.(*http.Transport); ok {
+		apiBase, sseBase = t, t.Clone()
+	}
 	c.SetHTTPClient(&http.Client{
 		Timeout: awid.DefaultTimeout,
 		Transport: &baseURLFallbackTransport{
-			base:  http.DefaultTransport,
+			base:  apiBase,
 			state: state,
 		},
+		CheckRedirect: awid.CheckRedirect,
 	})
 	c.SetSSEClient(&http.Client{
 		Transport: &baseURLFallbackTransport{
-			base:  http.DefaultTransport,
+			base:  sseBase,
 			state: state,
 		},
+		CheckRedirect: awid.CheckRedirect,
 	})
 }
 
@@ -860,7 +907,7 @@ func resolveBaseURLForInit(urlVal, serverVal string) (baseURL string, serverName
 	serverName = strings.TrimSpace(serverVal)
 
 	if baseURL == "" {
-		baseURL = strings.TrimSpace(os.Getenv("AWEB_URL"))
+		baseURL = envAwebURL()
 	}
 	if baseURL == "" && serverName != "" {
 		baseURL, err = awconfig.DeriveBaseURLFromServerName(serverName)
@@ -1080,25 +1127,64 @@ func printJSON(v any) {
 	fmt.Println(string(data))
 }
 
+// printOutput prints v with formatter, or as JSON with --json. Under
+// --output json-stream v is printed compactly on one line; list commands use
+// printJSONLines instead so each item gets its own line. Under --output csv
+// list results print as a table (see csvTable) and anything else as text.
 func printOutput(v any, formatter func(v any) string) {
+	if csvOutput() {
+		if header, rows, ok := csvTable(v); ok {
+			_ = writeCSV(os.Stdout, header, rows)
+			return
+		}
+		fmt.Print(terminalText(formatter(v)))
+		return
+	}
+	if jsonStreamOutput() {
+		printJSONLine(v)
+		return
+	}
 	if jsonFlag {
 		printJSON(v)
 		return
 	}
-	fmt.Print(formatter(v))
+	fmt.Print(terminalText(formatter(v)))
 }
 
-func parseTimeBestEffort(value string) (time.Time, bool) {
-	if value == "" {
-		return time.Time{}, false
-	}
-	if ts, err := time.Parse(time.RFC3339Nano, value); err == nil {
-		return ts, true
+// terminalText escapes control characters in text output so a message body
+// cannot drive the user's terminal. --raw-output turns it off; JSON and CSV
+// output are never altered.
+func terminalText(s string) string {
+	if rawOutputFlag {
+		return s
 	}
-	if ts, err := time.Parse(time.RFC3339, value); err == nil {
-		return ts, true
+	return aweb.SanitizeForTerminal(s)
+}
+
+func jsonStreamOutput() bool {
+	return outputFlag == outputJSONStream
+}
+
+func csvOutput() bool {
+	return outputFlag == outputCSV
+}
+
+func printJSONLine(v any) {
+	_ = json.NewEncoder(os.Stdout).Encode(v)
+}
+
+// printJSONLines prints each item as one compact JSON object per line, the
+// shape `jq -c` and `while read` loops consume.
+func printJSONLines[T any](items []T) {
+	enc := json.NewEncoder(os.Stdout)
+	for _, item := range items {
+		_ = enc.Encode(item)
 	}
-	return time.Time{}, false
+}
+
+func parseTimeBestEffort(value string) (time.Time, bool) {
+	ts, err := awid.ParseTimestamp(value)
+	return ts, err == nil
 }
 
 func formatTimeAgo(timestamp string) string {
@@ -1147,15 +1233,9 @@ func formatDuration(seconds int) string {
 }
 
 func ttlRemainingSeconds(expiresAt string, now time.Time) int {
-	if expiresAt == "" {
-		return 0
-	}
-	ts, err := time.Parse(time.RFC3339Nano, expiresAt)
+	ts, err := awid.ParseTimestamp(expiresAt)
 	if err != nil {
-		ts, err = time.Parse(time.RFC3339, expiresAt)
-		if err != nil {
-			return 0
-		}
+		return 0
 	}
 	secs := int(math.Ceil(ts.Sub(now).Seconds()))
 	if secs < 0 {
@@ -1194,6 +1274,21 @@ func checkVerificationRequired(err error) string {
 	return hint
 }
 
+// serverUnreachableHint replaces a transport error, such as "dial tcp ...:
+// connect: connection refused", with the server URL and what to check.
+// It returns "" when err is not a connection failure.
+func serverUnreachableHint(err error) string {
+	var unreachable *awid.ServerUnreachableError
+	if !errors.As(err, &unreachable) {
+		return ""
+	}
+	hint := fmt.Sprintf("cannot reach the aweb server at %s: %s", unreachable.BaseURL, unreachable.Hint())
+	if unreachable.Reason == awid.UnreachableTLS {
+		hint += " For a private CA, set ca_cert in " + awconfig.DefaultWorktreeWorkspaceRelativePath() + "."
+	}
+	return hint
+}
+
 // networkError wraps an error with a user-friendly message for network 404 errors.
 // When a network send fails because the target agent doesn't exist, the raw error
 // is "aweb: http 404: ..." which looks like a broken endpoint. This rewrites it
@@ -1254,7 +1349,7 @@ func checkIdentityMismatch(workingDir string, sel *awconfig.Selection) error {
 }
 
 func debugLog(format string, args ...any) {
-	if debugFlag {
+	if debugFlag || verboseLevel >= 2 {
 		fmt.Fprintf(os.Stderr, "[debug] "+format+"\n", args...)
 	}
 }
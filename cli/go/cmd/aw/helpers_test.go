@@ -1,8 +1,13 @@
 package main
 
 import (
+	"context"
+	"fmt"
+	"net"
 	"strings"
 	"testing"
+
+	"github.com/awebai/aw/awid"
 )
 
 func TestPromptIndexedChoiceRequiresNumberWhenNoDefault(t *testing.T) {
@@ -59,3 +64,30 @@ func TestSelectRoleFromAvailableRolesRejectsUnknownExplicitRole(t *testing.T) {
 		t.Fatalf("unexpected error: %v", err)
 	}
 }
+
+func TestServerUnreachableHintReplacesTransportError(t *testing.T) {
+	t.Parallel()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	baseURL := "http://" + listener.Addr().String()
+	_ = listener.Close()
+	c, err := awid.New(baseURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = fmt.Errorf("list locks: %w", c.Get(context.Background(), "/v1/reservations", nil))
+
+	msg := serverUnreachableHint(err)
+	if !strings.Contains(msg, baseURL) || !strings.Contains(msg, "is the aweb server running") {
+		t.Fatalf("hint=%q", msg)
+	}
+	if strings.Contains(msg, "dial tcp") {
+		t.Fatalf("hint repeats the raw error: %q", msg)
+	}
+	if serverUnreachableHint(fmt.Errorf("other")) != "" {
+		t.Fatal("hint for an unrelated error")
+	}
+}
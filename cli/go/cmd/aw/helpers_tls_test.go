@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAwReachesPrivateCAServerWithCACertOrInsecureOptIn(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/reservations":
+			_ = json.NewEncoder(w).Encode(map[string]any{"reservations": []any{}, "has_more": false})
+		case "/v1/agents/heartbeat":
+			w.WriteHeader(http.StatusOK)
+		default:
+			t.Errorf("unexpected %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	tmp := t.TempDir()
+	bin := filepath.Join(tmp, "aw")
+	buildAwBinary(t, ctx, bin)
+
+	lockList := func(dir string, env ...string) (string, string, error) {
+		run := exec.CommandContext(ctx, bin, "lock", "list")
+		run.Env = append(testCommandEnv(dir), env...)
+		run.Dir = dir
+		var stdout, stderr strings.Builder
+		run.Stdout, run.Stderr = &stdout, &stderr
+		err := run.Run()
+		return stdout.String(), stderr.String(), err
+	}
+
+	plain := filepath.Join(tmp, "plain")
+	if err := os.MkdirAll(plain, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeWorkspaceBindingForTest(t, plain, workspaceBinding(server.URL, "backend:demo", "alice", "workspace-1"))
+	if _, stderr, err := lockList(plain); err == nil || !strings.Contains(stderr, "certificate") {
+		t.Fatalf("expected a certificate error without ca_cert, err=%v stderr:\n%s", err, stderr)
+	}
+	stdout, stderr, err := lockList(plain, "AWEB_TLS_INSECURE=1")
+	if err != nil {
+		t.Fatalf("AWEB_TLS_INSECURE=1 run failed: %v\n%s%s", err, stdout, stderr)
+	}
+	if !strings.Contains(stderr, "disables TLS certificate verification") {
+		t.Fatalf("expected a warning for AWEB_TLS_INSECURE, stderr:\n%s", stderr)
+	}
+	if _, _, err := lockList(plain, "AWEB_TLS_INSECURE=true"); err == nil {
+		t.Fatal("AWEB_TLS_INSECURE=true should not disable verification; only 1 does")
+	}
+
+	trusted := filepath.Join(tmp, "trusted")
+	if err := os.MkdirAll(filepath.Join(trusted, ".aw"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(filepath.Join(trusted, ".aw", "ca.pem"), certPEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	binding := workspaceBinding(server.URL, "backend:demo", "alice", "workspace-1")
+	binding.CACert = ".aw/ca.pem"
+	writeWorkspaceBindingForTest(t, trusted, binding)
+	if stdout, stderr, err := lockList(trusted); err != nil {
+		t.Fatalf("ca_cert run failed: %v\n%s%s", err, stdout, stderr)
+	}
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -22,7 +23,34 @@ team-architecture flows:
 
 - connect with an existing team certificate already present in .aw/
 - create a hosted aweb.ai account with --hosted
-- launch guided onboarding in a TTY when this directory is still clean`,
+- launch guided onboarding in a TTY when this directory is still clean
+
+With AWEB_API_KEY set, or a key stored by ` + "`aw login`" + ` for the server
+given with --aweb-url, the server suggests an alias unless one is given
+with --alias or AWEB_ALIAS. --reuse-alias binds the agent that already
+holds that alias instead of letting the server pick a new one, and fails
+if the server cannot reuse it. --force ignores --alias and AWEB_ALIAS and
+always asks for a fresh allocation. In a TTY, guided onboarding only
+prompts for an alias when none was given, so --reuse-alias skips the
+prompt and --force brings it back even when AWEB_ALIAS is set.
+
+When many agents init against one team at once, the server can report an
+alias collision. --alias-retries N retries an ephemeral init up to N more
+times, each time with a server-allocated alias, backing off between
+attempts; the output reports how many attempts were needed.
+
+--api-key-stdin reads the API key from stdin instead, so it stays out of
+shell history and the process list; it takes precedence over AWEB_API_KEY
+and a stored key, and surrounding whitespace is trimmed.
+
+--expect-fingerprint pins the SHA-256 fingerprint of the server's TLS
+certificate for the API key bootstrap call; on mismatch nothing is
+written. Bootstrapping over plain http prints a warning, since that
+channel is unauthenticated.
+
+API key bootstrap prints the minted workspace API key to stderr before
+writing .aw/. If a local write then fails, init exits nonzero and the key
+can still be added to .aw/workspace.yaml by hand.`,
 	PersistentPreRun: func(cmd *cobra.Command, args []string) {
 		loadDotenvBestEffort()
 		// No heartbeat for init — no credentials yet.
@@ -31,23 +59,32 @@ team-architecture flows:
 }
 
 var (
-	initURL            string
-	initAwebURL        string
-	initAWIDRegistry   string
-	initHosted         bool
-	initHostedUsername string
-	initAlias          string
-	initName           string
-	initReachability   string
-	initInjectDocs     bool
-	initSetupHooks     bool
-	initSetupChannel   bool
-	initHumanName      string
-	initAgentType      string
-	initWriteContext   bool
-	initPrintExports   bool
-	initRole           string
-	initPersistent     bool
+	initURL               string
+	initAwebURL           string
+	initAWIDRegistry      string
+	initHosted            bool
+	initHostedUsername    string
+	initAlias             string
+	initName              string
+	initReachability      string
+	initInjectDocs        bool
+	initSetupHooks        bool
+	initSetupChannel      bool
+	initHumanName         string
+	initAgentType         string
+	initMeta              []string
+	initWriteContext      bool
+	initPrintExports      bool
+	initRole              string
+	initPersistent        bool
+	initReuseAlias        bool
+	initForce             bool
+	initAliasRetries      int
+	initExpectFingerprint string
+	initAPIKeyStdin       bool
+
+	// initStdinAPIKey holds the key read for --api-key-stdin.
+	initStdinAPIKey string
 )
 
 var (
@@ -76,10 +113,16 @@ func init() {
 	initCmd.Flags().BoolVar(&initSetupChannel, "setup-channel", false, "Set up Claude Code channel MCP server for real-time coordination")
 	initCmd.Flags().StringVar(&initHumanName, "human-name", "", "Human name (default: AWEB_HUMAN or $USER)")
 	initCmd.Flags().StringVar(&initAgentType, "agent-type", "", "Runtime type (default: AWEB_AGENT_TYPE or agent)")
+	initCmd.Flags().StringArrayVar(&initMeta, "meta", nil, "Agent self-description as key=value or key=@file.json, e.g. model=... or capabilities=@caps.json (repeatable)")
 	initCmd.Flags().BoolVar(&initWriteContext, "write-context", true, "Ensure .aw/context exists in the current directory")
 	initCmd.Flags().BoolVar(&initPrintExports, "print-exports", false, "Print shell export lines after JSON output")
 	addWorkspaceRoleFlags(initCmd, &initRole, "Workspace role name (must match a role in the active team roles bundle)")
 	initCmd.Flags().BoolVar(&initPersistent, "persistent", false, "Create a durable self-custodial identity instead of the default ephemeral identity")
+	initCmd.Flags().BoolVar(&initReuseAlias, "reuse-alias", false, "Reuse the agent already holding --alias (or AWEB_ALIAS) instead of allocating a new alias")
+	initCmd.Flags().BoolVar(&initForce, "force", false, "Always request a fresh server-allocated alias, ignoring --alias and AWEB_ALIAS")
+	initCmd.Flags().IntVar(&initAliasRetries, "alias-retries", 0, "With AWEB_API_KEY, retry up to N times with a server-allocated alias when the server reports an alias collision")
+	initCmd.Flags().BoolVar(&initAPIKeyStdin, "api-key-stdin", false, "Read the API key for bootstrap from stdin instead of AWEB_API_KEY")
+	initCmd.Flags().StringVar(&initExpectFingerprint, "expect-fingerprint", "", "With AWEB_API_KEY, require the server's TLS certificate to have this SHA-256 fingerprint before trusting the minted credentials")
 
 	rootCmd.AddCommand(initCmd)
 }
@@ -93,6 +136,23 @@ func runInit(cmd *cobra.Command, args []string) error {
 	if initSetupChannel && initSetupHooks {
 		return fmt.Errorf("--setup-channel and --setup-hooks are mutually exclusive: the channel supersedes the notify hook")
 	}
+	if err := validateInitAliasModeFlags(); err != nil {
+		return err
+	}
+	if initAPIKeyStdin {
+		apiKey, err := readAPIKey(cmd.InOrStdin())
+		if err != nil {
+			return fmt.Errorf("--api-key-stdin: %w", err)
+		}
+		initStdinAPIKey = apiKey
+	}
+	if strings.TrimSpace(initExpectFingerprint) != "" && resolveInitAPIKey() == "" {
+		return usageError("--expect-fingerprint applies to API key bootstrap; set AWEB_API_KEY or run `aw login`")
+	}
+	metadata, err := parseMetaFlags(initMeta)
+	if err != nil {
+		return err
+	}
 
 	// When only --inject-docs, --setup-hooks, or --setup-channel are requested,
 	// operate on the existing workspace without running the full init flow.
@@ -124,17 +184,22 @@ func runInit(cmd *cobra.Command, args []string) error {
 			return err
 		}
 		result, err := runAPIKeyBootstrapInit(apiKeyInitRequest{
-			WorkingDir:   wd,
-			AwebURL:      awebURL,
-			RegistryURL:  registryURL,
-			APIKey:       apiKey,
-			Name:         strings.TrimSpace(initName),
-			Alias:        resolveAliasValue(strings.TrimSpace(initAlias)),
-			Reachability: strings.TrimSpace(initReachability),
-			Role:         resolveRequestedRole(strings.TrimSpace(initRole)),
-			HumanName:    resolveHumanNameValue(strings.TrimSpace(initHumanName)),
-			AgentType:    resolveAgentTypeValue(strings.TrimSpace(initAgentType)),
-			Persistent:   initPersistent,
+			WorkingDir:        wd,
+			AwebURL:           awebURL,
+			RegistryURL:       registryURL,
+			APIKey:            apiKey,
+			Name:              strings.TrimSpace(initName),
+			Alias:             resolveInitAlias(),
+			Reachability:      strings.TrimSpace(initReachability),
+			Role:              resolveRequestedRole(strings.TrimSpace(initRole)),
+			HumanName:         resolveHumanNameValue(strings.TrimSpace(initHumanName)),
+			AgentType:         resolveAgentTypeValue(strings.TrimSpace(initAgentType)),
+			Metadata:          metadata,
+			Persistent:        initPersistent,
+			ReuseAlias:        initReuseAlias,
+			ForceNewAlias:     initForce,
+			AliasRetries:      initAliasRetries,
+			ExpectFingerprint: initExpectFingerprint,
 		})
 		if err != nil {
 			return err
@@ -166,7 +231,8 @@ func runInit(cmd *cobra.Command, args []string) error {
 				return err
 			}
 			result, err := initCertificateConnectWithOptions(wd, serviceURLs.AwebURL, certificateConnectOptions{
-				Role: resolveRequestedRole(strings.TrimSpace(initRole)),
+				Role:     resolveRequestedRole(strings.TrimSpace(initRole)),
+				Metadata: metadata,
 			})
 			if err != nil {
 				return err
@@ -206,10 +272,11 @@ func runInit(cmd *cobra.Command, args []string) error {
 				WorkingDir:  wd,
 				AwebURL:     awebURL,
 				RegistryURL: registryURL,
-				Alias:       resolveAliasValue(strings.TrimSpace(initAlias)),
+				Alias:       resolveInitAlias(),
 				Role:        resolveRequestedRole(strings.TrimSpace(initRole)),
 				HumanName:   resolveHumanNameValue(strings.TrimSpace(initHumanName)),
 				AgentType:   resolveAgentTypeValue(strings.TrimSpace(initAgentType)),
+				Metadata:    metadata,
 			})
 			if err != nil {
 				if isRegistryUnavailableError(err) {
@@ -241,12 +308,13 @@ func runInit(cmd *cobra.Command, args []string) error {
 				if initPersistent {
 					return strings.TrimSpace(initAlias)
 				}
-				return resolveAliasValue(strings.TrimSpace(initAlias))
+				return resolveInitAlias()
 			}(),
 			Name:               strings.TrimSpace(initName),
 			Reachability:       strings.TrimSpace(initReachability),
 			HumanName:          resolveHumanNameValue(strings.TrimSpace(initHumanName)),
 			AgentType:          resolveAgentTypeValue(strings.TrimSpace(initAgentType)),
+			Metadata:           metadata,
 			Role:               resolveRequestedRole(strings.TrimSpace(initRole)),
 			Persistent:         initPersistent,
 			AskPostCreateSetup: true,
@@ -324,7 +392,7 @@ func resolveInitAwebURLOverride() string {
 		value = strings.TrimSpace(initURL)
 	}
 	if value == "" {
-		value = strings.TrimSpace(os.Getenv("AWEB_URL"))
+		value = envAwebURL()
 	}
 	return value
 }
@@ -367,7 +435,7 @@ func hostedInitRequested() bool {
 // initNeedsFullInit returns true if the user passed flags that require the
 // full init flow, or if no local workspace binding exists yet (first-time init).
 func initNeedsFullInit() bool {
-	if initURL != "" || initAwebURL != "" || initAWIDRegistry != "" || initAlias != "" || initName != "" || initReachability != "" || initRole != "" || initPersistent {
+	if initURL != "" || initAwebURL != "" || initAWIDRegistry != "" || initAlias != "" || initName != "" || initReachability != "" || initRole != "" || initPersistent || initReuseAlias || initForce || initAliasRetries > 0 || initExpectFingerprint != "" || initAPIKeyStdin || len(initMeta) > 0 {
 		return true
 	}
 	wd, _ := os.Getwd()
@@ -432,6 +500,40 @@ func resolveAgentTypeValue(value string) string {
 	return "agent"
 }
 
+// parseMetaFlags turns repeated --meta key=value flags into agent metadata.
+// A value of @path is read from that file as JSON, for descriptors too large
+// for the command line. A later flag for the same key wins; nil means no
+// metadata was given.
+func parseMetaFlags(values []string) (map[string]any, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+	metadata := make(map[string]any, len(values))
+	for _, raw := range values {
+		key, value, ok := strings.Cut(raw, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, usageError("invalid --meta %q; expected key=value or key=@file.json", raw)
+		}
+		value = strings.TrimSpace(value)
+		path, fromFile := strings.CutPrefix(value, "@")
+		if !fromFile {
+			metadata[key] = value
+			continue
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, usageError("read --meta %s: %v", key, err)
+		}
+		var decoded any
+		if err := json.Unmarshal(data, &decoded); err != nil {
+			return nil, usageError("--meta %s: %s is not valid JSON: %v", key, path, err)
+		}
+		metadata[key] = decoded
+	}
+	return metadata, nil
+}
+
 func resolveAliasValue(explicit string) string {
 	if v := strings.TrimSpace(explicit); v != "" {
 		return v
@@ -439,6 +541,31 @@ func resolveAliasValue(explicit string) string {
 	return strings.TrimSpace(os.Getenv("AWEB_ALIAS"))
 }
 
+// resolveInitAlias returns the alias aw init should request, honoring
+// --force, which always leaves allocation to the server.
+func resolveInitAlias() string {
+	if initForce {
+		return ""
+	}
+	return resolveAliasValue(strings.TrimSpace(initAlias))
+}
+
+func validateInitAliasModeFlags() error {
+	if initReuseAlias && initForce {
+		return usageError("--reuse-alias and --force are mutually exclusive")
+	}
+	if initForce && strings.TrimSpace(initAlias) != "" {
+		return usageError("--force requests a fresh alias and cannot be combined with --alias")
+	}
+	if initAliasRetries < 0 {
+		return usageError("--alias-retries must be 0 or more")
+	}
+	if initAliasRetries > 0 && initReuseAlias {
+		return usageError("--alias-retries falls back to a server-allocated alias and cannot be combined with --reuse-alias")
+	}
+	return nil
+}
+
 func resolveRequestedRole(explicit string) string {
 	if v := strings.TrimSpace(explicit); v != "" {
 		return v
@@ -5,6 +5,7 @@ import (
 	"context"
 	"crypto/ed25519"
 	"crypto/sha256"
+	"crypto/tls"
 	"encoding/base64"
 	"encoding/hex"
 	"encoding/json"
@@ -38,7 +39,24 @@ type apiKeyInitRequest struct {
 	Role         string
 	HumanName    string
 	AgentType    string
+	Metadata     map[string]any
 	Persistent   bool
+	// ReuseAlias asks the server to bind the existing agent that already
+	// holds Alias instead of allocating a new one.
+	ReuseAlias bool
+	// ForceNewAlias drops any requested alias so the server always
+	// allocates a fresh one.
+	ForceNewAlias bool
+	// AliasRetries is how many more times an ephemeral init is attempted,
+	// each time with a server-allocated alias, after the server reports an
+	// alias collision.
+	AliasRetries int
+	// ExpectFingerprint pins the SHA-256 fingerprint of the server's TLS
+	// leaf certificate for the workspace init call.
+	ExpectFingerprint string
+	// KeyNotice receives the minted workspace API key before anything is
+	// written to disk; nil means os.Stderr.
+	KeyNotice io.Writer
 }
 
 type apiKeyBootstrapRequest struct {
@@ -52,6 +70,9 @@ type apiKeyBootstrapRequest struct {
 	HumanName           string `json:"human_name,omitempty"`
 	AgentType           string `json:"agent_type,omitempty"`
 	Lifetime            string `json:"lifetime"`
+	ReuseAlias          bool   `json:"reuse_alias,omitempty"`
+
+	Metadata map[string]any `json:"metadata,omitempty"`
 }
 
 type apiKeyBootstrapResponse struct {
@@ -91,8 +112,17 @@ type apiKeyBootstrapIdentityMaterial struct {
 	StableID   string
 }
 
+// resolveInitAPIKey prefers a key read for --api-key-stdin, then
+// AWEB_API_KEY, then a key `aw login` stored for the explicitly requested
+// server.
 func resolveInitAPIKey() string {
-	return strings.TrimSpace(os.Getenv(initAPIKeyEnvVar))
+	if initStdinAPIKey != "" {
+		return initStdinAPIKey
+	}
+	if v := strings.TrimSpace(os.Getenv(initAPIKeyEnvVar)); v != "" {
+		return v
+	}
+	return storedLoginAPIKey(resolveInitAwebURLOverride())
 }
 
 func resolveAPIKeyInitAwebURL() (string, error) {
@@ -116,6 +146,16 @@ func runAPIKeyBootstrapInit(req apiKeyInitRequest) (connectOutput, error) {
 	if err := ensureConnectTargetClean(req.WorkingDir); err != nil {
 		return connectOutput{}, err
 	}
+	expectFingerprint, err := normalizeCertFingerprint(req.ExpectFingerprint)
+	if err != nil {
+		return connectOutput{}, usageError("--expect-fingerprint: %v", err)
+	}
+	if u, err := url.Parse(strings.TrimSpace(req.AwebURL)); err == nil && u.Scheme == "http" {
+		if expectFingerprint != "" {
+			return connectOutput{}, usageError("--expect-fingerprint requires an https aweb URL; %s is plain http", req.AwebURL)
+		}
+		fmt.Fprintf(os.Stderr, "Warning: %s is plain http; the API key and the credentials it mints travel unauthenticated and unencrypted.\n", req.AwebURL)
+	}
 
 	// Cloud contract: persistent uses name (not alias), ephemeral uses alias (not name).
 	name := strings.TrimSpace(req.Name)
@@ -126,6 +166,17 @@ func runAPIKeyBootstrapInit(req apiKeyInitRequest) (connectOutput, error) {
 		}
 		alias = "" // cloud rejects alias for persistent
 	}
+	if req.ForceNewAlias {
+		alias = ""
+	}
+	if req.ReuseAlias {
+		if req.Persistent {
+			return connectOutput{}, usageError("--reuse-alias applies to ephemeral identities; persistent identities are addressed by --name")
+		}
+		if alias == "" {
+			return connectOutput{}, usageError("--reuse-alias requires --alias or AWEB_ALIAS")
+		}
+	}
 
 	var registry *awid.RegistryClient
 	if req.Persistent {
@@ -162,7 +213,13 @@ func runAPIKeyBootstrapInit(req apiKeyInitRequest) (connectOutput, error) {
 		}
 	}
 
-	resp, err := postAPIKeyWorkspaceInit(context.Background(), strings.TrimSpace(req.AwebURL), strings.TrimSpace(req.APIKey), apiKeyBootstrapRequest{
+	// A persistent identity is addressed by its name and a reused alias is
+	// the point of the request, so neither falls back to a fresh alias.
+	aliasRetries := req.AliasRetries
+	if req.Persistent || req.ReuseAlias {
+		aliasRetries = 0
+	}
+	resp, attempts, err := postAPIKeyWorkspaceInitWithAliasRetries(context.Background(), strings.TrimSpace(req.AwebURL), strings.TrimSpace(req.APIKey), expectFingerprint, aliasRetries, apiKeyBootstrapRequest{
 		DID:                 didKey,
 		PublicKey:           base64.StdEncoding.EncodeToString(pub),
 		Name:                name,
@@ -173,10 +230,15 @@ func runAPIKeyBootstrapInit(req apiKeyInitRequest) (connectOutput, error) {
 		HumanName:           strings.TrimSpace(req.HumanName),
 		AgentType:           strings.TrimSpace(req.AgentType),
 		Lifetime:            initLifetimeValue(req.Persistent),
+		ReuseAlias:          req.ReuseAlias,
+		Metadata:            req.Metadata,
 	})
 	if err != nil {
 		return connectOutput{}, err
 	}
+	if req.ReuseAlias && strings.TrimSpace(resp.Alias) != alias {
+		return connectOutput{}, fmt.Errorf("server allocated alias %q instead of reusing %q; rerun without --reuse-alias to accept a new alias", strings.TrimSpace(resp.Alias), alias)
+	}
 
 	encodedCert := strings.TrimSpace(resp.TeamCert)
 	if encodedCert == "" {
@@ -197,8 +259,17 @@ func runAPIKeyBootstrapInit(req apiKeyInitRequest) (connectOutput, error) {
 		return connectOutput{}, fmt.Errorf("workspace init response is missing api_key")
 	}
 
+	// The server never shows the minted key again, and it only reaches disk
+	// with workspace.yaml at the very end. Announce it first so a failed
+	// write (read-only checkout, full disk) cannot lose it.
+	keyNotice := req.KeyNotice
+	if keyNotice == nil {
+		keyNotice = os.Stderr
+	}
+	fmt.Fprintf(keyNotice, "Workspace API key (shown once; saving to .aw/workspace.yaml): %s\n", strings.TrimSpace(resp.APIKey))
+
 	if err := persistAPIKeyBootstrapState(req.WorkingDir, req.RegistryURL, signingKey, didKey, stableID, cert, persistent); err != nil {
-		return connectOutput{}, err
+		return connectOutput{}, unsavedWorkspaceAPIKeyError(err)
 	}
 	if persistent {
 		if err := removeAPIKeyPartialInit(req.WorkingDir); err != nil {
@@ -206,12 +277,24 @@ func runAPIKeyBootstrapInit(req apiKeyInitRequest) (connectOutput, error) {
 		}
 	}
 
-	return initCertificateConnectWithOptions(req.WorkingDir, serverURL, certificateConnectOptions{
+	out, err := initCertificateConnectWithOptions(req.WorkingDir, serverURL, certificateConnectOptions{
 		Role:      strings.TrimSpace(req.Role),
 		HumanName: strings.TrimSpace(req.HumanName),
 		AgentType: strings.TrimSpace(req.AgentType),
 		APIKey:    strings.TrimSpace(resp.APIKey),
+		Metadata:  req.Metadata,
 	})
+	if err != nil {
+		return connectOutput{}, unsavedWorkspaceAPIKeyError(err)
+	}
+	out.AliasAttempts = attempts
+	return out, nil
+}
+
+// unsavedWorkspaceAPIKeyError tells the user how to keep the API key init
+// already printed when finishing the workspace locally failed.
+func unsavedWorkspaceAPIKeyError(err error) error {
+	return fmt.Errorf("%w\nthe workspace API key printed above was minted but not saved; once the problem is fixed, add it to .aw/workspace.yaml as `api_key: <key>`", err)
 }
 
 func initLifetimeValue(persistent bool) string {
@@ -597,7 +680,51 @@ func persistAPIKeyBootstrapState(
 	})
 }
 
-func postAPIKeyWorkspaceInit(ctx context.Context, awebURL, apiKey string, payload apiKeyBootstrapRequest) (*apiKeyBootstrapResponse, error) {
+// Alias collisions reported by workspace init are retried with a
+// server-allocated alias, waiting aliasRetryBackoff and doubling between
+// attempts. Agents started together can race for the same allocation.
+var aliasRetryBackoff = 500 * time.Millisecond
+
+// workspaceInitConflictError is a 409 from workspace init: the requested
+// or allocated alias is already held by another agent in the team.
+type workspaceInitConflictError struct {
+	Detail string
+}
+
+func (e *workspaceInitConflictError) Error() string {
+	return fmt.Sprintf("POST /api/v1/workspaces/init returned %d: %s", http.StatusConflict, e.Detail)
+}
+
+// postAPIKeyWorkspaceInitWithAliasRetries calls postAPIKeyWorkspaceInit and,
+// while the server reports an alias collision, tries up to retries more
+// times with the alias left to the server. It returns the number of
+// attempts made.
+func postAPIKeyWorkspaceInitWithAliasRetries(ctx context.Context, awebURL, apiKey, expectFingerprint string, retries int, payload apiKeyBootstrapRequest) (*apiKeyBootstrapResponse, int, error) {
+	backoff := aliasRetryBackoff
+	for attempt := 1; ; attempt++ {
+		resp, err := postAPIKeyWorkspaceInit(ctx, awebURL, apiKey, expectFingerprint, payload)
+		var conflict *workspaceInitConflictError
+		if !errors.As(err, &conflict) || attempt > retries {
+			return resp, attempt, err
+		}
+		fmt.Fprintf(os.Stderr, "Alias collision on attempt %d of %d; retrying in %s with a server-allocated alias.\n", attempt, retries+1, backoff)
+		payload.Alias = ""
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, attempt, err
+		case <-timer.C:
+		}
+		backoff *= 2
+	}
+}
+
+// postAPIKeyWorkspaceInit mints the workspace credentials. When
+// expectFingerprint is set, the pinned leaf certificate replaces CA
+// verification, so self-signed servers can be pinned too; a mismatch fails
+// the handshake before the API key is sent or any response is read.
+func postAPIKeyWorkspaceInit(ctx context.Context, awebURL, apiKey, expectFingerprint string, payload apiKeyBootstrapRequest) (*apiKeyBootstrapResponse, error) {
 	var cancel context.CancelFunc
 	ctx, cancel = context.WithTimeout(ctx, 30*time.Second)
 	defer cancel()
@@ -613,7 +740,11 @@ func postAPIKeyWorkspaceInit(ctx context.Context, awebURL, apiKey string, payloa
 	req.Header.Set("Authorization", "Bearer "+strings.TrimSpace(apiKey))
 	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := (&http.Client{}).Do(req)
+	client := &http.Client{}
+	if expectFingerprint != "" {
+		client.Transport = pinnedCertTransport(expectFingerprint)
+	}
+	resp, err := client.Do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -630,6 +761,8 @@ func postAPIKeyWorkspaceInit(ctx context.Context, awebURL, apiKey string, payloa
 			return nil, fmt.Errorf("workspace init rejected the API key (401)")
 		case http.StatusNotFound:
 			return nil, fmt.Errorf("workspace init target was not found or the team was deleted (404)")
+		case http.StatusConflict:
+			return nil, &workspaceInitConflictError{Detail: detail}
 		default:
 			return nil, fmt.Errorf("POST /api/v1/workspaces/init returned %d: %s", resp.StatusCode, detail)
 		}
@@ -642,6 +775,50 @@ func postAPIKeyWorkspaceInit(ctx context.Context, awebURL, apiKey string, payloa
 	return &result, nil
 }
 
+// errCertFingerprintMismatch is returned when the server's TLS certificate
+// does not match --expect-fingerprint.
+var errCertFingerprintMismatch = errors.New("server TLS certificate does not match --expect-fingerprint; refusing to trust it")
+
+// normalizeCertFingerprint accepts a SHA-256 fingerprint as hex, with or
+// without colons and an optional "sha256:" prefix, and returns lowercase hex.
+func normalizeCertFingerprint(raw string) (string, error) {
+	value := strings.ToLower(strings.TrimSpace(raw))
+	if value == "" {
+		return "", nil
+	}
+	value = strings.TrimPrefix(value, "sha256:")
+	value = strings.ReplaceAll(value, ":", "")
+	decoded, err := hex.DecodeString(value)
+	if err != nil || len(decoded) != sha256.Size {
+		return "", fmt.Errorf("expected a SHA-256 certificate fingerprint (64 hex digits), got %q", raw)
+	}
+	return value, nil
+}
+
+func certFingerprint(der []byte) string {
+	sum := sha256.Sum256(der)
+	return hex.EncodeToString(sum[:])
+}
+
+func pinnedCertTransport(expectFingerprint string) *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		// The pin is the trust anchor; VerifyConnection does the checking.
+		InsecureSkipVerify: true,
+		VerifyConnection: func(cs tls.ConnectionState) error {
+			if len(cs.PeerCertificates) == 0 {
+				return errCertFingerprintMismatch
+			}
+			if got := certFingerprint(cs.PeerCertificates[0].Raw); got != expectFingerprint {
+				return fmt.Errorf("%w (got sha256:%s)", errCertFingerprintMismatch, got)
+			}
+			return nil
+		},
+	}
+	return transport
+}
+
 func runRequestedInitPostSetup(workingDir string) error {
 	repoRoot := resolveRepoRoot(workingDir)
 	if initInjectDocs {
@@ -5,6 +5,7 @@ import (
 	"crypto/ed25519"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -13,6 +14,7 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/awebai/aw/awconfig"
 	"github.com/awebai/aw/awid"
@@ -929,6 +931,38 @@ func TestRunAPIKeyBootstrapInitRejectsOverlongWorkspaceAPIKey(t *testing.T) {
 	}
 }
 
+func TestRunAPIKeyBootstrapInitPrintsAPIKeyBeforeFailedWrite(t *testing.T) {
+	t.Parallel()
+
+	var initBody map[string]any
+	server := newAliasEchoBootstrapServer(t, "alice", &initBody)
+
+	// A directory where teams.yaml belongs makes the local write fail after
+	// the server has minted the credentials, as a read-only disk would.
+	tmp := t.TempDir()
+	if err := os.MkdirAll(awconfig.TeamStatePath(tmp), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	var notice strings.Builder
+	_, err := runAPIKeyBootstrapInit(apiKeyInitRequest{
+		WorkingDir: tmp,
+		AwebURL:    server.URL,
+		APIKey:     "aw_sk_test",
+		Alias:      "alice",
+		KeyNotice:  &notice,
+	})
+	if err == nil {
+		t.Fatal("expected the blocked teams.yaml write to fail init")
+	}
+	if !strings.Contains(notice.String(), "Workspace API key") || !strings.Contains(notice.String(), "workspace-sk-ephemeral") {
+		t.Fatalf("minted key was not announced before the failure: %q\nerr=%v", notice.String(), err)
+	}
+	if !strings.Contains(err.Error(), "minted but not saved") || !strings.Contains(err.Error(), "api_key:") {
+		t.Fatalf("error should explain how to keep the key: %v", err)
+	}
+}
+
 func containsStringUnderTree(t *testing.T, root, needle string) bool {
 	t.Helper()
 
@@ -973,3 +1007,316 @@ func externalLikeTestURL(t *testing.T, raw string) string {
 	}
 	return u.String()
 }
+
+func newAliasEchoBootstrapServer(t *testing.T, responseAlias string, initBody *map[string]any) *httptest.Server {
+	t.Helper()
+	return newAliasEchoBootstrapServerWithInitHook(t, responseAlias, initBody, nil)
+}
+
+// newAliasEchoBootstrapServerWithInitHook is newAliasEchoBootstrapServer
+// with initHook run on each decoded workspace init body; when it returns
+// true it has written the response itself.
+func newAliasEchoBootstrapServerWithInitHook(t *testing.T, responseAlias string, initBody *map[string]any, initHook func(w http.ResponseWriter, body map[string]any) bool) *httptest.Server {
+	t.Helper()
+
+	teamPub, teamKey, err := awid.GenerateKeypair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	teamDIDKey := awid.ComputeDIDKey(teamPub)
+
+	var server *httptest.Server
+	server = newLocalHTTPServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/workspaces/init":
+			*initBody = nil
+			if err := json.NewDecoder(r.Body).Decode(initBody); err != nil {
+				t.Fatal(err)
+			}
+			if initHook != nil && initHook(w, *initBody) {
+				return
+			}
+			didKey, _ := (*initBody)["did"].(string)
+			cert, err := awid.SignTeamCertificate(teamKey, awid.TeamCertificateFields{
+				Team:         "backend:acme.com",
+				MemberDIDKey: didKey,
+				Alias:        responseAlias,
+				Lifetime:     awid.LifetimeEphemeral,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			encoded, err := awid.EncodeTeamCertificateHeader(cert)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"server_url":   server.URL + "/api",
+				"team_cert":    encoded,
+				"alias":        responseAlias,
+				"team_id":      "backend:acme.com",
+				"workspace_id": "ws-1",
+				"did":          didKey,
+				"stable_id":    "",
+				"lifetime":     awid.LifetimeEphemeral,
+				"custody":      awid.CustodySelf,
+				"api_key":      "workspace-sk-ephemeral",
+			})
+		case "/api/v1/connect":
+			requireCertificateAuthForTest(t, r)
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"team_id":      "backend:acme.com",
+				"alias":        responseAlias,
+				"agent_id":     "agent-1",
+				"workspace_id": "ws-1",
+				"repo_id":      "repo-1",
+				"team_did_key": teamDIDKey,
+			})
+		case "/v1/agents/heartbeat":
+			w.WriteHeader(http.StatusOK)
+		default:
+			t.Fatalf("unexpected %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	return server
+}
+
+func TestRunAPIKeyBootstrapInitReuseAliasKeepsExistingAlias(t *testing.T) {
+	t.Parallel()
+
+	var initBody map[string]any
+	server := newAliasEchoBootstrapServer(t, "alice", &initBody)
+
+	result, err := runAPIKeyBootstrapInit(apiKeyInitRequest{
+		WorkingDir: t.TempDir(),
+		AwebURL:    externalLikeTestURL(t, server.URL),
+		APIKey:     "aw_sk_test",
+		Alias:      "alice",
+		ReuseAlias: true,
+	})
+	if err != nil {
+		t.Fatalf("runAPIKeyBootstrapInit: %v", err)
+	}
+	if initBody["alias"] != "alice" {
+		t.Fatalf("init alias=%v", initBody["alias"])
+	}
+	if initBody["reuse_alias"] != true {
+		t.Fatalf("init reuse_alias=%v", initBody["reuse_alias"])
+	}
+	if result.Alias != "alice" {
+		t.Fatalf("alias=%q", result.Alias)
+	}
+}
+
+func TestRunAPIKeyBootstrapInitReuseAliasRejectsReallocatedAlias(t *testing.T) {
+	t.Parallel()
+
+	var initBody map[string]any
+	server := newAliasEchoBootstrapServer(t, "alice-2", &initBody)
+
+	tmp := t.TempDir()
+	_, err := runAPIKeyBootstrapInit(apiKeyInitRequest{
+		WorkingDir: tmp,
+		AwebURL:    externalLikeTestURL(t, server.URL),
+		APIKey:     "aw_sk_test",
+		Alias:      "alice",
+		ReuseAlias: true,
+	})
+	if err == nil || !strings.Contains(err.Error(), `server allocated alias "alice-2" instead of reusing "alice"`) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, statErr := os.Stat(filepath.Join(tmp, ".aw", "signing.key")); !os.IsNotExist(statErr) {
+		t.Fatalf("expected no signing key to be persisted, stat err=%v", statErr)
+	}
+}
+
+func TestRunAPIKeyBootstrapInitReuseAliasRequiresAlias(t *testing.T) {
+	t.Parallel()
+
+	_, err := runAPIKeyBootstrapInit(apiKeyInitRequest{
+		WorkingDir: t.TempDir(),
+		AwebURL:    "https://app.aweb.ai",
+		APIKey:     "aw_sk_test",
+		ReuseAlias: true,
+	})
+	if err == nil || !strings.Contains(err.Error(), "--reuse-alias requires --alias or AWEB_ALIAS") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestRunAPIKeyBootstrapInitForceRequestsFreshAlias(t *testing.T) {
+	t.Parallel()
+
+	var initBody map[string]any
+	server := newAliasEchoBootstrapServer(t, "bob", &initBody)
+
+	result, err := runAPIKeyBootstrapInit(apiKeyInitRequest{
+		WorkingDir:    t.TempDir(),
+		AwebURL:       externalLikeTestURL(t, server.URL),
+		APIKey:        "aw_sk_test",
+		Alias:         "alice",
+		ForceNewAlias: true,
+	})
+	if err != nil {
+		t.Fatalf("runAPIKeyBootstrapInit: %v", err)
+	}
+	if _, ok := initBody["alias"]; ok {
+		t.Fatalf("expected alias to be omitted, got %v", initBody["alias"])
+	}
+	if result.Alias != "bob" {
+		t.Fatalf("alias=%q", result.Alias)
+	}
+}
+
+func TestRunAPIKeyBootstrapInitRetriesAliasCollisions(t *testing.T) {
+	old := aliasRetryBackoff
+	aliasRetryBackoff = time.Millisecond
+	t.Cleanup(func() { aliasRetryBackoff = old })
+
+	var initBody map[string]any
+	var requested []any
+	server := newAliasEchoBootstrapServerWithInitHook(t, "carol", &initBody, func(w http.ResponseWriter, body map[string]any) bool {
+		requested = append(requested, body["alias"])
+		if len(requested) > 2 {
+			return false
+		}
+		http.Error(w, `{"detail":"alias already in use"}`, http.StatusConflict)
+		return true
+	})
+
+	result, err := runAPIKeyBootstrapInit(apiKeyInitRequest{
+		WorkingDir:   t.TempDir(),
+		AwebURL:      server.URL,
+		APIKey:       "aw_sk_test",
+		Alias:        "alice",
+		AliasRetries: 3,
+	})
+	if err != nil {
+		t.Fatalf("runAPIKeyBootstrapInit: %v", err)
+	}
+	if result.Alias != "carol" || result.AliasAttempts != 3 {
+		t.Fatalf("alias=%q attempts=%d", result.Alias, result.AliasAttempts)
+	}
+	if len(requested) != 3 || requested[0] != "alice" || requested[1] != nil || requested[2] != nil {
+		t.Fatalf("requested aliases=%v, want alice then server allocation", requested)
+	}
+}
+
+func TestPostAPIKeyWorkspaceInitStopsAfterAliasRetries(t *testing.T) {
+	old := aliasRetryBackoff
+	aliasRetryBackoff = time.Millisecond
+	t.Cleanup(func() { aliasRetryBackoff = old })
+
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		http.Error(w, `{"detail":"alias already in use"}`, http.StatusConflict)
+	}))
+	t.Cleanup(server.Close)
+
+	_, attempts, err := postAPIKeyWorkspaceInitWithAliasRetries(context.Background(), server.URL, "aw_sk_test", "", 1, apiKeyBootstrapRequest{Alias: "alice"})
+	var conflict *workspaceInitConflictError
+	if !errors.As(err, &conflict) || !strings.Contains(err.Error(), "alias already in use") {
+		t.Fatalf("err=%v", err)
+	}
+	if attempts != 2 || calls != 2 {
+		t.Fatalf("attempts=%d calls=%d, want 2", attempts, calls)
+	}
+
+	calls = 0
+	if _, attempts, _ := postAPIKeyWorkspaceInitWithAliasRetries(context.Background(), server.URL, "aw_sk_test", "", 0, apiKeyBootstrapRequest{}); attempts != 1 || calls != 1 {
+		t.Fatalf("without retries: attempts=%d calls=%d", attempts, calls)
+	}
+}
+
+func TestNormalizeCertFingerprint(t *testing.T) {
+	t.Parallel()
+
+	const want = "3a7bd3e2360a3d29eea436fcfb7e44c735d117c42d1c1835420b6b9942dd4f1b"
+	for _, raw := range []string{
+		want,
+		strings.ToUpper(want),
+		"sha256:" + want,
+		"3A:7B:D3:E2:36:0A:3D:29:EE:A4:36:FC:FB:7E:44:C7:35:D1:17:C4:2D:1C:18:35:42:0B:6B:99:42:DD:4F:1B",
+	} {
+		got, err := normalizeCertFingerprint(raw)
+		if err != nil || got != want {
+			t.Fatalf("normalizeCertFingerprint(%q)=%q, %v", raw, got, err)
+		}
+	}
+	if got, err := normalizeCertFingerprint(""); err != nil || got != "" {
+		t.Fatalf("empty=%q, %v", got, err)
+	}
+	for _, raw := range []string{"abc", "zz" + want[2:], want + "00"} {
+		if _, err := normalizeCertFingerprint(raw); err == nil {
+			t.Fatalf("expected error for %q", raw)
+		}
+	}
+}
+
+func TestPostAPIKeyWorkspaceInitChecksPinnedFingerprint(t *testing.T) {
+	t.Parallel()
+
+	var gotKey string
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotKey = r.Header.Get("Authorization")
+		_ = json.NewEncoder(w).Encode(map[string]any{"alias": "alice", "api_key": "workspace-sk"})
+	}))
+	t.Cleanup(server.Close)
+	pinned := certFingerprint(server.Certificate().Raw)
+
+	resp, err := postAPIKeyWorkspaceInit(context.Background(), server.URL, "aw_sk_test", pinned, apiKeyBootstrapRequest{})
+	if err != nil {
+		t.Fatalf("pinned fingerprint: %v", err)
+	}
+	if resp.APIKey != "workspace-sk" {
+		t.Fatalf("resp=%+v", resp)
+	}
+
+	gotKey = ""
+	wrong := strings.Repeat("0", 64)
+	if _, err := postAPIKeyWorkspaceInit(context.Background(), server.URL, "aw_sk_test", wrong, apiKeyBootstrapRequest{}); !errors.Is(err, errCertFingerprintMismatch) {
+		t.Fatalf("err=%v, want errCertFingerprintMismatch", err)
+	}
+	if gotKey != "" {
+		t.Fatal("API key was sent to a server that failed the pin")
+	}
+}
+
+func TestRunAPIKeyBootstrapInitFingerprintMismatchWritesNothing(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+	}))
+	t.Cleanup(server.Close)
+
+	tmp := t.TempDir()
+	_, err := runAPIKeyBootstrapInit(apiKeyInitRequest{
+		WorkingDir:        tmp,
+		AwebURL:           server.URL,
+		APIKey:            "aw_sk_test",
+		Alias:             "alice",
+		ExpectFingerprint: strings.Repeat("ab", 32),
+	})
+	if !errors.Is(err, errCertFingerprintMismatch) {
+		t.Fatalf("err=%v, want errCertFingerprintMismatch", err)
+	}
+	if _, statErr := os.Stat(filepath.Join(tmp, ".aw")); !os.IsNotExist(statErr) {
+		t.Fatalf(".aw should not be written on mismatch: %v", statErr)
+	}
+}
+
+func TestRunAPIKeyBootstrapInitRejectsFingerprintOverHTTP(t *testing.T) {
+	t.Parallel()
+
+	_, err := runAPIKeyBootstrapInit(apiKeyInitRequest{
+		WorkingDir:        t.TempDir(),
+		AwebURL:           "http://127.0.0.1:1",
+		APIKey:            "aw_sk_test",
+		ExpectFingerprint: strings.Repeat("ab", 32),
+	})
+	if err == nil || !strings.Contains(err.Error(), "requires an https aweb URL") {
+		t.Fatalf("err=%v", err)
+	}
+}
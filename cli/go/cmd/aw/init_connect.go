@@ -28,6 +28,9 @@ type connectOutput struct {
 	Alias       string `json:"alias"`
 	AwebURL     string `json:"aweb_url"`
 	WorkspaceID string `json:"workspace_id,omitempty"`
+	// AliasAttempts counts API key bootstrap attempts when alias
+	// collisions were retried; 0 or 1 means the first attempt succeeded.
+	AliasAttempts int `json:"alias_attempts,omitempty"`
 }
 
 // connectResponse is the server response from POST /v1/connect.
@@ -48,6 +51,8 @@ type connectRequest struct {
 	Role          string `json:"role,omitempty"`
 	HumanName     string `json:"human_name,omitempty"`
 	AgentType     string `json:"agent_type,omitempty"`
+
+	Metadata map[string]any `json:"metadata,omitempty"`
 }
 
 type certificateConnectOptions struct {
@@ -55,6 +60,7 @@ type certificateConnectOptions struct {
 	HumanName string
 	AgentType string
 	APIKey    string
+	Metadata  map[string]any
 }
 
 // initCertificateConnect implements the certificate-based init flow.
@@ -91,6 +97,7 @@ func initCertificateConnectWithOptions(workingDir, awebURL string, opts certific
 		Role:          strings.TrimSpace(opts.Role),
 		HumanName:     resolveHumanNameValue(strings.TrimSpace(opts.HumanName)),
 		AgentType:     resolveAgentTypeValue(strings.TrimSpace(opts.AgentType)),
+		Metadata:      opts.Metadata,
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
@@ -293,5 +300,8 @@ func formatConnect(v any) string {
 	sb.WriteString(fmt.Sprintf("Team:        %s\n", out.TeamID))
 	sb.WriteString(fmt.Sprintf("Alias:       %s\n", out.Alias))
 	sb.WriteString(fmt.Sprintf("Aweb URL:    %s\n", out.AwebURL))
+	if out.AliasAttempts > 1 {
+		sb.WriteString(fmt.Sprintf("Attempts:    %d (alias collisions retried)\n", out.AliasAttempts))
+	}
 	return sb.String()
 }
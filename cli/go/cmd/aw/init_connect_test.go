@@ -100,7 +100,7 @@ func TestInitWithCertificateConnectsToServer(t *testing.T) {
 	// Initialize a git repo so canonical_origin is available
 	initGitRepoWithOrigin(t, tmp, "https://github.com/acme/backend.git")
 
-	run := exec.CommandContext(ctx, bin, "init", "--url", server.URL, "--role", "developer", "--json")
+	run := exec.CommandContext(ctx, bin, "init", "--url", server.URL, "--role", "developer", "--meta", "model=m-1", "--json")
 	run.Env = idCreateCommandEnv(tmp)
 	run.Dir = tmp
 	out, err := run.CombinedOutput()
@@ -180,6 +180,9 @@ func TestInitWithCertificateConnectsToServer(t *testing.T) {
 	if !strings.HasSuffix(wsPath, filepath.Base(tmp)) {
 		t.Fatalf("connect payload workspace_path=%v", gotConnectPayload["workspace_path"])
 	}
+	if meta, _ := gotConnectPayload["metadata"].(map[string]any); meta["model"] != "m-1" {
+		t.Fatalf("connect payload metadata=%v", gotConnectPayload["metadata"])
+	}
 }
 
 func TestInitWithCertificatePreservesExplicitAPIPath(t *testing.T) {
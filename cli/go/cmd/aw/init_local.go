@@ -20,6 +20,7 @@ type implicitLocalInitRequest struct {
 	Role        string
 	HumanName   string
 	AgentType   string
+	Metadata    map[string]any
 }
 
 func runImplicitLocalInit(req implicitLocalInitRequest) (connectOutput, error) {
@@ -92,5 +93,6 @@ func runImplicitLocalInit(req implicitLocalInitRequest) (connectOutput, error) {
 		Role:      strings.TrimSpace(req.Role),
 		HumanName: strings.TrimSpace(req.HumanName),
 		AgentType: strings.TrimSpace(req.AgentType),
+		Metadata:  req.Metadata,
 	})
 }
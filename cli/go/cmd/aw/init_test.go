@@ -477,6 +477,99 @@ func TestInitUsesResolvedAliasForImplicitLocalFlow(t *testing.T) {
 	}
 }
 
+func TestInitPassesMetaToImplicitLocalFlow(t *testing.T) {
+	oldLocalFlow := initRunImplicitLocalFlow
+	oldIsTTY := initIsTTY
+	oldAwebURL := initAwebURL
+	oldRegistry := initAWIDRegistry
+	oldAlias := initAlias
+	oldMeta := initMeta
+	t.Cleanup(func() {
+		initRunImplicitLocalFlow = oldLocalFlow
+		initIsTTY = oldIsTTY
+		initAwebURL = oldAwebURL
+		initAWIDRegistry = oldRegistry
+		initAlias = oldAlias
+		initMeta = oldMeta
+	})
+
+	tmp := t.TempDir()
+	origWd, _ := os.Getwd()
+	if err := os.Chdir(tmp); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(origWd)
+
+	initIsTTY = func() bool { return false }
+	initAwebURL = "http://localhost:8100"
+	initAWIDRegistry = "http://127.0.0.1:8010"
+	initAlias = "alice"
+	initMeta = []string{"model=m-1", "version=2.3", "model=m-2"}
+
+	var got implicitLocalInitRequest
+	initRunImplicitLocalFlow = func(req implicitLocalInitRequest) (connectOutput, error) {
+		got = req
+		return connectOutput{Status: "connected", TeamID: "default:local", Alias: req.Alias}, nil
+	}
+
+	cmd := &cobraCommandClone{Command: *initCmd}
+	cmd.Command.SetContext(context.Background())
+	cmd.Command.SetIn(strings.NewReader(""))
+	cmd.Command.SetOut(io.Discard)
+	cmd.Command.SetErr(io.Discard)
+
+	if err := runInit(&cmd.Command, nil); err != nil {
+		t.Fatalf("runInit: %v", err)
+	}
+	if got.Metadata["model"] != "m-2" || got.Metadata["version"] != "2.3" || len(got.Metadata) != 2 {
+		t.Fatalf("metadata=%v", got.Metadata)
+	}
+}
+
+func TestParseMetaFlagsRejectsMissingKey(t *testing.T) {
+	t.Parallel()
+
+	for _, raw := range []string{"model", "=m-1", " =x"} {
+		if _, err := parseMetaFlags([]string{raw}); err == nil {
+			t.Fatalf("parseMetaFlags(%q) succeeded, want error", raw)
+		}
+	}
+	got, err := parseMetaFlags(nil)
+	if err != nil || got != nil {
+		t.Fatalf("parseMetaFlags(nil)=%v, %v", got, err)
+	}
+	got, err = parseMetaFlags([]string{"note=a=b"})
+	if err != nil || got["note"] != "a=b" {
+		t.Fatalf("parseMetaFlags(note=a=b)=%v, %v", got, err)
+	}
+}
+
+func TestParseMetaFlagsReadsJSONFile(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "caps.json")
+	if err := os.WriteFile(path, []byte(`{"tools":["edit","shell"]}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	got, err := parseMetaFlags([]string{"capabilities=@" + path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	caps, _ := got["capabilities"].(map[string]any)
+	tools, _ := caps["tools"].([]any)
+	if len(tools) != 2 || tools[0] != "edit" {
+		t.Fatalf("capabilities=%v", got["capabilities"])
+	}
+
+	bad := filepath.Join(t.TempDir(), "bad.json")
+	if err := os.WriteFile(bad, []byte("not json"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := parseMetaFlags([]string{"capabilities=@" + bad}); err == nil {
+		t.Fatal("expected invalid JSON error")
+	}
+}
+
 func TestInitUsesGuidedOnboardingWhenRegistryIsNotLocalhost(t *testing.T) {
 	oldLocalFlow := initRunImplicitLocalFlow
 	oldWizard := guidedOnboardingWizard
@@ -2,7 +2,10 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"os"
+	"strings"
 	"time"
 
 	aweb "github.com/awebai/aw"
@@ -13,13 +16,21 @@ import (
 var lockCmd = &cobra.Command{
 	Use:   "lock",
 	Short: "Distributed locks",
+	Long: `Distributed locks.
+
+When reservation_prefix is set in .aw/workspace.yaml it is prepended to every
+--resource-key and --prefix, so a sub-team gets its own lock namespace. Start
+a key with / to use it as-is, for example --resource-key /shared/deploy or
+` + "`aw lock list --prefix /`" + ` for every lock in the team. Output always shows the
+full key.`,
 }
 
 // lock acquire
 
 var (
-	lockAcquireResourceKey string
-	lockAcquireTTLSeconds  int
+	lockAcquireResourceKey  string
+	lockAcquireTTLSeconds   int
+	lockAcquireStealExpired bool
 )
 
 var lockAcquireCmd = &cobra.Command{
@@ -29,6 +40,9 @@ var lockAcquireCmd = &cobra.Command{
 		if lockAcquireResourceKey == "" {
 			return usageError("missing required flag: --resource-key")
 		}
+		if err := aweb.ValidateResourceKey(lockAcquireResourceKey); err != nil {
+			return usageError("--resource-key: %v", err)
+		}
 
 		c, err := resolveClient()
 		if err != nil {
@@ -39,8 +53,9 @@ var lockAcquireCmd = &cobra.Command{
 		defer cancel()
 
 		resp, err := c.ReservationAcquire(ctx, &aweb.ReservationAcquireRequest{
-			ResourceKey: lockAcquireResourceKey,
-			TTLSeconds:  lockAcquireTTLSeconds,
+			ResourceKey:  lockAcquireResourceKey,
+			TTLSeconds:   lockAcquireTTLSeconds,
+			StealExpired: lockAcquireStealExpired,
 		})
 		if err != nil {
 			if unsupportedErr := normalizeReservationMutationError("acquire", err); unsupportedErr != nil {
@@ -67,6 +82,9 @@ var lockRenewCmd = &cobra.Command{
 		if lockRenewResourceKey == "" {
 			return usageError("missing required flag: --resource-key")
 		}
+		if err := aweb.ValidateResourceKey(lockRenewResourceKey); err != nil {
+			return usageError("--resource-key: %v", err)
+		}
 
 		c, err := resolveClient()
 		if err != nil {
@@ -91,6 +109,50 @@ var lockRenewCmd = &cobra.Command{
 	},
 }
 
+// lock transfer
+
+var (
+	lockTransferResourceKey string
+	lockTransferToAlias     string
+)
+
+var lockTransferCmd = &cobra.Command{
+	Use:   "transfer",
+	Short: "Hand a lock you hold to another agent",
+	Long: `Reassign a lock you hold to another agent in the team without releasing
+it, so nobody else can take it in between. The lease's expiry is kept.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if lockTransferResourceKey == "" {
+			return usageError("missing required flag: --resource-key")
+		}
+		if err := aweb.ValidateResourceKey(lockTransferResourceKey); err != nil {
+			return usageError("--resource-key: %v", err)
+		}
+		toAlias := strings.TrimSpace(lockTransferToAlias)
+		if toAlias == "" {
+			return usageError("missing required flag: --to-alias")
+		}
+
+		c, err := resolveClient()
+		if err != nil {
+			return err
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		resp, err := c.ReservationTransfer(ctx, lockTransferResourceKey, toAlias)
+		if err != nil {
+			if unsupportedErr := normalizeReservationMutationError("transfer", err); unsupportedErr != nil {
+				return unsupportedErr
+			}
+			return err
+		}
+		printOutput(resp, formatLockTransfer)
+		return nil
+	},
+}
+
 // lock release
 
 var lockReleaseResourceKey string
@@ -102,6 +164,9 @@ var lockReleaseCmd = &cobra.Command{
 		if lockReleaseResourceKey == "" {
 			return usageError("missing required flag: --resource-key")
 		}
+		if err := aweb.ValidateResourceKey(lockReleaseResourceKey); err != nil {
+			return usageError("--resource-key: %v", err)
+		}
 
 		c, err := resolveClient()
 		if err != nil {
@@ -157,59 +222,177 @@ var lockRevokeCmd = &cobra.Command{
 
 // lock list
 
+const defaultLockListLimit = 50
+
 var (
-	lockListPrefix string
-	lockListMine   bool
+	lockListPrefix   string
+	lockListMine     bool
+	lockListLimit    int
+	lockListCursor   string
+	lockListWatch    bool
+	lockListInterval int
+	lockListExpiring time.Duration
 )
 
 var lockListCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List active locks",
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if lockListWatch && lockListInterval <= 0 {
+			return usageError("--interval must be positive")
+		}
+		if lockListExpiring < 0 {
+			return usageError("--expiring-within must not be negative")
+		}
+		if lockListMine && (cmd.Flags().Changed("limit") || cmd.Flags().Changed("cursor")) {
+			return usageError("--limit and --cursor cannot be used with --mine, which lists every matching lock")
+		}
 		c, sel, err := resolveClientSelection()
 		if err != nil {
 			return err
 		}
+		if lockListWatch {
+			return runLockListWatch(c, sel, time.Duration(lockListInterval)*time.Second, os.Stdout)
+		}
 
 		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 		defer cancel()
 
-		resp, err := c.ReservationList(ctx, lockListPrefix)
-		if err != nil {
-			return err
+		if lockListExpiring > 0 {
+			return listExpiringLocks(ctx, c, sel.Alias)
+		}
+		if jsonStreamOutput() {
+			return streamLockList(ctx, c, sel.Alias)
 		}
+
 		if lockListMine {
-			filtered := make([]aweb.ReservationView, 0, len(resp.Reservations))
-			for _, reservation := range resp.Reservations {
-				if reservation.HolderAlias == sel.Alias {
-					filtered = append(filtered, reservation)
-				}
+			// Ownership is filtered client-side, so page through everything
+			// rather than showing a partial page of someone else's locks.
+			all, err := c.ReservationListAll(ctx, lockListPrefix)
+			if err != nil {
+				return err
 			}
-			resp.Reservations = filtered
+			filtered := filterLocksByHolder(all, sel.Alias)
+			printOutput(&aweb.ReservationListResponse{Reservations: filtered, Total: len(filtered)}, formatLockList)
+			return nil
+		}
+
+		resp, err := c.ReservationList(ctx, aweb.ReservationListParams{
+			Prefix: lockListPrefix,
+			Limit:  lockListLimit,
+			Cursor: lockListCursor,
+		})
+		if err != nil {
+			return err
 		}
 		printOutput(resp, formatLockList)
 		return nil
 	},
 }
 
+// lock watch
+
+var lockWatchResourceKey string
+
+var lockWatchCmd = &cobra.Command{
+	Use:   "watch",
+	Short: "Stream changes to one lock",
+	Long: `Print each acquire, release and expiry of one lock as it happens, until
+interrupted. With --json each change is printed as one JSON line.
+
+Servers without the watch stream are reported as such; use
+` + "`aw lock list --watch`" + ` there instead.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if lockWatchResourceKey == "" {
+			return usageError("missing required flag: --resource-key")
+		}
+		if err := aweb.ValidateResourceKey(lockWatchResourceKey); err != nil {
+			return usageError("--resource-key: %v", err)
+		}
+		c, err := resolveClient()
+		if err != nil {
+			return err
+		}
+		return runLockWatch(c, lockWatchResourceKey, os.Stdout)
+	},
+}
+
 func init() {
 	lockAcquireCmd.Flags().StringVar(&lockAcquireResourceKey, "resource-key", "", "Opaque resource key")
 	lockAcquireCmd.Flags().IntVar(&lockAcquireTTLSeconds, "ttl-seconds", 3600, "TTL seconds")
+	lockAcquireCmd.Flags().BoolVar(&lockAcquireStealExpired, "steal-expired", false, "Take over the lock if another holder's lease has expired (live locks are never taken)")
 
 	lockRenewCmd.Flags().StringVar(&lockRenewResourceKey, "resource-key", "", "Opaque resource key")
 	lockRenewCmd.Flags().IntVar(&lockRenewTTLSeconds, "ttl-seconds", 3600, "TTL seconds")
 
+	lockTransferCmd.Flags().StringVar(&lockTransferResourceKey, "resource-key", "", "Opaque resource key")
+	lockTransferCmd.Flags().StringVar(&lockTransferToAlias, "to-alias", "", "Alias of the agent to hand the lock to")
+
 	lockReleaseCmd.Flags().StringVar(&lockReleaseResourceKey, "resource-key", "", "Opaque resource key")
 
 	lockRevokeCmd.Flags().StringVar(&lockRevokePrefix, "prefix", "", "Optional prefix filter")
 
 	lockListCmd.Flags().StringVar(&lockListPrefix, "prefix", "", "Prefix filter")
-	lockListCmd.Flags().BoolVar(&lockListMine, "mine", false, "Show only locks held by the current workspace alias")
+	lockListCmd.Flags().BoolVar(&lockListMine, "mine", false, "Show only locks held by the current workspace alias (not paged; excludes --limit and --cursor)")
+	lockListCmd.Flags().IntVar(&lockListLimit, "limit", defaultLockListLimit, "Maximum locks per page")
+	lockListCmd.Flags().StringVar(&lockListCursor, "cursor", "", "Cursor from a previous page")
+	lockListCmd.Flags().BoolVar(&lockListWatch, "watch", false, "Re-poll and redraw the list, marking newly acquired (+) and released (-) locks")
+	lockListCmd.Flags().IntVar(&lockListInterval, "interval", defaultLockWatchInterval, "Seconds between polls with --watch")
+	lockListCmd.Flags().DurationVar(&lockListExpiring, "expiring-within", 0, "Show only locks expiring within this window (e.g. 60s), soonest first")
+
+	lockWatchCmd.Flags().StringVar(&lockWatchResourceKey, "resource-key", "", "Opaque resource key")
 
-	lockCmd.AddCommand(lockAcquireCmd, lockRenewCmd, lockReleaseCmd, lockRevokeCmd, lockListCmd)
+	lockCmd.AddCommand(lockAcquireCmd, lockRenewCmd, lockTransferCmd, lockReleaseCmd, lockRevokeCmd, lockListCmd, lockWatchCmd)
 	rootCmd.AddCommand(lockCmd)
 }
 
+// streamLockList prints every matching lock as one JSON line as each page
+// arrives, rather than collecting the whole list first. --limit sets the
+// page size; --cursor is ignored.
+func streamLockList(ctx context.Context, c *aweb.Client, alias string) error {
+	it := c.ReservationListIter(aweb.ReservationListParams{Prefix: lockListPrefix, Limit: lockListLimit})
+	enc := json.NewEncoder(os.Stdout)
+	for {
+		reservation, ok, err := it.Next(ctx)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return nil
+		}
+		if lockListMine && reservation.HolderAlias != alias {
+			continue
+		}
+		if err := enc.Encode(reservation); err != nil {
+			return err
+		}
+	}
+}
+
+// listExpiringLocks prints the locks that expire within --expiring-within,
+// soonest first. The window is applied client-side, so it pages through
+// every lock; --limit and --cursor are ignored. Locks without a usable
+// expiry are left out with a warning on stderr.
+func listExpiringLocks(ctx context.Context, c *aweb.Client, alias string) error {
+	all, err := c.ReservationListAll(ctx, lockListPrefix)
+	if err != nil {
+		return err
+	}
+	if lockListMine {
+		all = filterLocksByHolder(all, alias)
+	}
+	expiring, skipped := aweb.ExpiringWithin(all, lockListExpiring, time.Now())
+	for _, r := range skipped {
+		fmt.Fprintf(os.Stderr, "Warning: skipping lock %s: missing or invalid expires_at %q\n", r.ResourceKey, r.ExpiresAt)
+	}
+	if jsonStreamOutput() {
+		printJSONLines(expiring)
+		return nil
+	}
+	printOutput(&aweb.ReservationListResponse{Reservations: expiring, Total: len(expiring)}, formatLockList)
+	return nil
+}
+
 func normalizeReservationMutationError(action string, err error) error {
 	code, ok := awid.HTTPStatusCode(err)
 	if !ok || (code != 404 && code != 405) {
@@ -4,11 +4,14 @@ import (
 	"context"
 	"encoding/json"
 	"net/http"
+	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
 	"testing"
 	"time"
+
+	aweb "github.com/awebai/aw"
 )
 
 func TestAwLockMutationUnsupportedMessage(t *testing.T) {
@@ -122,3 +125,334 @@ func TestAwLockListMineFiltersByCurrentAlias(t *testing.T) {
 		t.Fatalf("expected other lock to be filtered out:\n%s", text)
 	}
 }
+
+func TestAwLockListMineRejectsPagingFlags(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	tmp := t.TempDir()
+	bin := filepath.Join(tmp, "aw")
+	buildAwBinary(t, ctx, bin)
+
+	for _, flags := range [][]string{{"--limit", "5"}, {"--cursor", "c1"}} {
+		run := exec.CommandContext(ctx, bin, append([]string{"lock", "list", "--mine"}, flags...)...)
+		run.Env = testCommandEnv(tmp)
+		run.Dir = tmp
+		out, err := run.CombinedOutput()
+		if err == nil {
+			t.Fatalf("%v: expected error, got success:\n%s", flags, out)
+		}
+		if !strings.Contains(string(out), "cannot be used with --mine") {
+			t.Fatalf("%v: unexpected output:\n%s", flags, out)
+		}
+	}
+}
+
+func TestAwLockListJSONStreamPrintsOneLockPerLine(t *testing.T) {
+	t.Parallel()
+
+	server := newLocalHTTPServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/reservations":
+			key, next := "src/a.go", any("c1")
+			if r.URL.Query().Get("cursor") == "c1" {
+				key, next = "src/b.go", nil
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"reservations": []map[string]any{{
+					"resource_key":    key,
+					"holder_agent_id": "11111111-1111-1111-1111-111111111111",
+					"holder_alias":    "alice",
+					"acquired_at":     "2026-03-10T10:00:00Z",
+					"expires_at":      "2099-03-10T10:00:00Z",
+					"metadata":        map[string]any{},
+				}},
+				"has_more":    next != nil,
+				"next_cursor": next,
+			})
+		case "/v1/agents/heartbeat":
+			w.WriteHeader(http.StatusOK)
+		default:
+			t.Errorf("unexpected %s %s", r.Method, r.URL.Path)
+		}
+	}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	tmp := t.TempDir()
+	bin := filepath.Join(tmp, "aw")
+	buildAwBinary(t, ctx, bin)
+	writeWorkspaceBindingForTest(t, tmp, workspaceBinding(server.URL, "backend:demo", "alice", "workspace-1"))
+
+	run := exec.CommandContext(ctx, bin, "lock", "list", "--output", "json-stream")
+	run.Env = testCommandEnv(tmp)
+	run.Dir = tmp
+	out, err := run.Output()
+	if err != nil {
+		t.Fatalf("run failed: %v\n%s", err, string(out))
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(out)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("want one line per lock across both pages, got:\n%s", string(out))
+	}
+	for i, want := range []string{"src/a.go", "src/b.go"} {
+		var got aweb.ReservationView
+		if err := json.Unmarshal([]byte(lines[i]), &got); err != nil {
+			t.Fatalf("line %d is not a JSON object: %v\n%s", i, err, lines[i])
+		}
+		if got.ResourceKey != want {
+			t.Fatalf("line %d resource_key=%q, want %q", i, got.ResourceKey, want)
+		}
+	}
+}
+
+func TestAwLockListExpiringWithinFiltersAndSorts(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now().UTC()
+	lock := func(key, expiresAt string) map[string]any {
+		return map[string]any{
+			"resource_key":    key,
+			"holder_agent_id": "11111111-1111-1111-1111-111111111111",
+			"holder_alias":    "alice",
+			"acquired_at":     now.Add(-time.Hour).Format(time.RFC3339),
+			"expires_at":      expiresAt,
+			"metadata":        map[string]any{},
+		}
+	}
+	server := newLocalHTTPServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/reservations":
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"reservations": []map[string]any{
+					lock("src/late.go", now.Add(time.Hour).Format(time.RFC3339)),
+					lock("src/b.go", now.Add(40*time.Second).Format(time.RFC3339)),
+					lock("src/broken.go", ""),
+					lock("src/a.go", now.Add(10*time.Second).Format(time.RFC3339)),
+				},
+				"has_more": false,
+			})
+		case "/v1/agents/heartbeat":
+			w.WriteHeader(http.StatusOK)
+		default:
+			t.Errorf("unexpected %s %s", r.Method, r.URL.Path)
+		}
+	}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	tmp := t.TempDir()
+	bin := filepath.Join(tmp, "aw")
+	buildAwBinary(t, ctx, bin)
+	writeWorkspaceBindingForTest(t, tmp, workspaceBinding(server.URL, "backend:demo", "alice", "workspace-1"))
+
+	run := exec.CommandContext(ctx, bin, "lock", "list", "--expiring-within", "60s", "--json")
+	run.Env = testCommandEnv(tmp)
+	run.Dir = tmp
+	var stderr strings.Builder
+	run.Stderr = &stderr
+	out, err := run.Output()
+	if err != nil {
+		t.Fatalf("run failed: %v\n%s%s", err, string(out), stderr.String())
+	}
+
+	var got aweb.ReservationListResponse
+	if err := json.Unmarshal(out, &got); err != nil {
+		t.Fatalf("invalid json: %v\n%s", err, string(out))
+	}
+	var keys []string
+	for _, r := range got.Reservations {
+		keys = append(keys, r.ResourceKey)
+	}
+	if strings.Join(keys, ",") != "src/a.go,src/b.go" {
+		t.Fatalf("reservations=%v", keys)
+	}
+	if !strings.Contains(stderr.String(), "skipping lock src/broken.go") {
+		t.Fatalf("expected a warning for the lock without expiry, stderr:\n%s", stderr.String())
+	}
+}
+
+func TestAwOutputRejectsUnknownFormat(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	tmp := t.TempDir()
+	bin := filepath.Join(tmp, "aw")
+	buildAwBinary(t, ctx, bin)
+
+	run := exec.CommandContext(ctx, bin, "lock", "list", "--output", "yaml")
+	run.Env = testCommandEnv(tmp)
+	run.Dir = tmp
+	out, err := run.CombinedOutput()
+	if err == nil || !strings.Contains(string(out), "json-stream") {
+		t.Fatalf("expected --output yaml to be rejected, err=%v\n%s", err, string(out))
+	}
+}
+
+func TestDiffLockSnapshotsByResourceKey(t *testing.T) {
+	t.Parallel()
+
+	prev := map[string]aweb.ReservationView{
+		"src/kept.go":     {ResourceKey: "src/kept.go", HolderAlias: "alice"},
+		"src/handoff.go":  {ResourceKey: "src/handoff.go", HolderAlias: "alice"},
+		"src/released.go": {ResourceKey: "src/released.go", HolderAlias: "bob"},
+	}
+	cur := []aweb.ReservationView{
+		{ResourceKey: "src/kept.go", HolderAlias: "alice"},
+		{ResourceKey: "src/handoff.go", HolderAlias: "bob"},
+		{ResourceKey: "src/new.go", HolderAlias: "carol"},
+	}
+
+	acquired, released := diffLockSnapshots(prev, cur, false)
+	if strings.Join(acquired, ",") != "src/handoff.go,src/new.go" {
+		t.Fatalf("acquired=%v", acquired)
+	}
+	if len(released) != 1 || released[0].ResourceKey != "src/released.go" {
+		t.Fatalf("released=%+v", released)
+	}
+
+	if acquired, _ := diffLockSnapshots(map[string]aweb.ReservationView{}, cur, true); len(acquired) != 0 {
+		t.Fatalf("first poll acquired=%v, want none", acquired)
+	}
+}
+
+func TestFormatLockWatchFrameMarksChanges(t *testing.T) {
+	t.Parallel()
+
+	at := time.Date(2026, 3, 10, 10, 0, 0, 0, time.UTC)
+	out := formatLockWatchFrame(&lockWatchFrame{
+		At: at,
+		Locks: []aweb.ReservationView{
+			{ResourceKey: "src/kept.go", HolderAlias: "alice", ExpiresAt: "2026-03-10T10:05:00Z"},
+			{ResourceKey: "src/new.go", HolderAlias: "carol", ExpiresAt: "2026-03-10T10:00:30Z"},
+		},
+		Acquired: []string{"src/new.go"},
+		Released: []aweb.ReservationView{{ResourceKey: "src/released.go", HolderAlias: "bob"}},
+	}, 5*time.Second)
+
+	for _, want := range []string{
+		"  src/kept.go — alice (expires in 5m)\n",
+		"+ src/new.go — carol (expires in 30s)\n",
+		"- src/released.go — bob (released)\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("missing %q in:\n%s", want, out)
+		}
+	}
+}
+
+func TestAwLockListWatchExitsCleanlyOnInterrupt(t *testing.T) {
+	t.Parallel()
+
+	server := newLocalHTTPServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/reservations":
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"reservations": []map[string]any{{
+					"resource_key": "src/a.go",
+					"holder_alias": "alice",
+					"expires_at":   "2099-03-10T10:00:00Z",
+				}},
+			})
+		case "/v1/agents/heartbeat":
+			w.WriteHeader(http.StatusOK)
+		default:
+			t.Fatalf("unexpected %s %s", r.Method, r.URL.Path)
+		}
+	}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	tmp := t.TempDir()
+	bin := filepath.Join(tmp, "aw")
+	buildAwBinary(t, ctx, bin)
+	writeDefaultWorkspaceBindingForTest(t, tmp, server.URL)
+
+	run := exec.CommandContext(ctx, bin, "lock", "list", "--watch", "--interval", "1", "--json")
+	run.Env = testCommandEnv(tmp)
+	run.Dir = tmp
+	stdout, err := run.StdoutPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := run.Start(); err != nil {
+		t.Fatal(err)
+	}
+	dec := json.NewDecoder(stdout)
+	for i := 0; i < 2; i++ {
+		var frame lockWatchFrame
+		if err := dec.Decode(&frame); err != nil {
+			t.Fatalf("frame %d: %v", i, err)
+		}
+		if len(frame.Locks) != 1 || frame.Locks[0].ResourceKey != "src/a.go" {
+			t.Fatalf("frame %d=%+v", i, frame)
+		}
+	}
+	if err := run.Process.Signal(os.Interrupt); err != nil {
+		t.Fatal(err)
+	}
+	if err := run.Wait(); err != nil {
+		t.Fatalf("watch did not exit cleanly: %v", err)
+	}
+}
+
+func TestFormatReservationEventDescribesEachTransition(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 3, 10, 10, 0, 0, 0, time.UTC)
+	for _, tc := range []struct {
+		ev   aweb.ReservationEvent
+		want string
+	}{
+		{aweb.ReservationEvent{Type: aweb.ReservationAcquired, ResourceKey: "deploy", HolderAlias: "bob", ExpiresAt: "2026-03-10T10:05:00Z", Snapshot: true}, "10:00:00   deploy held by bob (expires in 5m)\n"},
+		{aweb.ReservationEvent{Type: aweb.ReservationAcquired, ResourceKey: "deploy", HolderAlias: "carol", ExpiresAt: "2026-03-10T10:00:30Z"}, "10:00:00 + deploy acquired by carol (expires in 30s)\n"},
+		{aweb.ReservationEvent{Type: aweb.ReservationReleased, ResourceKey: "deploy", HolderAlias: "bob"}, "10:00:00 - deploy released by bob\n"},
+		{aweb.ReservationEvent{Type: aweb.ReservationExpired, ResourceKey: "deploy", HolderAlias: "bob\x1b[2J"}, "10:00:00 - deploy expired (was held by bob\\x1b[2J)\n"},
+	} {
+		if got := formatReservationEvent(tc.ev, now); got != tc.want {
+			t.Fatalf("formatReservationEvent(%+v)=%q, want %q", tc.ev, got, tc.want)
+		}
+	}
+}
+
+func TestAwLockWatchReportsUnsupportedBackend(t *testing.T) {
+	t.Parallel()
+
+	server := newLocalHTTPServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/reservations/watch":
+			http.NotFound(w, r)
+		case "/v1/agents/heartbeat":
+			w.WriteHeader(http.StatusOK)
+		default:
+			t.Errorf("unexpected %s %s", r.Method, r.URL.Path)
+		}
+	}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	tmp := t.TempDir()
+	bin := filepath.Join(tmp, "aw")
+	buildAwBinary(t, ctx, bin)
+	writeDefaultWorkspaceBindingForTest(t, tmp, server.URL)
+
+	run := exec.CommandContext(ctx, bin, "lock", "watch", "--resource-key", "deploy")
+	run.Env = testCommandEnv(tmp)
+	run.Dir = tmp
+	out, err := run.CombinedOutput()
+	if err == nil {
+		t.Fatalf("expected failure, output=%s", out)
+	}
+	if !strings.Contains(string(out), "aw lock list --watch") {
+		t.Fatalf("output=%s", out)
+	}
+}
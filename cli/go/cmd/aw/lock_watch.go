@@ -0,0 +1,192 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"time"
+
+	aweb "github.com/awebai/aw"
+	"github.com/awebai/aw/awconfig"
+)
+
+const defaultLockWatchInterval = 5
+
+// lockWatchFrame is one redraw of `aw lock list --watch`. Acquired holds the
+// keys that appeared (or changed holder) since the previous tick; Released
+// holds the locks that were present then and are gone now.
+type lockWatchFrame struct {
+	At       time.Time              `json:"at"`
+	Locks    []aweb.ReservationView `json:"locks"`
+	Acquired []string               `json:"acquired"`
+	Released []aweb.ReservationView `json:"released"`
+}
+
+// diffLockSnapshots compares two polls by resource_key. The first poll has
+// no previous snapshot, so nothing is reported as newly acquired.
+func diffLockSnapshots(prev map[string]aweb.ReservationView, cur []aweb.ReservationView, first bool) (acquired []string, released []aweb.ReservationView) {
+	seen := make(map[string]struct{}, len(cur))
+	for _, r := range cur {
+		seen[r.ResourceKey] = struct{}{}
+		if first {
+			continue
+		}
+		if old, ok := prev[r.ResourceKey]; !ok || old.HolderAgentID != r.HolderAgentID || old.HolderAlias != r.HolderAlias {
+			acquired = append(acquired, r.ResourceKey)
+		}
+	}
+	for key, r := range prev {
+		if _, ok := seen[key]; !ok {
+			released = append(released, r)
+		}
+	}
+	sort.Strings(acquired)
+	sort.Slice(released, func(i, j int) bool { return released[i].ResourceKey < released[j].ResourceKey })
+	return acquired, released
+}
+
+func formatLockWatchFrame(frame *lockWatchFrame, interval time.Duration) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Locks at %s (every %s, Ctrl-C to stop)\n\n", frame.At.Format("15:04:05"), interval))
+	acquired := make(map[string]struct{}, len(frame.Acquired))
+	for _, key := range frame.Acquired {
+		acquired[key] = struct{}{}
+	}
+	if len(frame.Locks) == 0 && len(frame.Released) == 0 {
+		sb.WriteString("No active locks.\n")
+	}
+	for _, r := range frame.Locks {
+		marker := " "
+		if _, ok := acquired[r.ResourceKey]; ok {
+			marker = "+"
+		}
+		sb.WriteString(fmt.Sprintf("%s %s — %s (expires in %s)\n", marker, r.ResourceKey, r.HolderAlias, formatDuration(ttlRemainingSeconds(r.ExpiresAt, frame.At))))
+	}
+	for _, r := range frame.Released {
+		sb.WriteString(fmt.Sprintf("- %s — %s (released)\n", r.ResourceKey, r.HolderAlias))
+	}
+	return sb.String()
+}
+
+// runLockListWatch polls the lock list every interval and redraws it until
+// interrupted. With --json each tick is printed as one JSON line instead.
+func runLockListWatch(c *aweb.Client, sel *awconfig.Selection, interval time.Duration, out io.Writer) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	redraw := writerSupportsANSI(out)
+	enc := json.NewEncoder(out)
+	prev := map[string]aweb.ReservationView{}
+	first := true
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		pollCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+		locks, err := c.ReservationListAll(pollCtx, lockListPrefix)
+		cancel()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		if lockListMine {
+			locks = filterLocksByHolder(locks, sel.Alias)
+		}
+
+		frame := &lockWatchFrame{At: time.Now(), Locks: locks}
+		frame.Acquired, frame.Released = diffLockSnapshots(prev, locks, first)
+		if frame.Acquired == nil {
+			frame.Acquired = []string{}
+		}
+		if frame.Released == nil {
+			frame.Released = []aweb.ReservationView{}
+		}
+		prev = make(map[string]aweb.ReservationView, len(locks))
+		for _, r := range locks {
+			prev[r.ResourceKey] = r
+		}
+		first = false
+
+		switch {
+		case jsonFlag:
+			if err := enc.Encode(frame); err != nil {
+				return err
+			}
+		case redraw:
+			fmt.Fprint(out, "\033[H\033[2J"+formatLockWatchFrame(frame, interval))
+		default:
+			fmt.Fprintln(out, formatLockWatchFrame(frame, interval))
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// runLockWatch prints each change to resourceKey reported by the server's
+// reservation watch stream until interrupted.
+func runLockWatch(c *aweb.Client, resourceKey string, out io.Writer) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	events, err := c.WatchReservation(ctx, resourceKey)
+	if errors.Is(err, aweb.ErrReservationWatchUnsupported) {
+		return errors.New("lock watch is not supported by the current backend; use `aw lock list --watch` to poll instead")
+	}
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(out)
+	if !jsonFlag {
+		fmt.Fprintf(out, "Watching %s (Ctrl-C to stop)\n", terminalText(resourceKey))
+	}
+	for ev := range events {
+		if jsonFlag {
+			if err := enc.Encode(ev); err != nil {
+				return err
+			}
+			continue
+		}
+		fmt.Fprint(out, formatReservationEvent(ev, time.Now()))
+	}
+	return nil
+}
+
+func formatReservationEvent(ev aweb.ReservationEvent, now time.Time) string {
+	key := terminalText(ev.ResourceKey)
+	holder := terminalText(ev.HolderAlias)
+	prefix := now.Format("15:04:05") + " "
+	switch ev.Type {
+	case aweb.ReservationAcquired:
+		expires := formatDuration(ttlRemainingSeconds(ev.ExpiresAt, now))
+		if ev.Snapshot {
+			return fmt.Sprintf("%s  %s held by %s (expires in %s)\n", prefix, key, holder, expires)
+		}
+		return fmt.Sprintf("%s+ %s acquired by %s (expires in %s)\n", prefix, key, holder, expires)
+	case aweb.ReservationReleased:
+		return fmt.Sprintf("%s- %s released by %s\n", prefix, key, holder)
+	case aweb.ReservationExpired:
+		return fmt.Sprintf("%s- %s expired (was held by %s)\n", prefix, key, holder)
+	}
+	return fmt.Sprintf("%s? %s %s\n", prefix, key, terminalText(string(ev.Type)))
+}
+
+func filterLocksByHolder(locks []aweb.ReservationView, alias string) []aweb.ReservationView {
+	filtered := make([]aweb.ReservationView, 0, len(locks))
+	for _, reservation := range locks {
+		if reservation.HolderAlias == alias {
+			filtered = append(filtered, reservation)
+		}
+	}
+	return filtered
+}
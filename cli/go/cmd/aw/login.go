@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/awebai/aw/awconfig"
+	"github.com/awebai/aw/awid"
+	"github.com/spf13/cobra"
+)
+
+var (
+	loginServer  string
+	loginTimeout time.Duration
+)
+
+var loginCmd = &cobra.Command{
+	Use:   "login",
+	Short: "Log in through the browser and store an API key for aw init",
+	Long: `Log in with the OAuth device flow. aw prints a URL and a code; open the
+URL, confirm the code, and aw stores the API key the server issues in
+~/.config/aw/credentials.yaml. A later ` + "`aw init --aweb-url <server>`" + ` uses that
+key the same way it uses AWEB_API_KEY, so the unauthenticated init
+endpoint does not need to be exposed.`,
+	RunE: runLogin,
+}
+
+type loginOutput struct {
+	Status      string `json:"status"`
+	Server      string `json:"server"`
+	Credentials string `json:"credentials"`
+}
+
+func init() {
+	loginCmd.Flags().StringVar(&loginServer, "server", "", "aweb server URL (defaults to AWEB_URL)")
+	loginCmd.Flags().DurationVar(&loginTimeout, "timeout", 15*time.Minute, "How long to wait for the login to be approved")
+	loginCmd.GroupID = groupIdentity
+	rootCmd.AddCommand(loginCmd)
+}
+
+func runLogin(cmd *cobra.Command, args []string) error {
+	raw := strings.TrimSpace(loginServer)
+	if raw == "" {
+		raw = envAwebURL()
+	}
+	if raw == "" {
+		return usageError("--server or AWEB_URL is required")
+	}
+	server, err := normalizeAPIKeyBootstrapBaseURL(raw)
+	if err != nil {
+		return usageError("invalid --server %q: %v", raw, err)
+	}
+	client, err := awid.New(server + "/api")
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), loginTimeout)
+	defer cancel()
+
+	token, err := client.DeviceLogin(ctx, func(auth *awid.DeviceAuthorization) {
+		fmt.Fprint(os.Stderr, formatDeviceLoginPrompt(auth))
+	})
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return fmt.Errorf("login was not approved within %s", loginTimeout)
+		}
+		return fmt.Errorf("login: %w", err)
+	}
+	apiKey := strings.TrimSpace(token.AccessToken)
+	if len(apiKey) > maxWorkspaceAPIKeyLength {
+		return fmt.Errorf("login response api key exceeds %d bytes", maxWorkspaceAPIKeyLength)
+	}
+
+	path, err := awconfig.DefaultCredentialsPath()
+	if err != nil {
+		return err
+	}
+	err = awconfig.UpdateCredentialsAt(path, func(creds *awconfig.Credentials) error {
+		creds.SetAPIKey(server, apiKey, time.Now().UTC().Format(time.RFC3339))
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("write %s: %w", path, err)
+	}
+
+	printOutput(loginOutput{Status: "logged_in", Server: server, Credentials: path}, formatLogin)
+	return nil
+}
+
+// storedLoginAPIKey returns the key `aw login` stored for serverURL, or "".
+func storedLoginAPIKey(serverURL string) string {
+	if strings.TrimSpace(serverURL) == "" {
+		return ""
+	}
+	server, err := normalizeAPIKeyBootstrapBaseURL(serverURL)
+	if err != nil {
+		return ""
+	}
+	path, err := awconfig.DefaultCredentialsPath()
+	if err != nil {
+		return ""
+	}
+	creds, err := awconfig.LoadCredentialsFrom(path)
+	if err != nil {
+		return ""
+	}
+	return creds.APIKeyFor(server)
+}
+
+func formatDeviceLoginPrompt(auth *awid.DeviceAuthorization) string {
+	var sb strings.Builder
+	if auth.VerificationURIComplete != "" {
+		sb.WriteString(fmt.Sprintf("Open %s\n", auth.VerificationURIComplete))
+		sb.WriteString(fmt.Sprintf("and confirm the code %s\n", auth.UserCode))
+	} else {
+		sb.WriteString(fmt.Sprintf("Open %s\n", auth.VerificationURI))
+		sb.WriteString(fmt.Sprintf("and enter the code %s\n", auth.UserCode))
+	}
+	sb.WriteString("Waiting for approval...\n")
+	return sb.String()
+}
+
+func formatLogin(v any) string {
+	out := v.(loginOutput)
+	return fmt.Sprintf("Logged in to %s\nAPI key stored in %s\nRun `aw init --aweb-url %s` to create a workspace with it.\n", out.Server, out.Credentials, out.Server)
+}
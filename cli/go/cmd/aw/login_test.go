@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/awebai/aw/awconfig"
+	"github.com/awebai/aw/awid"
+)
+
+func TestAwLoginStoresKeyUsedByInit(t *testing.T) {
+	// Cannot use t.Parallel() — sets HOME and init globals.
+
+	var polls atomic.Int32
+	server := newLocalHTTPServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/auth/device/code":
+			_ = json.NewEncoder(w).Encode(awid.DeviceAuthorization{
+				DeviceCode:      "dev-1",
+				UserCode:        "WDJB-MJHT",
+				VerificationURI: "https://login.example.com/device",
+				ExpiresIn:       60,
+				Interval:        1,
+			})
+		case "/api/v1/auth/device/token":
+			if polls.Add(1) == 1 {
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = w.Write([]byte(`{"error":"authorization_pending"}`))
+				return
+			}
+			_ = json.NewEncoder(w).Encode(awid.DeviceToken{AccessToken: "aw_sk_from_login", TokenType: "bearer"})
+		default:
+			t.Errorf("unexpected path=%s", r.URL.Path)
+			http.NotFound(w, r)
+		}
+	}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	tmp := t.TempDir()
+	bin := filepath.Join(tmp, "aw")
+	buildAwBinary(t, ctx, bin)
+
+	run := exec.CommandContext(ctx, bin, "login", "--server", server.URL+"/api")
+	run.Env = append(testCommandEnv(tmp), "AWEB_URL=")
+	run.Dir = tmp
+	out, err := run.CombinedOutput()
+	if err != nil {
+		t.Fatalf("run failed: %v\n%s", err, string(out))
+	}
+	for _, want := range []string{"https://login.example.com/device", "WDJB-MJHT", "Logged in to " + server.URL} {
+		if !strings.Contains(string(out), want) {
+			t.Fatalf("output missing %q:\n%s", want, out)
+		}
+	}
+
+	creds, err := awconfig.LoadCredentialsFrom(filepath.Join(tmp, ".config", "aw", "credentials.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := creds.APIKeyFor(server.URL); got != "aw_sk_from_login" {
+		t.Fatalf("stored key=%q", got)
+	}
+
+	oldAwebURL, oldCompatURL := initAwebURL, initURL
+	t.Cleanup(func() { initAwebURL, initURL = oldAwebURL, oldCompatURL })
+	t.Setenv("HOME", tmp)
+	t.Setenv(initAPIKeyEnvVar, "")
+	t.Setenv("AWEB_URL", "")
+	initURL = ""
+
+	initAwebURL = ""
+	if got := resolveInitAPIKey(); got != "" {
+		t.Fatalf("key without an explicit server=%q, want none", got)
+	}
+	initAwebURL = server.URL + "/api"
+	if got := resolveInitAPIKey(); got != "aw_sk_from_login" {
+		t.Fatalf("resolveInitAPIKey=%q, want the stored login key", got)
+	}
+	t.Setenv(initAPIKeyEnvVar, "aw_sk_env")
+	if got := resolveInitAPIKey(); got != "aw_sk_env" {
+		t.Fatalf("resolveInitAPIKey=%q, want AWEB_API_KEY to win", got)
+	}
+	t.Cleanup(func() { initStdinAPIKey = "" })
+	initStdinAPIKey = "aw_sk_stdin"
+	if got := resolveInitAPIKey(); got != "aw_sk_stdin" {
+		t.Fatalf("resolveInitAPIKey=%q, want the --api-key-stdin key to win", got)
+	}
+}
@@ -2,8 +2,12 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"mime"
+	"net/http"
 	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -21,24 +25,37 @@ var mailCmd = &cobra.Command{
 // mail send
 
 var (
-	mailSendTo        string
+	mailSendTo        []string
 	mailSendToDID     string
 	mailSendToAddress string
 	mailSendSubject   string
 	mailSendBody      string
 	mailSendBodyFile  string
 	mailSendPriority  string
+	mailSendAttach    []string
+	mailSendQueue     bool
 )
 
 var mailSendCmd = &cobra.Command{
 	Use:   "send",
 	Short: "Send a message to another agent",
 	RunE: func(cmd *cobra.Command, args []string) error {
+		priority, err := awid.ParsePriority(mailSendPriority)
+		if err != nil {
+			return usageError("--priority: %v", err)
+		}
 		body, err := resolveMailBody(mailSendBody, mailSendBodyFile)
 		if err != nil {
 			return err
 		}
 		mailSendBody = body
+		attachments, err := loadMailAttachments(mailSendAttach)
+		if err != nil {
+			return err
+		}
+		if err := awid.ValidateAttachments(mailSendBody, attachments); err != nil {
+			return usageError("%v", err)
+		}
 		targetKind, targetValue, err := resolveMailTarget()
 		if err != nil {
 			return err
@@ -50,19 +67,27 @@ var mailSendCmd = &cobra.Command{
 		var c *aweb.Client
 		var sel *awconfig.Selection
 		req := &awid.SendMessageRequest{
-			Subject:  mailSendSubject,
-			Body:     mailSendBody,
-			Priority: awid.MessagePriority(mailSendPriority),
+			Subject:     mailSendSubject,
+			Body:        mailSendBody,
+			Priority:    priority,
+			Attachments: attachments,
+		}
+		if targetKind == "aliases" {
+			req.ToAliases = strings.Split(targetValue, ",")
+			return sendMailToAliases(ctx, req)
 		}
 		switch targetKind {
 		case "alias":
+			if err := aweb.ValidateAlias(targetValue); err != nil {
+				return usageError("--to: %v", err)
+			}
 			c, sel, err = resolveClientSelectionForAliasTarget(ctx, targetValue)
 			if err != nil {
 				return err
 			}
 			req.ToAlias = targetValue
 		case "did":
-			if strings.TrimSpace(teamFlag) != "" {
+			if strings.TrimSpace(teamFlag) != "" || asHumanFlag {
 				c, sel, err = resolveClientSelection()
 			} else {
 				c, sel, err = resolveIdentityMessagingClientSelection()
@@ -72,7 +97,7 @@ var mailSendCmd = &cobra.Command{
 			}
 			req.ToDID = targetValue
 		case "address":
-			if strings.TrimSpace(teamFlag) != "" {
+			if strings.TrimSpace(teamFlag) != "" || asHumanFlag {
 				c, sel, err = resolveClientSelection()
 			} else {
 				c, sel, err = resolveIdentityMessagingClientSelection()
@@ -86,7 +111,26 @@ var mailSendCmd = &cobra.Command{
 		}
 
 		var resp *awid.SendMessageResponse
-		if targetKind == "alias" {
+		if mailSendQueue {
+			queueDir, err := awconfig.DefaultMailQueueDir()
+			if err != nil {
+				return err
+			}
+			c.SetMailQueueDir(queueDir)
+			var queued bool
+			resp, queued, err = c.SendMessageQueued(ctx, req, targetKind != "alias")
+			if err != nil {
+				return networkError(err, targetValue)
+			}
+			if queued {
+				if jsonFlag {
+					printJSON(map[string]any{"status": "queued", "to": targetValue})
+				} else {
+					fmt.Printf("Server unreachable; queued mail to %s (run `aw mail flush` to retry)\n", targetValue)
+				}
+				return nil
+			}
+		} else if targetKind == "alias" {
 			resp, err = c.SendMessage(ctx, req)
 		} else {
 			resp, err = c.SendMessageByIdentity(ctx, req)
@@ -124,11 +168,80 @@ var mailSendCmd = &cobra.Command{
 			printJSON(resp)
 		} else {
 			fmt.Printf("Sent mail to %s (message_id=%s)\n", targetValue, resp.MessageID)
+			if resolved := formatResolvedRecipient(resp); resolved != "" {
+				fmt.Printf("Delivered to %s\n", resolved)
+			}
 		}
 		return nil
 	},
 }
 
+// sendMailToAliases sends one message per alias for a repeated --to. It
+// reports every recipient and fails if any send failed.
+func sendMailToAliases(ctx context.Context, req *awid.SendMessageRequest) error {
+	if mailSendQueue {
+		return usageError("--queue does not support multiple --to recipients")
+	}
+	for _, alias := range req.ToAliases {
+		if err := aweb.ValidateAlias(alias); err != nil {
+			return usageError("--to: %v", err)
+		}
+	}
+	c, sel, err := resolveClientSelection()
+	if err != nil {
+		return err
+	}
+	resp, err := c.SendMessageMulti(ctx, req)
+	if err != nil {
+		return err
+	}
+	logsDir := defaultLogsDir()
+	from := preferredIdentityDisplayLabel("", selectionAddress(sel), strings.TrimSpace(sel.StableID), strings.TrimSpace(sel.DID), "")
+	for _, r := range resp.Results {
+		if r.Error != "" {
+			continue
+		}
+		appendCommLog(logsDir, commLogNameForSelection(sel), &CommLogEntry{
+			Timestamp: time.Now().UTC().Format(time.RFC3339),
+			Dir:       "send",
+			Channel:   "mail",
+			MessageID: r.Response.MessageID,
+			From:      from,
+			To:        r.ToAlias,
+			Subject:   req.Subject,
+			Body:      req.Body,
+		})
+		appendInteractionLogForCWD(&InteractionEntry{
+			Timestamp: time.Now().UTC().Format(time.RFC3339),
+			Kind:      interactionKindMailOut,
+			MessageID: r.Response.MessageID,
+			To:        r.ToAlias,
+			Subject:   req.Subject,
+			Text:      req.Body,
+		})
+	}
+	printOutput(resp, formatMailSendMulti)
+	if failed := resp.Failed(); len(failed) > 0 {
+		return fmt.Errorf("failed to send to %d of %d recipients", len(failed), len(resp.Results))
+	}
+	return nil
+}
+
+// formatResolvedRecipient describes the agent the server delivered to, so a
+// sender can confirm an alias reached the agent they meant.
+func formatResolvedRecipient(resp *awid.SendMessageResponse) string {
+	alias := strings.TrimSpace(resp.ToAlias)
+	agentID := strings.TrimSpace(resp.ToAgentID)
+	switch {
+	case alias != "" && agentID != "":
+		return fmt.Sprintf("%s (agent_id=%s)", alias, agentID)
+	case alias != "":
+		return alias
+	default:
+		return agentID
+	}
+}
+
 // resolveMailBody returns the message body, sourcing it from --body or
 // --body-file. Reading from a file bypasses shell interpolation and is the
 // only safe way to send markdown that contains backticks. Exactly one
@@ -157,9 +270,48 @@ func resolveMailBody(bodyArg, bodyFileArg string) (string, error) {
 	return body, nil
 }
 
+// loadMailAttachments reads each --attach path into an attachment. Files
+// larger than the message size budget are rejected from their size alone so
+// the CLI never reads them into memory.
+func loadMailAttachments(paths []string) ([]awid.Attachment, error) {
+	var attachments []awid.Attachment
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, fmt.Errorf("attach %q: %w", path, err)
+		}
+		if info.IsDir() {
+			return nil, usageError("attach %q: is a directory", path)
+		}
+		if info.Size() > awid.MaxAttachmentsSize {
+			return nil, usageError("attach %q: file is %d bytes, exceeding the %d byte limit", path, info.Size(), awid.MaxAttachmentsSize)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("attach %q: %w", path, err)
+		}
+		contentType := mime.TypeByExtension(filepath.Ext(path))
+		if contentType == "" {
+			contentType = http.DetectContentType(data)
+		}
+		attachments = append(attachments, awid.Attachment{
+			Name:        filepath.Base(path),
+			ContentType: contentType,
+			Data:        data,
+		})
+	}
+	return attachments, nil
+}
+
 func resolveMailTarget() (string, string, error) {
+	var to []string
+	for _, value := range mailSendTo {
+		if value = strings.TrimSpace(value); value != "" {
+			to = append(to, value)
+		}
+	}
 	count := 0
-	if strings.TrimSpace(mailSendTo) != "" {
+	if len(to) > 0 {
 		count++
 	}
 	if strings.TrimSpace(mailSendToDID) != "" {
@@ -174,7 +326,16 @@ func resolveMailTarget() (string, string, error) {
 	if count > 1 {
 		return "", "", usageError("recipient flags are mutually exclusive: use only one of --to, --to-did, or --to-address")
 	}
-	if value := strings.TrimSpace(mailSendTo); value != "" {
+	if len(to) > 1 {
+		for _, value := range to {
+			if strings.HasPrefix(value, "did:") || strings.Contains(value, "/") {
+				return "", "", usageError("--to can only be repeated with aliases; send to %s separately", value)
+			}
+		}
+		return "aliases", strings.Join(to, ","), nil
+	}
+	if len(to) == 1 {
+		value := to[0]
 		switch {
 		case strings.HasPrefix(value, "did:"):
 			return "did", value, nil
@@ -195,6 +356,7 @@ func resolveMailTarget() (string, string, error) {
 var (
 	mailInboxShowAll bool
 	mailInboxLimit   int
+	mailInboxAckAll  bool
 )
 
 var mailInboxCmd = &cobra.Command{
@@ -216,9 +378,25 @@ var mailInboxCmd = &cobra.Command{
 			return err
 		}
 		// Mark all unread messages as read — seeing them means they're read.
-		for _, msg := range resp.Messages {
-			if msg.ReadAt == nil && msg.MessageID != "" {
-				_, _ = c.AckMessage(ctx, msg.MessageID)
+		// --ack-all extends this to every displayed message, in one batch,
+		// and reports per-message failures instead of ignoring them.
+		var ackIDs []string
+		var ackResp *awid.BulkAckResponse
+		if mailInboxAckAll {
+			for _, msg := range resp.Messages {
+				if msg.MessageID != "" {
+					ackIDs = append(ackIDs, msg.MessageID)
+				}
+			}
+			ackResp, err = c.AckMessages(ctx, ackIDs)
+			if err != nil {
+				return fmt.Errorf("ack messages: %w", err)
+			}
+		} else {
+			for _, msg := range resp.Messages {
+				if msg.ReadAt == nil && msg.MessageID != "" {
+					_, _ = c.AckMessage(ctx, msg.MessageID)
+				}
 			}
 		}
 		logsDir := defaultLogsDir()
@@ -268,23 +446,163 @@ var mailInboxCmd = &cobra.Command{
 				Text:      msg.Body,
 			})
 		}
-		printOutput(resp, formatMailInbox)
+		if jsonStreamOutput() {
+			printJSONLines(resp.Messages)
+		} else {
+			printOutput(resp, formatMailInbox)
+		}
+		if mailInboxAckAll {
+			if failed := ackResp.Failed(); len(failed) > 0 {
+				for _, f := range failed {
+					fmt.Fprintf(os.Stderr, "warning: failed to ack %s: %s\n", f.MessageID, f.Error)
+				}
+				return fmt.Errorf("failed to ack %d of %d messages", len(failed), len(ackIDs))
+			}
+		}
+		return nil
+	},
+}
+
+// mail show
+
+var mailShowMessageID string
+
+var mailShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Show one message by ID without acknowledging it",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if strings.TrimSpace(mailShowMessageID) == "" {
+			return usageError("missing required flag: --message-id")
+		}
+		c, err := resolveClient()
+		if err != nil {
+			return err
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		msg, err := c.GetMessage(ctx, mailShowMessageID)
+		if err != nil {
+			if errors.Is(err, awid.ErrMessageNotFound) {
+				return fmt.Errorf("message not found: %s", strings.TrimSpace(mailShowMessageID))
+			}
+			return err
+		}
+		printOutput(msg, formatMailShow)
+		return nil
+	},
+}
+
+// mail thread
+
+var (
+	mailThreadID        string
+	mailThreadMessageID string
+)
+
+var mailThreadCmd = &cobra.Command{
+	Use:   "thread",
+	Short: "Show a mail thread oldest first without acknowledging it",
+	Long: `Show a mail thread oldest first without acknowledging it.
+
+Pass --thread-id, or --message-id to show the thread that message belongs to.
+The thread is assembled from your inbox, so it holds the messages you
+received, not the replies you sent.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		threadID := strings.TrimSpace(mailThreadID)
+		messageID := strings.TrimSpace(mailThreadMessageID)
+		if (threadID == "") == (messageID == "") {
+			return usageError("pass exactly one of --thread-id or --message-id")
+		}
+		c, err := resolveClient()
+		if err != nil {
+			return err
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		if messageID != "" {
+			msg, err := c.GetMessage(ctx, messageID)
+			if err != nil {
+				if errors.Is(err, awid.ErrMessageNotFound) {
+					return fmt.Errorf("message not found: %s", messageID)
+				}
+				return err
+			}
+			if msg.ThreadID == nil || strings.TrimSpace(*msg.ThreadID) == "" {
+				return fmt.Errorf("message %s is not part of a thread; use `aw mail show --message-id %s`", messageID, messageID)
+			}
+			threadID = strings.TrimSpace(*msg.ThreadID)
+		}
+		messages, err := c.GetThread(ctx, threadID)
+		if err != nil {
+			return err
+		}
+		if jsonStreamOutput() {
+			printJSONLines(messages)
+			return nil
+		}
+		printOutput(messages, formatMailThread)
+		return nil
+	},
+}
+
+// mail flush
+
+var mailFlushCmd = &cobra.Command{
+	Use:   "flush",
+	Short: "Retry mail queued by `aw mail send --queue` while the server was unreachable",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// Alias sends need the team client; an identity-only setup can
+		// only have queued identity sends.
+		c, err := resolveClient()
+		if err != nil {
+			if c, _, err = resolveIdentityMessagingClientSelection(); err != nil {
+				return err
+			}
+		}
+		queueDir, err := awconfig.DefaultMailQueueDir()
+		if err != nil {
+			return err
+		}
+		c.SetMailQueueDir(queueDir)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+		defer cancel()
+		result, err := c.FlushQueue(ctx)
+		if result != nil {
+			printOutput(result, formatMailFlush)
+		}
+		if err != nil {
+			return err
+		}
+		if len(result.Failed) > 0 {
+			return fmt.Errorf("server rejected %d queued message(s)", len(result.Failed))
+		}
 		return nil
 	},
 }
 
 func init() {
-	mailSendCmd.Flags().StringVar(&mailSendTo, "to", "", "Recipient alias within the active team")
+	mailSendCmd.Flags().StringArrayVar(&mailSendTo, "to", nil, "Recipient alias within the active team (repeat to send to several aliases)")
 	mailSendCmd.Flags().StringVar(&mailSendToDID, "to-did", "", "Recipient stable identity (did:aw:...)")
 	mailSendCmd.Flags().StringVar(&mailSendToAddress, "to-address", "", "Recipient address (domain/name)")
 	mailSendCmd.Flags().StringVar(&mailSendSubject, "subject", "", "Subject")
 	mailSendCmd.Flags().StringVar(&mailSendBody, "body", "", "Body (mutually exclusive with --body-file)")
 	mailSendCmd.Flags().StringVar(&mailSendBodyFile, "body-file", "", "Read body from file (use this for markdown with backticks; bypasses shell interpolation)")
 	mailSendCmd.Flags().StringVar(&mailSendPriority, "priority", "normal", "Priority: low|normal|high|urgent")
+	mailSendCmd.Flags().StringArrayVar(&mailSendAttach, "attach", nil, "Attach a file (repeatable; 1MB total)")
+	mailSendCmd.Flags().BoolVar(&mailSendQueue, "queue", false, "Queue the message for `aw mail flush` if the server is unreachable")
 
 	mailInboxCmd.Flags().BoolVar(&mailInboxShowAll, "show-all", false, "Show all messages including already-read")
 	mailInboxCmd.Flags().IntVar(&mailInboxLimit, "limit", 50, "Max messages")
+	mailInboxCmd.Flags().BoolVar(&mailInboxAckAll, "ack-all", false, "Acknowledge every displayed message (including already-read with --show-all) and fail on any ack error")
+
+	mailShowCmd.Flags().StringVar(&mailShowMessageID, "message-id", "", "Message ID")
+
+	mailThreadCmd.Flags().StringVar(&mailThreadID, "thread-id", "", "Thread ID")
+	mailThreadCmd.Flags().StringVar(&mailThreadMessageID, "message-id", "", "Show the thread this message belongs to")
 
-	mailCmd.AddCommand(mailSendCmd, mailInboxCmd)
+	mailCmd.AddCommand(mailSendCmd, mailInboxCmd, mailShowCmd, mailThreadCmd, mailFlushCmd)
 	rootCmd.AddCommand(mailCmd)
 }
@@ -9,6 +9,7 @@ import (
 	"os/exec"
 	"path/filepath"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -24,7 +25,7 @@ func TestResolveMailTargetKeepsTildeTargetAsAlias(t *testing.T) {
 		mailSendToAddress = oldToAddress
 	})
 
-	mailSendTo = "ops~alice"
+	mailSendTo = []string{"ops~alice"}
 	mailSendToDID = ""
 	mailSendToAddress = ""
 
@@ -40,6 +41,31 @@ func TestResolveMailTargetKeepsTildeTargetAsAlias(t *testing.T) {
 	}
 }
 
+func TestResolveMailTargetRepeatedToRequiresAliases(t *testing.T) {
+	oldTo, oldToDID, oldToAddress := mailSendTo, mailSendToDID, mailSendToAddress
+	t.Cleanup(func() {
+		mailSendTo = oldTo
+		mailSendToDID = oldToDID
+		mailSendToAddress = oldToAddress
+	})
+	mailSendToDID = ""
+	mailSendToAddress = ""
+
+	mailSendTo = []string{"bob", " carol "}
+	kind, value, err := resolveMailTarget()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if kind != "aliases" || value != "bob,carol" {
+		t.Fatalf("kind=%q value=%q, want aliases bob,carol", kind, value)
+	}
+
+	mailSendTo = []string{"bob", "acme.com/carol"}
+	if _, _, err := resolveMailTarget(); err == nil || !strings.Contains(err.Error(), "acme.com/carol") {
+		t.Fatalf("err=%v, want the address rejected", err)
+	}
+}
+
 func TestResolveMailBodyUsesBodyArg(t *testing.T) {
 	body, err := resolveMailBody("hello", "")
 	if err != nil {
@@ -269,3 +295,172 @@ func TestAwMailSendRejectsBothBodyAndBodyFile(t *testing.T) {
 		t.Fatalf("expected mutually exclusive error, got:\n%s", string(out))
 	}
 }
+
+func TestAwMailSendRejectsUnknownPriority(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	tmp := t.TempDir()
+	bin := filepath.Join(tmp, "aw")
+	buildAwBinary(t, ctx, bin)
+	writeDefaultWorkspaceBindingForTest(t, tmp, "http://127.0.0.1:1")
+
+	run := exec.CommandContext(ctx, bin, "mail", "send",
+		"--to", "alice",
+		"--body", "hi",
+		"--priority", "hgih",
+	)
+	run.Env = testCommandEnv(tmp)
+	run.Dir = tmp
+	out, err := run.CombinedOutput()
+	if err == nil {
+		t.Fatalf("expected failure, got success:\n%s", string(out))
+	}
+	if !strings.Contains(string(out), `invalid priority "hgih" (valid: low, normal, high, urgent)`) {
+		t.Fatalf("expected priority error, got:\n%s", string(out))
+	}
+}
+
+func TestAwMailSendPrintsResolvedRecipient(t *testing.T) {
+	t.Parallel()
+
+	server := newLocalHTTPServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/messages":
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"message_id":   "msg-1",
+				"status":       "delivered",
+				"delivered_at": "2026-03-17T12:00:00Z",
+				"to_agent_id":  "agent-bob",
+				"to_alias":     "bob",
+			})
+		case "/v1/agents/heartbeat":
+			w.WriteHeader(http.StatusOK)
+		default:
+			t.Fatalf("unexpected path=%s", r.URL.Path)
+		}
+	}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	tmp := t.TempDir()
+	bin := filepath.Join(tmp, "aw")
+	buildAwBinary(t, ctx, bin)
+	writeDefaultWorkspaceBindingForTest(t, tmp, server.URL)
+
+	run := exec.CommandContext(ctx, bin, "mail", "send", "--to", "bob", "--body", "hi")
+	run.Env = testCommandEnv(tmp)
+	run.Dir = tmp
+	out, err := run.CombinedOutput()
+	if err != nil {
+		t.Fatalf("run failed: %v\n%s", err, string(out))
+	}
+	if !strings.Contains(string(out), "Delivered to bob (agent_id=agent-bob)") {
+		t.Fatalf("expected resolved recipient, got:\n%s", string(out))
+	}
+}
+
+func TestAwMailSendRejectsOversizedAttachmentBeforeSending(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	tmp := t.TempDir()
+	bin := filepath.Join(tmp, "aw")
+	buildAwBinary(t, ctx, bin)
+	writeDefaultWorkspaceBindingForTest(t, tmp, "http://127.0.0.1:1")
+
+	big := filepath.Join(tmp, "big.bin")
+	f, err := os.Create(big)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Truncate(awid.MaxAttachmentsSize + 1); err != nil {
+		t.Fatal(err)
+	}
+	_ = f.Close()
+
+	run := exec.CommandContext(ctx, bin, "mail", "send",
+		"--to", "alice",
+		"--body", "see attached",
+		"--attach", big,
+	)
+	run.Env = testCommandEnv(tmp)
+	run.Dir = tmp
+	out, err := run.CombinedOutput()
+	if err == nil {
+		t.Fatalf("expected failure, got success:\n%s", string(out))
+	}
+	if !strings.Contains(string(out), "exceeding the 1048576 byte limit") {
+		t.Fatalf("expected size limit error, got:\n%s", string(out))
+	}
+}
+
+func TestAwMailSendQueueAndFlush(t *testing.T) {
+	t.Parallel()
+
+	var available atomic.Bool
+	var delivered []string
+	server := newLocalHTTPServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/messages":
+			if !available.Load() {
+				http.Error(w, "bad gateway", http.StatusBadGateway)
+				return
+			}
+			var req awid.SendMessageRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				t.Fatalf("decode body: %v", err)
+			}
+			delivered = append(delivered, req.MessageID)
+			_ = json.NewEncoder(w).Encode(awid.SendMessageResponse{MessageID: req.MessageID, Status: "delivered"})
+		case "/v1/agents/heartbeat":
+			w.WriteHeader(http.StatusOK)
+		default:
+			t.Fatalf("unexpected path=%s", r.URL.Path)
+		}
+	}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	tmp := t.TempDir()
+	bin := filepath.Join(tmp, "aw")
+	buildAwBinary(t, ctx, bin)
+	writeDefaultWorkspaceBindingForTest(t, tmp, server.URL)
+
+	run := func(args ...string) string {
+		t.Helper()
+		cmd := exec.CommandContext(ctx, bin, args...)
+		cmd.Env = testCommandEnv(tmp)
+		cmd.Dir = tmp
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("aw %s failed: %v\n%s", strings.Join(args, " "), err, out)
+		}
+		return string(out)
+	}
+
+	if out := run("mail", "send", "--to", "bob", "--body", "hi", "--queue"); !strings.Contains(out, "queued mail to bob") {
+		t.Fatalf("send output=%q", out)
+	}
+	queued, err := filepath.Glob(filepath.Join(tmp, ".config", "aw", "mail-queue", "*.json"))
+	if err != nil || len(queued) != 1 {
+		t.Fatalf("queued files=%v err=%v", queued, err)
+	}
+
+	available.Store(true)
+	if out := run("mail", "flush"); !strings.Contains(out, "Sent queued mail to bob") {
+		t.Fatalf("flush output=%q", out)
+	}
+	if len(delivered) != 1 || delivered[0] == "" {
+		t.Fatalf("delivered=%v", delivered)
+	}
+	if out := run("mail", "flush"); !strings.Contains(out, "No queued mail.") {
+		t.Fatalf("second flush output=%q", out)
+	}
+}
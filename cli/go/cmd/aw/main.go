@@ -1,11 +1,8 @@
 package main
 
-// Set by goreleaser ldflags.
-var (
-	version = "dev"
-	commit  = "none"
-	date    = "unknown"
-)
+// Set by goreleaser ldflags. Commit and build date are injected into the
+// aweb package; see aweb.BuildInfo.
+var version = "dev"
 
 func main() {
 	Execute()
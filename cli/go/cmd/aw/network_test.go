@@ -115,6 +115,26 @@ func TestResolveClientSelectionEventStreamFallsBackFromStaleBaseURL(t *testing.T
 	}
 }
 
+func TestCleanBaseURLStripsAPIPrefix(t *testing.T) {
+	t.Parallel()
+
+	for raw, want := range map[string]string{
+		"http://localhost:8000/v1":      "http://localhost:8000",
+		"https://app.aweb.ai/api/v1/":   "https://app.aweb.ai/api",
+		"https://app.aweb.ai/api":       "https://app.aweb.ai/api",
+		"https://app.aweb.ai/v10":       "https://app.aweb.ai/v10",
+		"http://localhost:8000/?x=1#ab": "http://localhost:8000",
+	} {
+		got, err := cleanBaseURL(raw)
+		if err != nil {
+			t.Fatalf("cleanBaseURL(%q): %v", raw, err)
+		}
+		if got != want {
+			t.Fatalf("cleanBaseURL(%q)=%q, want %q", raw, got, want)
+		}
+	}
+}
+
 func TestResolveWorkingBaseURLContextHonorsCancellation(t *testing.T) {
 	t.Parallel()
 
@@ -46,6 +46,7 @@ type guidedOnboardingRequest struct {
 	Reachability       string
 	HumanName          string
 	AgentType          string
+	Metadata           map[string]any
 	Role               string
 	Persistent         bool
 	AskPostCreateSetup bool
@@ -105,7 +106,8 @@ func executeReconnectPath(req guidedOnboardingRequest) (*guidedOnboardingResult,
 	}
 
 	result, err := guidedOnboardingConnect(req.WorkingDir, serviceURLs.AwebURL, certificateConnectOptions{
-		Role: req.Role,
+		Role:     req.Role,
+		Metadata: req.Metadata,
 	})
 	if err != nil {
 		return nil, err
@@ -160,6 +162,7 @@ func executeHostedPath(req guidedOnboardingRequest) (*guidedOnboardingResult, er
 		Role:      req.Role,
 		HumanName: req.HumanName,
 		AgentType: req.AgentType,
+		Metadata:  req.Metadata,
 	})
 	if err != nil {
 		return nil, err
@@ -215,6 +218,7 @@ func executeBYODPath(req guidedOnboardingRequest) (*guidedOnboardingResult, erro
 		Role:      req.Role,
 		HumanName: req.HumanName,
 		AgentType: req.AgentType,
+		Metadata:  req.Metadata,
 	})
 	if err != nil {
 		return nil, err
@@ -300,7 +304,7 @@ func promptYesNoWithIO(label string, defaultYes bool, in io.Reader, out io.Write
 }
 
 func defaultWizardAwebURL() string {
-	if awebURL := strings.TrimSpace(os.Getenv("AWEB_URL")); awebURL != "" {
+	if awebURL := envAwebURL(); awebURL != "" {
 		return awebURL
 	}
 	return DefaultAwebURL
@@ -5,13 +5,48 @@ import (
 	"os"
 	"strings"
 
+	aweb "github.com/awebai/aw"
 	"github.com/spf13/cobra"
 )
 
 var serverFlag string
 var teamFlag string
+var asHumanFlag bool
 var debugFlag bool
+var noEnvFlag bool
 var jsonFlag bool
+var rawOutputFlag bool
+var configFlag string
+var outputFlag = outputFormat(outputText)
+
+const (
+	outputText       = "text"
+	outputJSON       = "json"
+	outputJSONStream = "json-stream"
+	outputCSV        = "csv"
+)
+
+// outputFormat is the value of --output. Both JSON formats also turn on
+// jsonFlag, so commands that only check jsonFlag print JSON for either. CSV
+// applies to list commands only; the rest print text under it.
+type outputFormat string
+
+func (o *outputFormat) String() string { return string(*o) }
+
+func (o *outputFormat) Set(v string) error {
+	switch v {
+	case outputText, outputJSON, outputJSONStream, outputCSV:
+	default:
+		return fmt.Errorf("want %s, %s, %s or %s", outputText, outputJSON, outputJSONStream, outputCSV)
+	}
+	*o = outputFormat(v)
+	if v == outputJSON || v == outputJSONStream {
+		jsonFlag = true
+	}
+	return nil
+}
+
+func (o *outputFormat) Type() string { return "format" }
 
 const (
 	groupWorkspace    = "workspace"
@@ -24,6 +59,12 @@ const (
 var rootCmd = &cobra.Command{
 	Use:   "aw",
 	Short: "aweb CLI",
+	Long: `aweb CLI
+
+Commands talk to the server named by AWEB_URL when it is set in the
+environment or in a .env file, and otherwise to aweb_url in
+.aw/workspace.yaml. Pass --no-env to ignore AWEB_URL, or --debug to log
+when it overrides the workspace.`,
 	PersistentPreRun: func(cmd *cobra.Command, args []string) {
 		if !debugFlag && os.Getenv("AW_DEBUG") == "1" {
 			debugFlag = true
@@ -41,17 +82,35 @@ var versionCmd = &cobra.Command{
 		// No-op: version command doesn't require command initialization side-effects.
 	},
 	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Printf("aw %s\n", version)
-		if commit != "none" {
-			fmt.Printf("  commit: %s\n", commit)
-		}
-		if date != "unknown" {
-			fmt.Printf("  built:  %s\n", date)
+		printOutput(versionInfo(), formatVersion)
+		if !jsonFlag {
+			checkLatestVersion(os.Stdout, "")
 		}
-		checkLatestVersion(os.Stdout, "")
 	},
 }
 
+// versionInfo is aweb.BuildInfo with the CLI release tag in place of the
+// library version; dev builds keep "dev".
+func versionInfo() aweb.Build {
+	info := aweb.BuildInfo()
+	info.Version = version
+	return info
+}
+
+func formatVersion(v any) string {
+	info := v.(aweb.Build)
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "aw %s\n", info.Version)
+	if info.Commit != "" {
+		fmt.Fprintf(&sb, "  commit: %s\n", info.Commit)
+	}
+	if info.Date != "" {
+		fmt.Fprintf(&sb, "  built:  %s\n", info.Date)
+	}
+	fmt.Fprintf(&sb, "  go:     %s %s/%s\n", info.GoVersion, info.OS, info.Arch)
+	return sb.String()
+}
+
 func init() {
 	rootCmd.AddGroup(
 		&cobra.Group{ID: groupWorkspace, Title: "Workspace Setup"},
@@ -72,6 +131,7 @@ func init() {
 	mailCmd.GroupID = groupNetwork
 	contactsCmd.GroupID = groupNetwork
 	directoryCmd.GroupID = groupNetwork
+	agentsCmd.GroupID = groupNetwork
 	heartbeatCmd.GroupID = groupNetwork
 	eventsCmd.GroupID = groupNetwork
 	controlCmd.GroupID = groupNetwork
@@ -93,7 +153,14 @@ func init() {
 
 	rootCmd.PersistentFlags().StringVar(&serverFlag, "server-name", "", "Override the server host or name for this command")
 	rootCmd.PersistentFlags().BoolVar(&debugFlag, "debug", false, "Log background errors to stderr")
+	rootCmd.PersistentFlags().BoolVarP(&quietFlag, "quiet", "q", false, "Suppress status messages on stderr")
+	rootCmd.PersistentFlags().CountVarP(&verboseLevel, "verbose", "v", "Log API requests and timings to stderr (-vv for more detail)")
+	rootCmd.MarkFlagsMutuallyExclusive("quiet", "verbose")
+	rootCmd.PersistentFlags().BoolVar(&noEnvFlag, "no-env", false, "Ignore AWEB_URL and use the server from .aw/workspace.yaml")
 	rootCmd.PersistentFlags().BoolVar(&jsonFlag, "json", false, "Output as JSON")
+	rootCmd.PersistentFlags().Var(&outputFlag, "output", "Output format: text, json (same as --json), json-stream (one compact JSON object per line) or csv (list commands)")
+	rootCmd.PersistentFlags().StringVar(&configFlag, "config", "", "Read and write this aw run config file instead of ~/.config/aw/run.json (overrides AW_CONFIG_PATH)")
+	rootCmd.PersistentFlags().BoolVar(&rawOutputFlag, "raw-output", false, "Print text output as received, without escaping terminal control characters")
 	bindTeamSelector(mailCmd)
 	bindTeamSelector(chatCmd)
 	bindTeamSelector(workCmd)
@@ -122,6 +189,7 @@ func bindTeamSelector(cmd *cobra.Command) {
 		return
 	}
 	cmd.PersistentFlags().StringVar(&teamFlag, "team", "", "Override the selected team_id for this command")
+	cmd.PersistentFlags().BoolVar(&asHumanFlag, "as-human", false, "Act through the human_account membership from .aw/context (--team takes precedence)")
 }
 
 func Execute() {
@@ -131,6 +199,8 @@ func Execute() {
 		msg := err.Error()
 		if hint := checkVerificationRequired(err); hint != "" {
 			msg = hint
+		} else if hint := serverUnreachableHint(err); hint != "" {
+			msg = hint
 		}
 		fmt.Fprintln(os.Stderr, msg)
 		os.Exit(exitCode(err))
@@ -153,6 +223,6 @@ func checkVersionFromHeader() {
 	}
 	latest = strings.TrimPrefix(latest, "v")
 	if compareVersions(current, latest) < 0 {
-		fmt.Fprintf(os.Stderr, "Upgrade available: v%s → v%s (run `aw upgrade`)\n", current, latest)
+		statusf("Upgrade available: v%s → v%s (run `aw upgrade`)", current, latest)
 	}
 }
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestAwNoEnvIgnoresAwebURL(t *testing.T) {
+	t.Parallel()
+
+	var lists atomic.Int32
+	server := newLocalHTTPServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.Path {
+		case "GET /v1/agents":
+			lists.Add(1)
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"team_id": "backend:demo",
+				"agents":  []map[string]any{{"agent_id": "agent-alice", "alias": "alice"}},
+			})
+		case "POST /v1/agents/heartbeat":
+			w.WriteHeader(http.StatusOK)
+		default:
+			t.Errorf("unexpected %s %s", r.Method, r.URL.Path)
+		}
+	}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	tmp := t.TempDir()
+	bin := filepath.Join(tmp, "aw")
+	buildAwBinary(t, ctx, bin)
+	writeDefaultWorkspaceBindingForTest(t, tmp, server.URL)
+
+	// Deleting yourself without --force is refused after listing agents, so
+	// the command reaches the server without changing anything.
+	run := exec.CommandContext(ctx, bin, "agents", "delete", "--agent-id", "agent-alice", "--no-env", "--debug")
+	run.Env = append(testCommandEnv(tmp), "AWEB_URL=http://127.0.0.1:1")
+	run.Dir = tmp
+	out, _ := run.CombinedOutput()
+	if !strings.Contains(string(out), "--force") {
+		t.Fatalf("output=%s", string(out))
+	}
+	if strings.Contains(string(out), "overrides aweb_url") {
+		t.Fatalf("--no-env still applied AWEB_URL:\n%s", string(out))
+	}
+	if lists.Load() != 1 {
+		t.Fatalf("workspace server saw %d agent lists, want 1", lists.Load())
+	}
+}
@@ -112,12 +112,12 @@ func runRun(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	runCfg, err := runLoadUserConfig(workingDir)
+	runCfg, err := runLoadUserConfig(workingDir, configFlag)
 	if err != nil {
 		return err
 	}
 	if runInitConfig {
-		return runInitUserConfig(cmd.InOrStdin(), cmd.OutOrStdout(), runCfg)
+		return runInitUserConfig(cmd.InOrStdin(), cmd.OutOrStdout(), runCfg, configFlag)
 	}
 
 	settings, err := runResolveSettings(runCfg, awrun.SettingOverrides{
@@ -39,12 +39,18 @@ func TestRunInitUsesRunConfigWorkflow(t *testing.T) {
 	})
 
 	runGetwd = func() (string, error) { return "/tmp/work", nil }
-	runLoadUserConfig = func(dir string) (awrun.UserConfig, error) {
+	oldConfig := configFlag
+	configFlag = "/tmp/alt-run.json"
+	t.Cleanup(func() { configFlag = oldConfig })
+	var loadedConfig, writtenConfig string
+	runLoadUserConfig = func(dir, configPath string) (awrun.UserConfig, error) {
 		loadedDir = dir
+		loadedConfig = configPath
 		return awrun.UserConfig{}, nil
 	}
-	runInitUserConfig = func(in io.Reader, out io.Writer, existing awrun.UserConfig) error {
+	runInitUserConfig = func(in io.Reader, out io.Writer, existing awrun.UserConfig, configPath string) error {
 		initCalled = true
+		writtenConfig = configPath
 		return nil
 	}
 	runResolveClientForDir = func(string) (*aweb.Client, *awconfig.Selection, error) {
@@ -68,6 +74,9 @@ func TestRunInitUsesRunConfigWorkflow(t *testing.T) {
 	if !initCalled {
 		t.Fatal("expected run init workflow to execute")
 	}
+	if loadedConfig != configFlag || writtenConfig != configFlag {
+		t.Fatalf("--config not passed through: load=%q init=%q", loadedConfig, writtenConfig)
+	}
 }
 
 func TestRunBuildsLoopOptionsFromConfigAndFlags(t *testing.T) {
@@ -97,7 +106,7 @@ func TestRunBuildsLoopOptionsFromConfigAndFlags(t *testing.T) {
 		initRunCommandVars()
 	})
 
-	runLoadUserConfig = func(dir string) (awrun.UserConfig, error) {
+	runLoadUserConfig = func(dir, _ string) (awrun.UserConfig, error) {
 		if !strings.HasSuffix(dir, "testdata") {
 			t.Fatalf("expected absolute testdata dir, got %q", dir)
 		}
@@ -237,7 +246,7 @@ func TestRunRequiresPromptWithoutConfiguredBasePrompt(t *testing.T) {
 		initRunCommandVars()
 	})
 
-	runLoadUserConfig = func(dir string) (awrun.UserConfig, error) { return awrun.UserConfig{}, nil }
+	runLoadUserConfig = func(dir, _ string) (awrun.UserConfig, error) { return awrun.UserConfig{}, nil }
 	runResolveSettings = func(cfg awrun.UserConfig, overrides awrun.SettingOverrides) (awrun.Settings, error) {
 		return awrun.Settings{}, nil
 	}
@@ -312,7 +321,7 @@ func TestRunAllowsEmptyPromptWhenInteractiveScreenIsAvailable(t *testing.T) {
 		initRunCommandVars()
 	})
 
-	runLoadUserConfig = func(dir string) (awrun.UserConfig, error) { return awrun.UserConfig{}, nil }
+	runLoadUserConfig = func(dir, _ string) (awrun.UserConfig, error) { return awrun.UserConfig{}, nil }
 	runResolveSettings = func(cfg awrun.UserConfig, overrides awrun.SettingOverrides) (awrun.Settings, error) {
 		return awrun.Settings{}, nil
 	}
@@ -379,7 +388,7 @@ func TestRunDefaultsCodexToNonPTYWhenInteractive(t *testing.T) {
 		initRunCommandVars()
 	})
 
-	runLoadUserConfig = func(dir string) (awrun.UserConfig, error) { return awrun.UserConfig{}, nil }
+	runLoadUserConfig = func(dir, _ string) (awrun.UserConfig, error) { return awrun.UserConfig{}, nil }
 	runResolveSettings = func(cfg awrun.UserConfig, overrides awrun.SettingOverrides) (awrun.Settings, error) {
 		return awrun.Settings{}, nil
 	}
@@ -443,7 +452,7 @@ func TestRunHonorsExplicitCodexPTYOverride(t *testing.T) {
 		initRunCommandVars()
 	})
 
-	runLoadUserConfig = func(dir string) (awrun.UserConfig, error) { return awrun.UserConfig{}, nil }
+	runLoadUserConfig = func(dir, _ string) (awrun.UserConfig, error) { return awrun.UserConfig{}, nil }
 	runResolveSettings = func(cfg awrun.UserConfig, overrides awrun.SettingOverrides) (awrun.Settings, error) {
 		return awrun.Settings{}, nil
 	}
@@ -502,7 +511,7 @@ func TestRunNonInteractiveMissingContextPrintsOnboardingHint(t *testing.T) {
 		initRunCommandVars()
 	})
 
-	runLoadUserConfig = func(dir string) (awrun.UserConfig, error) { return awrun.UserConfig{}, nil }
+	runLoadUserConfig = func(dir, _ string) (awrun.UserConfig, error) { return awrun.UserConfig{}, nil }
 	runResolveSettings = func(cfg awrun.UserConfig, overrides awrun.SettingOverrides) (awrun.Settings, error) {
 		return awrun.Settings{BasePrompt: "mission"}, nil
 	}
@@ -551,7 +560,7 @@ func TestRunInteractiveOnboardsBeforeRunning(t *testing.T) {
 		initRunCommandVars()
 	})
 
-	runLoadUserConfig = func(dir string) (awrun.UserConfig, error) { return awrun.UserConfig{}, nil }
+	runLoadUserConfig = func(dir, _ string) (awrun.UserConfig, error) { return awrun.UserConfig{}, nil }
 	runResolveSettings = func(cfg awrun.UserConfig, overrides awrun.SettingOverrides) (awrun.Settings, error) {
 		return awrun.Settings{BasePrompt: "mission"}, nil
 	}
@@ -920,7 +929,7 @@ func TestRunUsesWakeEventToTriggerSecondCycle(t *testing.T) {
 		t.Fatalf("new client: %v", err)
 	}
 
-	runLoadUserConfig = func(string) (awrun.UserConfig, error) { return awrun.UserConfig{}, nil }
+	runLoadUserConfig = func(string, string) (awrun.UserConfig, error) { return awrun.UserConfig{}, nil }
 	runResolveSettings = func(cfg awrun.UserConfig, overrides awrun.SettingOverrides) (awrun.Settings, error) {
 		return awrun.Settings{
 			BasePrompt:      "persistent mission",
@@ -1037,7 +1046,7 @@ func TestRunUsesActionableWakeEventToTriggerSecondCycle(t *testing.T) {
 		t.Fatalf("new client: %v", err)
 	}
 
-	runLoadUserConfig = func(string) (awrun.UserConfig, error) { return awrun.UserConfig{}, nil }
+	runLoadUserConfig = func(string, string) (awrun.UserConfig, error) { return awrun.UserConfig{}, nil }
 	runResolveSettings = func(cfg awrun.UserConfig, overrides awrun.SettingOverrides) (awrun.Settings, error) {
 		return awrun.Settings{
 			BasePrompt:      "persistent mission",
@@ -1127,7 +1136,7 @@ func TestRunContinuePrintsRecentInteractionRecap(t *testing.T) {
 		Text:      "I can add a compact recap without touching provider history.",
 	})
 
-	runLoadUserConfig = func(dir string) (awrun.UserConfig, error) { return awrun.UserConfig{}, nil }
+	runLoadUserConfig = func(dir, _ string) (awrun.UserConfig, error) { return awrun.UserConfig{}, nil }
 	runResolveSettings = func(cfg awrun.UserConfig, overrides awrun.SettingOverrides) (awrun.Settings, error) {
 		return awrun.Settings{BasePrompt: "persistent mission", WaitSeconds: 5, IdleWaitSeconds: 5}, nil
 	}
@@ -1246,7 +1255,7 @@ func TestRunPrintsContinueAndProviderCommandsOnExit(t *testing.T) {
 
 	tmp := t.TempDir()
 	runWorkingDir = tmp
-	runLoadUserConfig = func(string) (awrun.UserConfig, error) { return awrun.UserConfig{}, nil }
+	runLoadUserConfig = func(string, string) (awrun.UserConfig, error) { return awrun.UserConfig{}, nil }
 	runResolveSettings = func(cfg awrun.UserConfig, overrides awrun.SettingOverrides) (awrun.Settings, error) {
 		return awrun.Settings{BasePrompt: "mission", WaitSeconds: 5, IdleWaitSeconds: 5}, nil
 	}
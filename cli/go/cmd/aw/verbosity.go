@@ -0,0 +1,61 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// Set by -q/--quiet and -v/--verbose. -v logs each API request with its
+// status and latency; -vv also logs request starts, chat stream events and
+// --debug output.
+var (
+	quietFlag    bool
+	verboseLevel int
+)
+
+// statusf prints progress chatter to stderr unless --quiet is set. Warnings
+// and errors go to stderr directly and are never suppressed.
+func statusf(format string, args ...any) {
+	if quietFlag {
+		return
+	}
+	fmt.Fprintf(os.Stderr, format+"\n", args...)
+}
+
+// verbosef prints to stderr when -v was given at least level times.
+func verbosef(level int, format string, args ...any) {
+	if quietFlag || verboseLevel < level {
+		return
+	}
+	fmt.Fprintf(os.Stderr, format+"\n", args...)
+}
+
+// verboseObserver is the awid.Observer the CLI installs under -v. A request
+// the client retries, such as after refreshing an expired token, shows up
+// as one line per attempt.
+type verboseObserver struct {
+	baseURL string
+}
+
+func newVerboseObserver(baseURL string) *verboseObserver {
+	return &verboseObserver{baseURL: strings.TrimRight(baseURL, "/")}
+}
+
+func (o *verboseObserver) RequestStarted(method, path string) {
+	verbosef(2, "[http] %s %s%s", method, o.baseURL, path)
+}
+
+func (o *verboseObserver) RequestFinished(method, path string, status int, latency time.Duration) {
+	latency = latency.Round(time.Millisecond)
+	if status == 0 {
+		verbosef(1, "[http] %s %s%s failed after %s", method, o.baseURL, path, latency)
+		return
+	}
+	verbosef(1, "[http] %s %s%s -> %d (%s)", method, o.baseURL, path, status, latency)
+}
+
+func (o *verboseObserver) StreamEvent(sessionID, kind string) {
+	verbosef(2, "[stream] %s %s", sessionID, kind)
+}
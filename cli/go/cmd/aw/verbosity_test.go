@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAwVerboseLogsRequestsAndQuietExcludesIt(t *testing.T) {
+	t.Parallel()
+
+	server := newLocalHTTPServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/reservations":
+			_ = json.NewEncoder(w).Encode(map[string]any{"reservations": []any{}, "has_more": false})
+		case "/v1/agents/heartbeat":
+			w.WriteHeader(http.StatusOK)
+		default:
+			t.Errorf("unexpected %s %s", r.Method, r.URL.Path)
+		}
+	}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	tmp := t.TempDir()
+	bin := filepath.Join(tmp, "aw")
+	buildAwBinary(t, ctx, bin)
+	writeWorkspaceBindingForTest(t, tmp, workspaceBinding(server.URL, "backend:demo", "alice", "workspace-1"))
+
+	run := exec.CommandContext(ctx, bin, "lock", "list", "-v")
+	run.Env = testCommandEnv(tmp)
+	run.Dir = tmp
+	var stderr strings.Builder
+	run.Stderr = &stderr
+	if out, err := run.Output(); err != nil {
+		t.Fatalf("run failed: %v\n%s%s", err, string(out), stderr.String())
+	}
+	if !strings.Contains(stderr.String(), "[http] GET "+server.URL+"/v1/reservations -> 200 (") {
+		t.Fatalf("expected a request log line, stderr:\n%s", stderr.String())
+	}
+
+	run = exec.CommandContext(ctx, bin, "lock", "list", "-q", "-v")
+	run.Env = testCommandEnv(tmp)
+	run.Dir = tmp
+	out, err := run.CombinedOutput()
+	if err == nil {
+		t.Fatalf("expected -q with -v to fail, got:\n%s", string(out))
+	}
+	if !strings.Contains(string(out), "[quiet verbose]") {
+		t.Fatalf("unexpected error output:\n%s", string(out))
+	}
+}
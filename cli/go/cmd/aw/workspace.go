@@ -140,7 +140,7 @@ func runWorkspaceStatus(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	locksResp, err := client.ReservationList(ctx, "")
+	locks, err := client.ReservationListAll(ctx, "")
 	if err != nil {
 		return err
 	}
@@ -151,7 +151,7 @@ func runWorkspaceStatus(cmd *cobra.Command, args []string) error {
 	}
 
 	locksByWorkspace := map[string][]aweb.ReservationView{}
-	for _, reservation := range locksResp.Reservations {
+	for _, reservation := range locks {
 		holder := strings.TrimSpace(reservation.HolderAgentID)
 		if holder == "" {
 			continue
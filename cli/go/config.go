@@ -0,0 +1,107 @@
+package aweb
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/awebai/aw/awconfig"
+	"github.com/awebai/aw/awid"
+)
+
+// NewFromEnv returns a client configured from the environment. When
+// AWEB_API_KEY is set it needs nothing on disk: the client talks to AWEB_URL
+// and authenticates with the API key as a bearer token. Otherwise it uses the
+// aw workspace bound to the current directory, signing requests with the
+// worktree key and team certificate, and AWEB_URL overrides the workspace
+// base URL the same way the aw CLI does. TLS verification is never turned
+// off here; callers that want that opt in with SetInsecureSkipVerify.
+func NewFromEnv() (*Client, error) {
+	baseURL := strings.TrimSpace(os.Getenv("AWEB_URL"))
+	if baseURL != "" {
+		if err := awconfig.ValidateBaseURL(baseURL); err != nil {
+			return nil, fmt.Errorf("invalid AWEB_URL: %w", err)
+		}
+	}
+	apiKey := strings.TrimSpace(os.Getenv("AWEB_API_KEY"))
+	if apiKey == "" {
+		return newFromResolveOptions(awconfig.ResolveOptions{AllowEnvOverrides: true})
+	}
+	if baseURL == "" {
+		return nil, fmt.Errorf("AWEB_API_KEY is set but AWEB_URL is not")
+	}
+	c, err := New(baseURL)
+	if err != nil {
+		return nil, err
+	}
+	c.SetTokenSource(awid.StaticToken(apiKey))
+	return c, nil
+}
+
+// NewFromConfig returns a client for the aw workspace bound to workingDir
+// (the current directory when empty), ignoring environment overrides. It
+// needs the workspace's .aw directory with its signing key and team
+// certificate. Team memberships take the place of per-server accounts:
+// teamID selects a membership other than the active team.
+func NewFromConfig(workingDir, teamID string) (*Client, error) {
+	return newFromResolveOptions(awconfig.ResolveOptions{
+		WorkingDir:     strings.TrimSpace(workingDir),
+		TeamIDOverride: strings.TrimSpace(teamID),
+	})
+}
+
+func newFromResolveOptions(opts awconfig.ResolveOptions) (*Client, error) {
+	sel, err := awconfig.ResolveWorkspace(opts)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(sel.TeamID) == "" {
+		return nil, fmt.Errorf("%s has no team membership to authenticate with", sel.WorkingDir)
+	}
+	cert, err := awconfig.LoadTeamCertificateForTeam(sel.WorkingDir, sel.TeamID)
+	if err != nil {
+		return nil, fmt.Errorf("load team certificate for %s: %w", sel.TeamID, err)
+	}
+	signingKey, err := awid.LoadSigningKey(awconfig.WorktreeSigningKeyPath(sel.WorkingDir))
+	if err != nil {
+		return nil, fmt.Errorf("load signing key: %w", err)
+	}
+	c, err := NewWithCertificate(sel.BaseURL, signingKey, cert)
+	if err != nil {
+		return nil, err
+	}
+	if v := strings.TrimSpace(sel.Address); v != "" {
+		c.SetAddress(v)
+	}
+	if v := strings.TrimSpace(sel.StableID); v != "" {
+		c.SetStableID(v)
+	}
+	c.SetReservationPrefix(sel.ReservationPrefix)
+	if err := c.ConfigureTLS(sel); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// ConfigureTLS makes c trust the ca_cert named in the selected
+// workspace.yaml, for servers behind a private certificate authority.
+func (c *Client) ConfigureTLS(sel *awconfig.Selection) error {
+	path := strings.TrimSpace(sel.CACert)
+	if path == "" {
+		return nil
+	}
+	pool, err := awid.LoadRootCAs(path)
+	if err != nil {
+		return fmt.Errorf("load ca_cert from %s: %w", awconfig.DefaultWorktreeWorkspaceRelativePath(), err)
+	}
+	c.SetRootCAs(pool)
+	return nil
+}
+
+// TLSInsecureEnv reports whether AWEB_TLS_INSECURE opts out of TLS
+// certificate verification. Only the exact value 1 counts, so a stray
+// "false" or "0" never disables verification. The library constructors do
+// not consult it; the aw CLI does, and warns on stderr when it is set.
+func TLSInsecureEnv() bool {
+	return os.Getenv("AWEB_TLS_INSECURE") == "1"
+}
@@ -0,0 +1,177 @@
+package aweb
+
+import (
+	"context"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/awebai/aw/awconfig"
+	"github.com/awebai/aw/awid"
+)
+
+func writeWorkspaceForConfigTest(t *testing.T, root, baseURL string) {
+	t.Helper()
+
+	_, teamKey, err := awid.GenerateKeypair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub, signingKey, err := awid.GenerateKeypair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	const teamID = "backend:acme.com"
+	cert, err := awid.SignTeamCertificate(teamKey, awid.TeamCertificateFields{
+		Team:         teamID,
+		MemberDIDKey: awid.ComputeDIDKey(pub),
+		Alias:        "alice",
+		Lifetime:     awid.LifetimeEphemeral,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := awconfig.SaveTeamCertificateForTeam(root, teamID, cert); err != nil {
+		t.Fatal(err)
+	}
+	if err := awid.SaveSigningKey(awconfig.WorktreeSigningKeyPath(root), signingKey); err != nil {
+		t.Fatal(err)
+	}
+	if err := awconfig.SaveWorktreeWorkspaceTo(filepath.Join(root, ".aw", "workspace.yaml"), &awconfig.WorktreeWorkspace{
+		AwebURL: baseURL,
+		Memberships: []awconfig.WorktreeMembership{{
+			TeamID:      teamID,
+			Alias:       "alice",
+			WorkspaceID: "workspace-1",
+			CertPath:    awconfig.TeamCertificateRelativePath(teamID),
+		}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := awconfig.SaveTeamState(root, &awconfig.TeamState{
+		ActiveTeam: teamID,
+		Memberships: []awconfig.TeamMembership{{
+			TeamID:   teamID,
+			Alias:    "alice",
+			CertPath: awconfig.TeamCertificateRelativePath(teamID),
+		}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestNewFromConfigAuthenticatesWithWorkspaceCertificate(t *testing.T) {
+	t.Parallel()
+
+	var gotCert string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotCert = r.Header.Get("X-AWID-Team-Certificate")
+		_, _ = w.Write([]byte(`{"reservations":[]}`))
+	}))
+	t.Cleanup(server.Close)
+
+	tmp := t.TempDir()
+	writeWorkspaceForConfigTest(t, tmp, server.URL)
+
+	c, err := NewFromConfig(tmp, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.ReservationList(context.Background(), ReservationListParams{}); err != nil {
+		t.Fatal(err)
+	}
+	if strings.TrimSpace(gotCert) == "" {
+		t.Fatal("expected team certificate header")
+	}
+}
+
+func TestNewFromConfigTrustsWorkspaceCACert(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"reservations":[]}`))
+	}))
+	t.Cleanup(server.Close)
+
+	tmp := t.TempDir()
+	writeWorkspaceForConfigTest(t, tmp, server.URL)
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(filepath.Join(tmp, "ca.pem"), certPEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	wsPath := filepath.Join(tmp, ".aw", "workspace.yaml")
+	ws, err := awconfig.LoadWorktreeWorkspaceFrom(wsPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ws.CACert = "ca.pem"
+	if err := awconfig.SaveWorktreeWorkspaceTo(wsPath, ws); err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := NewFromConfig(tmp, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.ReservationList(context.Background(), ReservationListParams{}); err != nil {
+		t.Fatalf("with ca_cert: %v", err)
+	}
+}
+
+func TestNewFromConfigRejectsUnknownTeam(t *testing.T) {
+	t.Parallel()
+
+	tmp := t.TempDir()
+	writeWorkspaceForConfigTest(t, tmp, "https://app.aweb.ai")
+
+	_, err := NewFromConfig(tmp, "ops:acme.com")
+	if err == nil || !strings.Contains(err.Error(), `team "ops:acme.com" is not present`) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestNewFromEnvRejectsInvalidURL(t *testing.T) {
+	t.Setenv("AWEB_URL", "not a url")
+
+	_, err := NewFromEnv()
+	if err == nil || !strings.Contains(err.Error(), "invalid AWEB_URL") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestNewFromEnvWithAPIKeyNeedsNoWorkspace(t *testing.T) {
+	var gotAuth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		_, _ = w.Write([]byte(`{"reservations":[]}`))
+	}))
+	t.Cleanup(server.Close)
+	t.Chdir(t.TempDir())
+	t.Setenv("AWEB_URL", server.URL)
+	t.Setenv("AWEB_API_KEY", "aw_sk_test")
+
+	c, err := NewFromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.ReservationList(context.Background(), ReservationListParams{}); err != nil {
+		t.Fatal(err)
+	}
+	if gotAuth != "Bearer aw_sk_test" {
+		t.Fatalf("Authorization=%q", gotAuth)
+	}
+}
+
+func TestNewFromEnvWithAPIKeyRequiresURL(t *testing.T) {
+	t.Setenv("AWEB_URL", "")
+	t.Setenv("AWEB_API_KEY", "aw_sk_test")
+
+	_, err := NewFromEnv()
+	if err == nil || !strings.Contains(err.Error(), "AWEB_URL is not") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
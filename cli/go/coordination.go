@@ -80,7 +80,7 @@ type CoordinationStatusResponse struct {
 }
 
 func (c *Client) CoordinationStatus(ctx context.Context, workspaceID string) (*CoordinationStatusResponse, error) {
-	path := "/v1/status"
+	path := c.APIPath("/status")
 	if workspaceID != "" {
 		path += "?workspace_id=" + urlQueryEscape(workspaceID)
 	}
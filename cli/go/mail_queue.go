@@ -0,0 +1,222 @@
+package aweb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/awebai/aw/awconfig"
+	"github.com/awebai/aw/awid"
+)
+
+// ErrMailQueueDisabled is returned by FlushQueue when no spool directory is
+// set.
+var ErrMailQueueDisabled = errors.New("aweb: mail queue is not enabled")
+
+const (
+	mailQueueLockName     = ".lock"
+	mailQueueEntrySuffix  = ".json"
+	mailQueueFailedSuffix = ".failed"
+)
+
+// QueuedMessage is a spooled send. Key is the message_id the send is
+// retried with, so a flush after a send that did land cannot duplicate it.
+type QueuedMessage struct {
+	Key            string                  `json:"key"`
+	FromDID        string                  `json:"from_did,omitempty"`
+	IdentityTarget bool                    `json:"identity_target,omitempty"`
+	QueuedAt       string                  `json:"queued_at"`
+	Request        awid.SendMessageRequest `json:"request"`
+}
+
+// QueuedSendResult reports one spooled message handled by FlushQueue.
+type QueuedSendResult struct {
+	Message  QueuedMessage             `json:"message"`
+	Response *awid.SendMessageResponse `json:"response,omitempty"`
+	Error    string                    `json:"error,omitempty"`
+}
+
+// FlushResult summarizes a FlushQueue run. Failed messages were rejected by
+// the server and set aside with a .failed suffix; Remaining counts messages
+// still queued because the server is unreachable or they belong to another
+// identity.
+type FlushResult struct {
+	Sent      []QueuedSendResult `json:"sent"`
+	Failed    []QueuedSendResult `json:"failed"`
+	Remaining int                `json:"remaining"`
+}
+
+// SetMailQueueDir enables the offline mail spool used by SendMessageQueued
+// and FlushQueue. An empty dir disables it.
+func (c *Client) SetMailQueueDir(dir string) {
+	c.mailQueueDir = strings.TrimSpace(dir)
+}
+
+// MailQueueDir returns the spool directory, or "" when the queue is disabled.
+func (c *Client) MailQueueDir() string {
+	return c.mailQueueDir
+}
+
+// SendMessageQueued sends like SendMessage (or SendMessageByIdentity when
+// byIdentity is set), but when the server cannot be reached the request is
+// spooled for FlushQueue instead of failing. queued reports whether that
+// happened; resp is nil in that case. Errors the server returned are never
+// queued.
+func (c *Client) SendMessageQueued(ctx context.Context, req *awid.SendMessageRequest, byIdentity bool) (resp *awid.SendMessageResponse, queued bool, err error) {
+	if req == nil {
+		return nil, false, errors.New("aweb: request is required")
+	}
+	payload := *req
+	if !byIdentity && payload.ToAlias != "" {
+		if err := ValidateAlias(payload.ToAlias); err != nil {
+			return nil, false, err
+		}
+	}
+	if strings.TrimSpace(payload.MessageID) == "" {
+		if payload.MessageID, err = awid.GenerateUUID4(); err != nil {
+			return nil, false, err
+		}
+	}
+	resp, err = c.sendQueuedRequest(ctx, &payload, byIdentity)
+	if err == nil || c.mailQueueDir == "" || !isUnreachableError(err) {
+		return resp, false, err
+	}
+	entry := QueuedMessage{
+		Key:            payload.MessageID,
+		FromDID:        c.DID(),
+		IdentityTarget: byIdentity,
+		QueuedAt:       time.Now().UTC().Format(time.RFC3339),
+		Request:        payload,
+	}
+	if qerr := c.enqueueMessage(&entry); qerr != nil {
+		return nil, false, fmt.Errorf("%w (queueing failed: %v)", err, qerr)
+	}
+	return nil, true, nil
+}
+
+// FlushQueue retries spooled sends in the order they were queued. It stops
+// at the first message the server still cannot be reached for, so later
+// messages never overtake it. Messages spooled by another identity are left
+// for that identity to flush.
+func (c *Client) FlushQueue(ctx context.Context) (*FlushResult, error) {
+	if c.mailQueueDir == "" {
+		return nil, ErrMailQueueDisabled
+	}
+	lock, err := awconfig.LockExclusive(filepath.Join(c.mailQueueDir, mailQueueLockName))
+	if err != nil {
+		return nil, err
+	}
+	defer lock.Close()
+
+	names, err := queuedMessageNames(c.mailQueueDir)
+	if err != nil {
+		return nil, err
+	}
+	result := &FlushResult{Sent: []QueuedSendResult{}, Failed: []QueuedSendResult{}}
+	for i, name := range names {
+		path := filepath.Join(c.mailQueueDir, name)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return result, err
+		}
+		var entry QueuedMessage
+		if err := json.Unmarshal(data, &entry); err != nil {
+			return result, fmt.Errorf("aweb: reading queued message %s: %w", name, err)
+		}
+		if entry.FromDID != c.DID() {
+			result.Remaining++
+			continue
+		}
+		req := entry.Request
+		req.MessageID = entry.Key
+		resp, err := c.sendQueuedRequest(ctx, &req, entry.IdentityTarget)
+		if err != nil {
+			if isUnreachableError(err) {
+				result.Remaining += len(names) - i
+				return result, err
+			}
+			result.Failed = append(result.Failed, QueuedSendResult{Message: entry, Error: err.Error()})
+			if err := os.Rename(path, path+mailQueueFailedSuffix); err != nil {
+				return result, err
+			}
+			continue
+		}
+		result.Sent = append(result.Sent, QueuedSendResult{Message: entry, Response: resp})
+		if err := os.Remove(path); err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+func (c *Client) sendQueuedRequest(ctx context.Context, req *awid.SendMessageRequest, byIdentity bool) (*awid.SendMessageResponse, error) {
+	if byIdentity {
+		return c.SendMessageByIdentity(ctx, req)
+	}
+	return c.SendMessage(ctx, req)
+}
+
+func (c *Client) enqueueMessage(entry *QueuedMessage) error {
+	lock, err := awconfig.LockExclusive(filepath.Join(c.mailQueueDir, mailQueueLockName))
+	if err != nil {
+		return err
+	}
+	defer lock.Close()
+
+	data, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		return err
+	}
+	// Names sort in queue order: a fixed-width timestamp, then the key.
+	name := fmt.Sprintf("%020d-%s%s", time.Now().UnixNano(), entry.Key, mailQueueEntrySuffix)
+	path := filepath.Join(c.mailQueueDir, name)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func queuedMessageNames(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, e := range entries {
+		if e.Type().IsRegular() && strings.HasSuffix(e.Name(), mailQueueEntrySuffix) {
+			names = append(names, e.Name())
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// isUnreachableError reports whether err means the request may not have
+// reached the server: a transport failure, a timeout, or a gateway error.
+// Cancellation by the caller is not.
+func isUnreachableError(err error) bool {
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+	if code, ok := awid.HTTPStatusCode(err); ok {
+		return code == 502 || code == 503 || code == 504
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+	var urlErr *url.Error
+	return errors.As(err, &urlErr)
+}
@@ -0,0 +1,132 @@
+package aweb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/awebai/aw/awid"
+)
+
+func TestSendMessageQueuedSpoolsWhenUnreachableAndFlushesInOrder(t *testing.T) {
+	t.Parallel()
+
+	queueDir := t.TempDir()
+	down := httptest.NewServer(http.NotFoundHandler())
+	downURL := down.URL
+	down.Close()
+
+	offline, err := New(downURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	offline.SetMailQueueDir(queueDir)
+	for _, body := range []string{"first", "second"} {
+		resp, queued, err := offline.SendMessageQueued(context.Background(), &awid.SendMessageRequest{ToAlias: "bob", Body: body}, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !queued || resp != nil {
+			t.Fatalf("queued=%v resp=%+v, want queued", queued, resp)
+		}
+	}
+
+	var bodies, ids []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req awid.SendMessageRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatal(err)
+		}
+		bodies = append(bodies, req.Body)
+		ids = append(ids, req.MessageID)
+		_ = json.NewEncoder(w).Encode(awid.SendMessageResponse{MessageID: req.MessageID, Status: "delivered"})
+	}))
+	t.Cleanup(server.Close)
+
+	online, err := New(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	online.SetMailQueueDir(queueDir)
+	result, err := online.FlushQueue(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(bodies, ",") != "first,second" {
+		t.Fatalf("flushed bodies=%v, want queue order", bodies)
+	}
+	if len(result.Sent) != 2 || result.Remaining != 0 {
+		t.Fatalf("result=%+v", result)
+	}
+	for i, sent := range result.Sent {
+		if ids[i] == "" || ids[i] != sent.Message.Key {
+			t.Fatalf("message_id=%q, want the queued key %q", ids[i], sent.Message.Key)
+		}
+	}
+	names, err := queuedMessageNames(queueDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(names) != 0 {
+		t.Fatalf("queue not drained: %v", names)
+	}
+}
+
+func TestFlushQueueSetsAsideRejectedMessages(t *testing.T) {
+	t.Parallel()
+
+	queueDir := t.TempDir()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req awid.SendMessageRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		if req.ToAlias == "ghost" {
+			http.Error(w, `{"detail":"Recipient agent not found"}`, http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(awid.SendMessageResponse{MessageID: req.MessageID, Status: "delivered"})
+	}))
+	t.Cleanup(server.Close)
+
+	c, err := New(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.SetMailQueueDir(queueDir)
+	for _, to := range []string{"ghost", "bob"} {
+		if err := c.enqueueMessage(&QueuedMessage{Key: "key-" + to, Request: awid.SendMessageRequest{ToAlias: to, Body: "hi"}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	result, err := c.FlushQueue(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Failed) != 1 || result.Failed[0].Message.Key != "key-ghost" {
+		t.Fatalf("failed=%+v", result.Failed)
+	}
+	if len(result.Sent) != 1 || result.Sent[0].Message.Key != "key-bob" {
+		t.Fatalf("sent=%+v", result.Sent)
+	}
+	matches, err := filepath.Glob(filepath.Join(queueDir, "*"+mailQueueEntrySuffix+mailQueueFailedSuffix))
+	if err != nil || len(matches) != 1 {
+		t.Fatalf("set-aside files=%v err=%v", matches, err)
+	}
+}
+
+func TestFlushQueueRequiresQueueDir(t *testing.T) {
+	t.Parallel()
+
+	c, err := New("http://127.0.0.1:1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.FlushQueue(context.Background()); !errors.Is(err, ErrMailQueueDisabled) {
+		t.Fatalf("err=%v, want ErrMailQueueDisabled", err)
+	}
+}
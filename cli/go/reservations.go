@@ -3,25 +3,60 @@ package aweb
 import (
 	"context"
 	"encoding/json"
-	"io"
+	"errors"
+	"fmt"
 	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/awebai/aw/awid"
 )
 
+// ReservationAcquireRequest acquires ResourceKey. StealExpired asks the
+// server to take over the lock from another holder whose lease has already
+// expired; a lock that is still live is never taken, flag or not, and comes
+// back as a *ReservationHeldError.
 type ReservationAcquireRequest struct {
-	ResourceKey string         `json:"resource_key"`
-	TTLSeconds  int            `json:"ttl_seconds,omitempty"`
-	Metadata    map[string]any `json:"metadata,omitempty"`
+	ResourceKey  string         `json:"resource_key"`
+	TTLSeconds   int            `json:"ttl_seconds,omitempty"`
+	Metadata     map[string]any `json:"metadata,omitempty"`
+	StealExpired bool           `json:"steal_expired,omitempty"`
 }
 
+// ReservationAcquireResponse reports the acquired lock. When StealExpired
+// was set and the lock was taken over from another holder's expired lease,
+// Status is "stolen" and the StolenFrom fields name the previous holder.
+// Without StealExpired such a takeover is reported as a plain "acquired".
+//
+// FencingToken strictly increases each time the lock passes to a new
+// holder and stays the same while the caller keeps it. Pass it with writes
+// to the protected resource, which should reject any token lower than the
+// highest it has seen, so a holder that stalled past its lease cannot
+// overwrite the next holder's work. Older servers leave it zero.
 type ReservationAcquireResponse struct {
-	Status        string `json:"status"`
-	ResourceKey   string `json:"resource_key"`
-	HolderAgentID string `json:"holder_agent_id,omitempty"`
-	HolderAlias   string `json:"holder_alias,omitempty"`
-	AcquiredAt    string `json:"acquired_at,omitempty"`
-	ExpiresAt     string `json:"expires_at,omitempty"`
+	Status              string `json:"status"`
+	ResourceKey         string `json:"resource_key"`
+	HolderAgentID       string `json:"holder_agent_id,omitempty"`
+	HolderAlias         string `json:"holder_alias,omitempty"`
+	AcquiredAt          string `json:"acquired_at,omitempty"`
+	ExpiresAt           string `json:"expires_at,omitempty"`
+	StolenFromAgentID   string `json:"stolen_from_agent_id,omitempty"`
+	StolenFromAlias     string `json:"stolen_from_alias,omitempty"`
+	StolenFromExpiresAt string `json:"stolen_from_expires_at,omitempty"`
+	FencingToken        int64  `json:"fencing_token,omitempty"`
+}
+
+// Stolen reports whether the acquire took over another holder's expired lock.
+func (r ReservationAcquireResponse) Stolen() bool {
+	return r.StolenFromAgentID != "" || r.StolenFromAlias != ""
+}
+
+// ExpiresTime parses ExpiresAt.
+func (r ReservationAcquireResponse) ExpiresTime() (time.Time, error) {
+	return awid.ParseTimestamp(r.ExpiresAt)
 }
 
 // ReservationHeldError is returned when a reservation is already held by another agent.
@@ -42,15 +77,147 @@ func (e *ReservationHeldError) Error() string {
 	return "aweb: reservation is already held"
 }
 
+// SetReservationPrefix namespaces lock keys for a sub-team sharing a project.
+// The Reservation methods send prefix+key for every resource key and list or
+// revoke prefix they are given, except that a key starting with "/" is
+// absolute and is sent without that slash. Responses carry the full keys the
+// server stores. An empty prefix turns namespacing off, and keys starting
+// with "/" are then sent unchanged.
+func (c *Client) SetReservationPrefix(prefix string) {
+	c.reservationPrefix = strings.TrimSpace(prefix)
+}
+
+// ReservationPrefix returns the prefix set by SetReservationPrefix.
+func (c *Client) ReservationPrefix() string {
+	return c.reservationPrefix
+}
+
+// RenewReservationsHeader carries the keys set by SetActiveReservations.
+const RenewReservationsHeader = "X-Aweb-Renew-Reservations"
+
+// MaxActiveReservations is the most keys a server renews per request.
+const MaxActiveReservations = 32
+
+// SetActiveReservations asks the server to renew keys on every request the
+// client sends, so long work keeps its locks without calling
+// ReservationRenew on a timer. Each request extends the listed locks the
+// caller still holds by their own TTL, counted from that request, so a lock
+// lives until one TTL after the last request that listed it.
+// Keys take the reservation prefix set when this is called. An empty list
+// turns the renewal off, which is the default.
+//
+// The renewal is best effort. The request carrying it succeeds or fails on
+// its own, and nothing reports a lock that was not renewed: a server that
+// predates the header ignores it, and one that rejects the header or fails
+// to renew only logs that. A lock that expired or that another agent took
+// is skipped, not taken back. Check a lock with ReservationTouch before
+// relying on it.
+func (c *Client) SetActiveReservations(keys []string) error {
+	if len(keys) > MaxActiveReservations {
+		return fmt.Errorf("aweb: %d active reservations; at most %d are renewed", len(keys), MaxActiveReservations)
+	}
+	encoded := make([]string, 0, len(keys))
+	for _, key := range keys {
+		key, err := c.resourceKey(key)
+		if err != nil {
+			return err
+		}
+		encoded = append(encoded, url.PathEscape(key))
+	}
+	c.SetDefaultHeader(RenewReservationsHeader, strings.Join(encoded, ","))
+	return nil
+}
+
+// FencingToken returns the fencing token from the caller's latest acquire
+// of resourceKey, as carried through renewals. ok is false when the client
+// has not acquired the key, has since released, transferred or lost it, or
+// the server issued no token.
+func (c *Client) FencingToken(resourceKey string) (token int64, ok bool) {
+	return c.fencingTokens.load(c.reservationKey(resourceKey))
+}
+
+// fencingTokenCache remembers the token of each lock this client holds,
+// keyed by the full resource key.
+type fencingTokenCache struct {
+	mu     sync.Mutex
+	tokens map[string]int64
+}
+
+func (f *fencingTokenCache) store(key string, token int64) {
+	if token == 0 {
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.tokens == nil {
+		f.tokens = make(map[string]int64)
+	}
+	f.tokens[key] = token
+}
+
+func (f *fencingTokenCache) load(key string) (int64, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	token, ok := f.tokens[key]
+	return token, ok
+}
+
+func (f *fencingTokenCache) forget(key string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.tokens, key)
+}
+
+// carry fills in a renewal's token from the last acquire when the server
+// omitted it, and records it otherwise.
+func (f *fencingTokenCache) carry(out *ReservationRenewResponse) {
+	if out.FencingToken == 0 {
+		out.FencingToken, _ = f.load(out.ResourceKey)
+		return
+	}
+	f.store(out.ResourceKey, out.FencingToken)
+}
+
+// reservationKey applies the reservation prefix to a key or key prefix.
+func (c *Client) reservationKey(key string) string {
+	if c.reservationPrefix == "" {
+		return key
+	}
+	if absolute, ok := strings.CutPrefix(key, "/"); ok {
+		return absolute
+	}
+	return c.reservationPrefix + key
+}
+
+// resourceKey validates key and the prefixed key sent for it.
+func (c *Client) resourceKey(key string) (string, error) {
+	if err := ValidateResourceKey(key); err != nil {
+		return "", err
+	}
+	key = c.reservationKey(key)
+	if err := ValidateResourceKey(key); err != nil {
+		return "", err
+	}
+	return key, nil
+}
+
 func (c *Client) ReservationAcquire(ctx context.Context, req *ReservationAcquireRequest) (*ReservationAcquireResponse, error) {
-	resp, err := c.DoRaw(ctx, http.MethodPost, "/v1/reservations", "application/json", req)
+	if req != nil {
+		key, err := c.resourceKey(req.ResourceKey)
+		if err != nil {
+			return nil, err
+		}
+		prefixed := *req
+		prefixed.ResourceKey = key
+		req = &prefixed
+	}
+	resp, err := c.DoRaw(ctx, http.MethodPost, c.APIPath("/reservations"), "application/json", req)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
-	limited := io.LimitReader(resp.Body, awid.MaxResponseSize)
-	data, err := io.ReadAll(limited)
+	data, err := c.ReadResponseBody(resp)
 	if err != nil {
 		return nil, err
 	}
@@ -70,6 +237,7 @@ func (c *Client) ReservationAcquire(ctx context.Context, req *ReservationAcquire
 	if err := json.Unmarshal(data, &out); err != nil {
 		return nil, err
 	}
+	c.fencingTokens.store(out.ResourceKey, out.FencingToken)
 	return &out, nil
 }
 
@@ -78,17 +246,147 @@ type ReservationRenewRequest struct {
 	TTLSeconds  int    `json:"ttl_seconds,omitempty"`
 }
 
+// ReservationRenewResponse reports a renewed lock. HolderAgentID is the
+// agent whose hold was extended, which the server only allows to be the
+// caller; older servers leave it empty. FencingToken is the token of the
+// hold, filled in from the client's last acquire when the server omits it.
 type ReservationRenewResponse struct {
-	Status      string `json:"status"`
-	ResourceKey string `json:"resource_key"`
-	ExpiresAt   string `json:"expires_at"`
+	Status        string `json:"status"`
+	ResourceKey   string `json:"resource_key"`
+	ExpiresAt     string `json:"expires_at"`
+	HolderAgentID string `json:"holder_agent_id,omitempty"`
+	HolderAlias   string `json:"holder_alias,omitempty"`
+	FencingToken  int64  `json:"fencing_token,omitempty"`
 }
 
 func (c *Client) ReservationRenew(ctx context.Context, req *ReservationRenewRequest) (*ReservationRenewResponse, error) {
+	if req != nil {
+		key, err := c.resourceKey(req.ResourceKey)
+		if err != nil {
+			return nil, err
+		}
+		prefixed := *req
+		prefixed.ResourceKey = key
+		req = &prefixed
+	}
+	var out ReservationRenewResponse
+	if err := c.Post(ctx, c.APIPath("/reservations/renew"), req, &out); err != nil {
+		return nil, err
+	}
+	c.fencingTokens.carry(&out)
+	return &out, nil
+}
+
+// ErrNotHolder is returned by ReservationTouch and ReservationTransfer when
+// the caller no longer holds the reservation, because it expired, was
+// released, or another agent took it.
+var ErrNotHolder = errors.New("aweb: reservation not held by caller")
+
+// ReservationTouch extends resourceKey by ttlSeconds (zero means the server
+// default) only while the caller still holds it. When another agent holds
+// it the error wraps both ErrNotHolder and a *ReservationHeldError naming
+// that agent; when nobody does it wraps ErrNotHolder alone. Auto-renew loops
+// should use it to notice a lost lock instead of renewing nothing.
+func (c *Client) ReservationTouch(ctx context.Context, resourceKey string, ttlSeconds int) (*ReservationRenewResponse, error) {
+	resourceKey, err := c.resourceKey(resourceKey)
+	if err != nil {
+		return nil, err
+	}
+	req := &ReservationRenewRequest{ResourceKey: resourceKey, TTLSeconds: ttlSeconds}
+	resp, err := c.DoRaw(ctx, http.MethodPost, c.APIPath("/reservations/renew"), "application/json", req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := c.ReadResponseBody(resp)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusConflict:
+		c.fencingTokens.forget(resourceKey)
+		var held ReservationHeldError
+		if err := json.Unmarshal(data, &held); err == nil {
+			return nil, fmt.Errorf("%w: %w", ErrNotHolder, &held)
+		}
+		return nil, fmt.Errorf("%w: %w", ErrNotHolder, &awid.APIError{StatusCode: resp.StatusCode, Body: string(data)})
+	case resp.StatusCode == http.StatusNotFound:
+		c.fencingTokens.forget(resourceKey)
+		return nil, fmt.Errorf("%w: %s is not reserved", ErrNotHolder, resourceKey)
+	case resp.StatusCode < 200 || resp.StatusCode >= 300:
+		return nil, &awid.APIError{StatusCode: resp.StatusCode, Body: string(data)}
+	}
+
 	var out ReservationRenewResponse
-	if err := c.Post(ctx, "/v1/reservations/renew", req, &out); err != nil {
+	if err := json.Unmarshal(data, &out); err != nil {
 		return nil, err
 	}
+	c.fencingTokens.carry(&out)
+	return &out, nil
+}
+
+type ReservationTransferRequest struct {
+	ResourceKey string `json:"resource_key"`
+	ToAlias     string `json:"to_alias"`
+}
+
+// ReservationTransferResponse reports a lock handed to another agent. The
+// lease is unchanged: ExpiresAt is the expiry the previous holder had.
+type ReservationTransferResponse struct {
+	Status                string `json:"status"`
+	ResourceKey           string `json:"resource_key"`
+	HolderAgentID         string `json:"holder_agent_id"`
+	HolderAlias           string `json:"holder_alias"`
+	PreviousHolderAgentID string `json:"previous_holder_agent_id"`
+	PreviousHolderAlias   string `json:"previous_holder_alias"`
+	AcquiredAt            string `json:"acquired_at"`
+	ExpiresAt             string `json:"expires_at"`
+	// FencingToken is the new holder's token; the caller's old one is
+	// now stale.
+	FencingToken int64 `json:"fencing_token,omitempty"`
+}
+
+// ReservationTransfer reassigns resourceKey from the caller to toAlias in one
+// step, so no other agent can take the lock in between. Like ReservationTouch
+// it fails with ErrNotHolder, wrapping a *ReservationHeldError when another
+// agent holds the lock, if the caller does not hold it.
+func (c *Client) ReservationTransfer(ctx context.Context, resourceKey, toAlias string) (*ReservationTransferResponse, error) {
+	resourceKey, err := c.resourceKey(resourceKey)
+	if err != nil {
+		return nil, err
+	}
+	req := &ReservationTransferRequest{ResourceKey: resourceKey, ToAlias: toAlias}
+	resp, err := c.DoRaw(ctx, http.MethodPost, c.APIPath("/reservations/transfer"), "application/json", req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := c.ReadResponseBody(resp)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusConflict:
+		var held ReservationHeldError
+		if err := json.Unmarshal(data, &held); err == nil {
+			return nil, fmt.Errorf("%w: %w", ErrNotHolder, &held)
+		}
+		return nil, fmt.Errorf("%w: %w", ErrNotHolder, &awid.APIError{StatusCode: resp.StatusCode, Body: string(data)})
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, fmt.Errorf("%w: %s is not reserved", ErrNotHolder, resourceKey)
+	case resp.StatusCode < 200 || resp.StatusCode >= 300:
+		return nil, &awid.APIError{StatusCode: resp.StatusCode, Body: string(data)}
+	}
+
+	var out ReservationTransferResponse
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, err
+	}
+	c.fencingTokens.forget(resourceKey)
 	return &out, nil
 }
 
@@ -102,10 +400,22 @@ type ReservationReleaseResponse struct {
 }
 
 func (c *Client) ReservationRelease(ctx context.Context, req *ReservationReleaseRequest) (*ReservationReleaseResponse, error) {
+	if req != nil {
+		key, err := c.resourceKey(req.ResourceKey)
+		if err != nil {
+			return nil, err
+		}
+		prefixed := *req
+		prefixed.ResourceKey = key
+		req = &prefixed
+	}
 	var out ReservationReleaseResponse
-	if err := c.Post(ctx, "/v1/reservations/release", req, &out); err != nil {
+	if err := c.Post(ctx, c.APIPath("/reservations/release"), req, &out); err != nil {
 		return nil, err
 	}
+	if req != nil {
+		c.fencingTokens.forget(req.ResourceKey)
+	}
 	return &out, nil
 }
 
@@ -116,10 +426,36 @@ type ReservationView struct {
 	AcquiredAt    string         `json:"acquired_at"`
 	ExpiresAt     string         `json:"expires_at"`
 	Metadata      map[string]any `json:"metadata"`
+	FencingToken  int64          `json:"fencing_token,omitempty"`
 }
 
+// AcquiredTime parses AcquiredAt.
+func (r ReservationView) AcquiredTime() (time.Time, error) { return awid.ParseTimestamp(r.AcquiredAt) }
+
+// ExpiresTime parses ExpiresAt.
+func (r ReservationView) ExpiresTime() (time.Time, error) { return awid.ParseTimestamp(r.ExpiresAt) }
+
+// ReservationListResponse is one page of active reservations, ordered by
+// resource_key so that NextCursor stays stable across calls.
 type ReservationListResponse struct {
 	Reservations []ReservationView `json:"reservations"`
+	HasMore      bool              `json:"has_more"`
+	NextCursor   *string           `json:"next_cursor,omitempty"`
+	// Total counts every reservation matching the prefix, across pages.
+	Total int `json:"total"`
+	// NotModified is set when the server answered 304 and the page came
+	// from the client's conditional GET cache.
+	NotModified bool `json:"-"`
+}
+
+type ReservationListParams struct {
+	Prefix string
+	Limit  int
+	Cursor string
+	// ExpiringWithin, when positive, keeps only reservations on the page
+	// that expire within this long, soonest first; see ExpiringWithin.
+	// Paging and Total still describe the unfiltered list.
+	ExpiringWithin time.Duration
 }
 
 type ReservationRevokeRequest struct {
@@ -131,23 +467,105 @@ type ReservationRevokeResponse struct {
 	RevokedKeys  []string `json:"revoked_keys"`
 }
 
-// ReservationRevoke force-releases reservations, optionally filtered by prefix.
+// ReservationRevoke force-releases reservations, optionally filtered by
+// prefix. With a reservation prefix set, an empty filter revokes only that
+// namespace.
 func (c *Client) ReservationRevoke(ctx context.Context, req *ReservationRevokeRequest) (*ReservationRevokeResponse, error) {
+	if req != nil {
+		prefixed := *req
+		prefixed.Prefix = c.reservationKey(req.Prefix)
+		req = &prefixed
+	}
 	var out ReservationRevokeResponse
-	if err := c.Post(ctx, "/v1/reservations/revoke", req, &out); err != nil {
+	if err := c.Post(ctx, c.APIPath("/reservations/revoke"), req, &out); err != nil {
 		return nil, err
 	}
 	return &out, nil
 }
 
-func (c *Client) ReservationList(ctx context.Context, prefix string) (*ReservationListResponse, error) {
-	path := "/v1/reservations"
-	if prefix != "" {
-		path += "?prefix=" + urlQueryEscape(prefix)
+// ReservationList fetches one page of active reservations. With a
+// reservation prefix set, params.Prefix is relative to it, so an empty one
+// lists the whole namespace and "/" lists every reservation.
+// Unchanged pages are revalidated by ETag; see awid.Client.SetConditionalGET.
+func (c *Client) ReservationList(ctx context.Context, params ReservationListParams) (*ReservationListResponse, error) {
+	params.Prefix = c.reservationKey(params.Prefix)
+	path := c.APIPath("/reservations")
+	sep := "?"
+	if params.Prefix != "" {
+		path += sep + "prefix=" + urlQueryEscape(params.Prefix)
+		sep = "&"
+	}
+	if params.Limit > 0 {
+		path += sep + "limit=" + itoa(params.Limit)
+		sep = "&"
+	}
+	if params.Cursor != "" {
+		path += sep + "cursor=" + urlQueryEscape(params.Cursor)
 	}
 	var out ReservationListResponse
-	if err := c.Get(ctx, path, &out); err != nil {
+	notModified, err := c.GetConditional(ctx, path, &out)
+	if err != nil {
 		return nil, err
 	}
+	out.NotModified = notModified
+	if params.ExpiringWithin > 0 {
+		out.Reservations, _ = ExpiringWithin(out.Reservations, params.ExpiringWithin, time.Now())
+	}
 	return &out, nil
 }
+
+// ExpiringWithin returns the reservations that expire no later than d after
+// now, soonest first. Already expired reservations the server still lists
+// are included. Reservations whose ExpiresAt is missing or unparseable
+// cannot be placed and are returned in skipped instead.
+func ExpiringWithin(reservations []ReservationView, d time.Duration, now time.Time) (expiring, skipped []ReservationView) {
+	cutoff := now.Add(d)
+	expiries := make(map[string]time.Time, len(reservations))
+	expiring = []ReservationView{}
+	for _, r := range reservations {
+		expires, err := r.ExpiresTime()
+		if err != nil {
+			skipped = append(skipped, r)
+			continue
+		}
+		if expires.After(cutoff) {
+			continue
+		}
+		expiries[r.ResourceKey] = expires
+		expiring = append(expiring, r)
+	}
+	sort.SliceStable(expiring, func(i, j int) bool {
+		return expiries[expiring[i].ResourceKey].Before(expiries[expiring[j].ResourceKey])
+	})
+	return expiring, skipped
+}
+
+// ReservationListIter iterates over active reservations page by page, using
+// params.Limit as the page size. params.Cursor is ignored; iteration always
+// starts at the first page.
+func (c *Client) ReservationListIter(params ReservationListParams) *awid.Iterator[ReservationView] {
+	return awid.NewIterator(func(ctx context.Context, cursor string) ([]ReservationView, string, error) {
+		page := params
+		page.Cursor = cursor
+		resp, err := c.ReservationList(ctx, page)
+		if err != nil {
+			return nil, "", err
+		}
+		next := ""
+		if resp.HasMore && resp.NextCursor != nil {
+			next = *resp.NextCursor
+		}
+		return resp.Reservations, next, nil
+	})
+}
+
+// ReservationListAll follows NextCursor until every reservation matching
+// prefix has been fetched, and returns them ordered by resource_key.
+func (c *Client) ReservationListAll(ctx context.Context, prefix string) ([]ReservationView, error) {
+	all, err := c.ReservationListIter(ReservationListParams{Prefix: prefix}).All(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(all, func(i, j int) bool { return all[i].ResourceKey < all[j].ResourceKey })
+	return all, nil
+}
@@ -0,0 +1,582 @@
+package aweb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReservationListSendsPaginationParams(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/reservations" {
+			t.Fatalf("unexpected %s %s", r.Method, r.URL.Path)
+		}
+		q := r.URL.Query()
+		if q.Get("prefix") != "src/" || q.Get("limit") != "2" || q.Get("cursor") != "c1" {
+			t.Fatalf("query=%s", r.URL.RawQuery)
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"reservations": []map[string]any{{"resource_key": "src/b.go"}, {"resource_key": "src/c.go"}},
+			"has_more":     true,
+			"next_cursor":  "c2",
+			"total":        5,
+		})
+	}))
+	t.Cleanup(server.Close)
+
+	c, err := New(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := c.ReservationList(context.Background(), ReservationListParams{Prefix: "src/", Limit: 2, Cursor: "c1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Reservations) != 2 || resp.Total != 5 || !resp.HasMore {
+		t.Fatalf("resp=%+v", resp)
+	}
+	if resp.NextCursor == nil || *resp.NextCursor != "c2" {
+		t.Fatalf("next_cursor=%v", resp.NextCursor)
+	}
+}
+
+func TestReservationListReturnsCachedPageOnNotModified(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"locks-1"`)
+		if r.Header.Get("If-None-Match") == `"locks-1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"reservations": []map[string]any{{"resource_key": "src/a.go", "holder_alias": "alice"}},
+			"total":        1,
+		})
+	}))
+	t.Cleanup(server.Close)
+
+	c, err := New(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, err := c.ReservationList(context.Background(), ReservationListParams{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := c.ReservationList(context.Background(), ReservationListParams{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.NotModified || !second.NotModified {
+		t.Fatalf("NotModified first=%v second=%v", first.NotModified, second.NotModified)
+	}
+	if len(second.Reservations) != 1 || second.Reservations[0].HolderAlias != "alice" || second.Total != 1 {
+		t.Fatalf("cached page=%+v", second)
+	}
+}
+
+func TestReservationListAllFollowsCursors(t *testing.T) {
+	t.Parallel()
+
+	var cursors []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("prefix") != "src/" {
+			t.Fatalf("query=%s", r.URL.RawQuery)
+		}
+		cursor := r.URL.Query().Get("cursor")
+		cursors = append(cursors, cursor)
+		switch cursor {
+		case "":
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"reservations": []map[string]any{{"resource_key": "src/b.go"}},
+				"has_more":     true,
+				"next_cursor":  "page-2",
+				"total":        3,
+			})
+		case "page-2":
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"reservations": []map[string]any{{"resource_key": "src/c.go"}, {"resource_key": "src/a.go"}},
+				"has_more":     false,
+				"total":        3,
+			})
+		default:
+			t.Fatalf("unexpected cursor %q", cursor)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	c, err := New(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	all, err := c.ReservationListAll(context.Background(), "src/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cursors) != 2 {
+		t.Fatalf("cursors=%v", cursors)
+	}
+	want := []string{"src/a.go", "src/b.go", "src/c.go"}
+	if len(all) != len(want) {
+		t.Fatalf("got %d reservations", len(all))
+	}
+	for i, key := range want {
+		if all[i].ResourceKey != key {
+			t.Fatalf("all[%d]=%q want %q", i, all[i].ResourceKey, key)
+		}
+	}
+}
+
+func TestReservationListIterUsesPageSize(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("limit") != "1" {
+			t.Fatalf("query=%s", r.URL.RawQuery)
+		}
+		switch r.URL.Query().Get("cursor") {
+		case "":
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"reservations": []map[string]any{{"resource_key": "a"}},
+				"has_more":     true,
+				"next_cursor":  "a",
+			})
+		case "a":
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"reservations": []map[string]any{{"resource_key": "b"}},
+				"has_more":     false,
+			})
+		default:
+			t.Fatalf("query=%s", r.URL.RawQuery)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	c, err := New(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	it := c.ReservationListIter(ReservationListParams{Limit: 1, Cursor: "ignored"})
+	var keys []string
+	for {
+		r, ok, err := it.Next(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !ok {
+			break
+		}
+		keys = append(keys, r.ResourceKey)
+	}
+	if len(keys) != 2 || keys[0] != "a" || keys[1] != "b" {
+		t.Fatalf("keys=%v", keys)
+	}
+}
+
+func TestReservationAcquireStealExpired(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req ReservationAcquireRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatal(err)
+		}
+		if !req.StealExpired {
+			t.Fatalf("steal_expired not sent")
+		}
+		if req.ResourceKey == "live" {
+			w.WriteHeader(http.StatusConflict)
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"detail":          "Reservation already held",
+				"holder_agent_id": "agent-bob",
+				"holder_alias":    "bob",
+				"expires_at":      "2099-01-01T00:00:00Z",
+			})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"status":                 "stolen",
+			"resource_key":           req.ResourceKey,
+			"holder_agent_id":        "agent-alice",
+			"holder_alias":           "alice",
+			"stolen_from_agent_id":   "agent-bob",
+			"stolen_from_alias":      "bob",
+			"stolen_from_expires_at": "2020-01-01T00:00:00Z",
+		})
+	}))
+	t.Cleanup(server.Close)
+
+	c, err := New(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := c.ReservationAcquire(context.Background(), &ReservationAcquireRequest{ResourceKey: "orphaned", StealExpired: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !resp.Stolen() || resp.HolderAlias != "alice" || resp.StolenFromAlias != "bob" {
+		t.Fatalf("resp=%+v", resp)
+	}
+
+	_, err = c.ReservationAcquire(context.Background(), &ReservationAcquireRequest{ResourceKey: "live", StealExpired: true})
+	var held *ReservationHeldError
+	if !errors.As(err, &held) || held.HolderAlias != "bob" {
+		t.Fatalf("err=%v, want ReservationHeldError", err)
+	}
+}
+
+func TestReservationTouch(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/reservations/renew" {
+			t.Errorf("unexpected path=%s", r.URL.Path)
+		}
+		var req ReservationRenewRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Error(err)
+		}
+		switch req.ResourceKey {
+		case "mine":
+			if req.TTLSeconds != 60 {
+				t.Errorf("ttl_seconds=%d", req.TTLSeconds)
+			}
+			_ = json.NewEncoder(w).Encode(ReservationRenewResponse{
+				Status:        "renewed",
+				ResourceKey:   "mine",
+				ExpiresAt:     "2099-01-01T00:00:00Z",
+				HolderAgentID: "agent-alice",
+				HolderAlias:   "alice",
+			})
+		case "stolen":
+			w.WriteHeader(http.StatusConflict)
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"detail":          "Reservation already held",
+				"holder_agent_id": "agent-bob",
+				"holder_alias":    "bob",
+				"expires_at":      "2099-01-01T00:00:00Z",
+			})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"detail":"reservation not found"}`))
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	c, err := New(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := c.ReservationTouch(context.Background(), "mine", 60)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.HolderAgentID != "agent-alice" || resp.ExpiresAt != "2099-01-01T00:00:00Z" {
+		t.Fatalf("resp=%+v", resp)
+	}
+
+	_, err = c.ReservationTouch(context.Background(), "stolen", 60)
+	var held *ReservationHeldError
+	if !errors.Is(err, ErrNotHolder) || !errors.As(err, &held) || held.HolderAlias != "bob" {
+		t.Fatalf("err=%v, want ErrNotHolder naming bob", err)
+	}
+
+	_, err = c.ReservationTouch(context.Background(), "expired", 60)
+	if !errors.Is(err, ErrNotHolder) || errors.As(err, &held) {
+		t.Fatalf("err=%v, want ErrNotHolder alone", err)
+	}
+}
+
+func TestReservationTransfer(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/reservations/transfer" {
+			t.Errorf("unexpected path=%s", r.URL.Path)
+		}
+		var req ReservationTransferRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Error(err)
+		}
+		switch req.ResourceKey {
+		case "mine":
+			if req.ToAlias != "bob" {
+				t.Errorf("to_alias=%q", req.ToAlias)
+			}
+			_ = json.NewEncoder(w).Encode(ReservationTransferResponse{
+				Status:                "transferred",
+				ResourceKey:           "mine",
+				HolderAgentID:         "agent-bob",
+				HolderAlias:           "bob",
+				PreviousHolderAgentID: "agent-alice",
+				PreviousHolderAlias:   "alice",
+				ExpiresAt:             "2099-01-01T00:00:00Z",
+			})
+		case "theirs":
+			w.WriteHeader(http.StatusConflict)
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"detail":          "Reservation already held",
+				"holder_agent_id": "agent-carol",
+				"holder_alias":    "carol",
+				"expires_at":      "2099-01-01T00:00:00Z",
+			})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"detail":"reservation not found"}`))
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	c, err := New(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := c.ReservationTransfer(context.Background(), "mine", "bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.HolderAlias != "bob" || resp.PreviousHolderAlias != "alice" || resp.ExpiresAt != "2099-01-01T00:00:00Z" {
+		t.Fatalf("resp=%+v", resp)
+	}
+
+	_, err = c.ReservationTransfer(context.Background(), "theirs", "bob")
+	var held *ReservationHeldError
+	if !errors.Is(err, ErrNotHolder) || !errors.As(err, &held) || held.HolderAlias != "carol" {
+		t.Fatalf("err=%v, want ErrNotHolder naming carol", err)
+	}
+
+	_, err = c.ReservationTransfer(context.Background(), "expired", "bob")
+	if !errors.Is(err, ErrNotHolder) || errors.As(err, &held) {
+		t.Fatalf("err=%v, want ErrNotHolder alone", err)
+	}
+}
+
+func TestReservationPrefixNamespacesKeys(t *testing.T) {
+	t.Parallel()
+
+	var sent []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			sent = append(sent, "list "+r.URL.Query().Get("prefix"))
+			_ = json.NewEncoder(w).Encode(map[string]any{"reservations": []map[string]any{}})
+			return
+		}
+		var body map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		key, _ := body["resource_key"].(string)
+		sent = append(sent, r.URL.Path+" "+key)
+		_ = json.NewEncoder(w).Encode(map[string]any{"status": "ok", "resource_key": key})
+	}))
+	t.Cleanup(server.Close)
+
+	c, err := New(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.SetReservationPrefix("frontend/")
+	ctx := context.Background()
+
+	req := &ReservationAcquireRequest{ResourceKey: "build"}
+	acquired, err := c.ReservationAcquire(ctx, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if acquired.ResourceKey != "frontend/build" || req.ResourceKey != "build" {
+		t.Fatalf("response key=%q request key=%q", acquired.ResourceKey, req.ResourceKey)
+	}
+	if _, err := c.ReservationRenew(ctx, &ReservationRenewRequest{ResourceKey: "build"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.ReservationRelease(ctx, &ReservationReleaseRequest{ResourceKey: "/shared/deploy"}); err != nil {
+		t.Fatal(err)
+	}
+	for _, prefix := range []string{"", "src/", "/"} {
+		if _, err := c.ReservationList(ctx, ReservationListParams{Prefix: prefix}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want := []string{
+		"/v1/reservations frontend/build",
+		"/v1/reservations/renew frontend/build",
+		"/v1/reservations/release shared/deploy",
+		"list frontend/",
+		"list frontend/src/",
+		"list ",
+	}
+	if len(sent) != len(want) {
+		t.Fatalf("sent=%q", sent)
+	}
+	for i := range want {
+		if sent[i] != want[i] {
+			t.Fatalf("request %d=%q, want %q", i, sent[i], want[i])
+		}
+	}
+
+	c.SetReservationPrefix("")
+	sent = nil
+	if _, err := c.ReservationRelease(ctx, &ReservationReleaseRequest{ResourceKey: "/srv/data"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(sent) != 1 || sent[0] != "/v1/reservations/release /srv/data" {
+		t.Fatalf("without a prefix, keys must be sent unchanged: %q", sent)
+	}
+}
+
+func TestExpiringWithinSortsSoonestFirstAndSkipsBadExpiry(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 3, 10, 10, 0, 0, 0, time.UTC)
+	in := []ReservationView{
+		{ResourceKey: "later", ExpiresAt: "2026-03-10T10:00:50Z"},
+		{ResourceKey: "outside", ExpiresAt: "2026-03-10T10:05:00Z"},
+		{ResourceKey: "missing"},
+		{ResourceKey: "expired", ExpiresAt: "2026-03-10T09:59:00Z"},
+		{ResourceKey: "garbled", ExpiresAt: "tomorrow"},
+		{ResourceKey: "sooner", ExpiresAt: "2026-03-10T10:00:10Z"},
+	}
+
+	expiring, skipped := ExpiringWithin(in, time.Minute, now)
+	var got []string
+	for _, r := range expiring {
+		got = append(got, r.ResourceKey)
+	}
+	if strings.Join(got, ",") != "expired,sooner,later" {
+		t.Fatalf("expiring=%v", got)
+	}
+	if len(skipped) != 2 || skipped[0].ResourceKey != "missing" || skipped[1].ResourceKey != "garbled" {
+		t.Fatalf("skipped=%+v", skipped)
+	}
+}
+
+func TestFencingTokenCarriesThroughRenewalsUntilRelease(t *testing.T) {
+	t.Parallel()
+
+	renewals := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/reservations":
+			_ = json.NewEncoder(w).Encode(map[string]any{"status": "acquired", "resource_key": "team/deploy", "fencing_token": 7})
+		case "/v1/reservations/renew":
+			renewals++
+			resp := map[string]any{"status": "renewed", "resource_key": "team/deploy"}
+			if renewals == 2 {
+				resp["fencing_token"] = 9
+			}
+			_ = json.NewEncoder(w).Encode(resp)
+		case "/v1/reservations/release":
+			_ = json.NewEncoder(w).Encode(map[string]any{"status": "released", "resource_key": "team/deploy"})
+		default:
+			t.Errorf("unexpected %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	c, err := New(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.SetReservationPrefix("team/")
+	ctx := context.Background()
+	acquired, err := c.ReservationAcquire(ctx, &ReservationAcquireRequest{ResourceKey: "deploy"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if acquired.FencingToken != 7 {
+		t.Fatalf("acquire token=%d", acquired.FencingToken)
+	}
+
+	// The server leaves the token out; the client fills in the acquire's.
+	renewed, err := c.ReservationRenew(ctx, &ReservationRenewRequest{ResourceKey: "deploy"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if renewed.FencingToken != 7 {
+		t.Fatalf("first renew token=%d, want 7", renewed.FencingToken)
+	}
+	touched, err := c.ReservationTouch(ctx, "deploy", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if touched.FencingToken != 9 {
+		t.Fatalf("touch token=%d, want 9", touched.FencingToken)
+	}
+	if token, ok := c.FencingToken("deploy"); !ok || token != 9 {
+		t.Fatalf("FencingToken=%d,%v", token, ok)
+	}
+
+	if _, err := c.ReservationRelease(ctx, &ReservationReleaseRequest{ResourceKey: "deploy"}); err != nil {
+		t.Fatal(err)
+	}
+	if token, ok := c.FencingToken("deploy"); ok {
+		t.Fatalf("token %d kept after release", token)
+	}
+}
+
+func TestSetActiveReservationsRenewsOnEveryRequest(t *testing.T) {
+	t.Parallel()
+
+	var headers []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers = append(headers, r.Header.Get(RenewReservationsHeader))
+		_ = json.NewEncoder(w).Encode(map[string]any{"messages": []any{}, "reservations": []any{}})
+	}))
+	t.Cleanup(server.Close)
+
+	c, err := New(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	if err := c.Get(ctx, "/v1/messages/inbox", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	c.SetReservationPrefix("frontend/")
+	if err := c.SetActiveReservations([]string{"build", "/shared/a,b"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Get(ctx, "/v1/messages/inbox", nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.ReservationList(ctx, ReservationListParams{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.SetActiveReservations(nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Get(ctx, "/v1/messages/inbox", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	const active = "frontend%2Fbuild,shared%2Fa%2Cb"
+	want := []string{"", active, active, ""}
+	if len(headers) != len(want) {
+		t.Fatalf("headers=%q", headers)
+	}
+	for i := range want {
+		if headers[i] != want[i] {
+			t.Fatalf("request %d header=%q, want %q", i, headers[i], want[i])
+		}
+	}
+
+	if err := c.SetActiveReservations([]string{""}); !errors.Is(err, ErrInvalidResourceKey) {
+		t.Fatalf("empty key: err=%v", err)
+	}
+	tooMany := make([]string, MaxActiveReservations+1)
+	for i := range tooMany {
+		tooMany[i] = "k" + strings.Repeat("x", i)
+	}
+	if err := c.SetActiveReservations(tooMany); err == nil {
+		t.Fatal("accepted more keys than the server renews")
+	}
+}
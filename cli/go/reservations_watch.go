@@ -0,0 +1,253 @@
+package aweb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/awebai/aw/awid"
+)
+
+// ReservationEventType identifies a reservation transition.
+type ReservationEventType string
+
+const (
+	ReservationAcquired ReservationEventType = "acquired"
+	ReservationReleased ReservationEventType = "released"
+	ReservationExpired  ReservationEventType = "expired"
+)
+
+// ReservationEvent is one transition emitted by WatchReservation. For
+// released and expired events the holder fields name the agent that held
+// the lock. Snapshot is true for the acquired event describing a lock that
+// was already held when the watch started.
+type ReservationEvent struct {
+	Type          ReservationEventType `json:"type"`
+	ResourceKey   string               `json:"resource_key"`
+	HolderAgentID string               `json:"holder_agent_id,omitempty"`
+	HolderAlias   string               `json:"holder_alias,omitempty"`
+	AcquiredAt    string               `json:"acquired_at,omitempty"`
+	ExpiresAt     string               `json:"expires_at,omitempty"`
+	Snapshot      bool                 `json:"snapshot,omitempty"`
+}
+
+// ErrReservationWatchUnsupported is wrapped by WatchReservation when the
+// server has no watch endpoint. The original *awid.APIError stays in the
+// chain.
+var ErrReservationWatchUnsupported = errors.New("aweb: server does not support reservation watch")
+
+const (
+	reservationWatchReconnectMin = time.Second
+	reservationWatchReconnectMax = 30 * time.Second
+
+	// defaultReservationWaitPoll is how often ReservationAcquireWait retries
+	// when the server cannot stream reservation events.
+	defaultReservationWaitPoll = 2 * time.Second
+)
+
+// WatchReservation streams acquire, release and expire events for
+// resourceKey from GET /v1/reservations/watch.
+//
+// If the lock is held when the watch starts, the channel first receives an
+// acquired event with Snapshot set. Dropped connections are reopened with
+// backoff, and the server's fresh snapshot is compared with the known hold
+// so that changes missed while disconnected are still reported, once. The
+// channel is closed when ctx is done or the server rejects a reconnect with
+// a 4xx status. The initial connection error is returned directly, wrapping
+// ErrReservationWatchUnsupported on a 404.
+func (c *Client) WatchReservation(ctx context.Context, resourceKey string) (<-chan ReservationEvent, error) {
+	resourceKey, err := c.resourceKey(resourceKey)
+	if err != nil {
+		return nil, err
+	}
+	stream, err := c.openReservationWatch(ctx, resourceKey)
+	if err != nil {
+		if code, ok := awid.HTTPStatusCode(err); ok && code == http.StatusNotFound {
+			return nil, fmt.Errorf("%w: %w", ErrReservationWatchUnsupported, err)
+		}
+		return nil, err
+	}
+	out := make(chan ReservationEvent, 16)
+	go c.watchReservation(ctx, resourceKey, stream, out)
+	return out, nil
+}
+
+func (c *Client) openReservationWatch(ctx context.Context, resourceKey string) (*awid.SSEStream, error) {
+	return c.OpenSSEStream(ctx, c.APIPath("/reservations/watch?resource_key="+urlQueryEscape(resourceKey)))
+}
+
+func (c *Client) watchReservation(ctx context.Context, resourceKey string, stream *awid.SSEStream, out chan<- ReservationEvent) {
+	defer close(out)
+	w := &reservationWatcher{out: out}
+	backoff := reservationWatchReconnectMin
+	for {
+		if w.consume(ctx, stream) {
+			backoff = reservationWatchReconnectMin
+		}
+		_ = stream.Close()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(backoff):
+			}
+			backoff = min(backoff*2, reservationWatchReconnectMax)
+			next, err := c.openReservationWatch(ctx, resourceKey)
+			if err == nil {
+				stream = next
+				break
+			}
+			var apiErr *awid.APIError
+			if errors.As(err, &apiErr) && apiErr.StatusCode >= 400 && apiErr.StatusCode < 500 && apiErr.StatusCode != http.StatusTooManyRequests {
+				return
+			}
+		}
+	}
+}
+
+// reservationWatcher tracks the current hold so reconnect snapshots can be
+// turned into transitions.
+type reservationWatcher struct {
+	out         chan<- ReservationEvent
+	held        *ReservationEvent
+	seenInitial bool
+}
+
+// consume reads stream until it fails, reporting whether any event arrived.
+func (w *reservationWatcher) consume(ctx context.Context, stream *awid.SSEStream) bool {
+	received := false
+	for {
+		ev, err := stream.Next()
+		if err != nil || ctx.Err() != nil {
+			return received
+		}
+		received = true
+		switch kind := strings.TrimSpace(ev.Event); kind {
+		case "snapshot":
+			var payload struct {
+				ResourceKey string            `json:"resource_key"`
+				Reservation *ReservationEvent `json:"reservation"`
+			}
+			if json.Unmarshal([]byte(ev.Data), &payload) != nil {
+				continue
+			}
+			if payload.Reservation != nil {
+				payload.Reservation.ResourceKey = payload.ResourceKey
+			}
+			w.applySnapshot(ctx, payload.ResourceKey, payload.Reservation)
+		case string(ReservationAcquired), string(ReservationReleased), string(ReservationExpired):
+			var event ReservationEvent
+			if json.Unmarshal([]byte(ev.Data), &event) != nil {
+				continue
+			}
+			event.Type = ReservationEventType(kind)
+			event.Snapshot = false
+			if event.Type == ReservationAcquired {
+				w.held = &event
+			} else {
+				w.held = nil
+			}
+			w.emit(ctx, event)
+		}
+	}
+}
+
+func (w *reservationWatcher) applySnapshot(ctx context.Context, resourceKey string, current *ReservationEvent) {
+	initial := !w.seenInitial
+	w.seenInitial = true
+	if current == nil {
+		if w.held != nil {
+			gone := *w.held
+			gone.Type = ReservationReleased
+			gone.Snapshot = false
+			if expires, err := awid.ParseTimestamp(gone.ExpiresAt); err == nil && !expires.After(time.Now()) {
+				gone.Type = ReservationExpired
+			}
+			w.held = nil
+			w.emit(ctx, gone)
+		}
+		return
+	}
+	if w.held != nil && w.held.HolderAgentID == current.HolderAgentID && w.held.AcquiredAt == current.AcquiredAt {
+		w.held = current
+		return
+	}
+	current.Type = ReservationAcquired
+	current.ResourceKey = resourceKey
+	current.Snapshot = initial
+	w.held = current
+	w.emit(ctx, *current)
+}
+
+func (w *reservationWatcher) emit(ctx context.Context, ev ReservationEvent) {
+	select {
+	case w.out <- ev:
+	case <-ctx.Done():
+	}
+}
+
+// ReservationAcquireWait acquires req.ResourceKey, waiting while another
+// agent holds it. It watches the key and retries as soon as the lock is
+// released or expires; against a server without the watch endpoint, or
+// once the watch ends, it retries every poll instead (zero means two
+// seconds). Errors other than the lock being held are returned at once;
+// ctx bounds the whole wait.
+func (c *Client) ReservationAcquireWait(ctx context.Context, req *ReservationAcquireRequest, poll time.Duration) (*ReservationAcquireResponse, error) {
+	if req == nil {
+		return nil, errors.New("aweb: reservation acquire request is required")
+	}
+	if poll <= 0 {
+		poll = defaultReservationWaitPoll
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// The watch is opened before the first attempt so a release between a
+	// refused acquire and the wait is not missed.
+	events, err := c.WatchReservation(ctx, req.ResourceKey)
+	if err != nil && !errors.Is(err, ErrReservationWatchUnsupported) {
+		return nil, err
+	}
+	for {
+		resp, err := c.ReservationAcquire(ctx, req)
+		var held *ReservationHeldError
+		if !errors.As(err, &held) {
+			return resp, err
+		}
+
+		if events, err = waitReservationRetry(ctx, events, poll); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// waitReservationRetry blocks until the lock is worth trying again: a
+// release or expiry on events, or poll elapsing when events is nil. It
+// returns nil in place of events once the watch has ended.
+func waitReservationRetry(ctx context.Context, events <-chan ReservationEvent, poll time.Duration) (<-chan ReservationEvent, error) {
+	var retry <-chan time.Time
+	if events == nil {
+		timer := time.NewTimer(poll)
+		defer timer.Stop()
+		retry = timer.C
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-retry:
+			return nil, nil
+		case ev, ok := <-events:
+			if !ok {
+				return nil, nil
+			}
+			if ev.Type == ReservationReleased || ev.Type == ReservationExpired {
+				return events, nil
+			}
+		}
+	}
+}
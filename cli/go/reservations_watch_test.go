@@ -0,0 +1,144 @@
+package aweb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWatchReservationReportsReleaseMissedWhileDisconnected(t *testing.T) {
+	t.Parallel()
+
+	var connects atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/reservations/watch" || r.URL.Query().Get("resource_key") != "team/deploy" {
+			t.Errorf("unexpected %s %s", r.Method, r.URL)
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		if connects.Add(1) == 1 {
+			fmt.Fprint(w, "event: snapshot\ndata: {\"resource_key\":\"team/deploy\",\"reservation\":{\"holder_agent_id\":\"agent-bob\",\"holder_alias\":\"bob\",\"acquired_at\":\"2026-01-01T00:00:00Z\",\"expires_at\":\"2999-01-01T00:00:00Z\"}}\n\n")
+			return
+		}
+		fmt.Fprint(w, "event: snapshot\ndata: {\"resource_key\":\"team/deploy\",\"reservation\":null}\n\n")
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	t.Cleanup(server.Close)
+
+	c, err := New(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.SetReservationPrefix("team/")
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	events, err := c.WatchReservation(ctx, "deploy")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first := <-events
+	if first.Type != ReservationAcquired || !first.Snapshot || first.HolderAlias != "bob" || first.ResourceKey != "team/deploy" {
+		t.Fatalf("first=%+v", first)
+	}
+	second := <-events
+	if second.Type != ReservationReleased || second.Snapshot || second.HolderAlias != "bob" {
+		t.Fatalf("second=%+v", second)
+	}
+	cancel()
+	for range events {
+	}
+}
+
+func TestReservationAcquireWaitRetriesWhenWatchReportsRelease(t *testing.T) {
+	t.Parallel()
+
+	refused := make(chan struct{})
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/reservations/watch":
+			w.Header().Set("Content-Type", "text/event-stream")
+			fmt.Fprint(w, "event: snapshot\ndata: {\"resource_key\":\"deploy\",\"reservation\":{\"holder_agent_id\":\"agent-bob\",\"holder_alias\":\"bob\",\"expires_at\":\"2999-01-01T00:00:00Z\"}}\n\n")
+			w.(http.Flusher).Flush()
+			select {
+			case <-refused:
+			case <-r.Context().Done():
+				return
+			}
+			fmt.Fprint(w, "event: released\ndata: {\"resource_key\":\"deploy\",\"holder_alias\":\"bob\"}\n\n")
+			w.(http.Flusher).Flush()
+			<-r.Context().Done()
+		case "/v1/reservations":
+			if attempts.Add(1) == 1 {
+				w.WriteHeader(http.StatusConflict)
+				fmt.Fprint(w, `{"detail":"reservation is already held","holder_alias":"bob"}`)
+				close(refused)
+				return
+			}
+			fmt.Fprint(w, `{"status":"acquired","resource_key":"deploy","holder_alias":"alice"}`)
+		default:
+			t.Errorf("unexpected %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	c, err := New(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	// An hour-long poll proves the retry came from the stream.
+	resp, err := c.ReservationAcquireWait(ctx, &ReservationAcquireRequest{ResourceKey: "deploy"}, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.HolderAlias != "alice" || attempts.Load() != 2 {
+		t.Fatalf("resp=%+v attempts=%d", resp, attempts.Load())
+	}
+}
+
+func TestReservationAcquireWaitPollsWhenWatchIsUnsupported(t *testing.T) {
+	t.Parallel()
+
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/reservations/watch":
+			http.NotFound(w, r)
+		case "/v1/reservations":
+			if attempts.Add(1) < 3 {
+				w.WriteHeader(http.StatusConflict)
+				fmt.Fprint(w, `{"detail":"reservation is already held","holder_alias":"bob"}`)
+				return
+			}
+			fmt.Fprint(w, `{"status":"acquired","resource_key":"deploy","holder_alias":"alice"}`)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	c, err := New(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.WatchReservation(context.Background(), "deploy"); !errors.Is(err, ErrReservationWatchUnsupported) {
+		t.Fatalf("WatchReservation err=%v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	resp, err := c.ReservationAcquireWait(ctx, &ReservationAcquireRequest{ResourceKey: "deploy"}, 10*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Status != "acquired" || attempts.Load() != 3 {
+		t.Fatalf("resp=%+v attempts=%d", resp, attempts.Load())
+	}
+}
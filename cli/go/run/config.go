@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/awebai/aw/awconfig"
@@ -44,10 +45,52 @@ type SettingOverrides struct {
 	IdleWaitSeconds   *int
 }
 
+// ConfigPathEnv lists the global config layers explicitly, separated like
+// PATH. It replaces the default layers from GlobalConfigPaths.
+const ConfigPathEnv = "AW_CONFIG_PATH"
+
+// ConfigDropInDir is the directory next to the global run.json whose *.json
+// files are layered beneath it, e.g. a team's shared, version-controlled
+// prompts and services symlinked in.
+const ConfigDropInDir = "run.d"
+
 func DefaultConfigPath() (string, error) {
 	return awconfig.DefaultRunConfigPath()
 }
 
+// GlobalConfigPaths returns the global config layers, lowest precedence
+// first: the *.json files in run.d in lexical order, then run.json. When
+// AW_CONFIG_PATH is set its entries are the layers instead. A non-empty
+// configPath, as given by `aw --config`, wins over both and is the only
+// layer. The last layer is the user's own; WriteUserConfig writes there and
+// never to the others.
+func GlobalConfigPaths(configPath string) ([]string, error) {
+	if configPath = strings.TrimSpace(configPath); configPath != "" {
+		return []string{filepath.Clean(configPath)}, nil
+	}
+	if override := strings.TrimSpace(os.Getenv(ConfigPathEnv)); override != "" {
+		var paths []string
+		for _, path := range filepath.SplitList(override) {
+			if path = strings.TrimSpace(path); path != "" {
+				paths = append(paths, filepath.Clean(path))
+			}
+		}
+		if len(paths) > 0 {
+			return paths, nil
+		}
+	}
+	path, err := DefaultConfigPath()
+	if err != nil {
+		return nil, err
+	}
+	dropIns, err := filepath.Glob(filepath.Join(filepath.Dir(path), ConfigDropInDir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(dropIns)
+	return append(dropIns, path), nil
+}
+
 func FindLocalConfigPath(startDir string) (string, error) {
 	if strings.TrimSpace(startDir) == "" {
 		wd, err := os.Getwd()
@@ -63,7 +106,9 @@ func FindLocalConfigPath(startDir string) (string, error) {
 	return filepath.Join(filepath.Dir(ctxPath), "run.json"), nil
 }
 
-func LoadUserConfig(startDir string) (UserConfig, error) {
+// LoadUserConfig merges the global layers for configPath (see
+// GlobalConfigPaths) with the run.json of the workspace containing startDir.
+func LoadUserConfig(startDir, configPath string) (UserConfig, error) {
 	if strings.TrimSpace(startDir) == "" {
 		wd, err := os.Getwd()
 		if err != nil {
@@ -71,13 +116,17 @@ func LoadUserConfig(startDir string) (UserConfig, error) {
 		}
 		startDir = wd
 	}
-	globalPath, err := DefaultConfigPath()
+	globalPaths, err := GlobalConfigPaths(configPath)
 	if err != nil {
 		return UserConfig{}, err
 	}
-	cfg, err := loadUserConfigFile(globalPath)
-	if err != nil {
-		return UserConfig{}, err
+	var cfg UserConfig
+	for _, path := range globalPaths {
+		layer, err := loadUserConfigFile(path)
+		if err != nil {
+			return UserConfig{}, err
+		}
+		cfg = mergeUserConfig(cfg, layer)
 	}
 
 	localPath, err := FindLocalConfigPath(startDir)
@@ -94,11 +143,27 @@ func LoadUserConfig(startDir string) (UserConfig, error) {
 	return mergeUserConfig(cfg, localCfg), nil
 }
 
-func WriteUserConfig(cfg UserConfig) (string, error) {
-	path, err := DefaultConfigPath()
+// ReadUserConfig reads only the user's own global layer for configPath, the
+// one WriteUserConfig writes, and returns it with its path. A missing file
+// yields an empty config.
+func ReadUserConfig(configPath string) (UserConfig, string, error) {
+	paths, err := GlobalConfigPaths(configPath)
+	if err != nil {
+		return UserConfig{}, "", err
+	}
+	path := paths[len(paths)-1]
+	cfg, err := loadUserConfigFile(path)
+	return cfg, path, err
+}
+
+// WriteUserConfig writes cfg to the last global config layer for
+// configPath, leaving any shared layers beneath it untouched.
+func WriteUserConfig(configPath string, cfg UserConfig) (string, error) {
+	paths, err := GlobalConfigPaths(configPath)
 	if err != nil {
 		return "", err
 	}
+	path := paths[len(paths)-1]
 	return path, writeUserConfigTo(path, cfg)
 }
 
@@ -11,7 +11,7 @@ func TestLoadUserConfigMissingReturnsZero(t *testing.T) {
 	t.Setenv("HOME", dir)
 	t.Setenv("AW_CONFIG_PATH", "")
 
-	cfg, err := LoadUserConfig(dir)
+	cfg, err := LoadUserConfig(dir, "")
 	if err != nil {
 		t.Fatalf("LoadUserConfig returned error: %v", err)
 	}
@@ -32,7 +32,7 @@ func TestLoadUserConfigReadsFile(t *testing.T) {
 		t.Fatalf("write failed: %v", err)
 	}
 
-	cfg, err := LoadUserConfig(dir)
+	cfg, err := LoadUserConfig(dir, "")
 	if err != nil {
 		t.Fatalf("LoadUserConfig returned error: %v", err)
 	}
@@ -81,7 +81,7 @@ func TestLoadUserConfigLocalOverridesGlobal(t *testing.T) {
 		t.Fatalf("write local config failed: %v", err)
 	}
 
-	cfg, err := LoadUserConfig(workspaceRoot)
+	cfg, err := LoadUserConfig(workspaceRoot, "")
 	if err != nil {
 		t.Fatalf("LoadUserConfig returned error: %v", err)
 	}
@@ -105,6 +105,83 @@ func TestLoadUserConfigLocalOverridesGlobal(t *testing.T) {
 	}
 }
 
+func TestLoadUserConfigMergesDropInsBeneathRunJSON(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	t.Setenv("AW_CONFIG_PATH", "")
+	configDir := filepath.Join(dir, ".config", "aw")
+	if err := os.MkdirAll(filepath.Join(configDir, "run.d"), 0o755); err != nil {
+		t.Fatalf("mkdir failed: %v", err)
+	}
+	writeConfigLayer(t, filepath.Join(configDir, "run.d", "10-team.json"), `{"base_prompt":"team base","work_prompt_suffix":"team work","wait_seconds":11}`)
+	writeConfigLayer(t, filepath.Join(configDir, "run.d", "20-project.json"), `{"work_prompt_suffix":"project work"}`)
+	writeConfigLayer(t, filepath.Join(configDir, "run.json"), `{"wait_seconds":5}`)
+
+	cfg, err := LoadUserConfig(dir, "")
+	if err != nil {
+		t.Fatalf("LoadUserConfig returned error: %v", err)
+	}
+	if cfg.BasePrompt == nil || *cfg.BasePrompt != "team base" {
+		t.Fatalf("expected team base_prompt, got %#v", cfg.BasePrompt)
+	}
+	if cfg.WorkPromptSuffix == nil || *cfg.WorkPromptSuffix != "project work" {
+		t.Fatalf("expected later drop-in to win, got %#v", cfg.WorkPromptSuffix)
+	}
+	if cfg.WaitSeconds == nil || *cfg.WaitSeconds != 5 {
+		t.Fatalf("expected run.json to win, got %#v", cfg.WaitSeconds)
+	}
+}
+
+func TestConfigPathEnvLayersAndWritesOnlyLastLayer(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	shared := filepath.Join(dir, "repo", "aw-run.json")
+	user := filepath.Join(dir, "private", "run.json")
+	t.Setenv("AW_CONFIG_PATH", shared+string(os.PathListSeparator)+user)
+	if err := os.MkdirAll(filepath.Dir(shared), 0o755); err != nil {
+		t.Fatalf("mkdir failed: %v", err)
+	}
+	sharedData := `{"base_prompt":"shared base","idle_wait_seconds":44}`
+	writeConfigLayer(t, shared, sharedData)
+
+	wait := 3
+	path, err := WriteUserConfig("", UserConfig{WaitSeconds: &wait})
+	if err != nil {
+		t.Fatalf("WriteUserConfig returned error: %v", err)
+	}
+	if path != user {
+		t.Fatalf("wrote %s, want %s", path, user)
+	}
+	data, err := os.ReadFile(shared)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != sharedData {
+		t.Fatalf("shared layer was modified: %s", data)
+	}
+
+	cfg, err := LoadUserConfig(dir, "")
+	if err != nil {
+		t.Fatalf("LoadUserConfig returned error: %v", err)
+	}
+	if cfg.BasePrompt == nil || *cfg.BasePrompt != "shared base" {
+		t.Fatalf("expected shared base_prompt, got %#v", cfg.BasePrompt)
+	}
+	if cfg.IdleWaitSeconds == nil || *cfg.IdleWaitSeconds != 44 {
+		t.Fatalf("expected shared idle_wait_seconds, got %#v", cfg.IdleWaitSeconds)
+	}
+	if cfg.WaitSeconds == nil || *cfg.WaitSeconds != 3 {
+		t.Fatalf("expected user wait_seconds, got %#v", cfg.WaitSeconds)
+	}
+}
+
+func writeConfigLayer(t *testing.T, path, data string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatalf("write %s failed: %v", path, err)
+	}
+}
+
 func TestResolveSettingsPrecedence(t *testing.T) {
 	wait := 9
 	idleWait := 41
@@ -144,3 +221,32 @@ func TestResolveSettingsPrecedence(t *testing.T) {
 		t.Fatalf("expected comms suffix from config, got %q", settings.CommsPromptSuffix)
 	}
 }
+
+func TestConfigPathArgumentWinsOverEnv(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	envLayer := filepath.Join(dir, "env-run.json")
+	writeConfigLayer(t, envLayer, `{"base_prompt":"from env","idle_wait_seconds":44}`)
+	t.Setenv("AW_CONFIG_PATH", envLayer)
+	flagLayer := filepath.Join(dir, "flag", "run.json")
+
+	wait := 7
+	path, err := WriteUserConfig(flagLayer, UserConfig{WaitSeconds: &wait})
+	if err != nil {
+		t.Fatalf("WriteUserConfig returned error: %v", err)
+	}
+	if path != flagLayer {
+		t.Fatalf("wrote %s, want %s", path, flagLayer)
+	}
+
+	cfg, err := LoadUserConfig(dir, flagLayer)
+	if err != nil {
+		t.Fatalf("LoadUserConfig returned error: %v", err)
+	}
+	if cfg.WaitSeconds == nil || *cfg.WaitSeconds != 7 {
+		t.Fatalf("expected flag wait_seconds, got %#v", cfg.WaitSeconds)
+	}
+	if cfg.BasePrompt != nil || cfg.IdleWaitSeconds != nil {
+		t.Fatalf("env layer leaked into flag config: %#v", cfg)
+	}
+}
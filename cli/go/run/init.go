@@ -13,7 +13,9 @@ const (
 	DefaultInitCommsSuffix = "After handling the communication, return to the best available work if more work remains."
 )
 
-func InitUserConfig(in io.Reader, out io.Writer, existing UserConfig) error {
+// InitUserConfig prompts for each setting, starting from existing, and
+// writes the answers with WriteUserConfig(configPath, ...).
+func InitUserConfig(in io.Reader, out io.Writer, existing UserConfig, configPath string) error {
 	reader := bufio.NewReader(in)
 	current, err := ResolveSettings(existing, SettingOverrides{})
 	if err != nil {
@@ -50,7 +52,7 @@ func InitUserConfig(in io.Reader, out io.Writer, existing UserConfig) error {
 		WaitSeconds:       waitSeconds,
 		IdleWaitSeconds:   idleWaitSeconds,
 	}
-	path, err := WriteUserConfig(cfg)
+	path, err := WriteUserConfig(configPath, cfg)
 	if err != nil {
 		return err
 	}
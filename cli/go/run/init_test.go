@@ -16,11 +16,11 @@ func TestInitUserConfigWritesConfig(t *testing.T) {
 	input := strings.NewReader("coordinate with mia\nreview before finish\nreturn to work\n15\n45\n")
 	var output bytes.Buffer
 
-	if err := InitUserConfig(input, &output, UserConfig{}); err != nil {
+	if err := InitUserConfig(input, &output, UserConfig{}, ""); err != nil {
 		t.Fatalf("InitUserConfig returned error: %v", err)
 	}
 
-	cfg, err := LoadUserConfig(dir)
+	cfg, err := LoadUserConfig(dir, "")
 	if err != nil {
 		t.Fatalf("LoadUserConfig returned error: %v", err)
 	}
@@ -52,11 +52,11 @@ func TestInitUserConfigSeedsSuggestedDefaultsWhenUnset(t *testing.T) {
 	input := strings.NewReader("\n\n\n\n\n")
 	var output bytes.Buffer
 
-	if err := InitUserConfig(input, &output, UserConfig{}); err != nil {
+	if err := InitUserConfig(input, &output, UserConfig{}, ""); err != nil {
 		t.Fatalf("InitUserConfig returned error: %v", err)
 	}
 
-	cfg, err := LoadUserConfig(dir)
+	cfg, err := LoadUserConfig(dir, "")
 	if err != nil {
 		t.Fatalf("LoadUserConfig returned error: %v", err)
 	}
@@ -0,0 +1,67 @@
+package aweb
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// SanitizeForTerminal makes untrusted text, such as a message body, safe to
+// print to a terminal. Newlines and tabs are kept and CRLF becomes LF; every
+// other control character, including escape sequences, lone carriage returns
+// and the C1 range, is shown as a visible escape like \x1b instead of being
+// interpreted. Bidirectional overrides, which can make text read differently
+// from its bytes, are escaped the same way, and invalid UTF-8 becomes U+FFFD.
+//
+// It is for display only: JSON output should carry the original text.
+func SanitizeForTerminal(s string) string {
+	if isTerminalSafe(s) {
+		return s
+	}
+	var sb strings.Builder
+	sb.Grow(len(s))
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case r == utf8.RuneError && size == 1:
+			sb.WriteRune(utf8.RuneError)
+		case r == '\r' && strings.HasPrefix(s[i+1:], "\n"):
+			// Dropped; the following \n ends the line.
+		case terminalUnsafeRune(r):
+			if r < 0x100 {
+				fmt.Fprintf(&sb, `\x%02x`, r)
+			} else {
+				fmt.Fprintf(&sb, `\u%04x`, r)
+			}
+		default:
+			sb.WriteString(s[i : i+size])
+		}
+		i += size
+	}
+	return sb.String()
+}
+
+func isTerminalSafe(s string) bool {
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if (r == utf8.RuneError && size == 1) || r == '\r' || terminalUnsafeRune(r) {
+			return false
+		}
+		i += size
+	}
+	return true
+}
+
+func terminalUnsafeRune(r rune) bool {
+	switch {
+	case r == '\n' || r == '\t':
+		return false
+	case r < 0x20 || r == 0x7f:
+		return true
+	case r >= 0x80 && r <= 0x9f:
+		return true
+	case r >= 0x202a && r <= 0x202e, r >= 0x2066 && r <= 0x2069:
+		return true
+	}
+	return false
+}
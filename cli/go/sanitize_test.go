@@ -0,0 +1,25 @@
+package aweb
+
+import "testing"
+
+func TestSanitizeForTerminal(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		name, in, want string
+	}{
+		{"plain text unchanged", "hello — wörld\n\tindented", "hello — wörld\n\tindented"},
+		{"escape sequence shown", "\x1b[2Jcleared", `\x1b[2Jcleared`},
+		{"nul and bell", "a\x00b\x07", `a\x00b\x07`},
+		{"crlf becomes lf", "one\r\ntwo\r\n", "one\ntwo\n"},
+		{"lone carriage return shown", "safe\rEVIL", `safe\x0dEVIL`},
+		{"c1 csi shown", "x\u009b31mred", `x\x9b31mred`},
+		{"bidi override shown", "abc\u202edcba", `abc\u202edcba`},
+		{"invalid utf-8 replaced", "ok\xffok", "ok�ok"},
+		{"delete shown", "a\x7f", `a\x7f`},
+	} {
+		if got := SanitizeForTerminal(tc.in); got != tc.want {
+			t.Errorf("%s: SanitizeForTerminal(%q)=%q, want %q", tc.name, tc.in, got, tc.want)
+		}
+	}
+}
@@ -3,7 +3,6 @@ package aweb
 import (
 	"context"
 	"encoding/json"
-	"io"
 	"net/http"
 	"strings"
 
@@ -150,14 +149,14 @@ func (e *TaskHeldError) Error() string {
 
 func (c *Client) TaskCreate(ctx context.Context, req *TaskCreateRequest) (*Task, error) {
 	var out Task
-	if err := c.Post(ctx, "/v1/tasks", req, &out); err != nil {
+	if err := c.Post(ctx, c.APIPath("/tasks"), req, &out); err != nil {
 		return nil, err
 	}
 	return &out, nil
 }
 
 func (c *Client) TaskList(ctx context.Context, params TaskListParams) (*TaskListResponse, error) {
-	path := "/v1/tasks"
+	path := c.APIPath("/tasks")
 	sep := "?"
 	if params.Status != "" {
 		path += sep + "status=" + urlQueryEscape(params.Status)
@@ -188,7 +187,7 @@ func (c *Client) TaskList(ctx context.Context, params TaskListParams) (*TaskList
 
 func (c *Client) TaskListReady(ctx context.Context) (*TaskListResponse, error) {
 	var out TaskListResponse
-	if err := c.Get(ctx, "/v1/tasks/ready", &out); err != nil {
+	if err := c.Get(ctx, c.APIPath("/tasks/ready"), &out); err != nil {
 		return nil, err
 	}
 	return &out, nil
@@ -196,7 +195,7 @@ func (c *Client) TaskListReady(ctx context.Context) (*TaskListResponse, error) {
 
 func (c *Client) TaskListBlocked(ctx context.Context) (*TaskListResponse, error) {
 	var out TaskListResponse
-	if err := c.Get(ctx, "/v1/tasks/blocked", &out); err != nil {
+	if err := c.Get(ctx, c.APIPath("/tasks/blocked"), &out); err != nil {
 		return nil, err
 	}
 	return &out, nil
@@ -204,7 +203,7 @@ func (c *Client) TaskListBlocked(ctx context.Context) (*TaskListResponse, error)
 
 func (c *Client) TaskListActive(ctx context.Context) (*ActiveTaskListResponse, error) {
 	var out ActiveTaskListResponse
-	if err := c.Get(ctx, "/v1/tasks/active", &out); err != nil {
+	if err := c.Get(ctx, c.APIPath("/tasks/active"), &out); err != nil {
 		return nil, err
 	}
 	return &out, nil
@@ -212,7 +211,7 @@ func (c *Client) TaskListActive(ctx context.Context) (*ActiveTaskListResponse, e
 
 func (c *Client) TaskGet(ctx context.Context, ref string) (*Task, error) {
 	var out Task
-	if err := c.Get(ctx, "/v1/tasks/"+urlPathEscape(ref), &out); err != nil {
+	if err := c.Get(ctx, c.APIPath("/tasks/"+urlPathEscape(ref)), &out); err != nil {
 		return nil, err
 	}
 	return &out, nil
@@ -223,14 +222,13 @@ func (c *Client) TaskGet(ctx context.Context, ref string) (*Task, error) {
 // returned as a *TaskHeldError. When closing a parent task, the response
 // may include AutoClosed listing cascade-closed children.
 func (c *Client) TaskUpdate(ctx context.Context, ref string, req *TaskUpdateRequest) (*TaskUpdateResponse, error) {
-	resp, err := c.DoRaw(ctx, http.MethodPatch, "/v1/tasks/"+urlPathEscape(ref), "application/json", req)
+	resp, err := c.DoRaw(ctx, http.MethodPatch, c.APIPath("/tasks/"+urlPathEscape(ref)), "application/json", req)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
-	limited := io.LimitReader(resp.Body, awid.MaxResponseSize)
-	data, err := io.ReadAll(limited)
+	data, err := c.ReadResponseBody(resp)
 	if err != nil {
 		return nil, err
 	}
@@ -254,16 +252,16 @@ func (c *Client) TaskUpdate(ctx context.Context, ref string, req *TaskUpdateRequ
 }
 
 func (c *Client) TaskDelete(ctx context.Context, ref string) error {
-	return c.Delete(ctx, "/v1/tasks/"+urlPathEscape(ref))
+	return c.Delete(ctx, c.APIPath("/tasks/"+urlPathEscape(ref)))
 }
 
 // TaskAddDep adds a dependency. Returns 422 if this would create a cycle.
 func (c *Client) TaskAddDep(ctx context.Context, ref string, req *TaskAddDepRequest) error {
-	return c.Post(ctx, "/v1/tasks/"+urlPathEscape(ref)+"/deps", req, nil)
+	return c.Post(ctx, c.APIPath("/tasks/"+urlPathEscape(ref)+"/deps"), req, nil)
 }
 
 func (c *Client) TaskRemoveDep(ctx context.Context, ref string, depRef string) error {
-	return c.Delete(ctx, "/v1/tasks/"+urlPathEscape(ref)+"/deps/"+urlPathEscape(depRef))
+	return c.Delete(ctx, c.APIPath("/tasks/"+urlPathEscape(ref)+"/deps/"+urlPathEscape(depRef)))
 }
 
 // Comments
@@ -288,7 +286,7 @@ type TaskCommentListResponse struct {
 
 func (c *Client) TaskCommentCreate(ctx context.Context, ref string, req *TaskCommentCreateRequest) (*TaskComment, error) {
 	var out TaskComment
-	if err := c.Post(ctx, "/v1/tasks/"+urlPathEscape(ref)+"/comments", req, &out); err != nil {
+	if err := c.Post(ctx, c.APIPath("/tasks/"+urlPathEscape(ref)+"/comments"), req, &out); err != nil {
 		return nil, err
 	}
 	return &out, nil
@@ -296,7 +294,7 @@ func (c *Client) TaskCommentCreate(ctx context.Context, ref string, req *TaskCom
 
 func (c *Client) TaskCommentList(ctx context.Context, ref string) (*TaskCommentListResponse, error) {
 	var out TaskCommentListResponse
-	if err := c.Get(ctx, "/v1/tasks/"+urlPathEscape(ref)+"/comments", &out); err != nil {
+	if err := c.Get(ctx, c.APIPath("/tasks/"+urlPathEscape(ref)+"/comments"), &out); err != nil {
 		return nil, err
 	}
 	return &out, nil
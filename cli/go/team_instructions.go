@@ -54,14 +54,14 @@ type TeamInstructionsHistoryResponse struct {
 
 func (c *Client) ActiveTeamInstructions(ctx context.Context) (*ActiveTeamInstructionsResponse, error) {
 	var out ActiveTeamInstructionsResponse
-	if err := c.Get(ctx, "/v1/instructions/active", &out); err != nil {
+	if err := c.Get(ctx, c.APIPath("/instructions/active"), &out); err != nil {
 		return nil, err
 	}
 	return &out, nil
 }
 
 func (c *Client) TeamInstructionsHistory(ctx context.Context, limit int) (*TeamInstructionsHistoryResponse, error) {
-	path := "/v1/instructions/history"
+	path := c.APIPath("/instructions/history")
 	if limit > 0 {
 		path += "?limit=" + itoa(limit)
 	}
@@ -74,7 +74,7 @@ func (c *Client) TeamInstructionsHistory(ctx context.Context, limit int) (*TeamI
 
 func (c *Client) GetTeamInstructions(ctx context.Context, teamInstructionsID string) (*ActiveTeamInstructionsResponse, error) {
 	var out ActiveTeamInstructionsResponse
-	if err := c.Get(ctx, "/v1/instructions/"+urlPathEscape(teamInstructionsID), &out); err != nil {
+	if err := c.Get(ctx, c.APIPath("/instructions/"+urlPathEscape(teamInstructionsID)), &out); err != nil {
 		return nil, err
 	}
 	return &out, nil
@@ -82,7 +82,7 @@ func (c *Client) GetTeamInstructions(ctx context.Context, teamInstructionsID str
 
 func (c *Client) CreateTeamInstructions(ctx context.Context, req *CreateTeamInstructionsRequest) (*CreateTeamInstructionsResponse, error) {
 	var out CreateTeamInstructionsResponse
-	if err := c.Post(ctx, "/v1/instructions", req, &out); err != nil {
+	if err := c.Post(ctx, c.APIPath("/instructions"), req, &out); err != nil {
 		return nil, err
 	}
 	return &out, nil
@@ -90,7 +90,7 @@ func (c *Client) CreateTeamInstructions(ctx context.Context, req *CreateTeamInst
 
 func (c *Client) ActivateTeamInstructions(ctx context.Context, teamInstructionsID string) (*ActivateTeamInstructionsResponse, error) {
 	var out ActivateTeamInstructionsResponse
-	if err := c.Post(ctx, "/v1/instructions/"+urlPathEscape(teamInstructionsID)+"/activate", nil, &out); err != nil {
+	if err := c.Post(ctx, c.APIPath("/instructions/"+urlPathEscape(teamInstructionsID)+"/activate"), nil, &out); err != nil {
 		return nil, err
 	}
 	return &out, nil
@@ -98,7 +98,7 @@ func (c *Client) ActivateTeamInstructions(ctx context.Context, teamInstructionsI
 
 func (c *Client) ResetTeamInstructions(ctx context.Context) (*ResetTeamInstructionsResponse, error) {
 	var out ResetTeamInstructionsResponse
-	if err := c.Post(ctx, "/v1/instructions/reset", nil, &out); err != nil {
+	if err := c.Post(ctx, c.APIPath("/instructions/reset"), nil, &out); err != nil {
 		return nil, err
 	}
 	return &out, nil
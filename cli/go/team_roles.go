@@ -101,7 +101,7 @@ func (c *Client) ActiveTeamRoles(ctx context.Context, params ActiveTeamRolesPara
 		return nil, err
 	}
 
-	path := "/v1/roles/active"
+	path := c.APIPath("/roles/active")
 	sep := "?"
 	if roleName != "" {
 		path += sep + "role_name=" + urlQueryEscape(roleName)
@@ -117,7 +117,7 @@ func (c *Client) ActiveTeamRoles(ctx context.Context, params ActiveTeamRolesPara
 }
 
 func (c *Client) TeamRolesHistory(ctx context.Context, limit int) (*TeamRolesHistoryResponse, error) {
-	path := "/v1/roles/history"
+	path := c.APIPath("/roles/history")
 	if limit > 0 {
 		path += "?limit=" + itoa(limit)
 	}
@@ -130,7 +130,7 @@ func (c *Client) TeamRolesHistory(ctx context.Context, limit int) (*TeamRolesHis
 
 func (c *Client) CreateTeamRoles(ctx context.Context, req *CreateTeamRolesRequest) (*CreateTeamRolesResponse, error) {
 	var out CreateTeamRolesResponse
-	if err := c.Post(ctx, "/v1/roles", req, &out); err != nil {
+	if err := c.Post(ctx, c.APIPath("/roles"), req, &out); err != nil {
 		return nil, err
 	}
 	return &out, nil
@@ -138,7 +138,7 @@ func (c *Client) CreateTeamRoles(ctx context.Context, req *CreateTeamRolesReques
 
 func (c *Client) ActivateTeamRoles(ctx context.Context, teamRolesID string) (*ActivateTeamRolesResponse, error) {
 	var out ActivateTeamRolesResponse
-	if err := c.Post(ctx, "/v1/roles/"+urlPathEscape(teamRolesID)+"/activate", nil, &out); err != nil {
+	if err := c.Post(ctx, c.APIPath("/roles/"+urlPathEscape(teamRolesID)+"/activate"), nil, &out); err != nil {
 		return nil, err
 	}
 	return &out, nil
@@ -146,7 +146,7 @@ func (c *Client) ActivateTeamRoles(ctx context.Context, teamRolesID string) (*Ac
 
 func (c *Client) ResetTeamRoles(ctx context.Context) (*ResetTeamRolesResponse, error) {
 	var out ResetTeamRolesResponse
-	if err := c.Post(ctx, "/v1/roles/reset", nil, &out); err != nil {
+	if err := c.Post(ctx, c.APIPath("/roles/reset"), nil, &out); err != nil {
 		return nil, err
 	}
 	return &out, nil
@@ -154,7 +154,7 @@ func (c *Client) ResetTeamRoles(ctx context.Context) (*ResetTeamRolesResponse, e
 
 func (c *Client) DeactivateTeamRoles(ctx context.Context) (*DeactivateTeamRolesResponse, error) {
 	var out DeactivateTeamRolesResponse
-	if err := c.Post(ctx, "/v1/roles/deactivate", nil, &out); err != nil {
+	if err := c.Post(ctx, c.APIPath("/roles/deactivate"), nil, &out); err != nil {
 		return nil, err
 	}
 	return &out, nil
@@ -0,0 +1,69 @@
+package aweb
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// Server limits for aliases and reservation keys. Checking them locally turns
+// a 422 after a round trip into an immediate, specific error.
+const (
+	MaxAliasLength       = 64
+	MaxResourceKeyLength = 4096
+)
+
+var (
+	// ErrInvalidAlias is wrapped by ValidateAlias errors.
+	ErrInvalidAlias = errors.New("aweb: invalid alias")
+	// ErrInvalidResourceKey is wrapped by ValidateResourceKey errors.
+	ErrInvalidResourceKey = errors.New("aweb: invalid resource key")
+)
+
+var aliasPattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_-]*$`)
+
+// ValidateAlias checks an agent alias against the server's rules: 1 to 64
+// characters, starting with a letter or digit and otherwise letters, digits,
+// '-' or '_', and not the reserved "me". A team~alias target, which addresses
+// an agent on another team in the same namespace, is also accepted; the team
+// part must be 1 to 64 characters.
+func ValidateAlias(alias string) error {
+	alias = strings.TrimSpace(alias)
+	if team, rest, ok := strings.Cut(alias, "~"); ok {
+		team, rest = strings.TrimSpace(team), strings.TrimSpace(rest)
+		switch {
+		case strings.Contains(rest, "~"):
+			return fmt.Errorf("%w %q: team~alias must include exactly one '~'", ErrInvalidAlias, alias)
+		case team == "":
+			return fmt.Errorf("%w %q: team~alias must include a team", ErrInvalidAlias, alias)
+		case len(team) > MaxAliasLength:
+			return fmt.Errorf("%w %q: team must be at most %d characters", ErrInvalidAlias, alias, MaxAliasLength)
+		}
+		alias = rest
+	}
+	switch {
+	case alias == "":
+		return fmt.Errorf("%w: alias must not be empty", ErrInvalidAlias)
+	case len(alias) > MaxAliasLength:
+		return fmt.Errorf("%w %q: must be at most %d characters", ErrInvalidAlias, alias, MaxAliasLength)
+	case strings.EqualFold(alias, "me"):
+		return fmt.Errorf("%w %q: reserved alias", ErrInvalidAlias, alias)
+	case !aliasPattern.MatchString(alias):
+		return fmt.Errorf("%w %q: must start with a letter or digit and contain only letters, digits, '-' and '_'", ErrInvalidAlias, alias)
+	}
+	return nil
+}
+
+// ValidateResourceKey checks a reservation key against the server's rules:
+// 1 to 4096 characters. Keys are otherwise opaque.
+func ValidateResourceKey(key string) error {
+	switch {
+	case key == "":
+		return fmt.Errorf("%w: key must not be empty", ErrInvalidResourceKey)
+	case utf8.RuneCountInString(key) > MaxResourceKeyLength:
+		return fmt.Errorf("%w: key must be at most %d characters", ErrInvalidResourceKey, MaxResourceKeyLength)
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package aweb
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/awebai/aw/awid"
+)
+
+func TestValidateAlias(t *testing.T) {
+	t.Parallel()
+
+	for _, alias := range []string{"alice", "Bob-2", "a_b", "7", "backend~alice", strings.Repeat("a", MaxAliasLength)} {
+		if err := ValidateAlias(alias); err != nil {
+			t.Errorf("ValidateAlias(%q)=%v, want nil", alias, err)
+		}
+	}
+	for _, alias := range []string{"", "-alice", "al ice", "alice.dev", "me", "ME", "a~b~c", "~alice", "backend~", strings.Repeat("a", MaxAliasLength+1)} {
+		if err := ValidateAlias(alias); !errors.Is(err, ErrInvalidAlias) {
+			t.Errorf("ValidateAlias(%q)=%v, want ErrInvalidAlias", alias, err)
+		}
+	}
+}
+
+func TestValidateResourceKey(t *testing.T) {
+	t.Parallel()
+
+	for _, key := range []string{"x", "src/main.go", strings.Repeat("é", MaxResourceKeyLength)} {
+		if err := ValidateResourceKey(key); err != nil {
+			t.Errorf("ValidateResourceKey(%d chars)=%v, want nil", len([]rune(key)), err)
+		}
+	}
+	for _, key := range []string{"", strings.Repeat("k", MaxResourceKeyLength+1)} {
+		if err := ValidateResourceKey(key); !errors.Is(err, ErrInvalidResourceKey) {
+			t.Errorf("ValidateResourceKey(%d chars)=%v, want ErrInvalidResourceKey", len(key), err)
+		}
+	}
+}
+
+func TestInvalidInputFailsBeforeRequest(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected %s %s", r.Method, r.URL.Path)
+	}))
+	t.Cleanup(server.Close)
+
+	c, err := New(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.SetReservationPrefix("frontend/")
+	ctx := context.Background()
+
+	if _, err := c.ReservationAcquire(ctx, &ReservationAcquireRequest{ResourceKey: ""}); !errors.Is(err, ErrInvalidResourceKey) {
+		t.Fatalf("acquire err=%v", err)
+	}
+	// "/" is an absolute key that leaves nothing to lock once the slash is stripped.
+	if _, err := c.ReservationTouch(ctx, "/", 60); !errors.Is(err, ErrInvalidResourceKey) {
+		t.Fatalf("touch err=%v", err)
+	}
+	if _, _, err := c.SendMessageQueued(ctx, &awid.SendMessageRequest{ToAlias: "bob smith", Body: "hi"}, false); !errors.Is(err, ErrInvalidAlias) {
+		t.Fatalf("send err=%v", err)
+	}
+}
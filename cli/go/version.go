@@ -0,0 +1,74 @@
+package aweb
+
+import (
+	"runtime"
+	"runtime/debug"
+)
+
+// Version is the release of this module. It moves with the CHANGELOG.
+const Version = "1.8.1"
+
+// Set by goreleaser ldflags:
+//
+//	-X github.com/awebai/aw.commit=... -X github.com/awebai/aw.date=...
+var (
+	commit = ""
+	date   = ""
+)
+
+// Build describes the running build of the client.
+type Build struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit,omitempty"`
+	Date      string `json:"date,omitempty"`
+	GoVersion string `json:"go_version"`
+	OS        string `json:"os"`
+	Arch      string `json:"arch"`
+}
+
+// BuildInfo reports the module version, the commit and build date, and the
+// Go toolchain and platform. Commit and date come from ldflags in release
+// builds; otherwise they fall back to the VCS stamp go build records, and
+// stay empty when there is none (go test, go run).
+func BuildInfo() Build {
+	b := Build{
+		Version:   Version,
+		Commit:    commit,
+		Date:      date,
+		GoVersion: runtime.Version(),
+		OS:        runtime.GOOS,
+		Arch:      runtime.GOARCH,
+	}
+	if b.Commit != "" && b.Date != "" {
+		return b
+	}
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return b
+	}
+	modified := false
+	revision, vcsTime := "", ""
+	for _, s := range info.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			revision = s.Value
+		case "vcs.time":
+			vcsTime = s.Value
+		case "vcs.modified":
+			modified = s.Value == "true"
+		}
+	}
+	if b.Commit == "" && revision != "" {
+		if len(revision) > 12 {
+			revision = revision[:12]
+		}
+		if modified {
+			revision += "-dirty"
+		}
+		b.Commit = revision
+	}
+	if b.Date == "" {
+		b.Date = vcsTime
+	}
+	return b
+}
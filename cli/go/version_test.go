@@ -0,0 +1,25 @@
+package aweb
+
+import (
+	"runtime"
+	"testing"
+)
+
+func TestBuildInfoPrefersLinkedCommitAndDate(t *testing.T) {
+	oldCommit, oldDate := commit, date
+	t.Cleanup(func() { commit, date = oldCommit, oldDate })
+	commit, date = "abc1234", "2026-01-02T03:04:05Z"
+
+	got := BuildInfo()
+	want := Build{
+		Version:   Version,
+		Commit:    "abc1234",
+		Date:      "2026-01-02T03:04:05Z",
+		GoVersion: runtime.Version(),
+		OS:        runtime.GOOS,
+		Arch:      runtime.GOARCH,
+	}
+	if got != want {
+		t.Fatalf("BuildInfo()=%+v, want %+v", got, want)
+	}
+}
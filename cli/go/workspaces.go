@@ -87,14 +87,14 @@ type PatchCurrentWorkspaceResponse struct {
 
 func (c *Client) PatchCurrentWorkspace(ctx context.Context, req *PatchCurrentWorkspaceRequest) (*PatchCurrentWorkspaceResponse, error) {
 	var out PatchCurrentWorkspaceResponse
-	if err := c.Patch(ctx, "/v1/agents/me", req, &out); err != nil {
+	if err := c.Patch(ctx, c.APIPath("/agents/me"), req, &out); err != nil {
 		return nil, err
 	}
 	return &out, nil
 }
 
 func (c *Client) WorkspaceTeam(ctx context.Context, params WorkspaceTeamParams) (*WorkspaceListResponse, error) {
-	path := "/v1/workspaces/team"
+	path := c.APIPath("/workspaces/team")
 	sep := "?"
 	if params.HumanName != "" {
 		path += sep + "human_name=" + urlQueryEscape(params.HumanName)
@@ -125,7 +125,7 @@ func (c *Client) WorkspaceTeam(ctx context.Context, params WorkspaceTeamParams)
 // Returns nil, nil if the workspace was already deleted (404).
 func (c *Client) WorkspaceDelete(ctx context.Context, workspaceID string) (*DeleteWorkspaceResponse, error) {
 	var out DeleteWorkspaceResponse
-	err := c.Do(ctx, "DELETE", "/v1/workspaces/"+urlPathEscape(workspaceID), nil, &out)
+	err := c.Do(ctx, "DELETE", c.APIPath("/workspaces/"+urlPathEscape(workspaceID)), nil, &out)
 	if err != nil {
 		if code, ok := awid.HTTPStatusCode(err); ok && code == 404 {
 			return nil, nil
@@ -142,7 +142,7 @@ type WorkspaceListParams struct {
 
 // WorkspaceList lists workspaces, optionally filtered by hostname.
 func (c *Client) WorkspaceList(ctx context.Context, params WorkspaceListParams) (*WorkspaceListResponse, error) {
-	path := "/v1/workspaces"
+	path := c.APIPath("/workspaces")
 	sep := "?"
 	if params.Hostname != "" {
 		path += sep + "hostname=" + urlQueryEscape(params.Hostname)
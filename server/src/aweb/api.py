@@ -4,6 +4,7 @@ from contextlib import asynccontextmanager
 from typing import Optional
 
 from fastapi import FastAPI, Request
+from fastapi.middleware.gzip import GZipMiddleware
 from fastapi.responses import JSONResponse
 from redis.asyncio import Redis
 from redis.asyncio import from_url as async_redis_from_url
@@ -20,6 +21,7 @@ from .routing_utils import move_mount_before_spa_fallback
 from .service_errors import ServiceError
 from .mcp.server import NormalizeMountedMCPPathMiddleware
 from .routes.agents import router as agents_router
+from .routes.auth import router as auth_router
 from .routes.connect import router as connect_router
 from .routes.dashboard import router as dashboard_router
 from .routes.chat import router as chat_router
@@ -249,6 +251,8 @@ def create_app(
 
     app = FastAPI(title="aweb coordination core", version="0.1.0", lifespan=lifespan)
     app.add_middleware(NormalizeMountedMCPPathMiddleware, mount_path="/mcp")
+    # Inbox and history pages compress ~5x; event streams are never compressed.
+    app.add_middleware(GZipMiddleware, minimum_size=1024)
 
     @app.middleware("http")
     async def cache_body_middleware(request: Request, call_next):
@@ -325,6 +329,7 @@ def create_app(
         return {"status": "ok" if healthy else "unhealthy", "checks": checks}
 
     app.include_router(agents_router)
+    app.include_router(auth_router)
     app.include_router(connect_router)
     app.include_router(chat_router)
     app.include_router(dashboard_router)
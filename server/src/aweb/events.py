@@ -231,6 +231,7 @@ class TeamChatMessageSentEvent(TeamEvent):
     from_alias: str = ""
     to_aliases: list[str] = field(default_factory=list)
     preview: str = ""
+    priority: str = "normal"
 
 
 @dataclass
@@ -254,6 +255,14 @@ class TeamReservationRenewedEvent(TeamEvent):
     paths: list[str] = field(default_factory=list)
 
 
+@dataclass
+class TeamReservationTransferredEvent(TeamEvent):
+    type: str = field(default="reservation.transferred", init=False)
+    alias: str = ""
+    previous_alias: str = ""
+    paths: list[str] = field(default_factory=list)
+
+
 @dataclass
 class TeamAgentOnlineEvent(TeamEvent):
     type: str = field(default="agent.online", init=False)
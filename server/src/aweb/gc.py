@@ -169,6 +169,10 @@ async def _hard_delete_scope(aweb_db, *, team_id) -> None:
             "DELETE FROM {{tables.reservations}} WHERE team_id = $1",
             team_id,
         )
+        await tx.execute(
+            "DELETE FROM {{tables.reservation_fencing}} WHERE team_id = $1",
+            team_id,
+        )
         await tx.execute(
             "DELETE FROM {{tables.workspaces}} WHERE team_id = $1",
             team_id,
@@ -17,7 +17,9 @@ class AliasTarget:
 
 
 class AmbiguousLocalAddressError(Exception):
-    pass
+    def __init__(self, message: str, candidates: list[dict[str, Any]] | None = None):
+        super().__init__(message)
+        self.candidates = candidates or []
 
 
 def resolve_alias_target(auth_team_id: str | None, raw_alias: str, *, field: str) -> AliasTarget:
@@ -121,5 +123,15 @@ async def get_agent_by_namespace_alias(db, *, namespace: str, alias: str) -> dic
         str(alias or "").strip(),
     )
     if len(rows) > 1:
-        raise AmbiguousLocalAddressError(f"Address {namespace}/{alias} matches multiple local agents")
+        raise AmbiguousLocalAddressError(
+            f"Address {namespace}/{alias} matches multiple local agents",
+            candidates=[
+                {
+                    "agent_id": str(row["agent_id"]),
+                    "alias": row["alias"],
+                    "address": (row["address"] or "").strip() or None,
+                }
+                for row in rows
+            ],
+        )
     return None if not rows else dict(rows[0])
@@ -1,5 +1,6 @@
 from __future__ import annotations
 
+import json
 import logging
 import uuid as uuid_mod
 from datetime import datetime, timezone
@@ -252,6 +253,7 @@ async def send_in_session(
     reply_to: UUID | None = None,
     leaving: bool = False,
     hang_on: bool = False,
+    priority: str = "normal",
     signature: str | None = None,
     signed_payload: str | None = None,
     created_at: datetime | None = None,
@@ -278,8 +280,8 @@ async def send_in_session(
         """
         INSERT INTO {{tables.chat_messages}}
             (message_id, session_id, from_agent_id, from_did, from_alias, from_address,
-             body, sender_leaving, hang_on, reply_to, signature, signed_payload, created_at)
-        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+             body, sender_leaving, hang_on, reply_to, signature, signed_payload, created_at, priority)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
         RETURNING message_id, created_at
         """,
         effective_message_id,
@@ -295,6 +297,7 @@ async def send_in_session(
         signature,
         signed_payload,
         effective_created_at,
+        priority,
     )
 
     await aweb_db.execute(
@@ -340,11 +343,15 @@ async def get_pending_conversations(
             lm.from_did AS last_from_did,
             lm.from_agent_id AS last_from_agent_id,
             lm.hang_on AS last_message_hang_on,
+            lm.priority AS last_priority,
             lm.created_at AS last_activity,
             COALESCE(unread.cnt, 0) AS unread_count,
             s.wait_seconds,
             s.wait_started_at,
             s.wait_started_by,
+            s.subject,
+            s.metadata_json,
+            p.nudged_at,
             COALESCE(wait_ext.total_seconds, 0) AS extended_wait_seconds
         FROM {{tables.chat_sessions}} s
         JOIN {{tables.chat_participants}} p
@@ -352,7 +359,7 @@ async def get_pending_conversations(
         JOIN {{tables.chat_participants}} p2
           ON p2.session_id = s.session_id
         LEFT JOIN LATERAL (
-            SELECT body, from_alias, from_address, from_did, from_agent_id, hang_on, created_at
+            SELECT body, from_alias, from_address, from_did, from_agent_id, hang_on, priority, created_at
             FROM {{tables.chat_messages}}
             WHERE session_id = s.session_id
             ORDER BY created_at DESC
@@ -384,11 +391,15 @@ async def get_pending_conversations(
             lm.from_did,
             lm.from_agent_id,
             lm.hang_on,
+            lm.priority,
             lm.created_at,
             unread.cnt,
             s.wait_seconds,
             s.wait_started_at,
             s.wait_started_by,
+            s.subject,
+            s.metadata_json,
+            p.nudged_at,
             wait_ext.total_seconds
         HAVING COALESCE(unread.cnt, 0) > 0
             OR (
@@ -428,6 +439,7 @@ async def get_pending_conversations(
             "last_from_agent_id": (
                 str(row["last_from_agent_id"]) if row.get("last_from_agent_id") else None
             ),
+            "last_priority": row.get("last_priority") or "normal",
             "unread_count": int(row["unread_count"] or 0),
             "last_activity": row["last_activity"],
             "wait_seconds": int(row["wait_seconds"]) if row.get("wait_seconds") is not None else None,
@@ -436,11 +448,64 @@ async def get_pending_conversations(
                 str(row["wait_started_by"]) if row.get("wait_started_by") is not None else None
             ),
             "extended_wait_seconds": int(row["extended_wait_seconds"] or 0),
+            "subject": row.get("subject") or "",
+            "metadata": session_metadata(row.get("metadata_json")),
+            "nudged_at": row.get("nudged_at"),
         }
         for row in rows
     ]
 
 
+async def nudge_unread_participants(db, *, session_id: UUID, sender_did: str) -> list[str]:
+    """Flag participants who have not read the session's latest message.
+
+    The sender and the author of that message are never nudged. Returns the
+    aliases that were flagged, sorted; nudged_at changes the participant's
+    pending entry so connected event streams re-emit it.
+    """
+    aweb_db = db.get_manager("aweb")
+    rows = await aweb_db.fetch_all(
+        """
+        WITH latest AS (
+            SELECT from_did, created_at
+            FROM {{tables.chat_messages}}
+            WHERE session_id = $1
+            ORDER BY created_at DESC
+            LIMIT 1
+        )
+        UPDATE {{tables.chat_participants}} p
+        SET nudged_at = NOW()
+        FROM latest
+        WHERE p.session_id = $1
+          AND p.did <> $2
+          AND p.did <> latest.from_did
+          AND NOT EXISTS (
+              SELECT 1
+              FROM {{tables.chat_read_receipts}} rr
+              JOIN {{tables.chat_messages}} last_read_msg
+                ON last_read_msg.message_id = rr.last_read_message_id
+              WHERE rr.session_id = p.session_id
+                AND rr.did = p.did
+                AND last_read_msg.created_at >= latest.created_at
+          )
+        RETURNING p.alias
+        """,
+        session_id,
+        sender_did,
+    )
+    return sorted(str(row["alias"]) for row in rows)
+
+
+def session_metadata(raw: Any) -> dict[str, Any]:
+    """Decode a chat_sessions.metadata_json value; anything but an object is {}."""
+    if isinstance(raw, str):
+        try:
+            raw = json.loads(raw)
+        except json.JSONDecodeError:
+            return {}
+    return dict(raw) if isinstance(raw, dict) else {}
+
+
 async def get_message_history(
     db,
     *,
@@ -449,7 +514,19 @@ async def get_message_history(
     unread_only: bool = False,
     limit: int = 200,
     message_id: str | None = None,
+    since: datetime | None = None,
+    after_message_id: str | UUID | None = None,
+    body_contains: str | None = None,
+    oldest_first: bool = False,
 ) -> list[dict[str, Any]]:
+    """Return up to ``limit`` of the session's latest messages, oldest first.
+
+    ``body_contains`` keeps only messages whose body contains it, ignoring
+    case; the limit then applies to the matches. With ``oldest_first`` the
+    limit keeps the earliest matching messages instead, so a client can page
+    forward through a session by passing the last message it saw as
+    ``after_message_id``.
+    """
     aweb_db = db.get_manager("aweb")
     is_participant = await aweb_db.fetch_one(
         """
@@ -479,11 +556,27 @@ async def get_message_history(
 
     message_uuid = _uuid_or_none(message_id)
 
+    after_uuid = _uuid_or_none(after_message_id)
+    if after_uuid is not None:
+        after_row = await aweb_db.fetch_one(
+            """
+            SELECT created_at
+            FROM {{tables.chat_messages}}
+            WHERE session_id = $1 AND message_id = $2
+            """,
+            session_id,
+            after_uuid,
+        )
+        if after_row is None:
+            raise NotFoundError("after_message_id not found in this session")
+        if since is None or after_row["created_at"] > since:
+            since = after_row["created_at"]
+
     if message_uuid is not None:
         rows = await aweb_db.fetch_all(
             """
             SELECT message_id, from_alias, from_address, body, created_at, sender_leaving,
-                   from_agent_id, reply_to, from_did, signature, signed_payload
+                   from_agent_id, reply_to, from_did, signature, signed_payload, priority
             FROM {{tables.chat_messages}}
             WHERE session_id = $1
               AND message_id = $2
@@ -497,7 +590,7 @@ async def get_message_history(
         rows = await aweb_db.fetch_all(
             """
             SELECT message_id, from_alias, from_address, body, created_at, sender_leaving,
-                   from_agent_id, reply_to, from_did, signature, signed_payload
+                   from_agent_id, reply_to, from_did, signature, signed_payload, priority
             FROM {{tables.chat_messages}}
             WHERE session_id = $1
               AND (
@@ -507,7 +600,10 @@ async def get_message_history(
                     AND from_did <> $4
                 )
               )
-            ORDER BY created_at DESC
+              AND ($6::timestamptz IS NULL OR created_at > $6::timestamptz)
+              AND ($7::text IS NULL OR strpos(lower(body), lower($7::text)) > 0)
+            ORDER BY CASE WHEN $8::bool THEN created_at END ASC,
+                     created_at DESC
             LIMIT $5
             """,
             session_id,
@@ -515,8 +611,12 @@ async def get_message_history(
             last_read_message_at,
             participant_did,
             int(limit),
+            since,
+            body_contains or None,
+            bool(oldest_first),
         )
-    rows = list(reversed(rows))
+    if not oldest_first:
+        rows = list(reversed(rows))
 
     return [
         {
@@ -530,6 +630,7 @@ async def get_message_history(
             "body": row["body"],
             "created_at": row["created_at"],
             "sender_leaving": bool(row["sender_leaving"]),
+            "priority": row.get("priority") or "normal",
             "reply_to": str(row["reply_to"]) if row.get("reply_to") is not None else None,
             "signature": row.get("signature"),
             "signed_payload": row.get("signed_payload"),
@@ -634,3 +735,95 @@ async def mark_messages_read(
         "session_id": str(session_id),
         "messages_marked": int(marked or 0) if upserted else 0,
     }
+
+
+async def mark_messages_unread(
+    db,
+    *,
+    session_id: UUID,
+    participant_did: str,
+    from_message_id: str,
+) -> dict[str, Any]:
+    """Move the participant's read receipt back to just before from_message_id.
+
+    A receipt that already sits before that message is left alone, so this
+    only ever makes messages unread. The read time is not touched, which keeps
+    the move from being broadcast as a read receipt.
+    """
+    aweb_db = db.get_manager("aweb")
+    from_uuid = UUID(from_message_id)
+
+    is_participant = await aweb_db.fetch_one(
+        """
+        SELECT 1
+        FROM {{tables.chat_participants}}
+        WHERE session_id = $1 AND did = $2
+        """,
+        session_id,
+        participant_did,
+    )
+    if not is_participant:
+        raise ForbiddenError("Not a participant in this session")
+
+    msg = await aweb_db.fetch_one(
+        """
+        SELECT created_at
+        FROM {{tables.chat_messages}}
+        WHERE session_id = $1 AND message_id = $2
+        """,
+        session_id,
+        from_uuid,
+    )
+    if not msg:
+        raise NotFoundError("Message not found")
+
+    previous = await aweb_db.fetch_one(
+        """
+        SELECT message_id
+        FROM {{tables.chat_messages}}
+        WHERE session_id = $1 AND created_at < $2
+        ORDER BY created_at DESC
+        LIMIT 1
+        """,
+        session_id,
+        msg["created_at"],
+    )
+
+    await aweb_db.execute(
+        """
+        UPDATE {{tables.chat_read_receipts}}
+        SET last_read_message_id = $3
+        WHERE session_id = $1
+          AND did = $2
+          AND COALESCE(
+            (SELECT created_at FROM {{tables.chat_messages}}
+             WHERE message_id = {{tables.chat_read_receipts}}.last_read_message_id),
+            'epoch'::timestamptz
+          ) >= $4
+        """,
+        session_id,
+        participant_did,
+        previous["message_id"] if previous else None,
+        msg["created_at"],
+    )
+
+    unread = await aweb_db.fetch_value(
+        """
+        SELECT COUNT(*)::int
+        FROM {{tables.chat_messages}} m
+        LEFT JOIN {{tables.chat_read_receipts}} rr
+          ON rr.session_id = m.session_id AND rr.did = $2
+        LEFT JOIN {{tables.chat_messages}} last_read_msg
+          ON last_read_msg.message_id = rr.last_read_message_id
+        WHERE m.session_id = $1
+          AND m.from_did <> $2
+          AND m.created_at > COALESCE(last_read_msg.created_at, 'epoch'::timestamptz)
+        """,
+        session_id,
+        participant_did,
+    )
+
+    return {
+        "session_id": str(session_id),
+        "unread_count": int(unread or 0),
+    }
@@ -1,12 +1,16 @@
 from __future__ import annotations
 
+import hashlib
+import json
 import uuid as uuid_mod
 from datetime import datetime, timezone
-from typing import Literal
+from typing import Any, Literal
 from uuid import UUID
 
+from awid.signing import canonical_json_bytes
+
 from aweb.messaging.contacts import get_contact_addresses, is_address_in_contacts, normalize_owner_dids
-from aweb.service_errors import ForbiddenError, NotFoundError, ServiceError, ValidationError
+from aweb.service_errors import ConflictError, ForbiddenError, NotFoundError, ValidationError
 
 MessagePriority = Literal["low", "normal", "high", "urgent"]
 
@@ -16,6 +20,27 @@ def utc_iso(dt: datetime) -> str:
     return dt.strftime("%Y-%m-%dT%H:%M:%SZ")
 
 
+def attachments_digest(attachments: list[dict[str, Any]]) -> str:
+    """Hex SHA-256 of the canonical JSON of a message's attachments.
+
+    Signed mail carries this as attachments_sha256 so recipients can check
+    the attachments they receive are the ones the sender signed.
+    """
+    return hashlib.sha256(canonical_json_bytes(attachments)).hexdigest()
+
+
+def message_attachments(raw: Any) -> list[dict[str, Any]]:
+    """Decode a messages.attachments_json value; anything but a list is []."""
+    if isinstance(raw, str):
+        try:
+            raw = json.loads(raw)
+        except json.JSONDecodeError:
+            return []
+    if not isinstance(raw, list):
+        return []
+    return [dict(item) for item in raw if isinstance(item, dict)]
+
+
 def _parse_uuid(v: str, *, field_name: str) -> UUID:
     v = str(v).strip()
     if not v:
@@ -188,8 +213,62 @@ async def deliver_message(
     signed_payload: str | None = None,
     created_at: datetime | None = None,
     message_id: UUID | None = None,
+    attachments: list[dict[str, Any]] | None = None,
 ) -> tuple[UUID, datetime]:
     """Deliver a message between identities, not within a team."""
+    delivered_id, delivered_at, _ = await deliver_message_once(
+        db,
+        registry_client=registry_client,
+        recipient_agent=recipient_agent,
+        from_did=from_did,
+        to_did=to_did,
+        from_alias=from_alias,
+        to_alias=to_alias,
+        subject=subject,
+        body=body,
+        priority=priority,
+        sender_address=sender_address,
+        team_id=team_id,
+        from_agent_id=from_agent_id,
+        to_agent_id=to_agent_id,
+        signature=signature,
+        signed_payload=signed_payload,
+        created_at=created_at,
+        message_id=message_id,
+        attachments=attachments,
+    )
+    return delivered_id, delivered_at
+
+
+async def deliver_message_once(
+    db,
+    *,
+    registry_client=None,
+    recipient_agent: dict | None = None,
+    from_did: str,
+    to_did: str,
+    from_alias: str | None,
+    to_alias: str | None,
+    subject: str,
+    body: str,
+    priority: MessagePriority,
+    sender_address: str | None = None,
+    team_id: str | None = None,
+    from_agent_id: str | None = None,
+    to_agent_id: str | None = None,
+    signature: str | None = None,
+    signed_payload: str | None = None,
+    created_at: datetime | None = None,
+    message_id: UUID | None = None,
+    attachments: list[dict[str, Any]] | None = None,
+) -> tuple[UUID, datetime, bool]:
+    """deliver_message, also reporting whether the send was a replay.
+
+    A client-chosen message_id doubles as an idempotency key: a sender
+    retrying a send that already landed gets the stored message back, with
+    True as the third value, and nothing is delivered again. Reusing the ID
+    for a different recipient, subject or body is a ConflictError.
+    """
     sender_did = str(from_did or "").strip()
     recipient_did = str(to_did or "").strip()
     if not sender_did:
@@ -228,8 +307,10 @@ async def deliver_message(
         """
         INSERT INTO {{tables.messages}}
             (message_id, from_did, to_did, from_alias, from_address, to_alias, subject, body,
-             priority, team_id, from_agent_id, to_agent_id, signature, signed_payload, created_at)
-        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+             priority, team_id, from_agent_id, to_agent_id, signature, signed_payload, created_at,
+             attachments_json)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16::jsonb)
+        ON CONFLICT (message_id) DO NOTHING
         RETURNING message_id, created_at
         """,
         message_id,
@@ -247,8 +328,22 @@ async def deliver_message(
         signature,
         signed_payload,
         created_at,
+        json.dumps(attachments) if attachments else None,
     )
-    if not row:
-        raise ServiceError("Failed to create message")
+    if row:
+        return UUID(str(row["message_id"])), row["created_at"], False
 
-    return UUID(str(row["message_id"])), row["created_at"]
+    row = await aweb_db.fetch_one(
+        """
+        SELECT message_id, created_at, to_did, subject, body
+        FROM {{tables.messages}}
+        WHERE message_id = $1 AND from_did = $2
+        """,
+        message_id,
+        sender_did,
+    )
+    if not row:
+        raise ConflictError("message_id is already in use")
+    if (row["to_did"], row["subject"] or "", row["body"]) != (recipient_did, subject or "", body):
+        raise ConflictError("message_id was already used for a different message")
+    return UUID(str(row["message_id"])), row["created_at"], True
@@ -0,0 +1,4 @@
+-- 002_chat_session_subject.sql
+-- Optional conversation label and context shown in chat pending/session lists.
+ALTER TABLE {{tables.chat_sessions}} ADD COLUMN IF NOT EXISTS subject TEXT;
+ALTER TABLE {{tables.chat_sessions}} ADD COLUMN IF NOT EXISTS metadata_json JSONB;
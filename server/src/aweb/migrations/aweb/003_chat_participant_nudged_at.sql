@@ -0,0 +1,3 @@
+-- 003_chat_participant_nudged_at.sql
+-- Last time another participant nudged this one about unread messages.
+ALTER TABLE {{tables.chat_participants}} ADD COLUMN IF NOT EXISTS nudged_at TIMESTAMPTZ;
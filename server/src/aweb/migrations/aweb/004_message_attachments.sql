@@ -0,0 +1,3 @@
+-- 004_message_attachments.sql
+-- Files or links carried alongside a mail body, as a JSON array.
+ALTER TABLE {{tables.messages}} ADD COLUMN IF NOT EXISTS attachments_json JSONB;
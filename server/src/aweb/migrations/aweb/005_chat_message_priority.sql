@@ -0,0 +1,3 @@
+-- 005_chat_message_priority.sql
+-- Sender's urgency hint for a chat message; high and urgent wake recipients.
+ALTER TABLE {{tables.chat_messages}} ADD COLUMN IF NOT EXISTS priority TEXT NOT NULL DEFAULT 'normal';
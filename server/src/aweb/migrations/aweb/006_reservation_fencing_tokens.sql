@@ -0,0 +1,13 @@
+-- 006_reservation_fencing_tokens.sql
+-- Monotonic fencing token per lock. The counter lives outside the
+-- reservations table because releasing a lock deletes its row, and the next
+-- holder must still get a larger token than every earlier one.
+ALTER TABLE {{tables.reservations}} ADD COLUMN IF NOT EXISTS fencing_token BIGINT;
+
+CREATE TABLE IF NOT EXISTS {{tables.reservation_fencing}} (
+    team_id      TEXT NOT NULL,
+    resource_key TEXT NOT NULL,
+    last_token   BIGINT NOT NULL,
+
+    PRIMARY KEY (team_id, resource_key)
+);
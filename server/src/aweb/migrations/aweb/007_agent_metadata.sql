@@ -0,0 +1,3 @@
+-- Free-form self-description an agent reports at connect time (model,
+-- capabilities, version). NULL means the agent never sent any.
+ALTER TABLE {{tables.agents}} ADD COLUMN IF NOT EXISTS metadata_json JSONB;
@@ -0,0 +1,4 @@
+-- The lease length the holder last asked for, so a piggybacked renewal
+-- (X-Aweb-Renew-Reservations) extends a lock by its own TTL rather than a
+-- fixed one. NULL on locks taken before this column existed.
+ALTER TABLE {{tables.reservations}} ADD COLUMN IF NOT EXISTS ttl_seconds INTEGER;
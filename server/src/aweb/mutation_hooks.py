@@ -32,6 +32,7 @@ from .events import (
     TeamReservationAcquiredEvent,
     TeamReservationReleasedEvent,
     TeamReservationRenewedEvent,
+    TeamReservationTransferredEvent,
     TeamTaskClaimedEvent,
     TeamTaskCreatedEvent,
     TeamTaskStatusChangedEvent,
@@ -595,6 +596,7 @@ def _translate_team_event(event_type: str, ctx: dict):
             from_alias=str(ctx.get("from_alias", "")).strip(),
             to_aliases=[str(alias).strip() for alias in to_aliases if str(alias).strip()],
             preview=str(ctx.get("preview", "") or ""),
+            priority=str(ctx.get("priority", "normal") or "normal"),
         )
 
     if event_type == "task.created":
@@ -674,4 +676,15 @@ def _translate_team_event(event_type: str, ctx: dict):
             paths=_paths_from_context(ctx),
         )
 
+    if event_type == "reservation.transferred":
+        team_id = _team_id_from_context(ctx)
+        if not team_id:
+            return None
+        return TeamReservationTransferredEvent(
+            team_id=team_id,
+            alias=str(ctx.get("alias", "")).strip(),
+            previous_alias=str(ctx.get("previous_alias", "")).strip(),
+            paths=_paths_from_context(ctx),
+        )
+
     return None
@@ -0,0 +1,94 @@
+"""Reservation renewals piggybacked on ordinary requests.
+
+A client holding locks during long work can list them in the
+X-Aweb-Renew-Reservations header. Every team-authenticated request that
+carries it extends those of the listed locks the caller still holds, so the
+locks stay alive while the agent is doing anything at all.
+
+Each renewal extends a lock by its own TTL, the ttl_seconds its holder last
+acquired or renewed it with, counted from the request. This lengthens the
+lease of a lock the agent keeps using: it now lives until one TTL after the
+agent's last request that listed it, not one TTL after the last explicit
+renew. A holder that crashes loses the lock one TTL after its final request.
+
+The renewal is best effort: it never fails the request it rides on. Locks
+the caller no longer holds are skipped rather than taken back, and a header
+that cannot be parsed is ignored and logged.
+"""
+
+from __future__ import annotations
+
+import logging
+from datetime import datetime, timezone
+from typing import Optional
+from urllib.parse import unquote
+from uuid import UUID
+
+logger = logging.getLogger(__name__)
+
+RENEW_RESERVATIONS_HEADER = "X-Aweb-Renew-Reservations"
+MAX_RENEWED_RESERVATIONS = 32
+MAX_RESOURCE_KEY_LENGTH = 4096
+
+
+def parse_renew_reservations_header(value: str) -> Optional[list[str]]:
+    """Return the resource keys in a header value, or None if it is invalid.
+
+    The value is a comma-separated list of percent-encoded keys.
+    """
+    keys: list[str] = []
+    for part in value.split(","):
+        part = part.strip()
+        if not part:
+            continue
+        key = unquote(part)
+        if len(key) > MAX_RESOURCE_KEY_LENGTH:
+            return None
+        if key not in keys:
+            keys.append(key)
+    if len(keys) > MAX_RENEWED_RESERVATIONS:
+        return None
+    return keys
+
+
+async def renew_reservations_from_header(aweb_db, *, team_id: str, agent_id: str, header_value: Optional[str]) -> list[str]:
+    """Extend the caller's live locks named in header_value.
+
+    Each lock is extended to now plus its own TTL. Locks taken before the
+    TTL was stored use their original lease, expires_at - acquired_at. An
+    expiry only moves forward, so a lock renewed further ahead keeps its
+    expiry. Returns the keys renewed.
+    """
+    if not header_value:
+        return []
+    keys = parse_renew_reservations_header(header_value)
+    if keys is None:
+        logger.warning("Ignoring invalid %s header from agent %s", RENEW_RESERVATIONS_HEADER, agent_id)
+        return []
+    if not keys:
+        return []
+
+    now = datetime.now(timezone.utc)
+    try:
+        rows = await aweb_db.fetch_all(
+            """
+            UPDATE {{tables.reservations}}
+            SET expires_at = GREATEST(
+                expires_at,
+                $4 + COALESCE(make_interval(secs => ttl_seconds), expires_at - acquired_at)
+            )
+            WHERE team_id = $1
+              AND holder_agent_id = $2
+              AND resource_key = ANY($3::text[])
+              AND expires_at > $4
+            RETURNING resource_key
+            """,
+            team_id,
+            UUID(agent_id),
+            keys,
+            now,
+        )
+    except Exception:
+        logger.warning("Failed to renew reservations for agent %s", agent_id, exc_info=True)
+        return []
+    return [row["resource_key"] for row in rows]
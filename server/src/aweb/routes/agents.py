@@ -1,16 +1,22 @@
 from __future__ import annotations
 
-from typing import Literal, Optional
+import asyncio
+import json
+from datetime import datetime, timezone
+from typing import Any, Literal, Optional
 from uuid import UUID
 
-from fastapi import APIRouter, Depends, HTTPException, Request
+from fastapi import APIRouter, Depends, HTTPException, Request, Response
+from fastapi.responses import StreamingResponse
 from pydantic import BaseModel, Field
 
 from aweb.alias_allocator import suggest_next_name_prefix
 from aweb.deps import get_db, get_redis
 from aweb.team_auth_deps import TeamIdentity, get_team_identity
 
+from ..lifecycle import LifecycleActor, LifecycleCascadeRequest, apply_lifecycle_cascade
 from ..presence import list_agent_presences_by_workspace_ids, update_agent_presence
+from ._etag import etag_matches, payload_etag
 
 router = APIRouter(prefix="/v1/agents", tags=["agents"])
 
@@ -37,6 +43,7 @@ class AgentView(BaseModel):
     last_seen: Optional[str] = None
     online: bool = False
     lifetime: str = "ephemeral"
+    metadata: dict[str, Any] = Field(default_factory=dict)
 
 
 class ListAgentsResponse(BaseModel):
@@ -73,12 +80,28 @@ class PatchWorkspaceResponse(BaseModel):
     human_name: Optional[str] = None
 
 
+class DeleteAgentResponse(BaseModel):
+    agent_id: str
+    alias: str
+    deleted_at: str
+
+
 class SendControlSignalRequest(BaseModel):
     model_config = {"extra": "forbid"}
 
     signal: Literal["pause", "resume", "interrupt"]
 
 
+def _agent_metadata(raw: Any) -> dict[str, Any]:
+    """Decode an agents.metadata_json value; anything but an object is {}."""
+    if isinstance(raw, str):
+        try:
+            raw = json.loads(raw)
+        except json.JSONDecodeError:
+            return {}
+    return dict(raw) if isinstance(raw, dict) else {}
+
+
 # ---------------------------------------------------------------------------
 # Endpoints
 # ---------------------------------------------------------------------------
@@ -117,25 +140,19 @@ async def suggest_alias_prefix(
     )
 
 
-@router.get("", response_model=ListAgentsResponse)
-async def list_agents(
-    request: Request,
-    db=Depends(get_db),
-    redis=Depends(get_redis),
-    identity: TeamIdentity = Depends(get_team_identity),
-) -> ListAgentsResponse:
-    """List agents in the current team."""
+async def _team_agent_views(db, redis, *, team_id: str) -> list[AgentView]:
+    """Build the roster of team_id, with presence from Redis."""
     aweb_db = db.get_manager("aweb")
 
     rows = await aweb_db.fetch_all(
         """
         SELECT agent_id, alias, did_key, did_aw, address,
-               human_name, agent_type, role, lifetime, status
+               human_name, agent_type, role, lifetime, status, metadata_json
         FROM {{tables.agents}}
         WHERE team_id = $1 AND deleted_at IS NULL
         ORDER BY alias
         """,
-        identity.team_id,
+        team_id,
     )
 
     # Workspace context for each agent
@@ -151,7 +168,7 @@ async def list_agents(
         LEFT JOIN {{tables.repos}} r ON w.repo_id = r.id AND r.deleted_at IS NULL
         WHERE w.team_id = $1 AND w.deleted_at IS NULL
         """,
-        identity.team_id,
+        team_id,
     )
     context_by_agent = {str(r["agent_id"]): r for r in context_rows}
 
@@ -195,10 +212,96 @@ async def list_agents(
                 last_seen=last_seen,
                 online=online,
                 lifetime=str(r.get("lifetime") or "ephemeral"),
+                metadata=_agent_metadata(r.get("metadata_json")),
             )
         )
 
-    return ListAgentsResponse(team_id=identity.team_id, agents=agents)
+    return agents
+
+
+@router.get("", response_model=ListAgentsResponse)
+async def list_agents(
+    request: Request,
+    response: Response,
+    db=Depends(get_db),
+    redis=Depends(get_redis),
+    identity: TeamIdentity = Depends(get_team_identity),
+) -> ListAgentsResponse:
+    """List agents in the current team."""
+    agents = await _team_agent_views(db, redis, team_id=identity.team_id)
+    result = ListAgentsResponse(team_id=identity.team_id, agents=agents)
+    etag = payload_etag(result.model_dump())
+    if etag_matches(request.headers.get("If-None-Match"), etag):
+        return Response(status_code=304, headers={"ETag": etag})
+    response.headers["ETag"] = etag
+    return result
+
+
+# How often the presence stream re-reads the roster, how long it stays
+# open before the client reconnects, and how often it sends a keepalive.
+PRESENCE_STREAM_POLL_SECONDS = 5.0
+MAX_PRESENCE_STREAM_DURATION = 300.0
+PRESENCE_STREAM_KEEPALIVE_SECONDS = 30.0
+
+
+def _format_presence_sse(event_name: str, payload: dict[str, Any]) -> str:
+    return f"event: {event_name}\ndata: {json.dumps(payload)}\n\n"
+
+
+async def _sse_presence_events(*, request: Request, db, redis, team_id: str):
+    loop = asyncio.get_running_loop()
+    started = loop.time()
+    last_keepalive = started
+
+    agents = await _team_agent_views(db, redis, team_id=team_id)
+    online = {a.agent_id: a for a in agents if a.online}
+    yield _format_presence_sse("snapshot", {"agents": [a.model_dump() for a in online.values()]})
+
+    while loop.time() - started < MAX_PRESENCE_STREAM_DURATION:
+        await asyncio.sleep(PRESENCE_STREAM_POLL_SECONDS)
+        if await request.is_disconnected():
+            return
+
+        agents = await _team_agent_views(db, redis, team_id=team_id)
+        current = {a.agent_id: a for a in agents if a.online}
+        for agent_id, agent in current.items():
+            previous = online.get(agent_id)
+            if previous is None:
+                yield _format_presence_sse("online", agent.model_dump())
+            elif agent.last_seen != previous.last_seen:
+                yield _format_presence_sse("last_seen", agent.model_dump())
+        for agent_id, agent in online.items():
+            if agent_id not in current:
+                offline = next((a for a in agents if a.agent_id == agent_id), agent)
+                yield _format_presence_sse("offline", offline.model_dump())
+        online = current
+
+        now = loop.time()
+        if now - last_keepalive >= PRESENCE_STREAM_KEEPALIVE_SECONDS:
+            yield ": keepalive\n\n"
+            last_keepalive = now
+
+
+@router.get("/presence/stream")
+async def presence_stream(
+    request: Request,
+    db=Depends(get_db),
+    redis=Depends(get_redis),
+    identity: TeamIdentity = Depends(get_team_identity),
+) -> StreamingResponse:
+    """Stream presence changes in the current team.
+
+    The stream opens with a ``snapshot`` event listing the agents online at
+    connect time, then sends ``online``, ``offline`` and ``last_seen``
+    events carrying the agent's roster entry. It ends after
+    MAX_PRESENCE_STREAM_DURATION; clients reconnect and diff the next
+    snapshot against what they already know.
+    """
+    return StreamingResponse(
+        _sse_presence_events(request=request, db=db, redis=redis, team_id=identity.team_id),
+        media_type="text/event-stream",
+        headers={"Cache-Control": "no-cache", "X-Accel-Buffering": "no"},
+    )
 
 
 @router.post("/heartbeat", response_model=HeartbeatResponse)
@@ -331,3 +434,78 @@ async def send_control_signal(
         payload.signal,
     )
     return {"signal_id": str(result["signal_id"]), "signal": payload.signal}
+
+
+@router.delete("/{agent_id}", response_model=DeleteAgentResponse)
+async def delete_agent(
+    agent_id: str,
+    db=Depends(get_db),
+    redis=Depends(get_redis),
+    identity: TeamIdentity = Depends(get_team_identity),
+) -> DeleteAgentResponse:
+    """Remove an agent from the team along with its workspaces.
+
+    Its claims, locks and chat participation are released through the same
+    lifecycle cascade workspace deletion uses. An ephemeral agent is marked
+    deleted; a persistent one is archived, since its identity outlives the
+    team membership.
+    """
+    try:
+        target_id = str(UUID(agent_id))
+    except ValueError:
+        raise HTTPException(status_code=422, detail="agent_id must be a valid UUID")
+
+    aweb_db = db.get_manager("aweb")
+    agent = await aweb_db.fetch_one(
+        """
+        SELECT agent_id, alias, lifetime
+        FROM {{tables.agents}}
+        WHERE agent_id = $1 AND team_id = $2 AND deleted_at IS NULL
+        """,
+        UUID(target_id),
+        identity.team_id,
+    )
+    if agent is None:
+        raise HTTPException(status_code=404, detail="Agent not found")
+
+    persistent = agent["lifetime"] == "persistent"
+    deleted_at = datetime.now(timezone.utc)
+    result = await apply_lifecycle_cascade(
+        aweb_db,
+        redis,
+        LifecycleCascadeRequest(
+            operation="archive_persistent_agent" if persistent else "agent_deleted_cascade",
+            actor=LifecycleActor(
+                actor_id=identity.agent_id,
+                actor_type="agent",
+                authority="team_identity",
+            ),
+            team_id=identity.team_id,
+            target_agent_id=target_id,
+            workspace_scope="all_for_agent",
+            require_lifetime="persistent" if persistent else "ephemeral",
+            deleted_at=deleted_at,
+            mark_ephemeral_agent_deleted=not persistent,
+        ),
+    )
+    if result.errors:
+        raise HTTPException(status_code=409, detail=result.errors[0].message)
+    if not persistent and not result.identity_deleted:
+        # The cascade marks an ephemeral agent deleted through its
+        # workspaces; one with none left is marked here.
+        await aweb_db.execute(
+            """
+            UPDATE {{tables.agents}}
+            SET deleted_at = $2, status = 'deleted'
+            WHERE agent_id = $1 AND team_id = $3 AND deleted_at IS NULL
+            """,
+            UUID(target_id),
+            deleted_at,
+            identity.team_id,
+        )
+
+    return DeleteAgentResponse(
+        agent_id=target_id,
+        alias=agent["alias"],
+        deleted_at=deleted_at.isoformat(),
+    )
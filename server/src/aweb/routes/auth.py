@@ -0,0 +1,65 @@
+"""API key rotation.
+
+aweb does not store API keys itself: a hosting operator issues them and
+fronts aweb with a proxy that authenticates the key and forwards the caller
+as trusted proxy headers (see ``aweb.internal_auth``). Rotation is therefore
+delegated to the embedding application through ``app.state.rotate_api_key``,
+the same way mutation callbacks are delegated through ``app.state.on_mutation``.
+"""
+
+from __future__ import annotations
+
+import logging
+from typing import Optional
+
+from fastapi import APIRouter, HTTPException, Request
+from pydantic import BaseModel
+
+from ..internal_auth import parse_internal_auth_context
+
+logger = logging.getLogger(__name__)
+
+router = APIRouter(prefix="/v1/auth", tags=["auth"])
+
+
+class RotateAPIKeyResponse(BaseModel):
+    api_key: str
+    previous_key_expires_at: Optional[str] = None
+    grace_seconds: Optional[int] = None
+
+
+@router.post("/rotate")
+async def rotate_api_key(request: Request) -> RotateAPIKeyResponse:
+    """Issue a new API key for the calling key and start the old one's grace window.
+
+    The embedding application registers ``app.state.rotate_api_key`` as an
+    async callable taking ``team_id``, ``api_key_id`` and ``actor_id`` and
+    returning a mapping with ``api_key`` and, optionally,
+    ``previous_key_expires_at`` and ``grace_seconds``. A server without that
+    callback has no API keys to rotate and answers 501.
+    """
+    rotate = getattr(request.app.state, "rotate_api_key", None)
+    if rotate is None:
+        raise HTTPException(
+            status_code=501,
+            detail="This server does not issue API keys; rotate them with the hosting operator",
+        )
+
+    internal = parse_internal_auth_context(request)
+    if internal is None or internal["principal_type"] != "k":
+        raise HTTPException(status_code=403, detail="Only an API key can be rotated; authenticate with the key to rotate")
+
+    result = await rotate(
+        team_id=internal["team_id"],
+        api_key_id=internal["principal_id"],
+        actor_id=internal["actor_id"],
+    )
+    api_key = str((result or {}).get("api_key") or "").strip()
+    if not api_key:
+        logger.error("rotate_api_key callback returned no api_key for key %s", internal["principal_id"])
+        raise HTTPException(status_code=502, detail="API key rotation failed")
+    return RotateAPIKeyResponse(
+        api_key=api_key,
+        previous_key_expires_at=result.get("previous_key_expires_at"),
+        grace_seconds=result.get("grace_seconds"),
+    )
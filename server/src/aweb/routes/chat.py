@@ -40,11 +40,14 @@ from aweb.messaging.chat import (
     get_message_history,
     get_pending_conversations,
     mark_messages_read,
+    mark_messages_unread,
+    nudge_unread_participants,
     resolve_agent_by_did,
     send_in_session,
+    session_metadata,
 )
 from aweb.messaging.contacts import get_contact_addresses, is_address_in_contacts
-from aweb.messaging.messages import evaluate_messaging_policy, utc_iso as _utc_iso
+from aweb.messaging.messages import MessagePriority, evaluate_messaging_policy, utc_iso as _utc_iso
 from aweb.messaging.waiting import (
     get_waiting_agents,
     get_waiting_agents_by_session,
@@ -556,6 +559,11 @@ class CreateSessionRequest(BaseModel):
     from_did: str | None = Field(default=None, max_length=256)
     signature: str | None = Field(default=None, max_length=512)
     signed_payload: str | None = None
+    # Unsigned urgency hint; high and urgent wake recipients immediately.
+    priority: MessagePriority = "normal"
+    # Unsigned conversation label/context; the latest sender to set them wins.
+    subject: str | None = Field(default=None, max_length=200)
+    metadata: dict[str, Any] | None = None
 
     @field_validator("to_aliases", "to_dids", "to_addresses")
     @classmethod
@@ -718,6 +726,7 @@ async def create_or_send(
             body=payload.message,
             reply_to=uuid_mod.UUID(payload.reply_to) if payload.reply_to is not None else None,
             leaving=payload.leaving,
+            priority=payload.priority,
             signature=payload.signature,
             signed_payload=payload.signed_payload,
             created_at=msg_created_at,
@@ -745,6 +754,20 @@ async def create_or_send(
             UUID(actor_agent_id) if actor_agent_id else None,
         )
 
+    subject = (payload.subject or "").strip() or None
+    if subject is not None or payload.metadata:
+        await aweb_db.execute(
+            """
+            UPDATE {{tables.chat_sessions}}
+            SET subject = COALESCE($2, subject),
+                metadata_json = COALESCE($3::jsonb, metadata_json)
+            WHERE session_id = $1
+            """,
+            session_id,
+            subject,
+            json.dumps(payload.metadata) if payload.metadata else None,
+        )
+
     participants_rows = await aweb_db.fetch_all(
         """
         SELECT did, alias, address
@@ -785,6 +808,7 @@ async def create_or_send(
                 if (row.get("did") or "").strip() != actor_did
             ],
             "preview": payload.message[:80],
+            "priority": payload.priority,
         },
     )
 
@@ -938,6 +962,8 @@ async def pending(
                 "last_activity": _utc_iso(item["last_activity"]) if item["last_activity"] else "",
                 "sender_waiting": len(waiting) > 0,
                 "time_remaining_seconds": time_remaining_seconds,
+                "subject": item.get("subject") or "",
+                "metadata": item.get("metadata") or {},
             }
         )
 
@@ -946,6 +972,13 @@ async def pending(
 
 class HistoryResponse(BaseModel):
     messages: list[dict[str, Any]]
+    # Echoes the oldest_first query parameter so clients can tell whether
+    # the server honored it. Always true when since or after_message_id is
+    # set, since tailing must not skip the oldest new messages.
+    oldest_first: bool = False
+    # With oldest_first, the last message's ID: pass it back as
+    # after_message_id to read on from there.
+    next_cursor: str | None = None
 
 
 @router.get("/sessions/{session_id}/messages", response_model=HistoryResponse)
@@ -955,14 +988,67 @@ async def history(
     unread_only: bool = Query(False),
     limit: int = Query(200, ge=1, le=2000),
     message_id: str | None = Query(default=None),
+    since: datetime | None = Query(default=None),
+    after_message_id: str | None = Query(default=None),
+    oldest_first: bool = Query(False),
+    db=Depends(get_db),
+    auth: MessagingAuth = Depends(get_messaging_auth),
+) -> HistoryResponse:
+    del request
+    after_uuid: UUID | None = None
+    if after_message_id is not None and after_message_id.strip():
+        try:
+            after_uuid = UUID(after_message_id.strip())
+        except ValueError:
+            raise HTTPException(status_code=422, detail="Invalid after_message_id format")
+    return await _history_response(
+        db,
+        auth,
+        session_id,
+        unread_only=unread_only,
+        limit=limit,
+        message_id=message_id,
+        since=since,
+        after_message_id=after_uuid,
+        oldest_first=oldest_first,
+    )
+
+
+@router.get("/sessions/{session_id}/messages/search", response_model=HistoryResponse)
+async def search_history(
+    request: Request,
+    session_id: str = Path(..., min_length=1),
+    q: str = Query(..., min_length=1, max_length=500),
+    limit: int = Query(200, ge=1, le=2000),
     db=Depends(get_db),
     auth: MessagingAuth = Depends(get_messaging_auth),
 ) -> HistoryResponse:
+    """Messages in the session whose body contains ``q``, ignoring case."""
     del request
+    return await _history_response(db, auth, session_id, limit=limit, body_contains=q)
+
+
+async def _history_response(
+    db,
+    auth: MessagingAuth,
+    session_id: str,
+    *,
+    unread_only: bool = False,
+    limit: int,
+    message_id: str | None = None,
+    since: datetime | None = None,
+    after_message_id: UUID | None = None,
+    body_contains: str | None = None,
+    oldest_first: bool = False,
+) -> HistoryResponse:
     actor_dids = _actor_dids(auth)
     owner_dids = _actor_dids(auth)
     if not owner_dids:
         raise HTTPException(status_code=401, detail="Authenticated identity is missing a routing DID")
+    # A tailing read keeps the earliest new messages when more than limit
+    # arrived, so the next read carries on where this one stopped.
+    if since is not None or after_message_id is not None:
+        oldest_first = True
 
     try:
         session_uuid = UUID(session_id.strip())
@@ -995,6 +1081,10 @@ async def history(
         unread_only=unread_only,
         limit=limit,
         message_id=message_id,
+        since=since,
+        after_message_id=after_message_id,
+        body_contains=body_contains,
+        oldest_first=oldest_first,
     )
     contact_addrs = await get_contact_addresses(db, owner_dids=owner_dids)
     identity_map = await lookup_identity_metadata_by_did(
@@ -1018,6 +1108,7 @@ async def history(
                 "body": msg["body"],
                 "timestamp": _utc_iso(msg["created_at"]),
                 "sender_leaving": msg["sender_leaving"],
+                "priority": msg.get("priority") or "normal",
                 "reply_to": msg.get("reply_to"),
                 "to_address": _chat_to_address(participant_rows, from_did=from_did),
                 "from_did": from_did or None,
@@ -1028,7 +1119,8 @@ async def history(
             }
         )
 
-    return HistoryResponse(messages=history_items)
+    next_cursor = history_items[-1]["message_id"] if oldest_first and history_items else None
+    return HistoryResponse(messages=history_items, oldest_first=oldest_first, next_cursor=next_cursor)
 
 
 class MarkReadRequest(BaseModel):
@@ -1087,6 +1179,52 @@ async def mark_read(
     return {"success": True, "messages_marked": result["messages_marked"]}
 
 
+class MarkUnreadRequest(BaseModel):
+    model_config = ConfigDict(extra="forbid")
+
+    from_message_id: str = Field(..., min_length=1)
+
+    @field_validator("from_message_id")
+    @classmethod
+    def _validate_message_id(cls, v: str) -> str:
+        return _parse_uuid(v, field="from_message_id")
+
+
+class MarkUnreadResponse(BaseModel):
+    session_id: str
+    unread_count: int
+
+
+@router.post("/sessions/{session_id}/unread", response_model=MarkUnreadResponse)
+async def mark_unread(
+    session_id: str,
+    payload: MarkUnreadRequest,
+    db=Depends(get_db),
+    auth: MessagingAuth = Depends(get_messaging_auth),
+) -> MarkUnreadResponse:
+    actor_dids = _actor_dids(auth)
+    if not actor_dids:
+        raise HTTPException(status_code=401, detail="Authenticated identity is missing a routing DID")
+    session_uuid = UUID(session_id.strip())
+
+    aweb_db = db.get_manager("aweb")
+    sess = await aweb_db.fetch_one("SELECT 1 FROM {{tables.chat_sessions}} WHERE session_id = $1", session_uuid)
+    if not sess:
+        raise HTTPException(status_code=404, detail="Session not found")
+
+    actor_did = await _resolve_session_actor_did(db, session_id=session_uuid, actor_dids=actor_dids)
+    if not actor_did:
+        raise HTTPException(status_code=404, detail="Session not found")
+
+    result = await mark_messages_unread(
+        db,
+        session_id=session_uuid,
+        participant_did=actor_did,
+        from_message_id=payload.from_message_id,
+    )
+    return MarkUnreadResponse(**result)
+
+
 async def _close_session_pubsub(pubsub: PubSub | None, channel: str) -> None:
     if pubsub is None:
         return
@@ -1165,7 +1303,7 @@ async def _sse_events(
             recent = await aweb_db.fetch_all(
                 """
                 SELECT message_id, from_agent_id, from_alias, from_address, body, created_at,
-                       sender_leaving, hang_on, reply_to, from_did, signature, signed_payload
+                       sender_leaving, hang_on, reply_to, from_did, signature, signed_payload, priority
                 FROM {{tables.chat_messages}}
                 WHERE session_id = $1 AND created_at > $2
                 ORDER BY created_at ASC
@@ -1198,6 +1336,7 @@ async def _sse_events(
                     "sender_waiting": from_did in waiting,
                     "hang_on": is_hang_on,
                     "extends_wait_seconds": HANG_ON_EXTENSION_SECONDS if is_hang_on else 0,
+                    "priority": row.get("priority") or "normal",
                     "reply_to": str(row["reply_to"]) if row.get("reply_to") is not None else None,
                     "timestamp": _utc_iso(row["created_at"]),
                     "to_address": _chat_to_address(participant_rows, from_did=from_did),
@@ -1259,7 +1398,7 @@ async def _sse_events(
                 new_msgs = await aweb_db.fetch_all(
                     """
                     SELECT message_id, from_agent_id, from_alias, from_address, body, created_at,
-                           sender_leaving, hang_on, reply_to, from_did, signature, signed_payload
+                           sender_leaving, hang_on, reply_to, from_did, signature, signed_payload, priority
                     FROM {{tables.chat_messages}}
                     WHERE session_id = $1 AND created_at > $2
                     ORDER BY created_at ASC
@@ -1292,6 +1431,7 @@ async def _sse_events(
                         "sender_waiting": from_did in sender_waiting,
                         "hang_on": is_hang_on,
                         "extends_wait_seconds": HANG_ON_EXTENSION_SECONDS if is_hang_on else 0,
+                        "priority": row.get("priority") or "normal",
                         "reply_to": str(row["reply_to"]) if row.get("reply_to") is not None else None,
                         "timestamp": _utc_iso(row["created_at"]),
                         "to_address": _chat_to_address(participant_rows, from_did=from_did),
@@ -1410,6 +1550,7 @@ class SendMessageRequest(BaseModel):
 
     body: str = Field(..., min_length=1)
     hang_on: bool = False
+    priority: MessagePriority = "normal"
     reply_to: str | None = None
     message_id: str | None = None
     timestamp: str | None = None
@@ -1501,6 +1642,7 @@ async def send_message(
             body=payload.body,
             reply_to=uuid_mod.UUID(payload.reply_to) if payload.reply_to is not None else None,
             hang_on=payload.hang_on,
+            priority=payload.priority,
             signature=payload.signature,
             signed_payload=payload.signed_payload,
             created_at=msg_created_at,
@@ -1526,6 +1668,7 @@ async def send_message(
             "from_did_aw": (auth.did_aw or "").strip() or None,
             "to_aliases": [row["alias"] for row in recipient_rows],
             "preview": payload.body[:80],
+            "priority": payload.priority,
         },
     )
 
@@ -1536,6 +1679,128 @@ async def send_message(
     )
 
 
+class LeaveSessionResponse(BaseModel):
+    session_id: str
+    message_id: str
+    # True once every participant's latest message in the session is a leave.
+    session_closed: bool
+
+
+@router.post("/sessions/{session_id}/leave", response_model=LeaveSessionResponse)
+async def leave_session(
+    request: Request,
+    session_id: str = Path(..., min_length=1),
+    db=Depends(get_db),
+    auth: MessagingAuth = Depends(get_messaging_auth),
+) -> LeaveSessionResponse:
+    """Leave a conversation without a final message.
+
+    The leave is recorded as an empty message with sender_leaving set, so the
+    other party's stream sees the same event as after send-and-leave.
+    """
+    actor_dids = _actor_dids(auth)
+    if not actor_dids:
+        raise HTTPException(status_code=401, detail="Authenticated identity is missing a routing DID")
+    actor_agent = await _resolve_actor_agent(db, actor_dids)
+    actor_agent_id = auth.agent_id or (str(actor_agent["agent_id"]) if actor_agent else None)
+
+    try:
+        session_uuid = UUID(session_id.strip())
+    except Exception:
+        raise HTTPException(status_code=422, detail="Invalid id format")
+
+    aweb_db = db.get_manager("aweb")
+    sess = await aweb_db.fetch_one("SELECT 1 FROM {{tables.chat_sessions}} WHERE session_id = $1", session_uuid)
+    if not sess:
+        raise HTTPException(status_code=404, detail="Session not found")
+
+    actor_did = await _resolve_session_actor_did(db, session_id=session_uuid, actor_dids=actor_dids)
+    if not actor_did:
+        raise HTTPException(status_code=404, detail="Session not found")
+    recipient_rows = await _resolve_session_recipient_rows(db, session_id=session_uuid, actor_dids=actor_dids)
+
+    msg_row = await send_in_session(
+        db,
+        session_id=session_uuid,
+        sender_did=actor_did,
+        sender_agent_id=actor_agent_id,
+        sender_address=_sender_address(auth),
+        body="",
+        leaving=True,
+    )
+    if msg_row is None:
+        raise HTTPException(status_code=500, detail="Failed to leave session")
+
+    await fire_mutation_hook(
+        request,
+        "chat.message_sent",
+        {
+            "team_id": auth.team_id,
+            "session_id": str(session_uuid),
+            "message_id": str(msg_row["message_id"]),
+            "from_agent_id": actor_agent_id,
+            "from_alias": auth.alias or "",
+            "from_did": actor_did,
+            "from_did_aw": (auth.did_aw or "").strip() or None,
+            "to_aliases": [row["alias"] for row in recipient_rows],
+            "preview": "",
+        },
+    )
+
+    participant_rows = await aweb_db.fetch_all(
+        "SELECT did FROM {{tables.chat_participants}} WHERE session_id = $1",
+        session_uuid,
+    )
+    participant_dids = [str(row["did"]) for row in participant_rows if row.get("did")]
+    left = await _targets_left(db, session_id=session_uuid, target_dids=participant_dids)
+
+    return LeaveSessionResponse(
+        session_id=str(session_uuid),
+        message_id=str(msg_row["message_id"]),
+        session_closed=len(left) >= len(participant_dids),
+    )
+
+
+class NudgeResponse(BaseModel):
+    session_id: str
+    nudged: list[str]
+
+
+@router.post("/sessions/{session_id}/nudge", response_model=NudgeResponse)
+async def nudge_session(
+    request: Request,
+    session_id: str = Path(..., min_length=1),
+    db=Depends(get_db),
+    auth: MessagingAuth = Depends(get_messaging_auth),
+) -> NudgeResponse:
+    """Remind participants who have not read the latest message.
+
+    Each unread participant's pending entry is flagged so their event stream
+    re-emits it; the response names who was nudged.
+    """
+    del request
+    actor_dids = _actor_dids(auth)
+    if not actor_dids:
+        raise HTTPException(status_code=401, detail="Authenticated identity is missing a routing DID")
+
+    try:
+        session_uuid = UUID(session_id.strip())
+    except Exception:
+        raise HTTPException(status_code=422, detail="Invalid id format")
+
+    aweb_db = db.get_manager("aweb")
+    sess = await aweb_db.fetch_one("SELECT 1 FROM {{tables.chat_sessions}} WHERE session_id = $1", session_uuid)
+    if not sess:
+        raise HTTPException(status_code=404, detail="Session not found")
+
+    actor_did = await _resolve_session_actor_did(db, session_id=session_uuid, actor_dids=actor_dids)
+    if not actor_did:
+        raise HTTPException(status_code=404, detail="Session not found")
+
+    nudged = await nudge_unread_participants(db, session_id=session_uuid, sender_did=actor_did)
+    return NudgeResponse(session_id=str(session_uuid), nudged=nudged)
+
+
 class SessionListItem(BaseModel):
     session_id: str
     participants: list[str]
@@ -1543,6 +1808,12 @@ class SessionListItem(BaseModel):
     participant_addresses: list[str] = Field(default_factory=list)
     created_at: str
     sender_waiting: bool = False
+    subject: str = ""
+    metadata: dict[str, Any] = Field(default_factory=dict)
+    last_activity: str = ""
+    last_message: str = ""
+    last_from: str = ""
+    unread_count: int = 0
 
 
 class SessionListResponse(BaseModel):
@@ -1567,15 +1838,38 @@ async def list_sessions(
     for participant_did in actor_dids:
         rows = await aweb_db.fetch_all(
             """
-            SELECT s.session_id, s.created_at,
+            SELECT s.session_id, s.created_at, s.subject, s.metadata_json,
                    array_agg(p2.alias ORDER BY p2.alias) AS participants,
-                   array_agg(p2.did ORDER BY p2.alias) AS participant_dids
+                   array_agg(p2.did ORDER BY p2.alias) AS participant_dids,
+                   lm.body AS last_message,
+                   lm.from_alias AS last_from,
+                   lm.created_at AS last_activity,
+                   COALESCE(unread.cnt, 0) AS unread_count
             FROM {{tables.chat_sessions}} s
             JOIN {{tables.chat_participants}} p
               ON p.session_id = s.session_id AND p.did = $1
             JOIN {{tables.chat_participants}} p2
               ON p2.session_id = s.session_id
-            GROUP BY s.session_id, s.created_at
+            LEFT JOIN LATERAL (
+                SELECT body, from_alias, created_at
+                FROM {{tables.chat_messages}}
+                WHERE session_id = s.session_id
+                ORDER BY created_at DESC
+                LIMIT 1
+            ) lm ON TRUE
+            LEFT JOIN {{tables.chat_read_receipts}} rr
+              ON rr.session_id = s.session_id AND rr.did = $1
+            LEFT JOIN {{tables.chat_messages}} last_read_msg
+              ON last_read_msg.message_id = rr.last_read_message_id
+            LEFT JOIN LATERAL (
+                SELECT COUNT(*)::int AS cnt
+                FROM {{tables.chat_messages}} m
+                WHERE m.session_id = s.session_id
+                  AND m.from_did <> $1
+                  AND m.created_at > COALESCE(last_read_msg.created_at, 'epoch'::timestamptz)
+            ) unread ON TRUE
+            GROUP BY s.session_id, s.created_at, s.subject, s.metadata_json,
+                     lm.body, lm.from_alias, lm.created_at, unread.cnt
             ORDER BY s.created_at DESC
             """,
             participant_did,
@@ -1638,6 +1932,12 @@ async def list_sessions(
                 ],
                 created_at=_utc_iso(row["created_at"]),
                 sender_waiting=len(waiting) > 0,
+                subject=row.get("subject") or "",
+                metadata=session_metadata(row.get("metadata_json")),
+                last_activity=_utc_iso(row["last_activity"]) if row.get("last_activity") else "",
+                last_message=row.get("last_message") or "",
+                last_from=row.get("last_from") or "",
+                unread_count=int(row.get("unread_count") or 0),
             )
         )
 
@@ -6,6 +6,7 @@ verified certificate, creates or updates a workspace.
 
 from __future__ import annotations
 
+import json
 import uuid
 from typing import Any
 
@@ -13,7 +14,7 @@ import asyncpg
 from fastapi import APIRouter, Depends, HTTPException, Request
 from pgdbm import AsyncDatabaseManager
 from pgdbm.errors import QueryError
-from pydantic import BaseModel, Field
+from pydantic import BaseModel, Field, field_validator
 
 from awid.team_ids import parse_team_id
 from aweb.coordination.routes.repos import canonicalize_git_url
@@ -22,6 +23,8 @@ from aweb.team_auth_deps import verify_request_certificate
 
 router = APIRouter(prefix="/v1", tags=["connect"])
 
+MAX_AGENT_METADATA_BYTES = 64 * 1024
+
 
 # ---------------------------------------------------------------------------
 # Request / Response models
@@ -35,6 +38,16 @@ class ConnectRequest(BaseModel):
     role: str = Field(default="", max_length=50)
     human_name: str = Field(default="", max_length=64)
     agent_type: str = Field(default="agent", max_length=32)
+    # Agent self-description (model, capabilities, version). Omitted or
+    # empty keeps whatever the agent reported on an earlier connect.
+    metadata: dict[str, Any] | None = None
+
+    @field_validator("metadata")
+    @classmethod
+    def _limit_metadata(cls, value: dict[str, Any] | None) -> dict[str, Any] | None:
+        if value and len(json.dumps(value, separators=(",", ":"))) > MAX_AGENT_METADATA_BYTES:
+            raise ValueError(f"metadata must encode to at most {MAX_AGENT_METADATA_BYTES} bytes of JSON")
+        return value
 
 
 class ConnectResponse(BaseModel):
@@ -86,13 +99,16 @@ async def _ensure_agent(
     human_name: str,
     agent_type: str,
     role: str,
+    metadata: dict[str, Any] | None = None,
 ) -> str:
     """Find or create the agent row. Returns agent_id as string.
 
     did_aw and address come from the certificate's member_did_aw /
     member_address fields. Empty strings are stored as NULL (ephemeral
-    certificates do not carry these fields).
+    certificates do not carry these fields). Empty metadata leaves any
+    previously stored metadata in place.
     """
+    metadata_json = json.dumps(metadata) if metadata else None
     existing_agent = await db.fetch_one(
         """
         SELECT agent_id, alias FROM {{tables.agents}}
@@ -111,7 +127,8 @@ async def _ensure_agent(
             """
             UPDATE {{tables.agents}}
             SET did_aw = $1, address = $2, lifetime = $3, human_name = $4,
-                agent_type = $5, role = $6, status = 'active'
+                agent_type = $5, role = $6, status = 'active',
+                metadata_json = COALESCE($8::jsonb, metadata_json)
             WHERE agent_id = $7
             """,
             did_aw or None,
@@ -121,6 +138,7 @@ async def _ensure_agent(
             agent_type,
             role,
             existing_agent["agent_id"],
+            metadata_json,
         )
         return str(existing_agent["agent_id"])
 
@@ -143,8 +161,8 @@ async def _ensure_agent(
             """
             INSERT INTO {{tables.agents}}
                 (agent_id, team_id, did_key, did_aw, address,
-                 alias, lifetime, human_name, agent_type, role)
-            VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+                 alias, lifetime, human_name, agent_type, role, metadata_json)
+            VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11::jsonb)
             ON CONFLICT (team_id, did_key) WHERE deleted_at IS NULL DO NOTHING
             """,
             agent_id,
@@ -157,6 +175,7 @@ async def _ensure_agent(
             human_name,
             agent_type,
             role,
+            metadata_json,
         )
     except (QueryError, asyncpg.exceptions.UniqueViolationError) as exc:
         if isinstance(exc, QueryError) and not isinstance(exc.__cause__, asyncpg.exceptions.UniqueViolationError):
@@ -179,7 +198,8 @@ async def _ensure_agent(
                 """
                 UPDATE {{tables.agents}}
                 SET did_aw = $1, address = $2, lifetime = $3, human_name = $4,
-                    agent_type = $5, role = $6, status = 'active'
+                    agent_type = $5, role = $6, status = 'active',
+                    metadata_json = COALESCE($8::jsonb, metadata_json)
                 WHERE agent_id = $7
                 """,
                 did_aw or None,
@@ -189,6 +209,7 @@ async def _ensure_agent(
                 agent_type,
                 role,
                 existing_agent["agent_id"],
+                metadata_json,
             )
             return str(existing_agent["agent_id"])
         existing_alias = await db.fetch_one(
@@ -356,6 +377,7 @@ async def connect_agent(
     role: str,
     human_name: str,
     agent_type: str,
+    metadata: dict[str, Any] | None = None,
 ) -> dict[str, Any]:
     """Auto-provision team + agent + workspace from certificate info.
 
@@ -369,6 +391,7 @@ async def connect_agent(
         role: Agent role.
         human_name: Agent display name.
         agent_type: Agent type (agent, etc).
+        metadata: Agent self-description; empty keeps the stored value.
 
     Returns:
         Dict with team_id, alias, agent_id, workspace_id, role.
@@ -393,6 +416,7 @@ async def connect_agent(
         human_name=human_name,
         agent_type=agent_type,
         role=role,
+        metadata=metadata,
     )
 
     workspace_id = await _ensure_workspace(
@@ -448,6 +472,7 @@ async def connect_endpoint(
             role=payload.role,
             human_name=payload.human_name,
             agent_type=payload.agent_type,
+            metadata=payload.metadata,
         )
     except AliasConflictError as exc:
         raise HTTPException(status_code=409, detail=str(exc)) from exc
@@ -41,8 +41,10 @@ def _mail_wake_mode(priority: str | None) -> str:
     return "idle"
 
 
-def _chat_wake_mode(*, sender_waiting: bool) -> str:
-    return "interrupt" if sender_waiting else "prompt"
+def _chat_wake_mode(*, sender_waiting: bool, priority: str | None = None) -> str:
+    if sender_waiting or (priority or "normal").strip().lower() in {"high", "urgent"}:
+        return "interrupt"
+    return "prompt"
 
 
 async def _current_actionable_mail(aweb_db, *, inbox_dids: list[str]) -> list[dict[str, Any]]:
@@ -193,7 +195,16 @@ async def _current_actionable_chat(
                 ),
                 "unread_count": int(item.get("unread_count") or 0),
                 "sender_waiting": sender_waiting,
-                "wake_mode": _chat_wake_mode(sender_waiting=sender_waiting),
+                "priority": item.get("last_priority") or "normal",
+                "wake_mode": _chat_wake_mode(
+                    sender_waiting=sender_waiting,
+                    priority=item.get("last_priority"),
+                ),
+                "nudged_at": (
+                    item["nudged_at"].astimezone(timezone.utc).isoformat()
+                    if item.get("nudged_at")
+                    else ""
+                ),
             }
         )
     return actionable
@@ -1,12 +1,14 @@
 from __future__ import annotations
 
+import base64
+import binascii
 import json
 from datetime import datetime, timezone
 from typing import Optional
 from uuid import UUID
 
 from fastapi import APIRouter, Depends, HTTPException, Query, Request
-from pydantic import BaseModel, ConfigDict, Field, field_validator
+from pydantic import BaseModel, ConfigDict, Field, field_validator, model_validator
 
 from aweb.deps import get_db
 from aweb.hooks import fire_mutation_hook
@@ -23,13 +25,15 @@ from aweb.messaging.alias_targets import (
 )
 from aweb.messaging.messages import (
     MessagePriority,
-    deliver_message,
+    attachments_digest,
+    deliver_message_once,
     get_agent_by_alias,
     get_agent_by_id,
+    message_attachments,
     resolve_agent_by_did,
     utc_iso as _utc_iso,
 )
-from aweb.service_errors import ForbiddenError, NotFoundError, ValidationError
+from aweb.service_errors import ConflictError, ForbiddenError, NotFoundError, ValidationError
 
 router = APIRouter(prefix="/v1/messages", tags=["aweb-mail"])
 
@@ -41,6 +45,38 @@ async def _resolve_message_alias(db, auth: MessagingAuth, raw_alias: str) -> dic
     return await get_agent_by_alias(db, team_id=target.team_id, alias=target.alias)
 
 
+# Encoded size budget for all attachments on one message; matches
+# MaxAttachmentsSize in the Go client. Inbox pages carry attachment data
+# inline, so it stays well below the 10 MiB clients read a page with.
+MAX_ATTACHMENTS_BYTES = 1024 * 1024
+
+
+class MessageAttachment(BaseModel):
+    """A file (base64 data) or link carried alongside a mail body."""
+
+    model_config = ConfigDict(extra="forbid")
+
+    name: str = Field(..., min_length=1, max_length=256)
+    content_type: Optional[str] = Field(default=None, max_length=256)
+    data: Optional[str] = None
+    url: Optional[str] = Field(default=None, max_length=2048)
+
+    @model_validator(mode="after")
+    def _validate_source(self) -> "MessageAttachment":
+        if bool(self.data) == bool(self.url):
+            raise ValueError(f"attachment {self.name!r}: exactly one of data or url is required")
+        if self.data:
+            try:
+                base64.b64decode(self.data, validate=True)
+            except (binascii.Error, ValueError):
+                raise ValueError(f"attachment {self.name!r}: data must be base64")
+        return self
+
+    def wire(self) -> dict:
+        """The stored and signed form: empty fields are omitted."""
+        return {key: value for key, value in self.model_dump().items() if value}
+
+
 class SendMessageRequest(BaseModel):
     model_config = ConfigDict(extra="forbid")
 
@@ -57,6 +93,15 @@ class SendMessageRequest(BaseModel):
     from_did: Optional[str] = Field(default=None, max_length=256)
     signature: Optional[str] = Field(default=None, max_length=512)
     signed_payload: Optional[str] = None
+    attachments: list[MessageAttachment] = Field(default_factory=list)
+
+    @field_validator("attachments")
+    @classmethod
+    def _validate_attachments_size(cls, v: list[MessageAttachment]) -> list[MessageAttachment]:
+        total = sum(len(a.name) + len(a.content_type or "") + len(a.data or "") + len(a.url or "") for a in v)
+        if total > MAX_ATTACHMENTS_BYTES:
+            raise ValueError(f"attachments are {total} bytes encoded, exceeding the {MAX_ATTACHMENTS_BYTES} byte limit")
+        return v
 
     @field_validator("to_agent_id")
     @classmethod
@@ -90,6 +135,8 @@ class SendMessageResponse(BaseModel):
     message_id: str
     status: str
     delivered_at: str
+    to_agent_id: Optional[str] = None
+    to_alias: Optional[str] = None
 
 
 class InboxMessage(BaseModel):
@@ -110,6 +157,7 @@ class InboxMessage(BaseModel):
     to_address: Optional[str] = None
     signature: Optional[str] = None
     signed_payload: Optional[str] = None
+    attachments: list[dict] = Field(default_factory=list)
 
 
 class InboxResponse(BaseModel):
@@ -121,6 +169,26 @@ class AckResponse(BaseModel):
     acknowledged_at: str
 
 
+# Most message IDs one bulk ack takes; matches the largest inbox page.
+MAX_BULK_ACK_MESSAGES = 200
+
+
+class BulkAckRequest(BaseModel):
+    model_config = ConfigDict(extra="forbid")
+
+    message_ids: list[str] = Field(..., min_length=1, max_length=MAX_BULK_ACK_MESSAGES)
+
+
+class BulkAckResult(BaseModel):
+    message_id: str
+    acknowledged_at: Optional[str] = None
+    error: Optional[str] = None
+
+
+class BulkAckResponse(BaseModel):
+    results: list[BulkAckResult]
+
+
 def _parse_signed_timestamp(value: str) -> datetime:
     try:
         dt = datetime.fromisoformat(value.replace("Z", "+00:00"))
@@ -150,6 +218,7 @@ def _validate_signed_mail_payload(
     from_did: str,
     message_id: str,
     timestamp: str,
+    attachments: list[dict],
 ) -> None:
     if signed_payload is None:
         return
@@ -225,6 +294,9 @@ def _validate_signed_mail_payload(
         raise HTTPException(status_code=422, detail="signed_payload message_id must match the mail message")
     if payload.get("timestamp") != timestamp:
         raise HTTPException(status_code=422, detail="signed_payload timestamp must match the mail message")
+    expected_digest = attachments_digest(attachments) if attachments else None
+    if payload.get("attachments_sha256") != expected_digest:
+        raise HTTPException(status_code=422, detail="signed_payload attachments_sha256 must match the attachments")
 
 
 def _external_recipient_from_address(address: str, resolution) -> dict:
@@ -249,7 +321,10 @@ async def _local_recipient_from_address(db, *, domain: str, name: str) -> dict |
     try:
         return await get_agent_by_namespace_alias(db, namespace=domain, alias=name)
     except AmbiguousLocalAddressError as exc:
-        raise HTTPException(status_code=409, detail=str(exc)) from exc
+        raise HTTPException(
+            status_code=409,
+            detail={"code": "ambiguous_recipient", "message": str(exc), "candidates": exc.candidates},
+        ) from exc
 
 
 def _with_requested_address(row: dict, address: str) -> dict:
@@ -480,6 +555,7 @@ async def send_message(
         raise HTTPException(status_code=401, detail="Authenticated identity is missing a routing DID")
 
     msg_uuid = UUID(payload.message_id) if payload.message_id else None
+    attachments = [a.wire() for a in payload.attachments]
     created_at = None
     if payload.signature is not None:
         if payload.from_did is None or not payload.from_did.strip():
@@ -507,11 +583,12 @@ async def send_message(
             from_did=from_did,
             message_id=payload.message_id,
             timestamp=payload.timestamp,
+            attachments=attachments,
         )
         created_at = _parse_signed_timestamp(payload.timestamp)
 
     try:
-        message_id, created_at = await deliver_message(
+        message_id, created_at, replayed = await deliver_message_once(
             db,
             registry_client=registry_client,
             recipient_agent=recipient,
@@ -530,10 +607,21 @@ async def send_message(
             signed_payload=payload.signed_payload,
             created_at=created_at,
             message_id=msg_uuid,
+            attachments=attachments,
         )
-    except (ValidationError, NotFoundError, ForbiddenError) as exc:
+    except (ValidationError, NotFoundError, ForbiddenError, ConflictError) as exc:
         raise HTTPException(status_code=exc.status_code, detail=exc.detail) from exc
 
+    if replayed:
+        # The stored message was already announced when it first landed.
+        return SendMessageResponse(
+            message_id=str(message_id),
+            status="delivered",
+            delivered_at=_utc_iso(created_at),
+            to_agent_id=to_agent_id,
+            to_alias=to_alias,
+        )
+
     await fire_mutation_hook(
         request,
         "message.sent",
@@ -555,6 +643,8 @@ async def send_message(
         message_id=str(message_id),
         status="delivered",
         delivered_at=_utc_iso(created_at),
+        to_agent_id=to_agent_id,
+        to_alias=to_alias,
     )
 
 
@@ -588,7 +678,7 @@ async def get_inbox(
         f"""
         SELECT m.message_id, m.from_agent_id, m.from_alias, m.from_address, m.to_alias,
                m.subject, m.body, m.priority, m.read_at, m.created_at,
-               m.from_did, m.to_did, m.signature, m.signed_payload
+               m.from_did, m.to_did, m.signature, m.signed_payload, m.attachments_json
         FROM {{{{tables.messages}}}} m
         {where_clause}
         ORDER BY m.created_at DESC
@@ -608,33 +698,146 @@ async def get_inbox(
         ],
     )
 
-    messages = []
-    for r in rows:
-        from_did = (r.get("from_did") or "").strip()
-        to_did = (r.get("to_did") or "").strip()
-        messages.append(InboxMessage(
-            message_id=str(r["message_id"]),
-            from_agent_id=(str(r["from_agent_id"]) if r.get("from_agent_id") else None),
-            from_alias=r["from_alias"],
-            to_alias=r["to_alias"],
-            subject=r["subject"],
-            body=r["body"],
-            priority=r["priority"],
-            read_at=r["read_at"].isoformat() if r.get("read_at") else None,
-            created_at=r["created_at"].isoformat(),
-            from_did=from_did or None,
-            to_did=to_did or None,
-            from_stable_id=(identity_map.get(from_did, {}).get("stable_id") or None),
-            to_stable_id=(identity_map.get(to_did, {}).get("stable_id") or None),
-            from_address=(r.get("from_address") or identity_map.get(from_did, {}).get("address") or None),
-            to_address=(identity_map.get(to_did, {}).get("address") or None),
-            signature=r.get("signature"),
-            signed_payload=r.get("signed_payload"),
-        ))
+    messages = [_inbox_message(r, identity_map) for r in rows]
 
     return InboxResponse(messages=messages)
 
 
+@router.get("/{message_id}", response_model=InboxMessage)
+async def get_message(
+    message_id: str,
+    db=Depends(get_db),
+    auth: MessagingAuth = Depends(get_messaging_auth),
+) -> InboxMessage:
+    try:
+        msg_uuid = UUID(message_id.strip())
+    except Exception:
+        raise HTTPException(status_code=422, detail="Invalid message_id format")
+
+    caller_dids = auth_dids(auth)
+    if not caller_dids:
+        raise HTTPException(status_code=401, detail="Authenticated identity is missing a routing DID")
+
+    aweb_db = db.get_manager("aweb")
+    row = await aweb_db.fetch_one(
+        """
+        SELECT m.message_id, m.from_agent_id, m.from_alias, m.from_address, m.to_alias,
+               m.subject, m.body, m.priority, m.read_at, m.created_at,
+               m.from_did, m.to_did, m.signature, m.signed_payload, m.attachments_json
+        FROM {{tables.messages}} m
+        WHERE m.message_id = $1
+          AND (m.to_did = ANY($2::text[]) OR m.from_did = ANY($2::text[]))
+        """,
+        msg_uuid,
+        caller_dids,
+    )
+    if not row:
+        raise HTTPException(status_code=404, detail="Message not found")
+
+    identity_map = await lookup_identity_metadata_by_did(
+        db,
+        [str(value).strip() for value in (row.get("from_did"), row.get("to_did")) if value],
+    )
+    return _inbox_message(row, identity_map)
+
+
+def _inbox_message(r, identity_map: dict) -> InboxMessage:
+    from_did = (r.get("from_did") or "").strip()
+    to_did = (r.get("to_did") or "").strip()
+    return InboxMessage(
+        message_id=str(r["message_id"]),
+        from_agent_id=(str(r["from_agent_id"]) if r.get("from_agent_id") else None),
+        from_alias=r["from_alias"],
+        to_alias=r["to_alias"],
+        subject=r["subject"],
+        body=r["body"],
+        priority=r["priority"],
+        read_at=r["read_at"].isoformat() if r.get("read_at") else None,
+        created_at=r["created_at"].isoformat(),
+        from_did=from_did or None,
+        to_did=to_did or None,
+        from_stable_id=(identity_map.get(from_did, {}).get("stable_id") or None),
+        to_stable_id=(identity_map.get(to_did, {}).get("stable_id") or None),
+        from_address=(r.get("from_address") or identity_map.get(from_did, {}).get("address") or None),
+        to_address=(identity_map.get(to_did, {}).get("address") or None),
+        signature=r.get("signature"),
+        signed_payload=r.get("signed_payload"),
+        attachments=message_attachments(r.get("attachments_json")),
+    )
+
+
+@router.post("/ack", response_model=BulkAckResponse, response_model_exclude_none=True)
+async def ack_messages(
+    request: Request, payload: BulkAckRequest, db=Depends(get_db),
+    auth: MessagingAuth = Depends(get_messaging_auth),
+) -> BulkAckResponse:
+    """Mark a batch of messages read, reporting the outcome per message.
+
+    A message that is missing or addressed to someone else fails on its own
+    without failing the batch. Results follow the order of message_ids.
+    """
+    aweb_db = db.get_manager("aweb")
+    now = datetime.now(timezone.utc)
+    inbox_dids = auth_dids(auth)
+    if not inbox_dids:
+        raise HTTPException(status_code=401, detail="Authenticated identity is missing a routing DID")
+
+    parsed: dict[str, UUID] = {}
+    for raw in payload.message_ids:
+        try:
+            parsed[raw] = UUID(raw.strip())
+        except Exception:
+            continue
+    uuids = list(set(parsed.values()))
+
+    acked = await aweb_db.fetch_all(
+        """
+        UPDATE {{tables.messages}}
+        SET read_at = $1
+        WHERE message_id = ANY($2::uuid[]) AND to_did = ANY($3::text[])
+          AND read_at IS NULL
+        RETURNING message_id, from_alias, subject
+        """,
+        now,
+        uuids,
+        inbox_dids,
+    )
+    found = await aweb_db.fetch_all(
+        """
+        SELECT message_id FROM {{tables.messages}}
+        WHERE message_id = ANY($1::uuid[]) AND to_did = ANY($2::text[])
+        """,
+        uuids,
+        inbox_dids,
+    )
+    found_ids = {row["message_id"] for row in found}
+
+    for row in acked:
+        await fire_mutation_hook(
+            request,
+            "message.acknowledged",
+            {
+                "team_id": auth.team_id,
+                "agent_id": auth.agent_id,
+                "alias": auth.alias,
+                "message_id": str(row["message_id"]),
+                "from_alias": row["from_alias"],
+                "subject": row["subject"] or "",
+            },
+        )
+
+    results: list[BulkAckResult] = []
+    for raw in payload.message_ids:
+        msg_uuid = parsed.get(raw)
+        if msg_uuid is None:
+            results.append(BulkAckResult(message_id=raw, error="Invalid message_id format"))
+        elif msg_uuid not in found_ids:
+            results.append(BulkAckResult(message_id=raw, error="Message not found"))
+        else:
+            results.append(BulkAckResult(message_id=raw, acknowledged_at=now.isoformat()))
+    return BulkAckResponse(results=results)
+
+
 @router.post("/{message_id}/ack", response_model=AckResponse)
 async def ack_message(
     request: Request, message_id: str, db=Depends(get_db),
@@ -2,24 +2,33 @@
 
 from __future__ import annotations
 
+import asyncio
 import json
+import logging
+from collections.abc import AsyncIterator
 from datetime import datetime, timedelta, timezone
 from typing import Any, Optional
 from uuid import UUID
 
-from fastapi import APIRouter, Depends, HTTPException, Query, Request, status
+from fastapi import APIRouter, Depends, HTTPException, Query, Request, Response, status
 from pydantic import BaseModel, ConfigDict, Field
-from fastapi.responses import JSONResponse
+from fastapi.responses import JSONResponse, StreamingResponse
 
+from awid.pagination import encode_cursor, validate_pagination_params
 from aweb.deps import get_db
 from aweb.hooks import fire_mutation_hook
 from aweb.team_auth_deps import TeamIdentity, get_team_identity
 
+from ._etag import etag_matches, payload_etag
 from ._reservation_utils import reservation_metadata, reservation_prefix_like
 
+logger = logging.getLogger(__name__)
+
 router = APIRouter(prefix="/v1", tags=["reservations"])
 
 DEFAULT_RESERVATION_TTL_SECONDS = 3600
+RESERVATION_WATCH_POLL_INTERVAL = 1.0  # seconds between polls
+RESERVATION_WATCH_MAX_DURATION = 300  # maximum stream lifetime in seconds
 
 
 class ReservationView(BaseModel):
@@ -32,10 +41,14 @@ class ReservationView(BaseModel):
     ttl_remaining_seconds: int
     reason: Optional[str] = None
     metadata: dict[str, object]
+    fencing_token: Optional[int] = None
 
 
 class ReservationListResponse(BaseModel):
     reservations: list[ReservationView]
+    has_more: bool = False
+    next_cursor: Optional[str] = None
+    total: int
 
 
 class ReservationAcquireRequest(BaseModel):
@@ -44,6 +57,11 @@ class ReservationAcquireRequest(BaseModel):
     resource_key: str = Field(..., min_length=1, max_length=4096)
     ttl_seconds: int = Field(DEFAULT_RESERVATION_TTL_SECONDS, ge=1, le=86400)
     metadata: dict[str, Any] = Field(default_factory=dict)
+    # Expired locks have always been reclaimable and still are. With
+    # steal_expired the response reports a takeover as status "stolen" with
+    # the previous holder; without it the response is unchanged. Live locks
+    # held by another agent are never taken.
+    steal_expired: bool = False
 
 
 class ReservationAcquireResponse(BaseModel):
@@ -54,6 +72,12 @@ class ReservationAcquireResponse(BaseModel):
     holder_alias: str
     acquired_at: str
     expires_at: str
+    stolen_from_agent_id: Optional[str] = None
+    stolen_from_alias: Optional[str] = None
+    stolen_from_expires_at: Optional[str] = None
+    # Strictly increases each time the lock passes to a holder, including
+    # after a release; see _next_fencing_token.
+    fencing_token: int
 
 
 class ReservationConflictResponse(BaseModel):
@@ -74,6 +98,10 @@ class ReservationRenewResponse(BaseModel):
     status: str
     resource_key: str
     expires_at: str
+    holder_agent_id: str
+    holder_alias: str
+    # None for locks acquired before fencing tokens existed.
+    fencing_token: Optional[int] = None
 
 
 class ReservationReleaseRequest(BaseModel):
@@ -87,6 +115,25 @@ class ReservationReleaseResponse(BaseModel):
     resource_key: str
 
 
+class ReservationTransferRequest(BaseModel):
+    model_config = ConfigDict(extra="forbid")
+
+    resource_key: str = Field(..., min_length=1, max_length=4096)
+    to_alias: str = Field(..., min_length=1, max_length=256)
+
+
+class ReservationTransferResponse(BaseModel):
+    status: str
+    resource_key: str
+    holder_agent_id: str
+    holder_alias: str
+    previous_holder_agent_id: str
+    previous_holder_alias: str
+    acquired_at: str
+    expires_at: str
+    fencing_token: int
+
+
 class ReservationRevokeRequest(BaseModel):
     model_config = ConfigDict(extra="forbid")
 
@@ -130,19 +177,37 @@ def _reservation_view(row, *, now: datetime) -> ReservationView:
         ttl_remaining_seconds=max(int((row["expires_at"] - now).total_seconds()), 0),
         reason=_reservation_reason(metadata),
         metadata=metadata,
+        fencing_token=row["fencing_token"],
     )
 
 
 @router.get("/reservations")
 async def list_reservations(
     request: Request,
+    response: Response,
     prefix: Optional[str] = Query(None, description="Optional resource key prefix filter"),
+    limit: Optional[int] = Query(None, ge=1, le=200),
+    cursor: Optional[str] = Query(None),
     db=Depends(get_db),
     identity: TeamIdentity = Depends(get_team_identity),
 ) -> ReservationListResponse:
+    """List active reservations ordered by resource_key.
+
+    Without limit or cursor every match is returned on one page, as older
+    clients expect. total always counts every match across pages.
+
+    The ETag leaves out ttl_remaining_seconds, which changes every second;
+    a client reusing a cached body should count down from expires_at.
+    """
     aweb_db = db.get_manager("aweb")
     now = datetime.now(timezone.utc)
 
+    paginate = limit is not None or bool(cursor)
+    try:
+        validated_limit, cursor_data = validate_pagination_params(limit, cursor)
+    except ValueError as e:
+        raise HTTPException(status_code=422, detail=str(e))
+
     conditions = ["team_id = $1", "expires_at > NOW()"]
     params: list[object] = [identity.team_id]
 
@@ -151,20 +216,203 @@ async def list_reservations(
         params.append(reservation_prefix_like(prefix))
 
     where_clause = " AND ".join(conditions)
+    count_row = await aweb_db.fetch_one(
+        f"""
+        SELECT COUNT(*) AS total
+        FROM {{{{tables.reservations}}}}
+        WHERE {where_clause}
+        """,
+        *params,
+    )
+    total = int(count_row["total"]) if count_row else 0
+
+    if cursor_data is not None:
+        after_key = cursor_data.get("resource_key")
+        if not isinstance(after_key, str):
+            raise HTTPException(status_code=422, detail="Invalid cursor: missing resource_key")
+        conditions.append(f"resource_key > ${len(params) + 1}")
+        params.append(after_key)
+        where_clause = " AND ".join(conditions)
+
+    limit_clause = ""
+    if paginate:
+        limit_clause = f"LIMIT ${len(params) + 1}"
+        params.append(validated_limit + 1)
+
     rows = await aweb_db.fetch_all(
         f"""
         SELECT team_id, resource_key, holder_agent_id, holder_alias,
-               acquired_at, expires_at, metadata_json
+               acquired_at, expires_at, metadata_json, fencing_token
         FROM {{{{tables.reservations}}}}
         WHERE {where_clause}
         ORDER BY resource_key ASC
+        {limit_clause}
         """,
         *params,
     )
 
-    return ReservationListResponse(
-        reservations=[_reservation_view(row, now=now) for row in rows]
+    has_more = paginate and len(rows) > validated_limit
+    if has_more:
+        rows = rows[:validated_limit]
+    next_cursor = encode_cursor({"resource_key": rows[-1]["resource_key"]}) if has_more else None
+
+    result = ReservationListResponse(
+        reservations=[_reservation_view(row, now=now) for row in rows],
+        has_more=has_more,
+        next_cursor=next_cursor,
+        total=total,
     )
+    etag = payload_etag(result.model_dump(exclude={"reservations": {"__all__": {"ttl_remaining_seconds"}}}))
+    if etag_matches(request.headers.get("If-None-Match"), etag):
+        return Response(status_code=304, headers={"ETag": etag})
+    response.headers["ETag"] = etag
+    return result
+
+
+def _reservation_watch_state(row, *, now: datetime) -> Optional[dict[str, Any]]:
+    if row is None:
+        return None
+    return {
+        "holder_agent_id": str(row["holder_agent_id"]),
+        "holder_alias": row["holder_alias"],
+        "acquired_at": row["acquired_at"].isoformat(),
+        "expires_at": row["expires_at"].isoformat(),
+        "live": row["expires_at"] > now,
+    }
+
+
+def _reservation_watch_holder(state: dict[str, Any]) -> dict[str, Any]:
+    return {
+        "holder_agent_id": state["holder_agent_id"],
+        "holder_alias": state["holder_alias"],
+        "acquired_at": state["acquired_at"],
+        "expires_at": state["expires_at"],
+    }
+
+
+def _reservation_watch_event(event_type: str, resource_key: str, state: dict[str, Any]) -> str:
+    payload = {"type": event_type, "resource_key": resource_key, **_reservation_watch_holder(state)}
+    return f"event: {event_type}\ndata: {json.dumps(payload)}\n\n"
+
+
+def _reservation_watch_snapshot(resource_key: str, state: Optional[dict[str, Any]]) -> str:
+    held = _reservation_watch_holder(state) if state is not None and state["live"] else None
+    payload = {"type": "snapshot", "resource_key": resource_key, "reservation": held}
+    return f"event: snapshot\ndata: {json.dumps(payload)}\n\n"
+
+
+def _reservation_watch_transitions(
+    resource_key: str,
+    previous: Optional[dict[str, Any]],
+    current: Optional[dict[str, Any]],
+) -> list[str]:
+    """Events for one poll: a live hold that disappeared was released, one
+    still stored past its expiry expired, and a new holder (or a fresh
+    acquire by the same one) was acquired."""
+    events: list[str] = []
+    previously_live = previous is not None and previous["live"]
+    currently_live = current is not None and current["live"]
+    if previously_live and current is None:
+        events.append(_reservation_watch_event("released", resource_key, previous))
+    elif previously_live and not currently_live:
+        events.append(_reservation_watch_event("expired", resource_key, previous))
+    if currently_live and (
+        not previously_live
+        or previous["holder_agent_id"] != current["holder_agent_id"]
+        or previous["acquired_at"] != current["acquired_at"]
+    ):
+        events.append(_reservation_watch_event("acquired", resource_key, current))
+    return events
+
+
+async def _fetch_reservation_row(aweb_db, *, team_id: str, resource_key: str):
+    return await aweb_db.fetch_one(
+        """
+        SELECT holder_agent_id, holder_alias, acquired_at, expires_at
+        FROM {{tables.reservations}}
+        WHERE team_id = $1 AND resource_key = $2
+        """,
+        team_id,
+        resource_key,
+    )
+
+
+async def _sse_reservation_events(
+    *,
+    request: Request,
+    db,
+    team_id: str,
+    resource_key: str,
+) -> AsyncIterator[str]:
+    """Poll one reservation and emit its acquire/release/expire transitions."""
+    aweb_db = db.get_manager("aweb")
+    deadline = datetime.now(timezone.utc) + timedelta(seconds=RESERVATION_WATCH_MAX_DURATION)
+
+    yield ": keepalive\n\n"
+
+    # Every connection starts with the current hold, or null when the key
+    # is free, so a reconnecting client can tell what it missed.
+    row = await _fetch_reservation_row(aweb_db, team_id=team_id, resource_key=resource_key)
+    previous = _reservation_watch_state(row, now=datetime.now(timezone.utc))
+    yield _reservation_watch_snapshot(resource_key, previous)
+
+    while datetime.now(timezone.utc) < deadline:
+        await asyncio.sleep(RESERVATION_WATCH_POLL_INTERVAL)
+
+        if await request.is_disconnected():
+            break
+
+        try:
+            row = await _fetch_reservation_row(aweb_db, team_id=team_id, resource_key=resource_key)
+        except Exception:
+            logger.exception("reservation watch poll error for %s", resource_key)
+            yield f"event: error\ndata: {json.dumps({'type': 'error', 'detail': 'poll failure'})}\n\n"
+            break
+
+        current = _reservation_watch_state(row, now=datetime.now(timezone.utc))
+        for event in _reservation_watch_transitions(resource_key, previous, current):
+            yield event
+        previous = current
+
+
+@router.get("/reservations/watch")
+async def watch_reservation(
+    request: Request,
+    resource_key: str = Query(..., min_length=1, max_length=4096),
+    db=Depends(get_db),
+    identity: TeamIdentity = Depends(get_team_identity),
+):
+    """SSE stream of one resource key: a snapshot of the current hold, then
+    acquired, released and expired events. The stream ends after
+    RESERVATION_WATCH_MAX_DURATION; clients reconnect for a fresh snapshot."""
+    return StreamingResponse(
+        _sse_reservation_events(
+            request=request,
+            db=db,
+            team_id=identity.team_id,
+            resource_key=resource_key,
+        ),
+        media_type="text/event-stream",
+        headers={"Cache-Control": "no-cache", "X-Accel-Buffering": "no"},
+    )
+
+
+async def _next_fencing_token(tx, *, team_id: str, resource_key: str) -> int:
+    """Issue the next fencing token for a lock, inside the caller's
+    transaction. Holders pass it to downstream systems, which reject writes
+    carrying a token lower than one they have already seen."""
+    row = await tx.fetch_one(
+        """
+        INSERT INTO {{tables.reservation_fencing}} AS f (team_id, resource_key, last_token)
+        VALUES ($1, $2, 1)
+        ON CONFLICT (team_id, resource_key)
+        DO UPDATE SET last_token = f.last_token + 1
+        RETURNING last_token
+        """,
+        team_id,
+        resource_key,
+    )
+    return int(row["last_token"])
 
 
 @router.post(
@@ -187,7 +435,7 @@ async def acquire_reservation(
         row = await tx.fetch_one(
             """
             SELECT team_id, resource_key, holder_agent_id, holder_alias,
-                   acquired_at, expires_at, metadata_json
+                   acquired_at, expires_at, metadata_json, fencing_token
             FROM {{tables.reservations}}
             WHERE team_id = $1 AND resource_key = $2
             FOR UPDATE
@@ -203,9 +451,21 @@ async def acquire_reservation(
                 expires_at=row["expires_at"].isoformat(),
             )
 
+        # Reaching here with another agent's row means its lease has expired.
+        reclaimed = row if row and str(row["holder_agent_id"]) != identity.agent_id else None
+        stolen_from = reclaimed if payload.steal_expired else None
+
         # An empty metadata object means "preserve existing metadata" so the
         # current CLI acquire path does not silently clear reason/context fields.
         metadata = payload.metadata or (reservation_metadata(row["metadata_json"]) if row else {})
+        # Re-acquiring a lock the caller still holds keeps its token, so the
+        # caller's in-flight writes stay valid; any change of holder, or a
+        # hold that lapsed, gets a new one.
+        still_held = row is not None and row["expires_at"] > now and reclaimed is None
+        if still_held and row["fencing_token"] is not None:
+            fencing_token = int(row["fencing_token"])
+        else:
+            fencing_token = await _next_fencing_token(tx, team_id=identity.team_id, resource_key=payload.resource_key)
         if row:
             await tx.execute(
                 """
@@ -214,7 +474,9 @@ async def acquire_reservation(
                     holder_alias = $4,
                     acquired_at = $5,
                     expires_at = $6,
-                    metadata_json = $7::jsonb
+                    metadata_json = $7::jsonb,
+                    fencing_token = $8,
+                    ttl_seconds = $9
                 WHERE team_id = $1 AND resource_key = $2
                 """,
                 identity.team_id,
@@ -224,13 +486,16 @@ async def acquire_reservation(
                 now,
                 expires_at,
                 json.dumps(metadata),
+                fencing_token,
+                payload.ttl_seconds,
             )
         else:
             await tx.execute(
                 """
                 INSERT INTO {{tables.reservations}}
-                    (team_id, resource_key, holder_agent_id, holder_alias, acquired_at, expires_at, metadata_json)
-                VALUES ($1, $2, $3, $4, $5, $6, $7::jsonb)
+                    (team_id, resource_key, holder_agent_id, holder_alias, acquired_at, expires_at, metadata_json,
+                     fencing_token, ttl_seconds)
+                VALUES ($1, $2, $3, $4, $5, $6, $7::jsonb, $8, $9)
                 """,
                 identity.team_id,
                 payload.resource_key,
@@ -239,6 +504,8 @@ async def acquire_reservation(
                 now,
                 expires_at,
                 json.dumps(metadata),
+                fencing_token,
+                payload.ttl_seconds,
             )
 
     await fire_mutation_hook(
@@ -250,17 +517,23 @@ async def acquire_reservation(
             "alias": identity.alias,
             "resource_key": payload.resource_key,
             "ttl_seconds": payload.ttl_seconds,
+            "stolen_from_agent_id": str(reclaimed["holder_agent_id"]) if reclaimed else None,
+            "fencing_token": fencing_token,
         },
     )
 
     return ReservationAcquireResponse(
-        status="acquired",
+        status="stolen" if stolen_from else "acquired",
         team_id=identity.team_id,
         resource_key=payload.resource_key,
         holder_agent_id=identity.agent_id,
         holder_alias=identity.alias,
         acquired_at=now.isoformat(),
         expires_at=expires_at.isoformat(),
+        stolen_from_agent_id=str(stolen_from["holder_agent_id"]) if stolen_from else None,
+        stolen_from_alias=stolen_from["holder_alias"] if stolen_from else None,
+        stolen_from_expires_at=stolen_from["expires_at"].isoformat() if stolen_from else None,
+        fencing_token=fencing_token,
     )
 
 
@@ -282,7 +555,7 @@ async def renew_reservation(
     async with aweb_db.transaction() as tx:
         row = await tx.fetch_one(
             """
-            SELECT holder_agent_id, holder_alias, expires_at
+            SELECT holder_agent_id, holder_alias, expires_at, fencing_token
             FROM {{tables.reservations}}
             WHERE team_id = $1 AND resource_key = $2
             FOR UPDATE
@@ -302,12 +575,14 @@ async def renew_reservation(
         await tx.execute(
             """
             UPDATE {{tables.reservations}}
-            SET expires_at = $3
+            SET expires_at = $3,
+                ttl_seconds = $4
             WHERE team_id = $1 AND resource_key = $2
             """,
             identity.team_id,
             payload.resource_key,
             expires_at,
+            payload.ttl_seconds,
         )
 
     await fire_mutation_hook(
@@ -326,6 +601,9 @@ async def renew_reservation(
         status="renewed",
         resource_key=payload.resource_key,
         expires_at=expires_at.isoformat(),
+        holder_agent_id=str(row["holder_agent_id"]),
+        holder_alias=row["holder_alias"],
+        fencing_token=row["fencing_token"],
     )
 
 
@@ -388,6 +666,104 @@ async def release_reservation(
     return ReservationReleaseResponse(status="released", resource_key=payload.resource_key)
 
 
+@router.post(
+    "/reservations/transfer",
+    response_model=ReservationTransferResponse,
+    responses={409: {"model": ReservationConflictResponse}},
+)
+async def transfer_reservation(
+    request: Request,
+    payload: ReservationTransferRequest,
+    db=Depends(get_db),
+    identity: TeamIdentity = Depends(get_team_identity),
+) -> ReservationTransferResponse | JSONResponse:
+    """Hand a held lock to another agent in one step, keeping its lease.
+
+    Releasing and letting the recipient acquire would leave a window for a
+    third agent to take the lock; the row is reassigned under FOR UPDATE.
+    """
+    aweb_db = db.get_manager("aweb")
+    now = datetime.now(timezone.utc)
+    to_alias = payload.to_alias.strip()
+
+    async with aweb_db.transaction() as tx:
+        row = await tx.fetch_one(
+            """
+            SELECT holder_agent_id, holder_alias, expires_at
+            FROM {{tables.reservations}}
+            WHERE team_id = $1 AND resource_key = $2
+            FOR UPDATE
+            """,
+            identity.team_id,
+            payload.resource_key,
+        )
+        if row is None or row["expires_at"] <= now:
+            raise HTTPException(status_code=404, detail="reservation not found")
+        if str(row["holder_agent_id"]) != identity.agent_id:
+            return _reservation_conflict_response(
+                holder_agent_id=str(row["holder_agent_id"]),
+                holder_alias=row["holder_alias"],
+                expires_at=row["expires_at"].isoformat(),
+            )
+
+        target = await tx.fetch_one(
+            """
+            SELECT agent_id, alias
+            FROM {{tables.agents}}
+            WHERE team_id = $1 AND alias = $2 AND deleted_at IS NULL
+            """,
+            identity.team_id,
+            to_alias,
+        )
+        if target is None:
+            raise HTTPException(status_code=422, detail=f"no agent with alias {to_alias!r} in this team")
+
+        # The previous holder must be fenced off like after any other
+        # change of holder.
+        fencing_token = await _next_fencing_token(tx, team_id=identity.team_id, resource_key=payload.resource_key)
+        await tx.execute(
+            """
+            UPDATE {{tables.reservations}}
+            SET holder_agent_id = $3,
+                holder_alias = $4,
+                acquired_at = $5,
+                fencing_token = $6
+            WHERE team_id = $1 AND resource_key = $2
+            """,
+            identity.team_id,
+            payload.resource_key,
+            target["agent_id"],
+            target["alias"],
+            now,
+            fencing_token,
+        )
+
+    await fire_mutation_hook(
+        request,
+        "reservation.transferred",
+        {
+            "team_id": identity.team_id,
+            "holder_agent_id": str(target["agent_id"]),
+            "alias": target["alias"],
+            "previous_holder_agent_id": identity.agent_id,
+            "previous_alias": identity.alias,
+            "resource_key": payload.resource_key,
+        },
+    )
+
+    return ReservationTransferResponse(
+        status="transferred",
+        resource_key=payload.resource_key,
+        holder_agent_id=str(target["agent_id"]),
+        holder_alias=target["alias"],
+        previous_holder_agent_id=str(row["holder_agent_id"]),
+        previous_holder_alias=row["holder_alias"],
+        acquired_at=now.isoformat(),
+        expires_at=row["expires_at"].isoformat(),
+        fencing_token=fencing_token,
+    )
+
+
 @router.post("/reservations/revoke", response_model=ReservationRevokeResponse)
 async def revoke_reservations(
     request: Request,
@@ -20,6 +20,7 @@ from pgdbm import AsyncDatabaseManager
 from awid.signing import canonical_json_bytes, verify_did_key_signature
 from awid.team_ids import parse_team_id
 from awid.dns_auth import parse_didkey_auth, require_timestamp, enforce_timestamp_skew
+from aweb.reservation_heartbeat import RENEW_RESERVATIONS_HEADER, renew_reservations_from_header
 from aweb.team_auth import parse_and_verify_certificate
 
 logger = logging.getLogger(__name__)
@@ -190,7 +191,8 @@ async def get_team_identity(request: Request, db=Depends(get_db)) -> TeamIdentit
 
     Full auth pipeline (steps 1-6): verifies the certificate and
     resolves the agent from the local DB. For routes where the agent
-    must already exist.
+    must already exist. Locks listed in X-Aweb-Renew-Reservations are
+    then renewed; see aweb.reservation_heartbeat.
 
     IMPORTANT: this must be used as Depends(get_team_identity) so FastAPI
     evaluates it before body parameter injection. Calling it directly
@@ -203,9 +205,16 @@ async def get_team_identity(request: Request, db=Depends(get_db)) -> TeamIdentit
 
     aweb_db = _aweb_db(db)
     try:
-        return await resolve_team_identity(aweb_db, cert_info)
+        identity = await resolve_team_identity(aweb_db, cert_info)
     except ValueError as e:
         raise HTTPException(status_code=403, detail=str(e))
+    await renew_reservations_from_header(
+        aweb_db,
+        team_id=identity.team_id,
+        agent_id=identity.agent_id,
+        header_value=request.headers.get(RENEW_RESERVATIONS_HEADER),
+    )
+    return identity
 
 
 # ---------------------------------------------------------------------------
@@ -0,0 +1,234 @@
+"""HTTP-level tests for DELETE /v1/agents/{agent_id}."""
+
+from __future__ import annotations
+
+import base64
+import json
+from datetime import datetime, timezone
+from unittest.mock import AsyncMock
+from uuid import uuid4
+
+import pytest
+from fastapi import FastAPI
+from httpx import ASGITransport, AsyncClient
+from nacl.signing import SigningKey
+
+from awid.did import did_from_public_key
+from awid.signing import canonical_json_bytes, sign_message
+from aweb.routes.agents import router as agents_router
+
+
+def _make_keypair():
+    sk = SigningKey.generate()
+    pk = bytes(sk.verify_key)
+    did_key = did_from_public_key(pk)
+    return bytes(sk), pk, did_key
+
+
+def _make_certificate(team_sk, team_did_key, member_did_key, **kwargs):
+    cert = {
+        "version": 1,
+        "certificate_id": kwargs.get("certificate_id", "cert-001"),
+        "team_id": kwargs.get("team_id", "backend:acme.com"),
+        "team_did_key": team_did_key,
+        "member_did_key": member_did_key,
+        "member_did_aw": "",
+        "member_address": "",
+        "alias": kwargs.get("alias", "alice"),
+        "lifetime": kwargs.get("lifetime", "ephemeral"),
+        "issued_at": datetime.now(timezone.utc).isoformat(),
+    }
+    payload = canonical_json_bytes(cert)
+    cert["signature"] = sign_message(team_sk, payload)
+    return cert
+
+
+def _encode_certificate(cert):
+    return base64.b64encode(json.dumps(cert).encode()).decode()
+
+
+def _signed_request(agent_sk, agent_did_key, team_id, body_bytes=b""):
+    import hashlib
+
+    timestamp = datetime.now(timezone.utc).isoformat()
+    body_sha256 = hashlib.sha256(body_bytes).hexdigest()
+    payload_bytes = canonical_json_bytes({
+        "body_sha256": body_sha256,
+        "team_id": team_id,
+        "timestamp": timestamp,
+    })
+    sig = sign_message(agent_sk, payload_bytes)
+    return {
+        "Authorization": f"DIDKey {agent_did_key} {sig}",
+        "X-AWEB-Timestamp": timestamp,
+    }
+
+
+def _build_test_app(aweb_db, team_did_key):
+    app = FastAPI()
+    app.include_router(agents_router)
+
+    class _DbShim:
+        def get_manager(self, name="aweb"):
+            return aweb_db
+
+    import hashlib as _hashlib
+
+    @app.middleware("http")
+    async def cache_body(request, call_next):
+        if request.method in {"GET", "HEAD", "OPTIONS"}:
+            request.state.cached_body = b""
+            request.state.body_sha256 = _hashlib.sha256(b"").hexdigest()
+            return await call_next(request)
+
+        original_receive = request._receive
+        body = await request.body()
+        request.state.cached_body = body
+        request.state.body_sha256 = _hashlib.sha256(body).hexdigest()
+        replayed = False
+
+        async def _receive():
+            nonlocal replayed
+            if not replayed:
+                replayed = True
+                return {"type": "http.request", "body": body, "more_body": False}
+            while True:
+                message = await original_receive()
+                if message["type"] == "http.disconnect":
+                    return message
+                if message["type"] == "http.request" and not message.get("more_body", False):
+                    continue
+                return message
+
+        request._receive = _receive
+        return await call_next(request)
+
+    app.state.db = _DbShim()
+    app.state.redis = None
+    app.state.rate_limiter = None
+
+    registry = AsyncMock()
+    registry.get_team_public_key = AsyncMock(return_value=team_did_key)
+    registry.get_team_revocations = AsyncMock(return_value=set())
+    app.state.awid_registry_client = registry
+
+    return app
+
+
+async def _seed(aweb_db, team_id: str, team_did_key: str, caller_did_key: str) -> None:
+    await aweb_db.execute(
+        """
+        INSERT INTO {{tables.teams}} (team_id, namespace, team_name, team_did_key)
+        VALUES ($1, 'acme.com', 'backend', $2)
+        """,
+        team_id,
+        team_did_key,
+    )
+    await aweb_db.execute(
+        """
+        INSERT INTO {{tables.agents}} (agent_id, team_id, did_key, alias, lifetime)
+        VALUES ($1, $2, $3, 'alice', 'ephemeral')
+        """,
+        uuid4(),
+        team_id,
+        caller_did_key,
+    )
+
+
+async def _insert_target(aweb_db, team_id: str, *, alias: str, lifetime: str):
+    agent_id = uuid4()
+    workspace_id = uuid4()
+    await aweb_db.execute(
+        """
+        INSERT INTO {{tables.agents}} (agent_id, team_id, did_key, alias, lifetime)
+        VALUES ($1, $2, $3, $4, $5)
+        """,
+        agent_id,
+        team_id,
+        f"did:key:z6Mk{alias}",
+        alias,
+        lifetime,
+    )
+    await aweb_db.execute(
+        """
+        INSERT INTO {{tables.workspaces}} (workspace_id, team_id, agent_id, alias, workspace_path)
+        VALUES ($1, $2, $3, $4, '/tmp/worker')
+        """,
+        workspace_id,
+        team_id,
+        agent_id,
+        alias,
+    )
+    return agent_id, workspace_id
+
+
+def _caller_headers(team_id: str):
+    team_sk, _, team_did_key = _make_keypair()
+    agent_sk, _, agent_did_key = _make_keypair()
+    cert = _make_certificate(team_sk, team_did_key, agent_did_key, team_id=team_id, alias="alice")
+    headers = _signed_request(agent_sk, agent_did_key, team_id)
+    headers["X-AWID-Team-Certificate"] = _encode_certificate(cert)
+    return team_did_key, agent_did_key, headers
+
+
+@pytest.mark.asyncio
+@pytest.mark.parametrize(
+    ("lifetime", "status"),
+    [("ephemeral", "deleted"), ("persistent", "archived")],
+)
+async def test_delete_agent_removes_agent_and_workspaces(aweb_cloud_db, lifetime, status):
+    aweb_db = aweb_cloud_db.aweb_db
+    team_id = "backend:acme.com"
+    team_did_key, caller_did_key, headers = _caller_headers(team_id)
+    await _seed(aweb_db, team_id, team_did_key, caller_did_key)
+    agent_id, workspace_id = await _insert_target(aweb_db, team_id, alias="worker", lifetime=lifetime)
+
+    app = _build_test_app(aweb_db, team_did_key)
+    async with AsyncClient(transport=ASGITransport(app=app), base_url="http://test") as client:
+        resp = await client.delete(f"/v1/agents/{agent_id}", headers=headers)
+
+    assert resp.status_code == 200, resp.text
+    body = resp.json()
+    assert body["agent_id"] == str(agent_id)
+    assert body["alias"] == "worker"
+    assert body["deleted_at"]
+
+    agent = await aweb_db.fetch_one(
+        "SELECT deleted_at, status FROM {{tables.agents}} WHERE agent_id = $1",
+        agent_id,
+    )
+    workspace = await aweb_db.fetch_one(
+        "SELECT deleted_at FROM {{tables.workspaces}} WHERE workspace_id = $1",
+        workspace_id,
+    )
+    assert agent["deleted_at"] is not None
+    assert agent["status"] == status
+    assert workspace["deleted_at"] is not None
+
+
+@pytest.mark.asyncio
+async def test_delete_agent_unknown_or_other_team_is_404(aweb_cloud_db):
+    aweb_db = aweb_cloud_db.aweb_db
+    team_id = "backend:acme.com"
+    team_did_key, caller_did_key, headers = _caller_headers(team_id)
+    await _seed(aweb_db, team_id, team_did_key, caller_did_key)
+    await aweb_db.execute(
+        """
+        INSERT INTO {{tables.teams}} (team_id, namespace, team_name, team_did_key)
+        VALUES ('ops:acme.com', 'acme.com', 'ops', 'did:key:z6MkOps')
+        """
+    )
+    other_agent_id, _ = await _insert_target(aweb_db, "ops:acme.com", alias="worker", lifetime="ephemeral")
+
+    app = _build_test_app(aweb_db, team_did_key)
+    async with AsyncClient(transport=ASGITransport(app=app), base_url="http://test") as client:
+        missing = await client.delete(f"/v1/agents/{uuid4()}", headers=headers)
+        cross_team = await client.delete(f"/v1/agents/{other_agent_id}", headers=headers)
+
+    assert missing.status_code == 404
+    assert cross_team.status_code == 404
+    row = await aweb_db.fetch_one(
+        "SELECT deleted_at FROM {{tables.agents}} WHERE agent_id = $1",
+        other_agent_id,
+    )
+    assert row["deleted_at"] is None
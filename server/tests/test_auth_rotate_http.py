@@ -0,0 +1,91 @@
+from __future__ import annotations
+
+from uuid import uuid4
+
+import pytest
+from fastapi import FastAPI
+from httpx import ASGITransport, AsyncClient
+
+from aweb.internal_auth import build_internal_auth_header_value
+from aweb.routes.auth import router as auth_router
+
+SECRET = "proxy-secret"
+TEAM_ID = "backend:acme.com"
+
+
+def _app(rotate=None) -> FastAPI:
+    app = FastAPI()
+    app.include_router(auth_router)
+    if rotate is not None:
+        app.state.rotate_api_key = rotate
+    return app
+
+
+def _proxy_headers(*, principal_type: str, principal_id: str, actor_id: str) -> dict[str, str]:
+    headers = {
+        "X-Team-ID": TEAM_ID,
+        "X-AWEB-Actor-ID": actor_id,
+        "X-AWEB-Auth": build_internal_auth_header_value(
+            secret=SECRET,
+            team_id=TEAM_ID,
+            principal_type=principal_type,
+            principal_id=principal_id,
+            actor_id=actor_id,
+        ),
+    }
+    if principal_type == "k":
+        headers["X-API-Key"] = principal_id
+    else:
+        headers["X-User-ID"] = principal_id
+    return headers
+
+
+@pytest.mark.asyncio
+async def test_rotate_delegates_to_embedding_callback(monkeypatch):
+    monkeypatch.setenv("AWEB_TRUST_PROXY_HEADERS", "1")
+    monkeypatch.setenv("AWEB_INTERNAL_AUTH_SECRET", SECRET)
+    calls = []
+
+    async def rotate(**kwargs):
+        calls.append(kwargs)
+        return {"api_key": "aw_sk_new", "previous_key_expires_at": "2026-01-01T00:05:00Z", "grace_seconds": 300}
+
+    key_id, actor_id = str(uuid4()), str(uuid4())
+    async with AsyncClient(transport=ASGITransport(app=_app(rotate)), base_url="http://test") as client:
+        resp = await client.post(
+            "/v1/auth/rotate",
+            headers=_proxy_headers(principal_type="k", principal_id=key_id, actor_id=actor_id),
+        )
+
+    assert resp.status_code == 200, resp.text
+    assert resp.json() == {
+        "api_key": "aw_sk_new",
+        "previous_key_expires_at": "2026-01-01T00:05:00Z",
+        "grace_seconds": 300,
+    }
+    assert calls == [{"team_id": TEAM_ID, "api_key_id": key_id, "actor_id": actor_id}]
+
+
+@pytest.mark.asyncio
+async def test_rotate_without_callback_is_not_implemented():
+    async with AsyncClient(transport=ASGITransport(app=_app()), base_url="http://test") as client:
+        resp = await client.post("/v1/auth/rotate")
+
+    assert resp.status_code == 501
+
+
+@pytest.mark.asyncio
+async def test_rotate_rejects_callers_not_authenticated_by_api_key(monkeypatch):
+    monkeypatch.setenv("AWEB_TRUST_PROXY_HEADERS", "1")
+    monkeypatch.setenv("AWEB_INTERNAL_AUTH_SECRET", SECRET)
+
+    async def rotate(**kwargs):
+        raise AssertionError("rotate must not be called")
+
+    async with AsyncClient(transport=ASGITransport(app=_app(rotate)), base_url="http://test") as client:
+        resp = await client.post(
+            "/v1/auth/rotate",
+            headers=_proxy_headers(principal_type="u", principal_id=str(uuid4()), actor_id=str(uuid4())),
+        )
+
+    assert resp.status_code == 403
@@ -2257,6 +2257,109 @@ async def test_chat_pending_preserves_last_from_did_without_address_mapping(aweb
     assert body["pending"][0]["participant_dids"] == ["did:aw:bob"]
 
 
+@pytest.mark.asyncio
+async def test_chat_pending_includes_session_subject_and_metadata(aweb_cloud_db):
+    session_id = uuid4()
+    created_at = datetime.now(timezone.utc) - timedelta(minutes=5)
+    await aweb_cloud_db.aweb_db.execute(
+        """
+        INSERT INTO {{tables.chat_sessions}} (session_id, created_by, created_at, subject, metadata_json)
+        VALUES ($1, 'bob', $2, 'Deploy plan', '{"ticket": "OPS-7"}'::jsonb)
+        """,
+        session_id,
+        created_at,
+    )
+    await aweb_cloud_db.aweb_db.execute(
+        """
+        INSERT INTO {{tables.chat_participants}} (session_id, did, alias)
+        VALUES
+            ($1, 'did:key:z6MkAliceCurrent', 'alice'),
+            ($1, 'did:aw:bob', 'bob')
+        """,
+        session_id,
+    )
+    await aweb_cloud_db.aweb_db.execute(
+        """
+        INSERT INTO {{tables.chat_messages}}
+            (session_id, from_did, from_alias, body, created_at)
+        VALUES ($1, 'did:aw:bob', 'bob', 'ready?', $2)
+        """,
+        session_id,
+        created_at + timedelta(minutes=1),
+    )
+
+    app = _build_test_app(aweb_cloud_db.aweb_db, AsyncMock())
+
+    async def _auth_override():
+        return MessagingAuth(
+            did_key="did:key:z6MkAliceCurrent",
+            did_aw="did:aw:alice",
+            address="acme.com/alice",
+        )
+
+    app.dependency_overrides[get_messaging_auth] = _auth_override
+
+    async with AsyncClient(transport=ASGITransport(app=app), base_url="http://test") as client:
+        resp = await client.get("/v1/chat/pending")
+
+    assert resp.status_code == 200, resp.text
+    body = resp.json()
+    assert len(body["pending"]) == 1
+    assert body["pending"][0]["subject"] == "Deploy plan"
+    assert body["pending"][0]["metadata"] == {"ticket": "OPS-7"}
+
+
+@pytest.mark.asyncio
+async def test_chat_leave_session_marks_sender_leaving_and_reports_closed(aweb_cloud_db):
+    session_id = uuid4()
+    created_at = datetime.now(timezone.utc) - timedelta(minutes=5)
+    await aweb_cloud_db.aweb_db.execute(
+        """
+        INSERT INTO {{tables.chat_sessions}} (session_id, created_by, created_at)
+        VALUES ($1, 'bob', $2)
+        """,
+        session_id,
+        created_at,
+    )
+    await aweb_cloud_db.aweb_db.execute(
+        """
+        INSERT INTO {{tables.chat_participants}} (session_id, did, alias)
+        VALUES
+            ($1, 'did:key:z6MkAliceCurrent', 'alice'),
+            ($1, 'did:aw:bob', 'bob')
+        """,
+        session_id,
+    )
+
+    app = _build_test_app(aweb_cloud_db.aweb_db, AsyncMock())
+    current = {"did_key": "did:key:z6MkAliceCurrent", "alias": "alice"}
+
+    async def _auth_override():
+        return MessagingAuth(did_key=current["did_key"], did_aw=None, address=None, alias=current["alias"])
+
+    app.dependency_overrides[get_messaging_auth] = _auth_override
+
+    async with AsyncClient(transport=ASGITransport(app=app), base_url="http://test") as client:
+        resp = await client.post(f"/v1/chat/sessions/{session_id}/leave")
+        assert resp.status_code == 200, resp.text
+        assert resp.json()["session_closed"] is False
+
+        row = await aweb_cloud_db.aweb_db.fetch_one(
+            "SELECT body, sender_leaving FROM {{tables.chat_messages}} WHERE message_id = $1",
+            UUID(resp.json()["message_id"]),
+        )
+        assert row["sender_leaving"] is True
+        assert row["body"] == ""
+
+        current.update(did_key="did:aw:bob", alias="bob")
+        resp = await client.post(f"/v1/chat/sessions/{session_id}/leave")
+        assert resp.status_code == 200, resp.text
+        assert resp.json()["session_closed"] is True
+
+        resp = await client.post(f"/v1/chat/sessions/{uuid4()}/leave")
+        assert resp.status_code == 404
+
+
 @pytest.mark.asyncio
 async def test_chat_pending_includes_last_from_stable_id_for_current_sender_key(aweb_cloud_db):
     session_id = uuid4()
@@ -2435,6 +2538,84 @@ async def test_chat_history_and_read_accept_alternate_session_participant_did(aw
     assert read.json()["messages_marked"] == 1
 
 
+@pytest.mark.asyncio
+async def test_chat_mark_unread_moves_read_receipt_back(aweb_cloud_db, monkeypatch):
+    session_id = uuid4()
+    first_id, second_id = uuid4(), uuid4()
+    created_at = datetime.now(timezone.utc) - timedelta(minutes=5)
+    await aweb_cloud_db.aweb_db.execute(
+        """
+        INSERT INTO {{tables.chat_sessions}} (session_id, created_by, created_at)
+        VALUES ($1, 'bob', $2)
+        """,
+        session_id,
+        created_at,
+    )
+    await aweb_cloud_db.aweb_db.execute(
+        """
+        INSERT INTO {{tables.chat_participants}} (session_id, did, alias)
+        VALUES
+            ($1, 'did:key:z6MkAliceCurrent', 'alice'),
+            ($1, 'did:aw:bob', 'bob')
+        """,
+        session_id,
+    )
+    await aweb_cloud_db.aweb_db.execute(
+        """
+        INSERT INTO {{tables.chat_messages}}
+            (message_id, session_id, from_did, from_alias, body, created_at)
+        VALUES
+            ($1, $3, 'did:aw:bob', 'bob', 'first', $4),
+            ($2, $3, 'did:aw:bob', 'bob', 'second', $5)
+        """,
+        first_id,
+        second_id,
+        session_id,
+        created_at + timedelta(minutes=1),
+        created_at + timedelta(minutes=2),
+    )
+
+    app = _build_test_app(aweb_cloud_db.aweb_db, AsyncMock())
+    monkeypatch.setattr(chat_routes, "publish_chat_session_signal", AsyncMock(return_value=1))
+
+    async def _auth_override():
+        return MessagingAuth(
+            did_key="did:key:z6MkAliceCurrent",
+            did_aw="did:aw:alice",
+            address="acme.com/alice",
+        )
+
+    app.dependency_overrides[get_messaging_auth] = _auth_override
+
+    async with AsyncClient(transport=ASGITransport(app=app), base_url="http://test") as client:
+        read = await client.post(
+            f"/v1/chat/sessions/{session_id}/read",
+            json={"up_to_message_id": str(second_id)},
+        )
+        unread = await client.post(
+            f"/v1/chat/sessions/{session_id}/unread",
+            json={"from_message_id": str(second_id)},
+        )
+        again = await client.post(
+            f"/v1/chat/sessions/{session_id}/unread",
+            json={"from_message_id": str(second_id)},
+        )
+        everything = await client.post(
+            f"/v1/chat/sessions/{session_id}/unread",
+            json={"from_message_id": str(first_id)},
+        )
+        pending = await client.get("/v1/chat/pending")
+
+    assert read.status_code == 200, read.text
+    assert unread.status_code == 200, unread.text
+    assert unread.json() == {"session_id": str(session_id), "unread_count": 1}
+    # Repeating the call is a no-op rather than moving the receipt forward.
+    assert again.json()["unread_count"] == 1
+    assert everything.json()["unread_count"] == 2
+    assert pending.status_code == 200, pending.text
+    assert [item["unread_count"] for item in pending.json()["pending"]] == [2]
+
+
 @pytest.mark.asyncio
 async def test_chat_history_includes_sender_stable_identity_for_current_key(aweb_cloud_db):
     session_id = uuid4()
@@ -2562,6 +2743,291 @@ async def test_chat_history_filters_by_message_id(aweb_cloud_db):
     assert [item["body"] for item in body["messages"]] == ["second"]
 
 
+@pytest.mark.asyncio
+async def test_chat_history_filters_by_since_and_after_message_id(aweb_cloud_db):
+    session_id = uuid4()
+    message_ids = [uuid4(), uuid4(), uuid4()]
+    created_at = datetime.now(timezone.utc) - timedelta(minutes=5)
+    await aweb_cloud_db.aweb_db.execute(
+        """
+        INSERT INTO {{tables.chat_sessions}} (session_id, created_by, created_at)
+        VALUES ($1, 'alice', $2)
+        """,
+        session_id,
+        created_at,
+    )
+    await aweb_cloud_db.aweb_db.execute(
+        """
+        INSERT INTO {{tables.chat_participants}} (session_id, did, alias)
+        VALUES
+            ($1, 'did:aw:alice', 'alice'),
+            ($1, 'did:aw:bob', 'bob')
+        """,
+        session_id,
+    )
+    for i, message_id in enumerate(message_ids):
+        await aweb_cloud_db.aweb_db.execute(
+            """
+            INSERT INTO {{tables.chat_messages}}
+                (message_id, session_id, from_did, from_alias, body, created_at)
+            VALUES ($1, $2, 'did:aw:bob', 'bob', $3, $4)
+            """,
+            message_id,
+            session_id,
+            f"message {i}",
+            created_at + timedelta(minutes=i + 1),
+        )
+
+    app = _build_test_app(aweb_cloud_db.aweb_db, AsyncMock())
+
+    async def _auth_override():
+        return MessagingAuth(
+            did_key="did:key:z6MkAliceCurrent",
+            did_aw="did:aw:alice",
+            address="acme.com/alice",
+        )
+
+    app.dependency_overrides[get_messaging_auth] = _auth_override
+
+    since = (created_at + timedelta(minutes=1, seconds=30)).isoformat()
+    async with AsyncClient(transport=ASGITransport(app=app), base_url="http://test") as client:
+        by_since = await client.get(
+            f"/v1/chat/sessions/{session_id}/messages", params={"since": since}
+        )
+        by_after = await client.get(
+            f"/v1/chat/sessions/{session_id}/messages",
+            params={"after_message_id": str(message_ids[1])},
+        )
+        malformed = await client.get(
+            f"/v1/chat/sessions/{session_id}/messages",
+            params={"after_message_id": "not-a-uuid"},
+        )
+
+    assert by_since.status_code == 200, by_since.text
+    assert [m["body"] for m in by_since.json()["messages"]] == ["message 1", "message 2"]
+    assert by_after.status_code == 200, by_after.text
+    assert [m["body"] for m in by_after.json()["messages"]] == ["message 2"]
+    assert malformed.status_code == 422, malformed.text
+    assert malformed.json()["detail"] == "Invalid after_message_id format"
+
+
+@pytest.mark.asyncio
+async def test_chat_history_oldest_first_pages_forward(aweb_cloud_db):
+    session_id = uuid4()
+    message_ids = [uuid4(), uuid4(), uuid4()]
+    created_at = datetime.now(timezone.utc) - timedelta(minutes=5)
+    await aweb_cloud_db.aweb_db.execute(
+        """
+        INSERT INTO {{tables.chat_sessions}} (session_id, created_by, created_at)
+        VALUES ($1, 'alice', $2)
+        """,
+        session_id,
+        created_at,
+    )
+    await aweb_cloud_db.aweb_db.execute(
+        """
+        INSERT INTO {{tables.chat_participants}} (session_id, did, alias)
+        VALUES
+            ($1, 'did:aw:alice', 'alice'),
+            ($1, 'did:aw:bob', 'bob')
+        """,
+        session_id,
+    )
+    for i, message_id in enumerate(message_ids):
+        await aweb_cloud_db.aweb_db.execute(
+            """
+            INSERT INTO {{tables.chat_messages}}
+                (message_id, session_id, from_did, from_alias, body, created_at)
+            VALUES ($1, $2, 'did:aw:bob', 'bob', $3, $4)
+            """,
+            message_id,
+            session_id,
+            f"message {i}",
+            created_at + timedelta(minutes=i + 1),
+        )
+
+    app = _build_test_app(aweb_cloud_db.aweb_db, AsyncMock())
+
+    async def _auth_override():
+        return MessagingAuth(
+            did_key="did:key:z6MkAliceCurrent",
+            did_aw="did:aw:alice",
+            address="acme.com/alice",
+        )
+
+    app.dependency_overrides[get_messaging_auth] = _auth_override
+
+    async with AsyncClient(transport=ASGITransport(app=app), base_url="http://test") as client:
+        latest = await client.get(f"/v1/chat/sessions/{session_id}/messages", params={"limit": 2})
+        first_page = await client.get(
+            f"/v1/chat/sessions/{session_id}/messages",
+            params={"limit": 2, "oldest_first": "true"},
+        )
+        second_page = await client.get(
+            f"/v1/chat/sessions/{session_id}/messages",
+            params={"limit": 2, "oldest_first": "true", "after_message_id": str(message_ids[1])},
+        )
+        tail = await client.get(
+            f"/v1/chat/sessions/{session_id}/messages",
+            params={"limit": 2, "since": created_at.isoformat()},
+        )
+
+    assert latest.status_code == 200, latest.text
+    assert [m["body"] for m in latest.json()["messages"]] == ["message 1", "message 2"]
+    assert latest.json()["oldest_first"] is False
+    assert first_page.status_code == 200, first_page.text
+    assert [m["body"] for m in first_page.json()["messages"]] == ["message 0", "message 1"]
+    assert first_page.json()["oldest_first"] is True
+    assert first_page.json()["next_cursor"] == str(message_ids[1])
+    assert latest.json()["next_cursor"] is None
+    assert second_page.status_code == 200, second_page.text
+    assert [m["body"] for m in second_page.json()["messages"]] == ["message 2"]
+    # Tailing with since pages forward even without oldest_first, so the
+    # earliest new messages are not dropped when more than limit arrived.
+    assert tail.status_code == 200, tail.text
+    assert [m["body"] for m in tail.json()["messages"]] == ["message 0", "message 1"]
+    assert tail.json()["oldest_first"] is True
+    assert tail.json()["next_cursor"] == str(message_ids[1])
+
+
+@pytest.mark.asyncio
+async def test_chat_search_returns_matching_messages_ignoring_case(aweb_cloud_db):
+    session_id = uuid4()
+    created_at = datetime.now(timezone.utc) - timedelta(minutes=5)
+    await aweb_cloud_db.aweb_db.execute(
+        """
+        INSERT INTO {{tables.chat_sessions}} (session_id, created_by, created_at)
+        VALUES ($1, 'alice', $2)
+        """,
+        session_id,
+        created_at,
+    )
+    await aweb_cloud_db.aweb_db.execute(
+        """
+        INSERT INTO {{tables.chat_participants}} (session_id, did, alias)
+        VALUES
+            ($1, 'did:aw:alice', 'alice'),
+            ($1, 'did:aw:bob', 'bob')
+        """,
+        session_id,
+    )
+    for i, body in enumerate(["Deploy is green", "lunch?", "redeploy after the fix", "100% done"]):
+        await aweb_cloud_db.aweb_db.execute(
+            """
+            INSERT INTO {{tables.chat_messages}}
+                (message_id, session_id, from_did, from_alias, body, created_at)
+            VALUES ($1, $2, 'did:aw:bob', 'bob', $3, $4)
+            """,
+            uuid4(),
+            session_id,
+            body,
+            created_at + timedelta(minutes=i + 1),
+        )
+
+    app = _build_test_app(aweb_cloud_db.aweb_db, AsyncMock())
+
+    async def _auth_override():
+        return MessagingAuth(
+            did_key="did:key:z6MkAliceCurrent",
+            did_aw="did:aw:alice",
+            address="acme.com/alice",
+        )
+
+    app.dependency_overrides[get_messaging_auth] = _auth_override
+
+    url = f"/v1/chat/sessions/{session_id}/messages/search"
+    async with AsyncClient(transport=ASGITransport(app=app), base_url="http://test") as client:
+        found = await client.get(url, params={"q": "DEPLOY"})
+        limited = await client.get(url, params={"q": "deploy", "limit": 1})
+        literal = await client.get(url, params={"q": "%"})
+        missing_query = await client.get(url)
+
+    assert found.status_code == 200, found.text
+    assert [m["body"] for m in found.json()["messages"]] == ["Deploy is green", "redeploy after the fix"]
+    assert [m["body"] for m in limited.json()["messages"]] == ["redeploy after the fix"]
+    assert [m["body"] for m in literal.json()["messages"]] == ["100% done"]
+    assert missing_query.status_code == 422, missing_query.text
+
+
+@pytest.mark.asyncio
+async def test_chat_nudge_flags_only_participants_with_unread_messages(aweb_cloud_db):
+    session_id = uuid4()
+    first_id, latest_id = uuid4(), uuid4()
+    created_at = datetime.now(timezone.utc) - timedelta(minutes=5)
+    await aweb_cloud_db.aweb_db.execute(
+        """
+        INSERT INTO {{tables.chat_sessions}} (session_id, created_by, created_at)
+        VALUES ($1, 'alice', $2)
+        """,
+        session_id,
+        created_at,
+    )
+    await aweb_cloud_db.aweb_db.execute(
+        """
+        INSERT INTO {{tables.chat_participants}} (session_id, did, alias)
+        VALUES
+            ($1, 'did:aw:alice', 'alice'),
+            ($1, 'did:aw:bob', 'bob'),
+            ($1, 'did:aw:carol', 'carol')
+        """,
+        session_id,
+    )
+    for i, message_id in enumerate((first_id, latest_id)):
+        await aweb_cloud_db.aweb_db.execute(
+            """
+            INSERT INTO {{tables.chat_messages}}
+                (message_id, session_id, from_did, from_alias, body, created_at)
+            VALUES ($1, $2, 'did:aw:alice', 'alice', $3, $4)
+            """,
+            message_id,
+            session_id,
+            f"message {i}",
+            created_at + timedelta(minutes=i + 1),
+        )
+    # bob is caught up; carol only read the first message.
+    await aweb_cloud_db.aweb_db.execute(
+        """
+        INSERT INTO {{tables.chat_read_receipts}} (session_id, did, last_read_message_id)
+        VALUES ($1, 'did:aw:bob', $2), ($1, 'did:aw:carol', $3)
+        """,
+        session_id,
+        latest_id,
+        first_id,
+    )
+
+    app = _build_test_app(aweb_cloud_db.aweb_db, AsyncMock())
+
+    async def _auth_override():
+        return MessagingAuth(
+            did_key="did:key:z6MkAliceCurrent",
+            did_aw="did:aw:alice",
+            address="acme.com/alice",
+        )
+
+    app.dependency_overrides[get_messaging_auth] = _auth_override
+
+    async with AsyncClient(transport=ASGITransport(app=app), base_url="http://test") as client:
+        resp = await client.post(f"/v1/chat/sessions/{session_id}/nudge")
+        missing = await client.post(f"/v1/chat/sessions/{uuid4()}/nudge")
+
+    assert resp.status_code == 200, resp.text
+    assert resp.json() == {"session_id": str(session_id), "nudged": ["carol"]}
+    assert missing.status_code == 404, missing.text
+
+    rows = await aweb_cloud_db.aweb_db.fetch_all(
+        """
+        SELECT alias, nudged_at
+        FROM {{tables.chat_participants}}
+        WHERE session_id = $1
+        """,
+        session_id,
+    )
+    nudged = {row["alias"]: row["nudged_at"] for row in rows}
+    assert nudged["carol"] is not None
+    assert nudged["bob"] is None
+    assert nudged["alice"] is None
+
+
 @pytest.mark.asyncio
 async def test_chat_stream_accepts_alternate_session_participant_did(aweb_cloud_db, monkeypatch):
     session_id = uuid4()
@@ -2763,5 +3229,77 @@ async def test_chat_session_list_accepts_alternate_session_participant_did(aweb_
             "participant_addresses": ["acme.com/bob"],
             "created_at": created_at.strftime("%Y-%m-%dT%H:%M:%SZ"),
             "sender_waiting": False,
+            "subject": "",
+            "metadata": {},
+            "last_activity": "",
+            "last_message": "",
+            "last_from": "",
+            "unread_count": 0,
         }
     ]
+
+
+@pytest.mark.asyncio
+async def test_chat_session_list_reports_last_message_and_unread_count(aweb_cloud_db):
+    session_id = uuid4()
+    created_at = datetime.now(timezone.utc) - timedelta(minutes=10)
+    await aweb_cloud_db.aweb_db.execute(
+        """
+        INSERT INTO {{tables.chat_sessions}} (session_id, created_by, created_at)
+        VALUES ($1, 'alice', $2)
+        """,
+        session_id,
+        created_at,
+    )
+    await aweb_cloud_db.aweb_db.execute(
+        """
+        INSERT INTO {{tables.chat_participants}} (session_id, did, alias)
+        VALUES
+            ($1, 'did:aw:alice', 'alice'),
+            ($1, 'did:aw:bob', 'bob')
+        """,
+        session_id,
+    )
+    read_id = uuid4()
+    await aweb_cloud_db.aweb_db.execute(
+        """
+        INSERT INTO {{tables.chat_messages}}
+            (message_id, session_id, from_did, from_alias, body, created_at)
+        VALUES
+            ($1, $2, 'did:aw:bob', 'bob', 'first', $3),
+            ($4, $2, 'did:aw:bob', 'bob', 'second', $5),
+            ($6, $2, 'did:aw:bob', 'bob', 'third', $7)
+        """,
+        read_id,
+        session_id,
+        created_at + timedelta(minutes=1),
+        uuid4(),
+        created_at + timedelta(minutes=2),
+        uuid4(),
+        created_at + timedelta(minutes=3),
+    )
+    await aweb_cloud_db.aweb_db.execute(
+        """
+        INSERT INTO {{tables.chat_read_receipts}} (session_id, did, last_read_message_id)
+        VALUES ($1, 'did:aw:alice', $2)
+        """,
+        session_id,
+        read_id,
+    )
+
+    app = _build_test_app(aweb_cloud_db.aweb_db, AsyncMock())
+
+    async def _auth_override():
+        return MessagingAuth(did_key="did:key:alice", did_aw="did:aw:alice", address="acme.com/alice")
+
+    app.dependency_overrides[get_messaging_auth] = _auth_override
+
+    async with AsyncClient(transport=ASGITransport(app=app), base_url="http://test") as client:
+        resp = await client.get("/v1/chat/sessions")
+
+    assert resp.status_code == 200, resp.text
+    session = resp.json()["sessions"][0]
+    assert session["last_message"] == "third"
+    assert session["last_from"] == "bob"
+    assert session["last_activity"] == (created_at + timedelta(minutes=3)).strftime("%Y-%m-%dT%H:%M:%SZ")
+    assert session["unread_count"] == 2
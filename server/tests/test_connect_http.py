@@ -6,7 +6,7 @@ import base64
 import json
 from datetime import datetime, timezone
 from unittest.mock import AsyncMock
-from uuid import uuid4
+from uuid import UUID, uuid4
 
 import pytest
 from httpx import ASGITransport, AsyncClient
@@ -390,6 +390,51 @@ async def test_connect_http_reuses_existing_agent_for_same_alias(aweb_cloud_db):
     assert row["status"] == "active"
 
 
+@pytest.mark.asyncio
+async def test_connect_http_stores_agent_metadata(aweb_cloud_db):
+    """Metadata sent on connect is stored; a later connect without it keeps it."""
+    team_sk, _, team_did_key = _make_keypair()
+    agent_sk, _, agent_did_key = _make_keypair()
+
+    cert_header = _encode_certificate(
+        _make_certificate(
+            team_sk, team_did_key, agent_did_key,
+            team_id="backend:acme.com",
+            alias="alice",
+            lifetime="persistent",
+        )
+    )
+    app = _build_test_app(aweb_cloud_db.aweb_db, team_did_key)
+
+    async def connect(body):
+        body_bytes = json.dumps(body).encode()
+        headers = _signed_request(agent_sk, agent_did_key, "backend:acme.com", body_bytes)
+        headers["X-AWID-Team-Certificate"] = cert_header
+        async with AsyncClient(
+            transport=ASGITransport(app=app), base_url="http://test"
+        ) as client:
+            return await client.post("/v1/connect", content=body_bytes, headers={**headers, "Content-Type": "application/json"})
+
+    resp = await connect({"hostname": "Mac.local", "metadata": {"model": "m-1", "capabilities": ["code"]}})
+    assert resp.status_code == 200, resp.text
+    agent_id = resp.json()["agent_id"]
+
+    resp = await connect({"hostname": "Mac.local"})
+    assert resp.status_code == 200, resp.text
+
+    row = await aweb_cloud_db.aweb_db.fetch_one(
+        "SELECT metadata_json FROM {{tables.agents}} WHERE agent_id = $1",
+        UUID(agent_id),
+    )
+    stored = row["metadata_json"]
+    if isinstance(stored, str):
+        stored = json.loads(stored)
+    assert stored == {"model": "m-1", "capabilities": ["code"]}
+
+    resp = await connect({"hostname": "Mac.local", "metadata": {"blob": "x" * 70000}})
+    assert resp.status_code == 422
+
+
 @pytest.mark.asyncio
 async def test_connect_http_missing_cert_returns_401(aweb_cloud_db):
     """Request without certificate header returns 401."""
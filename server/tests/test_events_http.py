@@ -531,6 +531,71 @@ async def test_current_actionable_chat_uses_per_session_participant_lists(aweb_c
     ]
 
 
+@pytest.mark.asyncio
+async def test_current_actionable_chat_interrupts_for_urgent_messages(aweb_cloud_db, monkeypatch):
+    class _DbShim:
+        def __init__(self, aweb_db):
+            self._aweb_db = aweb_db
+
+        def get_manager(self, name="aweb"):
+            assert name == "aweb"
+            return self._aweb_db
+
+    await aweb_cloud_db.aweb_db.execute(
+        """
+        INSERT INTO {{tables.teams}} (team_id, namespace, team_name, team_did_key)
+        VALUES ('backend:acme.com', 'acme.com', 'backend', 'did:key:team')
+        """
+    )
+    await aweb_cloud_db.aweb_db.execute(
+        """
+        INSERT INTO {{tables.chat_sessions}} (session_id, team_id, created_by)
+        VALUES
+            ('11111111-1111-4111-8111-111111111111', 'backend:acme.com', 'did:aw:alice'),
+            ('22222222-2222-4222-8222-222222222222', 'backend:acme.com', 'did:aw:carol')
+        """
+    )
+    await aweb_cloud_db.aweb_db.execute(
+        """
+        INSERT INTO {{tables.chat_participants}} (session_id, did, alias)
+        VALUES
+            ('11111111-1111-4111-8111-111111111111', 'did:aw:bob', 'bob'),
+            ('11111111-1111-4111-8111-111111111111', 'did:aw:alice', 'alice'),
+            ('22222222-2222-4222-8222-222222222222', 'did:aw:bob', 'bob'),
+            ('22222222-2222-4222-8222-222222222222', 'did:aw:carol', 'carol')
+        """
+    )
+    await aweb_cloud_db.aweb_db.execute(
+        """
+        INSERT INTO {{tables.chat_messages}} (session_id, from_did, from_alias, body, priority)
+        VALUES
+            ('11111111-1111-4111-8111-111111111111', 'did:aw:alice', 'alice', 'prod is down', 'urgent'),
+            ('22222222-2222-4222-8222-222222222222', 'did:aw:carol', 'carol', 'lunch?', 'normal')
+        """
+    )
+
+    async def _no_waiting_agents(_redis, session_id: str, participant_dids: list[str]):
+        return []
+
+    monkeypatch.setattr(events_module, "get_waiting_agents", _no_waiting_agents)
+
+    actionable = await events_module._current_actionable_chat(
+        _DbShim(aweb_cloud_db.aweb_db),
+        None,
+        participant_dids=["did:aw:bob"],
+        viewer_team_id="backend:acme.com",
+        participant_agent_id=None,
+    )
+
+    by_session = {item["session_id"]: item for item in actionable}
+    urgent = by_session["11111111-1111-4111-8111-111111111111"]
+    assert urgent["priority"] == "urgent"
+    assert urgent["wake_mode"] == "interrupt"
+    normal = by_session["22222222-2222-4222-8222-222222222222"]
+    assert normal["priority"] == "normal"
+    assert normal["wake_mode"] == "prompt"
+
+
 @pytest.mark.asyncio
 async def test_current_actionable_chat_includes_from_stable_id_for_current_sender_key(aweb_cloud_db, monkeypatch):
     class _DbShim:
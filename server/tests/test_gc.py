@@ -238,6 +238,13 @@ async def test_gc_inactive_scopes_hard_deletes_populated_team(aweb_cloud_db):
         agent_id,
         created_at,
     )
+    await aweb_db.execute(
+        """
+        INSERT INTO {{tables.reservation_fencing}} (team_id, resource_key, last_token)
+        VALUES ($1, 'repo:backend', 1)
+        """,
+        team_id,
+    )
     await aweb_db.execute(
         """
         INSERT INTO {{tables.team_roles}} (
@@ -314,6 +321,7 @@ async def test_gc_inactive_scopes_hard_deletes_populated_team(aweb_cloud_db):
         "task_root_counters": ("SELECT COUNT(*) AS count FROM aweb.task_root_counters WHERE team_id = $1", (team_id,)),
         "task_claims": ("SELECT COUNT(*) AS count FROM aweb.task_claims WHERE team_id = $1", (team_id,)),
         "reservations": ("SELECT COUNT(*) AS count FROM aweb.reservations WHERE team_id = $1", (team_id,)),
+        "reservation_fencing": ("SELECT COUNT(*) AS count FROM aweb.reservation_fencing WHERE team_id = $1", (team_id,)),
         "team_roles": ("SELECT COUNT(*) AS count FROM aweb.team_roles WHERE team_id = $1", (team_id,)),
         "team_instructions": ("SELECT COUNT(*) AS count FROM aweb.team_instructions WHERE team_id = $1", (team_id,)),
         "audit_log": ("SELECT COUNT(*) AS count FROM aweb.audit_log WHERE team_id = $1", (team_id,)),
@@ -22,6 +22,7 @@ from aweb.identity_auth_deps import (
     get_identity_auth,
     get_messaging_auth,
 )
+from aweb.messaging.messages import attachments_digest
 from aweb.routes.messages import router as messages_router
 
 
@@ -322,6 +323,43 @@ async def test_messages_inbox_filters_by_message_id(aweb_cloud_db):
     assert [item["message_id"] for item in body["messages"]] == ["22222222-2222-2222-2222-222222222222"]
 
 
+@pytest.mark.asyncio
+async def test_get_message_returns_one_message_or_404(aweb_cloud_db):
+    alice_sk, _, alice_did_key = _make_keypair()
+    registry = AsyncMock()
+    registry.resolve_key = AsyncMock(return_value=KeyResolution(did_aw="did:aw:alice", current_did_key=alice_did_key))
+    registry.list_did_addresses = AsyncMock(return_value=[])
+    app = _build_test_app(aweb_cloud_db.aweb_db, registry)
+
+    await aweb_cloud_db.aweb_db.execute(
+        """
+        INSERT INTO {{tables.messages}} (
+            message_id, from_did, to_did, from_alias, to_alias, subject, body, priority
+        )
+        VALUES
+            ('11111111-1111-1111-1111-111111111111', 'did:aw:bob', 'did:aw:alice', 'bob', 'alice', 'first', 'one', 'normal'),
+            ('22222222-2222-2222-2222-222222222222', 'did:aw:bob', 'did:aw:carol', 'bob', 'carol', 'other', 'two', 'normal')
+        """
+    )
+
+    async with AsyncClient(transport=ASGITransport(app=app), base_url="http://test") as client:
+        resp = await client.get(
+            "/v1/messages/11111111-1111-1111-1111-111111111111",
+            headers=_signed_identity_headers(alice_sk, alice_did_key, "did:aw:alice"),
+        )
+        other = await client.get(
+            "/v1/messages/22222222-2222-2222-2222-222222222222",
+            headers=_signed_identity_headers(alice_sk, alice_did_key, "did:aw:alice"),
+        )
+
+    assert resp.status_code == 200, resp.text
+    body = resp.json()
+    assert body["message_id"] == "11111111-1111-1111-1111-111111111111"
+    assert body["body"] == "one"
+    assert body["read_at"] is None
+    assert other.status_code == 404, other.text
+
+
 @pytest.mark.asyncio
 async def test_send_message_mutation_context_includes_from_did_aw(aweb_cloud_db):
     alice_sk, _, alice_did_key = _make_keypair()
@@ -2602,6 +2640,85 @@ async def test_messages_inbox_and_ack_accept_ephemeral_cert_auth(aweb_cloud_db):
     assert row["read_at"] is not None
 
 
+@pytest.mark.asyncio
+async def test_bulk_ack_reports_each_message(aweb_cloud_db):
+    team_sk, _, team_did_key = _make_keypair()
+    alice_sk, _, alice_did_key = _make_keypair()
+    await aweb_cloud_db.aweb_db.execute(
+        """
+        INSERT INTO {{tables.teams}} (team_id, namespace, team_name, team_did_key)
+        VALUES ('default:local', 'local', 'default', $1)
+        """,
+        team_did_key,
+    )
+    await aweb_cloud_db.aweb_db.execute(
+        """
+        INSERT INTO {{tables.agents}} (
+            team_id, did_key, did_aw, address, alias, lifetime, role, messaging_policy
+        )
+        VALUES (
+            'default:local', $1, NULL, NULL, 'alice',
+            'ephemeral', 'developer', 'everyone'
+        )
+        """,
+        alice_did_key,
+    )
+    for message_id, to_did in (
+        ("55555555-5555-5555-5555-555555555555", alice_did_key),
+        ("66666666-6666-6666-6666-666666666666", "did:key:carol"),
+    ):
+        await aweb_cloud_db.aweb_db.execute(
+            """
+            INSERT INTO {{tables.messages}} (
+                message_id, from_did, to_did, from_alias, to_alias, subject, body, priority
+            )
+            VALUES ($1, 'did:key:bob', $2, 'bob', 'alice', 'bulk', 'hello', 'normal')
+            """,
+            UUID(message_id),
+            to_did,
+        )
+
+    cert = _make_certificate(
+        team_sk,
+        team_did_key,
+        alice_did_key,
+        team_id="default:local",
+        alias="alice",
+        lifetime="ephemeral",
+    )
+    cert_header = _encode_certificate(cert)
+    registry = AsyncMock()
+    registry.get_team_public_key = AsyncMock(return_value=team_did_key)
+    registry.get_team_revocations = AsyncMock(return_value=set())
+    app = _build_test_app(aweb_cloud_db.aweb_db, registry)
+
+    payload = {
+        "message_ids": [
+            "55555555-5555-5555-5555-555555555555",
+            "66666666-6666-6666-6666-666666666666",
+            "not-a-uuid",
+        ]
+    }
+    body_bytes = json.dumps(payload).encode()
+    headers = {
+        **_signed_team_headers(alice_sk, alice_did_key, "default:local", cert_header, body_bytes),
+        "Content-Type": "application/json",
+    }
+    async with AsyncClient(transport=ASGITransport(app=app), base_url="http://test") as client:
+        resp = await client.post("/v1/messages/ack", content=body_bytes, headers=headers)
+
+    assert resp.status_code == 200, resp.text
+    results = resp.json()["results"]
+    assert [r["message_id"] for r in results] == payload["message_ids"]
+    assert results[0].get("acknowledged_at") and "error" not in results[0]
+    assert results[1]["error"] == "Message not found"
+    assert results[2]["error"] == "Invalid message_id format"
+    rows = await aweb_cloud_db.aweb_db.fetch_all(
+        "SELECT message_id, read_at FROM {{tables.messages}} ORDER BY message_id"
+    )
+    assert [row["read_at"] is not None for row in rows] == [True, False]
+
+
 @pytest.mark.asyncio
 async def test_send_message_requires_timestamp_when_signature_is_provided(aweb_cloud_db):
     alice_sk, _, alice_did_key = _make_keypair()
@@ -3375,3 +3492,251 @@ async def test_send_message_to_private_address_uses_client_recipient_binding(awe
     )
     assert row["to_did"] == "did:aw:bob"
     assert row["to_alias"] == "bob"
+
+
+@pytest.mark.asyncio
+async def test_send_message_retry_with_same_message_id_is_idempotent(aweb_cloud_db):
+    _, _, bob_did_key = _make_keypair()
+    await aweb_cloud_db.aweb_db.execute(
+        """
+        INSERT INTO {{tables.teams}} (team_id, namespace, team_name, team_did_key)
+        VALUES ('ops:otherco.com', 'otherco.com', 'ops', 'did:key:team')
+        """
+    )
+    await aweb_cloud_db.aweb_db.execute(
+        """
+        INSERT INTO {{tables.agents}} (
+            team_id, did_key, did_aw, address, alias, lifetime, role, messaging_policy
+        )
+        VALUES (
+            'ops:otherco.com', $1, 'did:aw:bob', 'otherco.com/bob', 'bob',
+            'persistent', 'developer', 'everyone'
+        )
+        """,
+        bob_did_key,
+    )
+
+    app = _build_test_app(aweb_cloud_db.aweb_db, AsyncMock())
+    hooks: list[str] = []
+
+    async def _on_mutation(event_type, _context):
+        hooks.append(event_type)
+
+    app.state.on_mutation = _on_mutation
+    sender = {"did_aw": "did:aw:alice"}
+
+    async def _auth_override():
+        return MessagingAuth(
+            did_key="did:key:z6MkSenderCurrent",
+            did_aw=sender["did_aw"],
+            address=None,
+        )
+
+    app.dependency_overrides[get_messaging_auth] = _auth_override
+
+    message_id = str(uuid4())
+    payload = {"to_did": "did:aw:bob", "subject": "retried send", "body": "hi", "message_id": message_id}
+    async with AsyncClient(transport=ASGITransport(app=app), base_url="http://test") as client:
+        first = await client.post("/v1/messages", json=payload)
+        retry = await client.post("/v1/messages", json=payload)
+        changed = await client.post("/v1/messages", json={**payload, "body": "different"})
+        sender["did_aw"] = "did:aw:mallory"
+        collision = await client.post("/v1/messages", json={**payload, "subject": "hijack"})
+
+    assert first.status_code == 200, first.text
+    assert retry.status_code == 200, retry.text
+    assert first.json()["message_id"] == message_id
+    assert retry.json()["message_id"] == message_id
+    assert retry.json()["delivered_at"] == first.json()["delivered_at"]
+    assert hooks == ["message.sent"]
+    assert changed.status_code == 409, changed.text
+    assert changed.json()["detail"] == "message_id was already used for a different message"
+    assert collision.status_code == 409, collision.text
+    assert collision.json()["detail"] == "message_id is already in use"
+
+    rows = await aweb_cloud_db.aweb_db.fetch_all(
+        "SELECT from_did, subject FROM {{tables.messages}} WHERE message_id = $1",
+        UUID(message_id),
+    )
+    assert [(row["from_did"], row["subject"]) for row in rows] == [("did:aw:alice", "retried send")]
+
+
+@pytest.mark.asyncio
+async def test_send_message_stores_attachments_and_checks_signed_digest(aweb_cloud_db):
+    _, _, bob_did_key = _make_keypair()
+    await aweb_cloud_db.aweb_db.execute(
+        """
+        INSERT INTO {{tables.teams}} (team_id, namespace, team_name, team_did_key)
+        VALUES ('ops:otherco.com', 'otherco.com', 'ops', 'did:key:team')
+        """
+    )
+    await aweb_cloud_db.aweb_db.execute(
+        """
+        INSERT INTO {{tables.agents}} (
+            team_id, did_key, did_aw, address, alias, lifetime, role, messaging_policy
+        )
+        VALUES (
+            'ops:otherco.com', $1, 'did:aw:bob', 'otherco.com/bob', 'bob',
+            'persistent', 'developer', 'everyone'
+        )
+        """,
+        bob_did_key,
+    )
+
+    app = _build_test_app(aweb_cloud_db.aweb_db, AsyncMock())
+    caller = {"did_aw": "did:aw:alice", "did_key": "did:key:z6MkAliceCurrent"}
+
+    async def _auth_override():
+        return MessagingAuth(did_key=caller["did_key"], did_aw=caller["did_aw"], address=None)
+
+    app.dependency_overrides[get_messaging_auth] = _auth_override
+
+    attachments = [
+        {
+            "name": "report.json",
+            "content_type": "application/json",
+            "data": base64.b64encode(b'{"ok":true}').decode(),
+        },
+        {"name": "log", "url": "https://example.com/log.txt"},
+    ]
+
+    def _signed(message_id: str, timestamp: str, digest: str | None) -> dict:
+        fields = {
+            "body": "report attached",
+            "from": "did:aw:alice",
+            "from_did": "did:aw:alice",
+            "message_id": message_id,
+            "subject": "signed report",
+            "timestamp": timestamp,
+            "to": "did:aw:bob",
+            "to_did": "did:aw:bob",
+            "type": "mail",
+        }
+        if digest is not None:
+            fields["attachments_sha256"] = digest
+        return {
+            "to_did": "did:aw:bob",
+            "subject": "signed report",
+            "body": "report attached",
+            "from_did": "did:aw:alice",
+            "message_id": message_id,
+            "timestamp": timestamp,
+            "signature": "test-signature",
+            "signed_payload": canonical_json_bytes(fields).decode(),
+            "attachments": attachments,
+        }
+
+    timestamp = datetime.now(timezone.utc).replace(microsecond=0).isoformat()
+    async with AsyncClient(transport=ASGITransport(app=app), base_url="http://test") as client:
+        signed = await client.post(
+            "/v1/messages", json=_signed(str(uuid4()), timestamp, attachments_digest(attachments))
+        )
+        undigested = await client.post("/v1/messages", json=_signed(str(uuid4()), timestamp, None))
+        ambiguous = await client.post(
+            "/v1/messages",
+            json={
+                "to_did": "did:aw:bob",
+                "body": "bad attachment",
+                "attachments": [{"name": "x", "data": "eA==", "url": "https://example.com/x"}],
+            },
+        )
+        caller.update(did_aw="did:aw:bob", did_key=bob_did_key)
+        inbox = await client.get("/v1/messages/inbox")
+
+    assert signed.status_code == 200, signed.text
+    assert undigested.status_code == 422, undigested.text
+    assert undigested.json()["detail"] == "signed_payload attachments_sha256 must match the attachments"
+    assert ambiguous.status_code == 422, ambiguous.text
+
+    assert inbox.status_code == 200, inbox.text
+    messages = inbox.json()["messages"]
+    assert len(messages) == 1
+    assert messages[0]["message_id"] == signed.json()["message_id"]
+    assert messages[0]["attachments"] == attachments
+
+
+@pytest.mark.asyncio
+async def test_send_message_response_names_the_resolved_recipient(aweb_cloud_db):
+    await aweb_cloud_db.aweb_db.execute(
+        """
+        INSERT INTO {{tables.teams}} (team_id, namespace, team_name, team_did_key)
+        VALUES ('backend:acme.com', 'acme.com', 'backend', 'did:key:team')
+        """
+    )
+    bob = await aweb_cloud_db.aweb_db.fetch_one(
+        """
+        INSERT INTO {{tables.agents}} (
+            team_id, did_key, did_aw, address, alias, lifetime, role, messaging_policy
+        )
+        VALUES (
+            'backend:acme.com', 'did:key:z6MkBob', 'did:aw:bob', 'acme.com/bob', 'bob',
+            'persistent', 'developer', 'everyone'
+        )
+        RETURNING agent_id
+        """
+    )
+
+    app = _build_test_app(aweb_cloud_db.aweb_db, AsyncMock())
+
+    async def _auth_override():
+        return MessagingAuth(
+            did_key="did:key:z6MkAliceCurrent",
+            did_aw="did:aw:alice",
+            address="acme.com/alice",
+            team_id="backend:acme.com",
+            alias="alice",
+        )
+
+    app.dependency_overrides[get_messaging_auth] = _auth_override
+
+    async with AsyncClient(transport=ASGITransport(app=app), base_url="http://test") as client:
+        resp = await client.post("/v1/messages", json={"to_alias": "bob", "body": "hi"})
+
+    assert resp.status_code == 200, resp.text
+    assert resp.json()["to_agent_id"] == str(bob["agent_id"])
+    assert resp.json()["to_alias"] == "bob"
+
+
+@pytest.mark.asyncio
+async def test_send_message_to_ambiguous_local_address_returns_structured_409(aweb_cloud_db):
+    await aweb_cloud_db.aweb_db.execute(
+        """
+        INSERT INTO {{tables.teams}} (team_id, namespace, team_name, team_did_key)
+        VALUES
+            ('backend:acme.com', 'acme.com', 'backend', 'did:key:team-backend'),
+            ('ops:acme.com', 'acme.com', 'ops', 'did:key:team-ops')
+        """
+    )
+    await aweb_cloud_db.aweb_db.execute(
+        """
+        INSERT INTO {{tables.agents}} (
+            team_id, did_key, did_aw, address, alias, lifetime, role, messaging_policy
+        )
+        VALUES
+            ('backend:acme.com', 'did:key:z6MkBobBackend', NULL, NULL, 'bob', 'ephemeral', 'developer', 'everyone'),
+            ('ops:acme.com', 'did:key:z6MkBobOps', NULL, NULL, 'bob', 'ephemeral', 'developer', 'everyone')
+        """
+    )
+
+    app = _build_test_app(aweb_cloud_db.aweb_db, None)
+
+    async def _auth_override():
+        return MessagingAuth(
+            did_key="did:key:z6MkAliceCurrent",
+            did_aw="did:aw:alice",
+            address="acme.com/alice",
+        )
+
+    app.dependency_overrides[get_messaging_auth] = _auth_override
+
+    async with AsyncClient(transport=ASGITransport(app=app), base_url="http://test") as client:
+        resp = await client.post("/v1/messages", json={"to_address": "acme.com/bob", "body": "hi"})
+
+    assert resp.status_code == 409, resp.text
+    detail = resp.json()["detail"]
+    assert detail["code"] == "ambiguous_recipient"
+    assert "acme.com/bob" in detail["message"]
+    assert sorted(candidate["alias"] for candidate in detail["candidates"]) == ["bob", "bob"]
+    assert len({candidate["agent_id"] for candidate in detail["candidates"]}) == 2
+    count = await aweb_cloud_db.aweb_db.fetch_one("SELECT COUNT(*) AS n FROM {{tables.messages}}")
+    assert count["n"] == 0
@@ -114,3 +114,59 @@ async def test_list_online_workspaces_filters_by_team_id(monkeypatch):
 
     assert [workspace.alias for workspace in response.workspaces] == ["ivy"]
     assert response.workspaces[0].team_id == "default:acme.com"
+
+
+class _ConnectedRequest:
+    async def is_disconnected(self) -> bool:
+        return False
+
+
+def _presence_chunk(chunk: str):
+    lines = chunk.strip().splitlines()
+    return lines[0].removeprefix("event: "), json.loads(lines[1].removeprefix("data: "))
+
+
+@pytest.mark.asyncio
+async def test_presence_stream_emits_snapshot_then_transitions(monkeypatch):
+    def _view(agent_id: str, *, online: bool, last_seen: str | None = None):
+        return agent_routes.AgentView(
+            agent_id=agent_id,
+            alias=agent_id,
+            did_key=f"did:key:{agent_id}",
+            online=online,
+            status="active" if online else "offline",
+            last_seen=last_seen,
+        )
+
+    rosters = iter(
+        [
+            [_view("ivy", online=True, last_seen="t1"), _view("max", online=False)],
+            [_view("ivy", online=True, last_seen="t2"), _view("max", online=True, last_seen="t2")],
+            [_view("ivy", online=False), _view("max", online=True, last_seen="t2")],
+        ]
+    )
+    seen_teams: list[str] = []
+
+    async def _roster(_db, _redis, *, team_id: str):
+        seen_teams.append(team_id)
+        return next(rosters)
+
+    monkeypatch.setattr(agent_routes, "_team_agent_views", _roster)
+    monkeypatch.setattr(agent_routes, "PRESENCE_STREAM_POLL_SECONDS", 0)
+
+    stream = agent_routes._sse_presence_events(
+        request=_ConnectedRequest(), db=None, redis=None, team_id="default:acme.com"
+    )
+    event, payload = _presence_chunk(await anext(stream))
+    assert event == "snapshot"
+    assert [a["alias"] for a in payload["agents"]] == ["ivy"]
+
+    events = [_presence_chunk(await anext(stream)) for _ in range(3)]
+    assert [(e, p["alias"]) for e, p in events] == [
+        ("last_seen", "ivy"),
+        ("online", "max"),
+        ("offline", "ivy"),
+    ]
+    assert events[2][1]["online"] is False
+    assert set(seen_teams) == {"default:acme.com"}
+    await stream.aclose()
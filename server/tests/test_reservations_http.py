@@ -0,0 +1,417 @@
+from __future__ import annotations
+
+import json
+from datetime import datetime, timedelta, timezone
+from uuid import uuid4
+
+import pytest
+from fastapi import FastAPI
+from httpx import ASGITransport, AsyncClient
+
+import aweb.routes.reservations as reservations_module
+from aweb.reservation_heartbeat import renew_reservations_from_header
+from aweb.routes.reservations import router as reservations_router
+from aweb.team_auth_deps import TeamIdentity, get_team_identity
+
+
+TEAM_ID = "backend:acme.com"
+ALICE_ID = str(uuid4())
+BOB_ID = str(uuid4())
+
+
+class _DbShim:
+    def __init__(self, aweb_db) -> None:
+        self._db = aweb_db
+
+    def get_manager(self, name: str = "aweb"):
+        return self._db
+
+
+def _identity(alias: str, agent_id: str) -> TeamIdentity:
+    return TeamIdentity(
+        team_id=TEAM_ID,
+        alias=alias,
+        did_key=f"did:key:z6Mk{alias}",
+        did_aw=f"did:aw:{alias}",
+        address=f"acme.com/{alias}",
+        agent_id=agent_id,
+        lifetime="persistent",
+        certificate_id=f"cert-{alias}",
+    )
+
+
+def _build_reservations_app(aweb_db, identity: TeamIdentity) -> FastAPI:
+    app = FastAPI()
+    app.include_router(reservations_router)
+    app.state.db = _DbShim(aweb_db)
+    app.state.on_mutation = None
+
+    async def _identity_override():
+        return identity
+
+    app.dependency_overrides[get_team_identity] = _identity_override
+    return app
+
+
+async def _insert_reservation(aweb_db, *, resource_key: str, expires_at: datetime) -> None:
+    await aweb_db.execute(
+        """
+        INSERT INTO {{tables.reservations}}
+            (team_id, resource_key, holder_alias, holder_agent_id, acquired_at, expires_at, metadata_json)
+        VALUES ($1, $2, 'bob', $3, $4, $5, $6::jsonb)
+        """,
+        TEAM_ID,
+        resource_key,
+        BOB_ID,
+        expires_at - timedelta(hours=1),
+        expires_at,
+        json.dumps({"reason": "deploy"}),
+    )
+
+
+@pytest.mark.asyncio
+async def test_acquire_with_steal_expired_reports_takeover(aweb_cloud_db):
+    expired_at = datetime.now(timezone.utc) - timedelta(minutes=5)
+    await _insert_reservation(aweb_cloud_db.aweb_db, resource_key="deploy/prod", expires_at=expired_at)
+    app = _build_reservations_app(aweb_cloud_db.aweb_db, _identity("alice", ALICE_ID))
+
+    async with AsyncClient(transport=ASGITransport(app=app), base_url="http://test") as client:
+        resp = await client.post(
+            "/v1/reservations",
+            json={"resource_key": "deploy/prod", "ttl_seconds": 60, "steal_expired": True},
+        )
+
+    assert resp.status_code == 200, resp.text
+    body = resp.json()
+    assert body["status"] == "stolen"
+    assert body["holder_agent_id"] == ALICE_ID
+    assert body["stolen_from_agent_id"] == BOB_ID
+    assert body["stolen_from_alias"] == "bob"
+    assert datetime.fromisoformat(body["stolen_from_expires_at"]) == expired_at
+    row = await aweb_cloud_db.aweb_db.fetch_one(
+        "SELECT holder_alias FROM {{tables.reservations}} WHERE team_id = $1 AND resource_key = $2",
+        TEAM_ID,
+        "deploy/prod",
+    )
+    assert row["holder_alias"] == "alice"
+
+
+@pytest.mark.asyncio
+async def test_acquire_without_steal_expired_keeps_acquired_status(aweb_cloud_db):
+    expired_at = datetime.now(timezone.utc) - timedelta(minutes=5)
+    await _insert_reservation(aweb_cloud_db.aweb_db, resource_key="deploy/prod", expires_at=expired_at)
+    app = _build_reservations_app(aweb_cloud_db.aweb_db, _identity("alice", ALICE_ID))
+
+    async with AsyncClient(transport=ASGITransport(app=app), base_url="http://test") as client:
+        resp = await client.post("/v1/reservations", json={"resource_key": "deploy/prod", "ttl_seconds": 60})
+
+    assert resp.status_code == 200, resp.text
+    body = resp.json()
+    assert body["status"] == "acquired"
+    assert body["holder_agent_id"] == ALICE_ID
+    assert body["stolen_from_agent_id"] is None
+    assert body["stolen_from_alias"] is None
+
+
+@pytest.mark.asyncio
+async def test_acquire_never_steals_a_live_lock(aweb_cloud_db):
+    expires_at = datetime.now(timezone.utc) + timedelta(minutes=5)
+    await _insert_reservation(aweb_cloud_db.aweb_db, resource_key="deploy/prod", expires_at=expires_at)
+    app = _build_reservations_app(aweb_cloud_db.aweb_db, _identity("alice", ALICE_ID))
+
+    async with AsyncClient(transport=ASGITransport(app=app), base_url="http://test") as client:
+        resp = await client.post(
+            "/v1/reservations",
+            json={"resource_key": "deploy/prod", "ttl_seconds": 60, "steal_expired": True},
+        )
+
+    assert resp.status_code == 409, resp.text
+    assert resp.json()["holder_agent_id"] == BOB_ID
+    assert resp.json()["holder_alias"] == "bob"
+    row = await aweb_cloud_db.aweb_db.fetch_one(
+        "SELECT holder_alias FROM {{tables.reservations}} WHERE team_id = $1 AND resource_key = $2",
+        TEAM_ID,
+        "deploy/prod",
+    )
+    assert row["holder_alias"] == "bob"
+
+
+@pytest.mark.asyncio
+async def test_fencing_token_increases_with_each_new_holder(aweb_cloud_db):
+    alice_app = _build_reservations_app(aweb_cloud_db.aweb_db, _identity("alice", ALICE_ID))
+    bob_app = _build_reservations_app(aweb_cloud_db.aweb_db, _identity("bob", BOB_ID))
+
+    async with AsyncClient(transport=ASGITransport(app=alice_app), base_url="http://test") as alice:
+        first = await alice.post("/v1/reservations", json={"resource_key": "deploy/prod"})
+        again = await alice.post("/v1/reservations", json={"resource_key": "deploy/prod"})
+        renewed = await alice.post("/v1/reservations/renew", json={"resource_key": "deploy/prod"})
+        released = await alice.post("/v1/reservations/release", json={"resource_key": "deploy/prod"})
+    async with AsyncClient(transport=ASGITransport(app=bob_app), base_url="http://test") as bob:
+        second = await bob.post("/v1/reservations", json={"resource_key": "deploy/prod"})
+
+    for resp in (first, again, renewed, released, second):
+        assert resp.status_code == 200, resp.text
+    token = first.json()["fencing_token"]
+    assert token >= 1
+    # Re-acquiring and renewing a held lock keep the token.
+    assert again.json()["fencing_token"] == token
+    assert renewed.json()["fencing_token"] == token
+    # The row was deleted on release, yet the next holder's token is larger.
+    assert second.json()["fencing_token"] > token
+
+
+@pytest.mark.asyncio
+async def test_list_reservations_pages_by_resource_key_with_total(aweb_cloud_db):
+    expires_at = datetime.now(timezone.utc) + timedelta(minutes=5)
+    for key in ["src/c", "src/a", "docs/x", "src/b"]:
+        await _insert_reservation(aweb_cloud_db.aweb_db, resource_key=key, expires_at=expires_at)
+    await _insert_reservation(
+        aweb_cloud_db.aweb_db,
+        resource_key="src/expired",
+        expires_at=datetime.now(timezone.utc) - timedelta(minutes=5),
+    )
+    app = _build_reservations_app(aweb_cloud_db.aweb_db, _identity("alice", ALICE_ID))
+
+    async with AsyncClient(transport=ASGITransport(app=app), base_url="http://test") as client:
+        first = await client.get("/v1/reservations", params={"prefix": "src/", "limit": 2})
+        second = await client.get(
+            "/v1/reservations",
+            params={"prefix": "src/", "limit": 2, "cursor": first.json()["next_cursor"]},
+        )
+        unpaged = await client.get("/v1/reservations")
+        bad_cursor = await client.get("/v1/reservations", params={"cursor": "!!!"})
+
+    assert first.status_code == 200, first.text
+    assert [r["resource_key"] for r in first.json()["reservations"]] == ["src/a", "src/b"]
+    assert first.json()["has_more"] is True
+    assert first.json()["total"] == 3
+
+    assert second.status_code == 200, second.text
+    assert [r["resource_key"] for r in second.json()["reservations"]] == ["src/c"]
+    assert second.json()["has_more"] is False
+    assert second.json()["next_cursor"] is None
+    assert second.json()["total"] == 3
+
+    assert [r["resource_key"] for r in unpaged.json()["reservations"]] == ["docs/x", "src/a", "src/b", "src/c"]
+    assert unpaged.json()["has_more"] is False
+    assert unpaged.json()["total"] == 4
+
+    assert bad_cursor.status_code == 422, bad_cursor.text
+
+
+
+@pytest.mark.asyncio
+async def test_list_reservations_answers_matching_etag_with_304(aweb_cloud_db):
+    expires_at = datetime.now(timezone.utc) + timedelta(minutes=5)
+    await _insert_reservation(aweb_cloud_db.aweb_db, resource_key="src/a", expires_at=expires_at)
+    app = _build_reservations_app(aweb_cloud_db.aweb_db, _identity("alice", ALICE_ID))
+
+    async with AsyncClient(transport=ASGITransport(app=app), base_url="http://test") as client:
+        first = await client.get("/v1/reservations")
+        etag = first.headers["ETag"]
+        unchanged = await client.get("/v1/reservations", headers={"If-None-Match": etag})
+        await _insert_reservation(aweb_cloud_db.aweb_db, resource_key="src/b", expires_at=expires_at)
+        changed = await client.get("/v1/reservations", headers={"If-None-Match": etag})
+
+    assert first.status_code == 200, first.text
+    assert unchanged.status_code == 304
+    assert unchanged.headers["ETag"] == etag
+    assert unchanged.content == b""
+    assert changed.status_code == 200, changed.text
+    assert changed.headers["ETag"] != etag
+    assert [r["resource_key"] for r in changed.json()["reservations"]] == ["src/a", "src/b"]
+
+
+@pytest.mark.asyncio
+async def test_watch_reservation_streams_snapshot_then_expiry(aweb_cloud_db, monkeypatch):
+    monkeypatch.setattr(reservations_module, "RESERVATION_WATCH_POLL_INTERVAL", 0.05)
+    monkeypatch.setattr(reservations_module, "RESERVATION_WATCH_MAX_DURATION", 1)
+    expires_at = datetime.now(timezone.utc) + timedelta(milliseconds=300)
+    await _insert_reservation(aweb_cloud_db.aweb_db, resource_key="src/a", expires_at=expires_at)
+    app = _build_reservations_app(aweb_cloud_db.aweb_db, _identity("alice", ALICE_ID))
+
+    async with AsyncClient(transport=ASGITransport(app=app), base_url="http://test") as client:
+        resp = await client.get("/v1/reservations/watch", params={"resource_key": "src/a"})
+
+    assert resp.status_code == 200, resp.text
+    assert resp.headers["content-type"].startswith("text/event-stream")
+    events = [
+        json.loads(line[len("data: "):])
+        for line in resp.text.splitlines()
+        if line.startswith("data: ")
+    ]
+    assert [e["type"] for e in events] == ["snapshot", "expired"]
+    assert events[0]["reservation"]["holder_alias"] == "bob"
+    assert events[1]["resource_key"] == "src/a"
+    assert events[1]["holder_alias"] == "bob"
+
+
+def test_reservation_watch_transitions_report_release_and_new_holder():
+    held = {"holder_agent_id": BOB_ID, "holder_alias": "bob", "acquired_at": "t0", "expires_at": "t1", "live": True}
+    taken = dict(held, holder_agent_id=ALICE_ID, holder_alias="alice", acquired_at="t2")
+
+    def types(previous, current):
+        return [
+            json.loads(event.split("data: ", 1)[1])["type"]
+            for event in reservations_module._reservation_watch_transitions("src/a", previous, current)
+        ]
+
+    assert types(None, held) == ["acquired"]
+    assert types(held, None) == ["released"]
+    assert types(held, dict(held, live=False)) == ["expired"]
+    assert types(held, taken) == ["acquired"]
+    assert types(held, dict(held, expires_at="t9")) == []
+    assert types(dict(held, live=False), taken) == ["acquired"]
+
+
+async def _insert_agent(aweb_db, *, alias: str, agent_id: str) -> None:
+    await aweb_db.execute(
+        """
+        INSERT INTO {{tables.teams}} (team_id, namespace, team_name, team_did_key)
+        VALUES ($1, 'acme.com', 'backend', 'did:key:z6Mkteam')
+        ON CONFLICT DO NOTHING
+        """,
+        TEAM_ID,
+    )
+    await aweb_db.execute(
+        """
+        INSERT INTO {{tables.agents}} (agent_id, team_id, did_key, did_aw, alias, lifetime)
+        VALUES ($1, $2, $3, $4, $5, 'persistent')
+        """,
+        agent_id,
+        TEAM_ID,
+        f"did:key:z6Mk{alias}",
+        f"did:aw:{alias}",
+        alias,
+    )
+
+
+@pytest.mark.asyncio
+async def test_transfer_reassigns_holder_and_keeps_lease(aweb_cloud_db):
+    expires_at = datetime.now(timezone.utc) + timedelta(minutes=5)
+    await _insert_reservation(aweb_cloud_db.aweb_db, resource_key="deploy/prod", expires_at=expires_at)
+    await _insert_agent(aweb_cloud_db.aweb_db, alias="alice", agent_id=ALICE_ID)
+    app = _build_reservations_app(aweb_cloud_db.aweb_db, _identity("bob", BOB_ID))
+
+    async with AsyncClient(transport=ASGITransport(app=app), base_url="http://test") as client:
+        resp = await client.post(
+            "/v1/reservations/transfer",
+            json={"resource_key": "deploy/prod", "to_alias": "alice"},
+        )
+
+    assert resp.status_code == 200, resp.text
+    body = resp.json()
+    assert body["status"] == "transferred"
+    assert body["holder_agent_id"] == ALICE_ID
+    assert body["holder_alias"] == "alice"
+    assert body["previous_holder_agent_id"] == BOB_ID
+    assert body["previous_holder_alias"] == "bob"
+    assert datetime.fromisoformat(body["expires_at"]) == expires_at
+    row = await aweb_cloud_db.aweb_db.fetch_one(
+        "SELECT holder_alias, expires_at FROM {{tables.reservations}} WHERE team_id = $1 AND resource_key = $2",
+        TEAM_ID,
+        "deploy/prod",
+    )
+    assert row["holder_alias"] == "alice"
+    assert row["expires_at"] == expires_at
+    # The previous holder is fenced off.
+    assert body["fencing_token"] >= 1
+
+
+@pytest.mark.asyncio
+async def test_transfer_rejects_caller_who_is_not_the_holder(aweb_cloud_db):
+    expires_at = datetime.now(timezone.utc) + timedelta(minutes=5)
+    await _insert_reservation(aweb_cloud_db.aweb_db, resource_key="deploy/prod", expires_at=expires_at)
+    await _insert_agent(aweb_cloud_db.aweb_db, alias="alice", agent_id=ALICE_ID)
+    app = _build_reservations_app(aweb_cloud_db.aweb_db, _identity("alice", ALICE_ID))
+
+    async with AsyncClient(transport=ASGITransport(app=app), base_url="http://test") as client:
+        resp = await client.post(
+            "/v1/reservations/transfer",
+            json={"resource_key": "deploy/prod", "to_alias": "alice"},
+        )
+        missing = await client.post(
+            "/v1/reservations/transfer",
+            json={"resource_key": "deploy/none", "to_alias": "alice"},
+        )
+
+    assert resp.status_code == 409, resp.text
+    assert resp.json()["holder_alias"] == "bob"
+    assert missing.status_code == 404, missing.text
+    row = await aweb_cloud_db.aweb_db.fetch_one(
+        "SELECT holder_alias FROM {{tables.reservations}} WHERE team_id = $1 AND resource_key = $2",
+        TEAM_ID,
+        "deploy/prod",
+    )
+    assert row["holder_alias"] == "bob"
+
+
+@pytest.mark.asyncio
+async def test_renew_header_extends_only_the_callers_live_locks(aweb_cloud_db):
+    soon = datetime.now(timezone.utc) + timedelta(minutes=5)
+    for key in ["deploy/prod", "deploy/stage,eu"]:
+        await _insert_reservation(aweb_cloud_db.aweb_db, resource_key=key, expires_at=soon)
+    await _insert_reservation(
+        aweb_cloud_db.aweb_db,
+        resource_key="deploy/expired",
+        expires_at=datetime.now(timezone.utc) - timedelta(minutes=5),
+    )
+
+    renewed = await renew_reservations_from_header(
+        aweb_cloud_db.aweb_db,
+        team_id=TEAM_ID,
+        agent_id=BOB_ID,
+        header_value="deploy/prod, deploy%2Fstage%2Ceu, deploy/expired, deploy/none",
+    )
+    # Alice holds nothing, so her header renews nothing.
+    stolen = await renew_reservations_from_header(
+        aweb_cloud_db.aweb_db,
+        team_id=TEAM_ID,
+        agent_id=ALICE_ID,
+        header_value="deploy/prod",
+    )
+    invalid = await renew_reservations_from_header(
+        aweb_cloud_db.aweb_db,
+        team_id=TEAM_ID,
+        agent_id=BOB_ID,
+        header_value=",".join(f"k{i}" for i in range(33)),
+    )
+
+    assert sorted(renewed) == ["deploy/prod", "deploy/stage,eu"]
+    assert stolen == []
+    assert invalid == []
+    rows = await aweb_cloud_db.aweb_db.fetch_all(
+        "SELECT resource_key, expires_at FROM {{tables.reservations}} WHERE team_id = $1",
+        TEAM_ID,
+    )
+    expiry = {row["resource_key"]: row["expires_at"] for row in rows}
+    assert expiry["deploy/prod"] > soon + timedelta(minutes=50)
+    assert expiry["deploy/stage,eu"] > soon + timedelta(minutes=50)
+    assert expiry["deploy/expired"] < datetime.now(timezone.utc)
+
+
+@pytest.mark.asyncio
+async def test_renew_header_extends_a_lock_by_its_own_ttl(aweb_cloud_db):
+    app = _build_reservations_app(aweb_cloud_db.aweb_db, _identity("bob", BOB_ID))
+    async with AsyncClient(transport=ASGITransport(app=app), base_url="http://test") as client:
+        resp = await client.post("/v1/reservations", json={"resource_key": "deploy/prod", "ttl_seconds": 30})
+    assert resp.status_code == 200, resp.text
+
+    before = datetime.now(timezone.utc)
+    renewed = await renew_reservations_from_header(
+        aweb_cloud_db.aweb_db,
+        team_id=TEAM_ID,
+        agent_id=BOB_ID,
+        header_value="deploy/prod",
+    )
+
+    assert renewed == ["deploy/prod"]
+    row = await aweb_cloud_db.aweb_db.fetch_one(
+        "SELECT expires_at, ttl_seconds FROM {{tables.reservations}} WHERE team_id = $1 AND resource_key = $2",
+        TEAM_ID,
+        "deploy/prod",
+    )
+    assert row["ttl_seconds"] == 30
+    # A 30s lock stays a 30s lock: a crashed holder loses it shortly after
+    # its last request, not an hour later.
+    assert before + timedelta(seconds=29) <= row["expires_at"] <= datetime.now(timezone.utc) + timedelta(seconds=31)